@@ -9,6 +9,27 @@ pub fn build(b: *std.Build) void {
         .os_tag = .freestanding,
     });
 
+    // Optional CLI subsystems. Library code is only compiled when used, so
+    // these only matter for the executable. Builds are lean by default:
+    // turn subsystems on one at a time, or all at once with -Dlean=false.
+    const lean = b.option(bool, "lean", "Leave out every optional CLI subsystem (default: true)") orelse true;
+    const cli_options = b.addOptions();
+    cli_options.addOption(bool, "server", b.option(bool, "server", "Include the web UI server") orelse !lean);
+    cli_options.addOption(bool, "tui", b.option(bool, "tui", "Include the interactive terminal mode") orelse !lean);
+    cli_options.addOption(bool, "ags", b.option(bool, "ags", "Include AGS4 commands and SVG log rendering") orelse !lean);
+    cli_options.addOption(bool, "bench", b.option(bool, "bench", "Include the bench command") orelse !lean);
+    cli_options.addOption(bool, "exporters", b.option(bool, "exporters", "Include Excel input and output and validation reports") orelse !lean);
+    cli_options.addOption(bool, "correlation", b.option(bool, "correlation", "Include unit identification and spatial correlation") orelse !lean);
+
+    // Fixed sets for tests (everything in) and the lean check (everything out)
+    const subsystems = [_][]const u8{ "server", "tui", "ags", "bench", "exporters", "correlation" };
+    const full_options = b.addOptions();
+    const lean_options = b.addOptions();
+    for (subsystems) |name| {
+        full_options.addOption(bool, name, true);
+        lean_options.addOption(bool, name, false);
+    }
+
     // Main executable
     const exe = b.addExecutable(.{
         .name = "litholog",
@@ -16,6 +37,7 @@ pub fn build(b: *std.Build) void {
         .target = target,
         .optimize = optimize,
     });
+    exe.root_module.addOptions("build_options", cli_options);
 
     b.installArtifact(exe);
 
@@ -42,6 +64,18 @@ pub fn build(b: *std.Build) void {
     const run_step = b.step("run", "Run the app");
     run_step.dependOn(&run_cmd.step);
 
+    // Compile the CLI as -Dlean=true would, whatever options were given, so
+    // the build options keep compiling with every subsystem left out
+    const lean_exe = b.addExecutable(.{
+        .name = "litholog-lean",
+        .root_source_file = b.path("src/main.zig"),
+        .target = target,
+        .optimize = optimize,
+    });
+    lean_exe.root_module.addOptions("build_options", lean_options);
+    const lean_step = b.step("lean", "Compile the CLI with every optional subsystem left out");
+    lean_step.dependOn(&lean_exe.step);
+
     // Library build step
     const lib_step = b.step("lib", "Build the shared library");
     lib_step.dependOn(&lib.step);
@@ -66,6 +100,11 @@ pub fn build(b: *std.Build) void {
         .root_source_file = b.path("src/parser/bs5930.zig"),
     });
 
+    // Depth/description pairs from PDF borehole log tables, for library users
+    _ = b.addModule("pdf_extract", .{
+        .root_source_file = b.path("src/pdf_extract.zig"),
+    });
+
     // Tests - individual test files
     const lexer_tests = b.addTest(.{
         .root_source_file = b.path("tests/lexer_test.zig"),
@@ -77,6 +116,16 @@ pub fn build(b: *std.Build) void {
     const test_lexer_step = b.step("test-lexer", "Run lexer tests");
     test_lexer_step.dependOn(&run_lexer_tests.step);
 
+    const tokenizer_robustness_tests = b.addTest(.{
+        .root_source_file = b.path("tests/tokenizer_robustness_test.zig"),
+        .target = target,
+        .optimize = optimize,
+    });
+    tokenizer_robustness_tests.root_module.addImport("parser", parser_module);
+    const run_tokenizer_robustness_tests = b.addRunArtifact(tokenizer_robustness_tests);
+    const test_tokenizer_robustness_step = b.step("test-tokenizer-robustness", "Run tokenizer robustness and fuzz tests");
+    test_tokenizer_robustness_step.dependOn(&run_tokenizer_robustness_tests.step);
+
     const parser_tests = b.addTest(.{
         .root_source_file = b.path("tests/parser_test.zig"),
         .target = target,
@@ -147,11 +196,22 @@ pub fn build(b: *std.Build) void {
     const test_integration_step = b.step("test-integration", "Run integration tests");
     test_integration_step.dependOn(&run_integration_tests.step);
 
+    const determinism_tests = b.addTest(.{
+        .root_source_file = b.path("tests/determinism_test.zig"),
+        .target = target,
+        .optimize = optimize,
+    });
+    determinism_tests.root_module.addImport("parser", parser_module);
+    const run_determinism_tests = b.addRunArtifact(determinism_tests);
+    const test_determinism_step = b.step("test-determinism", "Run output stability tests");
+    test_determinism_step.dependOn(&run_determinism_tests.step);
+
     const cli_router_tests = b.addTest(.{
         .root_source_file = b.path("src/main.zig"),
         .target = target,
         .optimize = optimize,
     });
+    cli_router_tests.root_module.addOptions("build_options", full_options);
     const run_cli_router_tests = b.addRunArtifact(cli_router_tests);
 
     const ags_reader_unit_tests = b.addTest(.{
@@ -175,6 +235,13 @@ pub fn build(b: *std.Build) void {
     });
     const run_ags_writer_unit_tests = b.addRunArtifact(ags_writer_unit_tests);
 
+    const pdf_extract_unit_tests = b.addTest(.{
+        .root_source_file = b.path("src/pdf_extract.zig"),
+        .target = target,
+        .optimize = optimize,
+    });
+    const run_pdf_extract_unit_tests = b.addRunArtifact(pdf_extract_unit_tests);
+
     const svg_renderer_unit_tests = b.addTest(.{
         .root_source_file = b.path("src/svg_renderer.zig"),
         .target = target,
@@ -182,6 +249,13 @@ pub fn build(b: *std.Build) void {
     });
     const run_svg_renderer_unit_tests = b.addRunArtifact(svg_renderer_unit_tests);
 
+    const c_api_unit_tests = b.addTest(.{
+        .root_source_file = b.path("src/lib.zig"),
+        .target = target,
+        .optimize = optimize,
+    });
+    const run_c_api_unit_tests = b.addRunArtifact(c_api_unit_tests);
+
     // Original parser tests
     const lib_unit_tests = b.addTest(.{
         .root_source_file = b.path("src/parser/bs5930.zig"),
@@ -202,6 +276,7 @@ pub fn build(b: *std.Build) void {
     // Aggregate test step - runs all tests
     const test_step = b.step("test", "Run all unit tests");
     test_step.dependOn(&run_lexer_tests.step);
+    test_step.dependOn(&run_tokenizer_robustness_tests.step);
     test_step.dependOn(&run_parser_tests.step);
     test_step.dependOn(&run_validation_tests.step);
     test_step.dependOn(&run_strength_db_tests.step);
@@ -210,12 +285,16 @@ pub fn build(b: *std.Build) void {
     test_step.dependOn(&run_fuzzy_tests.step);
     test_step.dependOn(&run_anomaly_tests.step);
     test_step.dependOn(&run_integration_tests.step);
+    test_step.dependOn(&run_determinism_tests.step);
     test_step.dependOn(&run_cli_router_tests.step);
     test_step.dependOn(&run_lib_unit_tests.step);
+    test_step.dependOn(&run_c_api_unit_tests.step);
     test_step.dependOn(&run_ags_reader_unit_tests.step);
     test_step.dependOn(&run_ags_validator_unit_tests.step);
     test_step.dependOn(&run_ags_writer_unit_tests.step);
+    test_step.dependOn(&run_pdf_extract_unit_tests.step);
     test_step.dependOn(&run_svg_renderer_unit_tests.step);
+    test_step.dependOn(lean_step);
 
     // Demo executables
     const demo_spatial = b.addExecutable(.{
@@ -248,6 +327,17 @@ pub fn build(b: *std.Build) void {
     const demo_clustering_step = b.step("demo-clustering", "Run clustering quality metrics demo");
     demo_clustering_step.dependOn(&demo_clustering_run.step);
 
+    // Benchmarks in Go benchmark format for benchstat
+    const bench = b.addExecutable(.{
+        .name = "bench",
+        .root_source_file = b.path("src/bench.zig"),
+        .target = target,
+        .optimize = optimize,
+    });
+    const bench_run = b.addRunArtifact(bench);
+    const bench_step = b.step("bench", "Run parser benchmarks (benchstat-compatible output)");
+    bench_step.dependOn(&bench_run.step);
+
     // WASM module for GitHub Pages demo
     const wasm_exe = b.addExecutable(.{
         .name = "litholog-wasm",
@@ -5,6 +5,12 @@
 extern "C" {
 #endif
 
+// Enum values are stable across releases: stored integers keep their meaning
+// and new members are appended. *_UNKNOWN is returned by the *_from_string
+// functions for unrecognised terms. Functions taking a description read
+// *_UNKNOWN, -1 and values they do not know as absent, and return NULL for an
+// invalid material_type.
+
 typedef enum {
     LITHOLOG_MATERIAL_SOIL = 0,
     LITHOLOG_MATERIAL_ROCK = 1
@@ -19,15 +25,19 @@ typedef enum {
     LITHOLOG_CONSISTENCY_HARD = 5,
     LITHOLOG_CONSISTENCY_SOFT_TO_FIRM = 6,
     LITHOLOG_CONSISTENCY_FIRM_TO_STIFF = 7,
-    LITHOLOG_CONSISTENCY_STIFF_TO_VERY_STIFF = 8
+    LITHOLOG_CONSISTENCY_STIFF_TO_VERY_STIFF = 8,
+    LITHOLOG_CONSISTENCY_UNKNOWN = 255
 } litholog_consistency_t;
 
 typedef enum {
     LITHOLOG_DENSITY_VERY_LOOSE = 0,
     LITHOLOG_DENSITY_LOOSE = 1,
-    LITHOLOG_DENSITY_MEDIUM_DENSE = 2,
-    LITHOLOG_DENSITY_DENSE = 3,
-    LITHOLOG_DENSITY_VERY_DENSE = 4
+    LITHOLOG_DENSITY_LOOSE_TO_MEDIUM_DENSE = 2,
+    LITHOLOG_DENSITY_MEDIUM_DENSE = 3,
+    LITHOLOG_DENSITY_MEDIUM_DENSE_TO_DENSE = 4,
+    LITHOLOG_DENSITY_DENSE = 5,
+    LITHOLOG_DENSITY_VERY_DENSE = 6,
+    LITHOLOG_DENSITY_UNKNOWN = 255
 } litholog_density_t;
 
 typedef enum {
@@ -37,7 +47,8 @@ typedef enum {
     LITHOLOG_ROCK_STRENGTH_MODERATELY_STRONG = 3,
     LITHOLOG_ROCK_STRENGTH_STRONG = 4,
     LITHOLOG_ROCK_STRENGTH_VERY_STRONG = 5,
-    LITHOLOG_ROCK_STRENGTH_EXTREMELY_STRONG = 6
+    LITHOLOG_ROCK_STRENGTH_EXTREMELY_STRONG = 6,
+    LITHOLOG_ROCK_STRENGTH_UNKNOWN = 255
 } litholog_rock_strength_t;
 
 typedef enum {
@@ -45,8 +56,11 @@ typedef enum {
     LITHOLOG_SOIL_TYPE_SILT = 1,
     LITHOLOG_SOIL_TYPE_SAND = 2,
     LITHOLOG_SOIL_TYPE_GRAVEL = 3,
-    LITHOLOG_SOIL_TYPE_PEAT = 4,
-    LITHOLOG_SOIL_TYPE_ORGANIC = 5
+    LITHOLOG_SOIL_TYPE_COBBLES = 4,
+    LITHOLOG_SOIL_TYPE_BOULDERS = 5,
+    LITHOLOG_SOIL_TYPE_PEAT = 6,
+    LITHOLOG_SOIL_TYPE_ORGANIC = 7,
+    LITHOLOG_SOIL_TYPE_UNKNOWN = 255
 } litholog_soil_type_t;
 
 typedef enum {
@@ -64,7 +78,8 @@ typedef enum {
     LITHOLOG_ROCK_TYPE_GNEISS = 11,
     LITHOLOG_ROCK_TYPE_MARBLE = 12,
     LITHOLOG_ROCK_TYPE_CONGLOMERATE = 13,
-    LITHOLOG_ROCK_TYPE_BRECCIA = 14
+    LITHOLOG_ROCK_TYPE_BRECCIA = 14,
+    LITHOLOG_ROCK_TYPE_UNKNOWN = 255
 } litholog_rock_type_t;
 
 typedef enum {
@@ -72,7 +87,8 @@ typedef enum {
     LITHOLOG_WEATHERING_SLIGHTLY = 1,
     LITHOLOG_WEATHERING_MODERATELY = 2,
     LITHOLOG_WEATHERING_HIGHLY = 3,
-    LITHOLOG_WEATHERING_COMPLETELY = 4
+    LITHOLOG_WEATHERING_COMPLETELY = 4,
+    LITHOLOG_WEATHERING_UNKNOWN = 255
 } litholog_weathering_grade_t;
 
 typedef enum {
@@ -81,7 +97,8 @@ typedef enum {
     LITHOLOG_ROCK_STRUCTURE_JOINTED = 2,
     LITHOLOG_ROCK_STRUCTURE_FRACTURED = 3,
     LITHOLOG_ROCK_STRUCTURE_FOLIATED = 4,
-    LITHOLOG_ROCK_STRUCTURE_LAMINATED = 5
+    LITHOLOG_ROCK_STRUCTURE_LAMINATED = 5,
+    LITHOLOG_ROCK_STRUCTURE_UNKNOWN = 255
 } litholog_rock_structure_t;
 
 typedef enum {
@@ -140,6 +157,12 @@ litholog_soil_description_t* litholog_parse(const char* description);
 void litholog_free_description(litholog_soil_description_t* description);
 char* litholog_description_to_json(const litholog_soil_description_t* description);
 void litholog_free_string(char* str);
+// Rewrite only the words behind one field ("consistency", "primary_soil_type", ...);
+// returns NULL if the field was not read from the text
+char* litholog_replace_attribute(const char* raw, const char* field, const char* new_value);
+// Hex SHA-256 of the parsed meaning, unaffected by case, spacing or constituent order;
+// returns NULL if parsing fails
+char* litholog_canonical_hash(const char* description);
 
 // Utility functions
 const char* litholog_material_type_to_string(litholog_material_type_t type);
@@ -152,17 +175,37 @@ const char* litholog_weathering_grade_to_string(litholog_weathering_grade_t grad
 const char* litholog_rock_structure_to_string(litholog_rock_structure_t structure);
 const char* litholog_strength_parameter_type_to_string(litholog_strength_parameter_type_t type);
 
+// Term to enum value, or the matching *_UNKNOWN for unrecognised input
+int litholog_consistency_from_string(const char* text);
+int litholog_density_from_string(const char* text);
+int litholog_rock_strength_from_string(const char* text);
+int litholog_soil_type_from_string(const char* text);
+int litholog_rock_type_from_string(const char* text);
+int litholog_weathering_grade_from_string(const char* text);
+int litholog_rock_structure_from_string(const char* text);
+
 // Version functions
 unsigned int litholog_version_major(void);
 unsigned int litholog_version_minor(void);
 unsigned int litholog_version_patch(void);
 const char* litholog_version_string(void);
 
+// Parse a small built-in corpus and check the results; call at startup to catch
+// a broken or mismatched library. Returns 0 if every case matched, the 1-based
+// number of the first failing case, or -1 if memory ran out.
+int litholog_self_test(void);
+
 // Advanced features
 char* litholog_generate_description(const litholog_soil_description_t* description);
 char* litholog_generate_concise(const litholog_soil_description_t* description);
 char* litholog_fuzzy_match(const char* target, const char** options, int options_count, float threshold);
+// JSON array of the best k {"match", "score"} candidates, highest score first
+char* litholog_fuzzy_match_n(const char* target, const char** options, int options_count, int k);
 float litholog_similarity(const char* s1, const char* s2);
+// algorithm is "levenshtein", "jaro_winkler" or "token_set"; returns -1.0 if unknown
+float litholog_similarity_with(const char* s1, const char* s2, const char* algorithm);
+// Fills out[targets_count * options_count], row-major by target; returns 0 on success
+int litholog_similarity_matrix(const char** targets, int targets_count, const char** options, int options_count, float* out);
 
 // JSON input/output
 char* litholog_generate_from_json(const char* json_str);
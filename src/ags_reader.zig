@@ -1,6 +1,8 @@
 const std = @import("std");
 const bs5930 = @import("parser/bs5930.zig");
 pub const Parser = bs5930.Parser;
+pub const PhotoRef = bs5930.PhotoRef;
+pub const SampleRef = bs5930.SampleRef;
 
 pub const AgsProject = struct {
     id: []const u8,
@@ -26,7 +28,15 @@ pub const AgsStratum = struct {
     legend_code: ?[]const u8,
     geology_code: ?[]const u8,
     formation: ?[]const u8,
-    parsed: ?bs5930.SoilDescription,
+    parsed: ?bs5930.GeologicalDescription,
+    /// FILE_FSET linking the stratum to its photographs in the FILE group
+    file_set: ?[]const u8 = null,
+    /// Core photographs from the FILE group; depths come from FILE_REM
+    /// ("1.00-2.50 m") or default to the stratum
+    photos: []const bs5930.PhotoRef = &.{},
+    /// SAMP rows whose top lies within the stratum, with the laboratory
+    /// groups that reference them
+    samples: []const bs5930.SampleRef = &.{},
 };
 
 pub const AgsFile = struct {
@@ -55,6 +65,20 @@ pub const AgsFile = struct {
             if (stratum.geology_code) |v| allocator.free(v);
             if (stratum.formation) |v| allocator.free(v);
             if (stratum.parsed) |parsed| parsed.deinit(allocator);
+            if (stratum.file_set) |v| allocator.free(v);
+            for (stratum.photos) |photo| {
+                allocator.free(photo.uri);
+                if (photo.caption) |v| allocator.free(v);
+            }
+            allocator.free(stratum.photos);
+            for (stratum.samples) |sample| {
+                allocator.free(sample.reference);
+                allocator.free(sample.sample_type);
+                if (sample.id) |v| allocator.free(v);
+                for (sample.test_ids) |v| allocator.free(v);
+                allocator.free(sample.test_ids);
+            }
+            allocator.free(stratum.samples);
         }
         allocator.free(self.strata);
     }
@@ -77,9 +101,29 @@ pub fn parseSlice(allocator: std.mem.Allocator, parser: *bs5930.Parser, content:
     var strata = std.ArrayList(AgsStratum).init(allocator);
     defer strata.deinit();
 
+    var files = std.ArrayList(FileRow).init(allocator);
+    defer {
+        for (files.items) |file| file.deinit(allocator);
+        files.deinit();
+    }
+
+    var samples = std.ArrayList(SampleRow).init(allocator);
+    defer {
+        for (samples.items) |sample| sample.deinit(allocator);
+        samples.deinit();
+    }
+
+    var lab_rows = std.ArrayList(LabRow).init(allocator);
+    defer {
+        for (lab_rows.items) |row| row.deinit(allocator);
+        lab_rows.deinit();
+    }
+
     var project: ?AgsProject = null;
 
     var group_kind = GroupKind.none;
+    var group_name_buf: [16]u8 = undefined;
+    var group_name_len: usize = 0;
     var headings = std.ArrayList([]const u8).init(allocator);
     defer {
         for (headings.items) |h| allocator.free(h);
@@ -98,6 +142,9 @@ pub fn parseSlice(allocator: std.mem.Allocator, parser: *bs5930.Parser, content:
         const descriptor = fields[0];
         if (std.mem.eql(u8, descriptor, "GROUP")) {
             group_kind = parseGroupKind(if (fields.len > 1) fields[1] else "");
+            const name = if (fields.len > 1) fields[1] else "";
+            group_name_len = @min(name.len, group_name_buf.len);
+            @memcpy(group_name_buf[0..group_name_len], name[0..group_name_len]);
             for (headings.items) |h| allocator.free(h);
             headings.clearRetainingCapacity();
             continue;
@@ -126,8 +173,69 @@ pub fn parseSlice(allocator: std.mem.Allocator, parser: *bs5930.Parser, content:
             .geol => {
                 try strata.append(try parseStratumRow(allocator, parser, headings.items, fields));
             },
-            else => {},
+            .file => {
+                try files.append(try parseFileRow(allocator, headings.items, fields));
+            },
+            .samp => {
+                try samples.append(try parseSampleRow(allocator, headings.items, fields));
+            },
+            .other => {
+                // Laboratory groups name the sample they were run on
+                const key = try sampleKey(allocator, headings.items, fields) orelse continue;
+                errdefer allocator.free(key);
+                try lab_rows.append(.{ .key = key, .group = try allocator.dupe(u8, group_name_buf[0..group_name_len]) });
+            },
+            .none => {},
+        }
+    }
+
+    // FILE usually follows GEOL, so photos are linked once everything is read
+    for (strata.items) |*stratum| {
+        const set = stratum.file_set orelse continue;
+        var photos = std.ArrayList(bs5930.PhotoRef).init(allocator);
+        defer photos.deinit();
+        for (files.items) |file| {
+            if (!std.mem.eql(u8, file.set, set)) continue;
+            const range = parseDepthRange(file.remark) orelse [2]f64{ stratum.depth_top, stratum.depth_base };
+            try photos.append(.{
+                .uri = try allocator.dupe(u8, file.name),
+                .depth_top = range[0],
+                .depth_bottom = range[1],
+                .caption = try dupOptional(allocator, file.description),
+            });
+        }
+        stratum.photos = try photos.toOwnedSlice();
+    }
+
+    // Samples belong to the stratum their top lies in
+    for (strata.items, 0..) |*stratum, index| {
+        var refs = std.ArrayList(bs5930.SampleRef).init(allocator);
+        defer refs.deinit();
+        for (samples.items) |sample| {
+            if (stratumIndex(strata.items, sample.location_id, sample.depth_top) != index) continue;
+
+            var tests = std.ArrayList([]const u8).init(allocator);
+            defer tests.deinit();
+            if (sample.remark) |remark| {
+                if (std.mem.startsWith(u8, remark, "Tests:")) {
+                    var listed = std.mem.tokenizeAny(u8, remark["Tests:".len..], "; ");
+                    while (listed.next()) |id| try appendTest(allocator, &tests, id);
+                }
+            }
+            for (lab_rows.items) |row| {
+                if (sample.matches(row.key)) try appendTest(allocator, &tests, row.group);
+            }
+
+            try refs.append(.{
+                .reference = try allocator.dupe(u8, sample.reference),
+                .sample_type = try allocator.dupe(u8, sample.sample_type),
+                .depth_top = sample.depth_top,
+                .depth_base = sample.depth_base,
+                .id = try dupOptional(allocator, sample.id),
+                .test_ids = try tests.toOwnedSlice(),
+            });
         }
+        stratum.samples = try refs.toOwnedSlice();
     }
 
     return AgsFile{
@@ -137,15 +245,151 @@ pub fn parseSlice(allocator: std.mem.Allocator, parser: *bs5930.Parser, content:
     };
 }
 
-const GroupKind = enum { none, proj, loca, geol, other };
+const GroupKind = enum { none, proj, loca, geol, file, samp, other };
 
 fn parseGroupKind(name: []const u8) GroupKind {
     if (std.mem.eql(u8, name, "PROJ")) return .proj;
     if (std.mem.eql(u8, name, "LOCA")) return .loca;
     if (std.mem.eql(u8, name, "GEOL")) return .geol;
+    if (std.mem.eql(u8, name, "FILE")) return .file;
+    if (std.mem.eql(u8, name, "SAMP")) return .samp;
     return .other;
 }
 
+/// A SAMP group row, held until the strata are read
+const SampleRow = struct {
+    location_id: []const u8,
+    key: []const u8,
+    // Lab rows may leave SAMP_ID blank even when the SAMP row gives one,
+    // so the sample also answers to its LOCA_ID, SAMP_TOP, SAMP_REF and
+    // SAMP_TYPE
+    composite_key: ?[]const u8,
+    reference: []const u8,
+    sample_type: []const u8,
+    depth_top: f64,
+    depth_base: ?f64,
+    id: ?[]const u8,
+    remark: ?[]const u8,
+
+    fn deinit(self: SampleRow, allocator: std.mem.Allocator) void {
+        allocator.free(self.location_id);
+        allocator.free(self.key);
+        if (self.composite_key) |v| allocator.free(v);
+        allocator.free(self.reference);
+        allocator.free(self.sample_type);
+        if (self.id) |v| allocator.free(v);
+        if (self.remark) |v| allocator.free(v);
+    }
+
+    /// Whether a lab row's sample key names this sample
+    fn matches(self: SampleRow, key: []const u8) bool {
+        if (std.mem.eql(u8, key, self.key)) return true;
+        const composite = self.composite_key orelse return false;
+        return std.mem.eql(u8, key, composite);
+    }
+};
+
+/// A laboratory group row and the sample it was run on
+const LabRow = struct {
+    key: []const u8,
+    group: []const u8,
+
+    fn deinit(self: LabRow, allocator: std.mem.Allocator) void {
+        allocator.free(self.key);
+        allocator.free(self.group);
+    }
+};
+
+fn parseSampleRow(allocator: std.mem.Allocator, headings: []const []const u8, fields: []const []const u8) !SampleRow {
+    const base = getFieldByHeading(headings, fields, "SAMP_BASE");
+    return SampleRow{
+        .location_id = try allocator.dupe(u8, getFieldByHeading(headings, fields, "LOCA_ID") orelse ""),
+        .key = try sampleKey(allocator, headings, fields) orelse try allocator.dupe(u8, ""),
+        .composite_key = try compositeKey(allocator, headings, fields),
+        .reference = try allocator.dupe(u8, getFieldByHeading(headings, fields, "SAMP_REF") orelse ""),
+        .sample_type = try allocator.dupe(u8, getFieldByHeading(headings, fields, "SAMP_TYPE") orelse ""),
+        .depth_top = parseFloatOrDefault(getFieldByHeading(headings, fields, "SAMP_TOP"), 0),
+        .depth_base = if (base != null and base.?.len > 0) parseFloatOrDefault(base, 0) else null,
+        .id = try dupOptional(allocator, getFieldByHeading(headings, fields, "SAMP_ID")),
+        .remark = try dupOptional(allocator, getFieldByHeading(headings, fields, "SAMP_REM")),
+    };
+}
+
+/// SAMP_ID where given, otherwise LOCA_ID, SAMP_TOP, SAMP_REF and SAMP_TYPE,
+/// which together identify a sample in AGS 4. Null for rows that name no
+/// sample.
+fn sampleKey(allocator: std.mem.Allocator, headings: []const []const u8, fields: []const []const u8) !?[]u8 {
+    if (getFieldByHeading(headings, fields, "SAMP_ID")) |id| {
+        if (id.len > 0) return try allocator.dupe(u8, id);
+    }
+    return compositeKey(allocator, headings, fields);
+}
+
+/// LOCA_ID, SAMP_TOP, SAMP_REF and SAMP_TYPE joined into one key; null
+/// without a SAMP_TOP
+fn compositeKey(allocator: std.mem.Allocator, headings: []const []const u8, fields: []const []const u8) !?[]u8 {
+    const top = getFieldByHeading(headings, fields, "SAMP_TOP") orelse return null;
+    if (top.len == 0) return null;
+    return try std.fmt.allocPrint(allocator, "{s}|{d:.2}|{s}|{s}", .{
+        getFieldByHeading(headings, fields, "LOCA_ID") orelse "",
+        parseFloatOrDefault(top, 0),
+        getFieldByHeading(headings, fields, "SAMP_REF") orelse "",
+        getFieldByHeading(headings, fields, "SAMP_TYPE") orelse "",
+    });
+}
+
+/// The stratum of `location_id` containing `depth`; a depth on a boundary
+/// goes to the stratum below
+fn stratumIndex(strata: []const AgsStratum, location_id: []const u8, depth: f64) ?usize {
+    var on_base: ?usize = null;
+    for (strata, 0..) |stratum, i| {
+        if (!std.mem.eql(u8, stratum.location_id, location_id)) continue;
+        if (depth >= stratum.depth_top and depth < stratum.depth_base) return i;
+        if (depth == stratum.depth_base) on_base = i;
+    }
+    return on_base;
+}
+
+fn appendTest(allocator: std.mem.Allocator, tests: *std.ArrayList([]const u8), id: []const u8) !void {
+    for (tests.items) |existing| {
+        if (std.mem.eql(u8, existing, id)) return;
+    }
+    try tests.append(try allocator.dupe(u8, id));
+}
+
+/// A FILE group row, held until the strata that reference it are read
+const FileRow = struct {
+    set: []const u8,
+    name: []const u8,
+    description: ?[]const u8,
+    remark: ?[]const u8,
+
+    fn deinit(self: FileRow, allocator: std.mem.Allocator) void {
+        allocator.free(self.set);
+        allocator.free(self.name);
+        if (self.description) |v| allocator.free(v);
+        if (self.remark) |v| allocator.free(v);
+    }
+};
+
+fn parseFileRow(allocator: std.mem.Allocator, headings: []const []const u8, fields: []const []const u8) !FileRow {
+    return FileRow{
+        .set = try allocator.dupe(u8, getFieldByHeading(headings, fields, "FILE_FSET") orelse ""),
+        .name = try allocator.dupe(u8, getFieldByHeading(headings, fields, "FILE_NAME") orelse ""),
+        .description = try dupOptional(allocator, getFieldByHeading(headings, fields, "FILE_DESC")),
+        .remark = try dupOptional(allocator, getFieldByHeading(headings, fields, "FILE_REM")),
+    };
+}
+
+/// "1.00-2.50 m" as written by ags_writer for photo depths
+fn parseDepthRange(maybe_value: ?[]const u8) ?[2]f64 {
+    const value = std.mem.trim(u8, maybe_value orelse return null, " m");
+    const dash = std.mem.indexOfScalar(u8, value, '-') orelse return null;
+    const top = std.fmt.parseFloat(f64, std.mem.trim(u8, value[0..dash], " ")) catch return null;
+    const bottom = std.fmt.parseFloat(f64, std.mem.trim(u8, value[dash + 1 ..], " ")) catch return null;
+    return .{ top, bottom };
+}
+
 fn parseProjectRow(allocator: std.mem.Allocator, headings: []const []const u8, fields: []const []const u8) !AgsProject {
     return AgsProject{
         .id = try allocator.dupe(u8, getFieldByHeading(headings, fields, "PROJ_ID") orelse ""),
@@ -173,7 +417,7 @@ fn parseStratumRow(
     fields: []const []const u8,
 ) !AgsStratum {
     const desc = getFieldByHeading(headings, fields, "GEOL_DESC") orelse "";
-    const parsed_desc: ?bs5930.SoilDescription = parser.parse(desc) catch null;
+    const parsed_desc: ?bs5930.GeologicalDescription = parser.parse(desc) catch null;
 
     const formation_from_field = getFieldByHeading(headings, fields, "GEOL_FORM");
     var formation: ?[]const u8 = null;
@@ -194,6 +438,7 @@ fn parseStratumRow(
         .geology_code = try dupOptional(allocator, getFieldByHeading(headings, fields, "GEOL_GEOL")),
         .formation = formation,
         .parsed = parsed_desc,
+        .file_set = try dupOptional(allocator, getFieldByHeading(headings, fields, "FILE_FSET")),
     };
 }
 
@@ -286,3 +531,92 @@ test "parse basic AGS groups" {
     try std.testing.expectEqual(@as(usize, 1), ags.strata.len);
     try std.testing.expect(ags.strata[0].parsed != null);
 }
+
+test "link core photos through FILE_FSET" {
+    const allocator = std.testing.allocator;
+    var parser = bs5930.Parser.init(allocator);
+
+    const sample =
+        "\"GROUP\",\"GEOL\"\n" ++
+        "\"HEADING\",\"LOCA_ID\",\"GEOL_TOP\",\"GEOL_BASE\",\"GEOL_DESC\",\"FILE_FSET\"\n" ++
+        "\"DATA\",\"BH01\",\"1.00\",\"3.00\",\"Firm brown CLAY\",\"PH1\"\n" ++
+        "\"GROUP\",\"FILE\"\n" ++
+        "\"HEADING\",\"FILE_FSET\",\"FILE_NAME\",\"FILE_DESC\",\"FILE_TYPE\",\"FILE_REM\"\n" ++
+        "\"DATA\",\"PH1\",\"BH01_box1.jpg\",\"Box 1\",\"JPG\",\"1.00-2.00 m\"\n" ++
+        "\"DATA\",\"PH1\",\"BH01_box2.jpg\",\"\",\"JPG\",\"\"\n";
+
+    var ags = try parseSlice(allocator, &parser, sample);
+    defer ags.deinit(allocator);
+
+    const photos = ags.strata[0].photos;
+    try std.testing.expectEqual(@as(usize, 2), photos.len);
+    try std.testing.expectEqualStrings("BH01_box1.jpg", photos[0].uri);
+    try std.testing.expectEqualStrings("Box 1", photos[0].caption.?);
+    try std.testing.expectApproxEqAbs(@as(f64, 2.0), photos[0].depth_bottom, 0.0001);
+    try std.testing.expect(photos[1].caption == null);
+    try std.testing.expectApproxEqAbs(@as(f64, 3.0), photos[1].depth_bottom, 0.0001);
+}
+
+test "link samples and laboratory tests to strata" {
+    const allocator = std.testing.allocator;
+    var parser = bs5930.Parser.init(allocator);
+
+    const sample =
+        "\"GROUP\",\"GEOL\"\n" ++
+        "\"HEADING\",\"LOCA_ID\",\"GEOL_TOP\",\"GEOL_BASE\",\"GEOL_DESC\"\n" ++
+        "\"DATA\",\"BH01\",\"0.00\",\"2.00\",\"Firm brown CLAY\"\n" ++
+        "\"DATA\",\"BH01\",\"2.00\",\"6.00\",\"Dense SAND\"\n" ++
+        "\"GROUP\",\"SAMP\"\n" ++
+        "\"HEADING\",\"LOCA_ID\",\"SAMP_TOP\",\"SAMP_REF\",\"SAMP_TYPE\",\"SAMP_ID\",\"SAMP_BASE\"\n" ++
+        "\"DATA\",\"BH01\",\"1.00\",\"1\",\"U\",\"\",\"1.45\"\n" ++
+        "\"DATA\",\"BH01\",\"2.00\",\"2\",\"B\",\"\",\"\"\n" ++
+        "\"GROUP\",\"LLPL\"\n" ++
+        "\"HEADING\",\"LOCA_ID\",\"SAMP_TOP\",\"SAMP_REF\",\"SAMP_TYPE\",\"LLPL_LL\"\n" ++
+        "\"DATA\",\"BH01\",\"1\",\"1\",\"U\",\"48\"\n" ++
+        "\"GROUP\",\"TRIT\"\n" ++
+        "\"HEADING\",\"LOCA_ID\",\"SAMP_TOP\",\"SAMP_REF\",\"SAMP_TYPE\",\"TRIT_CU\"\n" ++
+        "\"DATA\",\"BH01\",\"1.00\",\"1\",\"U\",\"65\"\n";
+
+    var ags = try parseSlice(allocator, &parser, sample);
+    defer ags.deinit(allocator);
+
+    try std.testing.expectEqual(@as(usize, 1), ags.strata[0].samples.len);
+    const undisturbed = ags.strata[0].samples[0];
+    try std.testing.expectEqualStrings("U", undisturbed.sample_type);
+    try std.testing.expectApproxEqAbs(@as(f64, 1.45), undisturbed.depth_base.?, 0.0001);
+    try std.testing.expectEqual(@as(usize, 2), undisturbed.test_ids.len);
+    try std.testing.expectEqualStrings("LLPL", undisturbed.test_ids[0]);
+    try std.testing.expectEqualStrings("TRIT", undisturbed.test_ids[1]);
+
+    try std.testing.expectEqual(@as(usize, 1), ags.strata[1].samples.len);
+    try std.testing.expect(ags.strata[1].samples[0].depth_base == null);
+    try std.testing.expectEqual(@as(usize, 0), ags.strata[1].samples[0].test_ids.len);
+}
+
+test "link laboratory rows without SAMP_ID to identified samples" {
+    const allocator = std.testing.allocator;
+    var parser = bs5930.Parser.init(allocator);
+
+    const sample =
+        "\"GROUP\",\"GEOL\"\n" ++
+        "\"HEADING\",\"LOCA_ID\",\"GEOL_TOP\",\"GEOL_BASE\",\"GEOL_DESC\"\n" ++
+        "\"DATA\",\"BH01\",\"0.00\",\"2.00\",\"Firm brown CLAY\"\n" ++
+        "\"GROUP\",\"SAMP\"\n" ++
+        "\"HEADING\",\"LOCA_ID\",\"SAMP_TOP\",\"SAMP_REF\",\"SAMP_TYPE\",\"SAMP_ID\"\n" ++
+        "\"DATA\",\"BH01\",\"1.00\",\"1\",\"U\",\"BH01-U1\"\n" ++
+        "\"GROUP\",\"LLPL\"\n" ++
+        "\"HEADING\",\"LOCA_ID\",\"SAMP_TOP\",\"SAMP_REF\",\"SAMP_TYPE\",\"SAMP_ID\",\"LLPL_LL\"\n" ++
+        "\"DATA\",\"BH01\",\"1.00\",\"1\",\"U\",\"\",\"48\"\n" ++
+        "\"GROUP\",\"TRIT\"\n" ++
+        "\"HEADING\",\"LOCA_ID\",\"SAMP_TOP\",\"SAMP_REF\",\"SAMP_TYPE\",\"SAMP_ID\",\"TRIT_CU\"\n" ++
+        "\"DATA\",\"BH01\",\"1.00\",\"1\",\"U\",\"BH01-U1\",\"65\"\n";
+
+    var ags = try parseSlice(allocator, &parser, sample);
+    defer ags.deinit(allocator);
+
+    const undisturbed = ags.strata[0].samples[0];
+    try std.testing.expectEqualStrings("BH01-U1", undisturbed.id.?);
+    try std.testing.expectEqual(@as(usize, 2), undisturbed.test_ids.len);
+    try std.testing.expectEqualStrings("LLPL", undisturbed.test_ids[0]);
+    try std.testing.expectEqualStrings("TRIT", undisturbed.test_ids[1]);
+}
@@ -41,10 +41,10 @@ pub fn writeEnhanced(allocator: std.mem.Allocator, ags: *const ags_reader.AgsFil
         try writeRow(w, &[_][]const u8{
             "HEADING",   "LOCA_ID",   "GEOL_TOP",  "GEOL_BASE", "GEOL_DESC", "GEOL_LEG",  "GEOL_GEOL", "GEOL_FORM",
             "GEOL_MTYP", "GEOL_CONS", "GEOL_DENS", "GEOL_PSOL", "GEOL_PRCK", "GEOL_RSTR", "GEOL_WETH", "GEOL_CONF",
-            "GEOL_WARN",
+            "GEOL_WARN", "FILE_FSET",
         });
-        try writeRow(w, &[_][]const u8{ "UNIT", "", "m", "m", "", "", "", "", "", "", "", "", "", "", "", "", "" });
-        try writeRow(w, &[_][]const u8{ "TYPE", "ID", "2DP", "2DP", "X", "PA", "PA", "X", "X", "X", "X", "X", "X", "X", "X", "2DP", "X" });
+        try writeRow(w, &[_][]const u8{ "UNIT", "", "m", "m", "", "", "", "", "", "", "", "", "", "", "", "", "", "" });
+        try writeRow(w, &[_][]const u8{ "TYPE", "ID", "2DP", "2DP", "X", "PA", "PA", "X", "X", "X", "X", "X", "X", "X", "X", "2DP", "X", "X" });
 
         for (ags.strata) |s| {
             const top = try std.fmt.allocPrint(allocator, "{d:.2}", .{s.depth_top});
@@ -78,6 +78,8 @@ pub fn writeEnhanced(allocator: std.mem.Allocator, ags: *const ags_reader.AgsFil
             defer if (conf_value.len > 0) allocator.free(conf_value);
             defer if (warn_value.len > 0) allocator.free(warn_value);
 
+            var set_buf: [64]u8 = undefined;
+
             try writeRow(w, &[_][]const u8{
                 "DATA",
                 s.location_id,
@@ -96,13 +98,83 @@ pub fn writeEnhanced(allocator: std.mem.Allocator, ags: *const ags_reader.AgsFil
                 weth,
                 conf_value,
                 warn_value,
+                fileSet(&set_buf, s),
             });
         }
     }
 
+    if (hasSamples(ags.strata)) {
+        try w.writeByte('\n');
+        try writeRow(w, &[_][]const u8{ "GROUP", "SAMP" });
+        try writeRow(w, &[_][]const u8{ "HEADING", "LOCA_ID", "SAMP_TOP", "SAMP_REF", "SAMP_TYPE", "SAMP_ID", "SAMP_BASE", "SAMP_REM" });
+        try writeRow(w, &[_][]const u8{ "UNIT", "", "m", "", "", "", "m", "" });
+        try writeRow(w, &[_][]const u8{ "TYPE", "ID", "2DP", "X", "PA", "ID", "2DP", "X" });
+
+        for (ags.strata) |s| {
+            for (s.samples) |sample| {
+                const top = try std.fmt.allocPrint(allocator, "{d:.2}", .{sample.depth_top});
+                defer allocator.free(top);
+                const base = if (sample.depth_base) |depth| try std.fmt.allocPrint(allocator, "{d:.2}", .{depth}) else "";
+                defer if (sample.depth_base != null) allocator.free(base);
+                // SAMP has no test heading, so the laboratory groups go in the remark
+                const tests = try std.mem.join(allocator, "; ", sample.test_ids);
+                defer allocator.free(tests);
+                const remark = if (tests.len > 0) try std.fmt.allocPrint(allocator, "Tests: {s}", .{tests}) else "";
+                defer if (tests.len > 0) allocator.free(remark);
+
+                try writeRow(w, &[_][]const u8{ "DATA", s.location_id, top, sample.reference, sample.sample_type, sample.id orelse "", base, remark });
+            }
+        }
+    }
+
+    if (hasPhotos(ags.strata)) {
+        try w.writeByte('\n');
+        try writeRow(w, &[_][]const u8{ "GROUP", "FILE" });
+        try writeRow(w, &[_][]const u8{ "HEADING", "FILE_FSET", "FILE_NAME", "FILE_DESC", "FILE_TYPE", "FILE_REM" });
+        try writeRow(w, &[_][]const u8{ "UNIT", "", "", "", "", "" });
+        try writeRow(w, &[_][]const u8{ "TYPE", "X", "X", "X", "X", "X" });
+
+        for (ags.strata) |s| {
+            var set_buf: [64]u8 = undefined;
+            const set = fileSet(&set_buf, s);
+            for (s.photos) |photo| {
+                // Depths go in the remark; FILE has no depth headings
+                const depths = try std.fmt.allocPrint(allocator, "{d:.2}-{d:.2} m", .{ photo.depth_top, photo.depth_bottom });
+                defer allocator.free(depths);
+                const extension = std.fs.path.extension(photo.uri);
+                var type_buf: [16]u8 = undefined;
+                const file_type = if (extension.len > 1 and extension.len <= type_buf.len) std.ascii.upperString(&type_buf, extension[1..]) else "";
+
+                try writeRow(w, &[_][]const u8{ "DATA", set, photo.uri, photo.caption orelse "", file_type, depths });
+            }
+        }
+    }
+
     return out.toOwnedSlice();
 }
 
+fn hasSamples(strata: []const ags_reader.AgsStratum) bool {
+    for (strata) |s| {
+        if (s.samples.len > 0) return true;
+    }
+    return false;
+}
+
+fn hasPhotos(strata: []const ags_reader.AgsStratum) bool {
+    for (strata) |s| {
+        if (s.photos.len > 0) return true;
+    }
+    return false;
+}
+
+/// The stratum's FILE_FSET, or one made from its location and top depth
+/// when it has photos but no set yet
+fn fileSet(buf: []u8, s: ags_reader.AgsStratum) []const u8 {
+    if (s.file_set) |set| return set;
+    if (s.photos.len == 0) return "";
+    return std.fmt.bufPrint(buf, "{s}/{d:.2}", .{ s.location_id, s.depth_top }) catch s.location_id;
+}
+
 fn writeRow(writer: anytype, fields: []const []const u8) !void {
     for (fields, 0..) |field, i| {
         if (i > 0) try writer.writeByte(',');
@@ -134,3 +206,60 @@ test "write enhanced GEOL headings" {
 
     try std.testing.expect(output.len == 0);
 }
+
+test "write photo file group" {
+    const allocator = std.testing.allocator;
+
+    const photos = [_]ags_reader.PhotoRef{
+        .{ .uri = "BH01_box1.jpg", .depth_top = 1.0, .depth_bottom = 2.0, .caption = "Box 1" },
+    };
+    var strata = [_]ags_reader.AgsStratum{.{
+        .location_id = "BH01",
+        .depth_top = 1.0,
+        .depth_base = 3.0,
+        .description = "Firm CLAY",
+        .legend_code = null,
+        .geology_code = null,
+        .formation = null,
+        .parsed = null,
+        .photos = &photos,
+    }};
+    const ags = ags_reader.AgsFile{
+        .locations = &[_]ags_reader.AgsLocation{},
+        .strata = &strata,
+    };
+
+    const output = try writeEnhanced(allocator, &ags);
+    defer allocator.free(output);
+
+    try std.testing.expect(std.mem.indexOf(u8, output, "\"FILE_FSET\"") != null);
+    try std.testing.expect(std.mem.indexOf(u8, output, "\"DATA\",\"BH01/1.00\",\"BH01_box1.jpg\",\"Box 1\",\"JPG\",\"1.00-2.00 m\"") != null);
+}
+
+test "write sample group" {
+    const allocator = std.testing.allocator;
+
+    const samples = [_]ags_reader.SampleRef{
+        .{ .reference = "1", .sample_type = "U", .depth_top = 1.0, .depth_base = 1.45, .test_ids = &.{ "LLPL", "TRIT" } },
+    };
+    var strata = [_]ags_reader.AgsStratum{.{
+        .location_id = "BH01",
+        .depth_top = 0.0,
+        .depth_base = 2.0,
+        .description = "Firm CLAY",
+        .legend_code = null,
+        .geology_code = null,
+        .formation = null,
+        .parsed = null,
+        .samples = &samples,
+    }};
+    const ags = ags_reader.AgsFile{
+        .locations = &[_]ags_reader.AgsLocation{},
+        .strata = &strata,
+    };
+
+    const output = try writeEnhanced(allocator, &ags);
+    defer allocator.free(output);
+
+    try std.testing.expect(std.mem.indexOf(u8, output, "\"DATA\",\"BH01\",\"1.00\",\"1\",\"U\",\"\",\"1.45\",\"Tests: LLPL; TRIT\"") != null);
+}
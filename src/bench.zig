@@ -0,0 +1,79 @@
+// Parser benchmarks. Output follows the Go benchmark format so results from
+// several runs can be compared with `benchstat old.txt new.txt`:
+//
+//     zig build bench -Doptimize=ReleaseFast > new.txt
+//
+// Each benchmark runs per backend and batch size, and reports ns/op, B/op and
+// allocs/op. An "op" is parsing one whole batch.
+const std = @import("std");
+const bs5930 = @import("parser/bs5930.zig");
+const CountingAllocator = @import("counting_allocator.zig").CountingAllocator;
+
+const batch_sizes = [_]usize{ 1, 10, 100, 1000 };
+
+const Backend = enum {
+    // Parser.parse, as used by Zig callers
+    native,
+    // Parse plus JSON serialisation, the path taken by the language bindings
+    json,
+};
+
+fn runBatch(allocator: std.mem.Allocator, descriptions: []const []const u8, backend: Backend, batch_size: usize) !void {
+    var parser = bs5930.Parser.init(allocator);
+    for (0..batch_size) |i| {
+        const result = try parser.parse(descriptions[i % descriptions.len]);
+        defer result.deinit(allocator);
+
+        if (backend == .json) {
+            const json = try result.toJson(allocator);
+            allocator.free(json);
+        }
+    }
+}
+
+pub fn main() !void {
+    var gpa = std.heap.GeneralPurposeAllocator(.{}){};
+    defer _ = gpa.deinit();
+
+    // Batches cycle through the example corpus, so larger ones see the
+    // variety of real logs rather than a handful of strings
+    const descriptions = try bs5930.loadCorpus(gpa.allocator());
+    defer gpa.allocator().free(descriptions);
+
+    var stdout_buffer = std.io.bufferedWriter(std.io.getStdOut().writer());
+    const stdout = stdout_buffer.writer();
+
+    try stdout.print("goos: {s}\ngoarch: {s}\npkg: litholog\n", .{ @tagName(@import("builtin").os.tag), @tagName(@import("builtin").cpu.arch) });
+
+    // Aim for roughly a second per benchmark, like `go test -bench`
+    const target_ns: u64 = std.time.ns_per_s;
+
+    for (std.enums.values(Backend)) |backend| {
+        for (batch_sizes) |batch_size| {
+            var counter = CountingAllocator{ .child = gpa.allocator() };
+            const allocator = counter.allocator();
+
+            // Warm up and size the iteration count from one timed run
+            var timer = try std.time.Timer.start();
+            try runBatch(allocator, descriptions, backend, batch_size);
+            const first_ns = @max(timer.read(), 1);
+            const iterations: u64 = @max(1, target_ns / first_ns);
+
+            counter.reset();
+            timer.reset();
+            for (0..iterations) |_| try runBatch(allocator, descriptions, backend, batch_size);
+            const elapsed_ns = timer.read();
+
+            try stdout.print("BenchmarkParse/backend={s}/batch={d} \t{d}\t{d} ns/op\t{d} B/op\t{d} allocs/op\n", .{
+                @tagName(backend),
+                batch_size,
+                iterations,
+                elapsed_ns / iterations,
+                counter.bytes / iterations,
+                counter.allocs / iterations,
+            });
+        }
+    }
+
+    try stdout_buffer.flush();
+}
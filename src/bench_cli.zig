@@ -0,0 +1,329 @@
+const std = @import("std");
+const builtin = @import("builtin");
+const bs5930 = @import("parser/bs5930.zig");
+const version = @import("version.zig");
+const CsvProcessor = @import("csv_processor.zig").CsvProcessor;
+const CountingAllocator = @import("counting_allocator.zig").CountingAllocator;
+
+pub const Options = struct {
+    input_path: []const u8,
+    /// Header name, or a 0-based index with `has_header = false`. Defaults to
+    /// "Description", or the first column when there is no header.
+    column: ?[]const u8 = null,
+    has_header: bool = true,
+    /// Passes over the input, so small files still give stable percentiles
+    repeat: usize = 1,
+};
+
+/// What `litholog bench` measured, for sizing a processing job
+pub const Report = struct {
+    rows: usize,
+    failures: usize,
+    elapsed_ns: u64,
+    /// Native parse time per row
+    p50_ns: u64,
+    p90_ns: u64,
+    p99_ns: u64,
+    max_ns: u64,
+    parse_ns: u64,
+    /// Time spent serialising results to JSON, which the C API and language
+    /// bindings do for every row on top of the native parse
+    binding_ns: u64,
+    bytes_per_row: usize,
+    allocs_per_row: usize,
+    peak_bytes: usize,
+
+    pub fn rowsPerSecond(self: Report) f64 {
+        if (self.elapsed_ns == 0) return 0;
+        return @as(f64, @floatFromInt(self.rows)) * std.time.ns_per_s / @as(f64, @floatFromInt(self.elapsed_ns));
+    }
+
+    /// Fraction of per-row time the bindings add over the native parse
+    pub fn bindingShare(self: Report) f64 {
+        const total = self.parse_ns + self.binding_ns;
+        if (total == 0) return 0;
+        return @as(f64, @floatFromInt(self.binding_ns)) / @as(f64, @floatFromInt(total));
+    }
+
+    pub fn writeJson(self: Report, writer: anytype, options: Options) !void {
+        try writer.writeAll("{\"input\":");
+        try std.json.stringify(options.input_path, .{}, writer);
+        try writer.print(",\"version\":\"{s}\",\"platform\":\"{s}/{s}\",\"optimize\":\"{s}\"", .{
+            version.VERSION_STRING,
+            @tagName(builtin.os.tag),
+            @tagName(builtin.cpu.arch),
+            @tagName(builtin.mode),
+        });
+        try writer.print(",\"repeat\":{d},\"rows\":{d},\"failures\":{d},\"elapsed_ms\":{d:.3},\"rows_per_second\":{d:.1}", .{
+            options.repeat,
+            self.rows,
+            self.failures,
+            nsToMs(self.elapsed_ns),
+            self.rowsPerSecond(),
+        });
+        try writer.print(",\"latency_us\":{{\"p50\":{d:.2},\"p90\":{d:.2},\"p99\":{d:.2},\"max\":{d:.2}}}", .{
+            nsToUs(self.p50_ns),
+            nsToUs(self.p90_ns),
+            nsToUs(self.p99_ns),
+            nsToUs(self.max_ns),
+        });
+        try writer.print(",\"binding_overhead_share\":{d:.4}", .{self.bindingShare()});
+        try writer.print(",\"memory\":{{\"bytes_per_row\":{d},\"allocs_per_row\":{d},\"peak_bytes\":{d}}}}}\n", .{
+            self.bytes_per_row,
+            self.allocs_per_row,
+            self.peak_bytes,
+        });
+    }
+
+    pub fn writeText(self: Report, writer: anytype, options: Options) !void {
+        try writer.print("Benchmark: {s} ({d} rows, {d} failed, {d} pass(es))\n\n", .{ options.input_path, self.rows, self.failures, options.repeat });
+        try writer.print("  Throughput         {d:.0} rows/s ({d:.1} ms total)\n", .{ self.rowsPerSecond(), nsToMs(self.elapsed_ns) });
+        try writer.print("  Latency p50/p90/p99 {d:.1} / {d:.1} / {d:.1} us (max {d:.1})\n", .{
+            nsToUs(self.p50_ns),
+            nsToUs(self.p90_ns),
+            nsToUs(self.p99_ns),
+            nsToUs(self.max_ns),
+        });
+        try writer.print("  Binding overhead   {d:.1}% of parse + JSON time\n", .{self.bindingShare() * 100});
+        try writer.print("  Memory             {d} B and {d} allocations per row, peak {d} B\n", .{
+            self.bytes_per_row,
+            self.allocs_per_row,
+            self.peak_bytes,
+        });
+        if (builtin.mode == .Debug) {
+            try writer.writeAll("\nNote: this is a debug build; use -Doptimize=ReleaseFast for representative numbers.\n");
+        }
+    }
+};
+
+/// `litholog bench --input FILE.csv [--column NAME] [--repeat N] [--no-header] [--json]`,
+/// or `--corpus` in place of `--input` to time the built-in example corpus
+pub fn handle(allocator: std.mem.Allocator, args: []const [:0]u8, json_output: bool) !void {
+    var input_path: ?[]const u8 = null;
+    var use_corpus = false;
+    var options = Options{ .input_path = undefined };
+
+    var i: usize = 0;
+    while (i < args.len) : (i += 1) {
+        const arg = args[i];
+        if (std.mem.eql(u8, arg, "--input") or std.mem.eql(u8, arg, "-i")) {
+            if (i + 1 >= args.len) return error.MissingInputArgument;
+            i += 1;
+            input_path = args[i];
+        } else if (std.mem.eql(u8, arg, "--column")) {
+            if (i + 1 >= args.len) return error.MissingColumnArgument;
+            i += 1;
+            options.column = args[i];
+        } else if (std.mem.eql(u8, arg, "--repeat")) {
+            if (i + 1 >= args.len) return error.MissingRepeatArgument;
+            i += 1;
+            options.repeat = @max(1, try std.fmt.parseInt(usize, args[i], 10));
+        } else if (std.mem.eql(u8, arg, "--no-header")) {
+            options.has_header = false;
+        } else if (std.mem.eql(u8, arg, "--corpus")) {
+            use_corpus = true;
+        } else if (!std.mem.startsWith(u8, arg, "-") and input_path == null) {
+            input_path = arg;
+        } else {
+            return error.UnknownOption;
+        }
+    }
+    if (use_corpus) {
+        options.input_path = "corpus";
+        const descriptions = try bs5930.loadCorpus(allocator);
+        defer allocator.free(descriptions);
+        return writeReport(try run(allocator, descriptions, options.repeat), options, json_output);
+    }
+    options.input_path = input_path orelse return error.MissingInputArgument;
+
+    const descriptions = try loadDescriptions(allocator, options);
+    defer {
+        for (descriptions) |description| allocator.free(description);
+        allocator.free(descriptions);
+    }
+    try writeReport(try run(allocator, descriptions, options.repeat), options, json_output);
+}
+
+fn writeReport(report: Report, options: Options, json_output: bool) !void {
+    const out = std.io.getStdOut().writer();
+    if (json_output) {
+        try report.writeJson(out, options);
+    } else {
+        try report.writeText(out, options);
+    }
+}
+
+/// Time each description through the native parser and then JSON
+/// serialisation, `repeat` times over
+pub fn run(allocator: std.mem.Allocator, descriptions: []const []const u8, repeat: usize) !Report {
+    const latencies = try allocator.alloc(u64, descriptions.len * repeat);
+    defer allocator.free(latencies);
+
+    var counter = CountingAllocator{ .child = allocator };
+    const counted = counter.allocator();
+    var parser = bs5930.Parser.init(counted);
+
+    var rows: usize = 0;
+    var failures: usize = 0;
+    var parse_ns: u64 = 0;
+    var binding_ns: u64 = 0;
+
+    var total = try std.time.Timer.start();
+    var timer = try std.time.Timer.start();
+    for (0..repeat) |_| {
+        for (descriptions) |description| {
+            timer.reset();
+            const result = parser.parse(description) catch {
+                failures += 1;
+                continue;
+            };
+            defer result.deinit(counted);
+            const parsed_ns = timer.read();
+
+            const json = try result.toJson(counted);
+            counted.free(json);
+            const serialised_ns = timer.read() - parsed_ns;
+
+            latencies[rows] = parsed_ns;
+            rows += 1;
+            parse_ns += parsed_ns;
+            binding_ns += serialised_ns;
+        }
+    }
+    const elapsed_ns = total.read();
+
+    const timed = latencies[0..rows];
+    std.mem.sort(u64, timed, {}, std.sort.asc(u64));
+    const attempts = @max(1, rows + failures);
+    return Report{
+        .rows = rows,
+        .failures = failures,
+        .elapsed_ns = elapsed_ns,
+        .p50_ns = percentile(timed, 50),
+        .p90_ns = percentile(timed, 90),
+        .p99_ns = percentile(timed, 99),
+        .max_ns = if (timed.len > 0) timed[timed.len - 1] else 0,
+        .parse_ns = parse_ns,
+        .binding_ns = binding_ns,
+        .bytes_per_row = counter.bytes / attempts,
+        .allocs_per_row = counter.allocs / attempts,
+        .peak_bytes = counter.peak,
+    };
+}
+
+fn nsToMs(ns: u64) f64 {
+    return @as(f64, @floatFromInt(ns)) / std.time.ns_per_ms;
+}
+
+fn nsToUs(ns: u64) f64 {
+    return @as(f64, @floatFromInt(ns)) / std.time.ns_per_us;
+}
+
+/// Nearest-rank percentile of sorted samples
+fn percentile(sorted: []const u64, p: usize) u64 {
+    if (sorted.len == 0) return 0;
+    const rank = (sorted.len * p + 99) / 100;
+    return sorted[@max(rank, 1) - 1];
+}
+
+fn loadDescriptions(allocator: std.mem.Allocator, options: Options) ![][]const u8 {
+    const content = try std.fs.cwd().readFileAlloc(allocator, options.input_path, 100 * 1024 * 1024);
+    defer allocator.free(content);
+    return readDescriptions(allocator, content, options);
+}
+
+/// The description column of each CSV row. Caller owns the strings and slice.
+fn readDescriptions(allocator: std.mem.Allocator, content: []const u8, options: Options) ![][]const u8 {
+    var processor = CsvProcessor.init(allocator);
+    var descriptions = std.ArrayList([]const u8).init(allocator);
+    errdefer {
+        for (descriptions.items) |description| allocator.free(description);
+        descriptions.deinit();
+    }
+
+    const column_name = options.column orelse "Description";
+    var column_index: ?usize = null;
+    if (!options.has_header) {
+        column_index = if (options.column) |column| std.fmt.parseInt(usize, column, 10) catch return error.InvalidInputColumn else 0;
+    }
+
+    var lines = std.mem.splitScalar(u8, content, '\n');
+    while (lines.next()) |line| {
+        const trimmed = std.mem.trim(u8, line, " \t\r");
+        if (trimmed.len == 0) continue;
+
+        const columns = try processor.parseCsvLine(trimmed, ',');
+        defer {
+            for (columns) |col| allocator.free(col);
+            allocator.free(columns);
+        }
+
+        if (column_index == null) {
+            for (columns, 0..) |col, idx| {
+                if (std.mem.eql(u8, col, column_name)) column_index = idx;
+            }
+            if (column_index == null) return error.InputColumnNotFound;
+            continue;
+        }
+        if (column_index.? >= columns.len) continue;
+        try descriptions.append(try allocator.dupe(u8, columns[column_index.?]));
+    }
+    return descriptions.toOwnedSlice();
+}
+
+test "percentiles by nearest rank" {
+    const samples = [_]u64{ 1, 2, 3, 4, 5, 6, 7, 8, 9, 10 };
+    try std.testing.expectEqual(@as(u64, 5), percentile(&samples, 50));
+    try std.testing.expectEqual(@as(u64, 9), percentile(&samples, 90));
+    try std.testing.expectEqual(@as(u64, 10), percentile(&samples, 99));
+    try std.testing.expectEqual(@as(u64, 0), percentile(&.{}, 50));
+}
+
+test "benchmark a few descriptions" {
+    const descriptions = [_][]const u8{ "Firm brown CLAY", "Dense SAND", "Strong LIMESTONE" };
+    const report = try run(std.testing.allocator, &descriptions, 2);
+
+    try std.testing.expectEqual(@as(usize, 6), report.rows);
+    try std.testing.expectEqual(@as(usize, 0), report.failures);
+    try std.testing.expect(report.p50_ns <= report.p99_ns);
+    try std.testing.expect(report.allocs_per_row > 0);
+    try std.testing.expect(report.bindingShare() >= 0 and report.bindingShare() <= 1);
+
+    var json = std.ArrayList(u8).init(std.testing.allocator);
+    defer json.deinit();
+    try report.writeJson(json.writer(), .{ .input_path = "logs.csv", .repeat = 2 });
+    try std.testing.expect(std.mem.startsWith(u8, json.items, "{\"input\":\"logs.csv\""));
+    try std.testing.expect(std.mem.indexOf(u8, json.items, "\"rows\":6") != null);
+}
+
+test "read descriptions with and without a header" {
+    const allocator = std.testing.allocator;
+
+    const named = try readDescriptions(allocator, "Hole,Description\nBH1,Firm CLAY\nBH2,Dense SAND\n", .{ .input_path = "logs.csv" });
+    defer {
+        for (named) |description| allocator.free(description);
+        allocator.free(named);
+    }
+    try std.testing.expectEqual(@as(usize, 2), named.len);
+    try std.testing.expectEqualStrings("Firm CLAY", named[0]);
+
+    // --no-header --column 1: the first row is data, not a header
+    const indexed = try readDescriptions(allocator, "BH1,Firm CLAY\nBH2,Dense SAND\n", .{ .input_path = "logs.csv", .column = "1", .has_header = false });
+    defer {
+        for (indexed) |description| allocator.free(description);
+        allocator.free(indexed);
+    }
+    try std.testing.expectEqual(@as(usize, 2), indexed.len);
+    try std.testing.expectEqualStrings("Firm CLAY", indexed[0]);
+    try std.testing.expectEqualStrings("Dense SAND", indexed[1]);
+
+    // --no-header alone reads the first column
+    const first = try readDescriptions(allocator, "Firm CLAY,BH1\n", .{ .input_path = "logs.csv", .has_header = false });
+    defer {
+        for (first) |description| allocator.free(description);
+        allocator.free(first);
+    }
+    try std.testing.expectEqualStrings("Firm CLAY", first[0]);
+
+    try std.testing.expectError(error.InvalidInputColumn, readDescriptions(allocator, "Firm CLAY\n", .{ .input_path = "logs.csv", .column = "Description", .has_header = false }));
+}
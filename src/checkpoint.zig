@@ -0,0 +1,86 @@
+const std = @import("std");
+
+/// How far a batch job has got: every input line before `line` is done and
+/// the output is exactly `output_size` bytes long
+pub const Checkpoint = struct {
+    line: u64,
+    output_size: u64,
+};
+
+/// Persists batch progress so a long job can resume after a crash
+pub const Checkpointer = struct {
+    ptr: *anyopaque,
+    vtable: *const VTable,
+
+    pub const VTable = struct {
+        load: *const fn (ptr: *anyopaque) anyerror!?Checkpoint,
+        save: *const fn (ptr: *anyopaque, checkpoint: Checkpoint) anyerror!void,
+        clear: *const fn (ptr: *anyopaque) anyerror!void,
+    };
+
+    /// The saved checkpoint, or null to start from the beginning
+    pub fn load(self: Checkpointer) anyerror!?Checkpoint {
+        return self.vtable.load(self.ptr);
+    }
+
+    pub fn save(self: Checkpointer, checkpoint: Checkpoint) anyerror!void {
+        return self.vtable.save(self.ptr, checkpoint);
+    }
+
+    /// Forget progress once the job has finished
+    pub fn clear(self: Checkpointer) anyerror!void {
+        return self.vtable.clear(self.ptr);
+    }
+};
+
+/// Keeps the checkpoint in a small text file ("<line> <output_size>"),
+/// replaced atomically on each save
+pub const FileCheckpointer = struct {
+    path: []const u8,
+
+    pub fn init(path: []const u8) FileCheckpointer {
+        return FileCheckpointer{ .path = path };
+    }
+
+    pub fn checkpointer(self: *FileCheckpointer) Checkpointer {
+        return Checkpointer{
+            .ptr = self,
+            .vtable = &.{ .load = loadFn, .save = saveFn, .clear = clearFn },
+        };
+    }
+
+    fn loadFn(ptr: *anyopaque) anyerror!?Checkpoint {
+        const self: *FileCheckpointer = @ptrCast(@alignCast(ptr));
+
+        var buf: [64]u8 = undefined;
+        const text = std.fs.cwd().readFile(self.path, &buf) catch |err| switch (err) {
+            error.FileNotFound => return null,
+            else => return err,
+        };
+
+        var fields = std.mem.tokenizeAny(u8, text, " \r\n");
+        const line = fields.next() orelse return error.InvalidCheckpoint;
+        const output_size = fields.next() orelse return error.InvalidCheckpoint;
+        return Checkpoint{
+            .line = std.fmt.parseInt(u64, line, 10) catch return error.InvalidCheckpoint,
+            .output_size = std.fmt.parseInt(u64, output_size, 10) catch return error.InvalidCheckpoint,
+        };
+    }
+
+    fn saveFn(ptr: *anyopaque, checkpoint: Checkpoint) anyerror!void {
+        const self: *FileCheckpointer = @ptrCast(@alignCast(ptr));
+
+        var atomic = try std.fs.cwd().atomicFile(self.path, .{});
+        defer atomic.deinit();
+        try atomic.file.writer().print("{d} {d}\n", .{ checkpoint.line, checkpoint.output_size });
+        try atomic.finish();
+    }
+
+    fn clearFn(ptr: *anyopaque) anyerror!void {
+        const self: *FileCheckpointer = @ptrCast(@alignCast(ptr));
+        std.fs.cwd().deleteFile(self.path) catch |err| switch (err) {
+            error.FileNotFound => {},
+            else => return err,
+        };
+    }
+};
@@ -0,0 +1,150 @@
+const std = @import("std");
+
+pub const State = enum {
+    closed, // calls go through
+    open, // calls are refused until the cooldown ends
+    half_open, // one trial call decides whether to close again
+
+    pub fn toString(self: State) []const u8 {
+        return switch (self) {
+            .closed => "closed",
+            .open => "open",
+            .half_open => "half_open",
+        };
+    }
+};
+
+pub const max_window = 256;
+
+pub const Options = struct {
+    /// Recent calls the failure rate is taken over, up to `max_window`
+    window: usize = 50,
+    /// Failure rate (0-1) that opens the breaker
+    failure_threshold: f32 = 0.5,
+    /// Calls needed in the window before the rate counts
+    min_calls: usize = 10,
+    /// How long to refuse calls before trying again
+    cooldown_ms: i64 = 30_000,
+};
+
+pub const Health = struct {
+    state: State,
+    failure_rate: f32,
+    calls: usize,
+};
+
+/// Stops calling a backend that keeps failing, e.g. a parser erroring on
+/// every description, and reports its health. Safe to share between threads.
+pub const CircuitBreaker = struct {
+    options: Options = .{},
+    mutex: std.Thread.Mutex = .{},
+    state: State = .closed,
+    outcomes: [max_window]bool = undefined, // true = failure
+    next: usize = 0,
+    calls: usize = 0,
+    failures: usize = 0,
+    opened_at: i64 = 0,
+    trial_in_flight: bool = false,
+
+    pub fn init(options: Options) CircuitBreaker {
+        return CircuitBreaker{ .options = options };
+    }
+
+    /// Whether a call may go ahead now
+    pub fn allow(self: *CircuitBreaker) bool {
+        return self.allowAt(std.time.milliTimestamp());
+    }
+
+    pub fn allowAt(self: *CircuitBreaker, now_ms: i64) bool {
+        self.mutex.lock();
+        defer self.mutex.unlock();
+
+        switch (self.state) {
+            .closed => return true,
+            .open => {
+                if (now_ms - self.opened_at < self.options.cooldown_ms) return false;
+                self.state = .half_open;
+                self.trial_in_flight = true;
+                return true;
+            },
+            .half_open => {
+                if (self.trial_in_flight) return false;
+                self.trial_in_flight = true;
+                return true;
+            },
+        }
+    }
+
+    /// Record how an allowed call went
+    pub fn record(self: *CircuitBreaker, ok: bool) void {
+        self.recordAt(ok, std.time.milliTimestamp());
+    }
+
+    pub fn recordAt(self: *CircuitBreaker, ok: bool, now_ms: i64) void {
+        self.mutex.lock();
+        defer self.mutex.unlock();
+
+        if (self.state == .half_open) {
+            self.trial_in_flight = false;
+            if (ok) {
+                self.state = .closed;
+                self.calls = 0;
+                self.failures = 0;
+                self.next = 0;
+            } else {
+                self.state = .open;
+                self.opened_at = now_ms;
+            }
+            return;
+        }
+
+        const window = std.math.clamp(self.options.window, 1, max_window);
+        if (self.calls == window) {
+            if (self.outcomes[self.next]) self.failures -= 1;
+        } else {
+            self.calls += 1;
+        }
+        self.outcomes[self.next] = !ok;
+        if (!ok) self.failures += 1;
+        self.next = (self.next + 1) % window;
+
+        if (self.state == .closed and self.calls >= self.options.min_calls and self.failureRate() >= self.options.failure_threshold) {
+            self.state = .open;
+            self.opened_at = now_ms;
+        }
+    }
+
+    pub fn health(self: *CircuitBreaker) Health {
+        self.mutex.lock();
+        defer self.mutex.unlock();
+        return Health{ .state = self.state, .failure_rate = self.failureRate(), .calls = self.calls };
+    }
+
+    fn failureRate(self: *const CircuitBreaker) f32 {
+        if (self.calls == 0) return 0;
+        return @as(f32, @floatFromInt(self.failures)) / @as(f32, @floatFromInt(self.calls));
+    }
+};
+
+test "breaker opens on repeated failures and recovers after cooldown" {
+    var breaker = CircuitBreaker.init(.{ .window = 10, .min_calls = 4, .failure_threshold = 0.5, .cooldown_ms = 1000 });
+
+    breaker.recordAt(true, 0);
+    breaker.recordAt(false, 0);
+    breaker.recordAt(true, 0);
+    try std.testing.expect(breaker.allowAt(0));
+    breaker.recordAt(false, 10);
+    try std.testing.expectEqual(State.open, breaker.health().state);
+    try std.testing.expect(!breaker.allowAt(500));
+
+    // One trial after the cooldown; others wait for its outcome
+    try std.testing.expect(breaker.allowAt(1010));
+    try std.testing.expect(!breaker.allowAt(1011));
+    breaker.recordAt(false, 1020);
+    try std.testing.expect(!breaker.allowAt(1500));
+
+    try std.testing.expect(breaker.allowAt(2020));
+    breaker.recordAt(true, 2030);
+    try std.testing.expectEqual(State.closed, breaker.health().state);
+    try std.testing.expectEqual(@as(usize, 0), breaker.health().calls);
+}
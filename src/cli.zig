@@ -1,6 +1,8 @@
 const std = @import("std");
 const bs5930 = @import("parser/bs5930.zig");
 const builtin = @import("builtin");
+const build_options = @import("build_options");
+const CsvProcessor = @import("csv_processor.zig").CsvProcessor;
 
 pub const CliArgs = struct {
     description: ?[]const u8 = null,
@@ -10,14 +12,21 @@ pub const CliArgs = struct {
     csv_column: ?[]const u8 = null,
     csv_output_columns: ?[]const []const u8 = null,
     csv_no_header: bool = false,
+    checkpoint_path: ?[]const u8 = null,
     output_mode: OutputMode = .compact,
     output_mode_explicit: bool = false,
     json_input_path: ?[]const u8 = null,
     json_format: JsonFormat = .standard,
+    json_format_explicit: bool = false,
     help: bool = false,
     no_color: bool = false,
+    capitalization_policy: ?bs5930.CapitalizationPolicy = null,
+    profile_path: ?[]const u8 = null,
+    report_path: ?[]const u8 = null,
+    suppressed_rules: ?[]const []const u8 = null,
     check_anomalies: bool = false,
     check_compliance: bool = false,
+    lint: bool = false,
     generate_mode: ?GenerateMode = null,
     generate_count: u32 = 1,
     generate_seed: u64 = 0,
@@ -46,6 +55,16 @@ pub const CliArgs = struct {
         verbose,
         pretty,
         summary,
+        conll, // BIO token labels for NER training data
+
+        pub fn fromString(str: []const u8) ?OutputMode {
+            if (std.mem.eql(u8, str, "compact")) return .compact;
+            if (std.mem.eql(u8, str, "verbose")) return .verbose;
+            if (std.mem.eql(u8, str, "pretty")) return .pretty;
+            if (std.mem.eql(u8, str, "summary")) return .summary;
+            if (std.mem.eql(u8, str, "conll")) return .conll;
+            return null;
+        }
     };
 
     pub const GenerateMode = enum {
@@ -66,6 +85,11 @@ pub const CliArgs = struct {
                 allocator.free(cols);
             }
         }
+        if (self.suppressed_rules) |rules| {
+            if (self.allocator) |allocator| {
+                allocator.free(rules);
+            }
+        }
     }
 };
 
@@ -312,6 +336,45 @@ pub const Cli = struct {
                 result.check_anomalies = true;
             } else if (std.mem.eql(u8, arg, "--check-compliance") or std.mem.eql(u8, arg, "--compliance")) {
                 result.check_compliance = true;
+            } else if (std.mem.eql(u8, arg, "--lint")) {
+                result.lint = true;
+            } else if (std.mem.eql(u8, arg, "--capitalization")) {
+                if (i + 1 >= args.len) {
+                    return error.MissingCapitalizationArgument;
+                }
+                i += 1;
+                result.capitalization_policy = bs5930.CapitalizationPolicy.fromString(args[i]) orelse return error.InvalidCapitalizationPolicy;
+            } else if (std.mem.eql(u8, arg, "--profile")) {
+                if (i + 1 >= args.len) {
+                    return error.MissingProfileArgument;
+                }
+                i += 1;
+                result.profile_path = args[i];
+            } else if (std.mem.eql(u8, arg, "--report")) {
+                if (i + 1 >= args.len) {
+                    return error.MissingReportArgument;
+                }
+                i += 1;
+                result.report_path = args[i];
+            } else if (std.mem.eql(u8, arg, "--suppress")) {
+                if (i + 1 >= args.len) {
+                    return error.MissingSuppressArgument;
+                }
+                i += 1;
+                // Parse comma-separated list of rule codes or names
+                var rules = std.ArrayList([]const u8).init(self.allocator);
+                errdefer rules.deinit();
+                // A repeated --suppress adds to the rules already given
+                if (result.suppressed_rules) |earlier| try rules.appendSlice(earlier);
+                var rule_iter = std.mem.splitScalar(u8, args[i], ',');
+                while (rule_iter.next()) |rule| {
+                    const trimmed = std.mem.trim(u8, rule, " \t");
+                    if (bs5930.ValidationRule.fromCode(trimmed) == null) return error.UnknownValidationRule;
+                    try rules.append(trimmed);
+                }
+                const merged = try rules.toOwnedSlice();
+                if (result.suppressed_rules) |earlier| self.allocator.free(earlier);
+                result.suppressed_rules = merged;
             } else if (std.mem.eql(u8, arg, "--generate") or std.mem.eql(u8, arg, "-g")) {
                 if (i + 1 >= args.len) {
                     return error.MissingGenerateArgument;
@@ -377,6 +440,12 @@ pub const Cli = struct {
                 result.csv_output_columns = try cols.toOwnedSlice();
             } else if (std.mem.eql(u8, arg, "--csv-no-header")) {
                 result.csv_no_header = true;
+            } else if (std.mem.eql(u8, arg, "--checkpoint")) {
+                if (i + 1 >= args.len) {
+                    return error.MissingCheckpointArgument;
+                }
+                i += 1;
+                result.checkpoint_path = args[i];
             } else if (std.mem.eql(u8, arg, "--identify-units")) {
                 result.identify_units = true;
             } else if (std.mem.eql(u8, arg, "--borehole-id")) {
@@ -448,18 +517,7 @@ pub const Cli = struct {
                     return error.MissingModeArgument;
                 }
                 i += 1;
-                const mode_str = args[i];
-                if (std.mem.eql(u8, mode_str, "compact")) {
-                    result.output_mode = .compact;
-                } else if (std.mem.eql(u8, mode_str, "verbose")) {
-                    result.output_mode = .verbose;
-                } else if (std.mem.eql(u8, mode_str, "pretty")) {
-                    result.output_mode = .pretty;
-                } else if (std.mem.eql(u8, mode_str, "summary")) {
-                    result.output_mode = .summary;
-                } else {
-                    return error.InvalidOutputMode;
-                }
+                result.output_mode = CliArgs.OutputMode.fromString(args[i]) orelse return error.InvalidOutputMode;
                 result.output_mode_explicit = true;
             } else if (std.mem.eql(u8, arg, "--from-json")) {
                 if (i + 1 >= args.len) {
@@ -484,6 +542,7 @@ pub const Cli = struct {
                 } else {
                     return error.InvalidJsonFormat;
                 }
+                result.json_format_explicit = true;
             } else if (std.mem.startsWith(u8, arg, "-")) {
                 return error.UnknownOption;
             } else {
@@ -494,17 +553,58 @@ pub const Cli = struct {
             i += 1;
         }
 
+        // A lint run writes no validation report, so asking for both is a mistake
+        if (result.lint and result.report_path != null) {
+            result.deinit();
+            return error.LintWithReport;
+        }
+
         return result;
     }
 
     pub fn run(self: *Cli, args: CliArgs) !void {
-        const output_mode = if (args.output_mode_explicit) args.output_mode else self.defaultOutputMode();
-
         if (args.help) {
             try self.printHelp();
             return;
         }
 
+        // Profile settings apply first so command-line options can override them
+        var project_profile: ?bs5930.Profile = null;
+        defer if (project_profile) |*loaded| loaded.deinit();
+        if (args.profile_path) |profile_path| {
+            project_profile = bs5930.loadProfile(self.allocator, profile_path) catch |err| {
+                std.debug.print("Error: could not load profile '{s}': {s}\n", .{ profile_path, @errorName(err) });
+                return err;
+            };
+            self.parser.applyProfile(&project_profile.?) catch |err| {
+                std.debug.print("Error: could not apply profile '{s}': {s}\n", .{ profile_path, @errorName(err) });
+                return err;
+            };
+        }
+
+        var output_mode = if (args.output_mode_explicit) args.output_mode else self.defaultOutputMode();
+        if (!args.output_mode_explicit) {
+            if (project_profile) |loaded| {
+                if (loaded.output_mode) |mode_name| {
+                    output_mode = CliArgs.OutputMode.fromString(mode_name) orelse return error.InvalidOutputMode;
+                }
+            }
+        }
+
+        var json_format = args.json_format;
+        if (!args.json_format_explicit) {
+            if (project_profile) |loaded| {
+                if (loaded.output_template) |template| {
+                    json_format = std.meta.stringToEnum(CliArgs.JsonFormat, template) orelse return error.InvalidJsonFormat;
+                }
+            }
+        }
+
+        const lint_rules = if (project_profile) |loaded| loaded.lint else bs5930.LintRules{};
+
+        if (args.capitalization_policy) |policy| self.parser.capitalization_policy = policy;
+        if (args.suppressed_rules) |rules| self.parser.suppressed_rules = rules;
+
         // Handle CSV processing mode
         if (args.csv_path) |csv_path| {
             try self.processCsv(csv_path, args);
@@ -519,7 +619,7 @@ pub const Cli = struct {
 
         // Handle JSON input mode
         if (args.json_input_path) |json_path| {
-            try self.handleJsonInput(json_path, args);
+            try self.handleJsonInput(json_path, json_format);
             return;
         }
 
@@ -528,13 +628,28 @@ pub const Cli = struct {
             if (args.check_compliance) {
                 try self.checkCompliance(desc);
             }
+            if (args.lint) {
+                try self.lintDescription(desc, lint_rules);
+            }
         } else if (args.file_path) |file_path| {
-            try self.parseFile(file_path, output_mode, args.no_color, args.check_anomalies);
+            if (args.lint) {
+                try self.lintFile(file_path, lint_rules);
+            } else if (args.report_path) |report_path| {
+                if (!build_options.exporters) return notBuilt("--report", "exporters");
+                try self.writeValidationReport(file_path, report_path);
+            } else {
+                try self.parseFile(file_path, output_mode, args.no_color, args.check_anomalies);
+            }
         } else {
             try self.printHelp();
         }
     }
 
+    fn notBuilt(feature: []const u8, option: []const u8) error{NotBuilt} {
+        std.debug.print("Error: {s} is not included in this build; rebuild with -D{s}=true\n", .{ feature, option });
+        return error.NotBuilt;
+    }
+
     fn defaultOutputMode(self: *Cli) CliArgs.OutputMode {
         _ = self;
         return if (isatty(std.io.getStdOut().handle)) .summary else .compact;
@@ -554,6 +669,12 @@ pub const Cli = struct {
             return error.MissingCsvOutput;
         };
 
+        const excel = args.excel_output or
+            std.mem.endsWith(u8, csv_path, ".xlsx") or
+            std.mem.endsWith(u8, output_path, ".xlsx");
+        if (excel and !build_options.exporters) return notBuilt("Excel support", "exporters");
+        if (args.identify_units and !build_options.correlation) return notBuilt("--identify-units", "correlation");
+
         // For unit identification, validate additional columns
         if (args.identify_units) {
             if (args.borehole_id_column == null) {
@@ -586,8 +707,17 @@ pub const Cli = struct {
                 return error.MissingOutputColumns;
             };
 
+        // Checkpoints only cover the plain CSV to CSV loop
+        if (args.checkpoint_path != null) {
+            if (excel or args.identify_units) {
+                std.debug.print("Error: --checkpoint only works for CSV to CSV jobs, not Excel files or --identify-units\n", .{});
+                return error.CheckpointUnsupported;
+            }
+        }
+
         // Process CSV file
         var processor = csv_processor.CsvProcessor.init(self.allocator);
+        var file_checkpointer = if (args.checkpoint_path) |path| csv_processor.FileCheckpointer.init(path) else null;
         const options = csv_processor.CsvOptions{
             .input_column = args.csv_column.?,
             .output_columns = output_columns,
@@ -608,9 +738,25 @@ pub const Cli = struct {
             .freeze_header = args.freeze_header,
             .auto_filter = args.auto_filter,
             .sheet_name = args.sheet_name,
+            .checkpointer = if (file_checkpointer) |*fc| fc.checkpointer() else null,
         };
 
-        try processor.processFile(csv_path, output_path, options);
+        // Finish the current row and checkpoint on Ctrl-C or SIGTERM
+        stopping_processor = &processor;
+        defer stopping_processor = null;
+        installStopHandler();
+
+        processor.processFile(csv_path, output_path, options) catch |err| switch (err) {
+            error.Stopped => {
+                if (args.checkpoint_path) |path| {
+                    std.debug.print("Stopped; run the same command again to resume from {s}\n", .{path});
+                } else {
+                    std.debug.print("Stopped; {s} is incomplete\n", .{output_path});
+                }
+                return err;
+            },
+            else => return err,
+        };
 
         const stdout = std.io.getStdOut().writer();
         try stdout.print("Successfully processed CSV: {s} -> {s}\n", .{ csv_path, output_path });
@@ -628,7 +774,7 @@ pub const Cli = struct {
         }
     }
 
-    fn checkAndPrintAnomalies(self: *Cli, description: *const bs5930.SoilDescription) !void {
+    fn checkAndPrintAnomalies(self: *Cli, description: *const bs5930.GeologicalDescription) !void {
         var detector = bs5930.AnomalyDetector.init(self.allocator);
         var anomaly_result = try detector.detect(description);
         defer anomaly_result.deinit(self.allocator);
@@ -670,6 +816,67 @@ pub const Cli = struct {
         try stdout.writeAll(formatted);
     }
 
+    fn lintDescription(self: *Cli, description_text: []const u8, rules: bs5930.LintRules) !void {
+        const result = try self.parser.parse(description_text);
+        defer result.deinit(self.allocator);
+
+        var linter = bs5930.HouseStyleLinter.init(self.allocator, rules);
+        var report = try linter.lint(&result);
+        defer report.deinit(self.allocator);
+
+        const formatted = try report.format(self.allocator);
+        defer self.allocator.free(formatted);
+
+        const stdout = std.io.getStdOut().writer();
+        try stdout.writeAll("\n");
+        try stdout.writeAll(formatted);
+    }
+
+    /// Lint every line of a file, reporting only lines that break house style
+    fn lintFile(self: *Cli, file_path: []const u8, rules: bs5930.LintRules) !void {
+        const file = std.fs.cwd().openFile(file_path, .{}) catch |err| switch (err) {
+            error.FileNotFound => {
+                std.debug.print("Error: File not found: {s}\n", .{file_path});
+                return;
+            },
+            else => return err,
+        };
+        defer file.close();
+
+        const content = try file.readToEndAlloc(self.allocator, 1024 * 1024); // 1MB max
+        defer self.allocator.free(content);
+
+        const stdout = std.io.getStdOut().writer();
+        var linter = bs5930.HouseStyleLinter.init(self.allocator, rules);
+        var checked: usize = 0;
+        var failed: usize = 0;
+
+        var lines = std.mem.splitAny(u8, content, "\n");
+        var line_number: usize = 0;
+        while (lines.next()) |line| {
+            line_number += 1;
+            const trimmed = std.mem.trim(u8, line, " \t\r\n");
+            if (trimmed.len == 0) continue;
+
+            const result = try self.parser.parse(trimmed);
+            defer result.deinit(self.allocator);
+
+            var report = try linter.lint(&result);
+            defer report.deinit(self.allocator);
+
+            checked += 1;
+            if (report.passed()) continue;
+            failed += 1;
+
+            try stdout.print("Line {d}: {s}\n", .{ line_number, trimmed });
+            for (report.issues) |issue| {
+                try stdout.print("  [{s}] {s}\n", .{ issue.kind.toString(), issue.message });
+            }
+        }
+
+        try stdout.print("{d} of {d} descriptions match house style\n", .{ checked - failed, checked });
+    }
+
     fn handleGenerate(self: *Cli, gen_mode: CliArgs.GenerateMode, args: CliArgs) !void {
         const stdout = std.io.getStdOut().writer();
 
@@ -711,7 +918,7 @@ pub const Cli = struct {
         }
     }
 
-    fn handleJsonInput(self: *Cli, json_path: []const u8, args: CliArgs) !void {
+    fn handleJsonInput(self: *Cli, json_path: []const u8, json_format: CliArgs.JsonFormat) !void {
         const stdout = std.io.getStdOut().writer();
         const generator = @import("parser/generator.zig");
 
@@ -765,14 +972,14 @@ pub const Cli = struct {
                 defer json_str.deinit();
                 try std.json.stringify(item, .{}, json_str.writer());
 
-                const desc = bs5930.SoilDescription.fromJson(json_str.items, self.allocator) catch |err| {
+                const desc = bs5930.GeologicalDescription.fromJson(json_str.items, self.allocator) catch |err| {
                     std.debug.print("Error: Failed to parse description from JSON: {any}\n", .{err});
                     continue;
                 };
                 defer desc.deinit(self.allocator);
 
                 // Generate description based on format
-                const generated = switch (args.json_format) {
+                const generated = switch (json_format) {
                     .standard => try generator.generate(desc, self.allocator),
                     .concise => try generator.generateConcise(desc, self.allocator),
                     .verbose => try generator.generateVerbose(desc, self.allocator),
@@ -784,14 +991,14 @@ pub const Cli = struct {
             }
         } else {
             // Parse as single description
-            const desc = bs5930.SoilDescription.fromJson(trimmed, self.allocator) catch |err| {
+            const desc = bs5930.GeologicalDescription.fromJson(trimmed, self.allocator) catch |err| {
                 std.debug.print("Error: Failed to parse description from JSON: {any}\n", .{err});
                 return;
             };
             defer desc.deinit(self.allocator);
 
             // Generate description based on format
-            const generated = switch (args.json_format) {
+            const generated = switch (json_format) {
                 .standard => try generator.generate(desc, self.allocator),
                 .concise => try generator.generateConcise(desc, self.allocator),
                 .verbose => try generator.generateVerbose(desc, self.allocator),
@@ -803,6 +1010,61 @@ pub const Cli = struct {
         }
     }
 
+    /// Validate every line of a file and write a CSV or HTML report (by extension)
+    fn writeValidationReport(self: *Cli, file_path: []const u8, report_path: []const u8) !void {
+        const file = std.fs.cwd().openFile(file_path, .{}) catch |err| switch (err) {
+            error.FileNotFound => {
+                std.debug.print("Error: File not found: {s}\n", .{file_path});
+                return;
+            },
+            else => return err,
+        };
+        defer file.close();
+
+        const content = try file.readToEndAlloc(self.allocator, 1024 * 1024); // 1MB max
+        defer self.allocator.free(content);
+
+        var parsed = std.ArrayList(bs5930.GeologicalDescription).init(self.allocator);
+        defer {
+            for (parsed.items) |desc| desc.deinit(self.allocator);
+            parsed.deinit();
+        }
+        var line_numbers = std.ArrayList(usize).init(self.allocator);
+        defer line_numbers.deinit();
+
+        var lines = std.mem.splitAny(u8, content, "\n");
+        var line_number: usize = 0;
+        while (lines.next()) |line| {
+            line_number += 1;
+            const trimmed = std.mem.trim(u8, line, " \t\r\n");
+            if (trimmed.len == 0) continue;
+
+            const desc = try self.parser.parse(trimmed);
+            parsed.append(desc) catch |err| {
+                desc.deinit(self.allocator);
+                return err;
+            };
+            try line_numbers.append(line_number);
+        }
+
+        // Rows point at descriptions after parsing so the list no longer moves
+        const rows = try self.allocator.alloc(bs5930.ReportRow, parsed.items.len);
+        defer self.allocator.free(rows);
+        for (parsed.items, line_numbers.items, 0..) |*desc, number, i| {
+            rows[i] = .{ .row = number, .description = desc };
+        }
+
+        const output = try std.fs.cwd().createFile(report_path, .{});
+        defer output.close();
+        var buffered = std.io.bufferedWriter(output.writer());
+
+        var validator = self.parser.createValidator();
+        const summary = try bs5930.writeBatchReport(self.allocator, &validator, rows, buffered.writer(), bs5930.ReportFormat.fromPath(report_path));
+        try buffered.flush();
+
+        std.debug.print("Wrote {s}: {d} descriptions, {d} valid, {d} errors, {d} warnings\n", .{ report_path, summary.total, summary.valid, summary.errors, summary.warnings });
+    }
+
     fn parseFile(self: *Cli, file_path: []const u8, mode: CliArgs.OutputMode, no_color: bool, check_anomalies: bool) !void {
         const file = std.fs.cwd().openFile(file_path, .{}) catch |err| switch (err) {
             error.FileNotFound => {
@@ -837,7 +1099,7 @@ pub const Cli = struct {
         }
     }
 
-    fn printResult(self: *Cli, result: bs5930.SoilDescription, mode: CliArgs.OutputMode, no_color: bool) !void {
+    fn printResult(self: *Cli, result: bs5930.GeologicalDescription, mode: CliArgs.OutputMode, no_color: bool) !void {
         const stdout = std.io.getStdOut().writer();
 
         switch (mode) {
@@ -863,9 +1125,14 @@ pub const Cli = struct {
                 if (result.primary_soil_type) |pst| {
                     try stdout.print("Primary Soil: {s}\n", .{pst.toString()});
                 }
-                if (result.color) |color| {
+                if (result.colour_detail) |colour| {
+                    try stdout.print("Colour: {}\n", .{colour});
+                } else if (result.color) |color| {
                     try stdout.print("Color: {s}\n", .{color.toString()});
                 }
+                if (result.munsell_colour) |munsell| {
+                    try stdout.print("Munsell: {}{s}\n", .{ munsell, if (munsell.isValid()) "" else " (out of range)" });
+                }
                 if (result.moisture_content) |moisture| {
                     try stdout.print("Moisture Content: {s}\n", .{moisture.toString()});
                 }
@@ -875,11 +1142,75 @@ pub const Cli = struct {
                 if (result.particle_size) |particle_size| {
                     try stdout.print("Particle Size: {s}\n", .{particle_size.toString()});
                 }
+                if (result.particle_shape) |shape| {
+                    try stdout.print("Particle Shape: {}\n", .{shape});
+                }
+                for (result.inclusions) |inclusion| {
+                    try stdout.print("Inclusion: {}\n", .{inclusion});
+                }
+                for (result.tertiary_constituents) |constituent| {
+                    try stdout.print("Tertiary Constituent: {}\n", .{constituent});
+                }
+                if (result.fossil_frequency) |frequency| {
+                    try stdout.print("Fossils: {s}\n", .{frequency.toString()});
+                }
+                if (result.sensitivity) |sensitivity| {
+                    try stdout.print("Sensitivity: {s}\n", .{sensitivity.toString()});
+                }
+                if (result.peat_properties) |peat| {
+                    try stdout.print("Peat: {}\n", .{peat});
+                }
+                if (result.marine_indicators.count() > 0) {
+                    try stdout.print("Marine Indicators: ", .{});
+                    var indicators = result.marine_indicators.iterator();
+                    var first = true;
+                    while (indicators.next()) |indicator| {
+                        if (!first) try stdout.print(", ", .{});
+                        first = false;
+                        try stdout.print("{s}", .{indicator.toString()});
+                    }
+                    try stdout.print("\n", .{});
+                }
+                if (result.gas_indicators) |gas| {
+                    try stdout.print("Gas Indicators:{s}{s}{s}{s}\n", .{
+                        if (gas.gas_blisters) " gas blisters;" else "",
+                        if (gas.expanded_core) " expanded core;" else "",
+                        if (gas.gas_voids) " gas voids;" else "",
+                        if (gas.hydrate_dissociation) " hydrate dissociation;" else "",
+                    });
+                }
+                if (bs5930.classifyCarbonate(result)) |class| {
+                    try stdout.print("Carbonate Class: {s} ({s} carbonate)\n", .{ class.name(), class.content.toString() });
+                }
+                if (result.material_origin) |origin| {
+                    try stdout.print("Material Origin: {s}\n", .{origin.toString()});
+                }
+                for (result.made_ground_constituents) |constituent| {
+                    try stdout.print("Made Ground Constituent: {}\n", .{constituent});
+                }
+                if (result.alternative_type) |alternative| {
+                    try stdout.print("Alternative: {s}\n", .{alternative.toString()});
+                }
+                for (result.condition_notes) |note| {
+                    try stdout.print("Condition: {}\n", .{note});
+                }
+                if (result.composite) |composite| {
+                    try stdout.print("Composite: {}{s}\n", .{ composite, if (composite.proportions_stated) "" else " (assumed equal)" });
+                }
+                if (result.discontinuity_spacing) |spacing| {
+                    try stdout.print("Discontinuity Spacing: {}, RMR rating {d}\n", .{ spacing, spacing.rmrRating() });
+                }
+                if (result.karst_grade) |grade| {
+                    try stdout.print("Karst Grade: {s} ({s}), void risk {s}\n", .{ grade.code(), grade.toString(), bs5930.voidRisk(result).toString() });
+                }
                 if (result.strength_parameters) |sp| {
                     const sp_str = try sp.toString(self.allocator);
                     defer self.allocator.free(sp_str);
                     try stdout.print("Strength: {s}\n", .{sp_str});
                 }
+                if (result.boundary_advisory) |advisory| {
+                    try stdout.print("Soil/Rock Boundary: {}\n", .{advisory});
+                }
 
                 if (result.constituent_guidance) |cg| {
                     try stdout.print("Constituent Proportions:\n", .{});
@@ -889,6 +1220,12 @@ pub const Cli = struct {
                         try stdout.print("  - {s}\n", .{constituent_str});
                     }
                 }
+                const cited = try bs5930.citations(self.allocator, result);
+                defer self.allocator.free(cited);
+                if (cited.len > 0) {
+                    try stdout.print("References:\n", .{});
+                    for (cited) |citation| try stdout.print("  - {}\n", .{citation});
+                }
                 try stdout.print("Confidence: {d:.2}\n", .{result.confidence});
                 try stdout.print("Valid: {s}\n", .{if (result.is_valid) "Yes" else "No"});
 
@@ -902,6 +1239,11 @@ pub const Cli = struct {
 
                 try stdout.print("\n", .{});
             },
+            .conll => {
+                const words = try bs5930.labelDescription(self.allocator, result.raw_description, &result);
+                defer self.allocator.free(words);
+                try bs5930.writeConll(words, stdout);
+            },
             .verbose => {
                 const json = try result.toJson(self.allocator);
                 defer self.allocator.free(json);
@@ -956,6 +1298,11 @@ pub const Cli = struct {
             \\    -C, --no-color          Disable colorized output
             \\    -a, --check-anomalies   Check for anomalies in descriptions
             \\    --check-compliance      Check BS 5930:2015 compliance
+            \\    --lint                  Check house style (length, order, required fields)
+            \\    --capitalization <P>    Primary type capitals policy (required|preferred|ignored)
+            \\    --suppress <CODES>      Skip validation rules by code or name (e.g. W002,E010)
+            \\    --profile <FILE>        Load a project profile (.toml or .yaml)
+            \\    --report <FILE>         With --file, write a validation report (.csv or .html)
             \\    -g, --generate <MODE>   Generate descriptions (random|variations)
             \\    -n, --count <N>         Number of descriptions to generate (default: 1)
             \\    -s, --seed <SEED>       Seed for random generation (default: timestamp)
@@ -970,6 +1317,8 @@ pub const Cli = struct {
             \\    --column <NAME|INDEX>   Column name (or 0-based index) containing descriptions
             \\    --output-columns <COLS> Comma-separated list of result columns to add
             \\    --csv-no-header         Treat file as having no header row
+            \\    --checkpoint <FILE>     Save progress to FILE and resume from it after a crash
+            \\                            (CSV to CSV only)
             \\
             \\EXCEL OPTIONS:
             \\    --excel-output          Export to Excel format (.xlsx)
@@ -997,6 +1346,7 @@ pub const Cli = struct {
             \\    verbose                 JSON with confidence and warnings
             \\    pretty                  Colorized, indented JSON (like jq)
             \\    summary                 Human-readable key information
+            \\    conll                   Token/BIO label pairs for NER training
             \\
             \\GENERATE MODES:
             \\    random                  Generate random valid descriptions
@@ -1083,6 +1433,7 @@ pub const Cli = struct {
             \\    litholog "Firm CLAY" --check-compliance
             \\    litholog "Medium firm brown CLAY" --check-compliance
             \\    litholog "Soft GRAVEL" --check-compliance
+            \\    litholog "Brown firm CLAY" --lint --profile project.toml
             \\    
             \\    # Generate random descriptions
             \\    litholog --generate random --count 5
@@ -1132,3 +1483,21 @@ pub const Cli = struct {
         , .{});
     }
 };
+
+/// The CSV job a stop signal applies to, if one is running
+var stopping_processor: ?*CsvProcessor = null;
+
+fn handleStopSignal(_: c_int) callconv(.C) void {
+    if (stopping_processor) |processor| processor.stop();
+}
+
+fn installStopHandler() void {
+    if (builtin.os.tag == .windows) return;
+    const action = std.posix.Sigaction{
+        .handler = .{ .handler = handleStopSignal },
+        .mask = std.posix.empty_sigset,
+        .flags = 0,
+    };
+    std.posix.sigaction(std.posix.SIG.INT, &action, null);
+    std.posix.sigaction(std.posix.SIG.TERM, &action, null);
+}
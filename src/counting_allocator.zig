@@ -0,0 +1,82 @@
+const std = @import("std");
+
+/// Wraps an allocator to count bytes and allocations for memory profiles.
+/// Not thread-safe.
+pub const CountingAllocator = struct {
+    child: std.mem.Allocator,
+    /// Bytes requested, including growth by resize
+    bytes: usize = 0,
+    allocs: usize = 0,
+    /// Bytes currently allocated, and the most there have been at once
+    live: usize = 0,
+    peak: usize = 0,
+
+    pub fn allocator(self: *CountingAllocator) std.mem.Allocator {
+        return .{ .ptr = self, .vtable = &.{
+            .alloc = alloc,
+            .resize = resize,
+            .remap = remap,
+            .free = free,
+        } };
+    }
+
+    /// Start counting afresh; `live` is kept since those bytes are still held
+    pub fn reset(self: *CountingAllocator) void {
+        self.bytes = 0;
+        self.allocs = 0;
+        self.peak = self.live;
+    }
+
+    fn grow(self: *CountingAllocator, old_len: usize, new_len: usize) void {
+        if (new_len > old_len) {
+            self.bytes += new_len - old_len;
+            self.live += new_len - old_len;
+        } else {
+            self.live -= old_len - new_len;
+        }
+        self.peak = @max(self.peak, self.live);
+    }
+
+    fn alloc(ctx: *anyopaque, len: usize, alignment: std.mem.Alignment, ret_addr: usize) ?[*]u8 {
+        const self: *CountingAllocator = @ptrCast(@alignCast(ctx));
+        const memory = self.child.rawAlloc(len, alignment, ret_addr) orelse return null;
+        self.allocs += 1;
+        self.grow(0, len);
+        return memory;
+    }
+
+    fn resize(ctx: *anyopaque, memory: []u8, alignment: std.mem.Alignment, new_len: usize, ret_addr: usize) bool {
+        const self: *CountingAllocator = @ptrCast(@alignCast(ctx));
+        if (!self.child.rawResize(memory, alignment, new_len, ret_addr)) return false;
+        self.grow(memory.len, new_len);
+        return true;
+    }
+
+    fn remap(ctx: *anyopaque, memory: []u8, alignment: std.mem.Alignment, new_len: usize, ret_addr: usize) ?[*]u8 {
+        const self: *CountingAllocator = @ptrCast(@alignCast(ctx));
+        const remapped = self.child.rawRemap(memory, alignment, new_len, ret_addr) orelse return null;
+        self.grow(memory.len, new_len);
+        return remapped;
+    }
+
+    fn free(ctx: *anyopaque, memory: []u8, alignment: std.mem.Alignment, ret_addr: usize) void {
+        const self: *CountingAllocator = @ptrCast(@alignCast(ctx));
+        self.child.rawFree(memory, alignment, ret_addr);
+        self.grow(memory.len, 0);
+    }
+};
+
+test "count allocations and peak" {
+    var counter = CountingAllocator{ .child = std.testing.allocator };
+    const allocator = counter.allocator();
+
+    const a = try allocator.alloc(u8, 100);
+    const b = try allocator.alloc(u8, 50);
+    allocator.free(a);
+    allocator.free(b);
+
+    try std.testing.expectEqual(@as(usize, 150), counter.bytes);
+    try std.testing.expectEqual(@as(usize, 2), counter.allocs);
+    try std.testing.expectEqual(@as(usize, 0), counter.live);
+    try std.testing.expectEqual(@as(usize, 150), counter.peak);
+}
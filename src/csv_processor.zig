@@ -1,9 +1,17 @@
 const std = @import("std");
+const build_options = @import("build_options");
 const bs5930 = @import("parser/bs5930.zig");
 const unit_identifier = @import("parser/unit_identifier.zig");
 const spatial = @import("parser/spatial.zig");
 const excel_writer = @import("excel_writer.zig");
 const excel_reader = @import("excel_reader.zig");
+const checkpoint = @import("checkpoint.zig");
+const circuit_breaker = @import("circuit_breaker.zig");
+
+pub const Checkpoint = checkpoint.Checkpoint;
+pub const Checkpointer = checkpoint.Checkpointer;
+pub const FileCheckpointer = checkpoint.FileCheckpointer;
+pub const FailureBudget = circuit_breaker.Options;
 
 pub const CsvOptions = struct {
     input_column: []const u8,
@@ -28,11 +36,22 @@ pub const CsvOptions = struct {
     freeze_header: bool = false,
     auto_filter: bool = false,
     sheet_name: ?[]const u8 = null,
+    // Resume options (plain CSV to CSV only)
+    checkpointer: ?Checkpointer = null,
+    checkpoint_interval: usize = 1000, // Input lines between saves
+    // Give up with error.TooManyFailures when this share of recent rows
+    // fails to parse, rather than grinding through a broken parser
+    failure_budget: ?FailureBudget = null,
+    // Skip a row with error.ParseTimeout when its description takes longer
+    // than this to parse, so one pathological input cannot stall the job.
+    // When null the parser keeps its own timeout.
+    item_timeout_ms: ?u64 = null,
 };
 
 pub const CsvProcessor = struct {
     allocator: std.mem.Allocator,
     parser: bs5930.Parser,
+    stop_requested: std.atomic.Value(bool) = std.atomic.Value(bool).init(false),
 
     pub fn init(allocator: std.mem.Allocator) CsvProcessor {
         return CsvProcessor{
@@ -41,29 +60,58 @@ pub const CsvProcessor = struct {
         };
     }
 
+    /// Ask a running `processFile` to stop once the row in progress is
+    /// written. It saves a checkpoint, if configured, and returns
+    /// `error.Stopped`; Excel and unit identification jobs leave their
+    /// output unwritten or incomplete. Safe to call from another thread or a
+    /// signal handler.
+    pub fn stop(self: *CsvProcessor) void {
+        self.stop_requested.store(true, .release);
+    }
+
+    fn stopping(self: *CsvProcessor) bool {
+        return self.stop_requested.load(.acquire);
+    }
+
+    /// Parse every row of a CSV or Excel file. Excel files and unit
+    /// identification return `error.NotBuilt` when the CLI was built without
+    /// the exporters or correlation subsystem.
     pub fn processFile(
         self: *CsvProcessor,
         input_path: []const u8,
         output_path: []const u8,
         options: CsvOptions,
     ) !void {
+        const parser_timeout = self.parser.timeout_ns;
+        defer self.parser.timeout_ns = parser_timeout;
+        if (options.item_timeout_ms) |ms| self.parser.timeout_ns = ms * std.time.ns_per_ms;
+
         // Detect input format
         const is_input_excel = std.mem.endsWith(u8, input_path, ".xlsx");
 
         // Detect output format from file extension or excel_format flag
         const is_output_excel = options.excel_format or std.mem.endsWith(u8, output_path, ".xlsx");
 
+        // Only the CSV to CSV loop below can resume; the other paths read
+        // or write the whole file at once
+        if (options.checkpointer != null and (is_input_excel or is_output_excel or options.identify_units)) {
+            return error.CheckpointUnsupported;
+        }
+
         // If input is Excel, read it first
         if (is_input_excel) {
+            if (!build_options.exporters) return error.NotBuilt;
             return self.processExcelFile(input_path, output_path, options);
         }
 
         if (is_output_excel) {
+            if (!build_options.exporters) return error.NotBuilt;
             return self.processFileToExcel(input_path, output_path, options);
         }
 
         // If unit identification is requested, use the enhanced processor
         if (options.identify_units) {
+            if (!build_options.correlation) return error.NotBuilt;
             return self.processFileWithUnits(input_path, output_path, options);
         }
 
@@ -75,8 +123,15 @@ pub const CsvProcessor = struct {
         const content = try file.readToEndAlloc(self.allocator, 100 * 1024 * 1024); // 100MB max
         defer self.allocator.free(content);
 
-        // Create output file
-        const output_file = try std.fs.cwd().createFile(output_path, .{});
+        // Resume from a checkpoint, dropping any rows written after it
+        const resume_from: ?Checkpoint = if (options.checkpointer) |checkpointer| try checkpointer.load() else null;
+        const output_file = if (resume_from) |saved| blk: {
+            const file_to_resume = try std.fs.cwd().openFile(output_path, .{ .mode = .write_only });
+            errdefer file_to_resume.close();
+            try file_to_resume.setEndPos(saved.output_size);
+            try file_to_resume.seekTo(saved.output_size);
+            break :blk file_to_resume;
+        } else try std.fs.cwd().createFile(output_path, .{});
         defer output_file.close();
 
         const writer = output_file.writer();
@@ -85,8 +140,30 @@ pub const CsvProcessor = struct {
         var lines = std.mem.splitScalar(u8, content, '\n');
         var line_num: usize = 0;
         var input_col_idx: ?usize = null;
+        const resume_line: usize = if (resume_from) |saved| @intCast(saved.line) else 0;
+        var saved_line = resume_line;
+        var breaker = circuit_breaker.CircuitBreaker.init(options.failure_budget orelse .{});
 
         while (lines.next()) |line| : (line_num += 1) {
+            const is_header = line_num == 0 and options.has_header;
+            const replaying = line_num < resume_line;
+
+            // Lines before this one are done and written
+            if (options.checkpointer) |checkpointer| {
+                if (!replaying and line_num - saved_line >= options.checkpoint_interval) {
+                    try checkpointer.save(.{ .line = line_num, .output_size = try output_file.getPos() });
+                    saved_line = line_num;
+                }
+            }
+            if (replaying and !is_header) continue;
+
+            if (self.stopping()) {
+                if (options.checkpointer) |checkpointer| {
+                    try checkpointer.save(.{ .line = line_num, .output_size = try output_file.getPos() });
+                }
+                return error.Stopped;
+            }
+
             const trimmed = std.mem.trim(u8, line, " \t\r");
             if (trimmed.len == 0) continue;
 
@@ -100,7 +177,7 @@ pub const CsvProcessor = struct {
             }
 
             // First row - handle header
-            if (line_num == 0 and options.has_header) {
+            if (is_header) {
                 // Find input column index
                 for (columns, 0..) |col, idx| {
                     if (std.mem.eql(u8, col, options.input_column)) {
@@ -112,8 +189,8 @@ pub const CsvProcessor = struct {
                     return error.InputColumnNotFound;
                 }
 
-                // Write output header
-                try self.writeHeader(writer, columns, options.output_columns);
+                // Write output header, unless resuming after it
+                if (!replaying) try self.writeHeader(writer, columns, options.output_columns);
                 continue;
             }
 
@@ -135,13 +212,107 @@ pub const CsvProcessor = struct {
             // Parse the description
             const result = self.parser.parse(description) catch |err| {
                 std.debug.print("Error parsing row {}: {}\n", .{ line_num + 1, err });
+                if (options.failure_budget != null and tripBreaker(&breaker)) {
+                    // Resume retries this row once the parser is fixed
+                    if (options.checkpointer) |checkpointer| {
+                        try checkpointer.save(.{ .line = line_num, .output_size = try output_file.getPos() });
+                    }
+                    return error.TooManyFailures;
+                }
                 continue;
             };
             defer result.deinit(self.allocator);
+            breaker.record(true);
 
             // Write output row
             try self.writeRow(writer, columns, &result, options.output_columns);
         }
+
+        if (options.checkpointer) |checkpointer| try checkpointer.clear();
+    }
+
+    /// Parse the input column of a CSV file and hand each result to `sink`,
+    /// flushing it at the end. Stops early, like `processFile`, after `stop`.
+    pub fn processFileToSink(
+        self: *CsvProcessor,
+        input_path: []const u8,
+        options: CsvOptions,
+        sink: bs5930.Sink,
+    ) !void {
+        const parser_timeout = self.parser.timeout_ns;
+        defer self.parser.timeout_ns = parser_timeout;
+        if (options.item_timeout_ms) |ms| self.parser.timeout_ns = ms * std.time.ns_per_ms;
+
+        const file = try std.fs.cwd().openFile(input_path, .{});
+        defer file.close();
+
+        const content = try file.readToEndAlloc(self.allocator, 100 * 1024 * 1024); // 100MB max
+        defer self.allocator.free(content);
+
+        var lines = std.mem.splitScalar(u8, content, '\n');
+        var line_num: usize = 0;
+        var input_col_idx: ?usize = null;
+        var breaker = circuit_breaker.CircuitBreaker.init(options.failure_budget orelse .{});
+
+        while (lines.next()) |line| : (line_num += 1) {
+            if (self.stopping()) {
+                try sink.flush();
+                return error.Stopped;
+            }
+
+            const trimmed = std.mem.trim(u8, line, " \t\r");
+            if (trimmed.len == 0) continue;
+
+            const columns = try self.parseCsvLine(trimmed, options.delimiter);
+            defer {
+                for (columns) |col| {
+                    self.allocator.free(col);
+                }
+                self.allocator.free(columns);
+            }
+
+            if (line_num == 0 and options.has_header) {
+                for (columns, 0..) |col, idx| {
+                    if (std.mem.eql(u8, col, options.input_column)) {
+                        input_col_idx = idx;
+                    }
+                }
+                if (input_col_idx == null) {
+                    return error.InputColumnNotFound;
+                }
+                continue;
+            }
+
+            if (input_col_idx == null) {
+                input_col_idx = std.fmt.parseInt(usize, options.input_column, 10) catch {
+                    return error.InvalidInputColumn;
+                };
+            }
+
+            const col_idx = input_col_idx.?;
+            if (col_idx >= columns.len) continue;
+
+            const result = self.parser.parse(columns[col_idx]) catch |err| {
+                std.debug.print("Error parsing row {}: {}\n", .{ line_num + 1, err });
+                if (options.failure_budget != null and tripBreaker(&breaker)) {
+                    try sink.flush();
+                    return error.TooManyFailures;
+                }
+                continue;
+            };
+            defer result.deinit(self.allocator);
+            breaker.record(true);
+
+            try sink.write(.{ .row = line_num + 1, .description = &result });
+        }
+
+        try sink.flush();
+    }
+
+    /// Count a failed row; true once the failure budget is spent
+    fn tripBreaker(breaker: *circuit_breaker.CircuitBreaker) bool {
+        breaker.record(false);
+        return breaker.health().state == .open;
     }
 
     fn processFileToExcel(
@@ -174,6 +345,8 @@ pub const CsvProcessor = struct {
         var input_col_idx: ?usize = null;
 
         while (lines.next()) |line| : (line_num += 1) {
+            if (self.stopping()) return error.Stopped;
+
             const trimmed = std.mem.trim(u8, line, " \t\r");
             if (trimmed.len == 0) continue;
 
@@ -316,6 +489,8 @@ pub const CsvProcessor = struct {
         };
 
         while (lines.next()) |line| : (line_num += 1) {
+            if (self.stopping()) return error.Stopped;
+
             const trimmed = std.mem.trim(u8, line, " \t\r");
             if (trimmed.len == 0) continue;
 
@@ -424,6 +599,8 @@ pub const CsvProcessor = struct {
         var entry_idx: usize = 0;
 
         while (lines.next()) |line| : (line_num += 1) {
+            if (self.stopping()) return error.Stopped;
+
             const trimmed = std.mem.trim(u8, line, " \t\r");
             if (trimmed.len == 0) continue;
 
@@ -466,7 +643,9 @@ pub const CsvProcessor = struct {
         }
     }
 
-    fn parseCsvLine(self: *CsvProcessor, line: []const u8, delimiter: u8) ![][]const u8 {
+    /// Split a CSV line into unquoted fields; the caller frees each field
+    /// and the slice
+    pub fn parseCsvLine(self: *CsvProcessor, line: []const u8, delimiter: u8) ![][]const u8 {
         var columns = std.ArrayList([]const u8).init(self.allocator);
         errdefer columns.deinit();
 
@@ -546,7 +725,7 @@ pub const CsvProcessor = struct {
         self: *CsvProcessor,
         writer: anytype,
         input_columns: [][]const u8,
-        result: *const bs5930.SoilDescription,
+        result: *const bs5930.GeologicalDescription,
         output_columns: []const []const u8,
     ) !void {
         // Write all original columns
@@ -595,7 +774,7 @@ pub const CsvProcessor = struct {
 
     fn getResultValue(
         self: *CsvProcessor,
-        result: *const bs5930.SoilDescription,
+        result: *const bs5930.GeologicalDescription,
         field_name: []const u8,
     ) !?[]const u8 {
         // Map field names to result properties
@@ -755,6 +934,8 @@ pub const CsvProcessor = struct {
         // Process each row
         var row_idx: usize = 0;
         for (sheet.rows) |row| {
+            if (self.stopping()) return error.Stopped;
+
             if (row_idx == 0 and options.has_header) {
                 // Write header row
                 var header_cells = std.ArrayList([]const u8).init(self.allocator);
@@ -780,7 +961,14 @@ pub const CsvProcessor = struct {
             const description = row.cells[input_col_idx];
 
             // Parse the description
-            const result = try self.parser.parse(description);
+            const result = self.parser.parse(description) catch |err| switch (err) {
+                error.ParseTimeout => {
+                    std.debug.print("Error parsing row {}: {}\n", .{ row_idx + 1, err });
+                    row_idx += 1;
+                    continue;
+                },
+                else => return err,
+            };
             defer result.deinit(self.allocator);
 
             // Build output row
@@ -829,6 +1017,8 @@ pub const CsvProcessor = struct {
         // Process each row
         var row_idx: usize = 0;
         for (sheet.rows) |row| {
+            if (self.stopping()) return error.Stopped;
+
             if (row_idx == 0 and options.has_header) {
                 // Write header row
                 for (row.cells, 0..) |cell, idx| {
@@ -852,7 +1042,14 @@ pub const CsvProcessor = struct {
             const description = row.cells[input_col_idx];
 
             // Parse the description
-            const result = try self.parser.parse(description);
+            const result = self.parser.parse(description) catch |err| switch (err) {
+                error.ParseTimeout => {
+                    std.debug.print("Error parsing row {}: {}\n", .{ row_idx + 1, err });
+                    row_idx += 1;
+                    continue;
+                },
+                else => return err,
+            };
             defer result.deinit(self.allocator);
 
             // Write original columns
@@ -877,3 +1074,160 @@ pub const CsvProcessor = struct {
         }
     }
 };
+
+/// Keeps the checkpoint in memory and stops the job once it saves `stop_at`
+const TestCheckpointer = struct {
+    saved: ?Checkpoint = null,
+    stop_at: ?u64 = null,
+    processor: *CsvProcessor,
+
+    fn checkpointer(self: *TestCheckpointer) Checkpointer {
+        return Checkpointer{
+            .ptr = self,
+            .vtable = &.{ .load = loadFn, .save = saveFn, .clear = clearFn },
+        };
+    }
+
+    fn loadFn(ptr: *anyopaque) anyerror!?Checkpoint {
+        const self: *TestCheckpointer = @ptrCast(@alignCast(ptr));
+        return self.saved;
+    }
+
+    fn saveFn(ptr: *anyopaque, saved: Checkpoint) anyerror!void {
+        const self: *TestCheckpointer = @ptrCast(@alignCast(ptr));
+        self.saved = saved;
+        if (self.stop_at) |line| {
+            if (line == saved.line) self.processor.stop();
+        }
+    }
+
+    fn clearFn(ptr: *anyopaque) anyerror!void {
+        const self: *TestCheckpointer = @ptrCast(@alignCast(ptr));
+        self.saved = null;
+    }
+};
+
+test "resume a stopped job from its checkpoint" {
+    const allocator = std.testing.allocator;
+
+    var tmp = std.testing.tmpDir(.{});
+    defer tmp.cleanup();
+    try tmp.dir.writeFile(.{
+        .sub_path = "input.csv",
+        .data = "ID,Description\n1,Firm CLAY\n2,Dense SAND\n3,Strong LIMESTONE\n4,Soft brown SILT\n",
+    });
+    const dir = try tmp.dir.realpathAlloc(allocator, ".");
+    defer allocator.free(dir);
+    const input = try std.fs.path.join(allocator, &.{ dir, "input.csv" });
+    defer allocator.free(input);
+    const expected_path = try std.fs.path.join(allocator, &.{ dir, "expected.csv" });
+    defer allocator.free(expected_path);
+    const resumed_path = try std.fs.path.join(allocator, &.{ dir, "resumed.csv" });
+    defer allocator.free(resumed_path);
+
+    const columns = [_][]const u8{ "material_type", "primary_soil_type" };
+    var processor = CsvProcessor.init(allocator);
+    try processor.processFile(input, expected_path, .{ .input_column = "Description", .output_columns = &columns });
+
+    var checkpointer = TestCheckpointer{ .stop_at = 3, .processor = &processor };
+    const options = CsvOptions{
+        .input_column = "Description",
+        .output_columns = &columns,
+        .checkpointer = checkpointer.checkpointer(),
+        .checkpoint_interval = 1,
+    };
+    try std.testing.expectError(error.Stopped, processor.processFile(input, resumed_path, options));
+    try std.testing.expectEqual(@as(u64, 3), checkpointer.saved.?.line);
+
+    // A partial row written after the checkpoint is dropped on resume
+    {
+        const partial = try tmp.dir.openFile("resumed.csv", .{ .mode = .write_only });
+        defer partial.close();
+        try partial.seekFromEnd(0);
+        try partial.writeAll("3,Strong LIME");
+    }
+
+    processor.stop_requested.store(false, .release);
+    try processor.processFile(input, resumed_path, options);
+    try std.testing.expect(checkpointer.saved == null);
+
+    const expected = try tmp.dir.readFileAlloc(allocator, "expected.csv", 1024 * 1024);
+    defer allocator.free(expected);
+    const resumed = try tmp.dir.readFileAlloc(allocator, "resumed.csv", 1024 * 1024);
+    defer allocator.free(resumed);
+    try std.testing.expectEqualStrings(expected, resumed);
+}
+
+test "checkpoints are rejected where a job cannot resume" {
+    var processor = CsvProcessor.init(std.testing.allocator);
+    var checkpointer = TestCheckpointer{ .processor = &processor };
+    const options = CsvOptions{
+        .input_column = "Description",
+        .output_columns = &.{},
+        .checkpointer = checkpointer.checkpointer(),
+    };
+    try std.testing.expectError(error.CheckpointUnsupported, processor.processFile("input.csv", "output.xlsx", options));
+    try std.testing.expectError(error.CheckpointUnsupported, processor.processFile("input.xlsx", "output.csv", options));
+
+    var units = options;
+    units.identify_units = true;
+    try std.testing.expectError(error.CheckpointUnsupported, processor.processFile("input.csv", "output.csv", units));
+}
+
+test "every processing path stops when asked" {
+    const allocator = std.testing.allocator;
+
+    var tmp = std.testing.tmpDir(.{});
+    defer tmp.cleanup();
+    try tmp.dir.writeFile(.{
+        .sub_path = "input.csv",
+        .data = "BH,Top,Base,Description\nBH1,0.0,1.0,Firm CLAY\nBH1,1.0,2.0,Dense SAND\n",
+    });
+    const dir = try tmp.dir.realpathAlloc(allocator, ".");
+    defer allocator.free(dir);
+    const input = try std.fs.path.join(allocator, &.{ dir, "input.csv" });
+    defer allocator.free(input);
+    const csv_output = try std.fs.path.join(allocator, &.{ dir, "output.csv" });
+    defer allocator.free(csv_output);
+    const excel_output = try std.fs.path.join(allocator, &.{ dir, "output.xlsx" });
+    defer allocator.free(excel_output);
+
+    var processor = CsvProcessor.init(allocator);
+    processor.stop();
+
+    const options = CsvOptions{ .input_column = "Description", .output_columns = &.{"material_type"} };
+    try std.testing.expectError(error.Stopped, processor.processFile(input, csv_output, options));
+    try std.testing.expectError(error.Stopped, processor.processFile(input, excel_output, options));
+
+    var units = options;
+    units.identify_units = true;
+    units.borehole_id_column = "BH";
+    units.depth_top_column = "Top";
+    units.depth_bottom_column = "Base";
+    try std.testing.expectError(error.Stopped, processor.processFile(input, csv_output, units));
+}
+
+test "a job leaves the parser timeout as it found it" {
+    const allocator = std.testing.allocator;
+
+    var tmp = std.testing.tmpDir(.{});
+    defer tmp.cleanup();
+    try tmp.dir.writeFile(.{ .sub_path = "input.csv", .data = "Description\nFirm CLAY\n" });
+    const dir = try tmp.dir.realpathAlloc(allocator, ".");
+    defer allocator.free(dir);
+    const input = try std.fs.path.join(allocator, &.{ dir, "input.csv" });
+    defer allocator.free(input);
+    const output = try std.fs.path.join(allocator, &.{ dir, "output.csv" });
+    defer allocator.free(output);
+
+    var processor = CsvProcessor.init(allocator);
+    processor.parser.timeout_ns = std.time.ns_per_s;
+
+    var options = CsvOptions{ .input_column = "Description", .output_columns = &.{"material_type"} };
+    try processor.processFile(input, output, options);
+    try std.testing.expectEqual(@as(?u64, std.time.ns_per_s), processor.parser.timeout_ns);
+
+    options.item_timeout_ms = 60_000;
+    try processor.processFile(input, output, options);
+    try std.testing.expectEqual(@as(?u64, std.time.ns_per_s), processor.parser.timeout_ns);
+}
@@ -4,7 +4,7 @@ const types = @import("parser/types.zig");
 const version = @import("version.zig");
 
 const MaterialType = types.MaterialType;
-const SoilDescription = types.SoilDescription;
+const GeologicalDescription = types.GeologicalDescription;
 const Consistency = types.Consistency;
 const Density = types.Density;
 const RockStrength = types.RockStrength;
@@ -52,7 +52,7 @@ const CSoilDescription = extern struct {
     confidence: f64,
 };
 
-fn zigToC(description: SoilDescription) !*CSoilDescription {
+fn zigToC(description: GeologicalDescription) !*CSoilDescription {
     const c_desc = try allocator.create(CSoilDescription);
 
     // Copy raw description
@@ -111,6 +111,31 @@ fn zigToC(description: SoilDescription) !*CSoilDescription {
     return c_desc;
 }
 
+/// A pinned enum from a C field: -1 and *_UNKNOWN give null, and so do
+/// values this build does not know (for example from a newer version)
+fn fromCode(comptime T: type, code: i32) ?T {
+    const stored = std.math.cast(u8, code) orelse return null;
+    return types.fromStored(T, stored);
+}
+
+/// The fields of a C description the generators read, or null for an
+/// invalid material type. Borrows `raw_description` from `desc`.
+fn cToZig(desc: *const CSoilDescription) ?GeologicalDescription {
+    const material_type = fromCode(MaterialType, desc.material_type) orelse return null;
+    return GeologicalDescription{
+        .raw_description = std.mem.span(desc.raw_description),
+        .material_type = material_type,
+        .confidence = @floatCast(desc.confidence),
+        .consistency = fromCode(Consistency, desc.consistency),
+        .density = fromCode(Density, desc.density),
+        .primary_soil_type = fromCode(SoilType, desc.primary_soil_type),
+        .rock_strength = fromCode(RockStrength, desc.rock_strength),
+        .weathering_grade = fromCode(WeatheringGrade, desc.weathering_grade),
+        .rock_structure = fromCode(RockStructure, desc.rock_structure),
+        .primary_rock_type = fromCode(RockType, desc.primary_rock_type),
+    };
+}
+
 export fn litholog_parse(description: [*:0]const u8) ?*CSoilDescription {
     const desc_slice = std.mem.span(description);
 
@@ -147,34 +172,7 @@ export fn litholog_free_description(description: ?*CSoilDescription) void {
 export fn litholog_description_to_json(description: ?*const CSoilDescription) ?[*:0]const u8 {
     if (description) |desc| {
         // Convert back to Zig description
-        var zig_desc = SoilDescription{
-            .raw_description = std.mem.span(desc.raw_description),
-            .material_type = @enumFromInt(desc.material_type),
-            .confidence = @floatCast(desc.confidence),
-        };
-
-        // Convert optional fields
-        if (desc.consistency >= 0) {
-            zig_desc.consistency = @enumFromInt(desc.consistency);
-        }
-        if (desc.density >= 0) {
-            zig_desc.density = @enumFromInt(desc.density);
-        }
-        if (desc.primary_soil_type >= 0) {
-            zig_desc.primary_soil_type = @enumFromInt(desc.primary_soil_type);
-        }
-        if (desc.rock_strength >= 0) {
-            zig_desc.rock_strength = @enumFromInt(desc.rock_strength);
-        }
-        if (desc.weathering_grade >= 0) {
-            zig_desc.weathering_grade = @enumFromInt(desc.weathering_grade);
-        }
-        if (desc.rock_structure >= 0) {
-            zig_desc.rock_structure = @enumFromInt(desc.rock_structure);
-        }
-        if (desc.primary_rock_type >= 0) {
-            zig_desc.primary_rock_type = @enumFromInt(desc.primary_rock_type);
-        }
+        const zig_desc = cToZig(desc) orelse return null;
 
         const json = zig_desc.toJson(allocator) catch return null;
         const json_z = allocator.dupeZ(u8, json) catch return null;
@@ -191,8 +189,27 @@ export fn litholog_free_string(str: ?[*:0]const u8) void {
 }
 
 // Utility functions
+
+/// Returned by the *_from_string functions for unrecognised terms, and
+/// accepted by the *_to_string functions. Matches LITHOLOG_*_UNKNOWN.
+const unknown_code: i32 = types.unknown_value;
+
+/// Name for a stored integer value. Codes this build does not know (for
+/// example from a newer version) give "unknown" rather than a bad enum.
+fn enumString(comptime T: type, code: i32) [*:0]const u8 {
+    const value = std.meta.intToEnum(T, code) catch return "unknown";
+    const str = value.toString();
+    const z_str = allocator.dupeZ(u8, str) catch return "error";
+    return z_str.ptr;
+}
+
+fn enumCode(comptime T: type, text: [*:0]const u8) i32 {
+    const value = T.parse(std.mem.span(text)) catch return unknown_code;
+    return @intFromEnum(value);
+}
+
 export fn litholog_material_type_to_string(material_type: i32) [*:0]const u8 {
-    const mt: MaterialType = @enumFromInt(material_type);
+    const mt = std.meta.intToEnum(MaterialType, material_type) catch return "unknown";
     return switch (mt) {
         .soil => "soil",
         .rock => "rock",
@@ -200,52 +217,59 @@ export fn litholog_material_type_to_string(material_type: i32) [*:0]const u8 {
 }
 
 export fn litholog_consistency_to_string(consistency: i32) [*:0]const u8 {
-    const c: Consistency = @enumFromInt(consistency);
-    const str = c.toString();
-    const z_str = allocator.dupeZ(u8, str) catch return "error";
-    return z_str.ptr;
+    return enumString(Consistency, consistency);
 }
 
 export fn litholog_density_to_string(density: i32) [*:0]const u8 {
-    const d: Density = @enumFromInt(density);
-    const str = d.toString();
-    const z_str = allocator.dupeZ(u8, str) catch return "error";
-    return z_str.ptr;
+    return enumString(Density, density);
 }
 
 export fn litholog_rock_strength_to_string(strength: i32) [*:0]const u8 {
-    const rs: RockStrength = @enumFromInt(strength);
-    const str = rs.toString();
-    const z_str = allocator.dupeZ(u8, str) catch return "error";
-    return z_str.ptr;
+    return enumString(RockStrength, strength);
 }
 
 export fn litholog_soil_type_to_string(soil_type: i32) [*:0]const u8 {
-    const st: SoilType = @enumFromInt(soil_type);
-    const str = st.toString();
-    const z_str = allocator.dupeZ(u8, str) catch return "error";
-    return z_str.ptr;
+    return enumString(SoilType, soil_type);
 }
 
 export fn litholog_rock_type_to_string(rock_type: i32) [*:0]const u8 {
-    const rt: RockType = @enumFromInt(rock_type);
-    const str = rt.toString();
-    const z_str = allocator.dupeZ(u8, str) catch return "error";
-    return z_str.ptr;
+    return enumString(RockType, rock_type);
 }
 
 export fn litholog_weathering_grade_to_string(grade: i32) [*:0]const u8 {
-    const wg: WeatheringGrade = @enumFromInt(grade);
-    const str = wg.toString();
-    const z_str = allocator.dupeZ(u8, str) catch return "error";
-    return z_str.ptr;
+    return enumString(WeatheringGrade, grade);
 }
 
 export fn litholog_rock_structure_to_string(structure: i32) [*:0]const u8 {
-    const rs: RockStructure = @enumFromInt(structure);
-    const str = rs.toString();
-    const z_str = allocator.dupeZ(u8, str) catch return "error";
-    return z_str.ptr;
+    return enumString(RockStructure, structure);
+}
+
+export fn litholog_consistency_from_string(text: [*:0]const u8) i32 {
+    return enumCode(Consistency, text);
+}
+
+export fn litholog_density_from_string(text: [*:0]const u8) i32 {
+    return enumCode(Density, text);
+}
+
+export fn litholog_rock_strength_from_string(text: [*:0]const u8) i32 {
+    return enumCode(RockStrength, text);
+}
+
+export fn litholog_soil_type_from_string(text: [*:0]const u8) i32 {
+    return enumCode(SoilType, text);
+}
+
+export fn litholog_rock_type_from_string(text: [*:0]const u8) i32 {
+    return enumCode(RockType, text);
+}
+
+export fn litholog_weathering_grade_from_string(text: [*:0]const u8) i32 {
+    return enumCode(WeatheringGrade, text);
+}
+
+export fn litholog_rock_structure_from_string(text: [*:0]const u8) i32 {
+    return enumCode(RockStructure, text);
 }
 
 export fn litholog_strength_parameter_type_to_string(param_type: i32) [*:0]const u8 {
@@ -264,6 +288,14 @@ pub const litholog_version_minor = version.litholog_version_minor;
 pub const litholog_version_patch = version.litholog_version_patch;
 pub const litholog_version_string = version.litholog_version_string;
 
+/// 0 if the built-in corpus parses as expected, otherwise the 1-based number
+/// of the first failing case, or -1 if memory ran out
+export fn litholog_self_test() i32 {
+    const failure = bs5930.runSelfTest(allocator) catch return -1;
+    const failed = failure orelse return 0;
+    return @intCast(failed.index + 1);
+}
+
 // New feature exports
 
 const generator = @import("parser/generator.zig");
@@ -273,33 +305,7 @@ const fuzzy = @import("parser/fuzzy.zig");
 export fn litholog_generate_description(description: ?*const CSoilDescription) ?[*:0]const u8 {
     if (description) |desc| {
         // Convert C description back to Zig
-        var zig_desc = SoilDescription{
-            .raw_description = std.mem.span(desc.raw_description),
-            .material_type = @enumFromInt(desc.material_type),
-            .confidence = @floatCast(desc.confidence),
-        };
-
-        if (desc.consistency >= 0) {
-            zig_desc.consistency = @enumFromInt(desc.consistency);
-        }
-        if (desc.density >= 0) {
-            zig_desc.density = @enumFromInt(desc.density);
-        }
-        if (desc.primary_soil_type >= 0) {
-            zig_desc.primary_soil_type = @enumFromInt(desc.primary_soil_type);
-        }
-        if (desc.rock_strength >= 0) {
-            zig_desc.rock_strength = @enumFromInt(desc.rock_strength);
-        }
-        if (desc.weathering_grade >= 0) {
-            zig_desc.weathering_grade = @enumFromInt(desc.weathering_grade);
-        }
-        if (desc.rock_structure >= 0) {
-            zig_desc.rock_structure = @enumFromInt(desc.rock_structure);
-        }
-        if (desc.primary_rock_type >= 0) {
-            zig_desc.primary_rock_type = @enumFromInt(desc.primary_rock_type);
-        }
+        const zig_desc = cToZig(desc) orelse return null;
 
         const generated = generator.generate(zig_desc, allocator) catch return null;
         const generated_z = allocator.dupeZ(u8, generated) catch return null;
@@ -312,27 +318,7 @@ export fn litholog_generate_description(description: ?*const CSoilDescription) ?
 /// Generate a concise description
 export fn litholog_generate_concise(description: ?*const CSoilDescription) ?[*:0]const u8 {
     if (description) |desc| {
-        var zig_desc = SoilDescription{
-            .raw_description = std.mem.span(desc.raw_description),
-            .material_type = @enumFromInt(desc.material_type),
-            .confidence = @floatCast(desc.confidence),
-        };
-
-        if (desc.consistency >= 0) {
-            zig_desc.consistency = @enumFromInt(desc.consistency);
-        }
-        if (desc.density >= 0) {
-            zig_desc.density = @enumFromInt(desc.density);
-        }
-        if (desc.primary_soil_type >= 0) {
-            zig_desc.primary_soil_type = @enumFromInt(desc.primary_soil_type);
-        }
-        if (desc.rock_strength >= 0) {
-            zig_desc.rock_strength = @enumFromInt(desc.rock_strength);
-        }
-        if (desc.primary_rock_type >= 0) {
-            zig_desc.primary_rock_type = @enumFromInt(desc.primary_rock_type);
-        }
+        const zig_desc = cToZig(desc) orelse return null;
 
         const generated = generator.generateConcise(zig_desc, allocator) catch return null;
         const generated_z = allocator.dupeZ(u8, generated) catch return null;
@@ -363,6 +349,25 @@ export fn litholog_fuzzy_match(target: [*:0]const u8, options_ptr: [*][*:0]const
     return null;
 }
 
+/// The best k matches as a JSON array of {"match", "score"}, highest first
+export fn litholog_fuzzy_match_n(target: [*:0]const u8, options_ptr: [*]const [*:0]const u8, options_count: i32, k: i32) ?[*:0]const u8 {
+    if (options_count < 0 or k < 0) return null;
+
+    const options = allocator.alloc([]const u8, @intCast(options_count)) catch return null;
+    defer allocator.free(options);
+    for (options, 0..) |*option, i| option.* = std.mem.span(options_ptr[i]);
+
+    const candidates = fuzzy.fuzzyMatchN(std.mem.span(target), options, @intCast(k), .{}, allocator) catch return null;
+    defer allocator.free(candidates);
+
+    var json = std.ArrayList(u8).init(allocator);
+    defer json.deinit();
+    std.json.stringify(candidates, .{}, json.writer()) catch return null;
+
+    const json_z = allocator.dupeZ(u8, json.items) catch return null;
+    return json_z.ptr;
+}
+
 /// Calculate similarity between two strings (0.0 to 1.0)
 export fn litholog_similarity(s1: [*:0]const u8, s2: [*:0]const u8) f32 {
     const s1_slice = std.mem.span(s1);
@@ -371,12 +376,62 @@ export fn litholog_similarity(s1: [*:0]const u8, s2: [*:0]const u8) f32 {
     return fuzzy.similarityRatio(s1_slice, s2_slice, allocator) catch 0.0;
 }
 
+/// Similarity with a named algorithm: "levenshtein", "jaro_winkler" or
+/// "token_set". Returns -1.0 for an unknown algorithm.
+export fn litholog_similarity_with(s1: [*:0]const u8, s2: [*:0]const u8, algorithm: [*:0]const u8) f32 {
+    const selected = fuzzy.Algorithm.fromString(std.mem.span(algorithm)) orelse return -1.0;
+    return fuzzy.compare(std.mem.span(s1), std.mem.span(s2), .{ .algorithm = selected }, allocator) catch 0.0;
+}
+
+/// Fill `out` (targets_count * options_count floats, row-major by target)
+/// with the similarity of each target to each option. Returns 0 on success.
+export fn litholog_similarity_matrix(targets_ptr: [*]const [*:0]const u8, targets_count: i32, options_ptr: [*]const [*:0]const u8, options_count: i32, out: [*]f32) i32 {
+    if (targets_count < 0 or options_count < 0) return -1;
+
+    const targets = allocator.alloc([]const u8, @intCast(targets_count)) catch return -1;
+    defer allocator.free(targets);
+    for (targets, 0..) |*target, i| target.* = std.mem.span(targets_ptr[i]);
+
+    const options = allocator.alloc([]const u8, @intCast(options_count)) catch return -1;
+    defer allocator.free(options);
+    for (options, 0..) |*option, i| option.* = std.mem.span(options_ptr[i]);
+
+    const matrix = fuzzy.similarityMatrix(targets, options, allocator) catch return -1;
+    defer allocator.free(matrix);
+    @memcpy(out[0..matrix.len], matrix);
+
+    return 0;
+}
+
+/// Rewrite only the words behind one field of `raw`. `field` is a field name
+/// such as "consistency" or "primary_soil_type". Returns null if the field
+/// was not read from the text.
+export fn litholog_replace_attribute(raw: [*:0]const u8, field: [*:0]const u8, new_value: [*:0]const u8) ?[*:0]const u8 {
+    const target = std.meta.stringToEnum(types.Field, std.mem.span(field)) orelse return null;
+
+    var parser = bs5930.Parser.init(allocator);
+    const edited = parser.replaceAttribute(std.mem.span(raw), target, std.mem.span(new_value)) catch return null;
+    defer allocator.free(edited);
+
+    const edited_z = allocator.dupeZ(u8, edited) catch return null;
+    return edited_z.ptr;
+}
+
+/// Hex SHA-256 of what `description` means rather than how it is written,
+/// for spotting changes in meaning. Returns null if parsing fails.
+export fn litholog_canonical_hash(description: [*:0]const u8) ?[*:0]const u8 {
+    var parser = bs5930.Parser.init(allocator);
+    const hash = bs5930.canonicalHash(&parser, std.mem.span(description)) catch return null;
+    const hash_z = allocator.dupeZ(u8, &hash) catch return null;
+    return hash_z.ptr;
+}
+
 /// Generate a description from JSON string
 export fn litholog_generate_from_json(json_str: [*:0]const u8) ?[*:0]const u8 {
     const json_slice = std.mem.span(json_str);
 
-    // Parse JSON to SoilDescription
-    const desc = types.SoilDescription.fromJson(json_slice, allocator) catch return null;
+    // Parse JSON to GeologicalDescription
+    const desc = types.GeologicalDescription.fromJson(json_slice, allocator) catch return null;
     defer desc.deinit(allocator);
 
     // Generate standard format
@@ -390,8 +445,8 @@ export fn litholog_generate_from_json(json_str: [*:0]const u8) ?[*:0]const u8 {
 export fn litholog_generate_from_json_format(json_str: [*:0]const u8, format: i32) ?[*:0]const u8 {
     const json_slice = std.mem.span(json_str);
 
-    // Parse JSON to SoilDescription
-    const desc = types.SoilDescription.fromJson(json_slice, allocator) catch return null;
+    // Parse JSON to GeologicalDescription
+    const desc = types.GeologicalDescription.fromJson(json_slice, allocator) catch return null;
     defer desc.deinit(allocator);
 
     // Generate based on format: 0=standard, 1=concise, 2=verbose, 3=bs5930
@@ -406,3 +461,38 @@ export fn litholog_generate_from_json_format(json_str: [*:0]const u8, format: i3
     allocator.free(generated);
     return generated_z.ptr;
 }
+
+test "unknown enum values round-trip as absent" {
+    const stored = CSoilDescription{
+        .raw_description = "Firm CLAY",
+        .material_type = @intFromEnum(MaterialType.soil),
+        .consistency = unknown_code,
+        .density = -1,
+        .primary_soil_type = litholog_soil_type_from_string("not a soil"),
+        .rock_strength = unknown_code,
+        .weathering_grade = 200,
+        .rock_structure = unknown_code,
+        .primary_rock_type = unknown_code,
+        .secondary_constituents = undefined,
+        .secondary_constituents_count = 0,
+        .strength_parameters = null,
+        .has_strength_parameters = 0,
+        .confidence = 1.0,
+    };
+    try std.testing.expectEqual(unknown_code, stored.primary_soil_type);
+
+    const json = litholog_description_to_json(&stored).?;
+    defer litholog_free_string(json);
+    try std.testing.expect(std.mem.indexOf(u8, std.mem.span(json), "\"consistency\":\"") == null);
+
+    const generated = litholog_generate_description(&stored).?;
+    defer litholog_free_string(generated);
+    const concise = litholog_generate_concise(&stored).?;
+    defer litholog_free_string(concise);
+
+    var invalid = stored;
+    invalid.material_type = unknown_code;
+    try std.testing.expect(litholog_description_to_json(&invalid) == null);
+    try std.testing.expect(litholog_generate_description(&invalid) == null);
+    try std.testing.expect(litholog_generate_concise(&invalid) == null);
+}
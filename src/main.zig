@@ -4,7 +4,10 @@ const cli = @import("cli.zig");
 const tui = @import("tui.zig");
 const web = @import("web.zig");
 const ags_cli = @import("ags_cli.zig");
+const bench_cli = @import("bench_cli.zig");
 const version = @import("version.zig");
+const bs5930 = @import("parser/bs5930.zig");
+const build_options = @import("build_options");
 
 const KnownCommand = struct {
     name: []const u8,
@@ -23,6 +26,8 @@ const known_commands = [_]KnownCommand{
     .{ .name = "convert", .description = "Convert between JSON and text descriptions" },
     .{ .name = "web", .description = "Launch web UI" },
     .{ .name = "tui", .description = "Interactive terminal mode" },
+    .{ .name = "selftest", .description = "Check the parser against a built-in corpus" },
+    .{ .name = "bench", .description = "Measure parsing throughput, latency and memory on a CSV file" },
     .{ .name = "version", .description = "Show version info" },
     .{ .name = "help", .description = "Show help for commands" },
     .{ .name = "completions", .description = "Generate shell completion scripts" },
@@ -46,7 +51,7 @@ pub fn main() !void {
     const args = try std.process.argsAlloc(allocator);
     defer std.process.argsFree(allocator, args);
 
-    const launched_via_doubleclick = args.len == 1 and !isStdinTTY();
+    const launched_via_doubleclick = build_options.server and args.len == 1 and !isStdinTTY();
     if (launched_via_doubleclick) {
         var server = try web.WebServer.init(allocator, 8080);
         defer server.deinit();
@@ -83,6 +88,12 @@ pub fn main() !void {
         return if (json_output) printCommandHelpJson(clean_sub_args[0]) else printCommandHelp(clean_sub_args[0]);
     }
     if (std.mem.eql(u8, cmd, "version")) return if (json_output) printVersionJson() else printLongVersion();
+    if (std.mem.eql(u8, cmd, "selftest")) return runSelfTest(allocator, json_output);
+    if (std.mem.eql(u8, cmd, "bench")) {
+        if (!build_options.bench) exitNotBuilt(cmd, "bench");
+        if (clean_sub_args.len == 0 or hasHelpFlag(clean_sub_args)) return printBenchHelp();
+        return bench_cli.handle(allocator, clean_sub_args, json_output);
+    }
     if (std.mem.eql(u8, cmd, "completions")) {
         if (json_output) return printCompletionsJson(clean_sub_args);
         printCompletions(clean_sub_args) catch {
@@ -93,12 +104,14 @@ pub fn main() !void {
     }
 
     if (std.mem.eql(u8, cmd, "web") or std.mem.eql(u8, cmd, "gui")) {
+        if (!build_options.server) exitNotBuilt(cmd, "server");
         var server = try web.WebServer.init(allocator, 8080);
         defer server.deinit();
         try server.start();
         return;
     }
     if (std.mem.eql(u8, cmd, "tui")) {
+        if (!build_options.tui) exitNotBuilt(cmd, "tui");
         var litholog_tui = try tui.Tui.init(allocator);
         defer litholog_tui.deinit();
         try litholog_tui.run();
@@ -130,6 +143,7 @@ pub fn main() !void {
         return runMappedCli(allocator, try mapGenerateCommand(allocator, effective_flags, clean_sub_args));
     }
     if (std.mem.eql(u8, cmd, "units")) {
+        if (!build_options.correlation) exitNotBuilt(cmd, "correlation");
         if (clean_sub_args.len == 0 or hasHelpFlag(clean_sub_args)) return printCsvHelp();
         return runMappedCli(allocator, try mapUnitsCommand(allocator, effective_flags, clean_sub_args));
     }
@@ -155,11 +169,31 @@ fn runLegacyCli(allocator: std.mem.Allocator, args: [][:0]u8) !void {
             return;
         },
         error.MissingModeArgument => {
-            std.debug.print("Error: --mode option requires a mode (compact, verbose, pretty, summary)\n", .{});
+            std.debug.print("Error: --mode option requires a mode (compact, verbose, pretty, summary, conll)\n", .{});
             return;
         },
         error.InvalidOutputMode => {
-            std.debug.print("Error: Invalid output mode. Use: compact, verbose, pretty, or summary\n", .{});
+            std.debug.print("Error: Invalid output mode. Use: compact, verbose, pretty, summary, or conll\n", .{});
+            return;
+        },
+        error.MissingCapitalizationArgument, error.InvalidCapitalizationPolicy => {
+            std.debug.print("Error: --capitalization requires a policy (required, preferred, ignored)\n", .{});
+            return;
+        },
+        error.MissingReportArgument => {
+            std.debug.print("Error: --report requires an output file (.csv or .html)\n", .{});
+            return;
+        },
+        error.LintWithReport => {
+            std.debug.print("Error: --lint and --report cannot be used together\n", .{});
+            return;
+        },
+        error.MissingProfileArgument => {
+            std.debug.print("Error: --profile requires a TOML or YAML profile file\n", .{});
+            return;
+        },
+        error.MissingSuppressArgument, error.UnknownValidationRule => {
+            std.debug.print("Error: --suppress requires comma-separated rule codes (e.g. W002,E010)\n", .{});
             return;
         },
         error.UnknownOption => {
@@ -188,6 +222,7 @@ fn runMappedCli(allocator: std.mem.Allocator, mapped: []const []const u8) !void
 }
 
 fn runAgsCommand(allocator: std.mem.Allocator, cmd: []const u8, sub_args: []const [:0]u8, json_output: bool) !void {
+    if (!build_options.ags) exitNotBuilt(cmd, "ags");
     var zargs = std.ArrayList([:0]u8).init(allocator);
     defer {
         for (zargs.items) |item| allocator.free(item);
@@ -444,6 +479,8 @@ fn printRootHelp() !void {
         \\  convert     Convert between JSON and text descriptions
         \\  web         Launch the web-based GUI
         \\  tui         Interactive terminal mode
+        \\  selftest    Check the parser against a built-in corpus
+        \\  bench       Measure throughput, latency and memory on a CSV file
         \\  version     Show version details
         \\  completions Generate shell completion scripts
         \\
@@ -470,6 +507,7 @@ fn printCommandHelp(command: []const u8) !void {
     if (std.mem.eql(u8, command, "parse")) return printParseHelp();
     if (std.mem.eql(u8, command, "generate")) return printGenerateHelp();
     if (std.mem.eql(u8, command, "convert")) return printConvertHelp();
+    if (std.mem.eql(u8, command, "bench")) return printBenchHelp();
     return printRootHelp();
 }
 
@@ -479,6 +517,7 @@ fn printCommandHelpJson(command: []const u8) !void {
     if (std.mem.eql(u8, command, "csv")) return std.io.getStdOut().writer().writeAll("{\"command\":\"csv\"}\n");
     if (std.mem.eql(u8, command, "generate")) return std.io.getStdOut().writer().writeAll("{\"command\":\"generate\"}\n");
     if (std.mem.eql(u8, command, "convert")) return std.io.getStdOut().writer().writeAll("{\"command\":\"convert\"}\n");
+    if (std.mem.eql(u8, command, "bench")) return std.io.getStdOut().writer().writeAll("{\"command\":\"bench\"}\n");
     return printRootHelpJson();
 }
 
@@ -540,6 +579,7 @@ fn printCsvHelp() !void {
         \\      --column <NAME|INDEX>     Column containing descriptions (required)
         \\      --output-columns <COLS>   Comma-separated result columns to append
         \\      --no-header               Input has no header row
+        \\      --checkpoint <FILE>       Save progress and resume from FILE after a crash
         \\      --excel                   Export as Excel (.xlsx)
         \\      --freeze-header           Freeze header row (Excel only)
         \\      --auto-filter             Enable auto-filter (Excel only)
@@ -569,6 +609,27 @@ fn printConvertHelp() !void {
     );
 }
 
+fn printBenchHelp() !void {
+    try std.io.getStdOut().writer().writeAll(
+        \\Measure parsing throughput, latency percentiles and memory on a CSV file
+        \\
+        \\Usage:
+        \\  litholog bench --input <FILE.csv> [flags]
+        \\  litholog bench --corpus [flags]
+        \\
+        \\Flags:
+        \\  -i, --input <FILE>         CSV file of descriptions
+        \\      --corpus               Time the built-in example corpus instead
+        \\      --column <NAME|INDEX>  Column containing descriptions (default: Description)
+        \\      --no-header            Input has no header row; --column is an index (default: 0)
+        \\      --repeat <N>           Passes over the input (default: 1)
+        \\      --json                 Write the report as JSON
+        \\
+        \\Build with -Doptimize=ReleaseFast for representative numbers.
+        \\
+    );
+}
+
 fn printShortVersion() !void {
     try std.io.getStdOut().writer().print("litholog v{s}\n", .{version.VERSION_STRING});
 }
@@ -588,6 +649,30 @@ fn printLongVersion() !void {
     });
 }
 
+/// Exit with status 1 if the parser gets the built-in corpus wrong, e.g.
+/// after a bad build; suitable as a deployment or container health check
+fn runSelfTest(allocator: std.mem.Allocator, json_output: bool) !void {
+    const failure = try bs5930.runSelfTest(allocator);
+    const out = std.io.getStdOut().writer();
+
+    if (failure) |failed| {
+        if (json_output) {
+            try out.writeAll("{\"ok\":false,\"description\":");
+            try std.json.stringify(failed.description, .{}, out);
+            try out.print(",\"field\":\"{s}\"}}\n", .{failed.field});
+        } else {
+            try std.io.getStdErr().writer().print("Self-test failed: \"{s}\" gave the wrong {s}\n", .{ failed.description, failed.field });
+        }
+        std.process.exit(1);
+    }
+
+    if (json_output) {
+        try out.writeAll("{\"ok\":true}\n");
+    } else {
+        try out.writeAll("Self-test passed\n");
+    }
+}
+
 fn printVersionJson() !void {
     try std.io.getStdOut().writer().print(
         "{{\"name\":\"litholog\",\"version\":\"{s}\",\"zig\":\"{s}\",\"platform\":\"{s}/{s}\"}}\n",
@@ -597,7 +682,7 @@ fn printVersionJson() !void {
 
 fn printRootHelpJson() !void {
     try std.io.getStdOut().writer().writeAll(
-        "{\"name\":\"litholog\",\"commands\":[\"parse\",\"csv\",\"ags\",\"inspect\",\"enhance\",\"validate\",\"generate\",\"units\",\"convert\",\"web\",\"tui\",\"version\",\"help\",\"completions\"]}\n",
+        "{\"name\":\"litholog\",\"commands\":[\"parse\",\"csv\",\"ags\",\"inspect\",\"enhance\",\"validate\",\"generate\",\"units\",\"convert\",\"web\",\"tui\",\"selftest\",\"bench\",\"version\",\"help\",\"completions\"]}\n",
     );
 }
 
@@ -611,7 +696,7 @@ fn printCompletions(args: []const [:0]u8) !void {
             \\_litholog() {
             \\  local cur prev words cword
             \\  _init_completion || return
-            \\  local commands="parse csv ags inspect enhance validate generate units convert web tui version help completions"
+            \\  local commands="parse csv ags inspect enhance validate generate units convert web tui selftest bench version help completions"
             \\  if [[ ${cword} -eq 1 ]]; then
             \\    COMPREPLY=( $(compgen -W "${commands}" -- "${cur}") )
             \\    return
@@ -632,7 +717,7 @@ fn printCompletions(args: []const [:0]u8) !void {
             \\_litholog() {
             \\  local -a commands
             \\  local -a ags_commands
-            \\  commands=('parse:Parse descriptions' 'csv:Process CSV/Excel' 'ags:AGS workflows' 'inspect:Inspect AGS (legacy)' 'enhance:Enhance AGS (legacy)' 'validate:Validate AGS (legacy)' 'generate:Generate descriptions' 'units:Identify units' 'convert:Convert JSON/text' 'web:Launch web UI' 'tui:Interactive TUI' 'version:Show version' 'help:Show help' 'completions:Generate completions')
+            \\  commands=('parse:Parse descriptions' 'csv:Process CSV/Excel' 'ags:AGS workflows' 'inspect:Inspect AGS (legacy)' 'enhance:Enhance AGS (legacy)' 'validate:Validate AGS (legacy)' 'generate:Generate descriptions' 'units:Identify units' 'convert:Convert JSON/text' 'web:Launch web UI' 'tui:Interactive TUI' 'selftest:Check the parser' 'bench:Benchmark parsing' 'version:Show version' 'help:Show help' 'completions:Generate completions')
             \\  ags_commands=('inspect:Inspect AGS' 'enhance:Enhance AGS' 'validate:Validate AGS')
             \\  _arguments '1:command:->commands' '2:subcommand:->subcommands' && return
             \\  case $state in
@@ -662,6 +747,8 @@ fn printCompletions(args: []const [:0]u8) !void {
             \\complete -c litholog -n '__fish_use_subcommand' -a convert -d 'Convert JSON/text'
             \\complete -c litholog -n '__fish_use_subcommand' -a web -d 'Launch web UI'
             \\complete -c litholog -n '__fish_use_subcommand' -a tui -d 'Interactive TUI'
+            \\complete -c litholog -n '__fish_use_subcommand' -a selftest -d 'Check the parser'
+            \\complete -c litholog -n '__fish_use_subcommand' -a bench -d 'Benchmark parsing'
             \\complete -c litholog -n '__fish_use_subcommand' -a version -d 'Show version'
             \\complete -c litholog -n '__fish_use_subcommand' -a help -d 'Show help'
             \\complete -c litholog -n '__fish_use_subcommand' -a completions -d 'Generate completions'
@@ -684,6 +771,12 @@ fn printLegacyAgsNotice(cmd: []const u8) !void {
     );
 }
 
+/// For a command whose subsystem was left out with -D<option>=false
+fn exitNotBuilt(cmd: []const u8, option: []const u8) noreturn {
+    std.io.getStdErr().writer().print("Error: '{s}' is not included in this build; rebuild with -D{s}=true\n", .{ cmd, option }) catch {};
+    std.process.exit(2);
+}
+
 fn printUnknownCommand(cmd: []const u8) !void {
     const err = std.io.getStdErr().writer();
     try err.print("Error: unknown command \"{s}\"\n\n", .{cmd});
@@ -761,3 +854,19 @@ test "closestCommand suggests ags for ag" {
     const match = closestCommand("ag").?;
     try std.testing.expectEqualStrings("ags", match.name);
 }
+
+test "repeated --suppress options add up" {
+    var litholog_cli = cli.Cli.init(std.testing.allocator);
+    var argv = [_][:0]u8{
+        @constCast("litholog"),
+        @constCast("--suppress"),
+        @constCast("W002"),
+        @constCast("--suppress"),
+        @constCast("W003"),
+    };
+    var cli_args = try litholog_cli.parseArgs(&argv);
+    defer cli_args.deinit();
+
+    try std.testing.expectEqual(@as(usize, 2), cli_args.suppressed_rules.?.len);
+    try std.testing.expectEqualStrings("W003", cli_args.suppressed_rules.?[1]);
+}
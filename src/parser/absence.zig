@@ -0,0 +1,148 @@
+const std = @import("std");
+const types = @import("types.zig");
+const lexer = @import("lexer.zig");
+
+const Token = lexer.Token;
+
+/// A negated phrase found in the token stream. Slices point into the lexed text.
+pub const Match = struct {
+    absence: types.Absence,
+    first_token: usize,
+    last_token: usize,
+    /// Whether the covered tokens describe the absent feature and must not be
+    /// read as positive detections. "non-plastic" is itself a valid value.
+    suppress_tokens: bool = true,
+};
+
+const negations = [_][]const u8{ "no", "not", "without", "nil" };
+
+// Words between a negation and the feature it negates
+const fillers = [_][]const u8{ "visible", "apparent", "obvious", "recovery", "evidence", "signs", "trace", "traces", "any", "of" };
+
+// Words that end a negated feature: "no roots and rare gravel"
+const stop_words = [_][]const u8{ "and", "with", "but", "or" };
+
+/// Find negative statements such as "no visible organic matter",
+/// "no recovery of fines" and "non-plastic"
+pub fn find(allocator: std.mem.Allocator, text: []const u8, tokens: []const Token) ![]Match {
+    var matches = std.ArrayList(Match).init(allocator);
+    errdefer matches.deinit();
+
+    var i: usize = 0;
+    while (i < tokens.len) : (i += 1) {
+        const token = tokens[i];
+
+        if (token.type == .plasticity_index and std.ascii.startsWithIgnoreCase(token.value, "non")) {
+            try matches.append(Match{
+                .absence = .{ .feature = "plasticity", .phrase = text[token.start..token.end] },
+                .first_token = i,
+                .last_token = i,
+                .suppress_tokens = false,
+            });
+            continue;
+        }
+
+        if (token.type != .word or !isOneOf(token.value, &negations)) continue;
+
+        var j = i + 1;
+        while (j < tokens.len and !clauseBreak(text, tokens[j - 1], tokens[j]) and isOneOf(tokens[j].value, &fillers)) {
+            j += 1;
+        }
+
+        const feature_start = j;
+        while (j < tokens.len and !clauseBreak(text, tokens[j - 1], tokens[j]) and !endsFeature(text, tokens[j])) {
+            j += 1;
+        }
+        if (j == feature_start) continue;
+
+        const last = j - 1;
+        try matches.append(Match{
+            .absence = .{
+                .feature = text[tokens[feature_start].start..tokens[last].end],
+                .phrase = text[token.start..tokens[last].end],
+            },
+            .first_token = i,
+            .last_token = last,
+        });
+        i = last;
+    }
+
+    return matches.toOwnedSlice();
+}
+
+/// Punctuation between two tokens ends a clause
+fn clauseBreak(text: []const u8, previous: Token, next: Token) bool {
+    if (next.start < previous.end) return false;
+    for (text[previous.end..next.start]) |ch| {
+        switch (ch) {
+            ',', ';', '.', ':', '(', ')', '[', ']' => return true,
+            else => {},
+        }
+    }
+    return false;
+}
+
+fn endsFeature(text: []const u8, token: Token) bool {
+    if (isOneOf(token.value, &stop_words)) return true;
+    // An upper-case soil or rock name is the primary type, not part of the negated phrase
+    return (token.type == .soil_type or token.type == .rock_type) and isUpperCase(text[token.start..token.end]);
+}
+
+fn isOneOf(word: []const u8, list: []const []const u8) bool {
+    for (list) |candidate| {
+        if (std.ascii.eqlIgnoreCase(word, candidate)) return true;
+    }
+    return false;
+}
+
+fn isUpperCase(word: []const u8) bool {
+    for (word) |ch| {
+        if (std.ascii.isLower(ch)) return false;
+    }
+    return true;
+}
+
+test "find absence statements" {
+    const allocator = std.testing.allocator;
+    const text = "Firm brown CLAY with no visible organic matter, no recovery of fines";
+
+    var lex = lexer.Lexer.init(allocator, text);
+    defer lex.deinit();
+    const tokens = try lex.tokenize();
+    defer {
+        for (tokens) |token| {
+            if (token.corrected_from) |_| allocator.free(token.value);
+        }
+        allocator.free(tokens);
+    }
+
+    const matches = try find(allocator, text, tokens);
+    defer allocator.free(matches);
+
+    try std.testing.expectEqual(@as(usize, 2), matches.len);
+    try std.testing.expectEqualStrings("organic matter", matches[0].absence.feature);
+    try std.testing.expectEqualStrings("no visible organic matter", matches[0].absence.phrase);
+    try std.testing.expectEqualStrings("fines", matches[1].absence.feature);
+}
+
+test "non-plastic is an absence that keeps its token" {
+    const allocator = std.testing.allocator;
+    const text = "Loose non-plastic SILT";
+
+    var lex = lexer.Lexer.init(allocator, text);
+    defer lex.deinit();
+    const tokens = try lex.tokenize();
+    defer {
+        for (tokens) |token| {
+            if (token.corrected_from) |_| allocator.free(token.value);
+        }
+        allocator.free(tokens);
+    }
+
+    const matches = try find(allocator, text, tokens);
+    defer allocator.free(matches);
+
+    try std.testing.expectEqual(@as(usize, 1), matches.len);
+    try std.testing.expectEqualStrings("plasticity", matches[0].absence.feature);
+    try std.testing.expect(!matches[0].suppress_tokens);
+}
@@ -0,0 +1,202 @@
+const std = @import("std");
+const types = @import("types.zig");
+const canonical = @import("canonical.zig");
+const sink_mod = @import("sink.zig");
+
+const GeologicalDescription = types.GeologicalDescription;
+const Hash = canonical.Hash;
+const RockType = types.RockType;
+const SoilType = types.SoilType;
+
+/// Descriptions sharing one canonical hash
+pub const Group = struct {
+    count: usize = 0,
+    /// Up to `Options.sample_size` raw descriptions, drawn uniformly from all
+    /// `count` of them
+    samples: std.ArrayList([]u8),
+};
+
+/// One group as returned by `Aggregator.groups`
+pub const Summary = struct {
+    signature: Hash,
+    count: usize,
+    /// Owned by the aggregator
+    samples: []const []const u8,
+};
+
+/// Counts by primary soil or rock type
+pub const Tallies = struct {
+    total: usize = 0,
+    distinct: usize = 0,
+    soil: std.EnumArray(SoilType, usize) = std.EnumArray(SoilType, usize).initFill(0),
+    rock: std.EnumArray(RockType, usize) = std.EnumArray(RockType, usize).initFill(0),
+    /// No primary soil or rock type
+    untyped: usize = 0,
+};
+
+/// Collects parsed descriptions from any number of worker threads. Each
+/// description is grouped by its canonical hash, so rewordings of the same
+/// meaning count together, and a few of the original strings are kept per
+/// group to show what was written. `allocator` must be thread-safe when
+/// workers share the aggregator.
+pub const Aggregator = struct {
+    allocator: std.mem.Allocator,
+    mutex: std.Thread.Mutex = .{},
+    options: Options,
+    prng: std.Random.DefaultPrng,
+    by_signature: std.AutoHashMap(Hash, Group),
+    tallies: Tallies = .{},
+
+    pub const Options = struct {
+        /// Raw descriptions kept per group
+        sample_size: usize = 5,
+        /// Seed for choosing samples, so runs over the same input agree
+        seed: u64 = 0,
+    };
+
+    pub fn init(allocator: std.mem.Allocator, options: Options) Aggregator {
+        return Aggregator{
+            .allocator = allocator,
+            .options = options,
+            .prng = std.Random.DefaultPrng.init(options.seed),
+            .by_signature = std.AutoHashMap(Hash, Group).init(allocator),
+        };
+    }
+
+    pub fn deinit(self: *Aggregator) void {
+        var iter = self.by_signature.valueIterator();
+        while (iter.next()) |group| {
+            for (group.samples.items) |sample| self.allocator.free(sample);
+            group.samples.deinit();
+        }
+        self.by_signature.deinit();
+    }
+
+    /// Count one description. Safe to call from several threads at once.
+    pub fn add(self: *Aggregator, desc: *const GeologicalDescription) !void {
+        // Hashing is the expensive part, so it happens outside the lock
+        const signature = try canonical.hashOf(self.allocator, desc.*);
+
+        self.mutex.lock();
+        defer self.mutex.unlock();
+
+        const entry = try self.by_signature.getOrPut(signature);
+        if (!entry.found_existing) {
+            entry.value_ptr.* = Group{ .samples = std.ArrayList([]u8).init(self.allocator) };
+            self.tallies.distinct += 1;
+        }
+        const group = entry.value_ptr;
+        try self.sample(group, desc.raw_description);
+        group.count += 1;
+
+        self.tallies.total += 1;
+        if (desc.primary_soil_type) |soil_type| {
+            self.tallies.soil.getPtr(soil_type).* += 1;
+        } else if (desc.primary_rock_type) |rock_type| {
+            self.tallies.rock.getPtr(rock_type).* += 1;
+        } else {
+            self.tallies.untyped += 1;
+        }
+    }
+
+    /// Reservoir sampling: the n-th description of a group replaces a kept
+    /// one with probability sample_size / n
+    fn sample(self: *Aggregator, group: *Group, raw: []const u8) !void {
+        const seen = group.count + 1;
+        if (group.samples.items.len < self.options.sample_size) {
+            const copy = try self.allocator.dupe(u8, raw);
+            errdefer self.allocator.free(copy);
+            try group.samples.append(copy);
+            return;
+        }
+        const slot = self.prng.random().uintLessThan(usize, seen);
+        if (slot >= group.samples.items.len) return;
+        const copy = try self.allocator.dupe(u8, raw);
+        self.allocator.free(group.samples.items[slot]);
+        group.samples.items[slot] = copy;
+    }
+
+    /// Counts so far
+    pub fn snapshot(self: *Aggregator) Tallies {
+        self.mutex.lock();
+        defer self.mutex.unlock();
+        return self.tallies;
+    }
+
+    /// Every group, most frequent first. Call once the workers have finished:
+    /// the samples belong to the aggregator and a later `add` may free them.
+    /// Free the returned slice with `allocator`.
+    pub fn groups(self: *Aggregator, allocator: std.mem.Allocator) ![]Summary {
+        self.mutex.lock();
+        defer self.mutex.unlock();
+
+        const summaries = try allocator.alloc(Summary, self.by_signature.count());
+        var iter = self.by_signature.iterator();
+        var i: usize = 0;
+        while (iter.next()) |entry| : (i += 1) {
+            summaries[i] = Summary{
+                .signature = entry.key_ptr.*,
+                .count = entry.value_ptr.count,
+                .samples = entry.value_ptr.samples.items,
+            };
+        }
+        std.mem.sort(Summary, summaries, {}, moreFrequent);
+        return summaries;
+    }
+
+    /// Aggregate the results of a CSV job or any other sink producer
+    pub fn sink(self: *Aggregator) sink_mod.Sink {
+        return sink_mod.Sink{ .ptr = self, .vtable = &.{ .write = writeFn, .flush = flushFn } };
+    }
+
+    fn writeFn(ptr: *anyopaque, result: sink_mod.Result) anyerror!void {
+        const self: *Aggregator = @ptrCast(@alignCast(ptr));
+        try self.add(result.description);
+    }
+
+    fn flushFn(_: *anyopaque) anyerror!void {}
+};
+
+fn moreFrequent(_: void, a: Summary, b: Summary) bool {
+    if (a.count != b.count) return a.count > b.count;
+    return std.mem.lessThan(u8, &a.signature, &b.signature);
+}
+
+test "aggregate descriptions from several threads" {
+    // The testing allocator is not thread-safe on its own
+    var thread_safe = std.heap.ThreadSafeAllocator{ .child_allocator = std.testing.allocator };
+    const allocator = thread_safe.allocator();
+
+    var aggregator = Aggregator.init(allocator, .{ .sample_size = 2 });
+    defer aggregator.deinit();
+
+    const descriptions = [_]GeologicalDescription{
+        .{ .raw_description = "Firm CLAY", .material_type = .soil, .consistency = .firm, .primary_soil_type = .clay },
+        .{ .raw_description = "firm clay", .material_type = .soil, .consistency = .firm, .primary_soil_type = .clay },
+        .{ .raw_description = "FIRM CLAY", .material_type = .soil, .consistency = .firm, .primary_soil_type = .clay },
+        .{ .raw_description = "Strong LIMESTONE", .material_type = .rock, .rock_strength = .strong, .primary_rock_type = .limestone },
+    };
+
+    const Worker = struct {
+        fn run(target: *Aggregator, items: []const GeologicalDescription) void {
+            for (items) |*item| target.add(item) catch @panic("add failed");
+        }
+    };
+    var threads: [4]std.Thread = undefined;
+    for (&threads) |*thread| thread.* = try std.Thread.spawn(.{}, Worker.run, .{ &aggregator, &descriptions });
+    for (threads) |thread| thread.join();
+
+    const tallies = aggregator.snapshot();
+    try std.testing.expectEqual(@as(usize, 16), tallies.total);
+    try std.testing.expectEqual(@as(usize, 2), tallies.distinct);
+    try std.testing.expectEqual(@as(usize, 12), tallies.soil.get(.clay));
+    try std.testing.expectEqual(@as(usize, 4), tallies.rock.get(.limestone));
+
+    const summaries = try aggregator.groups(allocator);
+    defer allocator.free(summaries);
+    try std.testing.expectEqual(@as(usize, 2), summaries.len);
+    try std.testing.expectEqual(@as(usize, 12), summaries[0].count);
+    try std.testing.expectEqual(@as(usize, 2), summaries[0].samples.len);
+    try std.testing.expect(std.ascii.eqlIgnoreCase(summaries[0].samples[0], "firm clay"));
+    try std.testing.expectEqualStrings("Strong LIMESTONE", summaries[1].samples[0]);
+}
@@ -2,7 +2,7 @@ const std = @import("std");
 const types = @import("types.zig");
 const terminology = @import("terminology.zig");
 
-const SoilDescription = types.SoilDescription;
+const GeologicalDescription = types.GeologicalDescription;
 const MaterialType = types.MaterialType;
 const SoilType = types.SoilType;
 const Consistency = types.Consistency;
@@ -102,7 +102,7 @@ pub const AnomalyDetector = struct {
     }
 
     /// Detect all anomalies in a soil description
-    pub fn detect(self: *AnomalyDetector, description: *const SoilDescription) !AnomalyResult {
+    pub fn detect(self: *AnomalyDetector, description: *const GeologicalDescription) !AnomalyResult {
         var anomalies = std.ArrayList(Anomaly).init(self.allocator);
         errdefer {
             for (anomalies.items) |*anomaly| {
@@ -152,7 +152,7 @@ pub const AnomalyDetector = struct {
 
     fn checkMismatchedStrengthDescriptor(
         self: *AnomalyDetector,
-        description: *const SoilDescription,
+        description: *const GeologicalDescription,
         anomalies: *std.ArrayList(Anomaly),
     ) !void {
         if (description.material_type != .soil) return;
@@ -205,7 +205,7 @@ pub const AnomalyDetector = struct {
 
     fn checkMissingStrengthDescriptor(
         self: *AnomalyDetector,
-        description: *const SoilDescription,
+        description: *const GeologicalDescription,
         anomalies: *std.ArrayList(Anomaly),
     ) !void {
         if (description.material_type != .soil) return;
@@ -258,7 +258,7 @@ pub const AnomalyDetector = struct {
 
     fn checkUnusualConstituentCombination(
         self: *AnomalyDetector,
-        description: *const SoilDescription,
+        description: *const GeologicalDescription,
         anomalies: *std.ArrayList(Anomaly),
     ) !void {
         if (description.secondary_constituents.len == 0) return;
@@ -316,7 +316,7 @@ pub const AnomalyDetector = struct {
 
     fn checkConflictingProperties(
         self: *AnomalyDetector,
-        description: *const SoilDescription,
+        description: *const GeologicalDescription,
         anomalies: *std.ArrayList(Anomaly),
     ) !void {
         // Check for both consistency and density (should not both be present)
@@ -342,7 +342,7 @@ pub const AnomalyDetector = struct {
 
     fn checkOutOfRangeStrength(
         self: *AnomalyDetector,
-        description: *const SoilDescription,
+        description: *const GeologicalDescription,
         anomalies: *std.ArrayList(Anomaly),
     ) !void {
         if (description.strength_parameters) |params| {
@@ -367,7 +367,7 @@ pub const AnomalyDetector = struct {
 
     fn checkInvalidTransitionRange(
         self: *AnomalyDetector,
-        description: *const SoilDescription,
+        description: *const GeologicalDescription,
         anomalies: *std.ArrayList(Anomaly),
     ) !void {
         _ = self;
@@ -381,7 +381,7 @@ pub const AnomalyDetector = struct {
 
     fn checkExcessiveConstituents(
         self: *AnomalyDetector,
-        description: *const SoilDescription,
+        description: *const GeologicalDescription,
         anomalies: *std.ArrayList(Anomaly),
     ) !void {
         // More than 3 secondary constituents is unusual
@@ -407,7 +407,7 @@ pub const AnomalyDetector = struct {
 
     fn checkDuplicateConstituents(
         self: *AnomalyDetector,
-        description: *const SoilDescription,
+        description: *const GeologicalDescription,
         anomalies: *std.ArrayList(Anomaly),
     ) !void {
         if (description.secondary_constituents.len < 2) return;
@@ -440,7 +440,7 @@ pub const AnomalyDetector = struct {
 
     fn checkSpellingCorrections(
         self: *AnomalyDetector,
-        description: *const SoilDescription,
+        description: *const GeologicalDescription,
         anomalies: *std.ArrayList(Anomaly),
     ) !void {
         for (description.spelling_corrections) |correction| {
@@ -0,0 +1,61 @@
+const std = @import("std");
+const types = @import("types.zig");
+
+const MaterialOrigin = types.MaterialOrigin;
+
+/// A mine waste term found in a description
+pub const Match = struct {
+    origin: MaterialOrigin,
+    start: usize,
+    end: usize,
+};
+
+const terms = [_]struct { term: []const u8, origin: MaterialOrigin }{
+    .{ .term = "tailings", .origin = .tailings },
+    .{ .term = "mine tailings", .origin = .tailings },
+    .{ .term = "spoil", .origin = .spoil },
+    .{ .term = "colliery spoil", .origin = .spoil },
+    .{ .term = "mine spoil", .origin = .spoil },
+    .{ .term = "mine waste", .origin = .spoil },
+    .{ .term = "slag", .origin = .slag },
+    .{ .term = "furnace slag", .origin = .slag },
+};
+
+/// The mine waste the stratum is made of ("loose silty SAND sized TAILINGS").
+/// Terms after "with" describe inclusions, not the material, so "sandy
+/// GRAVEL with slag" has no origin.
+pub fn find(text: []const u8) ?Match {
+    var pos: usize = 0;
+    while (pos < text.len) : (pos += 1) {
+        if (pos > 0 and std.ascii.isAlphanumeric(text[pos - 1])) continue;
+        if (wordIs(text, pos, "with")) return null;
+
+        var best: ?Match = null;
+        for (terms) |entry| {
+            if (!wordIs(text, pos, entry.term)) continue;
+            const end = pos + entry.term.len;
+            if (best) |current| {
+                if (current.end >= end) continue;
+            }
+            best = Match{ .origin = entry.origin, .start = pos, .end = end };
+        }
+        if (best) |match| return match;
+    }
+    return null;
+}
+
+fn wordIs(text: []const u8, pos: usize, word: []const u8) bool {
+    if (!std.ascii.startsWithIgnoreCase(text[pos..], word)) return false;
+    const end = pos + word.len;
+    return end == text.len or !std.ascii.isAlphanumeric(text[end]);
+}
+
+test "find mine waste origin" {
+    const tailings = find("loose silty SAND sized TAILINGS").?;
+    try std.testing.expectEqual(MaterialOrigin.tailings, tailings.origin);
+    try std.testing.expectEqual(@as(usize, 23), tailings.start);
+
+    try std.testing.expectEqual(MaterialOrigin.spoil, find("Soft black COLLIERY SPOIL").?.origin);
+    try std.testing.expect(find("dense sandy GRAVEL with slag") == null);
+    try std.testing.expect(find("Firm CLAY") == null);
+}
@@ -0,0 +1,371 @@
+const std = @import("std");
+const types = @import("types.zig");
+const dictation = @import("dictation.zig");
+const strength_db = @import("strength_db.zig");
+
+const GeologicalDescription = types.GeologicalDescription;
+const Consistency = types.Consistency;
+const Density = types.Density;
+const PlasticityIndex = types.PlasticityIndex;
+const RockStrength = types.RockStrength;
+const WeatheringGrade = types.WeatheringGrade;
+const StrengthRange = strength_db.StrengthRange;
+
+/// Undrained shear strength band (kPa) for an AS 1726 consistency term. The
+/// terms are shared with BS 5930 but the band limits are not.
+pub fn shearStrengthBand(consistency: Consistency) StrengthRange {
+    return switch (consistency) {
+        .very_soft => .{ .lower_bound = 0, .upper_bound = 12 },
+        .soft => .{ .lower_bound = 12, .upper_bound = 25 },
+        .firm => .{ .lower_bound = 25, .upper_bound = 50 },
+        .stiff => .{ .lower_bound = 50, .upper_bound = 100 },
+        .very_stiff => .{ .lower_bound = 100, .upper_bound = 200 },
+        .hard => .{ .lower_bound = 200, .upper_bound = 400 },
+        .soft_to_firm => .{ .lower_bound = 12, .upper_bound = 50 },
+        .firm_to_stiff => .{ .lower_bound = 25, .upper_bound = 100 },
+        .stiff_to_very_stiff => .{ .lower_bound = 50, .upper_bound = 200 },
+    };
+}
+
+/// Density index band (%) for an AS 1726 density term
+pub fn densityIndexBand(density: Density) StrengthRange {
+    return switch (density) {
+        .very_loose => .{ .lower_bound = 0, .upper_bound = 15 },
+        .loose => .{ .lower_bound = 15, .upper_bound = 35 },
+        .loose_to_medium_dense => .{ .lower_bound = 15, .upper_bound = 65 },
+        .medium_dense => .{ .lower_bound = 35, .upper_bound = 65 },
+        .medium_dense_to_dense => .{ .lower_bound = 35, .upper_bound = 85 },
+        .dense => .{ .lower_bound = 65, .upper_bound = 85 },
+        .very_dense => .{ .lower_bound = 85, .upper_bound = 100 },
+    };
+}
+
+/// AS 1726 rock strength term nearest the BS 5930 class by UCS
+pub fn rockStrengthTerm(strength: RockStrength) []const u8 {
+    return switch (strength) {
+        .very_weak => "very low",
+        .weak => "low",
+        .moderately_weak => "medium",
+        .moderately_strong => "high",
+        .strong, .very_strong => "very high",
+        .extremely_strong => "extremely high",
+    };
+}
+
+fn weatheringTerm(grade: WeatheringGrade) []const u8 {
+    return switch (grade) {
+        .completely_weathered => "extremely weathered",
+        else => grade.toString(),
+    };
+}
+
+fn plasticityTerm(plasticity: PlasticityIndex) []const u8 {
+    return switch (plasticity) {
+        .non_plastic => "non-plastic",
+        .low_plasticity => "low plasticity",
+        .intermediate_plasticity => "medium plasticity",
+        .high_plasticity, .extremely_high_plasticity => "high plasticity",
+    };
+}
+
+/// Logging abbreviations, matched case-sensitively. Single letters ("D" is
+/// dense or dry) are ambiguous and left alone.
+const abbreviations = [_]struct { short: []const u8, term: []const u8 }{
+    .{ .short = "VS", .term = "very soft" },
+    .{ .short = "St", .term = "stiff" },
+    .{ .short = "VSt", .term = "very stiff" },
+    .{ .short = "VL", .term = "very loose" },
+    .{ .short = "MD", .term = "medium dense" },
+    .{ .short = "VD", .term = "very dense" },
+    .{ .short = "FR", .term = "fresh" },
+    .{ .short = "SW", .term = "slightly weathered" },
+    .{ .short = "MW", .term = "moderately weathered" },
+    .{ .short = "DW", .term = "moderately weathered" },
+    .{ .short = "HW", .term = "highly weathered" },
+    .{ .short = "XW", .term = "completely weathered" },
+};
+
+/// AS 1726 phrases and their BS 5930 equivalents
+const phrases = [_]struct { as1726: []const u8, bs5930: []const u8 }{
+    .{ .as1726 = "extremely low strength", .bs5930 = "very weak" },
+    .{ .as1726 = "very low strength", .bs5930 = "very weak" },
+    .{ .as1726 = "low strength", .bs5930 = "weak" },
+    .{ .as1726 = "medium strength", .bs5930 = "moderately weak" },
+    .{ .as1726 = "high strength", .bs5930 = "moderately strong" },
+    .{ .as1726 = "very high strength", .bs5930 = "strong" },
+    .{ .as1726 = "extremely high strength", .bs5930 = "extremely strong" },
+    .{ .as1726 = "medium plasticity", .bs5930 = "intermediate plasticity" },
+    .{ .as1726 = "extremely weathered", .bs5930 = "completely weathered" },
+    .{ .as1726 = "distinctly weathered", .bs5930 = "moderately weathered" },
+};
+
+const constituent_forms = [_]struct { noun: []const u8, adjective: []const u8 }{
+    .{ .noun = "sand", .adjective = "sandy" },
+    .{ .noun = "gravel", .adjective = "gravelly" },
+    .{ .noun = "silt", .adjective = "silty" },
+    .{ .noun = "clay", .adjective = "clayey" },
+};
+
+/// Rewrite an AS 1726 description ("Sandy CLAY (CI): medium plasticity,
+/// brown, with some gravel; moist, St") into BS 5930 wording and order
+/// ("Stiff moist intermediate plasticity brown sandy slightly gravelly CLAY").
+/// Group symbols are dropped and trace constituents become "with rare ...".
+/// Returns caller-owned text.
+pub fn normalize(allocator: std.mem.Allocator, text: []const u8) ![]u8 {
+    var cleaned = std.ArrayList(u8).init(allocator);
+    defer cleaned.deinit();
+
+    var i: usize = 0;
+    while (i < text.len) : (i += 1) {
+        if (text[i] == '(') {
+            if (std.mem.indexOfScalarPos(u8, text, i, ')')) |close| {
+                if (isGroupSymbol(text[i + 1 .. close])) {
+                    try cleaned.append(' ');
+                    i = close;
+                    continue;
+                }
+            }
+        }
+        try cleaned.append(switch (text[i]) {
+            ':', ';', ',' => ' ',
+            else => text[i],
+        });
+    }
+
+    var words = std.ArrayList([]const u8).init(allocator);
+    defer words.deinit();
+    var it = std.mem.tokenizeAny(u8, cleaned.items, " \t\r\n");
+    while (it.next()) |word| try words.append(word);
+
+    var rewritten = std.ArrayList(u8).init(allocator);
+    defer rewritten.deinit();
+    var traces = std.ArrayList([]const u8).init(allocator);
+    defer traces.deinit();
+
+    var w: usize = 0;
+    next_word: while (w < words.items.len) {
+        const rest = words.items[w..];
+
+        for (abbreviations) |abbreviation| {
+            if (std.mem.eql(u8, rest[0], abbreviation.short)) {
+                try appendWord(&rewritten, abbreviation.term);
+                w += 1;
+                continue :next_word;
+            }
+        }
+        for (phrases) |phrase| {
+            if (matchWords(rest, phrase.as1726)) |consumed| {
+                try appendWord(&rewritten, phrase.bs5930);
+                w += consumed;
+                continue :next_word;
+            }
+        }
+        // "with some sand" is a minor constituent: "slightly sandy"
+        if (matchWords(rest, "with some")) |consumed| {
+            if (consumed < rest.len) {
+                if (adjectiveFor(rest[consumed])) |adjective| {
+                    try appendWord(&rewritten, "slightly");
+                    try appendWord(&rewritten, adjective);
+                    w += consumed + 1;
+                    continue;
+                }
+            }
+        }
+        if (traceOf(rest)) |trace| {
+            try traces.append(trace.noun);
+            w += trace.consumed;
+            continue;
+        }
+
+        try appendWord(&rewritten, rest[0]);
+        w += 1;
+    }
+
+    const ordered = try dictation.normalize(allocator, rewritten.items);
+    if (traces.items.len == 0) return ordered;
+    defer allocator.free(ordered);
+
+    var result = std.ArrayList(u8).init(allocator);
+    errdefer result.deinit();
+    try result.appendSlice(ordered);
+    try result.appendSlice(" with rare ");
+    for (traces.items, 0..) |noun, index| {
+        if (index > 0) try result.appendSlice(" and ");
+        try result.appendSlice(noun);
+    }
+    return result.toOwnedSlice();
+}
+
+/// AS 1726 text for a parsed description: the soil or rock name first, then
+/// material properties, then condition ("Sandy CLAY: medium plasticity,
+/// brown, with some gravel; moist, stiff"). Returns caller-owned text.
+pub fn generate(desc: GeologicalDescription, allocator: std.mem.Allocator) ![]u8 {
+    var arena = std.heap.ArenaAllocator.init(allocator);
+    defer arena.deinit();
+    const scratch = arena.allocator();
+
+    var name = std.ArrayList(u8).init(scratch);
+    var details = std.ArrayList([]const u8).init(scratch);
+    var condition = std.ArrayList([]const u8).init(scratch);
+
+    switch (desc.material_type) {
+        .soil => {
+            // Major constituents prefix the name; minor ones are "with some"
+            for (desc.secondary_constituents) |sc| {
+                if (std.ascii.eqlIgnoreCase(sc.amount, "slightly")) {
+                    try details.append(try std.fmt.allocPrint(scratch, "with some {s}", .{nounFor(sc.soil_type)}));
+                    continue;
+                }
+                if (std.ascii.eqlIgnoreCase(sc.amount, "very")) try appendWord(&name, "very");
+                try appendWord(&name, sc.soil_type);
+            }
+            try appendWord(&name, if (desc.primary_soil_type) |soil| soil.toString() else "SOIL");
+            if (desc.secondary_primary_soil_type) |soil| {
+                try appendWord(&name, "and");
+                try appendWord(&name, soil.toString());
+            }
+
+            if (desc.plasticity_index) |plasticity| try details.insert(0, plasticityTerm(plasticity));
+            if (desc.particle_size) |size| {
+                try details.insert(0, try std.fmt.allocPrint(scratch, "{s} grained", .{size.toString()}));
+            }
+            if (desc.color) |color| try details.insert(details.items.len - countMinor(desc), color.toString());
+
+            if (desc.moisture_content) |moisture| try condition.append(moisture.toString());
+            if (desc.consistency) |consistency| {
+                try condition.append(consistency.toString());
+            } else if (desc.density) |density| {
+                try condition.append(density.toString());
+            }
+        },
+        .rock => {
+            try appendWord(&name, if (desc.primary_rock_type) |rock| rock.toString() else "ROCK");
+
+            if (desc.color) |color| try details.append(color.toString());
+            if (desc.rock_structure) |structure| try details.append(structure.toString());
+
+            if (desc.weathering_grade) |grade| try condition.append(weatheringTerm(grade));
+            if (desc.rock_strength) |strength| {
+                try condition.append(try std.fmt.allocPrint(scratch, "{s} strength", .{rockStrengthTerm(strength)}));
+            }
+        },
+    }
+
+    var out = std.ArrayList(u8).init(allocator);
+    errdefer out.deinit();
+
+    try out.appendSlice(name.items);
+    out.items[0] = std.ascii.toUpper(out.items[0]);
+
+    var separator: []const u8 = ": ";
+    for ([_][]const []const u8{ details.items, condition.items }) |group| {
+        if (group.len == 0) continue;
+        try out.appendSlice(separator);
+        for (group, 0..) |part, index| {
+            if (index > 0) try out.appendSlice(", ");
+            try out.appendSlice(part);
+        }
+        separator = "; ";
+    }
+    return out.toOwnedSlice();
+}
+
+/// "with some ..." entries, which follow the colour
+fn countMinor(desc: GeologicalDescription) usize {
+    var count: usize = 0;
+    for (desc.secondary_constituents) |sc| {
+        if (std.ascii.eqlIgnoreCase(sc.amount, "slightly")) count += 1;
+    }
+    return count;
+}
+
+fn appendWord(out: *std.ArrayList(u8), word: []const u8) !void {
+    if (out.items.len > 0) try out.append(' ');
+    try out.appendSlice(word);
+}
+
+/// Number of words matched if `words` starts with `phrase`
+fn matchWords(words: []const []const u8, phrase: []const u8) ?usize {
+    var count: usize = 0;
+    var parts = std.mem.tokenizeScalar(u8, phrase, ' ');
+    while (parts.next()) |part| : (count += 1) {
+        if (count >= words.len or !std.ascii.eqlIgnoreCase(words[count], part)) return null;
+    }
+    return count;
+}
+
+const Trace = struct {
+    noun: []const u8,
+    consumed: usize,
+};
+
+/// "trace sand", "trace of sand" or "with trace of sand"
+fn traceOf(words: []const []const u8) ?Trace {
+    var n: usize = 0;
+    if (n < words.len and std.ascii.eqlIgnoreCase(words[n], "with")) n += 1;
+    if (n >= words.len or !std.ascii.eqlIgnoreCase(words[n], "trace")) return null;
+    n += 1;
+    if (n < words.len and std.ascii.eqlIgnoreCase(words[n], "of")) n += 1;
+    if (n >= words.len or adjectiveFor(words[n]) == null) return null;
+    return Trace{ .noun = words[n], .consumed = n + 1 };
+}
+
+/// USCS group symbols such as "CI", "SP" or "Pt"
+fn isGroupSymbol(text: []const u8) bool {
+    if (text.len == 0 or text.len > 3 or !std.ascii.isUpper(text[0])) return false;
+    for (text) |ch| {
+        if (!std.ascii.isAlphabetic(ch)) return false;
+    }
+    return true;
+}
+
+fn adjectiveFor(noun: []const u8) ?[]const u8 {
+    for (constituent_forms) |form| {
+        if (std.ascii.eqlIgnoreCase(noun, form.noun)) return form.adjective;
+    }
+    return null;
+}
+
+fn nounFor(adjective: []const u8) []const u8 {
+    for (constituent_forms) |form| {
+        if (std.ascii.eqlIgnoreCase(adjective, form.adjective)) return form.noun;
+    }
+    return adjective;
+}
+
+test "normalize reads AS 1726 order, abbreviations and group symbols" {
+    const allocator = std.testing.allocator;
+
+    const soil = try normalize(allocator, "Sandy CLAY (CI): medium plasticity, brown, with some gravel, trace of silt; moist, St");
+    defer allocator.free(soil);
+    try std.testing.expectEqualStrings("Stiff moist intermediate plasticity brown sandy slightly gravelly CLAY with rare silt", soil);
+
+    const rock = try normalize(allocator, "SANDSTONE: grey; XW, high strength");
+    defer allocator.free(rock);
+    try std.testing.expectEqualStrings("Moderately strong completely weathered grey SANDSTONE", rock);
+}
+
+test "generate writes AS 1726 order" {
+    const allocator = std.testing.allocator;
+
+    var constituents = [_]types.SecondaryConstituent{
+        .{ .amount = "moderately", .soil_type = "sandy" },
+        .{ .amount = "slightly", .soil_type = "gravelly" },
+    };
+    const desc = GeologicalDescription{
+        .raw_description = "Stiff moist brown sandy slightly gravelly CLAY",
+        .material_type = .soil,
+        .consistency = .stiff,
+        .moisture_content = .moist,
+        .plasticity_index = .intermediate_plasticity,
+        .color = .brown,
+        .secondary_constituents = &constituents,
+        .primary_soil_type = .clay,
+    };
+
+    const text = try generate(desc, allocator);
+    defer allocator.free(text);
+    try std.testing.expectEqualStrings("Sandy CLAY: medium plasticity, brown, with some gravel; moist, stiff", text);
+
+    try std.testing.expectEqual(@as(f32, 50), shearStrengthBand(.stiff).lower_bound);
+}
@@ -0,0 +1,494 @@
+const std = @import("std");
+const types = @import("types.zig");
+const lexer = @import("lexer.zig");
+const bs5930 = @import("bs5930.zig");
+const scan = @import("scan.zig");
+
+const Field = types.Field;
+const Lexer = lexer.Lexer;
+const TokenType = lexer.TokenType;
+
+/// How far `autoFix` may rewrite a description. Each level includes the
+/// fixes of the levels before it.
+pub const FixLevel = enum {
+    /// Spacing and capitalisation only; the words are unchanged
+    conservative,
+    /// Also expands written abbreviations ("v.soft", "med. dense") and
+    /// applies the parser's spelling corrections
+    standard,
+    /// Also expands bare abbreviations ("md", "sl") and moves descriptors
+    /// into BS 5930 order
+    aggressive,
+
+    pub fn fromString(str: []const u8) ?FixLevel {
+        if (std.ascii.eqlIgnoreCase(str, "conservative")) return .conservative;
+        if (std.ascii.eqlIgnoreCase(str, "standard")) return .standard;
+        if (std.ascii.eqlIgnoreCase(str, "aggressive")) return .aggressive;
+        return null;
+    }
+
+    pub fn toString(self: FixLevel) []const u8 {
+        return @tagName(self);
+    }
+
+    pub fn allows(self: FixLevel, needed: FixLevel) bool {
+        return @intFromEnum(needed) <= @intFromEnum(self);
+    }
+};
+
+pub const FixKind = enum {
+    whitespace,
+    capitalisation,
+    abbreviation,
+    spelling,
+    ordering,
+
+    pub fn toString(self: FixKind) []const u8 {
+        return @tagName(self);
+    }
+};
+
+/// One change made by `autoFix`
+pub const Amendment = struct {
+    kind: FixKind,
+    /// The text that was replaced; the whole description for spacing and
+    /// capitalisation
+    before: []const u8,
+    after: []const u8,
+
+    pub fn deinit(self: Amendment, allocator: std.mem.Allocator) void {
+        allocator.free(self.before);
+        allocator.free(self.after);
+    }
+};
+
+/// A fixed description and the amendment log, in the order applied
+pub const Fix = struct {
+    text: []const u8,
+    amendments: []Amendment,
+
+    pub fn deinit(self: Fix, allocator: std.mem.Allocator) void {
+        allocator.free(self.text);
+        for (self.amendments) |amendment| amendment.deinit(allocator);
+        allocator.free(self.amendments);
+    }
+
+    pub fn changed(self: Fix) bool {
+        return self.amendments.len > 0;
+    }
+};
+
+const Abbreviation = struct {
+    short: []const u8,
+    full: []const u8,
+    level: FixLevel,
+};
+
+/// Longer forms first so "v.soft" is expanded before "v."
+const abbreviations = [_]Abbreviation{
+    .{ .short = "v.soft", .full = "very soft", .level = .standard },
+    .{ .short = "v. soft", .full = "very soft", .level = .standard },
+    .{ .short = "v.stiff", .full = "very stiff", .level = .standard },
+    .{ .short = "v. stiff", .full = "very stiff", .level = .standard },
+    .{ .short = "v.loose", .full = "very loose", .level = .standard },
+    .{ .short = "v. loose", .full = "very loose", .level = .standard },
+    .{ .short = "v.dense", .full = "very dense", .level = .standard },
+    .{ .short = "v. dense", .full = "very dense", .level = .standard },
+    .{ .short = "med. dense", .full = "medium dense", .level = .standard },
+    .{ .short = "med.dense", .full = "medium dense", .level = .standard },
+    .{ .short = "med dense", .full = "medium dense", .level = .standard },
+    .{ .short = "sl.", .full = "slightly", .level = .standard },
+    .{ .short = "mod.", .full = "moderately", .level = .standard },
+    .{ .short = "v.", .full = "very", .level = .standard },
+    // Bare forms could be other words, so only when asked
+    .{ .short = "md", .full = "medium dense", .level = .aggressive },
+    .{ .short = "vd", .full = "very dense", .level = .aggressive },
+    .{ .short = "vl", .full = "very loose", .level = .aggressive },
+    .{ .short = "sl", .full = "slightly", .level = .aggressive },
+    .{ .short = "mod", .full = "moderately", .level = .aggressive },
+};
+
+/// Clean up a description for bulk data cleansing. Fixes run in a fixed
+/// order (spacing, abbreviations, spelling, ordering, then capitalisation)
+/// and only those allowed by `level` are applied. Spelling and case follow
+/// what `parser`, with its dialect and vocabulary, makes of the text.
+pub fn autoFix(parser: *bs5930.Parser, description: []const u8, level: FixLevel) !Fix {
+    const allocator = parser.allocator;
+    var text = std.ArrayList(u8).init(allocator);
+    defer text.deinit();
+    try text.appendSlice(description);
+
+    var log = std.ArrayList(Amendment).init(allocator);
+    defer log.deinit();
+    errdefer for (log.items) |amendment| amendment.deinit(allocator);
+
+    try fixWhitespace(allocator, &text, &log);
+    for (abbreviations) |abbreviation| {
+        if (!level.allows(abbreviation.level)) continue;
+        try replaceWord(allocator, &text, &log, .abbreviation, abbreviation.short, abbreviation.full);
+    }
+    if (level.allows(.standard)) try fixSpelling(parser, &text, &log);
+    if (level.allows(.aggressive)) try fixOrder(allocator, &text, &log);
+    try fixCase(parser, &text, &log);
+
+    const fixed = try text.toOwnedSlice();
+    errdefer allocator.free(fixed);
+    return Fix{ .text = fixed, .amendments = try log.toOwnedSlice() };
+}
+
+fn record(allocator: std.mem.Allocator, log: *std.ArrayList(Amendment), kind: FixKind, before: []const u8, after: []const u8) !void {
+    const old = try allocator.dupe(u8, before);
+    errdefer allocator.free(old);
+    const new = try allocator.dupe(u8, after);
+    errdefer allocator.free(new);
+    try log.append(Amendment{ .kind = kind, .before = old, .after = new });
+}
+
+/// Single spaces, none at the ends or before punctuation
+fn fixWhitespace(allocator: std.mem.Allocator, text: *std.ArrayList(u8), log: *std.ArrayList(Amendment)) !void {
+    var fixed = std.ArrayList(u8).init(allocator);
+    defer fixed.deinit();
+
+    const trimmed = std.mem.trim(u8, text.items, " \t\r\n");
+    var pending_space = false;
+    for (trimmed) |c| {
+        if (std.ascii.isWhitespace(c)) {
+            pending_space = true;
+            continue;
+        }
+        if (pending_space and std.mem.indexOfScalar(u8, ",;:.)", c) == null) try fixed.append(' ');
+        pending_space = false;
+        try fixed.append(c);
+    }
+    if (std.mem.eql(u8, fixed.items, text.items)) return;
+
+    try record(allocator, log, .whitespace, text.items, fixed.items);
+    text.clearRetainingCapacity();
+    try text.appendSlice(fixed.items);
+}
+
+/// Replace every whole-word `from`, ignoring case, with `to`
+fn replaceWord(
+    allocator: std.mem.Allocator,
+    text: *std.ArrayList(u8),
+    log: *std.ArrayList(Amendment),
+    kind: FixKind,
+    from: []const u8,
+    to: []const u8,
+) !void {
+    var pos: usize = 0;
+    while (std.ascii.indexOfIgnoreCasePos(text.items, pos, from)) |start| {
+        const end = start + from.len;
+        const bounded = (start == 0 or !std.ascii.isAlphanumeric(text.items[start - 1])) and
+            (end == text.items.len or !std.ascii.isAlphanumeric(text.items[end]));
+        if (!bounded) {
+            pos = start + 1;
+            continue;
+        }
+        try record(allocator, log, kind, text.items[start..end], to);
+        try text.replaceRange(start, from.len, to);
+        pos = start + to.len;
+    }
+}
+
+fn fixSpelling(parser: *bs5930.Parser, text: *std.ArrayList(u8), log: *std.ArrayList(Amendment)) !void {
+    const allocator = parser.allocator;
+    const result = try parser.parse(text.items);
+    defer result.deinit(allocator);
+
+    for (result.spelling_corrections) |correction| {
+        if (std.ascii.eqlIgnoreCase(correction.original, correction.corrected)) continue;
+        try replaceWord(allocator, text, log, .spelling, correction.original, correction.corrected);
+    }
+}
+
+/// Position of a descriptor before the primary type in BS 5930 order:
+/// strength, state, structure, colour, secondary constituents, then grading
+fn orderRank(token_type: TokenType) ?u8 {
+    return switch (token_type) {
+        .consistency, .consistency_range, .density, .rock_strength => 0,
+        .moisture_content, .weathering_grade => 1,
+        .rock_structure => 2,
+        .color => 3,
+        .proportion, .adjective => 4,
+        .plasticity_index, .particle_size => 5,
+        else => null,
+    };
+}
+
+const Descriptor = struct {
+    start: usize,
+    end: usize,
+    rank: u8,
+
+    fn lessThan(_: void, a: Descriptor, b: Descriptor) bool {
+        return a.rank < b.rank;
+    }
+};
+
+const max_descriptors = 16;
+
+/// Sort the words before the primary type into BS 5930 order. Left alone
+/// unless every word there is a known descriptor separated by spaces.
+fn fixOrder(allocator: std.mem.Allocator, text: *std.ArrayList(u8), log: *std.ArrayList(Amendment)) !void {
+    var lex = Lexer.init(allocator, text.items);
+    defer lex.deinit();
+    const tokens = try lex.tokenize();
+    defer {
+        for (tokens) |token| {
+            if (token.corrected_from != null) allocator.free(token.value);
+        }
+        allocator.free(tokens);
+    }
+
+    const primary = for (tokens, 0..) |token, i| {
+        if (token.type == .soil_type or token.type == .rock_type) break i;
+    } else return;
+
+    var descriptors: [max_descriptors]Descriptor = undefined;
+    var count: usize = 0;
+    var i: usize = 0;
+    while (i < primary) : (i += 1) {
+        if (count == descriptors.len) return;
+        const first = i;
+        // "slightly sandy" and "grey-brown" move as one
+        while (i + 1 < primary and (tokens[i].type == .proportion or tokens[i].joiner != null)) i += 1;
+        for (tokens[first .. i + 1]) |token| {
+            if (orderRank(token.type) == null) return;
+        }
+        descriptors[count] = Descriptor{ .start = tokens[first].start, .end = tokens[i].end, .rank = orderRank(tokens[i].type).? };
+        count += 1;
+    }
+    if (count < 2) return;
+
+    const segment_start = descriptors[0].start;
+    const segment_end = descriptors[count - 1].end;
+    for (descriptors[0 .. count - 1], descriptors[1..count]) |current, next| {
+        for (text.items[current.end..next.start]) |c| {
+            if (c != ' ') return;
+        }
+    }
+    for (text.items[segment_end..tokens[primary].start]) |c| {
+        if (c != ' ') return;
+    }
+
+    const ordered = descriptors[0..count];
+    if (std.sort.isSorted(Descriptor, ordered, {}, Descriptor.lessThan)) return;
+    std.mem.sort(Descriptor, ordered, {}, Descriptor.lessThan);
+
+    var segment = std.ArrayList(u8).init(allocator);
+    defer segment.deinit();
+    for (ordered, 0..) |descriptor, index| {
+        if (index > 0) try segment.append(' ');
+        try segment.appendSlice(text.items[descriptor.start..descriptor.end]);
+    }
+    try record(allocator, log, .ordering, text.items[segment_start..segment_end], segment.items);
+    try text.replaceRange(segment_start, segment_end - segment_start, segment.items);
+}
+
+/// Primary types in capitals and everything else in lower case, with a
+/// capital at the start of each sentence. Brackets, Munsell codes,
+/// formation names and the MADE GROUND and TOPSOIL labels keep their case,
+/// as do abbreviations and units outside the vocabulary (see `keptWords`).
+/// Skipped when no primary type is found.
+fn fixCase(parser: *bs5930.Parser, text: *std.ArrayList(u8), log: *std.ArrayList(Amendment)) !void {
+    const allocator = parser.allocator;
+    const result = try parser.parse(text.items);
+    defer result.deinit(allocator);
+
+    const upper_fields = [_]Field{ .primary_soil_type, .secondary_primary_soil_type, .primary_rock_type, .made_ground_label, .topsoil };
+    const kept_fields = [_]Field{ .munsell_colour, .geological_formation };
+    if (result.spans.get(.primary_soil_type) == null and result.spans.get(.primary_rock_type) == null) return;
+
+    const kept_words = try keptWords(allocator, text.items);
+    defer allocator.free(kept_words);
+
+    const fixed = try allocator.dupe(u8, text.items);
+    defer allocator.free(fixed);
+
+    var depth: usize = 0;
+    var sentence_start = true;
+    for (fixed, 0..) |c, pos| {
+        if (c == '(') depth += 1;
+        if (c == ')' and depth > 0) depth -= 1;
+        if (depth > 0 or within(&result, &kept_fields, pos) or inRanges(kept_words, pos)) {
+            sentence_start = false;
+            continue;
+        }
+        if (within(&result, &upper_fields, pos)) {
+            fixed[pos] = std.ascii.toUpper(c);
+        } else if (sentence_start and std.ascii.isAlphabetic(c)) {
+            fixed[pos] = std.ascii.toUpper(c);
+        } else {
+            fixed[pos] = std.ascii.toLower(c);
+        }
+        if (std.ascii.isAlphabetic(c)) sentence_start = false;
+        if (c == '.' and endsSentence(fixed, pos)) sentence_start = true;
+    }
+    if (std.mem.eql(u8, fixed, text.items)) return;
+
+    try record(allocator, log, .capitalisation, text.items, fixed);
+    @memcpy(text.items, fixed);
+}
+
+const Range = struct {
+    start: usize,
+    end: usize,
+};
+
+/// Words whose case is meaningful: mixed case such as "kPa" and "CaCO3",
+/// and, unless the whole description is in capitals, all-capital words such
+/// as "RQD" and "SPT". Words the lexer knows as descriptive terms are not
+/// kept, so "FIRM" still becomes "firm".
+fn keptWords(allocator: std.mem.Allocator, text: []const u8) ![]Range {
+    var lex = Lexer.init(allocator, text);
+    defer lex.deinit();
+    const tokens = try lex.tokenize();
+    defer {
+        for (tokens) |token| {
+            if (token.corrected_from != null) allocator.free(token.value);
+        }
+        allocator.free(tokens);
+    }
+
+    const shouting = for (text) |c| {
+        if (std.ascii.isLower(c)) break false;
+    } else true;
+
+    var kept = std.ArrayList(Range).init(allocator);
+    errdefer kept.deinit();
+    var pos: usize = 0;
+    while (scan.next(text, pos, .alphanumeric)) |word| {
+        pos = word.end;
+        const keep = switch (wordCase(word.text)) {
+            .mixed => true,
+            .upper => !shouting,
+            .other => false,
+        };
+        if (keep and !isVocabulary(tokens, word.start)) try kept.append(.{ .start = word.start, .end = word.end });
+    }
+    return kept.toOwnedSlice();
+}
+
+const WordCase = enum { upper, mixed, other };
+
+/// `upper` for capitals and no lower case ("RQD", "N60", but not "A");
+/// `mixed` for a capital after the first letter among lower case ("kPa",
+/// "CaCO3"); `other` for lower case and capitalised words
+fn wordCase(word: []const u8) WordCase {
+    var letters: usize = 0;
+    var lower = false;
+    var later_upper = false;
+    for (word, 0..) |c, i| {
+        if (!std.ascii.isAlphabetic(c)) continue;
+        letters += 1;
+        if (std.ascii.isLower(c)) lower = true else if (i > 0) later_upper = true;
+    }
+    if (!lower) return if (letters >= 2 or (letters == 1 and word.len > 1)) .upper else .other;
+    return if (later_upper) .mixed else .other;
+}
+
+fn isVocabulary(tokens: []const lexer.Token, start: usize) bool {
+    for (tokens) |token| {
+        if (start < token.start or start >= token.end) continue;
+        return token.type != .word and token.type != .unknown;
+    }
+    return false;
+}
+
+fn inRanges(ranges: []const Range, pos: usize) bool {
+    for (ranges) |range| {
+        if (pos >= range.start and pos < range.end) return true;
+    }
+    return false;
+}
+
+/// Words that take a full stop without ending the sentence ("sl. sandy")
+const abbreviated_words = [_][]const u8{ "v", "sl", "mod", "med" };
+
+fn endsSentence(text: []const u8, dot: usize) bool {
+    if (dot + 1 < text.len and text[dot + 1] != ' ') return false;
+    var start = dot;
+    while (start > 0 and std.ascii.isAlphabetic(text[start - 1])) start -= 1;
+    for (abbreviated_words) |word| {
+        if (std.ascii.eqlIgnoreCase(text[start..dot], word)) return false;
+    }
+    return true;
+}
+
+fn within(result: *const types.GeologicalDescription, fields: []const Field, pos: usize) bool {
+    for (fields) |field| {
+        const span = result.spans.get(field) orelse continue;
+        if (pos >= span.start and pos < span.end) return true;
+    }
+    return false;
+}
+
+test "conservative fixes spacing and case only" {
+    const allocator = std.testing.allocator;
+    var parser = bs5930.Parser.init(allocator);
+
+    const fix = try autoFix(&parser, "  FIRM  BROWN sl. sandy clay ,  ", .conservative);
+    defer fix.deinit(allocator);
+
+    try std.testing.expectEqualStrings("Firm brown sl. sandy CLAY,", fix.text);
+    try std.testing.expectEqual(@as(usize, 2), fix.amendments.len);
+    try std.testing.expectEqual(FixKind.whitespace, fix.amendments[0].kind);
+    try std.testing.expectEqual(FixKind.capitalisation, fix.amendments[1].kind);
+}
+
+test "standard expands written abbreviations" {
+    const allocator = std.testing.allocator;
+    var parser = bs5930.Parser.init(allocator);
+
+    const fix = try autoFix(&parser, "Med. dense brown sl. silty SAND", .standard);
+    defer fix.deinit(allocator);
+
+    try std.testing.expectEqualStrings("Medium dense brown slightly silty SAND", fix.text);
+    try std.testing.expectEqual(FixKind.abbreviation, fix.amendments[0].kind);
+    try std.testing.expectEqualStrings("Med. dense", fix.amendments[0].before);
+    try std.testing.expectEqualStrings("medium dense", fix.amendments[0].after);
+
+    // Bare forms wait for the aggressive level
+    const bare = try autoFix(&parser, "md SAND", .standard);
+    defer bare.deinit(allocator);
+    try std.testing.expect(std.mem.indexOf(u8, bare.text, "medium dense") == null);
+}
+
+test "aggressive puts descriptors in BS 5930 order" {
+    const allocator = std.testing.allocator;
+    var parser = bs5930.Parser.init(allocator);
+
+    const fix = try autoFix(&parser, "brown slightly sandy firm CLAY with rare gravel", .aggressive);
+    defer fix.deinit(allocator);
+
+    try std.testing.expectEqualStrings("Firm brown slightly sandy CLAY with rare gravel", fix.text);
+    var reordered = false;
+    for (fix.amendments) |amendment| {
+        if (amendment.kind == .ordering) reordered = true;
+    }
+    try std.testing.expect(reordered);
+
+    // An unrecognised word before the primary type stops reordering
+    const unknown = try autoFix(&parser, "brown zorbly firm CLAY", .aggressive);
+    defer unknown.deinit(allocator);
+    try std.testing.expect(std.mem.startsWith(u8, unknown.text, "Brown zorbly firm"));
+}
+
+test "capitals and brackets are kept where they belong" {
+    const allocator = std.testing.allocator;
+    var parser = bs5930.Parser.init(allocator);
+
+    const fix = try autoFix(&parser, "MADE GROUND: BROWN SANDY CLAY (10YR 5/3) WITH BRICK (GLACIAL TILL)", .conservative);
+    defer fix.deinit(allocator);
+    try std.testing.expectEqualStrings("MADE GROUND: brown sandy CLAY (10YR 5/3) with brick (GLACIAL TILL)", fix.text);
+}
+
+test "abbreviations, units and formation names keep their case" {
+    const allocator = std.testing.allocator;
+    var parser = bs5930.Parser.init(allocator);
+
+    const fix = try autoFix(&parser, "FIRM brown CLAY, cu 60 kPa, CaCO3 20%, SPT N60 12. LONDON CLAY FORMATION", .conservative);
+    defer fix.deinit(allocator);
+    try std.testing.expectEqualStrings("Firm brown CLAY, cu 60 kPa, CaCO3 20%, SPT N60 12. LONDON CLAY FORMATION", fix.text);
+}
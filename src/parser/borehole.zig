@@ -0,0 +1,200 @@
+const std = @import("std");
+const types = @import("types.zig");
+
+/// A core photograph or other image registered to a depth range, so viewers
+/// can show the photo beside the description it illustrates
+pub const PhotoRef = struct {
+    /// File name, path or URL of the image
+    uri: []const u8,
+    depth_top: f64,
+    depth_bottom: f64,
+    caption: ?[]const u8 = null,
+
+    pub fn covers(self: PhotoRef, depth: f64) bool {
+        return depth >= self.depth_top and depth <= self.depth_bottom;
+    }
+};
+
+/// A sample taken from a stratum and the laboratory tests run on it, so
+/// test results can be traced back to the description
+pub const SampleRef = struct {
+    /// AGS SAMP_REF
+    reference: []const u8,
+    /// AGS SAMP_TYPE code, e.g. "U" (undisturbed), "B" (bulk), "D" (small disturbed)
+    sample_type: []const u8,
+    depth_top: f64,
+    depth_base: ?f64 = null,
+    /// AGS SAMP_ID, where the project uses one
+    id: ?[]const u8 = null,
+    /// Laboratory test groups run on the sample, e.g. "LLPL", "TRIT"
+    test_ids: []const []const u8 = &.{},
+};
+
+/// A single logged stratum within a borehole
+pub const Stratum = struct {
+    depth_top: f64,
+    depth_bottom: f64,
+    description: types.GeologicalDescription,
+    /// Core photographs of the stratum; a photo may run past its boundaries
+    photos: []const PhotoRef = &.{},
+    /// Samples whose top lies within the stratum
+    samples: []const SampleRef = &.{},
+
+    pub fn thickness(self: Stratum) f64 {
+        return self.depth_bottom - self.depth_top;
+    }
+
+    pub fn midDepth(self: Stratum) f64 {
+        return (self.depth_top + self.depth_bottom) / 2.0;
+    }
+
+    /// The first photo showing `depth`
+    pub fn photoAt(self: Stratum, depth: f64) ?PhotoRef {
+        for (self.photos) |photo| {
+            if (photo.covers(depth)) return photo;
+        }
+        return null;
+    }
+};
+
+/// A borehole log: ordered strata from ground level downwards.
+/// The log does not own its strata; callers manage description lifetimes.
+pub const BoreholeLog = struct {
+    id: []const u8,
+    ground_level: ?f64 = null,
+    /// Date of the logging event ("2026-03-01"), for boreholes logged more
+    /// than once; see `compareLogs`
+    logged_on: ?[]const u8 = null,
+    strata: []const Stratum,
+
+    /// Total logged depth of the borehole
+    pub fn finalDepth(self: BoreholeLog) f64 {
+        var depth: f64 = 0;
+        for (self.strata) |stratum| {
+            depth = @max(depth, stratum.depth_bottom);
+        }
+        return depth;
+    }
+
+    /// Elevation of a depth below ground, if the ground level is known
+    pub fn elevationAt(self: BoreholeLog, depth: f64) ?f64 {
+        if (self.ground_level) |gl| return gl - depth;
+        return null;
+    }
+
+    /// `{"id", "ground_level", "logged_on", "strata": [...]}` with each stratum's depths,
+    /// parsed description (as `GeologicalDescription.toJson`), photos and
+    /// samples
+    pub fn toJson(self: BoreholeLog, allocator: std.mem.Allocator) ![]u8 {
+        var out = std.ArrayList(u8).init(allocator);
+        errdefer out.deinit();
+        const writer = out.writer();
+
+        try writer.writeAll("{\"id\":");
+        try std.json.stringify(self.id, .{}, writer);
+        if (self.ground_level) |gl| try writer.print(",\"ground_level\":{d:.2}", .{gl});
+        if (self.logged_on) |date| {
+            try writer.writeAll(",\"logged_on\":");
+            try std.json.stringify(date, .{}, writer);
+        }
+        try writer.writeAll(",\"strata\":[");
+        for (self.strata, 0..) |stratum, i| {
+            if (i > 0) try writer.writeAll(",");
+            try writer.print("{{\"depth_top\":{d:.2},\"depth_bottom\":{d:.2},\"description\":", .{ stratum.depth_top, stratum.depth_bottom });
+            const description = try stratum.description.toJson(allocator);
+            defer allocator.free(description);
+            try writer.writeAll(description);
+
+            try writer.writeAll(",\"photos\":[");
+            for (stratum.photos, 0..) |photo, j| {
+                if (j > 0) try writer.writeAll(",");
+                try writer.writeAll("{\"uri\":");
+                try std.json.stringify(photo.uri, .{}, writer);
+                try writer.print(",\"depth_top\":{d:.2},\"depth_bottom\":{d:.2}", .{ photo.depth_top, photo.depth_bottom });
+                if (photo.caption) |caption| {
+                    try writer.writeAll(",\"caption\":");
+                    try std.json.stringify(caption, .{}, writer);
+                }
+                try writer.writeAll("}");
+            }
+            try writer.writeAll("]");
+
+            try writer.writeAll(",\"samples\":[");
+            for (stratum.samples, 0..) |sample, j| {
+                if (j > 0) try writer.writeAll(",");
+                try writer.writeAll("{\"reference\":");
+                try std.json.stringify(sample.reference, .{}, writer);
+                try writer.writeAll(",\"sample_type\":");
+                try std.json.stringify(sample.sample_type, .{}, writer);
+                try writer.print(",\"depth_top\":{d:.2}", .{sample.depth_top});
+                if (sample.depth_base) |base| try writer.print(",\"depth_base\":{d:.2}", .{base});
+                if (sample.id) |id| {
+                    try writer.writeAll(",\"id\":");
+                    try std.json.stringify(id, .{}, writer);
+                }
+                try writer.writeAll(",\"test_ids\":");
+                try std.json.stringify(sample.test_ids, .{}, writer);
+                try writer.writeAll("}");
+            }
+            try writer.writeAll("]}");
+        }
+        try writer.writeAll("]}");
+
+        return out.toOwnedSlice();
+    }
+};
+
+test "stratum geometry" {
+    const stratum = Stratum{
+        .depth_top = 1.0,
+        .depth_bottom = 3.5,
+        .description = .{ .raw_description = "Firm CLAY", .material_type = .soil },
+    };
+
+    try std.testing.expectApproxEqAbs(@as(f64, 2.5), stratum.thickness(), 0.0001);
+    try std.testing.expectApproxEqAbs(@as(f64, 2.25), stratum.midDepth(), 0.0001);
+}
+
+test "borehole log final depth and elevation" {
+    const strata = [_]Stratum{
+        .{ .depth_top = 0.0, .depth_bottom = 1.2, .description = .{ .raw_description = "MADE GROUND", .material_type = .soil } },
+        .{ .depth_top = 1.2, .depth_bottom = 8.0, .description = .{ .raw_description = "Firm CLAY", .material_type = .soil } },
+    };
+    const log = BoreholeLog{ .id = "BH01", .ground_level = 25.0, .strata = &strata };
+
+    try std.testing.expectApproxEqAbs(@as(f64, 8.0), log.finalDepth(), 0.0001);
+    try std.testing.expectApproxEqAbs(@as(f64, 20.0), log.elevationAt(5.0).?, 0.0001);
+}
+
+test "stratum photos by depth" {
+    const photos = [_]PhotoRef{
+        .{ .uri = "BH01_box1.jpg", .depth_top = 1.0, .depth_bottom = 2.0 },
+        .{ .uri = "BH01_box2.jpg", .depth_top = 2.0, .depth_bottom = 3.0, .caption = "Box 2" },
+    };
+    const strata = [_]Stratum{
+        .{ .depth_top = 1.2, .depth_bottom = 2.8, .description = .{ .raw_description = "Firm CLAY", .material_type = .soil }, .photos = &photos },
+    };
+    const log = BoreholeLog{ .id = "BH01", .strata = &strata };
+
+    try std.testing.expectEqualStrings("BH01_box2.jpg", strata[0].photoAt(2.5).?.uri);
+    try std.testing.expect(strata[0].photoAt(3.5) == null);
+
+    const json = try log.toJson(std.testing.allocator);
+    defer std.testing.allocator.free(json);
+    try std.testing.expect(std.mem.indexOf(u8, json, "\"uri\":\"BH01_box1.jpg\",\"depth_top\":1.00,\"depth_bottom\":2.00") != null);
+    try std.testing.expect(std.mem.indexOf(u8, json, "\"caption\":\"Box 2\"") != null);
+}
+
+test "stratum samples in JSON" {
+    const samples = [_]SampleRef{
+        .{ .reference = "1", .sample_type = "U", .depth_top = 2.0, .depth_base = 2.45, .test_ids = &.{ "LLPL", "TRIT" } },
+    };
+    const strata = [_]Stratum{
+        .{ .depth_top = 1.2, .depth_bottom = 2.8, .description = .{ .raw_description = "Firm CLAY", .material_type = .soil }, .samples = &samples },
+    };
+    const log = BoreholeLog{ .id = "BH01", .strata = &strata };
+
+    const json = try log.toJson(std.testing.allocator);
+    defer std.testing.allocator.free(json);
+    try std.testing.expect(std.mem.indexOf(u8, json, "\"samples\":[{\"reference\":\"1\",\"sample_type\":\"U\",\"depth_top\":2.00,\"depth_base\":2.45,\"test_ids\":[\"LLPL\",\"TRIT\"]}]") != null);
+}
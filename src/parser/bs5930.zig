@@ -9,15 +9,228 @@ const generator = @import("generator.zig");
 const fuzzy = @import("fuzzy.zig");
 const anomaly = @import("anomaly.zig");
 const compliance = @import("compliance.zig");
+const borehole = @import("borehole.zig");
+const correlation = @import("correlation.zig");
+const outlier = @import("outlier.zig");
+const fallback = @import("fallback.zig");
+const embedding = @import("embedding.zig");
+const training = @import("training.zig");
+const ocr = @import("ocr.zig");
+const dictation = @import("dictation.zig");
+const absence = @import("absence.zig");
+const config = @import("config.zig");
+const profile = @import("profile.zig");
+const report = @import("report.zig");
+const hooks = @import("hooks.zig");
+const plugin = @import("plugin.zig");
+const snapshot = @import("snapshot.zig");
+const lint = @import("lint.zig");
+const noise = @import("noise.zig");
+const result_types = @import("result.zig");
+const sequence = @import("sequence.zig");
+const synthetic = @import("synthetic.zig");
+const explanation = @import("explain.zig");
+const glossary = @import("glossary.zig");
+const csvio = @import("csvio.zig");
+const schema = @import("schema.zig");
+const result_store = @import("store.zig");
+const delta = @import("delta.zig");
+const sink = @import("sink.zig");
+const self_test = @import("selftest.zig");
+const canonical = @import("canonical.zig");
+const html = @import("html.zig");
+const markdown = @import("markdown.zig");
+const localization = @import("localize.zig");
+const us_sieve = @import("us_sieve.zig");
+const as1726 = @import("as1726.zig");
+const sensitivity_terms = @import("sensitivity.zig");
+const karst = @import("karst.zig");
+const anthropogenic = @import("anthropogenic.zig");
+const marine = @import("marine.zig");
+const gas = @import("gas.zig");
+const fossils = @import("fossils.zig");
+const colour_terms = @import("colour.zig");
+const munsell = @import("munsell.zig");
+const particle_shape = @import("particle_shape.zig");
+const peat = @import("peat.zig");
+const voiding = @import("voiding.zig");
+const carbonate = @import("carbonate.zig");
+const cpt = @import("cpt.zig");
+const inclusion_clauses = @import("inclusions.zig");
+const Deadline = @import("deadline.zig").Deadline;
+const made_ground = @import("made_ground.zig");
+const composite = @import("composite.zig");
+const parenthetical = @import("parenthetical.zig");
+const relog = @import("relog.zig");
+const aggregate = @import("aggregate.zig");
+const corpus = @import("corpus.zig");
+const unknown_terms = @import("unknown_terms.zig");
+const autofix = @import("autofix.zig");
+const migrate = @import("migrate.zig");
+const merging = @import("merge.zig");
+const face = @import("face.zig");
+const stratigraphy = @import("stratigraphy.zig");
+const grammar = @import("grammar.zig");
+const citing = @import("citations.zig");
+const spacing_terms = @import("spacing.zig");
+const scan = @import("scan.zig");
 
 // Re-export submodules for testing
 pub const Lexer = lexer.Lexer;
 pub const Token = lexer.Token;
 pub const TokenType = lexer.TokenType;
+pub const VocabularyTrie = @import("vocabulary.zig").Trie;
 pub const StrengthDatabase = strength_db.StrengthDatabase;
 pub const StrengthParameterType = strength_db.StrengthParameterType;
+pub const Measurement = strength_db.Measurement;
+pub const StrengthProvenance = strength_db.StrengthProvenance;
+pub const GroundwaterAdjustment = strength_db.GroundwaterAdjustment;
+pub const BoundaryAdvisory = strength_db.BoundaryAdvisory;
+pub const mergeMeasured = StrengthDatabase.mergeMeasured;
+pub const adjustForGroundwater = StrengthDatabase.adjustForGroundwater;
 pub const ConstituentDatabase = constituent_db.ConstituentDatabase;
 pub const Validator = validation.Validator;
+pub const CapitalizationPolicy = validation.CapitalizationPolicy;
+pub const ValidationResult = validation.ValidationResult;
+pub const ValidationRule = validation.ValidationError;
+pub const Finding = validation.Finding;
+pub const FindingLevel = validation.Level;
+
+// Re-export project profile types
+pub const ParserConfig = config.ParserConfig;
+pub const CustomDictionary = config.CustomDictionary;
+pub const Profile = profile.Profile;
+pub const ProfileFormat = profile.ProfileFormat;
+pub const loadProfile = profile.loadProfile;
+pub const parseProfile = profile.parseProfile;
+pub const ProfileStore = snapshot.ProfileStore;
+pub const ProfileSnapshot = snapshot.ProfileSnapshot;
+pub const NoiseFilter = noise.NoiseFilter;
+pub const drilling_jargon = noise.drilling_jargon;
+
+// Re-export batch report types
+pub const ReportFormat = report.ReportFormat;
+pub const ReportRow = report.ReportRow;
+pub const BatchSummary = report.BatchSummary;
+pub const writeBatchReport = report.writeBatchReport;
+
+// Re-export flattened CSV export
+pub const CsvWriter = csvio.Writer;
+pub const CsvOptions = csvio.Options;
+pub const ConstituentLayout = csvio.ConstituentLayout;
+pub const default_csv_columns = csvio.default_columns;
+
+// Re-export Markdown tables
+pub const markdownTable = markdown.table;
+
+// Re-export Postgres archiving
+pub const postgresDdl = schema.postgresDdl;
+pub const CopyWriter = schema.CopyWriter;
+
+// Re-export the result store
+pub const ResultStore = result_store.ResultStore;
+pub const StoreEntry = result_store.Entry;
+pub const StoreStats = result_store.StoreStats;
+pub const ResultSet = result_store.ResultSet;
+pub const StoreFilter = result_store.Filter;
+pub const StoreFieldMatch = result_store.FieldMatch;
+pub const StoreCursor = result_store.Cursor;
+pub const StorePage = result_store.Page;
+
+// Re-export stored result migration
+pub const upgradeResultJson = migrate.upgrade;
+
+// Re-export merging of partial descriptions
+pub const MergePolicy = merging.Policy;
+pub const MergeSide = merging.Side;
+pub const MergeConflict = merging.Conflict;
+pub const MergedDescription = merging.Merged;
+pub const mergeDescriptions = merging.merge;
+
+// Re-export stratigraphic unit names
+pub const StratigraphicRank = stratigraphy.Rank;
+pub const stratigraphicRankOf = stratigraphy.rankOf;
+
+// Re-export mixed-face blending
+pub const FaceShare = face.Share;
+pub const FaceSummary = face.FaceSummary;
+pub const FaceLithology = face.Lithology;
+pub const BlendedStrength = face.BlendedStrength;
+pub const blendFace = face.blend;
+
+// Re-export grammar export
+pub const GrammarFormat = grammar.Format;
+pub const writeGrammar = grammar.write;
+
+// Re-export standards citations for derived values
+pub const Citation = citing.Citation;
+pub const citations = citing.citations;
+
+// Re-export canonical hashing
+pub const CanonicalHash = canonical.Hash;
+pub const canonicalForm = canonical.canonicalForm;
+pub const canonicalHash = canonical.canonicalHash;
+pub const canonicalHashOf = canonical.hashOf;
+
+// Re-export differential batch processing
+pub const DeltaRecord = delta.Record;
+pub const DeltaSnapshot = delta.Snapshot;
+pub const DeltaChange = delta.Change;
+pub const DeltaRow = delta.Row;
+pub const Delta = delta.Delta;
+pub const processDelta = delta.processDelta;
+
+// Re-export result sinks
+pub const Sink = sink.Sink;
+pub const SinkResult = sink.Result;
+pub const JsonlSink = sink.JsonlSink;
+pub const CsvSink = sink.CsvSink;
+pub const CallbackSink = sink.CallbackSink;
+pub const ChannelSink = sink.ChannelSink;
+
+// Re-export result aggregation
+pub const Aggregator = aggregate.Aggregator;
+pub const AggregateGroup = aggregate.Group;
+pub const AggregateSummary = aggregate.Summary;
+pub const AggregateTallies = aggregate.Tallies;
+
+// Re-export the example corpus
+pub const CorpusIterator = corpus.Iterator;
+pub const corpusIterator = corpus.iterator;
+pub const loadCorpus = corpus.load;
+
+// Re-export unknown term collection
+pub const UnknownTerms = unknown_terms.UnknownTerms;
+pub const TermCount = unknown_terms.TermCount;
+pub const collectUnknownTerms = unknown_terms.collect;
+
+// Re-export description clean-up
+pub const FixLevel = autofix.FixLevel;
+pub const FixKind = autofix.FixKind;
+pub const Amendment = autofix.Amendment;
+pub const Fix = autofix.Fix;
+pub const autoFix = autofix.autoFix;
+
+// Re-export parse lifecycle hooks
+pub const BeforeParseHook = hooks.BeforeParseHook;
+pub const AfterParseHook = hooks.AfterParseHook;
+pub const UnknownTokenHook = hooks.UnknownTokenHook;
+
+// Re-export plugin interfaces
+pub const Dialect = plugin.Dialect;
+pub const Exporter = plugin.Exporter;
+pub const PluginRegistry = plugin.Registry;
+pub const DictionaryDialect = plugin.DictionaryDialect;
+pub const us_dialect = plugin.us_dialect;
+pub const is1498_dialect = plugin.is1498_dialect;
+pub const saice_dialect = plugin.saice_dialect;
+pub const nordic_dialect = plugin.nordic_dialect;
+pub const offshore_dialect = plugin.offshore_dialect;
+
+// Re-export US sieve grading
+pub const SizeRange = us_sieve.SizeRange;
+pub const sieveOpening = us_sieve.sieveOpening;
+pub const particleSizeForRange = us_sieve.particleSize;
 
 // Re-export anomaly detection
 pub const AnomalyDetector = anomaly.AnomalyDetector;
@@ -31,9 +244,98 @@ pub const ComplianceChecker = compliance.ComplianceChecker;
 pub const ComplianceReport = compliance.ComplianceReport;
 pub const ComplianceIssue = compliance.ComplianceIssue;
 
+// Re-export house-style linting
+pub const HouseStyleLinter = lint.HouseStyleLinter;
+pub const LintRules = lint.LintRules;
+pub const LintElement = lint.LintElement;
+pub const LintIssue = lint.LintIssue;
+pub const LintReport = lint.LintReport;
+
+// Re-export borehole correlation
+pub const BoreholeLog = borehole.BoreholeLog;
+pub const Stratum = borehole.Stratum;
+pub const PhotoRef = borehole.PhotoRef;
+pub const SampleRef = borehole.SampleRef;
+pub const SequenceBuilder = sequence.SequenceBuilder;
+pub const SequenceRelation = sequence.Relation;
+pub const SyntheticPreset = synthetic.Preset;
+pub const SyntheticLog = synthetic.SyntheticLog;
+pub const generateSyntheticLog = synthetic.generateSyntheticLog;
+pub const CorrelationOptions = correlation.CorrelationOptions;
+pub const CorrelationResult = correlation.CorrelationResult;
+pub const StrataPair = correlation.StrataPair;
+pub const correlate = correlation.correlate;
+pub const correlateWithOptions = correlation.correlateWithOptions;
+
+// Re-export re-logging comparison
+pub const StratumChange = relog.StratumChange;
+pub const StratumComparison = relog.StratumComparison;
+pub const LogComparison = relog.LogComparison;
+pub const compareLogs = relog.compareLogs;
+
+// Re-export unit outlier detection
+pub const Outlier = outlier.Outlier;
+pub const OutlierKind = outlier.OutlierKind;
+pub const OutlierOptions = outlier.OutlierOptions;
+pub const OutlierResult = outlier.OutlierResult;
+pub const detectOutliers = outlier.detectOutliers;
+pub const detectOutliersWithOptions = outlier.detectOutliersWithOptions;
+
+// Re-export fallback parsing
+pub const FallbackParser = fallback.FallbackParser;
+pub const HttpFallbackParser = fallback.HttpFallbackParser;
+
+// Re-export feature vector export
+pub const vectorize = embedding.vectorize;
+pub const feature_count = embedding.feature_count;
+pub const feature_names = embedding.feature_names;
+
+// Re-export training data export
+pub const LabelledWord = training.LabelledWord;
+pub const LabelEntity = training.LabelEntity;
+pub const BioTag = training.BioTag;
+pub const labelDescription = training.labelDescription;
+pub const writeConll = training.writeConll;
+
+// Re-export OCR normalisation
+pub const OcrFix = ocr.OcrFix;
+pub const OcrFixKind = ocr.OcrFixKind;
+pub const OcrNormalization = ocr.OcrNormalization;
+pub const normalizeOcr = ocr.normalize;
+
+// Re-export dictation normalisation
+pub const normalizeDictation = dictation.normalize;
+
 // Re-export types
+pub const GeologicalDescription = types.GeologicalDescription;
+/// Deprecated: use `GeologicalDescription`
 pub const SoilDescription = types.SoilDescription;
+pub const SoilProperties = types.SoilProperties;
+pub const RockProperties = types.RockProperties;
+pub const Description = result_types.Description;
+pub const SoilResult = result_types.SoilResult;
+pub const RockResult = result_types.RockResult;
+pub const CommonFields = result_types.CommonFields;
 pub const MaterialType = types.MaterialType;
+pub const Sensitivity = types.Sensitivity;
+pub const KarstGrade = types.KarstGrade;
+pub const MaterialOrigin = types.MaterialOrigin;
+pub const AnthropogenicMaterial = types.AnthropogenicMaterial;
+pub const MadeGroundConstituent = types.MadeGroundConstituent;
+pub const MarineIndicator = types.MarineIndicator;
+pub const GasIndicators = types.GasIndicators;
+pub const Frequency = types.Frequency;
+pub const Inclusion = types.Inclusion;
+pub const TertiaryConstituent = types.TertiaryConstituent;
+pub const CompositeDescription = types.CompositeDescription;
+pub const CompositeComponent = types.CompositeComponent;
+pub const CompositeArrangement = types.CompositeArrangement;
+pub const DiscontinuitySpacing = types.DiscontinuitySpacing;
+pub const SpacingTerm = types.SpacingTerm;
+pub const Condition = types.Condition;
+pub const ConditionNote = types.ConditionNote;
+pub const AlternativeType = types.AlternativeType;
+pub const Standard = types.Standard;
 pub const SoilType = types.SoilType;
 pub const RockType = types.RockType;
 pub const Consistency = types.Consistency;
@@ -41,7 +343,28 @@ pub const Density = types.Density;
 pub const RockStrength = types.RockStrength;
 pub const WeatheringGrade = types.WeatheringGrade;
 pub const RockStructure = types.RockStructure;
+pub const Color = types.Color;
+pub const Colour = types.Colour;
+pub const Hue = types.Hue;
+pub const Shade = types.Shade;
+pub const Tone = types.Tone;
+pub const MunsellColour = types.MunsellColour;
+pub const MunsellHue = types.MunsellHue;
+pub const PlasticityIndex = types.PlasticityIndex;
+pub const Angularity = types.Angularity;
+pub const ParticleForm = types.ParticleForm;
+pub const ParticleShape = types.ParticleShape;
+pub const PeatFabric = types.PeatFabric;
+pub const VonPost = types.VonPost;
+pub const PeatProperties = types.PeatProperties;
 pub const SecondaryConstituent = types.SecondaryConstituent;
+pub const Absence = types.Absence;
+pub const Source = types.Source;
+pub const Field = types.Field;
+pub const Span = types.Span;
+pub const unknown_value = types.unknown_value;
+pub const fromStored = types.fromStored;
+pub const toStored = types.toStored;
 
 // Re-export generator functions
 pub const generate = generator.generate;
@@ -54,29 +377,191 @@ pub const generateVariations = generator.generateVariations;
 pub const generateWithStrength = generator.generateWithStrength;
 pub const generateLabel = generator.generateLabel;
 
+// Re-export teaching explanations
+pub const explain = explanation.explain;
+
+// Re-export HTML report fragments
+pub const toHtml = html.toHtml;
+
+// Re-export karst void risk
+pub const VoidRisk = voiding.VoidRisk;
+pub const voidRisk = voiding.voidRisk;
+
+// Re-export offshore carbonate soil classification
+pub const CarbonateClass = carbonate.CarbonateClass;
+pub const CarbonateContent = carbonate.CarbonateContent;
+pub const Cementation = carbonate.Cementation;
+pub const classifyCarbonate = carbonate.classify;
+
+// Re-export CPT soil behaviour type mapping
+pub const SbtZone = cpt.SbtZone;
+pub const IcRange = cpt.IcRange;
+pub const CptAgreement = cpt.Agreement;
+pub const sbtZoneOf = cpt.zoneOf;
+pub const icRangeOf = cpt.icRangeOf;
+pub const compareWithCpt = cpt.compare;
+
+// Re-export AS 1726 support
+pub const generateAs1726 = as1726.generate;
+pub const normalizeAs1726 = as1726.normalize;
+pub const as1726ShearStrengthBand = as1726.shearStrengthBand;
+pub const as1726DensityIndexBand = as1726.densityIndexBand;
+
+// Re-export display string localisation
+pub const Locale = localization.Locale;
+pub const localize = localization.localize;
+pub const findUnlocalized = localization.findUnlocalized;
+
+// Re-export the glossary
+pub const Definition = glossary.Definition;
+pub const define = glossary.define;
+
+// Re-export the startup self-test
+pub const selfTest = self_test.selfTest;
+pub const runSelfTest = self_test.run;
+pub const SelfTestFailure = self_test.Failure;
+
 // Re-export fuzzy functions
 pub const levenshteinDistance = fuzzy.levenshteinDistance;
 pub const similarityRatio = fuzzy.similarityRatio;
+pub const similarityMatrix = fuzzy.similarityMatrix;
+pub const fuzzyMatchN = fuzzy.fuzzyMatchN;
+pub const MatchMode = fuzzy.MatchMode;
+pub const SimilarityAlgorithm = fuzzy.Algorithm;
+pub const SimilarityOptions = fuzzy.SimilarityOptions;
+pub const compareSimilarity = fuzzy.compare;
+pub const phoneticKey = fuzzy.phoneticKey;
+pub const FuzzyCandidate = fuzzy.Candidate;
 
 pub const Parser = struct {
     allocator: std.mem.Allocator,
+    // Optional secondary parser consulted when rule-based confidence is low
+    fallback: ?FallbackParser = null,
+    fallback_threshold: f32 = 0.5,
+    // Repair OCR damage ("FirmC1AY") before parsing scanned logs
+    ocr_mode: bool = false,
+    // Whether primary types must be upper case ("Firm CLAY")
+    capitalization_policy: CapitalizationPolicy = .ignored,
+    // Validation rules to skip, by code ("W002") or name ("MissingConsistency")
+    suppressed_rules: []const []const u8 = &.{},
+    // Project terms rewritten to standard ones before parsing ("boulder clay" -> CLAY)
+    vocabulary: ?*const CustomDictionary = null,
+    // How misspelt terms are matched; .phonetic also catches misheard ones ("shail")
+    match_mode: fuzzy.MatchMode = .edit_distance,
+    // Similarity measure for misspelt terms; .jaro_winkler favours shared prefixes
+    similarity_algorithm: fuzzy.Algorithm = .levenshtein,
+    // Whether misspelt terms are matched at all, and how close they must be
+    fuzzy_matching: bool = true,
+    fuzzy_threshold: f32 = 0.8,
+    // Confidence below which validation reports W001 LowConfidence
+    min_confidence: f32 = 0.5,
+    // Log jargon blanked out before tokenising so it is never read as a term
+    noise_filter: NoiseFilter = .{},
+    // Description standard; .as1726 rewrites AS 1726 wording and order before parsing
+    standard: Standard = .bs5930,
+    // Regional parsing pack applied before the project vocabulary
+    dialect: ?Dialect = null,
+    // Flag derived strength ranges for soils described as wet or saturated
+    adjust_for_groundwater: bool = false,
+    // Give up on one description with error.ParseTimeout after this long; off when null
+    timeout_ns: ?u64 = null,
+    // Lifecycle hooks registered with onBeforeParse/onAfterParse/onUnknownToken
+    before_parse_hook: ?BeforeParseHook = null,
+    after_parse_hook: ?AfterParseHook = null,
+    unknown_token_hook: ?UnknownTokenHook = null,
 
     pub fn init(allocator: std.mem.Allocator) Parser {
         return Parser{ .allocator = allocator };
     }
 
-    pub fn parse(self: *Parser, description: []const u8) !SoilDescription {
+    pub fn initOcrMode(allocator: std.mem.Allocator) Parser {
+        return Parser{ .allocator = allocator, .ocr_mode = true };
+    }
+
+    pub fn initWithFallback(allocator: std.mem.Allocator, fallback_parser: FallbackParser, threshold: f32) Parser {
+        return Parser{
+            .allocator = allocator,
+            .fallback = fallback_parser,
+            .fallback_threshold = threshold,
+        };
+    }
+
+    /// Register `callback(context, description)` to run before each parse.
+    /// An error from the callback aborts the parse.
+    pub fn onBeforeParse(self: *Parser, context: anytype, comptime callback: anytype) void {
+        self.before_parse_hook = BeforeParseHook.init(context, callback);
+    }
+
+    /// Register `callback(context, *GeologicalDescription)` to run on each result,
+    /// after validation and any fallback. The callback may modify the result.
+    pub fn onAfterParse(self: *Parser, context: anytype, comptime callback: anytype) void {
+        self.after_parse_hook = AfterParseHook.init(context, callback);
+    }
+
+    /// Register `callback(context, Token)` to run for each word the lexer
+    /// could not classify, e.g. to queue terms for dictionary building
+    pub fn onUnknownToken(self: *Parser, context: anytype, comptime callback: anytype) void {
+        self.unknown_token_hook = UnknownTokenHook.init(context, callback);
+    }
+
+    pub fn parse(self: *Parser, description: []const u8) !GeologicalDescription {
+        const deadline: ?Deadline = if (self.timeout_ns) |limit| Deadline.start(limit) else null;
+        if (self.before_parse_hook) |hook| try hook.call(description);
+
         // Clone the description to avoid memory issues
         const owned_description = try self.allocator.dupe(u8, description);
-        var preprocessed = try self.preprocessDescription(description);
+        // Freed with the result once there is one
+        var result_owns_description = false;
+        errdefer if (!result_owns_description) self.allocator.free(owned_description);
+
+        var ocr_normalization: ?ocr.OcrNormalization = null;
+        defer if (ocr_normalization) |*normalization| normalization.deinit(self.allocator);
+        if (self.ocr_mode) {
+            ocr_normalization = try ocr.normalize(self.allocator, description);
+        }
+        const ocr_input = if (ocr_normalization) |normalization| normalization.text else description;
+
+        const standard_text: ?[]u8 = if (self.standard == .as1726) try as1726.normalize(self.allocator, ocr_input) else null;
+        defer if (standard_text) |text| self.allocator.free(text);
+        const standard_input = standard_text orelse ocr_input;
+
+        const dialect_text: ?[]u8 = if (self.dialect) |dialect| try dialect.normalize(self.allocator, standard_input) else null;
+        defer if (dialect_text) |text| self.allocator.free(text);
+        const dialect_input = dialect_text orelse standard_input;
+
+        const vocabulary_text: ?[]u8 = if (self.vocabulary) |vocabulary| try vocabulary.substitute(self.allocator, dialect_input) else null;
+        defer if (vocabulary_text) |text| self.allocator.free(text);
+        const parse_input = vocabulary_text orelse dialect_input;
+
+        const filtered_input = try self.allocator.dupe(u8, parse_input);
+        defer self.allocator.free(filtered_input);
+        self.noise_filter.blank(filtered_input);
+        // Read before the brackets, where "(10YR 5/3)" or "(H4)" would be
+        // taken for a formation name
+        const munsell_match = munsell.find(filtered_input);
+        if (munsell_match) |match| munsell.blank(filtered_input, match);
+        const peat_match = peat.find(filtered_input);
+        if (peat_match) |match| peat.blank(filtered_input, match);
+        // "(60-150 mm)" is not a formation name, and "medium spaced" not a particle size
+        const spacing_match = spacing_terms.find(filtered_input);
+        if (spacing_match) |match| spacing_terms.blank(filtered_input, match);
+
+        var preprocessed = try self.preprocessDescription(filtered_input);
         defer {
             self.allocator.free(preprocessed.parse_text);
             if (preprocessed.geological_formation) |formation| self.allocator.free(formation);
             if (preprocessed.made_ground_label) |label| self.allocator.free(label);
+            self.allocator.free(preprocessed.condition_notes);
         }
 
+        try checkDeadline(deadline);
         var lex = Lexer.init(self.allocator, preprocessed.parse_text);
         defer lex.deinit();
+        lex.match_mode = self.match_mode;
+        lex.algorithm = self.similarity_algorithm;
+        lex.fuzzy_matching = self.fuzzy_matching;
+        lex.fuzzy_threshold = self.fuzzy_threshold;
+        lex.deadline = deadline;
 
         const tokens = try lex.tokenize();
         defer {
@@ -90,16 +575,169 @@ pub const Parser = struct {
             self.allocator.free(tokens);
         }
 
+        if (self.unknown_token_hook) |hook| {
+            for (tokens) |token| {
+                if (token.type == .word or token.type == .unknown) try hook.call(token);
+            }
+        }
+
+        // Negated phrases ("no visible organic matter") are not detections
+        const absence_matches = try absence.find(self.allocator, preprocessed.parse_text, tokens);
+        defer self.allocator.free(absence_matches);
+        for (absence_matches) |match| {
+            if (!match.suppress_tokens) continue;
+            for (tokens[match.first_token .. match.last_token + 1]) |*token| {
+                token.type = .unknown;
+            }
+        }
+
         // Determine material type by checking for rock or soil indicators
         const material_type = self.determineMaterialType(tokens);
 
-        var result = SoilDescription{
+        var result = GeologicalDescription{
             .raw_description = owned_description,
             .material_type = material_type,
         };
-
-        result = try self.parseTokens(tokens, result);
-        result.is_made_ground = preprocessed.is_made_ground;
+        result_owns_description = true;
+        errdefer result.deinit(self.allocator);
+
+        result = try self.parseTokens(tokens, preprocessed.parse_text, result);
+        try checkDeadline(deadline);
+        if (result.material_type == .soil) {
+            if (sensitivity_terms.find(preprocessed.parse_text)) |match| {
+                result.sensitivity = match.sensitivity;
+                result.markSpan(.sensitivity, match.start, match.end);
+            }
+        }
+        if (colour_terms.find(preprocessed.parse_text)) |match| {
+            result.colour_detail = match.colour;
+            result.markSpan(.colour_detail, match.start, match.end);
+        }
+        if (munsell_match) |match| result.munsell_colour = match.colour;
+        if (spacing_match) |match| result.discontinuity_spacing = match.spacing;
+        if (particle_shape.find(preprocessed.parse_text)) |match| {
+            result.particle_shape = match.shape;
+            result.markSpan(.particle_shape, match.start, match.end);
+        }
+        if (peat_match) |match| {
+            if (peat.describesPeat(result)) result.peat_properties = match.properties;
+        }
+        const clauses = try inclusion_clauses.read(self.allocator, preprocessed.parse_text);
+        defer clauses.deinit(self.allocator);
+        var inclusion_list = std.ArrayList(types.Inclusion).init(self.allocator);
+        defer inclusion_list.deinit();
+        for (clauses.inclusions) |match| {
+            if (isNegated(tokens, absence_matches, match.start, match.end)) continue;
+            try inclusion_list.append(match.inclusion);
+            result.markSpan(.inclusions, match.start, match.end);
+        }
+        result.inclusions = try inclusion_list.toOwnedSlice();
+        // Tertiary constituents are everything after "with", soils included,
+        // in the order written
+        var tertiary_list = std.ArrayList(types.TertiaryConstituent).init(self.allocator);
+        defer tertiary_list.deinit();
+        errdefer for (tertiary_list.items) |constituent| self.allocator.free(constituent.name);
+        var soil_index: usize = 0;
+        var other_index: usize = 0;
+        while (soil_index < clauses.inclusions.len or other_index < clauses.tertiary.len) {
+            const soil_next = other_index >= clauses.tertiary.len or
+                (soil_index < clauses.inclusions.len and clauses.inclusions[soil_index].start < clauses.tertiary[other_index].start);
+            var constituent: types.TertiaryConstituent = undefined;
+            var start: usize = undefined;
+            var end: usize = undefined;
+            if (soil_next) {
+                const match = clauses.inclusions[soil_index];
+                soil_index += 1;
+                if (isNegated(tokens, absence_matches, match.start, match.end)) continue;
+                const soil_type = match.inclusion.soil_type;
+                constituent = .{ .frequency = match.inclusion.frequency, .name = try self.allocator.dupe(u8, @tagName(soil_type)), .soil_type = soil_type };
+                start = match.start;
+                end = match.end;
+            } else {
+                const match = clauses.tertiary[other_index];
+                other_index += 1;
+                if (isNegated(tokens, absence_matches, match.start, match.end)) continue;
+                constituent = .{ .frequency = match.frequency, .name = try inclusion_clauses.nameOf(self.allocator, preprocessed.parse_text, match) };
+                start = match.start;
+                end = match.end;
+            }
+            tertiary_list.append(constituent) catch |err| {
+                self.allocator.free(constituent.name);
+                return err;
+            };
+            result.markSpan(.tertiary_constituents, start, end);
+        }
+        result.tertiary_constituents = try tertiary_list.toOwnedSlice();
+        // Shells in a LIMESTONE or coral in a reef rock say nothing about
+        // a marine soil, so only soils carry marine indicators
+        var marine_matches = marine.iterate(preprocessed.parse_text);
+        while (marine_matches.next()) |match| {
+            if (result.material_type != .soil) break;
+            if (isNegated(tokens, absence_matches, match.start, match.end)) continue;
+            result.marine_indicators.insert(match.indicator);
+            result.markSpan(.marine_indicators, match.start, match.end);
+        }
+        if (fossils.find(preprocessed.parse_text)) |match| {
+            result.fossil_frequency = match.frequency;
+            result.markSpan(.fossil_frequency, match.start, match.end);
+        }
+        var gas_matches = gas.iterate(preprocessed.parse_text);
+        while (gas_matches.next()) |match| {
+            if (isNegated(tokens, absence_matches, match.start, match.end)) continue;
+            if (result.gas_indicators == null) result.gas_indicators = .{};
+            gas.record(&result.gas_indicators.?, match.kind);
+            result.markSpan(.gas_indicators, match.start, match.end);
+        }
+        if (anthropogenic.find(preprocessed.parse_text)) |match| {
+            result.material_origin = match.origin;
+            result.markSpan(.material_origin, match.start, match.end);
+        }
+        const made_ground_matches = try made_ground.findAll(self.allocator, preprocessed.parse_text);
+        defer self.allocator.free(made_ground_matches);
+        var made_ground_list = std.ArrayList(types.MadeGroundConstituent).init(self.allocator);
+        defer made_ground_list.deinit();
+        for (made_ground_matches) |match| {
+            if (isNegated(tokens, absence_matches, match.start, match.end)) continue;
+            try made_ground_list.append(match.constituent);
+            result.markSpan(.made_ground_constituents, match.start, match.end);
+        }
+        result.made_ground_constituents = try made_ground_list.toOwnedSlice();
+        if (composite.find(preprocessed.parse_text)) |match| {
+            result.composite = try match.toOwned(self.allocator);
+            result.markSpan(.composite, match.start, match.end);
+        }
+        if (result.material_type == .rock) {
+            if (karst.find(preprocessed.parse_text)) |match| {
+                // Cavities in a basalt are not karst
+                const soluble = if (result.primary_rock_type) |rock_type| voiding.isSoluble(rock_type) else true;
+                if (match.explicit or soluble) {
+                    result.karst_grade = match.grade;
+                    result.markSpan(.karst_grade, match.start, match.end);
+                    if (!match.explicit) result.sources.put(.karst_grade, .inferred);
+                }
+            }
+        }
+        // Spans are only meaningful while the parsed text still lines up with
+        // the raw description; OCR repair, dialects and vocabularies rewrite it
+        if (std.mem.eql(u8, parse_input, description)) {
+            for (std.enums.values(types.Field)) |field| {
+                const span = result.spans.get(field) orelse continue;
+                result.spans.put(field, .{ .start = span.start + preprocessed.offset, .end = span.end + preprocessed.offset });
+            }
+            if (preprocessed.formation_span) |span| result.spans.put(.geological_formation, span);
+            if (preprocessed.condition_span) |span| result.spans.put(.condition_notes, span);
+            if (preprocessed.alternative_span) |span| result.spans.put(.alternative_type, span);
+            if (preprocessed.made_ground_span) |span| result.spans.put(.made_ground_label, span);
+            if (preprocessed.topsoil_span) |span| result.spans.put(.topsoil, span);
+            if (munsell_match) |match| result.spans.put(.munsell_colour, .{ .start = match.start, .end = match.end });
+            if (spacing_match) |match| result.spans.put(.discontinuity_spacing, .{ .start = match.start, .end = match.end });
+            if (result.peat_properties != null) result.spans.put(.peat_properties, .{ .start = peat_match.?.start, .end = peat_match.?.end });
+        } else {
+            result.spans = .{};
+        }
+        result.absences = try self.collectAbsences(absence_matches);
+        // Natural ground does not hold brick or concrete
+        result.is_made_ground = preprocessed.is_made_ground or result.material_origin != null or made_ground.indicatesMadeGround(result.made_ground_constituents);
         if (preprocessed.geological_formation) |formation| {
             result.geological_formation = formation;
             preprocessed.geological_formation = null;
@@ -108,14 +746,243 @@ pub const Parser = struct {
             result.made_ground_label = label;
             preprocessed.made_ground_label = null;
         }
+        result.is_topsoil = preprocessed.is_topsoil;
+        result.condition_notes = preprocessed.condition_notes;
+        preprocessed.condition_notes = &.{};
+        result.alternative_type = preprocessed.alternative_type;
+
+        // Lookups are inferred and an unmarked description defaults to soil;
+        // everything else was read from the text
+        if (result.strength_parameters != null) result.sources.put(.strength_parameters, .inferred);
+        if (result.constituent_guidance != null) result.sources.put(.constituent_guidance, .inferred);
+        if (result.boundary_advisory != null) result.sources.put(.boundary_advisory, .inferred);
+        if (result.material_type == .soil and result.primary_soil_type == null and !result.is_made_ground and !result.is_topsoil) {
+            result.sources.put(.material_type, .default);
+        }
+        result.tagSources(.parsed);
+
+        if (ocr_normalization) |normalization| {
+            try self.appendOcrCorrections(&result, normalization.fixes);
+        }
+
+        try checkDeadline(deadline);
 
         // Validate the parsed description
-        var validator = Validator.init(self.allocator);
+        var validator = self.createValidator();
         try validator.validate(&result);
 
+        // Consult the fallback parser when the rule-based result is weak
+        if (self.fallback) |fallback_parser| {
+            try checkDeadline(deadline);
+            if (result.confidence < self.fallback_threshold) {
+                if (self.tryFallback(fallback_parser, description, result.confidence)) |fallback_result| {
+                    result.deinit(self.allocator);
+                    result = fallback_result;
+                    // Whatever the fallback tagged, none of it came from the rules
+                    result.sources = .{};
+                    result.tagSources(.fallback);
+                }
+            }
+        }
+
+        if (self.after_parse_hook) |hook| try hook.call(&result);
+
         return result;
     }
 
+    fn checkDeadline(deadline: ?Deadline) !void {
+        if (deadline) |limit| try limit.check();
+    }
+
+    /// A validator configured with this parser's capitalisation policy and suppressed rules
+    pub fn createValidator(self: *const Parser) Validator {
+        var validator = Validator.init(self.allocator);
+        validator.capitalization_policy = self.capitalization_policy;
+        validator.suppressed_rules = self.suppressed_rules;
+        validator.low_confidence_threshold = self.min_confidence;
+        return validator;
+    }
+
+    /// Parse a batch of descriptions and write a CSV or HTML validation report.
+    /// Report row numbers are 1-based positions in `descriptions`.
+    pub fn validateBatchReport(self: *Parser, descriptions: []const []const u8, writer: anytype, format: ReportFormat) !BatchSummary {
+        const parsed = try self.allocator.alloc(GeologicalDescription, descriptions.len);
+        var parsed_count: usize = 0;
+        defer {
+            for (parsed[0..parsed_count]) |desc| desc.deinit(self.allocator);
+            self.allocator.free(parsed);
+        }
+
+        const rows = try self.allocator.alloc(ReportRow, descriptions.len);
+        defer self.allocator.free(rows);
+
+        for (descriptions, 0..) |description, i| {
+            parsed[i] = try self.parse(description);
+            parsed_count += 1;
+            rows[i] = ReportRow{ .row = i + 1, .description = &parsed[i] };
+        }
+
+        var validator = self.createValidator();
+        return report.writeBatchReport(self.allocator, &validator, rows, writer, format);
+    }
+
+    /// Adopt a project profile's parser and validation settings. The profile
+    /// must outlive the parser. A `dialect` must name a built-in dialect;
+    /// for a registered one, leave it out and call `useDialect`.
+    pub fn applyProfile(self: *Parser, project_profile: *const Profile) !void {
+        if (project_profile.standard) |name| {
+            self.standard = Standard.fromString(name) orelse return error.UnknownStandard;
+        }
+        if (project_profile.dialect) |name| {
+            self.dialect = plugin.builtinDialect(name) orelse return error.UnknownDialect;
+        }
+        self.ocr_mode = project_profile.ocr_mode;
+        self.fuzzy_matching = project_profile.config.enable_fuzzy_matching;
+        self.fuzzy_threshold = project_profile.config.fuzzy_threshold;
+        self.min_confidence = project_profile.config.min_confidence;
+        self.capitalization_policy = project_profile.config.capitalization_policy;
+        self.suppressed_rules = project_profile.config.suppressed_rules;
+        self.noise_filter = project_profile.config.noise_filter;
+        self.vocabulary = &project_profile.vocabulary;
+    }
+
+    /// Parse with the store's current profile. Settings are applied to a
+    /// per-call copy of the parser, so a concurrent reload or another thread's
+    /// profile never changes a parse already in progress.
+    pub fn parseWithProfile(self: *const Parser, store: *ProfileStore, description: []const u8) !GeologicalDescription {
+        const current = store.acquire();
+        defer current.release();
+
+        var local = self.*;
+        try local.applyProfile(&current.profile);
+        return local.parse(description);
+    }
+
+    /// The grammar this parser accepts, with its project vocabulary, as ISO
+    /// EBNF or as W3C EBNF for railroad diagram tools. Caller owns the text.
+    pub fn exportGrammar(self: *const Parser, format: GrammarFormat) ![]u8 {
+        var text = std.ArrayList(u8).init(self.allocator);
+        errdefer text.deinit();
+        try grammar.write(self.allocator, text.writer(), format, self.vocabulary);
+        return text.toOwnedSlice();
+    }
+
+    /// Use a registered dialect pack, e.g. the one named by a profile's `dialect`
+    pub fn useDialect(self: *Parser, registry: *const PluginRegistry, name: []const u8) !void {
+        self.dialect = registry.getDialect(name) orelse return error.UnknownDialect;
+    }
+
+    /// Parse into a result typed by material, so soil consumers never see
+    /// rock fields and vice versa. `parse` keeps returning a
+    /// `GeologicalDescription`: every caller, the C API and the JSON writers
+    /// depend on it, so the typed result is opt-in rather than a breaking change.
+    pub fn parseTyped(self: *Parser, description: []const u8) !Description {
+        const parsed = try self.parse(description);
+        errdefer parsed.deinit(self.allocator);
+        return try Description.fromGeological(self.allocator, parsed);
+    }
+
+    /// Parse a dictated description ("firm clay slightly sandy brown") after
+    /// reordering and capitalising it into standard form. The normalised text
+    /// becomes the raw description.
+    pub fn parseDictation(self: *Parser, dictated: []const u8) !GeologicalDescription {
+        const normalized = try dictation.normalize(self.allocator, dictated);
+        defer self.allocator.free(normalized);
+
+        // Dictated terms go wrong by sound rather than by keystroke
+        const previous_mode = self.match_mode;
+        self.match_mode = .phonetic;
+        defer self.match_mode = previous_mode;
+
+        return self.parse(normalized);
+    }
+
+    /// Correct one attribute of a stored description, rewriting only the words
+    /// behind `field` ("Firm brown CLAY", .consistency, "Stiff" gives "Stiff
+    /// brown CLAY"). Returns caller-owned text, or error.AttributeNotFound if
+    /// the field was not read from the text.
+    pub fn replaceAttribute(self: *Parser, raw: []const u8, field: types.Field, new_value: []const u8) ![]u8 {
+        const parsed = try self.parse(raw);
+        defer parsed.deinit(self.allocator);
+
+        const span = parsed.spanOf(field) orelse return error.AttributeNotFound;
+        return std.mem.concat(self.allocator, u8, &.{ raw[0..span.start], new_value, raw[span.end..] });
+    }
+
+    /// Whether `[start, end)` of the parsed text is inside a negated phrase
+    /// ("no shell fragments")
+    fn isNegated(tokens: []const Token, matches: []const absence.Match, start: usize, end: usize) bool {
+        for (matches) |match| {
+            if (!match.suppress_tokens) continue;
+            if (start >= tokens[match.first_token].start and end <= tokens[match.last_token].end) return true;
+        }
+        return false;
+    }
+
+    fn collectAbsences(self: *Parser, matches: []const absence.Match) ![]Absence {
+        var absences = std.ArrayList(Absence).init(self.allocator);
+        errdefer {
+            for (absences.items) |item| {
+                self.allocator.free(item.feature);
+                self.allocator.free(item.phrase);
+            }
+            absences.deinit();
+        }
+
+        for (matches) |match| {
+            const feature = try self.allocator.dupe(u8, match.absence.feature);
+            errdefer self.allocator.free(feature);
+            const phrase = try self.allocator.dupe(u8, match.absence.phrase);
+            errdefer self.allocator.free(phrase);
+            try absences.append(Absence{ .feature = feature, .phrase = phrase });
+        }
+
+        return absences.toOwnedSlice();
+    }
+
+    /// Report OCR repairs alongside the lexer's spelling corrections
+    fn appendOcrCorrections(self: *Parser, result: *GeologicalDescription, fixes: []const ocr.OcrFix) !void {
+        if (fixes.len == 0) return;
+
+        const existing = result.spelling_corrections.len;
+        var corrections = std.ArrayList(types.SpellingCorrection).init(self.allocator);
+        defer corrections.deinit();
+        try corrections.ensureTotalCapacity(existing + fixes.len);
+        corrections.appendSliceAssumeCapacity(result.spelling_corrections);
+        // The existing corrections stay owned by `result` until the swap below
+        errdefer for (corrections.items[existing..]) |correction| {
+            self.allocator.free(correction.original);
+            self.allocator.free(correction.corrected);
+        };
+
+        for (fixes) |fix| {
+            const similarity_score = try fuzzy.similarityRatio(fix.original, fix.corrected, self.allocator);
+            const original = try self.allocator.dupe(u8, fix.original);
+            errdefer self.allocator.free(original);
+            const corrected = try self.allocator.dupe(u8, fix.corrected);
+            corrections.appendAssumeCapacity(types.SpellingCorrection{
+                .original = original,
+                .corrected = corrected,
+                .similarity_score = similarity_score,
+            });
+        }
+
+        const merged = try corrections.toOwnedSlice();
+        self.allocator.free(result.spelling_corrections);
+        result.spelling_corrections = merged;
+    }
+
+    /// Returns the fallback result only if it beats the rule-based confidence.
+    /// Fallback failures are not fatal; the rule-based result is kept.
+    fn tryFallback(self: *Parser, fallback_parser: FallbackParser, description: []const u8, rule_confidence: f32) ?GeologicalDescription {
+        const candidate = (fallback_parser.parse(self.allocator, description) catch return null) orelse return null;
+        if (candidate.confidence <= rule_confidence) {
+            candidate.deinit(self.allocator);
+            return null;
+        }
+        return candidate;
+    }
+
     fn determineMaterialType(self: *Parser, tokens: []Token) MaterialType {
         _ = self;
 
@@ -142,11 +1009,15 @@ pub const Parser = struct {
         return .soil;
     }
 
-    fn parseTokens(self: *Parser, tokens: []Token, result: SoilDescription) !SoilDescription {
+    fn parseTokens(self: *Parser, tokens: []Token, text: []const u8, result: GeologicalDescription) !GeologicalDescription {
         var parsed = result;
         var i: usize = 0;
         var secondary_constituents = std.ArrayList(SecondaryConstituent).init(self.allocator);
         defer secondary_constituents.deinit();
+        // Soils after "with" are held in the main soil, not primary types;
+        // the clause itself is read by inclusions.zig and, like it, ends
+        // with its sentence
+        var in_with_clause = false;
 
         // Collect spelling corrections from tokens
         var spelling_corrections = std.ArrayList(types.SpellingCorrection).init(self.allocator);
@@ -165,12 +1036,28 @@ pub const Parser = struct {
 
         while (i < tokens.len) {
             const token = tokens[i];
+            if (in_with_clause and i > 0 and endsSentence(text[tokens[i - 1].end..token.start])) {
+                in_with_clause = false;
+            }
 
             switch (token.type) {
                 .consistency_range, .consistency => {
                     if (parsed.material_type == .soil and parsed.consistency == null) {
                         if (Consistency.fromString(token.value)) |consistency| {
                             parsed.consistency = consistency;
+                            parsed.markSpan(.consistency, token.start, token.end);
+
+                            // "firm-stiff" and "firm/stiff" read as "firm to stiff"
+                            if (joinedNext(tokens, i, .consistency)) |next| {
+                                if (Consistency.fromString(next.value)) |upper| {
+                                    if (Consistency.range(consistency, upper)) |range| {
+                                        parsed.consistency = range;
+                                        parsed.markSpan(.consistency, token.start, next.end);
+                                        i += 2;
+                                        continue;
+                                    }
+                                }
+                            }
                         }
                     }
                     i += 1;
@@ -179,6 +1066,18 @@ pub const Parser = struct {
                     if (parsed.material_type == .soil and parsed.density == null) {
                         if (Density.fromString(token.value)) |density| {
                             parsed.density = density;
+                            parsed.markSpan(.density, token.start, token.end);
+
+                            if (joinedNext(tokens, i, .density)) |next| {
+                                if (Density.fromString(next.value)) |upper| {
+                                    if (Density.range(density, upper)) |range| {
+                                        parsed.density = range;
+                                        parsed.markSpan(.density, token.start, next.end);
+                                        i += 2;
+                                        continue;
+                                    }
+                                }
+                            }
                         }
                     }
                     i += 1;
@@ -187,6 +1086,7 @@ pub const Parser = struct {
                     if (parsed.material_type == .rock and parsed.rock_strength == null) {
                         if (RockStrength.fromString(token.value)) |strength| {
                             parsed.rock_strength = strength;
+                            parsed.markSpan(.rock_strength, token.start, token.end);
                         }
                     }
                     i += 1;
@@ -195,6 +1095,7 @@ pub const Parser = struct {
                     if (parsed.material_type == .rock and parsed.weathering_grade == null) {
                         if (WeatheringGrade.fromString(token.value)) |weathering| {
                             parsed.weathering_grade = weathering;
+                            parsed.markSpan(.weathering_grade, token.start, token.end);
                         }
                     }
                     i += 1;
@@ -203,6 +1104,7 @@ pub const Parser = struct {
                     if (parsed.material_type == .rock and parsed.rock_structure == null) {
                         if (RockStructure.fromString(token.value)) |structure| {
                             parsed.rock_structure = structure;
+                            parsed.markSpan(.rock_structure, token.start, token.end);
                         }
                     }
                     i += 1;
@@ -211,6 +1113,7 @@ pub const Parser = struct {
                     if (parsed.material_type == .rock and parsed.primary_rock_type == null) {
                         if (RockType.fromString(token.value)) |rock_type| {
                             parsed.primary_rock_type = rock_type;
+                            parsed.markSpan(.primary_rock_type, token.start, token.end);
                         }
                     }
                     i += 1;
@@ -225,6 +1128,7 @@ pub const Parser = struct {
                                     .amount = try self.allocator.dupe(u8, sc_result.constituent.amount),
                                     .soil_type = try self.allocator.dupe(u8, sc_result.constituent.soil_type),
                                 });
+                                parsed.markSpan(.secondary_constituents, token.start, tokens[i + sc_result.tokens_consumed - 1].end);
                                 i += sc_result.tokens_consumed;
                                 continue;
                             }
@@ -238,25 +1142,33 @@ pub const Parser = struct {
                             .amount = try self.allocator.dupe(u8, constituent.amount),
                             .soil_type = try self.allocator.dupe(u8, constituent.soil_type),
                         });
+                        parsed.markSpan(.secondary_constituents, token.start, token.end);
                     }
                     i += 1;
                 },
                 .soil_type => {
-                    if (parsed.material_type == .soil) {
+                    if (parsed.material_type == .soil and !in_with_clause) {
                         if (SoilType.fromString(token.value)) |soil_type| {
                             if (parsed.primary_soil_type == null) {
                                 parsed.primary_soil_type = soil_type;
+                                parsed.markSpan(.primary_soil_type, token.start, token.end);
                             } else if (parsed.secondary_primary_soil_type == null and i > 0 and tokens[i - 1].type == .word and std.ascii.eqlIgnoreCase(tokens[i - 1].value, "and")) {
                                 parsed.secondary_primary_soil_type = soil_type;
+                                parsed.markSpan(.secondary_primary_soil_type, token.start, token.end);
                             }
                         }
                     }
                     i += 1;
                 },
                 .color => {
-                    if (parsed.color == null) {
-                        if (types.Color.fromString(token.value)) |color| {
+                    if (types.Color.fromString(token.value)) |color| {
+                        if (parsed.color == null) {
                             parsed.color = color;
+                            parsed.markSpan(.color, token.start, token.end);
+                        } else if (parsed.secondary_color == null and i > 0 and tokens[i - 1].type == .color and tokens[i - 1].joiner != null) {
+                            // "grey/brown" keeps both colours
+                            parsed.secondary_color = color;
+                            parsed.markSpan(.secondary_color, token.start, token.end);
                         }
                     }
                     i += 1;
@@ -265,6 +1177,7 @@ pub const Parser = struct {
                     if (parsed.moisture_content == null) {
                         if (types.MoistureContent.fromString(token.value)) |moisture| {
                             parsed.moisture_content = moisture;
+                            parsed.markSpan(.moisture_content, token.start, token.end);
                         }
                     }
                     i += 1;
@@ -273,6 +1186,7 @@ pub const Parser = struct {
                     if (parsed.plasticity_index == null) {
                         if (types.PlasticityIndex.fromString(token.value)) |plasticity| {
                             parsed.plasticity_index = plasticity;
+                            parsed.markSpan(.plasticity_index, token.start, token.end);
                         }
                     }
                     i += 1;
@@ -281,19 +1195,25 @@ pub const Parser = struct {
                     if (parsed.particle_size == null) {
                         if (types.ParticleSize.fromString(token.value)) |particle_size| {
                             parsed.particle_size = particle_size;
+                            parsed.markSpan(.particle_size, token.start, token.end);
                         }
                     }
                     i += 1;
                 },
                 .word => {
+                    if (parsed.primary_soil_type != null and std.ascii.eqlIgnoreCase(token.value, "with")) {
+                        in_with_clause = true;
+                    }
                     // Check if it's an uppercase soil or rock type we missed
                     if (parsed.material_type == .soil and parsed.primary_soil_type == null) {
                         if (SoilType.fromString(token.value)) |soil_type| {
                             parsed.primary_soil_type = soil_type;
+                            parsed.markSpan(.primary_soil_type, token.start, token.end);
                         }
                     } else if (parsed.material_type == .rock and parsed.primary_rock_type == null) {
                         if (RockType.fromString(token.value)) |rock_type| {
                             parsed.primary_rock_type = rock_type;
+                            parsed.markSpan(.primary_rock_type, token.start, token.end);
                         }
                     }
                     i += 1;
@@ -315,6 +1235,10 @@ pub const Parser = struct {
             parsed.rock_strength,
             parsed.primary_soil_type,
         );
+        if (self.adjust_for_groundwater) StrengthDatabase.adjustForGroundwater(&parsed);
+        if (parsed.strength_parameters) |params| {
+            parsed.boundary_advisory = StrengthDatabase.boundaryAdvisory(parsed.material_type, params);
+        }
 
         // Lookup constituent guidance for soil materials
         if (parsed.material_type == .soil) {
@@ -328,6 +1252,16 @@ pub const Parser = struct {
         return parsed;
     }
 
+    /// True when the text between two tokens closes a sentence: a ";" or a
+    /// full stop that is not a decimal point
+    fn endsSentence(gap: []const u8) bool {
+        for (gap, 0..) |c, i| {
+            if (c == ';') return true;
+            if (c == '.' and (i + 1 == gap.len or std.ascii.isWhitespace(gap[i + 1]))) return true;
+        }
+        return false;
+    }
+
     const SecondaryConstituentResult = struct {
         constituent: SecondaryConstituent,
         tokens_consumed: usize,
@@ -363,6 +1297,13 @@ pub const Parser = struct {
         };
     }
 
+    /// The token tied to tokens[index] by a hyphen or slash, if it has the given type
+    fn joinedNext(tokens: []const Token, index: usize, token_type: TokenType) ?Token {
+        if (tokens[index].joiner == null or index + 1 >= tokens.len) return null;
+        const next = tokens[index + 1];
+        return if (next.type == token_type) next else null;
+    }
+
     fn parseStandaloneSecondaryConstituent(_: *Parser, token_value: []const u8) ?SecondaryConstituent {
         var lower_buf: [32]u8 = undefined;
         if (token_value.len >= lower_buf.len) return null;
@@ -401,31 +1342,83 @@ pub const Parser = struct {
 
     const PreprocessedDescription = struct {
         parse_text: []u8,
+        // Where parse_text starts in the input, for mapping token offsets back
+        offset: usize = 0,
         geological_formation: ?[]u8 = null,
+        formation_span: ?types.Span = null,
+        condition_notes: []types.ConditionNote = &.{},
+        condition_span: ?types.Span = null,
+        alternative_type: ?types.AlternativeType = null,
+        alternative_span: ?types.Span = null,
         is_made_ground: bool = false,
         made_ground_label: ?[]u8 = null,
+        made_ground_span: ?types.Span = null,
+        is_topsoil: bool = false,
+        topsoil_span: ?types.Span = null,
+    };
+
+    /// Condition notes and the alternative name read from brackets
+    const Parentheticals = struct {
+        notes: std.ArrayList(types.ConditionNote),
+        notes_span: ?types.Span = null,
+        alternative_type: ?types.AlternativeType = null,
+        alternative_span: ?types.Span = null,
+
+        fn record(self: *Parentheticals, reading: parenthetical.Reading, span: types.Span) !void {
+            var conditions = reading.conditions.iterator();
+            while (conditions.next()) |condition| {
+                try self.notes.append(.{ .condition = condition, .tentative = reading.tentative });
+            }
+            if (reading.conditions.count() > 0) {
+                const first = self.notes_span orelse span;
+                self.notes_span = .{ .start = @min(first.start, span.start), .end = @max(first.end, span.end) };
+            }
+            if (self.alternative_type == null and reading.alternative != null) {
+                self.alternative_type = reading.alternative;
+                self.alternative_span = span;
+            }
+        }
     };
 
     fn preprocessDescription(self: *Parser, description: []const u8) !PreprocessedDescription {
-        var working = std.mem.trim(u8, description, " \t\r\n");
+        // Trailing sentence punctuation would hide a closing formation bracket
+        var working = std.mem.trimRight(u8, std.mem.trim(u8, description, " \t\r\n"), " \t.;,");
         var is_made_ground = false;
         var made_ground_label: ?[]u8 = null;
+        var made_ground_span: ?types.Span = null;
 
-        if (startsWithIgnoreCase(working, "MADE GROUND")) {
+        for ([_][]const u8{ "MADE GROUND", "FILL" }) |label| {
+            if (!startsWithIgnoreCase(working, label)) continue;
             is_made_ground = true;
-            made_ground_label = try self.allocator.dupe(u8, "MADE GROUND");
-            working = std.mem.trimLeft(u8, working["MADE GROUND".len..], " :-\t");
-        } else if (startsWithIgnoreCase(working, "FILL")) {
-            is_made_ground = true;
-            made_ground_label = try self.allocator.dupe(u8, "FILL");
-            working = std.mem.trimLeft(u8, working["FILL".len..], " :-\t");
-        } else if (startsWithIgnoreCase(working, "TOPSOIL")) {
-            is_made_ground = true;
-            made_ground_label = try self.allocator.dupe(u8, "TOPSOIL");
+            made_ground_label = try self.allocator.dupe(u8, label);
+            const start = offsetIn(description, working);
+            made_ground_span = .{ .start = start, .end = start + label.len };
+            working = std.mem.trimLeft(u8, working[label.len..], " :-\t");
+            break;
+        }
+
+        // Topsoil is natural ground, labelled like made ground ("TOPSOIL:
+        // soft dark brown sandy clay") or named as the material ("dark brown
+        // sandy TOPSOIL with rootlets"). Topsoil mentioned in passing
+        // ("CLAY below topsoil") does not count.
+        var is_topsoil = false;
+        var topsoil_span: ?types.Span = null;
+        if (startsWithIgnoreCase(working, "TOPSOIL")) {
+            is_topsoil = true;
+            const start = offsetIn(description, working);
+            topsoil_span = .{ .start = start, .end = start + "TOPSOIL".len };
             working = std.mem.trimLeft(u8, working["TOPSOIL".len..], " :-\t");
+        } else if (principalTopsoil(working)) |pos| {
+            is_topsoil = true;
+            const start = offsetIn(description, working) + pos;
+            topsoil_span = .{ .start = start, .end = start + "topsoil".len };
         }
 
         var geological_formation: ?[]u8 = null;
+        errdefer if (geological_formation) |formation| self.allocator.free(formation);
+        var formation_span: ?types.Span = null;
+        var brackets = Parentheticals{ .notes = std.ArrayList(types.ConditionNote).init(self.allocator) };
+        errdefer brackets.notes.deinit();
         if (working.len > 2 and working[working.len - 1] == ')') {
             var depth: usize = 0;
             var start_idx: ?usize = null;
@@ -445,22 +1438,111 @@ pub const Parser = struct {
             }
 
             if (start_idx) |start| {
-                const formation = std.mem.trim(u8, working[start + 1 .. working.len - 1], " \t");
-                if (formation.len > 0) {
-                    geological_formation = try self.allocator.dupe(u8, formation);
+                const group = std.mem.trim(u8, working[start + 1 .. working.len - 1], " \t");
+                if (group.len > 0) {
+                    const group_start = offsetIn(description, group);
+                    const span = types.Span{ .start = group_start, .end = group_start + group.len };
+                    const reading = parenthetical.read(group);
+                    if (reading.isDeposit()) {
+                        geological_formation = try self.allocator.dupe(u8, group);
+                        formation_span = span;
+                    } else {
+                        try brackets.record(reading, span);
+                    }
                     working = std.mem.trim(u8, working[0..start], " \t");
                 }
             }
         }
 
+        const parse_text = try self.allocator.dupe(u8, working);
+        errdefer self.allocator.free(parse_text);
+        const offset = offsetIn(description, working);
+
+        // Brackets within the description are blanked once read, so "(or
+        // SILT)" is not parsed as a second soil; unrecognised groups stay
+        var pos: usize = 0;
+        while (std.mem.indexOfScalarPos(u8, parse_text, pos, '(')) |open| {
+            const close = std.mem.indexOfScalarPos(u8, parse_text, open + 1, ')') orelse break;
+            pos = close + 1;
+            const group = std.mem.trim(u8, parse_text[open + 1 .. close], " \t");
+            if (group.len == 0) continue;
+            // A share of an interbedded stratum ("SANDSTONE (60%)"), read later
+            if (std.mem.endsWith(u8, group, "%")) continue;
+
+            const group_start = offset + offsetIn(parse_text, group);
+            const span = types.Span{ .start = group_start, .end = group_start + group.len };
+            const reading = parenthetical.read(group);
+            if (reading.isDeposit()) {
+                if (geological_formation != null or !parenthetical.isUpperCase(group)) continue;
+                geological_formation = try self.allocator.dupe(u8, group);
+                formation_span = span;
+            } else {
+                try brackets.record(reading, span);
+            }
+            @memset(parse_text[open .. close + 1], ' ');
+        }
+
+        // Unit names outside brackets ("Firm CLAY. LONDON CLAY FORMATION")
+        // are blanked too, so their CLAY is not read as the soil, unless it
+        // is the only lithology given ("Weak white CHALK GROUP")
+        if (stratigraphy.find(parse_text)) |match| {
+            if (geological_formation == null) {
+                geological_formation = try self.allocator.dupe(u8, match.name);
+                formation_span = .{ .start = offset + match.start, .end = offset + match.end };
+            }
+            @memset(parse_text[stratigraphy.blankFrom(parse_text, match)..match.end], ' ');
+        }
+
         return PreprocessedDescription{
-            .parse_text = try self.allocator.dupe(u8, working),
+            .parse_text = parse_text,
+            .offset = offset,
             .geological_formation = geological_formation,
+            .formation_span = formation_span,
+            .condition_notes = try brackets.notes.toOwnedSlice(),
+            .condition_span = brackets.notes_span,
+            .alternative_type = brackets.alternative_type,
+            .alternative_span = brackets.alternative_span,
             .is_made_ground = is_made_ground,
             .made_ground_label = made_ground_label,
+            .made_ground_span = made_ground_span,
+            .is_topsoil = is_topsoil,
+            .topsoil_span = topsoil_span,
         };
     }
 
+    /// Offset of "topsoil" as the principal noun: the last word of the
+    /// first clause, not the object of a preposition. The clause ends at
+    /// punctuation or a "with" or "containing" clause.
+    fn principalTopsoil(text: []const u8) ?usize {
+        const prepositions = [_][]const u8{ "of", "below", "beneath", "under", "underlying", "above", "over", "overlying", "on", "into", "from", "and", "or" };
+        var previous: []const u8 = "";
+        var pos: usize = 0;
+        while (scan.next(text, pos, .letters)) |word| {
+            pos = word.end;
+            if (isClauseStart(word.text)) return null;
+            if (std.ascii.eqlIgnoreCase(word.text, "topsoil")) {
+                for (prepositions) |preposition| {
+                    if (std.ascii.eqlIgnoreCase(previous, preposition)) return null;
+                }
+                if (word.endsPhrase()) return word.start;
+                const after = scan.next(text, word.end, .letters) orelse return word.start;
+                return if (isClauseStart(after.text)) word.start else null;
+            }
+            if (word.endsPhrase()) return null;
+            previous = word.text;
+        }
+        return null;
+    }
+
+    fn isClauseStart(word: []const u8) bool {
+        return std.ascii.eqlIgnoreCase(word, "with") or std.ascii.eqlIgnoreCase(word, "containing");
+    }
+
+    /// Byte offset of `slice` within `text`, which must contain it
+    fn offsetIn(text: []const u8, slice: []const u8) usize {
+        return @intFromPtr(slice.ptr) - @intFromPtr(text.ptr);
+    }
+
     fn startsWithIgnoreCase(haystack: []const u8, prefix: []const u8) bool {
         if (haystack.len < prefix.len) return false;
         return std.ascii.eqlIgnoreCase(haystack[0..prefix.len], prefix);
@@ -743,6 +1825,454 @@ test "parse compound primary soil types" {
     try std.testing.expect(result.secondary_primary_soil_type.? == .gravel);
 }
 
+test "fallback parser replaces low confidence result" {
+    const Stub = struct {
+        fn parseFn(_: *anyopaque, allocator: std.mem.Allocator, description: []const u8) anyerror!?SoilDescription {
+            return SoilDescription{
+                .raw_description = try allocator.dupe(u8, description),
+                .material_type = .soil,
+                .primary_soil_type = .silt,
+                .consistency = .soft,
+                .confidence = 0.95,
+            };
+        }
+    };
+
+    const allocator = std.testing.allocator;
+    var dummy: u8 = 0;
+    const stub = FallbackParser{ .ptr = &dummy, .vtable = &.{ .parse = Stub.parseFn } };
+    var parser = Parser.initWithFallback(allocator, stub, 0.8);
+
+    // Dense CLAY is invalid and low confidence, so the fallback wins
+    const weak = try parser.parse("Dense CLAY");
+    defer weak.deinit(allocator);
+    try std.testing.expect(weak.primary_soil_type.? == .silt);
+    try std.testing.expect(weak.sourceOf(.primary_soil_type).? == .fallback);
+
+    // Firm CLAY is fully confident, so the fallback is never used
+    const strong = try parser.parse("Firm CLAY");
+    defer strong.deinit(allocator);
+    try std.testing.expect(strong.primary_soil_type.? == .clay);
+}
+
+test "ocr mode repairs scanned text" {
+    const allocator = std.testing.allocator;
+    var parser = Parser.initOcrMode(allocator);
+
+    const result = try parser.parse("FirrnC1AY");
+    defer result.deinit(allocator);
+
+    try std.testing.expect(result.consistency.? == .firm);
+    try std.testing.expect(result.primary_soil_type.? == .clay);
+    try std.testing.expectEqualStrings("FirrnC1AY", result.raw_description);
+    try std.testing.expect(result.spelling_corrections.len >= 2);
+}
+
+test "parse dictated description" {
+    const allocator = std.testing.allocator;
+    var parser = Parser.init(allocator);
+
+    const result = try parser.parseDictation("stiff clay very sandy grey");
+    defer result.deinit(allocator);
+
+    try std.testing.expectEqualStrings("Stiff grey very sandy CLAY", result.raw_description);
+    try std.testing.expect(result.consistency.? == .stiff);
+    try std.testing.expect(result.primary_soil_type.? == .clay);
+    try std.testing.expect(result.color.? == .grey);
+}
+
+test "parse with project profile vocabulary" {
+    const allocator = std.testing.allocator;
+
+    const text =
+        \\[validation]
+        \\suppress = ["W002"]
+        \\
+        \\[vocabulary.soil_types]
+        \\"boulder clay" = "clay"
+    ;
+
+    var project_profile = try parseProfile(allocator, text, .toml);
+    defer project_profile.deinit();
+
+    var parser = Parser.init(allocator);
+    try parser.applyProfile(&project_profile);
+
+    const result = try parser.parse("Brown boulder clay");
+    defer result.deinit(allocator);
+
+    try std.testing.expectEqualStrings("Brown boulder clay", result.raw_description);
+    try std.testing.expect(result.primary_soil_type.? == .clay);
+    try std.testing.expectEqual(@as(usize, 0), result.warnings.len);
+}
+
+test "profile selects dialect, fuzzy matching and confidence threshold" {
+    const allocator = std.testing.allocator;
+
+    const text =
+        \\dialect = "us"
+        \\
+        \\[parser]
+        \\fuzzy_matching = false
+        \\fuzzy_threshold = 0.9
+        \\min_confidence = 0.7
+    ;
+
+    var project_profile = try parseProfile(allocator, text, .toml);
+    defer project_profile.deinit();
+
+    var parser = Parser.init(allocator);
+    try parser.applyProfile(&project_profile);
+
+    try std.testing.expectEqualStrings("us", parser.dialect.?.name);
+    try std.testing.expect(!parser.fuzzy_matching);
+    try std.testing.expectEqual(@as(f32, 0.9), parser.fuzzy_threshold);
+    try std.testing.expectEqual(@as(f32, 0.7), parser.createValidator().low_confidence_threshold);
+
+    var unknown = try parseProfile(allocator, "dialect = \"klingon\"", .toml);
+    defer unknown.deinit();
+    try std.testing.expectError(error.UnknownDialect, parser.applyProfile(&unknown));
+}
+
+test "batch validation report" {
+    const allocator = std.testing.allocator;
+    var parser = Parser.init(allocator);
+
+    var output = std.ArrayList(u8).init(allocator);
+    defer output.deinit();
+
+    const descriptions = [_][]const u8{ "Firm CLAY", "Firm SAND", "Dense SAND" };
+    const summary = try parser.validateBatchReport(&descriptions, output.writer(), .csv);
+
+    try std.testing.expectEqual(@as(usize, 3), summary.total);
+    try std.testing.expectEqual(@as(usize, 2), summary.valid);
+    try std.testing.expect(std.mem.indexOf(u8, output.items, "2,Firm SAND,false,") != null);
+}
+
+test "capitalisation policy warns on lower case primary type" {
+    const allocator = std.testing.allocator;
+    var parser = Parser.init(allocator);
+    parser.capitalization_policy = .required;
+
+    const result = try parser.parse("Firm clay");
+    defer result.deinit(allocator);
+
+    try std.testing.expect(result.primary_soil_type.? == .clay);
+    try std.testing.expectEqual(@as(usize, 1), result.warnings.len);
+}
+
+test "parse glacial till with inclusions and deposit name" {
+    const allocator = std.testing.allocator;
+    var parser = Parser.init(allocator);
+
+    const result = try parser.parse("firm to stiff slightly sandy slightly gravelly CLAY with occasional cobbles and rare boulders (GLACIAL TILL)");
+    defer result.deinit(allocator);
+
+    try std.testing.expect(result.consistency.? == .firm_to_stiff);
+    try std.testing.expect(result.primary_soil_type.? == .clay);
+    try std.testing.expect(result.secondary_primary_soil_type == null);
+    try std.testing.expectEqual(@as(usize, 2), result.secondary_constituents.len);
+    try std.testing.expectEqual(@as(usize, 2), result.inclusions.len);
+    try std.testing.expect(result.inclusions[0].frequency.? == .occasional);
+    try std.testing.expect(result.inclusions[0].soil_type == .cobbles);
+    try std.testing.expect(result.inclusions[1].frequency.? == .rare);
+    try std.testing.expect(result.inclusions[1].soil_type == .boulders);
+    try std.testing.expectEqualStrings("GLACIAL TILL", result.geological_formation.?);
+    try std.testing.expect(result.is_valid);
+    try std.testing.expect(result.confidence == 1.0);
+}
+
+test "parse described inclusions in layered alluvium" {
+    const allocator = std.testing.allocator;
+    var parser = Parser.init(allocator);
+
+    const result = try parser.parse("Soft brown CLAY with pockets of soft grey silt and thin laminae of fine sand (ALLUVIUM)");
+    defer result.deinit(allocator);
+
+    try std.testing.expectEqual(@as(usize, 2), result.inclusions.len);
+    try std.testing.expect(result.inclusions[0].form == .pockets);
+    try std.testing.expect(result.inclusions[0].consistency.? == .soft);
+    try std.testing.expect(result.inclusions[0].color.? == .grey);
+    try std.testing.expect(result.inclusions[0].soil_type == .silt);
+    try std.testing.expect(result.inclusions[1].form == .laminae);
+    try std.testing.expect(result.inclusions[1].thickness.? == .thin);
+    try std.testing.expect(result.inclusions[1].particle_size.? == .fine);
+    try std.testing.expect(result.inclusions[1].soil_type == .sand);
+
+    const json = try result.toJson(allocator);
+    defer allocator.free(json);
+    try std.testing.expect(std.mem.indexOf(u8, json, "{\"form\":\"pockets\",\"consistency\":\"soft\",\"color\":\"grey\",\"soil_type\":\"silt\"}") != null);
+    const restored = try GeologicalDescription.fromJson(json, allocator);
+    defer restored.deinit(allocator);
+    try std.testing.expectEqualSlices(types.Inclusion, result.inclusions, restored.inclusions);
+}
+
+test "parse interbedded composite descriptions" {
+    const allocator = std.testing.allocator;
+    var parser = Parser.init(allocator);
+
+    const result = try parser.parse("Interbedded thinly bedded SANDSTONE (60%) and MUDSTONE");
+    defer result.deinit(allocator);
+
+    try std.testing.expect(result.primary_rock_type.? == .sandstone);
+    const composite_result = result.composite.?;
+    try std.testing.expect(composite_result.arrangement == .interbedded);
+    try std.testing.expect(composite_result.thickness.? == .thin);
+    try std.testing.expect(composite_result.proportions_stated);
+    try std.testing.expectEqual(@as(usize, 2), composite_result.components.len);
+    try std.testing.expect(composite_result.components[1].rock_type.? == .mudstone);
+    try std.testing.expectEqual(@as(u8, 40), composite_result.components[1].percent);
+
+    const json = try result.toJson(allocator);
+    defer allocator.free(json);
+    try std.testing.expect(std.mem.indexOf(u8, json, "\"composite\":{\"arrangement\":\"interbedded\",\"thickness\":\"thin\",\"components\":[{\"rock_type\":\"sandstone\",\"percent\":60},{\"rock_type\":\"mudstone\",\"percent\":40}],\"proportions_stated\":true}") != null);
+    const restored = try GeologicalDescription.fromJson(json, allocator);
+    defer restored.deinit(allocator);
+    try std.testing.expectEqualSlices(types.CompositeComponent, composite_result.components, restored.composite.?.components);
+}
+
+test "advise on descriptions at the soil/rock boundary" {
+    const allocator = std.testing.allocator;
+    var parser = Parser.init(allocator);
+
+    const clay = try parser.parse("Hard grey CLAY");
+    defer clay.deinit(allocator);
+    const soil_side = clay.boundary_advisory.?;
+    try std.testing.expectEqual(MaterialType.soil, soil_side.described_as);
+    try std.testing.expectApproxEqAbs(@as(f32, 400), soil_side.as_soil.upper_bound, 0.001);
+    try std.testing.expectApproxEqAbs(@as(f32, 0.8), soil_side.as_rock.upper_bound, 0.001);
+    try std.testing.expectEqual(Source.inferred, clay.sourceOf(.boundary_advisory).?);
+
+    const mudstone = try parser.parse("Extremely weak grey MUDSTONE");
+    defer mudstone.deinit(allocator);
+    try std.testing.expect(mudstone.rock_strength.? == .very_weak);
+    try std.testing.expectEqual(MaterialType.rock, mudstone.boundary_advisory.?.described_as);
+
+    const json = try mudstone.toJson(allocator);
+    defer allocator.free(json);
+    try std.testing.expect(std.mem.indexOf(u8, json, "\"boundary_advisory\":{\"described_as\":\"rock\"") != null);
+
+    const firm = try parser.parse("Firm grey CLAY");
+    defer firm.deinit(allocator);
+    try std.testing.expect(firm.boundary_advisory == null);
+}
+
+test "parse discontinuity spacing" {
+    const allocator = std.testing.allocator;
+    var parser = Parser.init(allocator);
+
+    const description = "Strong grey SANDSTONE with medium spaced joints";
+    const rock = try parser.parse(description);
+    defer rock.deinit(allocator);
+
+    const spacing = rock.discontinuity_spacing.?;
+    try std.testing.expectEqual(SpacingTerm.medium, spacing.term.?);
+    try std.testing.expectEqual(@as(u32, 200), spacing.min_mm);
+    try std.testing.expectEqual(@as(u32, 600), spacing.max_mm.?);
+    try std.testing.expect(rock.particle_size == null);
+    try std.testing.expect(rock.primary_rock_type.? == .sandstone);
+    const span = rock.spans.get(.discontinuity_spacing).?;
+    try std.testing.expectEqualStrings("medium spaced", description[span.start..span.end]);
+
+    const clay = try parser.parse("Stiff fissured grey CLAY, fissures spaced 20-50 mm");
+    defer clay.deinit(allocator);
+    try std.testing.expectEqual(SpacingTerm.very_close, clay.discontinuity_spacing.?.term.?);
+    try std.testing.expectEqual(@as(u32, 50), clay.discontinuity_spacing.?.max_mm.?);
+
+    const json = try clay.toJson(allocator);
+    defer allocator.free(json);
+    const restored = try GeologicalDescription.fromJson(json, allocator);
+    defer restored.deinit(allocator);
+    try std.testing.expectEqual(clay.discontinuity_spacing.?, restored.discontinuity_spacing.?);
+}
+
+test "cite the source of derived strength" {
+    const allocator = std.testing.allocator;
+    var parser = Parser.init(allocator);
+
+    const result = try parser.parse("Stiff brown CLAY");
+    defer result.deinit(allocator);
+
+    const cited = try citations(allocator, result);
+    defer allocator.free(cited);
+    try std.testing.expect(cited.len > 0);
+    try std.testing.expectEqual(Field.strength_parameters, cited[0].field);
+    try std.testing.expectEqualStrings("BS 5930:2015", cited[0].standard);
+    try std.testing.expectEqualStrings("§6.3.2.2", cited[0].clause.?);
+    try std.testing.expectEqualStrings("Table 13", cited[0].table.?);
+}
+
+test "parse formation names outside brackets" {
+    const allocator = std.testing.allocator;
+    var parser = Parser.init(allocator);
+
+    const description = "Stiff fissured grey CLAY. LONDON CLAY FORMATION";
+    const result = try parser.parse(description);
+    defer result.deinit(allocator);
+
+    try std.testing.expectEqualStrings("LONDON CLAY FORMATION", result.geological_formation.?);
+    const span = result.spans.get(.geological_formation).?;
+    try std.testing.expectEqualStrings("LONDON CLAY FORMATION", description[span.start..span.end]);
+    try std.testing.expect(result.primary_soil_type.? == .clay);
+    try std.testing.expect(result.secondary_primary_soil_type == null);
+    try std.testing.expectEqual(StratigraphicRank.formation, stratigraphicRankOf(result.geological_formation.?).?);
+
+    const group = try parser.parse("Red MUDSTONE (MERCIA MUDSTONE GROUP)");
+    defer group.deinit(allocator);
+    try std.testing.expectEqualStrings("MERCIA MUDSTONE GROUP", group.geological_formation.?);
+
+    // The unit name gives the only rock type, so it is kept
+    const chalk = try parser.parse("Weak white CHALK GROUP");
+    defer chalk.deinit(allocator);
+    try std.testing.expectEqualStrings("CHALK GROUP", chalk.geological_formation.?);
+    try std.testing.expect(chalk.material_type == .rock);
+    try std.testing.expect(chalk.primary_rock_type.? == .chalk);
+}
+
+test "parse bracketed notes and alternative names" {
+    const allocator = std.testing.allocator;
+    var parser = Parser.init(allocator);
+
+    const result = try parser.parse("Firm brown CLAY (possibly reworked) with rare gravel (GLACIAL TILL)");
+    defer result.deinit(allocator);
+
+    try std.testing.expect(result.primary_soil_type.? == .clay);
+    try std.testing.expectEqualStrings("GLACIAL TILL", result.geological_formation.?);
+    try std.testing.expectEqual(@as(usize, 1), result.condition_notes.len);
+    try std.testing.expect(result.condition_notes[0].condition == .reworked);
+    try std.testing.expect(result.condition_notes[0].tentative);
+    try std.testing.expectEqual(@as(usize, 1), result.inclusions.len);
+
+    const recovered = try parser.parse("Extremely weak MUDSTONE (recovered as CLAY)");
+    defer recovered.deinit(allocator);
+
+    try std.testing.expect(recovered.material_type == .rock);
+    try std.testing.expect(recovered.geological_formation == null);
+    try std.testing.expect(recovered.condition_notes[0].condition == .non_intact);
+    try std.testing.expect(recovered.alternative_type.?.soil == .clay);
+}
+
+test "parse partings in rock" {
+    const allocator = std.testing.allocator;
+    var parser = Parser.init(allocator);
+
+    const result = try parser.parse("Strong grey LIMESTONE with thin clay partings");
+    defer result.deinit(allocator);
+
+    try std.testing.expect(result.material_type == .rock);
+    try std.testing.expectEqual(@as(usize, 1), result.inclusions.len);
+    try std.testing.expect(result.inclusions[0].thickness.? == .thin);
+    try std.testing.expect(result.inclusions[0].form == .partings);
+    try std.testing.expect(result.inclusions[0].soil_type == .clay);
+}
+
+test "parse structured colour" {
+    const allocator = std.testing.allocator;
+    var parser = Parser.init(allocator);
+
+    const result = try parser.parse("Firm dark brownish grey mottled orange slightly sandy CLAY with pockets of white silt");
+    defer result.deinit(allocator);
+
+    const colour = result.colour_detail.?;
+    try std.testing.expect(colour.shade.? == .dark);
+    try std.testing.expect(colour.tint.? == .brown);
+    try std.testing.expect(colour.hue == .grey);
+    try std.testing.expect(colour.mottling.?.hue == .orange);
+    try std.testing.expect(result.spans.get(.colour_detail) != null);
+
+    const json = try result.toJson(allocator);
+    defer allocator.free(json);
+    try std.testing.expect(std.mem.indexOf(u8, json, "\"colour_detail\":{\"hue\":\"grey\",\"shade\":\"dark\",\"tint\":\"brown\",\"mottling\":{\"hue\":\"orange\"}}") != null);
+
+    const restored = try GeologicalDescription.fromJson(json, allocator);
+    defer restored.deinit(allocator);
+    try std.testing.expect(std.meta.eql(result.colour_detail, restored.colour_detail));
+}
+
+test "parse Munsell colour code" {
+    const allocator = std.testing.allocator;
+    var parser = Parser.init(allocator);
+
+    const result = try parser.parse("Firm brown (10YR 5/3) slightly sandy CLAY (GLACIAL TILL)");
+    defer result.deinit(allocator);
+
+    const code = result.munsell_colour.?;
+    try std.testing.expect(code.hue == .yr);
+    try std.testing.expect(code.isValid());
+    try std.testing.expect(result.colour_detail.?.hue == .brown);
+    try std.testing.expect(result.primary_soil_type.? == .clay);
+    try std.testing.expectEqualStrings("GLACIAL TILL", result.geological_formation.?);
+    try std.testing.expectEqual(@as(usize, 12), result.spans.get(.munsell_colour).?.start);
+
+    const json = try result.toJson(allocator);
+    defer allocator.free(json);
+    try std.testing.expect(std.mem.indexOf(u8, json, "\"munsell_colour\":\"10YR 5/3\"") != null);
+
+    const restored = try GeologicalDescription.fromJson(json, allocator);
+    defer restored.deinit(allocator);
+    try std.testing.expect(std.meta.eql(result.munsell_colour, restored.munsell_colour));
+
+    // A bracketed code alone at the end is not a formation name
+    const trailing = try parser.parse("Stiff grey CLAY (N 5/)");
+    defer trailing.deinit(allocator);
+    try std.testing.expect(trailing.munsell_colour.?.hue == .n);
+    try std.testing.expect(trailing.geological_formation == null);
+
+    const out_of_range = try parser.parse("Firm brown 10YR 12/3 CLAY");
+    defer out_of_range.deinit(allocator);
+    try std.testing.expect(!out_of_range.munsell_colour.?.isValid());
+    try std.testing.expect(out_of_range.warnings.len > 0);
+}
+
+test "parse peat fabric and von Post grade" {
+    const allocator = std.testing.allocator;
+    var parser = Parser.init(allocator);
+
+    const result = try parser.parse("Soft dark brown pseudo-fibrous PEAT (H5)");
+    defer result.deinit(allocator);
+
+    try std.testing.expect(result.primary_soil_type.? == .peat);
+    try std.testing.expect(result.geological_formation == null);
+    const properties = result.soil().?.peat_properties.?;
+    try std.testing.expect(properties.fabric.? == .pseudo_fibrous);
+    try std.testing.expect(properties.humification.? == .h5);
+
+    const json = try result.toJson(allocator);
+    defer allocator.free(json);
+    try std.testing.expect(std.mem.indexOf(u8, json, "\"peat_properties\":{\"fabric\":\"pseudo-fibrous\",\"humification\":\"H5\"}") != null);
+
+    const restored = try GeologicalDescription.fromJson(json, allocator);
+    defer restored.deinit(allocator);
+    try std.testing.expect(std.meta.eql(result.peat_properties, restored.peat_properties));
+
+    const clay = try parser.parse("Firm fibrous CLAY");
+    defer clay.deinit(allocator);
+    try std.testing.expect(clay.peat_properties == null);
+}
+
+test "parse particle shape" {
+    const allocator = std.testing.allocator;
+    var parser = Parser.init(allocator);
+
+    const result = try parser.parse("Medium dense subangular to rounded fine to coarse GRAVEL of flint");
+    defer result.deinit(allocator);
+
+    const shape = result.particle_shape.?;
+    try std.testing.expect(shape.angularity.? == .subangular);
+    try std.testing.expect(shape.angularity_to.? == .rounded);
+    try std.testing.expect(result.particle_size.? == .fine_to_coarse);
+    try std.testing.expect(result.primary_soil_type.? == .gravel);
+    try std.testing.expect(result.soil().?.particle_shape != null);
+
+    const json = try result.toJson(allocator);
+    defer allocator.free(json);
+    try std.testing.expect(std.mem.indexOf(u8, json, "\"particle_shape\":{\"angularity\":\"subangular\",\"angularity_to\":\"rounded\"}") != null);
+
+    const restored = try GeologicalDescription.fromJson(json, allocator);
+    defer restored.deinit(allocator);
+    try std.testing.expect(std.meta.eql(result.particle_shape, restored.particle_shape));
+}
+
 test "parse made ground prefix and density range" {
     const allocator = std.testing.allocator;
     var parser = Parser.init(allocator);
@@ -755,3 +2285,127 @@ test "parse made ground prefix and density range" {
     try std.testing.expect(result.density.? == .medium_dense_to_dense);
     try std.testing.expect(result.primary_soil_type.? == .gravel);
 }
+
+test "parse gives up after its timeout" {
+    const allocator = std.testing.allocator;
+    var parser = Parser.init(allocator);
+
+    parser.timeout_ns = std.time.ns_per_hour;
+    const result = try parser.parse("Firm brown slightly sandy CLAY");
+    defer result.deinit(allocator);
+    try std.testing.expect(result.primary_soil_type.? == .clay);
+
+    // Nothing parses in no time; the testing allocator checks nothing leaks
+    parser.timeout_ns = 0;
+    try std.testing.expectError(error.ParseTimeout, parser.parse("Firm brown slightly sandy CLAY"));
+}
+
+test "parse tertiary with constituents" {
+    const allocator = std.testing.allocator;
+    var parser = Parser.init(allocator);
+
+    const description = "Soft grey CLAY with occasional gravel and rare shell fragments";
+    const result = try parser.parse(description);
+    defer result.deinit(allocator);
+
+    try std.testing.expectEqual(@as(usize, 1), result.inclusions.len);
+    try std.testing.expectEqual(@as(usize, 2), result.tertiary_constituents.len);
+    try std.testing.expect(result.tertiary_constituents[0].frequency.? == .occasional);
+    try std.testing.expectEqualStrings("gravel", result.tertiary_constituents[0].name);
+    try std.testing.expectEqual(SoilType.gravel, result.tertiary_constituents[0].soil_type.?);
+    try std.testing.expect(result.tertiary_constituents[1].frequency.? == .rare);
+    try std.testing.expectEqualStrings("shell fragments", result.tertiary_constituents[1].name);
+    try std.testing.expect(result.tertiary_constituents[1].soil_type == null);
+    const span = result.spans.get(.tertiary_constituents).?;
+    try std.testing.expectEqualStrings("occasional gravel and rare shell fragments", description[span.start..span.end]);
+
+    const json = try result.toJson(allocator);
+    defer allocator.free(json);
+    try std.testing.expect(std.mem.indexOf(u8, json, "\"tertiary_constituents\":[{\"frequency\":\"occasional\",\"name\":\"gravel\",\"soil_type\":\"gravel\"},{\"frequency\":\"rare\",\"name\":\"shell fragments\"}]") != null);
+    const restored = try GeologicalDescription.fromJson(json, allocator);
+    defer restored.deinit(allocator);
+    try std.testing.expectEqual(SoilType.gravel, restored.tertiary_constituents[0].soil_type.?);
+    try std.testing.expectEqualStrings("shell fragments", restored.tertiary_constituents[1].name);
+
+    // The gravel is written once, as an inclusion
+    const generated = try generator.generate(result, allocator);
+    defer allocator.free(generated);
+    try std.testing.expectEqual(@as(usize, 1), std.mem.count(u8, generated, "gravel"));
+}
+
+test "parse topsoil as natural ground" {
+    const allocator = std.testing.allocator;
+    var parser = Parser.init(allocator);
+
+    const description = "TOPSOIL: soft dark brown slightly sandy clay with rootlets";
+    const result = try parser.parse(description);
+    defer result.deinit(allocator);
+
+    try std.testing.expect(result.is_topsoil);
+    try std.testing.expect(!result.is_made_ground);
+    try std.testing.expect(result.made_ground_label == null);
+    try std.testing.expect(result.consistency.? == .soft);
+    const span = result.spans.get(.topsoil).?;
+    try std.testing.expectEqualStrings("TOPSOIL", description[span.start..span.end]);
+
+    const json = try result.toJson(allocator);
+    defer allocator.free(json);
+    try std.testing.expect(std.mem.indexOf(u8, json, "\"is_topsoil\":true") != null);
+    const restored = try GeologicalDescription.fromJson(json, allocator);
+    defer restored.deinit(allocator);
+    try std.testing.expect(restored.is_topsoil);
+
+    const named = try parser.parse("Dark brown sandy TOPSOIL with rootlets");
+    defer named.deinit(allocator);
+    try std.testing.expect(named.is_topsoil);
+    try std.testing.expect(named.confidence > 0.5);
+
+    // Topsoil mentioned in passing is not the stratum
+    const pockets = try parser.parse("Firm CLAY with pockets of reworked topsoil");
+    defer pockets.deinit(allocator);
+    try std.testing.expect(!pockets.is_topsoil);
+    try std.testing.expect(pockets.primary_soil_type.? == .clay);
+    const below = try parser.parse("Firm brown CLAY below topsoil");
+    defer below.deinit(allocator);
+    try std.testing.expect(!below.is_topsoil);
+}
+
+test "parse made ground constituents" {
+    const allocator = std.testing.allocator;
+    var parser = Parser.init(allocator);
+
+    const description = "MADE GROUND: firm brown sandy CLAY with occasional brick and concrete fragments";
+    const result = try parser.parse(description);
+    defer result.deinit(allocator);
+
+    try std.testing.expect(result.is_made_ground);
+    try std.testing.expect(result.primary_soil_type.? == .clay);
+    try std.testing.expectEqual(@as(usize, 2), result.made_ground_constituents.len);
+    try std.testing.expect(result.made_ground_constituents[0].material == .brick);
+    try std.testing.expect(result.made_ground_constituents[0].frequency.? == .occasional);
+    try std.testing.expect(result.made_ground_constituents[1].material == .concrete);
+    const span = result.spans.get(.made_ground_constituents).?;
+    try std.testing.expectEqualStrings("brick and concrete", description[span.start..span.end]);
+
+    const json = try result.toJson(allocator);
+    defer allocator.free(json);
+    try std.testing.expect(std.mem.indexOf(u8, json, "\"made_ground_constituents\":[{\"frequency\":\"occasional\",\"material\":\"brick\"},{\"frequency\":\"occasional\",\"material\":\"concrete\"}]") != null);
+    const restored = try GeologicalDescription.fromJson(json, allocator);
+    defer restored.deinit(allocator);
+    try std.testing.expectEqual(@as(usize, 2), restored.made_ground_constituents.len);
+
+    // No label, but brick is not natural
+    const unlabelled = try parser.parse("Soft grey CLAY with rare brick");
+    defer unlabelled.deinit(allocator);
+    try std.testing.expect(unlabelled.is_made_ground);
+    try std.testing.expect(unlabelled.made_ground_label == null);
+
+    // Rubble and tile can be natural or agricultural on their own
+    const chalk = try parser.parse("Structureless CHALK composed of white chalk rubble");
+    defer chalk.deinit(allocator);
+    try std.testing.expect(!chalk.is_made_ground);
+    const drained = try parser.parse("TOPSOIL: dark brown sandy clay with rootlets and rare tile drain fragments");
+    defer drained.deinit(allocator);
+    try std.testing.expect(!drained.is_made_ground);
+    try std.testing.expect(drained.is_topsoil);
+}
@@ -0,0 +1,159 @@
+const std = @import("std");
+const types = @import("types.zig");
+const bs5930 = @import("bs5930.zig");
+
+const GeologicalDescription = types.GeologicalDescription;
+const Parser = bs5930.Parser;
+const Sha256 = std.crypto.hash.sha2.Sha256;
+
+/// Hex SHA-256 of a canonical form
+pub const Hash = [Sha256.digest_length * 2]u8;
+
+/// The meaning of a parsed description as "key=value" lines in a fixed order.
+/// Wording, case, word order within the description, "gray"/"grey" and
+/// derived values (strength ranges, confidence, warnings) do not affect it.
+/// Caller owns the result.
+pub fn canonicalForm(allocator: std.mem.Allocator, desc: GeologicalDescription) ![]u8 {
+    var out = std.ArrayList(u8).init(allocator);
+    errdefer out.deinit();
+    const writer = out.writer();
+
+    try writer.print("material_type={s}\n", .{desc.material_type.toString()});
+    if (desc.is_made_ground) try writer.writeAll("made_ground=true\n");
+    if (desc.is_topsoil) try writer.writeAll("topsoil=true\n");
+    if (desc.material_origin) |value| try writer.print("material_origin={s}\n", .{@tagName(value)});
+    if (desc.consistency) |value| try writer.print("consistency={s}\n", .{@tagName(value)});
+    if (desc.density) |value| try writer.print("density={s}\n", .{@tagName(value)});
+    if (desc.primary_soil_type) |value| try writer.print("primary_soil_type={s}\n", .{@tagName(value)});
+    if (desc.secondary_primary_soil_type) |value| try writer.print("secondary_primary_soil_type={s}\n", .{@tagName(value)});
+    if (desc.rock_strength) |value| try writer.print("rock_strength={s}\n", .{@tagName(value)});
+    if (desc.weathering_grade) |value| try writer.print("weathering_grade={s}\n", .{@tagName(value)});
+    if (desc.rock_structure) |value| try writer.print("rock_structure={s}\n", .{@tagName(value)});
+    if (desc.discontinuity_spacing) |value| try writer.print("discontinuity_spacing={}\n", .{value});
+    if (desc.primary_rock_type) |value| try writer.print("primary_rock_type={s}\n", .{@tagName(value)});
+    if (desc.karst_grade) |value| try writer.print("karst_grade={s}\n", .{@tagName(value)});
+    if (desc.composite) |value| {
+        try writer.print("composite={}\n", .{value});
+        if (value.thickness) |thickness| try writer.print("composite_thickness={s}\n", .{thickness.toString()});
+    }
+    if (desc.color) |value| try writer.print("color={s}\n", .{colorName(value)});
+    if (desc.secondary_color) |value| try writer.print("secondary_color={s}\n", .{colorName(value)});
+    if (desc.colour_detail) |value| try writer.print("colour_detail={}\n", .{value});
+    if (desc.munsell_colour) |value| try writer.print("munsell_colour={}\n", .{value});
+    if (desc.moisture_content) |value| try writer.print("moisture_content={s}\n", .{@tagName(value)});
+    if (desc.plasticity_index) |value| try writer.print("plasticity_index={s}\n", .{@tagName(value)});
+    if (desc.particle_size) |value| try writer.print("particle_size={s}\n", .{@tagName(value)});
+    if (desc.particle_shape) |value| try writer.print("particle_shape={}\n", .{value});
+    if (desc.sensitivity) |value| try writer.print("sensitivity={s}\n", .{@tagName(value)});
+    if (desc.peat_properties) |value| try writer.print("peat_properties={}\n", .{value});
+    var indicators = desc.marine_indicators.iterator();
+    while (indicators.next()) |indicator| try writer.print("marine_indicator={s}\n", .{@tagName(indicator)});
+    if (desc.fossil_frequency) |value| try writer.print("fossil_frequency={s}\n", .{@tagName(value)});
+    if (desc.gas_indicators) |gas| {
+        if (gas.gas_blisters) try writer.writeAll("gas_indicator=gas_blisters\n");
+        if (gas.expanded_core) try writer.writeAll("gas_indicator=expanded_core\n");
+        if (gas.gas_voids) try writer.writeAll("gas_indicator=gas_voids\n");
+        if (gas.hydrate_dissociation) try writer.writeAll("gas_indicator=hydrate_dissociation\n");
+    }
+    if (desc.geological_formation) |formation| try writeLowerLine(writer, "geological_formation", formation);
+    if (desc.alternative_type) |alternative| try writeLowerLine(writer, "alternative_type", alternative.toString());
+    for (desc.condition_notes) |note| try writer.print("condition={}\n", .{note});
+
+    // "slightly sandy slightly gravelly" means the same as the reverse
+    const constituents = try allocator.alloc([]u8, desc.secondary_constituents.len);
+    var filled: usize = 0;
+    defer {
+        for (constituents[0..filled]) |item| allocator.free(item);
+        allocator.free(constituents);
+    }
+    for (desc.secondary_constituents) |sc| {
+        const item = try std.fmt.allocPrint(allocator, "{s} {s}", .{ sc.amount, sc.soil_type });
+        constituents[filled] = std.ascii.lowerString(item, item);
+        filled += 1;
+    }
+    std.mem.sort([]u8, constituents, {}, stringLessThan);
+    for (constituents) |item| try writer.print("constituent={s}\n", .{item});
+
+    // Inclusions are written in soil type order, so "cobbles and boulders"
+    // matches "boulders and cobbles"
+    for (std.enums.values(types.SoilType)) |soil_type| {
+        for (desc.inclusions) |inclusion| {
+            if (inclusion.soil_type != soil_type) continue;
+            try writer.print("inclusion={}\n", .{inclusion});
+        }
+    }
+
+    // Tertiary constituents are sorted for the same reason
+    const tertiary = try allocator.alloc([]u8, desc.tertiary_constituents.len);
+    var tertiary_filled: usize = 0;
+    defer {
+        for (tertiary[0..tertiary_filled]) |item| allocator.free(item);
+        allocator.free(tertiary);
+    }
+    for (desc.tertiary_constituents) |constituent| {
+        tertiary[tertiary_filled] = try std.fmt.allocPrint(allocator, "{}", .{constituent});
+        tertiary_filled += 1;
+    }
+    std.mem.sort([]u8, tertiary, {}, stringLessThan);
+    for (tertiary) |item| try writer.print("tertiary_constituent={s}\n", .{item});
+
+    // Materials are written in enum order, so "brick and concrete" matches
+    // "concrete and brick"
+    for (std.enums.values(types.AnthropogenicMaterial)) |material| {
+        for (desc.made_ground_constituents) |constituent| {
+            if (constituent.material != material) continue;
+            try writer.print("made_ground_constituent={}\n", .{constituent});
+        }
+    }
+
+    for (desc.absences) |absence| try writeLowerLine(writer, "absent", absence.feature);
+
+    return out.toOwnedSlice();
+}
+
+/// Hash of `canonicalForm`, for spotting when a stored description's meaning
+/// has changed rather than just its formatting
+pub fn hashOf(allocator: std.mem.Allocator, desc: GeologicalDescription) !Hash {
+    const form = try canonicalForm(allocator, desc);
+    defer allocator.free(form);
+
+    var digest: [Sha256.digest_length]u8 = undefined;
+    Sha256.hash(form, &digest, .{});
+    return std.fmt.bytesToHex(digest, .lower);
+}
+
+/// Parse `description` and hash its meaning
+pub fn canonicalHash(parser: *Parser, description: []const u8) !Hash {
+    const desc = try parser.parse(description);
+    defer desc.deinit(parser.allocator);
+    return hashOf(parser.allocator, desc);
+}
+
+fn colorName(color: types.Color) []const u8 {
+    return switch (color) {
+        .gray => "grey",
+        else => @tagName(color),
+    };
+}
+
+fn writeLowerLine(writer: anytype, key: []const u8, value: []const u8) !void {
+    try writer.print("{s}=", .{key});
+    for (std.mem.trim(u8, value, " \t")) |ch| try writer.writeByte(std.ascii.toLower(ch));
+    try writer.writeByte('\n');
+}
+
+fn stringLessThan(_: void, a: []u8, b: []u8) bool {
+    return std.mem.lessThan(u8, a, b);
+}
+
+test "canonical hash ignores formatting but not meaning" {
+    const allocator = std.testing.allocator;
+    var parser = Parser.init(allocator);
+
+    const base = try canonicalHash(&parser, "Firm slightly sandy slightly gravelly CLAY");
+    const reformatted = try canonicalHash(&parser, "firm  slightly gravelly slightly sandy CLAY");
+    const changed = try canonicalHash(&parser, "Stiff slightly sandy slightly gravelly CLAY");
+
+    try std.testing.expectEqualStrings(&base, &reformatted);
+    try std.testing.expect(!std.mem.eql(u8, &base, &changed));
+}
@@ -0,0 +1,281 @@
+const std = @import("std");
+const types = @import("types.zig");
+
+const GeologicalDescription = types.GeologicalDescription;
+
+/// Carbonate content band of the offshore (ISO 19901-8, after Clark &
+/// Walker) classification, by percentage of calcium carbonate
+pub const CarbonateContent = enum {
+    siliceous, // 0-10 %
+    low, // 10-50 %
+    high, // 50-90 %
+    carbonate, // 90-100 %
+
+    pub fn fromPercentage(percent: f32) CarbonateContent {
+        if (percent < 10) return .siliceous;
+        if (percent < 50) return .low;
+        if (percent < 90) return .high;
+        return .carbonate;
+    }
+
+    pub fn toString(self: CarbonateContent) []const u8 {
+        return switch (self) {
+            .siliceous => "0-10%",
+            .low => "10-50%",
+            .high => "50-90%",
+            .carbonate => "90-100%",
+        };
+    }
+};
+
+/// Degree of cementation. Strongly cemented material is named as rock.
+pub const Cementation = enum {
+    uncemented,
+    weak,
+    moderate,
+    strong,
+
+    pub fn toString(self: Cementation) []const u8 {
+        return switch (self) {
+            .uncemented => "uncemented",
+            .weak => "weakly cemented",
+            .moderate => "moderately cemented",
+            .strong => "strongly cemented",
+        };
+    }
+};
+
+/// Grain size the classification is made for
+pub const Grain = enum { clay, silt, sand, gravel };
+
+/// One cell of the carbonate content and cementation matrix
+pub const CarbonateClass = struct {
+    grain: Grain,
+    content: CarbonateContent,
+    cementation: Cementation,
+
+    /// The classification name, e.g. "carbonate silica sand" or "calcarenite"
+    pub fn name(self: CarbonateClass) []const u8 {
+        return names[@intFromEnum(self.cementation)][@intFromEnum(self.grain)][@intFromEnum(self.content)];
+    }
+};
+
+// [grain][content] for uncemented material
+const soil_names = [4][4][]const u8{
+    .{ "clay", "calcareous clay", "siliceous carbonate clay", "carbonate clay" },
+    .{ "silt", "calcareous silt", "siliceous carbonate silt", "carbonate silt" },
+    .{ "silica sand", "carbonate silica sand", "silica carbonate sand", "carbonate sand" },
+    .{ "silica gravel", "carbonate silica gravel", "silica carbonate gravel", "carbonate gravel" },
+};
+
+// [grain][content] for strongly cemented material
+const rock_names = [4][4][]const u8{
+    .{ "claystone", "calcareous claystone", "siliceous calcilutite", "calcilutite" },
+    .{ "siltstone", "calcareous siltstone", "siliceous calcisiltite", "calcisiltite" },
+    .{ "sandstone", "calcareous sandstone", "siliceous calcarenite", "calcarenite" },
+    .{ "conglomerate", "calcareous conglomerate", "siliceous calcirudite", "calcirudite" },
+};
+
+// [cementation][grain][content]
+const names = blk: {
+    var table: [4][4][4][]const u8 = undefined;
+    for (0..4) |grain| {
+        for (0..4) |content| {
+            table[@intFromEnum(Cementation.uncemented)][grain][content] = soil_names[grain][content];
+            table[@intFromEnum(Cementation.weak)][grain][content] = "weakly cemented " ++ soil_names[grain][content];
+            table[@intFromEnum(Cementation.moderate)][grain][content] = "moderately cemented " ++ soil_names[grain][content];
+            table[@intFromEnum(Cementation.strong)][grain][content] = rock_names[grain][content];
+        }
+    }
+    break :blk table;
+};
+
+const content_terms = [_]struct { phrase: []const u8, content: CarbonateContent }{
+    .{ .phrase = "carbonate", .content = .carbonate },
+    .{ .phrase = "calcareous", .content = .low },
+    .{ .phrase = "carbonate silica", .content = .low },
+    .{ .phrase = "calcareous silica", .content = .low },
+    .{ .phrase = "silica carbonate", .content = .high },
+    .{ .phrase = "siliceous carbonate", .content = .high },
+};
+
+const cementation_terms = [_]struct { phrase: []const u8, cementation: Cementation }{
+    .{ .phrase = "uncemented", .cementation = .uncemented },
+    .{ .phrase = "non-cemented", .cementation = .uncemented },
+    .{ .phrase = "cemented", .cementation = .moderate },
+    .{ .phrase = "weakly cemented", .cementation = .weak },
+    .{ .phrase = "slightly cemented", .cementation = .weak },
+    .{ .phrase = "poorly cemented", .cementation = .weak },
+    .{ .phrase = "moderately cemented", .cementation = .moderate },
+    .{ .phrase = "firmly cemented", .cementation = .moderate },
+    .{ .phrase = "well cemented", .cementation = .strong },
+    .{ .phrase = "strongly cemented", .cementation = .strong },
+};
+
+/// The carbonate classification of a soil description that mentions
+/// carbonate ("CARBONATE SAND", "calcareous", "CaCO3 65%"). A stated
+/// percentage sets the band; otherwise the descriptor does. Null without a
+/// carbonate descriptor or a clay to gravel primary soil.
+pub fn classify(desc: GeologicalDescription) ?CarbonateClass {
+    const primary = desc.primary_soil_type orelse return null;
+    const grain: Grain = switch (primary) {
+        .clay => .clay,
+        .silt => .silt,
+        .sand => .sand,
+        .gravel => .gravel,
+        else => return null,
+    };
+
+    const text = desc.raw_description;
+    const content = if (statedPercentage(text)) |percent|
+        CarbonateContent.fromPercentage(percent)
+    else
+        descriptorContent(text) orelse return null;
+
+    return CarbonateClass{
+        .grain = grain,
+        .content = content,
+        .cementation = describedCementation(text),
+    };
+}
+
+fn descriptorContent(text: []const u8) ?CarbonateContent {
+    var pos: usize = 0;
+    while (pos < text.len) : (pos += 1) {
+        if (pos > 0 and std.ascii.isAlphanumeric(text[pos - 1])) continue;
+        var best: ?CarbonateContent = null;
+        var best_len: usize = 0;
+        for (content_terms) |term| {
+            if (term.phrase.len <= best_len or !phraseAt(text, pos, term.phrase)) continue;
+            best = term.content;
+            best_len = term.phrase.len;
+        }
+        if (best) |content| return content;
+    }
+    return null;
+}
+
+fn describedCementation(text: []const u8) Cementation {
+    var pos: usize = 0;
+    while (pos < text.len) : (pos += 1) {
+        if (pos > 0 and std.ascii.isAlphanumeric(text[pos - 1])) continue;
+        var best: ?Cementation = null;
+        var best_len: usize = 0;
+        for (cementation_terms) |term| {
+            if (term.phrase.len <= best_len or !phraseAt(text, pos, term.phrase)) continue;
+            best = term.cementation;
+            best_len = term.phrase.len;
+        }
+        if (best) |cementation| return cementation;
+    }
+    return .uncemented;
+}
+
+/// A percentage labelled as the carbonate content: "CaCO3 65%",
+/// "carbonate content: 65%", "carbonate content of 65%" or "65% CaCO3".
+/// Any other percentage, such as "CARBONATE SAND with 20% gravel", is a
+/// constituent and says nothing about carbonate.
+fn statedPercentage(text: []const u8) ?f32 {
+    var pos: usize = 0;
+    while (pos < text.len) : (pos += 1) {
+        if (pos > 0 and std.ascii.isAlphanumeric(text[pos - 1])) continue;
+        for ([_][]const u8{ "carbonate content", "caco3" }) |label| {
+            if (!phraseAt(text, pos, label)) continue;
+            if (percentageAt(text, skipLink(text, pos + label.len))) |value| return value;
+        }
+        if (std.ascii.isDigit(text[pos])) {
+            var end = pos;
+            while (end < text.len and (std.ascii.isDigit(text[end]) or text[end] == '.')) end += 1;
+            const percent = skipSpaces(text, end);
+            if (percent >= text.len or text[percent] != '%') continue;
+            if (!phraseAt(text, skipSpaces(text, percent + 1), "caco3")) continue;
+            return std.fmt.parseFloat(f32, text[pos..end]) catch continue;
+        }
+    }
+    return null;
+}
+
+/// Past the spaces and any ":", "=" or "of" between a label and its value
+fn skipLink(text: []const u8, from: usize) usize {
+    var pos = skipSpaces(text, from);
+    if (pos < text.len and (text[pos] == ':' or text[pos] == '=')) {
+        pos = skipSpaces(text, pos + 1);
+    } else if (phraseAt(text, pos, "of")) {
+        pos = skipSpaces(text, pos + 2);
+    }
+    return pos;
+}
+
+/// "65%" or "65 %" starting at `pos`
+fn percentageAt(text: []const u8, pos: usize) ?f32 {
+    var end = pos;
+    while (end < text.len and (std.ascii.isDigit(text[end]) or text[end] == '.')) end += 1;
+    if (end == pos) return null;
+    const percent = skipSpaces(text, end);
+    if (percent >= text.len or text[percent] != '%') return null;
+    return std.fmt.parseFloat(f32, text[pos..end]) catch null;
+}
+
+fn skipSpaces(text: []const u8, from: usize) usize {
+    var pos = from;
+    while (pos < text.len and text[pos] == ' ') pos += 1;
+    return pos;
+}
+
+fn phraseAt(text: []const u8, pos: usize, phrase: []const u8) bool {
+    if (!std.ascii.startsWithIgnoreCase(text[pos..], phrase)) return false;
+    const end = pos + phrase.len;
+    return end == text.len or !std.ascii.isAlphanumeric(text[end]);
+}
+
+test "classify carbonate soils" {
+    const carbonate_sand = GeologicalDescription{
+        .raw_description = "Medium dense white CARBONATE SAND",
+        .material_type = .soil,
+        .primary_soil_type = .sand,
+    };
+    try std.testing.expectEqualStrings("carbonate sand", classify(carbonate_sand).?.name());
+
+    const measured = GeologicalDescription{
+        .raw_description = "Dense light grey SAND, carbonate content 35%",
+        .material_type = .soil,
+        .primary_soil_type = .sand,
+    };
+    try std.testing.expectEqualStrings("carbonate silica sand", classify(measured).?.name());
+
+    const labelled = GeologicalDescription{
+        .raw_description = "Dense light grey SAND (CaCO3 95%)",
+        .material_type = .soil,
+        .primary_soil_type = .sand,
+    };
+    try std.testing.expectEqualStrings("carbonate sand", classify(labelled).?.name());
+
+    // The gravel percentage is not a carbonate content
+    const gravelly = GeologicalDescription{
+        .raw_description = "Medium dense CARBONATE SAND with 20% gravel",
+        .material_type = .soil,
+        .primary_soil_type = .sand,
+    };
+    try std.testing.expectEqualStrings("carbonate sand", classify(gravelly).?.name());
+
+    const cemented = GeologicalDescription{
+        .raw_description = "Well cemented CARBONATE SAND",
+        .material_type = .soil,
+        .primary_soil_type = .sand,
+    };
+    try std.testing.expectEqualStrings("calcarenite", classify(cemented).?.name());
+
+    const weak = GeologicalDescription{
+        .raw_description = "Weakly cemented calcareous SILT",
+        .material_type = .soil,
+        .primary_soil_type = .silt,
+    };
+    try std.testing.expectEqualStrings("weakly cemented calcareous silt", classify(weak).?.name());
+
+    const plain = GeologicalDescription{
+        .raw_description = "Dense SAND",
+        .material_type = .soil,
+        .primary_soil_type = .sand,
+    };
+    try std.testing.expect(classify(plain) == null);
+}
@@ -0,0 +1,157 @@
+const std = @import("std");
+const types = @import("types.zig");
+
+const Field = types.Field;
+const GeologicalDescription = types.GeologicalDescription;
+
+/// Where a derived value comes from
+pub const Citation = struct {
+    /// The field holding the value
+    field: Field,
+    /// What the source gives ("cu from consistency")
+    subject: []const u8,
+    /// Standard or publication ("BS 5930:2015")
+    standard: []const u8,
+    clause: ?[]const u8 = null,
+    table: ?[]const u8 = null,
+
+    /// "cu from consistency: BS 5930:2015 §6.3.2.2"
+    pub fn format(self: Citation, comptime _: []const u8, _: std.fmt.FormatOptions, writer: anytype) !void {
+        try writer.print("{s}: {s}", .{ self.subject, self.standard });
+        if (self.clause) |clause| try writer.print(" {s}", .{clause});
+        if (self.table) |table| try writer.print(", {s}", .{table});
+    }
+};
+
+const bs5930 = "BS 5930:2015";
+
+// Term-to-range correlations cite the table giving the ranges; formulas
+// cite the publication only
+const cu_from_consistency = Citation{ .field = .strength_parameters, .subject = "cu from consistency", .standard = bs5930, .clause = "§6.3.2.2", .table = "Table 13" };
+const spt_from_density = Citation{ .field = .strength_parameters, .subject = "SPT N from density", .standard = bs5930, .table = "Table 11" };
+const ucs_from_strength = Citation{ .field = .strength_parameters, .subject = "UCS from rock strength", .standard = "BS EN ISO 14689-1:2003", .table = "Table 5" };
+const cu_from_qu = Citation{ .field = .strength_parameters, .subject = "qu = 2cu between cu and UCS", .standard = "BS EN ISO 17892-7:2018" };
+const dilatancy = Citation{ .field = .strength_parameters, .subject = "SPT N dilatancy correction", .standard = "Terzaghi and Peck (1948)" };
+const proportions = Citation{ .field = .constituent_guidance, .subject = "constituent proportions", .standard = bs5930, .clause = "§6.3.2.4" };
+const karst_grades = Citation{ .field = .karst_grade, .subject = "karst grade", .standard = "Waltham and Fookes (2003)" };
+
+/// For zones from `cpt.zoneOf`, which are not part of a description
+pub const cpt_soil_behaviour_type = Citation{ .field = .primary_soil_type, .subject = "soil behaviour type from CPT", .standard = "Robertson (1990)" };
+/// For bands from `carbonate.classify`
+pub const carbonate_content = Citation{ .field = .primary_soil_type, .subject = "carbonate content band", .standard = "ISO 19901-8 (after Clark and Walker)" };
+
+/// The sources of the values in `desc` that were derived rather than read
+/// or measured, in `Field` order. Caller owns the slice; the strings are
+/// static.
+pub fn citations(allocator: std.mem.Allocator, desc: GeologicalDescription) ![]Citation {
+    var list = std.ArrayList(Citation).init(allocator);
+    errdefer list.deinit();
+
+    if (desc.strength_parameters) |params| {
+        if (params.provenance != .measured) {
+            try list.append(switch (params.parameter_type) {
+                .undrained_shear_strength => cu_from_consistency,
+                .spt_n_value => spt_from_density,
+                .ucs => ucs_from_strength,
+            });
+        }
+        // Applied to measured N-values, so cited whatever the provenance
+        if (params.groundwater_adjustment) |adjustment| {
+            if (adjustment == .dilatancy) try list.append(dilatancy);
+        }
+        if (params.alternate) |alternate| {
+            if (alternate.provenance != .measured) try list.append(cu_from_qu);
+        }
+    }
+    if (desc.constituent_guidance != null) try list.append(proportions);
+    if (desc.karst_grade != null and desc.sourceOf(.karst_grade) == .inferred) try list.append(karst_grades);
+
+    return list.toOwnedSlice();
+}
+
+test "cite derived strength" {
+    const allocator = std.testing.allocator;
+    const desc = GeologicalDescription{
+        .raw_description = "Very stiff CLAY",
+        .material_type = .soil,
+        .consistency = .very_stiff,
+        .primary_soil_type = .clay,
+        .strength_parameters = .{
+            .parameter_type = .undrained_shear_strength,
+            .range = .{ .lower_bound = 100, .upper_bound = 200 },
+            .intermediate_geomaterial = true,
+            .alternate = .{ .parameter_type = .ucs, .range = .{ .lower_bound = 0.2, .upper_bound = 0.4 } },
+        },
+    };
+
+    const cited = try citations(allocator, desc);
+    defer allocator.free(cited);
+    try std.testing.expectEqual(@as(usize, 2), cited.len);
+    try std.testing.expectEqual(Field.strength_parameters, cited[0].field);
+
+    var buf: [64]u8 = undefined;
+    try std.testing.expectEqualStrings("cu from consistency: BS 5930:2015 §6.3.2.2, Table 13", try std.fmt.bufPrint(&buf, "{}", .{cited[0]}));
+    try std.testing.expectEqualStrings("BS EN ISO 17892-7:2018", cited[1].standard);
+}
+
+test "measured strength is not cited" {
+    const allocator = std.testing.allocator;
+    const desc = GeologicalDescription{
+        .raw_description = "Firm CLAY",
+        .material_type = .soil,
+        .strength_parameters = .{
+            .parameter_type = .undrained_shear_strength,
+            .range = .{ .lower_bound = 30, .upper_bound = 45 },
+            .provenance = .measured,
+        },
+    };
+
+    const cited = try citations(allocator, desc);
+    defer allocator.free(cited);
+    try std.testing.expectEqual(@as(usize, 0), cited.len);
+}
+
+test "dilatancy correction on measured SPT N is cited" {
+    const allocator = std.testing.allocator;
+    const desc = GeologicalDescription{
+        .raw_description = "Dense wet fine SAND",
+        .material_type = .soil,
+        .strength_parameters = .{
+            .parameter_type = .spt_n_value,
+            .range = .{ .lower_bound = 22.5, .upper_bound = 30 },
+            .provenance = .measured,
+            .groundwater_adjustment = .dilatancy,
+        },
+    };
+
+    const cited = try citations(allocator, desc);
+    defer allocator.free(cited);
+    try std.testing.expectEqual(@as(usize, 1), cited.len);
+    try std.testing.expectEqualStrings("Terzaghi and Peck (1948)", cited[0].standard);
+}
+
+test "karst grade is cited only when inferred" {
+    const allocator = std.testing.allocator;
+    var desc = GeologicalDescription{
+        .raw_description = "Karstic LIMESTONE, karst grade kIII",
+        .material_type = .rock,
+        .karst_grade = .mature,
+    };
+    desc.sources.put(.karst_grade, .parsed);
+
+    const read = try citations(allocator, desc);
+    defer allocator.free(read);
+    try std.testing.expectEqual(@as(usize, 0), read.len);
+
+    desc.sources.put(.karst_grade, .inferred);
+    const inferred = try citations(allocator, desc);
+    defer allocator.free(inferred);
+    try std.testing.expectEqual(@as(usize, 1), inferred.len);
+    try std.testing.expectEqual(Field.karst_grade, inferred[0].field);
+}
+
+test "each strength correlation cites its table" {
+    for ([_]Citation{ cu_from_consistency, spt_from_density, ucs_from_strength }) |citation| {
+        try std.testing.expect(citation.table != null);
+    }
+}
@@ -0,0 +1,145 @@
+const std = @import("std");
+const types = @import("types.zig");
+const scan = @import("scan.zig");
+
+const Colour = types.Colour;
+const Hue = types.Hue;
+const Shade = types.Shade;
+const Tone = types.Tone;
+const Word = scan.Word;
+
+/// The colour phrase of a description
+pub const Match = struct {
+    colour: Colour,
+    start: usize,
+    end: usize,
+};
+
+const max_words = 64;
+
+/// The first colour phrase of a description, before any "with" clause so the
+/// colour of an inclusion is not taken for the main soil's. Reads
+/// "[shade] [tint] hue" ("dark brownish grey", "grey-brown"), then either
+/// "<colour> mottled <colour>" or "mottled <colour> and <colour>".
+pub fn find(text: []const u8) ?Match {
+    var words: [max_words]Word = undefined;
+    var len: usize = 0;
+    var pos: usize = 0;
+    while (len < words.len) {
+        const word = scan.next(text, pos, .letters) orelse break;
+        if (std.ascii.eqlIgnoreCase(word.text, "with")) break;
+        words[len] = word;
+        len += 1;
+        pos = word.end;
+    }
+
+    for (0..len) |i| {
+        if (readColour(words[0..len], i)) |match| return match;
+    }
+    return null;
+}
+
+fn readColour(words: []const Word, first: usize) ?Match {
+    // "mottled orange and grey"
+    if (std.ascii.eqlIgnoreCase(words[first].text, "mottled")) {
+        if (words[first].endsPhrase()) return null;
+        const base = readTone(words, first + 1) orelse return null;
+        var colour = withTone(base.tone);
+        var end = base.end;
+        if (!words[end - 1].endsPhrase() and end + 1 < words.len and std.ascii.eqlIgnoreCase(words[end].text, "and")) {
+            if (readTone(words, end + 1)) |mottles| {
+                colour.mottling = mottles.tone;
+                end = mottles.end;
+            }
+        }
+        return Match{ .colour = colour, .start = words[first].start, .end = words[end - 1].end };
+    }
+
+    // "grey mottled orange"
+    const base = readTone(words, first) orelse return null;
+    var colour = withTone(base.tone);
+    var end = base.end;
+    if (!words[end - 1].endsPhrase() and end + 1 < words.len and std.ascii.eqlIgnoreCase(words[end].text, "mottled")) {
+        if (readTone(words, end + 1)) |mottles| {
+            colour.mottling = mottles.tone;
+            end = mottles.end;
+        }
+    }
+    return Match{ .colour = colour, .start = words[first].start, .end = words[end - 1].end };
+}
+
+const ReadTone = struct {
+    tone: Tone,
+    /// Index of the word after the tone
+    end: usize,
+};
+
+fn readTone(words: []const Word, first: usize) ?ReadTone {
+    var i = first;
+    var tone = Tone{ .hue = undefined };
+
+    if (i < words.len and !words[i].endsPhrase()) {
+        if (Shade.fromString(words[i].text)) |shade| {
+            tone.shade = shade;
+            i += 1;
+        }
+    }
+    if (i < words.len and !words[i].endsPhrase()) {
+        if (Hue.fromTint(words[i].text)) |tint| {
+            tone.tint = tint;
+            i += 1;
+        } else if (words[i].joinedBy('-') and i + 1 < words.len) {
+            // "grey-brown": the first hue qualifies the second
+            if (Hue.fromString(words[i].text)) |tint| {
+                if (Hue.fromString(words[i + 1].text) != null) {
+                    tone.tint = tint;
+                    i += 1;
+                }
+            }
+        }
+    }
+    if (i >= words.len) return null;
+    tone.hue = Hue.fromString(words[i].text) orelse return null;
+    return ReadTone{ .tone = tone, .end = i + 1 };
+}
+
+fn withTone(tone: Tone) Colour {
+    return Colour{ .shade = tone.shade, .tint = tone.tint, .hue = tone.hue };
+}
+
+test "find shade, tint and hue" {
+    const match = find("Firm dark brownish grey slightly sandy CLAY").?;
+    try std.testing.expectEqual(Shade.dark, match.colour.shade.?);
+    try std.testing.expectEqual(Hue.brown, match.colour.tint.?);
+    try std.testing.expectEqual(Hue.grey, match.colour.hue);
+    try std.testing.expect(match.colour.mottling == null);
+    try std.testing.expectEqual(@as(usize, 5), match.start);
+    try std.testing.expectEqual(@as(usize, 23), match.end);
+
+    const hyphenated = find("Stiff grey-brown CLAY").?;
+    try std.testing.expectEqual(Hue.grey, hyphenated.colour.tint.?);
+    try std.testing.expectEqual(Hue.brown, hyphenated.colour.hue);
+
+    try std.testing.expectEqual(Hue.grey, find("Soft gray SILT").?.colour.hue);
+}
+
+test "find mottling" {
+    const mottled = find("Firm mottled orange and grey CLAY").?;
+    try std.testing.expectEqual(Hue.orange, mottled.colour.hue);
+    try std.testing.expectEqual(Hue.grey, mottled.colour.mottling.?.hue);
+
+    const grey = find("Stiff light grey mottled dark orange CLAY").?;
+    try std.testing.expectEqual(Shade.light, grey.colour.shade.?);
+    try std.testing.expectEqual(Hue.grey, grey.colour.hue);
+    try std.testing.expectEqual(Shade.dark, grey.colour.mottling.?.shade.?);
+    try std.testing.expectEqual(Hue.orange, grey.colour.mottling.?.hue);
+
+    var buf: [64]u8 = undefined;
+    try std.testing.expectEqualStrings("light grey mottled dark orange", try std.fmt.bufPrint(&buf, "{}", .{grey.colour}));
+}
+
+test "ignore colours of inclusions and missing colours" {
+    try std.testing.expect(find("Firm CLAY with pockets of grey silt") == null);
+    try std.testing.expect(find("Dense SAND") == null);
+    try std.testing.expect(find("Firm light CLAY") == null);
+}
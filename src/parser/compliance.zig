@@ -10,7 +10,7 @@ pub const ComplianceChecker = struct {
         return ComplianceChecker{ .allocator = allocator };
     }
 
-    pub fn check(self: *ComplianceChecker, description: *const types.SoilDescription) !ComplianceReport {
+    pub fn check(self: *ComplianceChecker, description: *const types.GeologicalDescription) !ComplianceReport {
         var issues = std.ArrayList(ComplianceIssue).init(self.allocator);
         errdefer {
             for (issues.items) |*issue| {
@@ -46,7 +46,7 @@ pub const ComplianceChecker = struct {
     fn checkTerminologyCompliance(
         self: *ComplianceChecker,
         issues: *std.ArrayList(ComplianceIssue),
-        description: *const types.SoilDescription,
+        description: *const types.GeologicalDescription,
     ) !void {
         const raw_lower_buf = try self.allocator.alloc(u8, description.raw_description.len);
         defer self.allocator.free(raw_lower_buf);
@@ -58,6 +58,7 @@ pub const ComplianceChecker = struct {
         }{
             .{ .term = "medium firm", .suggestion = "Use 'firm' or 'firm to stiff'" },
             .{ .term = "moderately stiff", .suggestion = "Use 'stiff' or 'firm to stiff'" },
+            .{ .term = "medium plasticity", .suggestion = "Use 'intermediate plasticity'" },
         };
 
         for (non_standard_terms) |item| {
@@ -81,7 +82,7 @@ pub const ComplianceChecker = struct {
     fn checkDescriptorOrder(
         self: *ComplianceChecker,
         issues: *std.ArrayList(ComplianceIssue),
-        description: *const types.SoilDescription,
+        description: *const types.GeologicalDescription,
     ) !void {
         // BS 5930 §6.3 specifies order:
         // [Consistency/Density] [Color] [Secondary constituents] [Primary constituent]
@@ -149,7 +150,7 @@ pub const ComplianceChecker = struct {
     fn checkProportionConsistency(
         self: *ComplianceChecker,
         issues: *std.ArrayList(ComplianceIssue),
-        description: *const types.SoilDescription,
+        description: *const types.GeologicalDescription,
     ) !void {
         // Check proportion descriptors match BS 5930 guidelines
         // - "slightly" = 5-20%
@@ -184,7 +185,7 @@ pub const ComplianceChecker = struct {
     fn checkGeologicalPlausibility(
         self: *ComplianceChecker,
         issues: *std.ArrayList(ComplianceIssue),
-        description: *const types.SoilDescription,
+        description: *const types.GeologicalDescription,
     ) !void {
         // Check for geologically implausible combinations
 
@@ -255,7 +256,7 @@ pub const ComplianceChecker = struct {
     fn checkDeprecatedTerms(
         self: *ComplianceChecker,
         issues: *std.ArrayList(ComplianceIssue),
-        description: *const types.SoilDescription,
+        description: *const types.GeologicalDescription,
     ) !void {
         const raw_lower_buf = try self.allocator.alloc(u8, description.raw_description.len);
         defer self.allocator.free(raw_lower_buf);
@@ -0,0 +1,206 @@
+const std = @import("std");
+const types = @import("types.zig");
+const scan = @import("scan.zig");
+
+const CompositeArrangement = types.CompositeArrangement;
+const CompositeComponent = types.CompositeComponent;
+const CompositeDescription = types.CompositeDescription;
+const RockType = types.RockType;
+const SoilType = types.SoilType;
+const Thickness = types.Thickness;
+
+pub const max_components = 4;
+
+/// A composite stratum read from a description, with the components held
+/// inline; see `toOwned`
+pub const Match = struct {
+    arrangement: CompositeArrangement,
+    thickness: ?Thickness = null,
+    components: [max_components]CompositeComponent = undefined,
+    len: usize = 0,
+    proportions_stated: bool = false,
+    start: usize,
+    end: usize,
+
+    /// The description's form of the match. Caller owns the components.
+    pub fn toOwned(self: *const Match, allocator: std.mem.Allocator) !CompositeDescription {
+        return CompositeDescription{
+            .arrangement = self.arrangement,
+            .thickness = self.thickness,
+            .components = try allocator.dupe(CompositeComponent, self.components[0..self.len]),
+            .proportions_stated = self.proportions_stated,
+        };
+    }
+};
+
+/// One lithology being read, before the proportions are settled
+const Part = struct {
+    rock_type: ?RockType = null,
+    soil_type: ?SoilType = null,
+    percent: ?u8 = null,
+    end: usize = 0,
+
+    fn isSet(self: Part) bool {
+        return self.rock_type != null or self.soil_type != null;
+    }
+};
+
+/// Reads an interbedded or interlaminated stratum, written either as
+/// "Interbedded SANDSTONE and MUDSTONE" or "CLAY interlaminated with SILT".
+/// Components are joined by "and" or commas and run to the end of the
+/// sentence or the next "with". A percentage before or after a component
+/// ("SANDSTONE (70%) and MUDSTONE (30%)") is its proportion; components
+/// without one share what is left evenly. Null unless two or more
+/// lithologies are named.
+pub fn find(text: []const u8) ?Match {
+    var parts: [max_components]Part = undefined;
+    var len: usize = 0;
+    var current = Part{};
+    // A lithology named earlier in the sentence, for "CLAY interlaminated with SILT"
+    var before = Part{};
+    var before_start: usize = 0;
+    var match: ?Match = null;
+    var equal = false;
+
+    var pos: usize = 0;
+    while (scan.next(text, pos, .alphanumeric)) |word| {
+        pos = word.end;
+
+        if (match == null) {
+            if (CompositeArrangement.fromString(word.text)) |arrangement| {
+                match = Match{ .arrangement = arrangement, .start = word.start, .end = word.end };
+                if (before.isSet()) {
+                    parts[0] = before;
+                    len = 1;
+                    match.?.start = before_start;
+                }
+                if (scan.next(text, word.end, .alphanumeric)) |after| {
+                    if (std.ascii.eqlIgnoreCase(after.text, "with")) pos = after.end;
+                }
+                if (word.stop) break;
+                continue;
+            }
+            if (lithologyOf(word.text)) |part| {
+                before = part;
+                before.end = word.end;
+                before_start = word.start;
+            }
+            if (word.stop) before = Part{};
+            continue;
+        }
+
+        if (std.ascii.eqlIgnoreCase(word.text, "with")) break;
+        if (std.ascii.eqlIgnoreCase(word.text, "equal")) equal = true;
+
+        if (Thickness.fromString(word.text)) |thickness| {
+            if (scan.next(text, word.end, .alphanumeric)) |after| {
+                if (std.ascii.eqlIgnoreCase(after.text, "bedded") or std.ascii.eqlIgnoreCase(after.text, "laminated")) {
+                    match.?.thickness = thickness;
+                }
+            }
+        } else if (word.percent) {
+            if (std.fmt.parseInt(u8, word.text, 10) catch null) |percent| {
+                if (percent <= 100) current.percent = percent;
+            }
+        } else if (lithologyOf(word.text)) |part| {
+            if (!current.isSet()) {
+                current.rock_type = part.rock_type;
+                current.soil_type = part.soil_type;
+                current.end = word.end;
+            }
+        }
+
+        const closes = word.comma or word.stop or std.ascii.eqlIgnoreCase(word.text, "and");
+        if (closes and current.isSet() and len < parts.len) {
+            parts[len] = current;
+            len += 1;
+            current = Part{};
+        }
+        if (word.stop) break;
+    }
+    if (current.isSet() and len < parts.len) {
+        parts[len] = current;
+        len += 1;
+    }
+
+    var result = match orelse return null;
+    if (len < 2) return null;
+    result.end = @max(result.end, parts[len - 1].end);
+    result.len = len;
+    result.proportions_stated = equal;
+    settleProportions(&result, parts[0..len]);
+    return result;
+}
+
+/// Stated percentages are kept; the rest of 100 is split evenly over the
+/// components without one, the first ones taking any remainder
+fn settleProportions(match: *Match, parts: []const Part) void {
+    var stated_total: u32 = 0;
+    var unstated: u32 = 0;
+    for (parts) |part| {
+        if (part.percent) |percent| stated_total += percent else unstated += 1;
+    }
+    // Stated shares that do not add up are ignored
+    const use_stated = stated_total <= 100 and (unstated > 0 or stated_total == 100);
+    if (!use_stated) unstated = @intCast(parts.len);
+    const left: u32 = if (use_stated) 100 - stated_total else 100;
+    if (use_stated and unstated < parts.len) match.proportions_stated = true;
+
+    var remainder = if (unstated > 0) left % unstated else 0;
+    for (parts, 0..) |part, i| {
+        var percent: u32 = undefined;
+        if (use_stated and part.percent != null) {
+            percent = part.percent.?;
+        } else {
+            percent = left / unstated;
+            if (remainder > 0) {
+                percent += 1;
+                remainder -= 1;
+            }
+        }
+        match.components[i] = .{ .rock_type = part.rock_type, .soil_type = part.soil_type, .percent = @intCast(percent) };
+    }
+}
+
+fn lithologyOf(text: []const u8) ?Part {
+    if (RockType.fromString(text)) |rock_type| return Part{ .rock_type = rock_type };
+    // "organic" describes a soil rather than naming one
+    const soil_type = SoilType.fromString(text) orelse return null;
+    if (soil_type == .organic) return null;
+    return Part{ .soil_type = soil_type };
+}
+
+test "read interbedded rock" {
+    const text = "Interbedded thinly bedded SANDSTONE and MUDSTONE";
+    const match = find(text).?;
+    try std.testing.expectEqual(CompositeArrangement.interbedded, match.arrangement);
+    try std.testing.expectEqual(Thickness.thin, match.thickness.?);
+    try std.testing.expectEqual(@as(usize, 2), match.len);
+    try std.testing.expectEqual(RockType.sandstone, match.components[0].rock_type.?);
+    try std.testing.expectEqual(@as(u8, 50), match.components[0].percent);
+    try std.testing.expectEqual(RockType.mudstone, match.components[1].rock_type.?);
+    try std.testing.expect(!match.proportions_stated);
+    try std.testing.expectEqualStrings(text, text[match.start..match.end]);
+}
+
+test "read stated proportions and the trailing form" {
+    const stated = find("Interbedded strong LIMESTONE (70%) and weak MUDSTONE").?;
+    try std.testing.expect(stated.proportions_stated);
+    try std.testing.expectEqual(@as(u8, 70), stated.components[0].percent);
+    try std.testing.expectEqual(@as(u8, 30), stated.components[1].percent);
+
+    const text = "Soft grey CLAY interlaminated with SILT with rare shell fragments";
+    const trailing = find(text).?;
+    try std.testing.expectEqual(CompositeArrangement.interlaminated, trailing.arrangement);
+    try std.testing.expectEqual(SoilType.clay, trailing.components[0].soil_type.?);
+    try std.testing.expectEqual(SoilType.silt, trailing.components[1].soil_type.?);
+    try std.testing.expectEqualStrings("CLAY interlaminated with SILT", text[trailing.start..trailing.end]);
+
+    const three = find("Interbedded SANDSTONE, SILTSTONE and MUDSTONE").?;
+    try std.testing.expectEqual(@as(usize, 3), three.len);
+    try std.testing.expectEqual(@as(u8, 34), three.components[0].percent);
+    try std.testing.expectEqual(@as(u8, 33), three.components[2].percent);
+
+    try std.testing.expect(find("Strong grey SANDSTONE") == null);
+    try std.testing.expect(find("Interbedded SANDSTONE") == null);
+}
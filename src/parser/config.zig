@@ -1,5 +1,7 @@
 const std = @import("std");
 const types = @import("types.zig");
+const validation = @import("validation.zig");
+const noise = @import("noise.zig");
 
 /// Parser configuration options
 pub const ParserConfig = struct {
@@ -33,6 +35,15 @@ pub const ParserConfig = struct {
     /// Confidence penalty for fuzzy matches
     fuzzy_match_penalty: f32 = 0.1,
 
+    /// How strictly upper-case primary types are enforced
+    capitalization_policy: validation.CapitalizationPolicy = .ignored,
+
+    /// Validation rule codes or names to suppress ("W002", "MissingConsistency")
+    suppressed_rules: []const []const u8 = &[_][]const u8{},
+
+    /// Terms ignored while parsing ("approx.", "recovered as")
+    noise_filter: noise.NoiseFilter = .{},
+
     /// Enable verbose logging
     verbose: bool = false,
 
@@ -76,6 +87,18 @@ pub const ParserConfig = struct {
         return config;
     }
 
+    pub fn withCapitalizationPolicy(self: ParserConfig, policy: validation.CapitalizationPolicy) ParserConfig {
+        var config = self;
+        config.capitalization_policy = policy;
+        return config;
+    }
+
+    pub fn withSuppressedRules(self: ParserConfig, rules: []const []const u8) ParserConfig {
+        var config = self;
+        config.suppressed_rules = rules;
+        return config;
+    }
+
     pub fn withVerbose(self: ParserConfig, verbose: bool) ParserConfig {
         var config = self;
         config.verbose = verbose;
@@ -99,6 +122,12 @@ pub const ParserConfig = struct {
         if (self.fuzzy_match_penalty < 0.0 or self.fuzzy_match_penalty > 1.0) {
             return error.InvalidFuzzyMatchPenalty;
         }
+
+        for (self.suppressed_rules) |rule| {
+            if (validation.ValidationError.fromCode(rule) == null) {
+                return error.UnknownValidationRule;
+            }
+        }
     }
 };
 
@@ -184,6 +213,66 @@ pub const CustomDictionary = struct {
     pub fn lookupConsistency(self: *CustomDictionary, term: []const u8) ?types.Consistency {
         return self.consistency_terms.get(term);
     }
+
+    /// Rewrite custom terms as their standard equivalents, matching whole words
+    /// case-insensitively ("Firm boulder clay" -> "Firm CLAY")
+    pub fn substitute(self: *const CustomDictionary, allocator: std.mem.Allocator, text: []const u8) ![]u8 {
+        var output = std.ArrayList(u8).init(allocator);
+        errdefer output.deinit();
+
+        var pos: usize = 0;
+        while (pos < text.len) {
+            const at_word_start = pos == 0 or !std.ascii.isAlphanumeric(text[pos - 1]);
+            if (at_word_start) {
+                if (self.matchAt(text, pos)) |match| {
+                    try output.appendSlice(match.replacement);
+                    pos += match.len;
+                    continue;
+                }
+            }
+            try output.append(text[pos]);
+            pos += 1;
+        }
+
+        return output.toOwnedSlice();
+    }
+
+    const TermMatch = struct {
+        len: usize,
+        replacement: []const u8,
+    };
+
+    /// Longest custom term starting at `pos`
+    fn matchAt(self: *const CustomDictionary, text: []const u8, pos: usize) ?TermMatch {
+        var best: ?TermMatch = null;
+
+        var soil_iter = self.soil_types.iterator();
+        while (soil_iter.next()) |entry| {
+            considerTerm(&best, text, pos, entry.key_ptr.*, entry.value_ptr.toString());
+        }
+
+        var rock_iter = self.rock_types.iterator();
+        while (rock_iter.next()) |entry| {
+            considerTerm(&best, text, pos, entry.key_ptr.*, entry.value_ptr.toString());
+        }
+
+        var cons_iter = self.consistency_terms.iterator();
+        while (cons_iter.next()) |entry| {
+            considerTerm(&best, text, pos, entry.key_ptr.*, entry.value_ptr.toString());
+        }
+
+        return best;
+    }
+
+    fn considerTerm(best: *?TermMatch, text: []const u8, pos: usize, term: []const u8, replacement: []const u8) void {
+        if (term.len == 0 or !std.ascii.startsWithIgnoreCase(text[pos..], term)) return;
+        const end = pos + term.len;
+        if (end < text.len and std.ascii.isAlphanumeric(text[end])) return;
+        if (best.*) |current| {
+            if (current.len >= term.len) return;
+        }
+        best.* = TermMatch{ .len = term.len, .replacement = replacement };
+    }
 };
 
 /// Confidence adjuster for fine-tuning parse confidence scores
@@ -262,6 +351,20 @@ test "custom dictionary" {
     try std.testing.expectEqual(types.SoilType.clay, soil_type.?);
 }
 
+test "custom dictionary substitution" {
+    const allocator = std.testing.allocator;
+
+    var dict = CustomDictionary.init(allocator);
+    defer dict.deinit();
+
+    try dict.addSoilType("boulder clay", types.SoilType.clay);
+
+    const result = try dict.substitute(allocator, "Firm brown Boulder Clay");
+    defer allocator.free(result);
+
+    try std.testing.expectEqualStrings("Firm brown CLAY", result);
+}
+
 test "confidence adjuster" {
     const config = ParserConfig.default();
     const adjuster = ConfidenceAdjuster.init(config);
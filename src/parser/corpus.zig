@@ -0,0 +1,61 @@
+const std = @import("std");
+
+/// About three thousand descriptions in the style of UK site investigation
+/// logs: soils, rocks, made ground, bracketed formations, inclusions and
+/// follow-on sentences such as "Gravel is subangular flint."
+const text = @embedFile("corpus.txt");
+
+/// Walks the example corpus without allocating
+pub const Iterator = struct {
+    lines: std.mem.SplitIterator(u8, .scalar),
+
+    pub fn next(self: *Iterator) ?[]const u8 {
+        while (self.lines.next()) |line| {
+            const trimmed = std.mem.trim(u8, line, " \t\r");
+            if (trimmed.len == 0 or trimmed[0] == '#') continue;
+            return trimmed;
+        }
+        return null;
+    }
+};
+
+pub fn iterator() Iterator {
+    return Iterator{ .lines = std.mem.splitScalar(u8, text, '\n') };
+}
+
+/// Every example description, in file order. The strings are embedded in
+/// the binary; free only the returned slice.
+pub fn load(allocator: std.mem.Allocator) ![]const []const u8 {
+    var descriptions = std.ArrayList([]const u8).init(allocator);
+    errdefer descriptions.deinit();
+
+    var iter = iterator();
+    while (iter.next()) |description| try descriptions.append(description);
+    return descriptions.toOwnedSlice();
+}
+
+test "load the example corpus" {
+    const allocator = std.testing.allocator;
+    const bs5930 = @import("bs5930.zig");
+
+    const descriptions = try load(allocator);
+    defer allocator.free(descriptions);
+    try std.testing.expect(descriptions.len >= 3000);
+
+    var parser = bs5930.Parser.init(allocator);
+    var rock: usize = 0;
+    var made_ground: usize = 0;
+    var topsoil: usize = 0;
+    for (descriptions) |description| {
+        try std.testing.expect(description[0] != '#');
+        const result = try parser.parse(description);
+        defer result.deinit(allocator);
+        if (result.material_type == .rock) rock += 1;
+        if (result.is_made_ground) made_ground += 1;
+        if (result.is_topsoil) topsoil += 1;
+    }
+    // Enough of each kind to be worth testing against
+    try std.testing.expect(rock > descriptions.len / 10);
+    try std.testing.expect(made_ground > descriptions.len / 50);
+    try std.testing.expect(topsoil > descriptions.len / 100);
+}
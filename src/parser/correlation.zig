@@ -0,0 +1,220 @@
+const std = @import("std");
+const types = @import("types.zig");
+const borehole = @import("borehole.zig");
+const unit_identifier = @import("unit_identifier.zig");
+
+const BoreholeLog = borehole.BoreholeLog;
+const Stratum = borehole.Stratum;
+const UnitIdentifier = unit_identifier.UnitIdentifier;
+
+/// Options controlling how strata are matched between boreholes
+pub const CorrelationOptions = struct {
+    /// Weight given to semantic similarity (remainder goes to depth proximity)
+    semantic_weight: f64 = 0.7,
+    /// Depth/elevation difference (m) at which depth similarity drops to zero
+    depth_tolerance: f64 = 5.0,
+    /// Minimum pair confidence for two strata to be correlated
+    min_confidence: f64 = 0.5,
+};
+
+/// A stratum in borehole A matched to a stratum in borehole B
+pub const StrataPair = struct {
+    a_index: usize,
+    b_index: usize,
+    semantic_similarity: f64,
+    depth_similarity: f64,
+    confidence: f64,
+};
+
+/// Result of correlating two borehole logs
+pub const CorrelationResult = struct {
+    pairs: []StrataPair,
+    unmatched_a: []usize, // Strata in A with no counterpart (e.g. pinched out in B)
+    unmatched_b: []usize,
+    confidence: f64, // Overall confidence in the correlation (0.0 to 1.0)
+
+    pub fn deinit(self: *CorrelationResult, allocator: std.mem.Allocator) void {
+        allocator.free(self.pairs);
+        allocator.free(self.unmatched_a);
+        allocator.free(self.unmatched_b);
+    }
+};
+
+/// Correlate strata between two adjacent boreholes using default options
+pub fn correlate(allocator: std.mem.Allocator, a: BoreholeLog, b: BoreholeLog) !CorrelationResult {
+    return correlateWithOptions(allocator, a, b, .{});
+}
+
+/// Correlate strata between two boreholes.
+/// Strata are aligned in stratigraphic order (matches never cross), maximising
+/// the total pair confidence from semantic similarity and depth proximity.
+pub fn correlateWithOptions(
+    allocator: std.mem.Allocator,
+    a: BoreholeLog,
+    b: BoreholeLog,
+    options: CorrelationOptions,
+) !CorrelationResult {
+    const n = a.strata.len;
+    const m = b.strata.len;
+    const cols = m + 1;
+
+    // Pairwise confidence for every stratum combination
+    const pair_scores = try allocator.alloc(f64, n * m);
+    defer allocator.free(pair_scores);
+
+    for (a.strata, 0..) |sa, i| {
+        for (b.strata, 0..) |sb, j| {
+            pair_scores[i * m + j] = pairConfidence(a, sa, b, sb, options);
+        }
+    }
+
+    // Dynamic programming alignment (order preserving, gaps are free)
+    const table = try allocator.alloc(f64, (n + 1) * cols);
+    defer allocator.free(table);
+    @memset(table, 0);
+
+    for (1..n + 1) |i| {
+        for (1..m + 1) |j| {
+            var best = @max(table[(i - 1) * cols + j], table[i * cols + j - 1]);
+            const score = pair_scores[(i - 1) * m + (j - 1)];
+            if (score >= options.min_confidence) {
+                best = @max(best, table[(i - 1) * cols + (j - 1)] + score);
+            }
+            table[i * cols + j] = best;
+        }
+    }
+
+    // Traceback
+    var pairs = std.ArrayList(StrataPair).init(allocator);
+    errdefer pairs.deinit();
+    var unmatched_a = std.ArrayList(usize).init(allocator);
+    errdefer unmatched_a.deinit();
+    var unmatched_b = std.ArrayList(usize).init(allocator);
+    errdefer unmatched_b.deinit();
+
+    var i = n;
+    var j = m;
+    while (i > 0 and j > 0) {
+        const score = pair_scores[(i - 1) * m + (j - 1)];
+        if (score >= options.min_confidence and table[i * cols + j] == table[(i - 1) * cols + (j - 1)] + score) {
+            const sa = a.strata[i - 1];
+            const sb = b.strata[j - 1];
+            try pairs.append(StrataPair{
+                .a_index = i - 1,
+                .b_index = j - 1,
+                .semantic_similarity = UnitIdentifier.similarityScore(&sa.description, &sb.description),
+                .depth_similarity = depthSimilarity(a, sa, b, sb, options.depth_tolerance),
+                .confidence = score,
+            });
+            i -= 1;
+            j -= 1;
+        } else if (table[(i - 1) * cols + j] >= table[i * cols + j - 1]) {
+            try unmatched_a.append(i - 1);
+            i -= 1;
+        } else {
+            try unmatched_b.append(j - 1);
+            j -= 1;
+        }
+    }
+    while (i > 0) : (i -= 1) try unmatched_a.append(i - 1);
+    while (j > 0) : (j -= 1) try unmatched_b.append(j - 1);
+
+    std.mem.reverse(StrataPair, pairs.items);
+    std.mem.reverse(usize, unmatched_a.items);
+    std.mem.reverse(usize, unmatched_b.items);
+
+    // Overall confidence: mean pair confidence scaled by stratum coverage
+    var confidence: f64 = 0;
+    if (pairs.items.len > 0) {
+        var total: f64 = 0;
+        for (pairs.items) |pair| total += pair.confidence;
+        const mean = total / @as(f64, @floatFromInt(pairs.items.len));
+        const coverage = @as(f64, @floatFromInt(2 * pairs.items.len)) / @as(f64, @floatFromInt(n + m));
+        confidence = mean * coverage;
+    }
+
+    return CorrelationResult{
+        .pairs = try pairs.toOwnedSlice(),
+        .unmatched_a = try unmatched_a.toOwnedSlice(),
+        .unmatched_b = try unmatched_b.toOwnedSlice(),
+        .confidence = confidence,
+    };
+}
+
+fn pairConfidence(a: BoreholeLog, sa: Stratum, b: BoreholeLog, sb: Stratum, options: CorrelationOptions) f64 {
+    const semantic = UnitIdentifier.similarityScore(&sa.description, &sb.description);
+    if (semantic == 0) return 0; // Different material types never correlate
+
+    const depth = depthSimilarity(a, sa, b, sb, options.depth_tolerance);
+    return options.semantic_weight * semantic + (1.0 - options.semantic_weight) * depth;
+}
+
+/// Compare by elevation when both ground levels are known, otherwise by depth
+fn depthSimilarity(a: BoreholeLog, sa: Stratum, b: BoreholeLog, sb: Stratum, tolerance: f64) f64 {
+    const level_a = a.elevationAt(sa.midDepth()) orelse sa.midDepth();
+    const level_b = b.elevationAt(sb.midDepth()) orelse sb.midDepth();
+    const both_known = a.ground_level != null and b.ground_level != null;
+
+    const diff = if (both_known) @abs(level_a - level_b) else @abs(sa.midDepth() - sb.midDepth());
+    if (tolerance <= 0) return if (diff == 0) 1.0 else 0.0;
+    return @max(0.0, 1.0 - diff / tolerance);
+}
+
+test "correlate identical sequences" {
+    const allocator = std.testing.allocator;
+
+    const strata_a = [_]Stratum{
+        .{ .depth_top = 0.0, .depth_bottom = 2.0, .description = .{ .raw_description = "Firm CLAY", .material_type = .soil, .primary_soil_type = .clay, .consistency = .firm } },
+        .{ .depth_top = 2.0, .depth_bottom = 5.0, .description = .{ .raw_description = "Dense SAND", .material_type = .soil, .primary_soil_type = .sand, .density = .dense } },
+        .{ .depth_top = 5.0, .depth_bottom = 9.0, .description = .{ .raw_description = "Strong LIMESTONE", .material_type = .rock, .primary_rock_type = .limestone, .rock_strength = .strong } },
+    };
+    const strata_b = [_]Stratum{
+        .{ .depth_top = 0.0, .depth_bottom = 2.5, .description = .{ .raw_description = "Stiff CLAY", .material_type = .soil, .primary_soil_type = .clay, .consistency = .stiff } },
+        .{ .depth_top = 2.5, .depth_bottom = 5.5, .description = .{ .raw_description = "Dense SAND", .material_type = .soil, .primary_soil_type = .sand, .density = .dense } },
+        .{ .depth_top = 5.5, .depth_bottom = 10.0, .description = .{ .raw_description = "Strong LIMESTONE", .material_type = .rock, .primary_rock_type = .limestone, .rock_strength = .strong } },
+    };
+
+    var result = try correlate(allocator, .{ .id = "BH01", .strata = &strata_a }, .{ .id = "BH02", .strata = &strata_b });
+    defer result.deinit(allocator);
+
+    try std.testing.expectEqual(@as(usize, 3), result.pairs.len);
+    for (result.pairs, 0..) |pair, idx| {
+        try std.testing.expectEqual(idx, pair.a_index);
+        try std.testing.expectEqual(idx, pair.b_index);
+    }
+    try std.testing.expectEqual(@as(usize, 0), result.unmatched_a.len);
+    try std.testing.expect(result.confidence > 0.7);
+}
+
+test "correlate with pinched out stratum" {
+    const allocator = std.testing.allocator;
+
+    const strata_a = [_]Stratum{
+        .{ .depth_top = 0.0, .depth_bottom = 2.0, .description = .{ .raw_description = "Firm CLAY", .material_type = .soil, .primary_soil_type = .clay, .consistency = .firm } },
+        .{ .depth_top = 2.0, .depth_bottom = 3.0, .description = .{ .raw_description = "Loose GRAVEL", .material_type = .soil, .primary_soil_type = .gravel, .density = .loose } },
+        .{ .depth_top = 3.0, .depth_bottom = 8.0, .description = .{ .raw_description = "Weak MUDSTONE", .material_type = .rock, .primary_rock_type = .mudstone, .rock_strength = .weak } },
+    };
+    const strata_b = [_]Stratum{
+        .{ .depth_top = 0.0, .depth_bottom = 2.2, .description = .{ .raw_description = "Firm CLAY", .material_type = .soil, .primary_soil_type = .clay, .consistency = .firm } },
+        .{ .depth_top = 2.2, .depth_bottom = 7.0, .description = .{ .raw_description = "Weak MUDSTONE", .material_type = .rock, .primary_rock_type = .mudstone, .rock_strength = .weak } },
+    };
+
+    var result = try correlate(allocator, .{ .id = "BH01", .strata = &strata_a }, .{ .id = "BH02", .strata = &strata_b });
+    defer result.deinit(allocator);
+
+    try std.testing.expectEqual(@as(usize, 2), result.pairs.len);
+    try std.testing.expectEqual(@as(usize, 1), result.unmatched_a.len);
+    try std.testing.expectEqual(@as(usize, 1), result.unmatched_a[0]);
+    try std.testing.expectEqual(@as(usize, 2), result.pairs[1].a_index);
+    try std.testing.expectEqual(@as(usize, 1), result.pairs[1].b_index);
+}
+
+test "correlate empty logs" {
+    const allocator = std.testing.allocator;
+
+    var result = try correlate(allocator, .{ .id = "BH01", .strata = &.{} }, .{ .id = "BH02", .strata = &.{} });
+    defer result.deinit(allocator);
+
+    try std.testing.expectEqual(@as(usize, 0), result.pairs.len);
+    try std.testing.expectEqual(@as(f64, 0), result.confidence);
+}
@@ -0,0 +1,227 @@
+const std = @import("std");
+const types = @import("types.zig");
+
+const GeologicalDescription = types.GeologicalDescription;
+const SoilType = types.SoilType;
+
+/// Soil behaviour type zone of the Robertson (1990) CPT chart
+pub const SbtZone = enum(u4) {
+    sensitive_fine_grained = 1,
+    organic = 2,
+    clay = 3,
+    silt_mixture = 4,
+    sand_mixture = 5,
+    sand = 6,
+    gravelly_sand = 7,
+    very_stiff_sand = 8,
+    very_stiff_fine_grained = 9,
+
+    pub fn number(self: SbtZone) u4 {
+        return @intFromEnum(self);
+    }
+
+    pub fn toString(self: SbtZone) []const u8 {
+        return switch (self) {
+            .sensitive_fine_grained => "sensitive fine grained",
+            .organic => "organic soils - clay",
+            .clay => "clays - silty clay to clay",
+            .silt_mixture => "silt mixtures - clayey silt to silty clay",
+            .sand_mixture => "sand mixtures - silty sand to sandy silt",
+            .sand => "sands - clean sand to silty sand",
+            .gravelly_sand => "gravelly sand to dense sand",
+            .very_stiff_sand => "very stiff sand to clayey sand",
+            .very_stiff_fine_grained => "very stiff fine grained",
+        };
+    }
+
+    /// The Ic band of zones 2 to 7. Zones 1, 8 and 9 are set by friction
+    /// ratio and normalised resistance, not by Ic.
+    pub fn icRange(self: SbtZone) ?IcRange {
+        return switch (self) {
+            .organic => .{ .min = 3.60, .max = std.math.inf(f32) },
+            .clay => .{ .min = 2.95, .max = 3.60 },
+            .silt_mixture => .{ .min = 2.60, .max = 2.95 },
+            .sand_mixture => .{ .min = 2.05, .max = 2.60 },
+            .sand => .{ .min = 1.31, .max = 2.05 },
+            .gravelly_sand => .{ .min = 0, .max = 1.31 },
+            .sensitive_fine_grained, .very_stiff_sand, .very_stiff_fine_grained => null,
+        };
+    }
+
+    /// The zone with an Ic band that holds `ic`
+    pub fn fromIc(ic: f32) SbtZone {
+        if (ic < 1.31) return .gravelly_sand;
+        if (ic < 2.05) return .sand;
+        if (ic < 2.60) return .sand_mixture;
+        if (ic < 2.95) return .silt_mixture;
+        if (ic < 3.60) return .clay;
+        return .organic;
+    }
+
+    /// The nearest zone with an Ic band, for comparing zones 1, 8 and 9
+    /// against a measured Ic
+    pub fn onIcScale(self: SbtZone) SbtZone {
+        return switch (self) {
+            .sensitive_fine_grained, .very_stiff_fine_grained => .clay,
+            .very_stiff_sand => .sand_mixture,
+            else => self,
+        };
+    }
+
+    /// Primary soil types a borehole log would give for the zone
+    pub fn soilTypes(self: SbtZone) []const SoilType {
+        return switch (self) {
+            .sensitive_fine_grained, .very_stiff_fine_grained => &.{ .clay, .silt },
+            .organic => &.{ .peat, .organic, .clay },
+            .clay => &.{.clay},
+            .silt_mixture => &.{ .silt, .clay },
+            .sand_mixture => &.{ .sand, .silt },
+            .sand, .very_stiff_sand => &.{.sand},
+            .gravelly_sand => &.{ .sand, .gravel },
+        };
+    }
+};
+
+/// Ic from `min` up to but not including `max`
+pub const IcRange = struct {
+    min: f32,
+    max: f32,
+
+    pub fn contains(self: IcRange, ic: f32) bool {
+        return ic >= self.min and ic < self.max;
+    }
+};
+
+/// How a described stratum compares with the CPT beside it
+pub const Agreement = enum {
+    consistent,
+    /// One zone apart, as is common at zone boundaries
+    adjacent,
+    conflicting,
+
+    pub fn toString(self: Agreement) []const u8 {
+        return @tagName(self);
+    }
+};
+
+/// The soil behaviour type a CPT would most likely report for a described
+/// soil. Only approximate: a CPT measures behaviour, not grading, so the
+/// zone comes from the primary type, the main secondary constituents,
+/// stiffness or density and sensitivity. Null for rock and for made ground
+/// with no soil type.
+pub fn zoneOf(desc: GeologicalDescription) ?SbtZone {
+    if (desc.material_type != .soil) return null;
+    const primary = desc.primary_soil_type orelse return null;
+
+    const fine = primary == .clay or primary == .silt;
+    if (fine) {
+        if (desc.sensitivity) |sensitivity| {
+            if (sensitivity == .high or sensitivity == .brittle or sensitivity == .quick) return .sensitive_fine_grained;
+        }
+    }
+
+    return switch (primary) {
+        .peat, .organic => .organic,
+        .clay => if (isVeryStiff(desc))
+            .very_stiff_fine_grained
+        else if (hasMajor(desc, "sandy") or hasMajor(desc, "silty"))
+            .silt_mixture
+        else
+            .clay,
+        .silt => if (isVeryStiff(desc))
+            .very_stiff_fine_grained
+        else if (hasMajor(desc, "sandy"))
+            .sand_mixture
+        else
+            .silt_mixture,
+        .sand => if (hasMajor(desc, "gravelly"))
+            .gravelly_sand
+        else if (hasMajor(desc, "clayey") and isVeryDense(desc))
+            .very_stiff_sand
+        else if (hasMajor(desc, "silty") or hasMajor(desc, "clayey"))
+            .sand_mixture
+        else
+            .sand,
+        .gravel, .cobbles, .boulders => .gravelly_sand,
+    };
+}
+
+/// The Ic band expected for a described soil
+pub fn icRangeOf(desc: GeologicalDescription) ?IcRange {
+    const zone = zoneOf(desc) orelse return null;
+    return zone.onIcScale().icRange();
+}
+
+/// Compare a described soil with the Ic measured beside it. Null when the
+/// description has no soil behaviour type.
+pub fn compare(desc: GeologicalDescription, ic: f32) ?Agreement {
+    const described = (zoneOf(desc) orelse return null).onIcScale().number();
+    const measured = SbtZone.fromIc(ic).number();
+    const apart = if (described > measured) described - measured else measured - described;
+    return switch (apart) {
+        0 => .consistent,
+        1 => .adjacent,
+        else => .conflicting,
+    };
+}
+
+/// A secondary constituent at more than "slightly" ("sandy", "very sandy")
+fn hasMajor(desc: GeologicalDescription, adjective: []const u8) bool {
+    for (desc.secondary_constituents) |constituent| {
+        if (!std.ascii.eqlIgnoreCase(constituent.soil_type, adjective)) continue;
+        if (!std.ascii.eqlIgnoreCase(constituent.amount, "slightly")) return true;
+    }
+    return false;
+}
+
+fn isVeryStiff(desc: GeologicalDescription) bool {
+    const consistency = desc.consistency orelse return false;
+    return consistency == .very_stiff or consistency == .hard or consistency == .stiff_to_very_stiff;
+}
+
+fn isVeryDense(desc: GeologicalDescription) bool {
+    const density = desc.density orelse return false;
+    return density == .very_dense;
+}
+
+test "map described soils to behaviour type zones" {
+    const clay = GeologicalDescription{ .raw_description = "Firm CLAY", .material_type = .soil, .consistency = .firm, .primary_soil_type = .clay };
+    try std.testing.expectEqual(SbtZone.clay, zoneOf(clay).?);
+
+    const sandy_clay = GeologicalDescription{
+        .raw_description = "Firm sandy CLAY",
+        .material_type = .soil,
+        .consistency = .firm,
+        .primary_soil_type = .clay,
+        .secondary_constituents = &.{.{ .amount = "moderately", .soil_type = "sandy" }},
+    };
+    try std.testing.expectEqual(SbtZone.silt_mixture, zoneOf(sandy_clay).?);
+
+    const slightly_sandy = GeologicalDescription{
+        .raw_description = "Firm slightly sandy CLAY",
+        .material_type = .soil,
+        .primary_soil_type = .clay,
+        .secondary_constituents = &.{.{ .amount = "slightly", .soil_type = "sandy" }},
+    };
+    try std.testing.expectEqual(SbtZone.clay, zoneOf(slightly_sandy).?);
+
+    const stiff = GeologicalDescription{ .raw_description = "Hard CLAY", .material_type = .soil, .consistency = .hard, .primary_soil_type = .clay };
+    try std.testing.expectEqual(SbtZone.very_stiff_fine_grained, zoneOf(stiff).?);
+    try std.testing.expect(icRangeOf(stiff).?.contains(3.2));
+
+    const sand = GeologicalDescription{ .raw_description = "Dense SAND", .material_type = .soil, .density = .dense, .primary_soil_type = .sand };
+    try std.testing.expectEqual(SbtZone.sand, zoneOf(sand).?);
+    try std.testing.expect(zoneOf(.{ .raw_description = "Strong LIMESTONE", .material_type = .rock, .primary_rock_type = .limestone }) == null);
+}
+
+test "compare a description with a measured Ic" {
+    const sand = GeologicalDescription{ .raw_description = "Dense SAND", .material_type = .soil, .primary_soil_type = .sand };
+    try std.testing.expectEqual(Agreement.consistent, compare(sand, 1.8).?);
+    try std.testing.expectEqual(Agreement.adjacent, compare(sand, 2.3).?);
+    try std.testing.expectEqual(Agreement.conflicting, compare(sand, 3.2).?);
+
+    try std.testing.expectEqual(SbtZone.organic, SbtZone.fromIc(3.7));
+    try std.testing.expectEqual(SbtZone.gravelly_sand, SbtZone.fromIc(1.0));
+    try std.testing.expect(SbtZone.clay.icRange().?.contains(2.95));
+    try std.testing.expect(!SbtZone.clay.icRange().?.contains(3.60));
+}
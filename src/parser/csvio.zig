@@ -0,0 +1,250 @@
+const std = @import("std");
+const types = @import("types.zig");
+
+const GeologicalDescription = types.GeologicalDescription;
+
+/// How secondary constituents are laid out
+pub const ConstituentLayout = enum {
+    joined, // one row per description, constituents joined into one cell
+    exploded, // one row per constituent, other columns repeated
+
+    pub fn fromString(str: []const u8) ?ConstituentLayout {
+        return std.meta.stringToEnum(ConstituentLayout, str);
+    }
+};
+
+pub const default_columns = [_][]const u8{
+    "raw_description",
+    "material_type",
+    "consistency",
+    "density",
+    "primary_soil_type",
+    "secondary_constituents",
+    "rock_strength",
+    "weathering_grade",
+    "primary_rock_type",
+    "strength_parameters.range.lower_bound",
+    "strength_parameters.range.upper_bound",
+    "confidence",
+    "is_valid",
+};
+
+pub const Options = struct {
+    /// Field paths, one per column. Nested fields use dots
+    /// ("strength_parameters.range.typical_value"); enums are written by name
+    /// and missing values as empty cells.
+    columns: []const []const u8 = &default_columns,
+    constituents: ConstituentLayout = .joined,
+    /// Between joined list items
+    separator: []const u8 = "; ",
+    header: bool = true,
+};
+
+const ResolveError = error{ UnknownColumn, OutOfMemory };
+
+/// Flattens parsed descriptions into CSV rows
+pub const Writer = struct {
+    allocator: std.mem.Allocator,
+    options: Options,
+    header_written: bool = false,
+
+    pub fn init(allocator: std.mem.Allocator, options: Options) Writer {
+        return Writer{ .allocator = allocator, .options = options };
+    }
+
+    /// Check every column path before writing anything
+    pub fn validate(self: *const Writer) !void {
+        const empty = GeologicalDescription{ .raw_description = "", .material_type = .soil };
+        var cell = std.ArrayList(u8).init(self.allocator);
+        defer cell.deinit();
+        for (self.options.columns) |column| {
+            cell.clearRetainingCapacity();
+            try resolve(&cell, empty, column, self.options, null);
+        }
+    }
+
+    /// Write the row(s) for one description, preceded by the header on the
+    /// first call if enabled
+    pub fn write(self: *Writer, writer: anytype, desc: *const GeologicalDescription) !void {
+        if (self.options.header and !self.header_written) {
+            for (self.options.columns, 0..) |column, i| {
+                if (i > 0) try writer.writeByte(',');
+                try writeCsvField(writer, column);
+            }
+            try writer.writeByte('\n');
+        }
+        self.header_written = true;
+
+        var cell = std.ArrayList(u8).init(self.allocator);
+        defer cell.deinit();
+
+        if (self.options.constituents == .joined or desc.secondary_constituents.len == 0) {
+            return self.writeRow(writer, desc, &cell, null);
+        }
+        for (0..desc.secondary_constituents.len) |index| {
+            try self.writeRow(writer, desc, &cell, index);
+        }
+    }
+
+    pub fn writeAll(self: *Writer, writer: anytype, descs: []const GeologicalDescription) !void {
+        for (descs) |*desc| try self.write(writer, desc);
+    }
+
+    fn writeRow(self: *Writer, writer: anytype, desc: *const GeologicalDescription, cell: *std.ArrayList(u8), constituent: ?usize) !void {
+        for (self.options.columns, 0..) |column, i| {
+            if (i > 0) try writer.writeByte(',');
+            cell.clearRetainingCapacity();
+            try resolve(cell, desc.*, column, self.options, constituent);
+            try writeCsvField(writer, cell.items);
+        }
+        try writer.writeByte('\n');
+    }
+};
+
+/// Append one column's value for `desc` to `cell`, as it would appear in a
+/// joined CSV row; for other tabular exports
+pub fn resolveColumn(cell: *std.ArrayList(u8), desc: *const GeologicalDescription, column: []const u8, options: Options) !void {
+    return resolve(cell, desc.*, column, options, null);
+}
+
+/// Append the value at `path` within `value` to `cell`. `constituent` picks a
+/// single secondary constituent when rows are exploded.
+fn resolve(cell: *std.ArrayList(u8), value: anytype, path: []const u8, options: Options, constituent: ?usize) ResolveError!void {
+    const T = @TypeOf(value);
+    switch (@typeInfo(T)) {
+        .optional => {
+            if (value) |inner| return resolve(cell, inner, path, options, constituent);
+            // Still reject a bad path below a null field
+            const Child = @typeInfo(T).optional.child;
+            if (path.len > 0 and !hasPath(Child, path)) return error.UnknownColumn;
+        },
+        .@"struct" => |info| {
+            if (path.len == 0) {
+                if (comptime @hasDecl(T, "toString") and @typeInfo(@TypeOf(T.toString)).@"fn".params.len == 2) {
+                    const text = value.toString(cell.allocator) catch return error.OutOfMemory;
+                    defer cell.allocator.free(text);
+                    return cell.appendSlice(text);
+                }
+                return error.UnknownColumn;
+            }
+            const dot = std.mem.indexOfScalar(u8, path, '.') orelse path.len;
+            const head = path[0..dot];
+            const rest = if (dot < path.len) path[dot + 1 ..] else "";
+            inline for (info.fields) |field| {
+                if (std.mem.eql(u8, field.name, head)) {
+                    const child = @field(value, field.name);
+                    if (comptime std.mem.eql(u8, field.name, "secondary_constituents")) {
+                        if (constituent) |index| {
+                            if (index < child.len) return resolve(cell, child[index], rest, options, null);
+                            return;
+                        }
+                    }
+                    return resolve(cell, child, rest, options, constituent);
+                }
+            }
+            return error.UnknownColumn;
+        },
+        .pointer => |info| {
+            if (info.size == .slice) {
+                if (info.child == u8) {
+                    if (path.len > 0) return error.UnknownColumn;
+                    return cell.appendSlice(value);
+                }
+                if (value.len == 0 and path.len > 0 and !hasPath(info.child, path)) return error.UnknownColumn;
+                for (value, 0..) |item, i| {
+                    if (i > 0) try cell.appendSlice(options.separator);
+                    try resolve(cell, item, path, options, constituent);
+                }
+                return;
+            }
+            if (info.size == .one and @typeInfo(info.child) != .@"opaque") return resolve(cell, value.*, path, options, constituent);
+            return error.UnknownColumn;
+        },
+        .@"enum" => {
+            if (path.len > 0) return error.UnknownColumn;
+            if (comptime @hasDecl(T, "toString") and @TypeOf(T.toString) == fn (T) []const u8) {
+                return cell.appendSlice(value.toString());
+            }
+            return cell.appendSlice(@tagName(value));
+        },
+        .bool => {
+            if (path.len > 0) return error.UnknownColumn;
+            return cell.appendSlice(if (value) "true" else "false");
+        },
+        .int, .float => {
+            if (path.len > 0) return error.UnknownColumn;
+            return cell.writer().print("{d}", .{value});
+        },
+        else => return error.UnknownColumn,
+    }
+}
+
+/// Whether `path` names a field of `T`, for values that are absent
+fn hasPath(comptime T: type, path: []const u8) bool {
+    switch (@typeInfo(T)) {
+        .optional => |info| return hasPath(info.child, path),
+        .@"struct" => |info| {
+            if (path.len == 0) return true;
+            const dot = std.mem.indexOfScalar(u8, path, '.') orelse path.len;
+            const rest = if (dot < path.len) path[dot + 1 ..] else "";
+            inline for (info.fields) |field| {
+                if (std.mem.eql(u8, field.name, path[0..dot])) return hasPath(field.type, rest);
+            }
+            return false;
+        },
+        .pointer => |info| {
+            if (info.size == .slice and info.child != u8) return hasPath(info.child, path);
+            return path.len == 0;
+        },
+        else => return path.len == 0,
+    }
+}
+
+fn writeCsvField(writer: anytype, value: []const u8) !void {
+    const needs_quotes = std.mem.indexOfAny(u8, value, ",\"\n\r") != null;
+    if (!needs_quotes) return writer.writeAll(value);
+
+    try writer.writeByte('"');
+    for (value) |ch| {
+        if (ch == '"') try writer.writeByte('"');
+        try writer.writeByte(ch);
+    }
+    try writer.writeByte('"');
+}
+
+test "csv writer joins or explodes secondary constituents" {
+    const allocator = std.testing.allocator;
+
+    var constituents = [_]types.SecondaryConstituent{
+        .{ .amount = "slightly", .soil_type = "sandy" },
+        .{ .amount = "very", .soil_type = "gravelly" },
+    };
+    const desc = GeologicalDescription{
+        .raw_description = "Firm slightly sandy very gravelly CLAY",
+        .material_type = .soil,
+        .consistency = .firm,
+        .primary_soil_type = .clay,
+        .secondary_constituents = &constituents,
+    };
+    const columns = [_][]const u8{ "consistency", "secondary_constituents", "secondary_constituents.soil_type", "strength_parameters.range.lower_bound" };
+
+    var joined = std.ArrayList(u8).init(allocator);
+    defer joined.deinit();
+    var csv = Writer.init(allocator, .{ .columns = &columns });
+    try csv.validate();
+    try csv.write(joined.writer(), &desc);
+    try std.testing.expectEqualStrings(
+        "consistency,secondary_constituents,secondary_constituents.soil_type,strength_parameters.range.lower_bound\n" ++
+            "firm,slightly sandy; very gravelly,sandy; gravelly,\n",
+        joined.items,
+    );
+
+    var exploded = std.ArrayList(u8).init(allocator);
+    defer exploded.deinit();
+    var rows = Writer.init(allocator, .{ .columns = columns[0..3], .constituents = .exploded, .header = false });
+    try rows.write(exploded.writer(), &desc);
+    try std.testing.expectEqualStrings("firm,slightly sandy,sandy\nfirm,very gravelly,gravelly\n", exploded.items);
+
+    var bad = Writer.init(allocator, .{ .columns = &.{"consistency.name"} });
+    try std.testing.expectError(error.UnknownColumn, bad.validate());
+}
@@ -0,0 +1,30 @@
+const std = @import("std");
+
+/// A time limit on one parse, checked between stages and between words so a
+/// pathological description gives up with error.ParseTimeout instead of
+/// holding a batch worker
+pub const Deadline = struct {
+    started: std.time.Instant,
+    limit_ns: u64,
+
+    /// Null when the platform has no monotonic clock, in which case nothing
+    /// times out
+    pub fn start(limit_ns: u64) ?Deadline {
+        const now = std.time.Instant.now() catch return null;
+        return Deadline{ .started = now, .limit_ns = limit_ns };
+    }
+
+    pub fn check(self: Deadline) error{ParseTimeout}!void {
+        const now = std.time.Instant.now() catch return;
+        if (now.since(self.started) > self.limit_ns) return error.ParseTimeout;
+    }
+};
+
+test "deadline expires after its limit" {
+    const generous = Deadline.start(std.time.ns_per_hour) orelse return error.SkipZigTest;
+    try generous.check();
+
+    const expired = Deadline.start(0) orelse return error.SkipZigTest;
+    std.time.sleep(std.time.ns_per_ms);
+    try std.testing.expectError(error.ParseTimeout, expired.check());
+}
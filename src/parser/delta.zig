@@ -0,0 +1,217 @@
+const std = @import("std");
+const types = @import("types.zig");
+const bs5930 = @import("bs5930.zig");
+
+const GeologicalDescription = types.GeologicalDescription;
+const Parser = bs5930.Parser;
+
+/// A source row: a stable key (database id, "BH01/2.50") and its text
+pub const Record = struct {
+    id: []const u8,
+    description: []const u8,
+};
+
+pub const Change = enum {
+    added,
+    changed,
+    unchanged,
+
+    pub fn toString(self: Change) []const u8 {
+        return @tagName(self);
+    }
+};
+
+/// Parse results from a previous run, keyed by record id
+pub const Snapshot = struct {
+    allocator: std.mem.Allocator,
+    entries: std.StringHashMap(Entry),
+
+    pub const Entry = struct {
+        /// Hash of the description text the result was parsed from
+        fingerprint: u64,
+        description: GeologicalDescription,
+    };
+
+    /// An empty snapshot, for the first run
+    pub fn init(allocator: std.mem.Allocator) Snapshot {
+        return Snapshot{
+            .allocator = allocator,
+            .entries = std.StringHashMap(Entry).init(allocator),
+        };
+    }
+
+    pub fn deinit(self: *Snapshot) void {
+        var it = self.entries.iterator();
+        while (it.next()) |item| {
+            self.allocator.free(item.key_ptr.*);
+            item.value_ptr.description.deinit(self.allocator);
+        }
+        self.entries.deinit();
+    }
+
+    pub fn get(self: *const Snapshot, id: []const u8) ?*const GeologicalDescription {
+        const entry = self.entries.getPtr(id) orelse return null;
+        return &entry.description;
+    }
+};
+
+/// One current record and its result, fresh or carried over
+pub const Row = struct {
+    id: []const u8,
+    change: Change,
+    description: *const GeologicalDescription,
+};
+
+pub const Delta = struct {
+    /// Results for this run; pass it as `previous` next time
+    snapshot: Snapshot,
+    /// One row per current record, in input order, borrowing from `snapshot`
+    rows: []Row,
+    /// Ids in the previous snapshot that are no longer present, sorted
+    removed: [][]const u8,
+    /// How many descriptions were actually parsed
+    parsed: usize,
+
+    pub fn deinit(self: *Delta) void {
+        const allocator = self.snapshot.allocator;
+        allocator.free(self.rows);
+        for (self.removed) |id| allocator.free(id);
+        allocator.free(self.removed);
+        self.snapshot.deinit();
+    }
+};
+
+fn idLessThan(_: void, a: []const u8, b: []const u8) bool {
+    return std.mem.lessThan(u8, a, b);
+}
+
+fn fingerprintOf(text: []const u8) u64 {
+    return std.hash.Wyhash.hash(0, text);
+}
+
+/// Parse only the records that are new or whose text changed since
+/// `previous`, reusing earlier results for the rest. `previous` is emptied
+/// into the returned snapshot on success and left untouched on error.
+pub fn processDelta(parser: *Parser, previous: *Snapshot, current: []const Record) !Delta {
+    const allocator = parser.allocator;
+
+    var next = Snapshot.init(allocator);
+    errdefer next.deinit();
+    try next.entries.ensureTotalCapacity(@intCast(current.len));
+
+    const changes = try allocator.alloc(Change, current.len);
+    defer allocator.free(changes);
+
+    var current_ids = std.StringHashMap(void).init(allocator);
+    defer current_ids.deinit();
+    try current_ids.ensureTotalCapacity(@intCast(current.len));
+
+    // Parse what is new or changed; nothing leaves `previous` yet
+    var parsed: usize = 0;
+    for (current, 0..) |record, i| {
+        if (current_ids.getOrPutAssumeCapacity(record.id).found_existing) return error.DuplicateId;
+
+        const fingerprint = fingerprintOf(record.description);
+        if (previous.entries.get(record.id)) |old| {
+            if (old.fingerprint == fingerprint) {
+                changes[i] = .unchanged;
+                continue;
+            }
+            changes[i] = .changed;
+        } else {
+            changes[i] = .added;
+        }
+
+        const description = try parser.parse(record.description);
+        const id = allocator.dupe(u8, record.id) catch |err| {
+            description.deinit(allocator);
+            return err;
+        };
+        next.entries.putAssumeCapacityNoClobber(id, .{ .fingerprint = fingerprint, .description = description });
+        parsed += 1;
+    }
+
+    const rows = try allocator.alloc(Row, current.len);
+    errdefer allocator.free(rows);
+
+    var removed = std.ArrayList([]const u8).init(allocator);
+    errdefer {
+        for (removed.items) |id| allocator.free(id);
+        removed.deinit();
+    }
+    var it = previous.entries.keyIterator();
+    while (it.next()) |key| {
+        if (!current_ids.contains(key.*)) {
+            const id = try allocator.dupe(u8, key.*);
+            removed.append(id) catch |err| {
+                allocator.free(id);
+                return err;
+            };
+        }
+    }
+    std.mem.sort([]const u8, removed.items, {}, idLessThan);
+    const removed_ids = try removed.toOwnedSlice();
+
+    // Nothing below can fail: carry unchanged results over from `previous`
+    for (current, changes) |record, change| {
+        if (change != .unchanged) continue;
+        const moved = previous.entries.fetchRemove(record.id).?;
+        next.entries.putAssumeCapacityNoClobber(moved.key, moved.value);
+    }
+    previous.deinit();
+    previous.* = Snapshot.init(allocator);
+
+    for (current, changes, 0..) |record, change, i| {
+        const entry = next.entries.getEntry(record.id).?;
+        rows[i] = Row{
+            .id = entry.key_ptr.*,
+            .change = change,
+            .description = &entry.value_ptr.description,
+        };
+    }
+
+    return Delta{
+        .snapshot = next,
+        .rows = rows,
+        .removed = removed_ids,
+        .parsed = parsed,
+    };
+}
+
+test "delta only parses new and changed records" {
+    const allocator = std.testing.allocator;
+    var parser = Parser.init(allocator);
+
+    var snapshot = Snapshot.init(allocator);
+    defer snapshot.deinit();
+
+    var first = try processDelta(&parser, &snapshot, &.{
+        .{ .id = "1", .description = "Firm CLAY" },
+        .{ .id = "2", .description = "Dense SAND" },
+        .{ .id = "3", .description = "Strong LIMESTONE" },
+    });
+    try std.testing.expectEqual(@as(usize, 3), first.parsed);
+
+    // The next run takes the first run's snapshot
+    snapshot.deinit();
+    snapshot = first.snapshot;
+    first.snapshot = Snapshot.init(allocator);
+    first.deinit();
+
+    var second = try processDelta(&parser, &snapshot, &.{
+        .{ .id = "1", .description = "Firm CLAY" },
+        .{ .id = "2", .description = "Very dense SAND" },
+        .{ .id = "4", .description = "Soft PEAT" },
+    });
+    defer second.deinit();
+
+    try std.testing.expectEqual(@as(usize, 2), second.parsed);
+    try std.testing.expectEqual(Change.unchanged, second.rows[0].change);
+    try std.testing.expectEqual(types.Consistency.firm, second.rows[0].description.consistency.?);
+    try std.testing.expectEqual(Change.changed, second.rows[1].change);
+    try std.testing.expectEqual(types.Density.very_dense, second.rows[1].description.density.?);
+    try std.testing.expectEqual(Change.added, second.rows[2].change);
+    try std.testing.expectEqual(@as(usize, 1), second.removed.len);
+    try std.testing.expectEqualStrings("3", second.removed[0]);
+    try std.testing.expectEqual(@as(usize, 0), snapshot.entries.count());
+}
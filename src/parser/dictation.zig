@@ -0,0 +1,167 @@
+const std = @import("std");
+const lexer = @import("lexer.zig");
+
+const Lexer = lexer.Lexer;
+const Token = lexer.Token;
+
+/// Position of each kind of term in a standard BS 5930 description
+const Slot = enum {
+    strength, // consistency, density or rock strength
+    weathering,
+    structure,
+    moisture,
+    plasticity,
+    colour,
+    constituent,
+    particle_size,
+    primary,
+    tail, // "with rare gravel", unrecognised words
+};
+
+const Case = enum { lower, upper };
+
+const Piece = struct {
+    slot: Slot,
+    text: []const u8,
+    case: Case = .lower,
+};
+
+/// Reorder and capitalise a dictated description into standard form, e.g.
+/// "firm to stiff clay slightly sandy brown" -> "Firm to stiff brown slightly sandy CLAY".
+/// Terms keep their relative order within a slot; anything from "with" onwards
+/// is kept verbatim as trailing detail.
+pub fn normalize(allocator: std.mem.Allocator, dictated: []const u8) ![]u8 {
+    const trimmed = std.mem.trim(u8, dictated, " \t\r\n.");
+
+    var lex = Lexer.init(allocator, trimmed);
+    defer lex.deinit();
+
+    const tokens = try lex.tokenize();
+    defer {
+        for (tokens) |token| {
+            if (token.corrected_from) |_| allocator.free(token.value);
+        }
+        allocator.free(tokens);
+    }
+
+    var pieces = std.ArrayList(Piece).init(allocator);
+    defer pieces.deinit();
+
+    var in_tail = false;
+    var i: usize = 0;
+    while (i < tokens.len) : (i += 1) {
+        const token = tokens[i];
+        const next: ?Token = if (i + 1 < tokens.len) tokens[i + 1] else null;
+
+        if (in_tail) {
+            try pieces.append(.{ .slot = .tail, .text = token.value });
+            continue;
+        }
+
+        if (token.type == .word and std.ascii.eqlIgnoreCase(token.value, "with")) {
+            in_tail = true;
+            try pieces.append(.{ .slot = .tail, .text = token.value });
+            continue;
+        }
+
+        if (token.type == .word and std.ascii.eqlIgnoreCase(token.value, "and")) {
+            if (next) |n| {
+                // "SAND and GRAVEL" or "slightly sandy and gravelly"
+                const slot: ?Slot = switch (n.type) {
+                    .soil_type, .rock_type => .primary,
+                    .adjective, .proportion => .constituent,
+                    else => null,
+                };
+                if (slot) |s| {
+                    try pieces.append(.{ .slot = s, .text = token.value });
+                    continue;
+                }
+            }
+        }
+
+        if (token.type == .proportion) {
+            if (next) |n| {
+                if (n.type == .adjective or n.type == .soil_type) {
+                    try pieces.append(.{ .slot = .constituent, .text = token.value });
+                    try pieces.append(.{ .slot = .constituent, .text = n.value });
+                    i += 1;
+                    continue;
+                }
+            }
+        }
+
+        const slot = slotFor(token);
+        try pieces.append(.{
+            .slot = slot,
+            .text = token.value,
+            .case = if (slot == .primary) .upper else .lower,
+        });
+    }
+
+    std.sort.insertion(Piece, pieces.items, {}, pieceLessThan);
+
+    var output = std.ArrayList(u8).init(allocator);
+    errdefer output.deinit();
+
+    for (pieces.items) |piece| {
+        if (output.items.len > 0) try output.append(' ');
+        for (piece.text) |ch| {
+            try output.append(switch (piece.case) {
+                .lower => std.ascii.toLower(ch),
+                .upper => std.ascii.toUpper(ch),
+            });
+        }
+    }
+
+    if (output.items.len > 0) {
+        output.items[0] = std.ascii.toUpper(output.items[0]);
+    }
+
+    return output.toOwnedSlice();
+}
+
+fn slotFor(token: Token) Slot {
+    return switch (token.type) {
+        .consistency_range, .consistency, .density, .rock_strength => .strength,
+        .weathering_grade => .weathering,
+        .rock_structure => .structure,
+        .moisture_content => .moisture,
+        .plasticity_index => .plasticity,
+        .color => .colour,
+        .adjective, .proportion => .constituent,
+        .particle_size => .particle_size,
+        .soil_type, .rock_type => .primary,
+        .word, .unknown => .tail,
+    };
+}
+
+fn pieceLessThan(_: void, a: Piece, b: Piece) bool {
+    return @intFromEnum(a.slot) < @intFromEnum(b.slot);
+}
+
+test "normalize dictated soil description" {
+    const allocator = std.testing.allocator;
+
+    const result = try normalize(allocator, "firm to stiff clay slightly sandy brown");
+    defer allocator.free(result);
+
+    try std.testing.expectEqualStrings("Firm to stiff brown slightly sandy CLAY", result);
+}
+
+test "normalize dictated description keeps trailing detail" {
+    const allocator = std.testing.allocator;
+
+    const result = try normalize(allocator, "sand and gravel dense with rare cobbles.");
+    defer allocator.free(result);
+
+    try std.testing.expectEqualStrings("Dense SAND and GRAVEL with rare cobbles", result);
+}
+
+test "normalize dictated rock description" {
+    const allocator = std.testing.allocator;
+
+    const result = try normalize(allocator, "limestone strong grey slightly weathered");
+    defer allocator.free(result);
+
+    try std.testing.expectEqualStrings("Strong slightly weathered grey LIMESTONE", result);
+}
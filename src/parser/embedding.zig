@@ -0,0 +1,198 @@
+const std = @import("std");
+const types = @import("types.zig");
+const outlier = @import("outlier.zig");
+
+const GeologicalDescription = types.GeologicalDescription;
+const SoilDescription = types.SoilDescription;
+
+// Categorical fields encoded one-hot, in vector order
+const one_hot_fields = [_]struct { name: []const u8, E: type }{
+    .{ .name = "material_type", .E = types.MaterialType },
+    .{ .name = "primary_soil_type", .E = types.SoilType },
+    .{ .name = "primary_rock_type", .E = types.RockType },
+    .{ .name = "color", .E = types.Color },
+    .{ .name = "particle_size", .E = types.ParticleSize },
+};
+
+// Ordered fields encoded as a presence flag followed by a value scaled to 0.0-1.0
+const ordinal_fields = [_][]const u8{
+    "consistency",
+    "density",
+    "rock_strength",
+    "weathering_grade",
+    "moisture_content",
+    "plasticity_index",
+};
+
+// Secondary constituent adjectives, each encoded by proportion (0, slightly, moderately, very)
+const constituent_fields = [_][]const u8{ "sandy", "silty", "clayey", "gravelly" };
+
+const scalar_fields = [_][]const u8{ "is_made_ground", "confidence" };
+
+/// Number of features produced by vectorize
+pub const feature_count: usize = blk: {
+    var count: usize = 0;
+    for (one_hot_fields) |field| count += std.enums.values(field.E).len;
+    count += ordinal_fields.len * 2;
+    count += constituent_fields.len;
+    count += scalar_fields.len;
+    break :blk count;
+};
+
+/// Feature names aligned with the vector returned by vectorize
+pub const feature_names: [feature_count][]const u8 = blk: {
+    @setEvalBranchQuota(10000);
+    var names: [feature_count][]const u8 = undefined;
+    var idx: usize = 0;
+    for (one_hot_fields) |field| {
+        for (std.meta.fieldNames(field.E)) |value_name| {
+            names[idx] = field.name ++ "." ++ value_name;
+            idx += 1;
+        }
+    }
+    for (ordinal_fields) |name| {
+        names[idx] = name ++ ".present";
+        names[idx + 1] = name ++ ".value";
+        idx += 2;
+    }
+    for (constituent_fields) |name| {
+        names[idx] = "secondary." ++ name;
+        idx += 1;
+    }
+    for (scalar_fields) |name| {
+        names[idx] = name;
+        idx += 1;
+    }
+    break :blk names;
+};
+
+/// Produce a deterministic feature vector for a parsed description.
+/// Categorical fields are one-hot encoded and ordered fields are ordinal
+/// encoded, so vectors from different runs and machines are directly comparable.
+pub fn vectorize(allocator: std.mem.Allocator, desc: *const GeologicalDescription) ![]f32 {
+    const vector = try allocator.alloc(f32, feature_count);
+    @memset(vector, 0);
+
+    var idx: usize = 0;
+    idx += oneHot(types.MaterialType, vector[idx..], desc.material_type);
+    idx += oneHot(types.SoilType, vector[idx..], desc.primary_soil_type);
+    idx += oneHot(types.RockType, vector[idx..], desc.primary_rock_type);
+    idx += oneHot(types.Color, vector[idx..], desc.color);
+    idx += oneHot(types.ParticleSize, vector[idx..], desc.particle_size);
+
+    idx += ordinal(vector[idx..], if (desc.consistency) |c| outlier.consistencyRank(c) / 5.0 else null);
+    idx += ordinal(vector[idx..], if (desc.density) |d| outlier.densityRank(d) / 4.0 else null);
+    idx += ordinal(vector[idx..], enumFraction(types.RockStrength, desc.rock_strength));
+    idx += ordinal(vector[idx..], enumFraction(types.WeatheringGrade, desc.weathering_grade));
+    idx += ordinal(vector[idx..], enumFraction(types.MoistureContent, desc.moisture_content));
+    idx += ordinal(vector[idx..], enumFraction(types.PlasticityIndex, desc.plasticity_index));
+
+    for (constituent_fields) |name| {
+        for (desc.secondary_constituents) |sc| {
+            if (std.ascii.eqlIgnoreCase(sc.soil_type, name)) {
+                vector[idx] = proportionValue(sc.amount);
+            }
+        }
+        idx += 1;
+    }
+
+    vector[idx] = if (desc.is_made_ground) 1.0 else 0.0;
+    vector[idx + 1] = desc.confidence;
+    idx += 2;
+
+    std.debug.assert(idx == feature_count);
+    return vector;
+}
+
+fn oneHot(comptime E: type, out: []f32, value: anytype) usize {
+    const count = std.enums.values(E).len;
+    const optional: ?E = value;
+    if (optional) |v| {
+        out[@intFromEnum(v)] = 1.0;
+    }
+    return count;
+}
+
+fn ordinal(out: []f32, value: ?f64) usize {
+    if (value) |v| {
+        out[0] = 1.0;
+        out[1] = @floatCast(v);
+    }
+    return 2;
+}
+
+fn enumFraction(comptime E: type, value: ?E) ?f64 {
+    const v = value orelse return null;
+    const max_index = std.enums.values(E).len - 1;
+    return @as(f64, @floatFromInt(@intFromEnum(v))) / @as(f64, @floatFromInt(max_index));
+}
+
+fn proportionValue(amount: []const u8) f32 {
+    const proportion = types.SecondaryConstituent.Proportion.fromString(amount) orelse return 0.5;
+    return switch (proportion) {
+        .slightly => 1.0 / 3.0,
+        .moderately => 2.0 / 3.0,
+        .very => 1.0,
+    };
+}
+
+fn featureIndex(name: []const u8) ?usize {
+    for (feature_names, 0..) |feature_name, i| {
+        if (std.mem.eql(u8, feature_name, name)) return i;
+    }
+    return null;
+}
+
+test "vector length matches feature names" {
+    const allocator = std.testing.allocator;
+    const desc = SoilDescription{ .raw_description = "Firm CLAY", .material_type = .soil };
+
+    const vector = try vectorize(allocator, &desc);
+    defer allocator.free(vector);
+
+    try std.testing.expectEqual(feature_count, vector.len);
+    try std.testing.expectEqual(feature_names.len, vector.len);
+}
+
+test "vectorize encodes parsed fields" {
+    const allocator = std.testing.allocator;
+    var constituents = [_]types.SecondaryConstituent{.{ .amount = "slightly", .soil_type = "sandy" }};
+    const desc = SoilDescription{
+        .raw_description = "Firm slightly sandy brown CLAY",
+        .material_type = .soil,
+        .primary_soil_type = .clay,
+        .consistency = .firm,
+        .color = .brown,
+        .secondary_constituents = &constituents,
+    };
+
+    const vector = try vectorize(allocator, &desc);
+    defer allocator.free(vector);
+
+    try std.testing.expectEqual(@as(f32, 1.0), vector[featureIndex("material_type.soil").?]);
+    try std.testing.expectEqual(@as(f32, 0.0), vector[featureIndex("material_type.rock").?]);
+    try std.testing.expectEqual(@as(f32, 1.0), vector[featureIndex("primary_soil_type.clay").?]);
+    try std.testing.expectEqual(@as(f32, 1.0), vector[featureIndex("color.brown").?]);
+    try std.testing.expectEqual(@as(f32, 1.0), vector[featureIndex("consistency.present").?]);
+    try std.testing.expectApproxEqAbs(@as(f32, 0.4), vector[featureIndex("consistency.value").?], 0.0001);
+    try std.testing.expectEqual(@as(f32, 0.0), vector[featureIndex("density.present").?]);
+    try std.testing.expectApproxEqAbs(@as(f32, 1.0 / 3.0), vector[featureIndex("secondary.sandy").?], 0.0001);
+}
+
+test "vectorize is deterministic" {
+    const allocator = std.testing.allocator;
+    const desc = SoilDescription{
+        .raw_description = "Strong slightly weathered LIMESTONE",
+        .material_type = .rock,
+        .primary_rock_type = .limestone,
+        .rock_strength = .strong,
+        .weathering_grade = .slightly_weathered,
+    };
+
+    const first = try vectorize(allocator, &desc);
+    defer allocator.free(first);
+    const second = try vectorize(allocator, &desc);
+    defer allocator.free(second);
+
+    try std.testing.expectEqualSlices(f32, first, second);
+}
@@ -0,0 +1,180 @@
+const std = @import("std");
+const types = @import("types.zig");
+
+const GeologicalDescription = types.GeologicalDescription;
+const Consistency = types.Consistency;
+const Density = types.Density;
+const RockStrength = types.RockStrength;
+const WeatheringGrade = types.WeatheringGrade;
+const SoilType = types.SoilType;
+const RockType = types.RockType;
+
+/// A plain-English explanation of each term in a description and what it
+/// means for design, one "- term: explanation" line per term. For training
+/// and client summaries. Returns caller-owned text.
+pub fn explain(desc: GeologicalDescription, allocator: std.mem.Allocator) ![]u8 {
+    var text = std.ArrayList(u8).init(allocator);
+    errdefer text.deinit();
+    const writer = text.writer();
+
+    if (desc.is_made_ground) {
+        try writer.writeAll("- Made ground: deposited by people rather than nature. Often variable and compressible; not usually relied on for foundations.\n");
+    }
+    if (desc.is_topsoil) {
+        try writer.writeAll("- Topsoil: the natural organic surface layer. Usually stripped and stockpiled before earthworks; not used as foundation or fill.\n");
+    }
+
+    if (desc.consistency) |consistency| {
+        const cu = consistency.cuRange();
+        try writer.print("- {s}: {s} Undrained shear strength (cu) is typically {d:.0}-{d:.0} kPa.\n", .{
+            consistency.toString(),
+            consistencyMeaning(consistency),
+            cu.lower_bound,
+            cu.upper_bound,
+        });
+    }
+
+    if (desc.density) |density| {
+        const spt = density.sptRange();
+        try writer.print("- {s}: {s} SPT N is typically {d:.0}-{d:.0} blows/300mm.\n", .{
+            density.toString(),
+            densityMeaning(density),
+            spt.lower_bound,
+            spt.upper_bound,
+        });
+    }
+
+    for (desc.secondary_constituents) |constituent| {
+        try writer.print("- {s} {s}: a secondary fraction; \"slightly\" means a little, no qualifier a moderate amount and \"very\" a lot. It changes drainage and strength compared with the pure soil.\n", .{ constituent.amount, constituent.soil_type });
+    }
+
+    if (desc.primary_soil_type) |soil_type| {
+        try writer.print("- {s}: {s}\n", .{ soil_type.toString(), soilMeaning(soil_type) });
+    }
+
+    if (desc.rock_strength) |strength| {
+        const ucs = strength.ucsRange();
+        try writer.print("- {s}: {s} Unconfined compressive strength is typically {d}-{d} MPa.\n", .{
+            strength.toString(),
+            strengthMeaning(strength),
+            ucs.lower_bound,
+            ucs.upper_bound,
+        });
+    }
+
+    if (desc.weathering_grade) |grade| {
+        try writer.print("- {s} (grade {s}): {s}\n", .{ grade.toString(), grade.gradeCode(), weatheringMeaning(grade) });
+    }
+
+    if (desc.primary_rock_type) |rock_type| {
+        try writer.print("- {s}: {s}\n", .{ rock_type.toString(), rockMeaning(rock_type) });
+    }
+
+    if (desc.strength_parameters) |params| {
+        if (params.intermediate_geomaterial) {
+            try writer.writeAll("- Intermediate geomaterial: strong enough to behave like weak rock, so both soil (cu) and rock (UCS) strengths are quoted.\n");
+        }
+    }
+
+    if (desc.geological_formation) |formation| {
+        try writer.print("- {s}: the geological unit; its known history and behaviour add to what the description says.\n", .{formation});
+    }
+
+    return text.toOwnedSlice();
+}
+
+fn consistencyMeaning(consistency: Consistency) []const u8 {
+    return switch (consistency) {
+        .very_soft => "exudes between the fingers when squeezed. Very low bearing capacity and large settlements.",
+        .soft => "moulded by light finger pressure. Low bearing capacity; expect significant settlement.",
+        .firm => "moulded by strong finger pressure. Adequate for light structures on shallow foundations.",
+        .stiff => "cannot be moulded by the fingers but can be indented by the thumb. Good bearing capacity.",
+        .very_stiff => "can be indented by the thumbnail. High bearing capacity; close to weak rock.",
+        .hard => "can be scratched by the thumbnail. Behaves much like weak rock.",
+        .soft_to_firm, .firm_to_stiff, .stiff_to_very_stiff => "a range between two consistencies; design to the weaker end unless tests show otherwise.",
+    };
+}
+
+fn densityMeaning(density: Density) []const u8 {
+    return switch (density) {
+        .very_loose => "very easily disturbed. Prone to settlement and, below the water table, liquefaction.",
+        .loose => "easily excavated and compressible under vibration or load.",
+        .medium_dense => "moderately packed. Usually adequate for shallow foundations.",
+        .dense => "tightly packed and hard to excavate by hand. Good bearing capacity.",
+        .very_dense => "very tightly packed. High bearing capacity and little settlement.",
+        .loose_to_medium_dense, .medium_dense_to_dense => "a range between two densities; design to the looser end unless tests show otherwise.",
+    };
+}
+
+fn strengthMeaning(strength: RockStrength) []const u8 {
+    return switch (strength) {
+        .very_weak => "crumbles under firm blows of a geological hammer and can be peeled with a knife.",
+        .weak => "can be shaped with a knife and dented by a hammer point.",
+        .moderately_weak => "can be scraped with a knife; a firm hammer blow makes shallow indentations.",
+        .moderately_strong => "cannot be scraped with a knife; breaks with one firm hammer blow.",
+        .strong => "needs more than one hammer blow to break.",
+        .very_strong => "needs many hammer blows to break.",
+        .extremely_strong => "can only be chipped with a hammer.",
+    };
+}
+
+fn weatheringMeaning(grade: WeatheringGrade) []const u8 {
+    return switch (grade) {
+        .fresh => "no visible sign of weathering; strength is that of the intact rock.",
+        .slightly_weathered => "discoloured along joints; strength is little reduced.",
+        .moderately_weathered => "less than half the rock is decomposed to soil; strength is noticeably reduced.",
+        .highly_weathered => "more than half the rock is decomposed to soil; treat it as much weaker than fresh rock.",
+        .completely_weathered => "all the rock is decomposed to soil although the original structure remains; design it as soil.",
+    };
+}
+
+fn soilMeaning(soil_type: SoilType) []const u8 {
+    return switch (soil_type) {
+        .clay => "fine-grained and cohesive. Low permeability; strength depends on water content, and it settles slowly under load and may shrink and swell.",
+        .silt => "fine-grained with little cohesion. Loses strength when wet or disturbed and is susceptible to frost.",
+        .sand => "coarse-grained and granular. Free draining; strength comes from friction and density.",
+        .gravel => "coarse-grained and granular. Very free draining and usually a good founding stratum when dense.",
+        .cobbles => "very coarse particles (63-200 mm). Obstruct piling and excavation.",
+        .boulders => "particles over 200 mm. Obstruct piling and excavation.",
+        .peat => "organic and highly compressible. Unsuitable for foundations; large, long-term settlement.",
+        .organic => "contains organic matter. Compressible and may produce gas; usually avoided for foundations.",
+    };
+}
+
+fn rockMeaning(rock_type: RockType) []const u8 {
+    return switch (rock_type) {
+        .limestone => "sedimentary carbonate rock. May contain solution features and cavities.",
+        .sandstone => "sedimentary rock of cemented sand. Strength depends on the cement.",
+        .mudstone => "fine-grained sedimentary rock. Softens and slakes when exposed to water.",
+        .shale => "fissile fine-grained sedimentary rock. Splits along bedding and degrades on exposure.",
+        .granite => "coarse crystalline igneous rock. Usually strong; may weather deeply to sandy soil.",
+        .basalt => "fine crystalline igneous rock. Very strong and abrasive.",
+        .chalk => "soft, fine limestone. Can soften when saturated or disturbed and may contain solution features.",
+        .dolomite => "magnesium-rich carbonate rock. Similar to limestone but less soluble.",
+        .quartzite => "metamorphosed sandstone. Very strong and abrasive to drilling tools.",
+        .slate => "metamorphosed mudstone with a strong cleavage. Splits into thin sheets.",
+        .schist => "foliated metamorphic rock. Strength differs along and across the foliation.",
+        .gneiss => "banded metamorphic rock. Generally strong.",
+        .marble => "metamorphosed limestone. Strong but soluble.",
+        .conglomerate => "sedimentary rock of rounded gravel in a finer matrix. Strength depends on the matrix.",
+        .breccia => "sedimentary rock of angular fragments in a finer matrix. Strength depends on the matrix.",
+    };
+}
+
+test "explain covers each parsed term" {
+    const allocator = std.testing.allocator;
+
+    const desc = GeologicalDescription{
+        .raw_description = "Firm CLAY",
+        .material_type = .soil,
+        .consistency = .firm,
+        .primary_soil_type = .clay,
+    };
+
+    const text = try explain(desc, allocator);
+    defer allocator.free(text);
+
+    try std.testing.expect(std.mem.indexOf(u8, text, "- firm: moulded by strong finger pressure") != null);
+    try std.testing.expect(std.mem.indexOf(u8, text, "25-50 kPa") != null);
+    try std.testing.expect(std.mem.indexOf(u8, text, "- CLAY: fine-grained and cohesive") != null);
+}
@@ -0,0 +1,185 @@
+const std = @import("std");
+const types = @import("types.zig");
+const strength_db = @import("strength_db.zig");
+
+const GeologicalDescription = types.GeologicalDescription;
+const RockType = types.RockType;
+const SoilType = types.SoilType;
+const StrengthParameterType = strength_db.StrengthParameterType;
+const StrengthRange = strength_db.StrengthRange;
+
+/// One description's part of a tunnel face or excavation
+pub const Share = struct {
+    description: *const GeologicalDescription,
+    /// Any non-negative weight, such as a percentage of the face area.
+    /// Shares are scaled to add up to 1.
+    proportion: f64,
+};
+
+/// A primary soil or rock type
+pub const Lithology = union(enum) {
+    soil: SoilType,
+    rock: RockType,
+
+    pub fn format(self: Lithology, comptime _: []const u8, _: std.fmt.FormatOptions, writer: anytype) !void {
+        switch (self) {
+            .soil => |soil_type| try writer.writeAll(soil_type.toString()),
+            .rock => |rock_type| try writer.writeAll(rock_type.toString()),
+        }
+    }
+};
+
+/// One strength parameter over the part of the face that has it
+pub const BlendedStrength = struct {
+    /// Fraction of the face whose descriptions give this parameter
+    coverage: f64,
+    /// Bounds and typical value averaged by proportion
+    weighted: StrengthRange,
+    /// Lowest lower bound and highest upper bound of any description
+    envelope: StrengthRange,
+};
+
+pub const FaceSummary = struct {
+    /// The type covering most of the face, null when no description has one
+    dominant: ?Lithology = null,
+    dominant_share: f64 = 0,
+    /// Fractions of the face described as soil and as rock
+    soil_share: f64 = 0,
+    rock_share: f64 = 0,
+    strength: std.EnumArray(StrengthParameterType, ?BlendedStrength) = std.EnumArray(StrengthParameterType, ?BlendedStrength).initFill(null),
+
+    /// Soil and rock both take up at least `threshold` of the face, the
+    /// usual trigger for mixed-face tunnelling methods
+    pub fn isMixed(self: FaceSummary, threshold: f64) bool {
+        return self.soil_share >= threshold and self.rock_share >= threshold;
+    }
+};
+
+/// Blend the descriptions making up a face into one summary, weighting each
+/// by its proportion. The same type in several descriptions adds up towards
+/// the dominant type. Strength ranges are only blended over the
+/// descriptions that give them, so a clay's cu is not diluted by a
+/// sandstone's UCS.
+pub fn blend(shares: []const Share) error{InvalidProportion}!FaceSummary {
+    var total: f64 = 0;
+    for (shares) |share| {
+        if (!(share.proportion >= 0) or std.math.isInf(share.proportion)) return error.InvalidProportion;
+        total += share.proportion;
+    }
+    if (total == 0) return error.InvalidProportion;
+
+    var summary = FaceSummary{};
+    var soil = std.EnumArray(SoilType, f64).initFill(0);
+    var rock = std.EnumArray(RockType, f64).initFill(0);
+    var sums = std.EnumArray(StrengthParameterType, Sum).initFill(.{});
+
+    for (shares) |share| {
+        const weight = share.proportion / total;
+        const desc = share.description;
+        switch (desc.material_type) {
+            .soil => summary.soil_share += weight,
+            .rock => summary.rock_share += weight,
+        }
+        if (desc.primary_soil_type) |soil_type| {
+            soil.getPtr(soil_type).* += weight;
+        } else if (desc.primary_rock_type) |rock_type| {
+            rock.getPtr(rock_type).* += weight;
+        }
+        if (desc.strength_parameters) |parameters| sums.getPtr(parameters.parameter_type).add(weight, parameters.range);
+    }
+
+    for (std.enums.values(SoilType)) |soil_type| {
+        if (soil.get(soil_type) > summary.dominant_share) {
+            summary.dominant = .{ .soil = soil_type };
+            summary.dominant_share = soil.get(soil_type);
+        }
+    }
+    for (std.enums.values(RockType)) |rock_type| {
+        if (rock.get(rock_type) > summary.dominant_share) {
+            summary.dominant = .{ .rock = rock_type };
+            summary.dominant_share = rock.get(rock_type);
+        }
+    }
+    for (std.enums.values(StrengthParameterType)) |parameter_type| {
+        summary.strength.set(parameter_type, sums.get(parameter_type).blended());
+    }
+    return summary;
+}
+
+/// Running totals for one strength parameter
+const Sum = struct {
+    weight: f64 = 0,
+    lower: f64 = 0,
+    upper: f64 = 0,
+    typical: f64 = 0,
+    envelope: ?StrengthRange = null,
+
+    fn add(self: *Sum, weight: f64, range: StrengthRange) void {
+        self.weight += weight;
+        self.lower += weight * range.lower_bound;
+        self.upper += weight * range.upper_bound;
+        self.typical += weight * (range.typical_value orelse range.getMidpoint());
+        if (self.envelope) |*envelope| {
+            envelope.lower_bound = @min(envelope.lower_bound, range.lower_bound);
+            envelope.upper_bound = @max(envelope.upper_bound, range.upper_bound);
+        } else {
+            self.envelope = .{ .lower_bound = range.lower_bound, .upper_bound = range.upper_bound };
+        }
+    }
+
+    fn blended(self: Sum) ?BlendedStrength {
+        const envelope = self.envelope orelse return null;
+        return BlendedStrength{
+            .coverage = self.weight,
+            .weighted = .{
+                .lower_bound = @floatCast(self.lower / self.weight),
+                .upper_bound = @floatCast(self.upper / self.weight),
+                .typical_value = @floatCast(self.typical / self.weight),
+            },
+            .envelope = envelope,
+        };
+    }
+};
+
+test "blend a mixed face" {
+    const clay = GeologicalDescription{
+        .raw_description = "Stiff CLAY",
+        .material_type = .soil,
+        .primary_soil_type = .clay,
+        .strength_parameters = .{ .parameter_type = .undrained_shear_strength, .range = .{ .lower_bound = 75, .upper_bound = 150, .typical_value = 110 } },
+    };
+    const firm_clay = GeologicalDescription{
+        .raw_description = "Firm CLAY",
+        .material_type = .soil,
+        .primary_soil_type = .clay,
+        .strength_parameters = .{ .parameter_type = .undrained_shear_strength, .range = .{ .lower_bound = 40, .upper_bound = 75 } },
+    };
+    const sandstone = GeologicalDescription{
+        .raw_description = "Strong SANDSTONE",
+        .material_type = .rock,
+        .primary_rock_type = .sandstone,
+        .strength_parameters = .{ .parameter_type = .ucs, .range = .{ .lower_bound = 50, .upper_bound = 100 } },
+    };
+
+    const summary = try blend(&.{
+        .{ .description = &clay, .proportion = 30 },
+        .{ .description = &firm_clay, .proportion = 10 },
+        .{ .description = &sandstone, .proportion = 60 },
+    });
+
+    try std.testing.expectEqual(RockType.sandstone, summary.dominant.?.rock);
+    try std.testing.expectApproxEqAbs(@as(f64, 0.6), summary.dominant_share, 1e-9);
+    try std.testing.expectApproxEqAbs(@as(f64, 0.4), summary.soil_share, 1e-9);
+    try std.testing.expect(summary.isMixed(0.2));
+
+    const cu = summary.strength.get(.undrained_shear_strength).?;
+    try std.testing.expectApproxEqAbs(@as(f64, 0.4), cu.coverage, 1e-9);
+    try std.testing.expectApproxEqAbs(@as(f32, 66.25), cu.weighted.lower_bound, 0.01);
+    try std.testing.expectApproxEqAbs(@as(f32, 131.25), cu.weighted.upper_bound, 0.01);
+    try std.testing.expectApproxEqAbs(@as(f32, 40), cu.envelope.lower_bound, 0.01);
+    try std.testing.expectApproxEqAbs(@as(f32, 150), cu.envelope.upper_bound, 0.01);
+    try std.testing.expect(summary.strength.get(.spt_n_value) == null);
+
+    try std.testing.expectError(error.InvalidProportion, blend(&.{.{ .description = &clay, .proportion = 0 }}));
+    try std.testing.expectError(error.InvalidProportion, blend(&.{.{ .description = &clay, .proportion = -1 }}));
+}
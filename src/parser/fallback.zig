@@ -0,0 +1,103 @@
+const std = @import("std");
+const types = @import("types.zig");
+
+const GeologicalDescription = types.GeologicalDescription;
+const SoilDescription = types.SoilDescription;
+
+/// Secondary parser consulted when rule-based parsing confidence is low.
+/// Implementations own nothing on behalf of the caller: a returned
+/// GeologicalDescription is allocated with the supplied allocator and freed by the caller.
+pub const FallbackParser = struct {
+    ptr: *anyopaque,
+    vtable: *const VTable,
+
+    pub const VTable = struct {
+        parse: *const fn (ptr: *anyopaque, allocator: std.mem.Allocator, description: []const u8) anyerror!?GeologicalDescription,
+    };
+
+    /// Parse a description, returning null when the fallback has no opinion
+    pub fn parse(self: FallbackParser, allocator: std.mem.Allocator, description: []const u8) anyerror!?GeologicalDescription {
+        return self.vtable.parse(self.ptr, allocator, description);
+    }
+};
+
+/// Reference fallback that POSTs `{"description": "..."}` to an external model
+/// endpoint and expects a GeologicalDescription JSON document in response.
+pub const HttpFallbackParser = struct {
+    endpoint: []const u8,
+    client: std.http.Client,
+
+    pub fn init(allocator: std.mem.Allocator, endpoint: []const u8) HttpFallbackParser {
+        return HttpFallbackParser{
+            .endpoint = endpoint,
+            .client = std.http.Client{ .allocator = allocator },
+        };
+    }
+
+    pub fn deinit(self: *HttpFallbackParser) void {
+        self.client.deinit();
+    }
+
+    pub fn fallbackParser(self: *HttpFallbackParser) FallbackParser {
+        return FallbackParser{
+            .ptr = self,
+            .vtable = &.{ .parse = parseFn },
+        };
+    }
+
+    fn parseFn(ptr: *anyopaque, allocator: std.mem.Allocator, description: []const u8) anyerror!?GeologicalDescription {
+        const self: *HttpFallbackParser = @ptrCast(@alignCast(ptr));
+
+        var payload = std.ArrayList(u8).init(allocator);
+        defer payload.deinit();
+        try std.json.stringify(.{ .description = description }, .{}, payload.writer());
+
+        var response = std.ArrayList(u8).init(allocator);
+        defer response.deinit();
+
+        const result = try self.client.fetch(.{
+            .location = .{ .url = self.endpoint },
+            .method = .POST,
+            .payload = payload.items,
+            .headers = .{ .content_type = .{ .override = "application/json" } },
+            .response_storage = .{ .dynamic = &response },
+        });
+        if (result.status != .ok) return null;
+
+        var parsed = try GeologicalDescription.fromJson(response.items, allocator);
+        errdefer parsed.deinit(allocator);
+
+        // Keep the caller's original text rather than whatever the model echoed
+        const echoed = parsed.raw_description;
+        parsed.raw_description = try allocator.dupe(u8, description);
+        allocator.free(echoed);
+        return parsed;
+    }
+};
+
+test "fallback parser dispatches through vtable" {
+    const Stub = struct {
+        calls: usize = 0,
+
+        fn parseFn(ptr: *anyopaque, allocator: std.mem.Allocator, description: []const u8) anyerror!?SoilDescription {
+            const self: *@This() = @ptrCast(@alignCast(ptr));
+            self.calls += 1;
+            return SoilDescription{
+                .raw_description = try allocator.dupe(u8, description),
+                .material_type = .soil,
+                .primary_soil_type = .clay,
+                .confidence = 0.9,
+            };
+        }
+    };
+
+    const allocator = std.testing.allocator;
+    var stub = Stub{};
+    const fallback = FallbackParser{ .ptr = &stub, .vtable = &.{ .parse = Stub.parseFn } };
+
+    const result = (try fallback.parse(allocator, "odd clay-ish stuff")).?;
+    defer result.deinit(allocator);
+
+    try std.testing.expectEqual(@as(usize, 1), stub.calls);
+    try std.testing.expectEqual(types.SoilType.clay, result.primary_soil_type.?);
+}
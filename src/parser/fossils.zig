@@ -0,0 +1,57 @@
+const std = @import("std");
+const types = @import("types.zig");
+
+const Frequency = types.Frequency;
+
+/// A stated fossil frequency found in a description
+pub const Match = struct {
+    frequency: Frequency,
+    start: usize,
+    end: usize,
+};
+
+const fossil_terms = [_][]const u8{
+    "fossils",
+    "fossil fragments",
+    "shell fragments",
+    "shells",
+    "ammonites",
+    "belemnites",
+    "bivalves",
+    "brachiopods",
+    "crinoids",
+    "crinoid ossicles",
+    "gastropods",
+    "corals",
+};
+
+/// The frequency of fossils in a description ("LIMESTONE with frequent
+/// shell fragments", "rare ammonites"), read from the frequency word in
+/// front of the fossil noun
+pub fn find(text: []const u8) ?Match {
+    var words = std.mem.tokenizeAny(u8, text, " \t,;");
+    while (words.next()) |word| {
+        const frequency = Frequency.fromString(word) orelse continue;
+        const word_start = words.index - word.len;
+
+        var rest = words.index;
+        while (rest < text.len and text[rest] == ' ') rest += 1;
+        for (fossil_terms) |term| {
+            if (!std.ascii.startsWithIgnoreCase(text[rest..], term)) continue;
+            const end = rest + term.len;
+            if (end < text.len and std.ascii.isAlphanumeric(text[end])) continue;
+            return Match{ .frequency = frequency, .start = word_start, .end = end };
+        }
+    }
+    return null;
+}
+
+test "find fossil frequency" {
+    const match = find("Strong grey LIMESTONE with frequent shell fragments").?;
+    try std.testing.expectEqual(Frequency.frequent, match.frequency);
+    try std.testing.expectEqual(@as(usize, 27), match.start);
+
+    try std.testing.expectEqual(Frequency.rare, find("Stiff dark grey CLAY with rare ammonites").?.frequency);
+    try std.testing.expect(find("Firm CLAY with rare cobbles") == null);
+    try std.testing.expect(find("Strong LIMESTONE, fossils") == null);
+}
@@ -1,62 +1,369 @@
 const std = @import("std");
 
-/// Levenshtein distance algorithm for fuzzy string matching
-pub fn levenshteinDistance(s1: []const u8, s2: []const u8, allocator: std.mem.Allocator) !usize {
-    const len1 = s1.len;
-    const len2 = s2.len;
+/// Similarity scoring algorithm. All compare Unicode code points, not bytes.
+pub const Algorithm = enum {
+    levenshtein, // Edit distance ratio; the default
+    jaro_winkler, // Favours shared prefixes; good for short terms
+    token_set, // Ignores word order and repeats ("grey brown" vs "brown grey")
+
+    pub fn fromString(str: []const u8) ?Algorithm {
+        if (std.ascii.eqlIgnoreCase(str, "levenshtein")) return .levenshtein;
+        if (std.ascii.eqlIgnoreCase(str, "jaro_winkler") or std.ascii.eqlIgnoreCase(str, "jaro-winkler")) return .jaro_winkler;
+        if (std.ascii.eqlIgnoreCase(str, "token_set") or std.ascii.eqlIgnoreCase(str, "token-set")) return .token_set;
+        return null;
+    }
+};
+
+/// Per-call similarity settings
+pub const SimilarityOptions = struct {
+    algorithm: Algorithm = .levenshtein,
+    mode: MatchMode = .edit_distance,
+};
+
+/// Decode UTF-8 into code points. Invalid UTF-8 is read byte by byte.
+fn decode(text: []const u8, allocator: std.mem.Allocator) ![]u21 {
+    const view = std.unicode.Utf8View.init(text) catch {
+        const bytes = try allocator.alloc(u21, text.len);
+        for (text, 0..) |byte, i| bytes[i] = byte;
+        return bytes;
+    };
 
-    if (len1 == 0) return len2;
-    if (len2 == 0) return len1;
+    var points = std.ArrayList(u21).init(allocator);
+    errdefer points.deinit();
+    var iter = view.iterator();
+    while (iter.nextCodepoint()) |point| try points.append(point);
+    return points.toOwnedSlice();
+}
 
-    // Create matrix (len1+1) x (len2+1)
-    const rows = len1 + 1;
-    const cols = len2 + 1;
+/// Two-row Levenshtein distance; `buffer` holds at least 2 * (b.len + 1) entries
+fn editDistance(a: []const u21, b: []const u21, buffer: []usize) usize {
+    if (a.len == 0) return b.len;
+    if (b.len == 0) return a.len;
 
-    var matrix = try allocator.alloc([]usize, rows);
-    defer {
-        for (matrix) |row| {
-            allocator.free(row);
+    var previous = buffer[0 .. b.len + 1];
+    var current = buffer[b.len + 1 .. 2 * (b.len + 1)];
+    for (previous, 0..) |*cell, j| cell.* = j;
+
+    for (a, 1..) |ch1, i| {
+        current[0] = i;
+        for (b, 1..) |ch2, j| {
+            const cost: usize = if (ch1 == ch2) 0 else 1;
+            current[j] = @min(@min(previous[j] + 1, current[j - 1] + 1), previous[j - 1] + cost);
         }
-        allocator.free(matrix);
+        std.mem.swap([]usize, &previous, &current);
     }
 
-    for (matrix) |*row| {
-        row.* = try allocator.alloc(usize, cols);
+    return previous[b.len];
+}
+
+fn editRatio(a: []const u21, b: []const u21, buffer: []usize) f32 {
+    const max_len = @max(a.len, b.len);
+    if (max_len == 0) return 1.0;
+    const distance = editDistance(a, b, buffer);
+    return 1.0 - (@as(f32, @floatFromInt(distance)) / @as(f32, @floatFromInt(max_len)));
+}
+
+/// Levenshtein distance algorithm for fuzzy string matching, counted in code points
+pub fn levenshteinDistance(s1: []const u8, s2: []const u8, allocator: std.mem.Allocator) !usize {
+    const a = try decode(s1, allocator);
+    defer allocator.free(a);
+    const b = try decode(s2, allocator);
+    defer allocator.free(b);
+
+    const buffer = try allocator.alloc(usize, 2 * (b.len + 1));
+    defer allocator.free(buffer);
+    return editDistance(a, b, buffer);
+}
+
+/// Calculate similarity ratio between two strings (0.0 to 1.0)
+pub fn similarityRatio(s1: []const u8, s2: []const u8, allocator: std.mem.Allocator) !f32 {
+    const a = try decode(s1, allocator);
+    defer allocator.free(a);
+    const b = try decode(s2, allocator);
+    defer allocator.free(b);
+
+    const buffer = try allocator.alloc(usize, 2 * (b.len + 1));
+    defer allocator.free(buffer);
+    return editRatio(a, b, buffer);
+}
+
+/// Jaro-Winkler similarity (0.0 to 1.0), with the standard 0.1 prefix scale
+pub fn jaroWinkler(s1: []const u8, s2: []const u8, allocator: std.mem.Allocator) !f32 {
+    const a = try decode(s1, allocator);
+    defer allocator.free(a);
+    const b = try decode(s2, allocator);
+    defer allocator.free(b);
+
+    if (a.len == 0 and b.len == 0) return 1.0;
+    if (a.len == 0 or b.len == 0) return 0.0;
+
+    const a_matched = try allocator.alloc(bool, a.len);
+    defer allocator.free(a_matched);
+    const b_matched = try allocator.alloc(bool, b.len);
+    defer allocator.free(b_matched);
+    @memset(a_matched, false);
+    @memset(b_matched, false);
+
+    const window = (@max(a.len, b.len) / 2) -| 1;
+    var matches: usize = 0;
+    for (a, 0..) |ch, i| {
+        const lo = i -| window;
+        const hi = @min(i + window + 1, b.len);
+        for (lo..hi) |j| {
+            if (b_matched[j] or b[j] != ch) continue;
+            a_matched[i] = true;
+            b_matched[j] = true;
+            matches += 1;
+            break;
+        }
+    }
+    if (matches == 0) return 0.0;
+
+    // Half the number of matched characters that are out of order
+    var transpositions: usize = 0;
+    var j: usize = 0;
+    for (a, 0..) |ch, i| {
+        if (!a_matched[i]) continue;
+        while (!b_matched[j]) j += 1;
+        if (ch != b[j]) transpositions += 1;
+        j += 1;
     }
 
-    // Initialize first row and column
-    for (0..rows) |i| {
-        matrix[i][0] = i;
+    const m: f32 = @floatFromInt(matches);
+    const t: f32 = @as(f32, @floatFromInt(transpositions)) / 2.0;
+    const jaro = (m / @as(f32, @floatFromInt(a.len)) + m / @as(f32, @floatFromInt(b.len)) + (m - t) / m) / 3.0;
+
+    var prefix: usize = 0;
+    while (prefix < @min(4, @min(a.len, b.len)) and a[prefix] == b[prefix]) prefix += 1;
+
+    return jaro + @as(f32, @floatFromInt(prefix)) * 0.1 * (1.0 - jaro);
+}
+
+/// Token set ratio: compares the shared words against each side's extra
+/// words, so word order and repeated words do not count against a match
+pub fn tokenSetRatio(s1: []const u8, s2: []const u8, allocator: std.mem.Allocator) !f32 {
+    const tokens1 = try sortedTokens(s1, allocator);
+    defer allocator.free(tokens1);
+    const tokens2 = try sortedTokens(s2, allocator);
+    defer allocator.free(tokens2);
+
+    var common = std.ArrayList(u8).init(allocator);
+    defer common.deinit();
+    var only1 = std.ArrayList(u8).init(allocator);
+    defer only1.deinit();
+    var only2 = std.ArrayList(u8).init(allocator);
+    defer only2.deinit();
+
+    for (tokens1) |token| {
+        const shared = containsToken(tokens2, token);
+        try appendToken(if (shared) &common else &only1, token);
     }
-    for (0..cols) |j| {
-        matrix[0][j] = j;
+    for (tokens2) |token| {
+        if (!containsToken(tokens1, token)) try appendToken(&only2, token);
     }
 
-    // Fill matrix
-    for (1..rows) |i| {
-        for (1..cols) |j| {
-            const cost: usize = if (s1[i - 1] == s2[j - 1]) 0 else 1;
+    const with1 = try joinTokens(common.items, only1.items, allocator);
+    defer allocator.free(with1);
+    const with2 = try joinTokens(common.items, only2.items, allocator);
+    defer allocator.free(with2);
+
+    var best = try similarityRatio(with1, with2, allocator);
+    if (common.items.len > 0) {
+        best = @max(best, try similarityRatio(common.items, with1, allocator));
+        best = @max(best, try similarityRatio(common.items, with2, allocator));
+    }
+    return best;
+}
 
-            const deletion = matrix[i - 1][j] + 1;
-            const insertion = matrix[i][j - 1] + 1;
-            const substitution = matrix[i - 1][j - 1] + cost;
+/// Unique whitespace/punctuation-separated words, sorted, compared case-insensitively
+fn sortedTokens(text: []const u8, allocator: std.mem.Allocator) ![]const []const u8 {
+    var tokens = std.ArrayList([]const u8).init(allocator);
+    errdefer tokens.deinit();
 
-            matrix[i][j] = @min(@min(deletion, insertion), substitution);
+    var words = std.mem.tokenizeAny(u8, text, " \t\r\n,;:.-/");
+    while (words.next()) |word| {
+        if (!containsToken(tokens.items, word)) try tokens.append(word);
+    }
+
+    std.sort.insertion([]const u8, tokens.items, {}, struct {
+        fn lessThan(_: void, a: []const u8, b: []const u8) bool {
+            return std.ascii.lessThanIgnoreCase(a, b);
         }
+    }.lessThan);
+    return tokens.toOwnedSlice();
+}
+
+fn containsToken(tokens: []const []const u8, token: []const u8) bool {
+    for (tokens) |existing| {
+        if (std.ascii.eqlIgnoreCase(existing, token)) return true;
     }
+    return false;
+}
 
-    return matrix[len1][len2];
+fn appendToken(list: *std.ArrayList(u8), token: []const u8) !void {
+    if (list.items.len > 0) try list.append(' ');
+    for (token) |ch| try list.append(std.ascii.toLower(ch));
 }
 
-/// Calculate similarity ratio between two strings (0.0 to 1.0)
-pub fn similarityRatio(s1: []const u8, s2: []const u8, allocator: std.mem.Allocator) !f32 {
-    if (s1.len == 0 and s2.len == 0) return 1.0;
+fn joinTokens(first: []const u8, second: []const u8, allocator: std.mem.Allocator) ![]u8 {
+    if (first.len == 0) return allocator.dupe(u8, second);
+    if (second.len == 0) return allocator.dupe(u8, first);
+    return std.fmt.allocPrint(allocator, "{s} {s}", .{ first, second });
+}
+
+/// Similarity of two strings with the chosen algorithm and match mode
+pub fn compare(s1: []const u8, s2: []const u8, options: SimilarityOptions, allocator: std.mem.Allocator) !f32 {
+    const spelling = try score(options.algorithm, s1, s2, allocator);
+    if (options.mode == .edit_distance) return spelling;
+
+    const key1 = try phoneticKey(s1, allocator);
+    defer allocator.free(key1);
+    const key2 = try phoneticKey(s2, allocator);
+    defer allocator.free(key2);
 
-    const distance = try levenshteinDistance(s1, s2, allocator);
-    const max_len = @max(s1.len, s2.len);
+    return @max(spelling, try score(options.algorithm, key1, key2, allocator));
+}
 
-    const ratio = 1.0 - (@as(f32, @floatFromInt(distance)) / @as(f32, @floatFromInt(max_len)));
-    return ratio;
+fn score(algorithm: Algorithm, s1: []const u8, s2: []const u8, allocator: std.mem.Allocator) !f32 {
+    return switch (algorithm) {
+        .levenshtein => similarityRatio(s1, s2, allocator),
+        .jaro_winkler => jaroWinkler(s1, s2, allocator),
+        .token_set => tokenSetRatio(s1, s2, allocator),
+    };
+}
+
+/// Similarity of every target against every option in one call, for
+/// deduplicating large datasets. Returns a caller-owned row-major matrix:
+/// `result[t * options.len + o]` is the ratio for targets[t] and options[o].
+pub fn similarityMatrix(targets: []const []const u8, options: []const []const u8, allocator: std.mem.Allocator) ![]f32 {
+    const matrix = try allocator.alloc(f32, targets.len * options.len);
+    errdefer allocator.free(matrix);
+
+    // Decode every option once up front
+    const decoded = try allocator.alloc([]u21, options.len);
+    var decoded_count: usize = 0;
+    defer {
+        for (decoded[0..decoded_count]) |points| allocator.free(points);
+        allocator.free(decoded);
+    }
+    var longest_option: usize = 0;
+    for (options, 0..) |option, o| {
+        decoded[o] = try decode(option, allocator);
+        decoded_count += 1;
+        longest_option = @max(longest_option, decoded[o].len);
+    }
+
+    const buffer = try allocator.alloc(usize, 2 * (longest_option + 1));
+    defer allocator.free(buffer);
+
+    for (targets, 0..) |target, t| {
+        const target_points = try decode(target, allocator);
+        defer allocator.free(target_points);
+        for (decoded, 0..) |option_points, o| {
+            matrix[t * options.len + o] = editRatio(target_points, option_points, buffer);
+        }
+    }
+
+    return matrix;
+}
+
+pub const MatchMode = enum {
+    edit_distance, // Levenshtein ratio on the spelling
+    phonetic, // Also compare how the words sound, for misheard or dictated terms
+
+    pub fn fromString(str: []const u8) ?MatchMode {
+        if (std.ascii.eqlIgnoreCase(str, "edit_distance") or std.ascii.eqlIgnoreCase(str, "levenshtein")) return .edit_distance;
+        if (std.ascii.eqlIgnoreCase(str, "phonetic")) return .phonetic;
+        return null;
+    }
+};
+
+/// A simplified metaphone key: common English spellings of the same sound are
+/// folded together and vowels after the first letter dropped, so "granight"
+/// and "granite" both give "grnt" and "shail" and "shale" both give "xl".
+pub fn phoneticKey(word: []const u8, allocator: std.mem.Allocator) ![]u8 {
+    var key = std.ArrayList(u8).init(allocator);
+    errdefer key.deinit();
+
+    var i: usize = 0;
+    while (i < word.len) {
+        const ch = std.ascii.toLower(word[i]);
+        const next: u8 = if (i + 1 < word.len) std.ascii.toLower(word[i + 1]) else 0;
+        const after: u8 = if (i + 2 < word.len) std.ascii.toLower(word[i + 2]) else 0;
+
+        var code: ?u8 = ch;
+        var consumed: usize = 1;
+        switch (ch) {
+            'a', 'e', 'i', 'o', 'u', 'y' => {
+                // "igh" as in "granight" is a long vowel
+                if (next == 'g' and after == 'h') consumed = 3;
+                if (key.items.len > 0) code = null else code = 'a';
+            },
+            'c' => {
+                if (next == 'k') consumed = 2;
+                code = if (next == 'e' or next == 'i' or next == 'y') 's' else if (next == 'h') 'x' else 'k';
+                if (next == 'h') consumed = 2;
+            },
+            's' => {
+                if (next == 'h') {
+                    code = 'x';
+                    consumed = 2;
+                } else if (next == 'c' and after == 'h') {
+                    code = 'k';
+                    consumed = 3;
+                }
+            },
+            'p' => if (next == 'h') {
+                code = 'f';
+                consumed = 2;
+            },
+            'g' => {
+                if (next == 'h') {
+                    code = null;
+                    consumed = 2;
+                } else if (next == 'e' or next == 'i' or next == 'y') {
+                    code = 'j';
+                }
+            },
+            'd' => if (next == 'g') {
+                code = 'j';
+                consumed = 2;
+            },
+            'k' => if (key.items.len == 0 and next == 'n') {
+                code = null;
+            },
+            'w' => if (next == 'h') {
+                consumed = 2;
+            },
+            'q' => code = 'k',
+            'z' => code = 's',
+            'x' => {
+                try appendCode(&key, 'k');
+                code = 's';
+            },
+            'h' => code = null, // Silent on its own in most terms
+            else => if (!std.ascii.isAlphabetic(ch)) {
+                code = null;
+            },
+        }
+
+        if (code) |c| try appendCode(&key, c);
+        i += consumed;
+    }
+
+    return key.toOwnedSlice();
+}
+
+/// Collapse doubled sounds ("gravel" / "gravvel")
+fn appendCode(key: *std.ArrayList(u8), code: u8) !void {
+    if (key.items.len > 0 and key.items[key.items.len - 1] == code) return;
+    try key.append(code);
+}
+
+/// Levenshtein similarity in the given mode. Phonetic mode takes the better
+/// of the spelling and sound scores, so it never scores below edit distance.
+pub fn similarity(s1: []const u8, s2: []const u8, mode: MatchMode, allocator: std.mem.Allocator) !f32 {
+    return compare(s1, s2, .{ .mode = mode }, allocator);
 }
 
 /// Find the closest match from a list of options
@@ -129,6 +436,44 @@ pub fn fuzzyMatchCaseInsensitive(target: []const u8, options: []const []const u8
     return null;
 }
 
+pub const Candidate = struct {
+    match: []const u8, // Borrowed from options
+    score: f32,
+};
+
+/// The best `k` options for `target`, highest score first, compared
+/// case-insensitively with the given `settings`. Ties keep the order of
+/// `options`. Returns a caller-owned slice of at most `k` candidates.
+pub fn fuzzyMatchN(target: []const u8, options: []const []const u8, k: usize, settings: SimilarityOptions, allocator: std.mem.Allocator) ![]Candidate {
+    const target_lower = try std.ascii.allocLowerString(allocator, target);
+    defer allocator.free(target_lower);
+
+    const candidates = try allocator.alloc(Candidate, options.len);
+    errdefer allocator.free(candidates);
+
+    for (options, 0..) |option, i| {
+        const option_lower = try std.ascii.allocLowerString(allocator, option);
+        defer allocator.free(option_lower);
+        candidates[i] = Candidate{
+            .match = option,
+            .score = try compare(target_lower, option_lower, settings, allocator),
+        };
+    }
+
+    std.sort.insertion(Candidate, candidates, {}, struct {
+        fn higherScore(_: void, a: Candidate, b: Candidate) bool {
+            return a.score > b.score;
+        }
+    }.higherScore);
+
+    const count = @min(k, candidates.len);
+    if (count == candidates.len) return candidates;
+
+    const top = try allocator.dupe(Candidate, candidates[0..count]);
+    allocator.free(candidates);
+    return top;
+}
+
 test "levenshtein distance" {
     const allocator = std.testing.allocator;
 
@@ -153,6 +498,24 @@ test "similarity ratio" {
     try std.testing.expect(ratio2 < 1.0);
 }
 
+test "similarity matrix matches pairwise ratios" {
+    const allocator = std.testing.allocator;
+
+    const targets = [_][]const u8{ "clai", "sandd", "" };
+    const options = [_][]const u8{ "clay", "sand", "gravel", "" };
+
+    const matrix = try similarityMatrix(&targets, &options, allocator);
+    defer allocator.free(matrix);
+
+    try std.testing.expectEqual(targets.len * options.len, matrix.len);
+    for (targets, 0..) |target, t| {
+        for (options, 0..) |option, o| {
+            const expected = try similarityRatio(target, option, allocator);
+            try std.testing.expectApproxEqAbs(expected, matrix[t * options.len + o], 0.0001);
+        }
+    }
+}
+
 test "find closest match" {
     const allocator = std.testing.allocator;
 
@@ -179,6 +542,59 @@ test "fuzzy match with threshold" {
     try std.testing.expect(match2 == null);
 }
 
+test "top-k fuzzy match keeps near ties" {
+    const allocator = std.testing.allocator;
+
+    const options = [_][]const u8{ "SILT", "SAND", "CLAY", "SALT" };
+    const top = try fuzzyMatchN("sald", &options, 3, .{}, allocator);
+    defer allocator.free(top);
+
+    try std.testing.expectEqual(@as(usize, 3), top.len);
+    try std.testing.expectEqualStrings("SAND", top[0].match);
+    try std.testing.expectEqualStrings("SALT", top[1].match);
+    try std.testing.expectApproxEqAbs(top[0].score, top[1].score, 0.0001);
+    try std.testing.expect(top[1].score >= top[2].score);
+}
+
+test "distances count code points, not bytes" {
+    const allocator = std.testing.allocator;
+
+    try std.testing.expectEqual(@as(usize, 1), try levenshteinDistance("café", "cafe", allocator));
+    try std.testing.expectApproxEqAbs(@as(f32, 0.75), try similarityRatio("café", "cafe", allocator), 0.0001);
+    try std.testing.expectEqual(@as(usize, 0), try levenshteinDistance("ölig", "ölig", allocator));
+}
+
+test "jaro-winkler and token set algorithms" {
+    const allocator = std.testing.allocator;
+
+    const jw = try compare("martha", "marhta", .{ .algorithm = .jaro_winkler }, allocator);
+    try std.testing.expectApproxEqAbs(@as(f32, 0.961), jw, 0.001);
+    try std.testing.expectApproxEqAbs(@as(f32, 0.0), try jaroWinkler("abc", "xyz", allocator), 0.0001);
+
+    const reordered = try compare("grey brown clay", "brown grey CLAY", .{ .algorithm = .token_set }, allocator);
+    try std.testing.expectApproxEqAbs(@as(f32, 1.0), reordered, 0.0001);
+    try std.testing.expect(try compare("grey brown clay", "brown grey CLAY", .{}, allocator) < 0.8);
+    try std.testing.expectEqual(Algorithm.jaro_winkler, Algorithm.fromString("jaro-winkler").?);
+}
+
+test "phonetic matching finds misheard terms" {
+    const allocator = std.testing.allocator;
+
+    const granight = try phoneticKey("granight", allocator);
+    defer allocator.free(granight);
+    try std.testing.expectEqualStrings("grnt", granight);
+
+    const shale = try phoneticKey("shale", allocator);
+    defer allocator.free(shale);
+    try std.testing.expectEqualStrings("xl", shale);
+
+    const edit = try similarity("granight", "granite", .edit_distance, allocator);
+    const sound = try similarity("granight", "granite", .phonetic, allocator);
+    try std.testing.expect(edit < 0.8);
+    try std.testing.expectApproxEqAbs(@as(f32, 1.0), sound, 0.0001);
+    try std.testing.expect(try similarity("shail", "shale", .phonetic, allocator) > 0.95);
+}
+
 test "case insensitive fuzzy match" {
     const allocator = std.testing.allocator;
 
@@ -0,0 +1,90 @@
+const std = @import("std");
+const types = @import("types.zig");
+
+const GasIndicators = types.GasIndicators;
+
+/// A gas or hydrate note found in a description
+pub const Match = struct {
+    kind: Kind,
+    start: usize,
+    end: usize,
+};
+
+pub const Kind = enum { gas_blisters, expanded_core, gas_voids, hydrate_dissociation };
+
+const phrases = [_]struct { phrase: []const u8, kind: Kind }{
+    .{ .phrase = "gas blisters", .kind = .gas_blisters },
+    .{ .phrase = "gas blistered", .kind = .gas_blisters },
+    .{ .phrase = "blistered", .kind = .gas_blisters },
+    .{ .phrase = "blisters", .kind = .gas_blisters },
+    .{ .phrase = "expanded core", .kind = .expanded_core },
+    .{ .phrase = "core expansion", .kind = .expanded_core },
+    .{ .phrase = "gas expansion", .kind = .expanded_core },
+    .{ .phrase = "extruded from liner", .kind = .expanded_core },
+    .{ .phrase = "gas voids", .kind = .gas_voids },
+    .{ .phrase = "gas bubbles", .kind = .gas_voids },
+    .{ .phrase = "gas pockets", .kind = .gas_voids },
+    .{ .phrase = "degassing", .kind = .gas_voids },
+    .{ .phrase = "hydrate dissociation", .kind = .hydrate_dissociation },
+    .{ .phrase = "dissociating hydrate", .kind = .hydrate_dissociation },
+    .{ .phrase = "gas hydrate", .kind = .hydrate_dissociation },
+    .{ .phrase = "gas hydrates", .kind = .hydrate_dissociation },
+    .{ .phrase = "hydrates", .kind = .hydrate_dissociation },
+};
+
+/// Walks gas and hydrate notes ("gas blisters", "expanded core", "hydrate
+/// dissociation") left to right, longest phrase first at each position
+pub const Iterator = struct {
+    text: []const u8,
+    pos: usize = 0,
+
+    pub fn next(self: *Iterator) ?Match {
+        while (self.pos < self.text.len) : (self.pos += 1) {
+            if (self.pos > 0 and std.ascii.isAlphanumeric(self.text[self.pos - 1])) continue;
+
+            var best: ?Match = null;
+            for (phrases) |entry| {
+                if (!std.ascii.startsWithIgnoreCase(self.text[self.pos..], entry.phrase)) continue;
+                const end = self.pos + entry.phrase.len;
+                if (end < self.text.len and std.ascii.isAlphanumeric(self.text[end])) continue;
+                if (best) |current| {
+                    if (current.end >= end) continue;
+                }
+                best = Match{ .kind = entry.kind, .start = self.pos, .end = end };
+            }
+            if (best) |match| {
+                self.pos = match.end;
+                return match;
+            }
+        }
+        return null;
+    }
+};
+
+pub fn iterate(text: []const u8) Iterator {
+    return Iterator{ .text = text };
+}
+
+/// Set the flag for `kind`
+pub fn record(indicators: *GasIndicators, kind: Kind) void {
+    switch (kind) {
+        .gas_blisters => indicators.gas_blisters = true,
+        .expanded_core => indicators.expanded_core = true,
+        .gas_voids => indicators.gas_voids = true,
+        .hydrate_dissociation => indicators.hydrate_dissociation = true,
+    }
+}
+
+test "iterate gas and hydrate notes" {
+    var matches = iterate("Very soft grey CLAY with gas blisters, expanded core; hydrate dissociation noted");
+    var indicators = GasIndicators{};
+    while (matches.next()) |match| record(&indicators, match.kind);
+
+    try std.testing.expect(indicators.gas_blisters);
+    try std.testing.expect(indicators.expanded_core);
+    try std.testing.expect(indicators.hydrate_dissociation);
+    try std.testing.expect(!indicators.gas_voids);
+
+    var none = iterate("Firm grey CLAY");
+    try std.testing.expect(none.next() == null);
+}
@@ -3,6 +3,7 @@ const types = @import("types.zig");
 const strength_db = @import("strength_db.zig");
 const Random = std.Random;
 
+const GeologicalDescription = types.GeologicalDescription;
 const SoilDescription = types.SoilDescription;
 const MaterialType = types.MaterialType;
 const SoilType = types.SoilType;
@@ -14,8 +15,8 @@ const WeatheringGrade = types.WeatheringGrade;
 const RockStructure = types.RockStructure;
 const SecondaryConstituent = types.SecondaryConstituent;
 
-/// Generate a human-readable geological description from a SoilDescription struct
-pub fn generate(desc: SoilDescription, allocator: std.mem.Allocator) ![]u8 {
+/// Generate a human-readable geological description from a GeologicalDescription struct
+pub fn generate(desc: GeologicalDescription, allocator: std.mem.Allocator) ![]u8 {
     var parts = std.ArrayList([]const u8).init(allocator);
     defer parts.deinit();
 
@@ -43,6 +44,8 @@ pub fn generate(desc: SoilDescription, allocator: std.mem.Allocator) ![]u8 {
             if (desc.primary_soil_type) |pst| {
                 try parts.append(pst.toString());
             }
+
+            try appendInclusions(&parts, desc.inclusions, desc.tertiary_constituents);
         },
         .rock => {
             // Add rock strength
@@ -72,8 +75,35 @@ pub fn generate(desc: SoilDescription, allocator: std.mem.Allocator) ![]u8 {
     return try std.mem.join(allocator, " ", parts.items);
 }
 
+/// "with occasional cobbles and rare boulders", "with thin partings of fine sand",
+/// then the tertiary constituents that are not soils ("and rare shell fragments")
+fn appendInclusions(parts: *std.ArrayList([]const u8), inclusions: []const types.Inclusion, tertiary: []const types.TertiaryConstituent) !void {
+    for (inclusions, 0..) |inclusion, i| {
+        try parts.append(if (i == 0) "with" else "and");
+        if (inclusion.frequency) |frequency| try parts.append(frequency.toString());
+        if (inclusion.thickness) |thickness| try parts.append(thickness.toString());
+        if (inclusion.form != .particles) {
+            try parts.append(inclusion.form.toString());
+            try parts.append("of");
+        }
+        if (inclusion.consistency) |consistency| try parts.append(consistency.toString());
+        if (inclusion.color) |color| try parts.append(color.toString());
+        if (inclusion.particle_size) |size| try parts.append(size.toString());
+        try parts.append(@tagName(inclusion.soil_type));
+    }
+    var first = inclusions.len == 0;
+    for (tertiary) |constituent| {
+        // Soils were written above with their form and description
+        if (constituent.soil_type != null) continue;
+        try parts.append(if (first) "with" else "and");
+        first = false;
+        if (constituent.frequency) |frequency| try parts.append(frequency.toString());
+        try parts.append(constituent.name);
+    }
+}
+
 /// Generate a concise description (minimal formatting)
-pub fn generateConcise(desc: SoilDescription, allocator: std.mem.Allocator) ![]u8 {
+pub fn generateConcise(desc: GeologicalDescription, allocator: std.mem.Allocator) ![]u8 {
     var parts = std.ArrayList([]const u8).init(allocator);
     defer parts.deinit();
 
@@ -104,7 +134,7 @@ pub fn generateConcise(desc: SoilDescription, allocator: std.mem.Allocator) ![]u
 }
 
 /// Generate a verbose description with all available information
-pub fn generateVerbose(desc: SoilDescription, allocator: std.mem.Allocator) ![]u8 {
+pub fn generateVerbose(desc: GeologicalDescription, allocator: std.mem.Allocator) ![]u8 {
     var parts = std.ArrayList([]const u8).init(allocator);
     defer parts.deinit();
 
@@ -148,6 +178,8 @@ pub fn generateVerbose(desc: SoilDescription, allocator: std.mem.Allocator) ![]u
             if (desc.primary_soil_type) |pst| {
                 try parts.append(pst.toString());
             }
+
+            try appendInclusions(&parts, desc.inclusions, desc.tertiary_constituents);
         },
         .rock => {
             // Add rock strength
@@ -177,7 +209,7 @@ pub fn generateVerbose(desc: SoilDescription, allocator: std.mem.Allocator) ![]u
 }
 
 /// Generate description with BS5930 standard formatting
-pub fn generateBS5930(desc: SoilDescription, allocator: std.mem.Allocator) ![]u8 {
+pub fn generateBS5930(desc: GeologicalDescription, allocator: std.mem.Allocator) ![]u8 {
     var result = std.ArrayList(u8).init(allocator);
     var writer = result.writer();
 
@@ -293,7 +325,7 @@ pub fn generateFromProperties(
 ) ![]u8 {
     const raw_desc = "generated";
 
-    var desc = SoilDescription{
+    var desc = GeologicalDescription{
         .raw_description = raw_desc,
         .material_type = material_type,
     };
@@ -337,7 +369,7 @@ pub fn generateRandom(allocator: std.mem.Allocator, seed: u64) ![]u8 {
     const material_type = if (random.boolean()) MaterialType.soil else MaterialType.rock;
 
     const raw_desc = "random";
-    var desc = SoilDescription{
+    var desc = GeologicalDescription{
         .raw_description = raw_desc,
         .material_type = material_type,
     };
@@ -391,7 +423,7 @@ pub fn generateRandom(allocator: std.mem.Allocator, seed: u64) ![]u8 {
 }
 
 /// Generate variations of a description with different strength descriptors
-pub fn generateVariations(desc: SoilDescription, allocator: std.mem.Allocator) ![][]u8 {
+pub fn generateVariations(desc: GeologicalDescription, allocator: std.mem.Allocator) ![][]u8 {
     var variations = std.ArrayList([]u8).init(allocator);
     errdefer {
         for (variations.items) |v| allocator.free(v);
@@ -438,7 +470,7 @@ pub fn generateVariations(desc: SoilDescription, allocator: std.mem.Allocator) !
 }
 
 /// Generate description with strength parameters included
-pub fn generateWithStrength(desc: SoilDescription, allocator: std.mem.Allocator) ![]u8 {
+pub fn generateWithStrength(desc: GeologicalDescription, allocator: std.mem.Allocator) ![]u8 {
     const base = try generate(desc, allocator);
     defer allocator.free(base);
 
@@ -462,7 +494,7 @@ pub fn generateWithStrength(desc: SoilDescription, allocator: std.mem.Allocator)
 }
 
 /// Generate simplified description suitable for labels or summaries
-pub fn generateLabel(desc: SoilDescription, allocator: std.mem.Allocator) ![]u8 {
+pub fn generateLabel(desc: GeologicalDescription, allocator: std.mem.Allocator) ![]u8 {
     switch (desc.material_type) {
         .soil => {
             if (desc.primary_soil_type) |pst| {
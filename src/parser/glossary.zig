@@ -0,0 +1,161 @@
+const std = @import("std");
+
+/// A glossary entry for a BS 5930 term
+pub const Definition = struct {
+    term: []const u8,
+    definition: []const u8,
+    /// Governing clause of the standard
+    reference: []const u8,
+    related: []const []const u8 = &.{},
+};
+
+const soils = "BS 5930:2015 §6.3";
+const consistency = "BS 5930:2015 §6.3.2.2";
+const constituents = "BS 5930:2015 §6.3.2.4";
+const rocks = "BS 5930:2015 §6.4";
+
+const consistency_terms = [_][]const u8{ "very soft", "soft", "firm", "stiff", "very stiff", "hard" };
+const density_terms = [_][]const u8{ "very loose", "loose", "medium dense", "dense", "very dense" };
+const strength_terms = [_][]const u8{ "very weak", "weak", "moderately weak", "moderately strong", "strong", "very strong", "extremely strong" };
+const weathering_terms = [_][]const u8{ "fresh", "slightly weathered", "moderately weathered", "highly weathered", "completely weathered" };
+const structure_terms = [_][]const u8{ "massive", "bedded", "jointed", "fractured", "foliated", "laminated" };
+const proportion_terms = [_][]const u8{ "slightly", "moderately", "very" };
+const fine_soils = [_][]const u8{ "clay", "silt" };
+const coarse_soils = [_][]const u8{ "sand", "gravel", "cobbles", "boulders" };
+const moisture_terms = [_][]const u8{ "dry", "moist", "wet", "saturated" };
+const size_terms = [_][]const u8{ "fine", "medium", "coarse" };
+const carbonate_rocks = [_][]const u8{ "limestone", "chalk", "dolomite", "marble" };
+const clastic_rocks = [_][]const u8{ "sandstone", "mudstone", "shale", "conglomerate", "breccia" };
+
+const colour_definition = "Colour of the material in its natural moist state; qualified by light, dark or mottled, or combined as in grey-brown.";
+
+const entries = [_]Definition{
+    // Consistency of fine soils
+    .{ .term = "very soft", .definition = "Fine soil that exudes between the fingers when squeezed; undrained shear strength below 12 kPa.", .reference = consistency, .related = &consistency_terms },
+    .{ .term = "soft", .definition = "Fine soil that is moulded by light finger pressure; undrained shear strength 12-25 kPa.", .reference = consistency, .related = &consistency_terms },
+    .{ .term = "firm", .definition = "Fine soil that is moulded by strong finger pressure; undrained shear strength 25-50 kPa.", .reference = consistency, .related = &consistency_terms },
+    .{ .term = "stiff", .definition = "Fine soil that cannot be moulded by the fingers but can be indented by the thumb; undrained shear strength 50-100 kPa.", .reference = consistency, .related = &consistency_terms },
+    .{ .term = "very stiff", .definition = "Fine soil that can be indented by the thumbnail; undrained shear strength 100-200 kPa.", .reference = consistency, .related = &consistency_terms },
+    .{ .term = "hard", .definition = "Fine soil that can be scratched by the thumbnail; undrained shear strength above 200 kPa.", .reference = consistency, .related = &consistency_terms },
+
+    // Density of coarse soils
+    .{ .term = "very loose", .definition = "Coarse soil with SPT N below 4.", .reference = soils, .related = &density_terms },
+    .{ .term = "loose", .definition = "Coarse soil with SPT N of 4-10; easily excavated.", .reference = soils, .related = &density_terms },
+    .{ .term = "medium dense", .definition = "Coarse soil with SPT N of 10-30.", .reference = soils, .related = &density_terms },
+    .{ .term = "dense", .definition = "Coarse soil with SPT N of 30-50; hard to excavate by hand.", .reference = soils, .related = &density_terms },
+    .{ .term = "very dense", .definition = "Coarse soil with SPT N above 50.", .reference = soils, .related = &density_terms },
+
+    // Rock strength
+    .{ .term = "very weak", .definition = "Rock that crumbles under firm blows of a geological hammer; UCS below 1 MPa.", .reference = rocks, .related = &strength_terms },
+    .{ .term = "weak", .definition = "Rock that can be shaped with a knife; UCS 1-5 MPa.", .reference = rocks, .related = &strength_terms },
+    .{ .term = "moderately weak", .definition = "Rock that can be scraped with a knife; UCS 5-12.5 MPa.", .reference = rocks, .related = &strength_terms },
+    .{ .term = "moderately strong", .definition = "Rock that cannot be scraped with a knife and breaks with one firm hammer blow; UCS 12.5-50 MPa.", .reference = rocks, .related = &strength_terms },
+    .{ .term = "strong", .definition = "Rock that needs more than one hammer blow to break; UCS 50-100 MPa.", .reference = rocks, .related = &strength_terms },
+    .{ .term = "very strong", .definition = "Rock that needs many hammer blows to break; UCS 100-200 MPa.", .reference = rocks, .related = &strength_terms },
+    .{ .term = "extremely strong", .definition = "Rock that can only be chipped with a hammer; UCS above 200 MPa.", .reference = rocks, .related = &strength_terms },
+
+    // Weathering
+    .{ .term = "fresh", .definition = "Grade I: no visible sign of weathering.", .reference = rocks, .related = &weathering_terms },
+    .{ .term = "weathered", .definition = "Changed by weathering; qualified by slightly, moderately, highly or completely.", .reference = rocks, .related = &weathering_terms },
+    .{ .term = "slightly weathered", .definition = "Grade II: discoloration on discontinuities; rock may be slightly weaker than fresh.", .reference = rocks, .related = &weathering_terms },
+    .{ .term = "moderately weathered", .definition = "Grade III: less than half the rock is decomposed or disintegrated to soil.", .reference = rocks, .related = &weathering_terms },
+    .{ .term = "highly weathered", .definition = "Grade IV: more than half the rock is decomposed or disintegrated to soil.", .reference = rocks, .related = &weathering_terms },
+    .{ .term = "completely weathered", .definition = "Grade V: all rock is decomposed or disintegrated to soil; the original mass structure is largely intact.", .reference = rocks, .related = &weathering_terms },
+
+    // Rock structure
+    .{ .term = "massive", .definition = "Rock with no visible bedding, foliation or discontinuities over the exposure.", .reference = rocks, .related = &structure_terms },
+    .{ .term = "bedded", .definition = "Rock with visible sedimentary layering; qualified by bed thickness.", .reference = rocks, .related = &structure_terms },
+    .{ .term = "jointed", .definition = "Rock cut by natural fractures across which there has been no visible movement.", .reference = rocks, .related = &structure_terms },
+    .{ .term = "fractured", .definition = "Rock broken by natural discontinuities; spacing is recorded as fracture state.", .reference = rocks, .related = &structure_terms },
+    .{ .term = "foliated", .definition = "Metamorphic rock with a planar fabric of aligned minerals.", .reference = rocks, .related = &structure_terms },
+    .{ .term = "laminated", .definition = "Layered at a spacing of less than 6 mm.", .reference = rocks, .related = &structure_terms },
+
+    // Proportions of secondary constituents
+    .{ .term = "slightly", .definition = "A small proportion of a secondary constituent, e.g. slightly sandy.", .reference = constituents, .related = &proportion_terms },
+    .{ .term = "moderately", .definition = "A moderate proportion of a secondary constituent; usually written without a qualifier, e.g. sandy.", .reference = constituents, .related = &proportion_terms },
+    .{ .term = "very", .definition = "A large proportion of a secondary constituent, e.g. very sandy.", .reference = constituents, .related = &proportion_terms },
+    .{ .term = "sandy", .definition = "Containing a secondary sand fraction.", .reference = constituents, .related = &.{ "sand", "slightly", "very" } },
+    .{ .term = "silty", .definition = "Containing a secondary silt fraction.", .reference = constituents, .related = &.{ "silt", "slightly", "very" } },
+    .{ .term = "clayey", .definition = "Containing a secondary clay fraction.", .reference = constituents, .related = &.{ "clay", "slightly", "very" } },
+    .{ .term = "gravelly", .definition = "Containing a secondary gravel fraction.", .reference = constituents, .related = &.{ "gravel", "slightly", "very" } },
+
+    // Soil types
+    .{ .term = "clay", .definition = "Fine soil with particles below 0.002 mm that is plastic and cohesive when moist.", .reference = soils, .related = &fine_soils },
+    .{ .term = "silt", .definition = "Fine soil with particles of 0.002-0.063 mm; little plasticity and dilatant when shaken.", .reference = soils, .related = &fine_soils },
+    .{ .term = "sand", .definition = "Coarse soil with particles of 0.063-2 mm.", .reference = soils, .related = &coarse_soils },
+    .{ .term = "gravel", .definition = "Coarse soil with particles of 2-63 mm.", .reference = soils, .related = &coarse_soils },
+    .{ .term = "cobbles", .definition = "Very coarse soil particles of 63-200 mm.", .reference = soils, .related = &coarse_soils },
+    .{ .term = "boulders", .definition = "Very coarse soil particles larger than 200 mm.", .reference = soils, .related = &coarse_soils },
+    .{ .term = "peat", .definition = "Organic soil made predominantly of plant remains.", .reference = soils, .related = &.{"organic"} },
+    .{ .term = "organic", .definition = "Containing organic matter, which raises compressibility and lowers strength.", .reference = soils, .related = &.{"peat"} },
+
+    // Rock types
+    .{ .term = "limestone", .definition = "Sedimentary rock made mostly of calcium carbonate.", .reference = rocks, .related = &carbonate_rocks },
+    .{ .term = "chalk", .definition = "Very fine-grained, usually white, porous limestone.", .reference = rocks, .related = &carbonate_rocks },
+    .{ .term = "dolomite", .definition = "Carbonate rock made mostly of calcium magnesium carbonate.", .reference = rocks, .related = &carbonate_rocks },
+    .{ .term = "marble", .definition = "Metamorphosed limestone or dolomite.", .reference = rocks, .related = &carbonate_rocks },
+    .{ .term = "sandstone", .definition = "Sedimentary rock of cemented sand-sized grains.", .reference = rocks, .related = &clastic_rocks },
+    .{ .term = "mudstone", .definition = "Sedimentary rock of clay and silt-sized particles without fissility.", .reference = rocks, .related = &clastic_rocks },
+    .{ .term = "shale", .definition = "Fissile sedimentary rock of clay and silt-sized particles.", .reference = rocks, .related = &clastic_rocks },
+    .{ .term = "conglomerate", .definition = "Sedimentary rock of rounded gravel-sized clasts in a finer matrix.", .reference = rocks, .related = &clastic_rocks },
+    .{ .term = "breccia", .definition = "Rock of angular gravel-sized fragments in a finer matrix.", .reference = rocks, .related = &clastic_rocks },
+    .{ .term = "granite", .definition = "Coarse-grained igneous rock of quartz, feldspar and mica.", .reference = rocks, .related = &.{"basalt"} },
+    .{ .term = "basalt", .definition = "Fine-grained dark igneous rock.", .reference = rocks, .related = &.{"granite"} },
+    .{ .term = "quartzite", .definition = "Metamorphosed sandstone made almost entirely of quartz.", .reference = rocks, .related = &.{"sandstone"} },
+    .{ .term = "slate", .definition = "Fine-grained metamorphic rock with a strong cleavage.", .reference = rocks, .related = &.{ "mudstone", "shale" } },
+    .{ .term = "schist", .definition = "Medium to coarse-grained metamorphic rock with a pronounced foliation.", .reference = rocks, .related = &.{ "gneiss", "foliated" } },
+    .{ .term = "gneiss", .definition = "Coarse-grained banded metamorphic rock.", .reference = rocks, .related = &.{ "schist", "foliated" } },
+
+    // Moisture
+    .{ .term = "dry", .definition = "No visible moisture; the soil is dusty or crumbly.", .reference = soils, .related = &moisture_terms },
+    .{ .term = "moist", .definition = "Damp to the touch but with no free water.", .reference = soils, .related = &moisture_terms },
+    .{ .term = "wet", .definition = "Free water visible on the surface of the sample.", .reference = soils, .related = &moisture_terms },
+    .{ .term = "saturated", .definition = "All voids filled with water; water drains from the sample.", .reference = soils, .related = &moisture_terms },
+
+    // Particle size qualifiers
+    .{ .term = "fine", .definition = "The finer third of a particle size range, e.g. fine sand (0.063-0.2 mm).", .reference = soils, .related = &size_terms },
+    .{ .term = "medium", .definition = "The middle third of a particle size range, e.g. medium sand (0.2-0.63 mm).", .reference = soils, .related = &size_terms },
+    .{ .term = "coarse", .definition = "The coarser third of a particle size range, e.g. coarse sand (0.63-2 mm).", .reference = soils, .related = &size_terms },
+
+    // Colours
+    .{ .term = "grey", .definition = colour_definition, .reference = soils, .related = &.{"gray"} },
+    .{ .term = "gray", .definition = colour_definition, .reference = soils, .related = &.{"grey"} },
+    .{ .term = "brown", .definition = colour_definition, .reference = soils },
+    .{ .term = "red", .definition = colour_definition, .reference = soils },
+    .{ .term = "yellow", .definition = colour_definition, .reference = soils },
+    .{ .term = "orange", .definition = colour_definition, .reference = soils },
+    .{ .term = "black", .definition = colour_definition, .reference = soils },
+    .{ .term = "white", .definition = colour_definition, .reference = soils },
+    .{ .term = "green", .definition = colour_definition, .reference = soils },
+    .{ .term = "blue", .definition = colour_definition, .reference = soils },
+    .{ .term = "pink", .definition = colour_definition, .reference = soils },
+    .{ .term = "purple", .definition = colour_definition, .reference = soils },
+    .{ .term = "tan", .definition = colour_definition, .reference = soils },
+    .{ .term = "buff", .definition = colour_definition, .reference = soils },
+};
+
+/// Look up a term case-insensitively, e.g. for tooltips in logging software
+pub fn define(term: []const u8) !Definition {
+    const trimmed = std.mem.trim(u8, term, " \t");
+    for (entries) |entry| {
+        if (std.ascii.eqlIgnoreCase(entry.term, trimmed)) return entry;
+    }
+    return error.UnknownTerm;
+}
+
+test "every vocabulary term has a definition" {
+    const vocabulary = @import("vocabulary.zig");
+    for (vocabulary.categories) |category| {
+        for (category.terms) |term| {
+            const entry = try define(term);
+            for (entry.related) |related| _ = try define(related);
+        }
+    }
+}
+
+test "define is case-insensitive and rejects unknown terms" {
+    const entry = try define("  Very Stiff ");
+    try std.testing.expectEqualStrings("very stiff", entry.term);
+    try std.testing.expectEqualStrings("BS 5930:2015 §6.3.2.2", entry.reference);
+    try std.testing.expectError(error.UnknownTerm, define("squishy"));
+}
@@ -0,0 +1,293 @@
+const std = @import("std");
+const types = @import("types.zig");
+const lexer = @import("lexer.zig");
+const vocabulary = @import("vocabulary.zig");
+const config = @import("config.zig");
+
+const CustomDictionary = config.CustomDictionary;
+const TokenType = lexer.TokenType;
+
+pub const Format = enum {
+    /// ISO 14977 EBNF: `rule = a , [ b ] , { c } ;`
+    ebnf,
+    /// W3C EBNF as read by railroad diagram generators: `rule ::= a b? c*`
+    w3c,
+
+    pub fn fromString(str: []const u8) ?Format {
+        if (std.ascii.eqlIgnoreCase(str, "ebnf") or std.ascii.eqlIgnoreCase(str, "iso")) return .ebnf;
+        if (std.ascii.eqlIgnoreCase(str, "w3c") or std.ascii.eqlIgnoreCase(str, "railroad")) return .w3c;
+        return null;
+    }
+
+    pub fn toString(self: Format) []const u8 {
+        return @tagName(self);
+    }
+};
+
+/// Text matched by a pattern rather than a word list
+const Special = struct {
+    /// For ISO EBNF, written `? description ?`
+    description: []const u8,
+    /// For W3C EBNF
+    pattern: []const u8,
+};
+
+const Expr = union(enum) {
+    terminal: []const u8,
+    rule: []const u8,
+    special: Special,
+    sequence: []const Expr,
+    choice: []const Expr,
+    /// A sequence that may be left out
+    optional: []const Expr,
+    /// A sequence repeated zero or more times
+    repeat: []const Expr,
+};
+
+const Rule = struct {
+    name: []const u8,
+    expr: Expr,
+};
+
+fn t(text: []const u8) Expr {
+    return .{ .terminal = text };
+}
+
+fn r(name: []const u8) Expr {
+    return .{ .rule = name };
+}
+
+/// How the description is put together. The word lists come after, from
+/// `word_rules`.
+const structure = [_]Rule{
+    .{ .name = "description", .expr = .{ .sequence = &.{
+        .{ .optional = &.{r("label")} },
+        .{ .choice = &.{ r("soil_description"), r("rock_description") } },
+        .{ .optional = &.{r("deposit")} },
+    } } },
+    .{ .name = "label", .expr = .{ .choice = &.{ t("MADE GROUND"), t("FILL"), t("TOPSOIL") } } },
+    .{ .name = "soil_description", .expr = .{ .sequence = &.{
+        .{ .repeat = &.{r("soil_descriptor")} },
+        r("soil_type"),
+        .{ .optional = &.{ t("and"), r("soil_type") } },
+        .{ .repeat = &.{r("with_clause")} },
+    } } },
+    .{ .name = "soil_descriptor", .expr = .{ .choice = &.{
+        r("consistency_range"),
+        r("density"),
+        r("colour"),
+        r("moisture"),
+        r("particle_size"),
+        r("secondary_constituent"),
+    } } },
+    .{ .name = "consistency_range", .expr = .{ .sequence = &.{
+        r("consistency"),
+        .{ .optional = &.{ .{ .choice = &.{ t("to"), t("-") } }, r("consistency") } },
+    } } },
+    .{ .name = "density", .expr = .{ .sequence = &.{
+        .{ .optional = &.{.{ .choice = &.{ t("very"), t("medium") } }} },
+        r("density_term"),
+    } } },
+    .{ .name = "secondary_constituent", .expr = .{ .sequence = &.{ .{ .optional = &.{r("proportion")} }, r("constituent") } } },
+    .{ .name = "colour", .expr = .{ .sequence = &.{
+        .{ .optional = &.{.{ .choice = &.{ t("light"), t("dark") } }} },
+        r("colour_term"),
+        .{ .optional = &.{ .{ .choice = &.{ t("-"), t("/") } }, r("colour_term") } },
+    } } },
+    .{ .name = "rock_description", .expr = .{ .sequence = &.{
+        .{ .repeat = &.{r("rock_descriptor")} },
+        r("rock_type"),
+        .{ .repeat = &.{r("with_clause")} },
+    } } },
+    .{ .name = "rock_descriptor", .expr = .{ .choice = &.{ r("rock_strength"), r("weathering"), r("rock_structure"), r("colour") } } },
+    .{ .name = "rock_strength", .expr = .{ .sequence = &.{
+        .{ .optional = &.{.{ .choice = &.{ r("proportion"), t("extremely") } }} },
+        r("strength_term"),
+    } } },
+    .{ .name = "weathering", .expr = .{ .sequence = &.{
+        .{ .optional = &.{.{ .choice = &.{ r("proportion"), t("highly"), t("completely") } }} },
+        r("weathering_term"),
+    } } },
+    .{ .name = "with_clause", .expr = .{ .sequence = &.{
+        t("with"),
+        r("item"),
+        .{ .repeat = &.{ .{ .choice = &.{ t("and"), t(",") } }, r("item") } },
+    } } },
+    .{ .name = "item", .expr = .{ .sequence = &.{
+        .{ .optional = &.{r("frequency")} },
+        .{ .optional = &.{r("thickness")} },
+        .{ .choice = &.{
+            .{ .sequence = &.{ r("form"), t("of"), r("soil_type") } },
+            .{ .sequence = &.{ r("soil_type"), .{ .optional = &.{r("form")} } } },
+        } },
+    } } },
+    .{ .name = "deposit", .expr = .{ .sequence = &.{
+        t("("),
+        .{ .special = .{ .description = "words in capitals", .pattern = "[A-Z] [A-Z ]*" } },
+        t(")"),
+    } } },
+};
+
+/// A rule listing the words of one lexer category, plus the configured
+/// vocabulary's terms for it
+const WordRule = struct {
+    name: []const u8,
+    token_type: TokenType,
+};
+
+const word_rules = [_]WordRule{
+    .{ .name = "consistency", .token_type = .consistency },
+    .{ .name = "density_term", .token_type = .density },
+    .{ .name = "proportion", .token_type = .proportion },
+    .{ .name = "constituent", .token_type = .adjective },
+    .{ .name = "soil_type", .token_type = .soil_type },
+    .{ .name = "rock_type", .token_type = .rock_type },
+    .{ .name = "strength_term", .token_type = .rock_strength },
+    .{ .name = "weathering_term", .token_type = .weathering_grade },
+    .{ .name = "rock_structure", .token_type = .rock_structure },
+    .{ .name = "colour_term", .token_type = .color },
+    .{ .name = "moisture", .token_type = .moisture_content },
+    .{ .name = "particle_size", .token_type = .particle_size },
+};
+
+/// Write the grammar the parser accepts, with the built-in vocabulary and
+/// any project `dictionary` terms. Regional dialects, AS 1726 wording and
+/// spelling correction are applied before this grammar and are not shown.
+pub fn write(allocator: std.mem.Allocator, writer: anytype, format: Format, dictionary: ?*const CustomDictionary) !void {
+    switch (format) {
+        .ebnf => try writer.writeAll("(* litholog BS 5930 description grammar *)\n"),
+        .w3c => try writer.writeAll("/* litholog BS 5930 description grammar */\n"),
+    }
+    for (structure) |rule| try writeRule(writer, format, rule);
+
+    var words = std.ArrayList(Expr).init(allocator);
+    defer words.deinit();
+    var custom = std.ArrayList([]const u8).init(allocator);
+    defer custom.deinit();
+
+    for (word_rules) |word_rule| {
+        words.clearRetainingCapacity();
+        for (vocabulary.categories) |category| {
+            if (category.token_type != word_rule.token_type) continue;
+            for (category.terms) |term| try words.append(t(term));
+        }
+        if (dictionary) |dict| {
+            custom.clearRetainingCapacity();
+            try customTerms(&custom, dict, word_rule.token_type);
+            std.mem.sort([]const u8, custom.items, {}, stringLessThan);
+            for (custom.items) |term| try words.append(t(term));
+        }
+        try writeRule(writer, format, .{ .name = word_rule.name, .expr = .{ .choice = words.items } });
+    }
+
+    try writeEnumRule(writer, format, "frequency", types.Frequency, null);
+    try writeEnumRule(writer, format, "thickness", types.Thickness, null);
+    try writeEnumRule(writer, format, "form", types.InclusionForm, .particles);
+}
+
+fn customTerms(list: *std.ArrayList([]const u8), dict: *const CustomDictionary, token_type: TokenType) !void {
+    switch (token_type) {
+        .soil_type => try appendKeys(list, dict.soil_types),
+        .rock_type => try appendKeys(list, dict.rock_types),
+        .consistency => try appendKeys(list, dict.consistency_terms),
+        .density => try appendKeys(list, dict.density_terms),
+        .rock_strength => try appendKeys(list, dict.rock_strength_terms),
+        .weathering_grade => try appendKeys(list, dict.weathering_terms),
+        .rock_structure => try appendKeys(list, dict.structure_terms),
+        else => {},
+    }
+}
+
+fn appendKeys(list: *std.ArrayList([]const u8), map: anytype) !void {
+    var keys = map.keyIterator();
+    while (keys.next()) |key| try list.append(key.*);
+}
+
+/// A choice of an enum's words, leaving out `skip`
+fn writeEnumRule(writer: anytype, format: Format, name: []const u8, comptime E: type, comptime skip: ?E) !void {
+    const values = comptime std.enums.values(E);
+    var words: [values.len]Expr = undefined;
+    var len: usize = 0;
+    for (values) |value| {
+        if (skip != null and value == skip.?) continue;
+        words[len] = t(value.toString());
+        len += 1;
+    }
+    try writeRule(writer, format, .{ .name = name, .expr = .{ .choice = words[0..len] } });
+}
+
+fn writeRule(writer: anytype, format: Format, rule: Rule) !void {
+    try writer.writeAll(rule.name);
+    try writer.writeAll(if (format == .ebnf) " = " else " ::= ");
+    try writeExpr(writer, format, rule.expr, .top);
+    try writer.writeAll(if (format == .ebnf) " ;\n" else "\n");
+}
+
+/// Where an expression sits, which decides whether it needs brackets
+const Context = enum { top, in_sequence, before_suffix };
+
+fn writeExpr(writer: anytype, format: Format, expr: Expr, context: Context) anyerror!void {
+    switch (expr) {
+        .terminal => |text| try writer.print("\"{s}\"", .{text}),
+        .rule => |name| try writer.writeAll(name),
+        .special => |special| switch (format) {
+            .ebnf => try writer.print("? {s} ?", .{special.description}),
+            .w3c => try writer.writeAll(special.pattern),
+        },
+        .sequence => |items| {
+            if (items.len == 1) return writeExpr(writer, format, items[0], context);
+            const bracket = context == .before_suffix;
+            if (bracket) try writer.writeAll("( ");
+            for (items, 0..) |item, i| {
+                if (i > 0) try writer.writeAll(if (format == .ebnf) " , " else " ");
+                try writeExpr(writer, format, item, .in_sequence);
+            }
+            if (bracket) try writer.writeAll(" )");
+        },
+        .choice => |items| {
+            const bracket = context != .top;
+            if (bracket) try writer.writeAll("( ");
+            for (items, 0..) |item, i| {
+                if (i > 0) try writer.writeAll(" | ");
+                try writeExpr(writer, format, item, .top);
+            }
+            if (bracket) try writer.writeAll(" )");
+        },
+        .optional, .repeat => |items| switch (format) {
+            .ebnf => {
+                try writer.writeAll(if (expr == .optional) "[ " else "{ ");
+                try writeExpr(writer, format, .{ .sequence = items }, .top);
+                try writer.writeAll(if (expr == .optional) " ]" else " }");
+            },
+            .w3c => {
+                try writeExpr(writer, format, .{ .sequence = items }, .before_suffix);
+                try writer.writeAll(if (expr == .optional) "?" else "*");
+            },
+        },
+    }
+}
+
+fn stringLessThan(_: void, a: []const u8, b: []const u8) bool {
+    return std.mem.lessThan(u8, a, b);
+}
+
+test "export the grammar in both notations" {
+    const allocator = std.testing.allocator;
+    var dictionary = CustomDictionary.init(allocator);
+    defer dictionary.deinit();
+    try dictionary.addSoilType("boulder clay", .clay);
+
+    var ebnf = std.ArrayList(u8).init(allocator);
+    defer ebnf.deinit();
+    try write(allocator, ebnf.writer(), .ebnf, &dictionary);
+    try std.testing.expect(std.mem.indexOf(u8, ebnf.items, "consistency_range = consistency , [ ( \"to\" | \"-\" ) , consistency ] ;\n") != null);
+    try std.testing.expect(std.mem.indexOf(u8, ebnf.items, "\"organic\" | \"boulder clay\" ;\n") != null);
+    try std.testing.expect(std.mem.indexOf(u8, ebnf.items, "form = \"pockets\" | ") != null);
+
+    var w3c = std.ArrayList(u8).init(allocator);
+    defer w3c.deinit();
+    try write(allocator, w3c.writer(), .w3c, null);
+    try std.testing.expect(std.mem.indexOf(u8, w3c.items, "description ::= label? ( soil_description | rock_description ) deposit?\n") != null);
+    try std.testing.expect(std.mem.indexOf(u8, w3c.items, "with_clause ::= \"with\" item ( ( \"and\" | \",\" ) item )*\n") != null);
+    try std.testing.expect(std.mem.indexOf(u8, w3c.items, "boulder clay") == null);
+}
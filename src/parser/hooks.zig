@@ -0,0 +1,54 @@
+const std = @import("std");
+const types = @import("types.zig");
+const lexer = @import("lexer.zig");
+
+const GeologicalDescription = types.GeologicalDescription;
+const Token = lexer.Token;
+
+/// A callback plus the application state it needs. `context` must be a
+/// pointer, e.g. to a queue that collects unknown terms.
+pub fn Hook(comptime Arg: type) type {
+    return struct {
+        context: *anyopaque,
+        callback: *const fn (context: *anyopaque, arg: Arg) anyerror!void,
+
+        const Self = @This();
+
+        pub fn init(context: anytype, comptime callback: anytype) Self {
+            const Context = @TypeOf(context);
+            const wrapper = struct {
+                fn call(ptr: *anyopaque, arg: Arg) anyerror!void {
+                    const typed: Context = @ptrCast(@alignCast(ptr));
+                    return callback(typed, arg);
+                }
+            };
+            return Self{ .context = @ptrCast(context), .callback = wrapper.call };
+        }
+
+        pub fn call(self: Self, arg: Arg) anyerror!void {
+            return self.callback(self.context, arg);
+        }
+    };
+}
+
+/// Called with the raw description before any normalisation
+pub const BeforeParseHook = Hook([]const u8);
+/// Called with the final result, after validation and any fallback
+pub const AfterParseHook = Hook(*GeologicalDescription);
+/// Called for each word the lexer could not classify
+pub const UnknownTokenHook = Hook(Token);
+
+test "hook passes typed context to callback" {
+    const Counter = struct {
+        calls: usize = 0,
+
+        fn record(self: *@This(), description: []const u8) !void {
+            self.calls += description.len;
+        }
+    };
+
+    var counter = Counter{};
+    const hook = BeforeParseHook.init(&counter, Counter.record);
+    try hook.call("Firm CLAY");
+    try std.testing.expectEqual(@as(usize, 9), counter.calls);
+}
@@ -0,0 +1,206 @@
+const std = @import("std");
+const types = @import("types.zig");
+
+const GeologicalDescription = types.GeologicalDescription;
+
+/// A `<dl>` fragment describing a parse result, for embedding in report
+/// pages. Each term and value carries a `litholog-<field>` class (e.g.
+/// `litholog-consistency`) and the list is classed by material, so pages
+/// style results with plain CSS. Text is HTML-escaped. Returns caller-owned
+/// markup.
+pub fn toHtml(desc: GeologicalDescription, allocator: std.mem.Allocator) ![]u8 {
+    var html = std.ArrayList(u8).init(allocator);
+    errdefer html.deinit();
+    const writer = html.writer();
+
+    try writer.print("<dl class=\"litholog-description litholog-{s}\">\n", .{desc.material_type.toString()});
+    try item(writer, "raw-description", "Description", desc.raw_description);
+    if (desc.is_made_ground) try item(writer, "made-ground", "Made ground", desc.made_ground_label orelse "yes");
+    if (desc.material_origin) |value| try item(writer, "material-origin", "Origin", value.toString());
+    if (desc.is_topsoil) try item(writer, "topsoil", "Topsoil", "yes");
+
+    if (desc.consistency) |value| try item(writer, "consistency", "Consistency", value.toString());
+    if (desc.density) |value| try item(writer, "density", "Density", value.toString());
+    if (desc.rock_strength) |value| try item(writer, "rock-strength", "Strength", value.toString());
+    if (desc.weathering_grade) |value| try item(writer, "weathering-grade", "Weathering", value.toString());
+    if (desc.rock_structure) |value| try item(writer, "rock-structure", "Structure", value.toString());
+    if (desc.discontinuity_spacing) |value| {
+        var spacing_buf: [64]u8 = undefined;
+        try item(writer, "discontinuity-spacing", "Discontinuity spacing", try std.fmt.bufPrint(&spacing_buf, "{}", .{value}));
+    }
+    if (desc.karst_grade) |value| try item(writer, "karst-grade", "Karst grade", value.code());
+    if (desc.composite) |value| {
+        const text = try std.fmt.allocPrint(allocator, "{}", .{value});
+        defer allocator.free(text);
+        try item(writer, "composite", "Composite", text);
+    }
+    if (desc.color) |value| try item(writer, "color", "Colour", value.toString());
+    if (desc.secondary_color) |value| try item(writer, "secondary-color", "Secondary colour", value.toString());
+    if (desc.colour_detail) |value| {
+        var colour_buf: [96]u8 = undefined;
+        try item(writer, "colour-detail", "Colour as described", try std.fmt.bufPrint(&colour_buf, "{}", .{value}));
+    }
+    if (desc.munsell_colour) |value| {
+        var munsell_buf: [48]u8 = undefined;
+        try item(writer, "munsell-colour", "Munsell colour", try std.fmt.bufPrint(&munsell_buf, "{}", .{value}));
+    }
+    if (desc.moisture_content) |value| try item(writer, "moisture-content", "Moisture", value.toString());
+    if (desc.plasticity_index) |value| try item(writer, "plasticity-index", "Plasticity", value.toString());
+    if (desc.particle_size) |value| try item(writer, "particle-size", "Particle size", value.toString());
+    if (desc.particle_shape) |value| {
+        var shape_buf: [64]u8 = undefined;
+        try item(writer, "particle-shape", "Particle shape", try std.fmt.bufPrint(&shape_buf, "{}", .{value}));
+    }
+    if (desc.sensitivity) |value| try item(writer, "sensitivity", "Sensitivity", value.toString());
+    if (desc.peat_properties) |value| {
+        var peat_buf: [32]u8 = undefined;
+        try item(writer, "peat-properties", "Peat", try std.fmt.bufPrint(&peat_buf, "{}", .{value}));
+    }
+
+    if (desc.marine_indicators.count() > 0) {
+        try writer.writeAll("  <dt class=\"litholog-marine-indicators\">Marine indicators</dt>\n");
+        try writer.writeAll("  <dd class=\"litholog-marine-indicators\"><ul>");
+        var indicators = desc.marine_indicators.iterator();
+        while (indicators.next()) |indicator| {
+            try writer.writeAll("<li>");
+            try writeEscaped(writer, indicator.toString());
+            try writer.writeAll("</li>");
+        }
+        try writer.writeAll("</ul></dd>\n");
+    }
+    if (desc.fossil_frequency) |value| try item(writer, "fossil-frequency", "Fossils", value.toString());
+    if (desc.gas_indicators) |gas| {
+        try writer.writeAll("  <dt class=\"litholog-gas-indicators\">Gas indicators</dt>\n");
+        try writer.writeAll("  <dd class=\"litholog-gas-indicators\"><ul>");
+        if (gas.gas_blisters) try writer.writeAll("<li>gas blisters</li>");
+        if (gas.expanded_core) try writer.writeAll("<li>expanded core</li>");
+        if (gas.gas_voids) try writer.writeAll("<li>gas voids</li>");
+        if (gas.hydrate_dissociation) try writer.writeAll("<li>hydrate dissociation</li>");
+        try writer.writeAll("</ul></dd>\n");
+    }
+
+    if (desc.secondary_constituents.len > 0) {
+        try writer.writeAll("  <dt class=\"litholog-secondary-constituents\">Secondary constituents</dt>\n");
+        try writer.writeAll("  <dd class=\"litholog-secondary-constituents\"><ul>");
+        for (desc.secondary_constituents) |constituent| {
+            try writer.writeAll("<li>");
+            try writeEscaped(writer, constituent.amount);
+            try writer.writeByte(' ');
+            try writeEscaped(writer, constituent.soil_type);
+            try writer.writeAll("</li>");
+        }
+        try writer.writeAll("</ul></dd>\n");
+    }
+
+    if (desc.inclusions.len > 0) {
+        try writer.writeAll("  <dt class=\"litholog-inclusions\">Inclusions</dt>\n");
+        try writer.writeAll("  <dd class=\"litholog-inclusions\"><ul>");
+        for (desc.inclusions) |inclusion| {
+            // Enum words only, nothing to escape
+            try writer.print("<li>{}</li>", .{inclusion});
+        }
+        try writer.writeAll("</ul></dd>\n");
+    }
+
+    if (desc.tertiary_constituents.len > 0) {
+        try writer.writeAll("  <dt class=\"litholog-tertiary-constituents\">Tertiary constituents</dt>\n");
+        try writer.writeAll("  <dd class=\"litholog-tertiary-constituents\"><ul>");
+        for (desc.tertiary_constituents) |constituent| {
+            try writer.writeAll("<li>");
+            if (constituent.frequency) |frequency| try writer.print("{s} ", .{frequency.toString()});
+            try writeEscaped(writer, constituent.name);
+            try writer.writeAll("</li>");
+        }
+        try writer.writeAll("</ul></dd>\n");
+    }
+
+    if (desc.made_ground_constituents.len > 0) {
+        try writer.writeAll("  <dt class=\"litholog-made-ground-constituents\">Made ground constituents</dt>\n");
+        try writer.writeAll("  <dd class=\"litholog-made-ground-constituents\"><ul>");
+        for (desc.made_ground_constituents) |constituent| {
+            // Enum words only, nothing to escape
+            try writer.print("<li>{}</li>", .{constituent});
+        }
+        try writer.writeAll("</ul></dd>\n");
+    }
+
+    if (desc.primary_soil_type) |value| try item(writer, "primary-soil-type", "Soil type", value.toString());
+    if (desc.secondary_primary_soil_type) |value| try item(writer, "secondary-primary-soil-type", "Secondary soil type", value.toString());
+    if (desc.primary_rock_type) |value| try item(writer, "primary-rock-type", "Rock type", value.toString());
+    if (desc.geological_formation) |value| try item(writer, "geological-formation", "Formation", value);
+    if (desc.alternative_type) |value| try item(writer, "alternative-type", "Or", value.toString());
+
+    if (desc.condition_notes.len > 0) {
+        try writer.writeAll("  <dt class=\"litholog-condition-notes\">Condition</dt>\n");
+        try writer.writeAll("  <dd class=\"litholog-condition-notes\"><ul>");
+        for (desc.condition_notes) |note| try writer.print("<li>{}</li>", .{note});
+        try writer.writeAll("</ul></dd>\n");
+    }
+
+    if (desc.strength_parameters) |sp| {
+        const strength = try sp.toString(allocator);
+        defer allocator.free(strength);
+        try item(writer, "strength-parameters", "Estimated strength", strength);
+    }
+    if (desc.boundary_advisory) |value| {
+        const text = try std.fmt.allocPrint(allocator, "{}", .{value});
+        defer allocator.free(text);
+        try item(writer, "boundary-advisory", "Soil/rock boundary", text);
+    }
+
+    if (desc.warnings.len > 0) {
+        try writer.writeAll("  <dt class=\"litholog-warnings\">Warnings</dt>\n");
+        try writer.writeAll("  <dd class=\"litholog-warnings\"><ul>");
+        for (desc.warnings) |warning| {
+            try writer.writeAll("<li>");
+            try writeEscaped(writer, warning);
+            try writer.writeAll("</li>");
+        }
+        try writer.writeAll("</ul></dd>\n");
+    }
+
+    var confidence_buf: [16]u8 = undefined;
+    const confidence = try std.fmt.bufPrint(&confidence_buf, "{d:.2}", .{desc.confidence});
+    try item(writer, "confidence", "Confidence", confidence);
+
+    try writer.writeAll("</dl>\n");
+    return html.toOwnedSlice();
+}
+
+fn item(writer: anytype, class: []const u8, label: []const u8, value: []const u8) !void {
+    try writer.print("  <dt class=\"litholog-{s}\">{s}</dt>\n", .{ class, label });
+    try writer.print("  <dd class=\"litholog-{s}\">", .{class});
+    try writeEscaped(writer, value);
+    try writer.writeAll("</dd>\n");
+}
+
+fn writeEscaped(writer: anytype, text: []const u8) !void {
+    for (text) |ch| {
+        switch (ch) {
+            '&' => try writer.writeAll("&amp;"),
+            '<' => try writer.writeAll("&lt;"),
+            '>' => try writer.writeAll("&gt;"),
+            '"' => try writer.writeAll("&quot;"),
+            '\'' => try writer.writeAll("&#39;"),
+            else => try writer.writeByte(ch),
+        }
+    }
+}
+
+test "html fragment classes each field and escapes text" {
+    const allocator = std.testing.allocator;
+
+    const desc = GeologicalDescription{
+        .raw_description = "Firm CLAY <fill>",
+        .material_type = .soil,
+        .consistency = .firm,
+        .primary_soil_type = .clay,
+    };
+
+    const html = try toHtml(desc, allocator);
+    defer allocator.free(html);
+
+    try std.testing.expect(std.mem.startsWith(u8, html, "<dl class=\"litholog-description litholog-soil\">"));
+    try std.testing.expect(std.mem.indexOf(u8, html, "<dd class=\"litholog-consistency\">firm</dd>") != null);
+    try std.testing.expect(std.mem.indexOf(u8, html, "Firm CLAY &lt;fill&gt;") != null);
+}
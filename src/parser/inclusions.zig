@@ -0,0 +1,388 @@
+const std = @import("std");
+const types = @import("types.zig");
+const scan = @import("scan.zig");
+
+const Frequency = types.Frequency;
+const Inclusion = types.Inclusion;
+const InclusionForm = types.InclusionForm;
+const SoilType = types.SoilType;
+const Thickness = types.Thickness;
+const Word = scan.Word;
+
+/// An inclusion read from a "with" clause
+pub const Match = struct {
+    inclusion: Inclusion,
+    start: usize,
+    end: usize,
+};
+
+/// Something other than a soil read from a "with" clause ("rare shell
+/// fragments"). The name runs from `name_start` to `end`; see `nameOf`.
+pub const TertiaryMatch = struct {
+    frequency: ?Frequency,
+    start: usize,
+    name_start: usize,
+    end: usize,
+};
+
+/// Everything read from the "with" clauses of a description
+pub const Clauses = struct {
+    inclusions: []Match,
+    tertiary: []TertiaryMatch,
+
+    pub fn deinit(self: Clauses, allocator: std.mem.Allocator) void {
+        allocator.free(self.inclusions);
+        allocator.free(self.tertiary);
+    }
+};
+
+const Item = struct {
+    inclusion: Inclusion,
+    stated_frequency: bool,
+    /// Written "<form> of <soil>"
+    form_first: bool,
+};
+
+const max_clause_words = 32;
+
+/// Words that make a "with" item a property or the structure of the soil,
+/// or a change down the hole, rather than something held in it ("with high
+/// plasticity", "with fissures", "with depth")
+const non_materials = [_][]const u8{
+    "depth",           "plasticity", "fissures",    "fissure",     "fissuring",    "joints",
+    "jointing",        "bedding",    "laminations", "lamination",  "slickensides", "slickensided",
+    "discontinuities", "structure",  "strength",    "consistency", "density",      "moisture",
+};
+
+/// Words that may stand between a form or frequency and the soil noun
+/// ("pockets of soft grey sandy silt", "fine to coarse gravel")
+const modifiers = [_][]const u8{
+    "sandy", "silty",  "clayey", "gravelly", "peaty", "organic",
+    "dark",  "light",  "pale",   "slightly", "very",  "to",
+};
+
+/// Reads every "with" clause in a description into inclusions. Items are
+/// joined by "and", "with" or commas ("with gravel, occasional cobbles and
+/// boulders") and run to the next full stop, semicolon or bracket. Each item
+/// is an optional frequency and thickness, then either "<form> of <soil>"
+/// ("pockets of soft silt") or "<soil> [<form>]" ("sand partings").
+///
+/// An item joined by "and" without a frequency of its own takes the one
+/// before it, so "occasional cobbles and boulders" is two occasional
+/// inclusions; after "<form> of <soil>" it takes the form too ("thin bands of
+/// sand and silt"). Items that do not read as a soil ("with depth", "with
+/// shell fragments") are skipped; `read` keeps them.
+pub fn find(allocator: std.mem.Allocator, text: []const u8) ![]Match {
+    const clauses = try read(allocator, text);
+    allocator.free(clauses.tertiary);
+    return clauses.inclusions;
+}
+
+/// Like `find`, but items that are not a soil are kept as tertiary
+/// constituents, with a frequency carried over "and" as for soils ("rare
+/// shell fragments and rootlets"). Only things held in the soil count:
+/// properties and structure ("with high plasticity", "with fissures"),
+/// "with depth", items with numbers, and man-made materials, which are made
+/// ground constituents, are not tertiary constituents.
+pub fn read(allocator: std.mem.Allocator, text: []const u8) !Clauses {
+    var matches = std.ArrayList(Match).init(allocator);
+    errdefer matches.deinit();
+    var tertiary = std.ArrayList(TertiaryMatch).init(allocator);
+    errdefer tertiary.deinit();
+
+    var pos: usize = 0;
+    while (scan.next(text, pos, .terms)) |word| {
+        pos = word.end;
+        if (word.stop or word.bracket or !std.ascii.eqlIgnoreCase(word.text, "with")) continue;
+
+        var clause: [max_clause_words]Word = undefined;
+        var len: usize = 0;
+        while (len < clause.len) {
+            const next_word = scan.next(text, pos, .terms) orelse break;
+            clause[len] = next_word;
+            len += 1;
+            pos = next_word.end;
+            if (next_word.stop or next_word.bracket) break;
+        }
+        try readClause(&matches, &tertiary, clause[0..len]);
+    }
+
+    const inclusions = try matches.toOwnedSlice();
+    errdefer allocator.free(inclusions);
+    return Clauses{ .inclusions = inclusions, .tertiary = try tertiary.toOwnedSlice() };
+}
+
+/// The words of a tertiary constituent's name, lower case and joined by
+/// single spaces ("shell fragments"). Caller owns the result.
+pub fn nameOf(allocator: std.mem.Allocator, text: []const u8, match: TertiaryMatch) ![]u8 {
+    var name = std.ArrayList(u8).init(allocator);
+    errdefer name.deinit();
+    var pos = match.name_start;
+    while (scan.next(text, pos, .terms)) |word| {
+        if (word.start >= match.end) break;
+        pos = word.end;
+        if (name.items.len > 0) try name.append(' ');
+        for (word.text) |c| try name.append(std.ascii.toLower(c));
+    }
+    return name.toOwnedSlice();
+}
+
+fn readClause(matches: *std.ArrayList(Match), tertiary: *std.ArrayList(TertiaryMatch), clause: []const Word) !void {
+    var i: usize = 0;
+    // The item an "and" continues from
+    var last: ?Item = null;
+    var carried: ?Item = null;
+    // Frequency of the previous item of either kind
+    var last_frequency: ?Frequency = null;
+    var carried_frequency: ?Frequency = null;
+
+    while (i < clause.len) {
+        var end = i;
+        while (end < clause.len and !isJoiner(clause[end].text)) {
+            const comma = clause[end].comma;
+            end += 1;
+            if (comma) break;
+        }
+
+        if (end > i) {
+            if (readItem(clause[i..end])) |read| {
+                var item = read;
+                if (carried) |previous| {
+                    if (!item.stated_frequency) item.inclusion.frequency = previous.inclusion.frequency;
+                    if (previous.form_first and !item.form_first and item.inclusion.form == .particles and item.inclusion.thickness == null) {
+                        item.inclusion.form = previous.inclusion.form;
+                        item.inclusion.thickness = previous.inclusion.thickness;
+                        item.form_first = true;
+                    }
+                }
+                try matches.append(Match{
+                    .inclusion = item.inclusion,
+                    .start = clause[i].start,
+                    .end = clause[end - 1].end,
+                });
+                last = item;
+                last_frequency = item.inclusion.frequency;
+            } else if (readTertiary(clause[i..end])) |read_item| {
+                var item = read_item;
+                if (item.frequency == null) item.frequency = carried_frequency;
+                try tertiary.append(item);
+                last = null;
+                last_frequency = item.frequency;
+            } else {
+                last = null;
+                last_frequency = null;
+            }
+        }
+
+        carried = null;
+        carried_frequency = null;
+        if (end < clause.len and isJoiner(clause[end].text)) {
+            if (std.ascii.eqlIgnoreCase(clause[end].text, "and")) {
+                carried = last;
+                carried_frequency = last_frequency;
+            }
+            end += 1;
+        }
+        i = end;
+    }
+}
+
+/// An item that is not a soil: an optional frequency, then words naming it
+fn readTertiary(words: []const Word) ?TertiaryMatch {
+    var i: usize = 0;
+    var frequency: ?Frequency = null;
+    if (Frequency.fromString(words[0].text)) |stated| {
+        frequency = stated;
+        i += 1;
+    }
+    // "a strong organic odour"
+    if (i < words.len and (std.ascii.eqlIgnoreCase(words[i].text, "a") or std.ascii.eqlIgnoreCase(words[i].text, "an"))) i += 1;
+    if (i >= words.len) return null;
+
+    for (words[i..]) |word| {
+        if (std.ascii.isDigit(word.text[0])) return null;
+        if (isNonMaterial(word.text) or types.AnthropogenicMaterial.fromString(word.text) != null) return null;
+    }
+
+    return TertiaryMatch{
+        .frequency = frequency,
+        .start = words[0].start,
+        .name_start = words[i].start,
+        .end = words[words.len - 1].end,
+    };
+}
+
+fn readItem(words: []const Word) ?Item {
+    var i: usize = 0;
+    var frequency: ?Frequency = null;
+    var thickness: ?Thickness = null;
+
+    if (i < words.len) {
+        if (Frequency.fromString(words[i].text)) |stated| {
+            frequency = stated;
+            i += 1;
+        }
+    }
+    if (i < words.len) {
+        if (Thickness.fromString(words[i].text)) |stated| {
+            thickness = stated;
+            i += 1;
+        }
+    }
+    if (i >= words.len) return null;
+
+    // "pockets of silt"
+    if (InclusionForm.fromString(words[i].text)) |form| {
+        if (i + 1 >= words.len or !std.ascii.eqlIgnoreCase(words[i + 1].text, "of")) return null;
+        const soil_type = soilAfterModifiers(words[i + 2 ..]) orelse return null;
+        var inclusion = Inclusion{ .frequency = frequency, .thickness = thickness, .form = form, .soil_type = soil_type };
+        describe(&inclusion, words[i + 2 .. words.len - 1]);
+        return Item{
+            .inclusion = inclusion,
+            .stated_frequency = frequency != null,
+            .form_first = true,
+        };
+    }
+
+    // "sand partings", "cobbles"
+    var nouns = words[i..];
+    var form: InclusionForm = .particles;
+    if (nouns.len >= 2) {
+        if (InclusionForm.fromString(nouns[nouns.len - 1].text)) |stated| {
+            form = stated;
+            nouns = nouns[0 .. nouns.len - 1];
+        }
+    }
+    const soil_type = soilAfterModifiers(nouns) orelse return null;
+    var inclusion = Inclusion{ .frequency = frequency, .thickness = thickness, .form = form, .soil_type = soil_type };
+    describe(&inclusion, nouns[0 .. nouns.len - 1]);
+    return Item{
+        .inclusion = inclusion,
+        .stated_frequency = frequency != null,
+        .form_first = false,
+    };
+}
+
+/// Fills in the consistency, colour and particle size from the modifiers in
+/// front of an inclusion's soil noun. A range reads as its first term, and
+/// "dark grey" as grey.
+fn describe(inclusion: *Inclusion, words: []const Word) void {
+    for (words) |word| {
+        if (inclusion.consistency == null) inclusion.consistency = types.Consistency.fromString(word.text);
+        if (inclusion.color == null) inclusion.color = types.Color.fromString(word.text);
+        if (inclusion.particle_size == null) inclusion.particle_size = types.ParticleSize.fromString(word.text);
+    }
+}
+
+/// The soil noun at the end of `words` when everything before it describes it
+fn soilAfterModifiers(words: []const Word) ?SoilType {
+    if (words.len == 0) return null;
+    for (words[0 .. words.len - 1]) |word| {
+        if (!isModifier(word.text)) return null;
+    }
+    return soilNoun(words[words.len - 1].text);
+}
+
+fn soilNoun(text: []const u8) ?SoilType {
+    if (std.ascii.eqlIgnoreCase(text, "cobble")) return .cobbles;
+    if (std.ascii.eqlIgnoreCase(text, "boulder")) return .boulders;
+    return SoilType.fromString(text);
+}
+
+/// Colour, size, strength and "sandy"-style words that describe a soil noun
+pub fn isModifier(text: []const u8) bool {
+    for (modifiers) |modifier| {
+        if (std.ascii.eqlIgnoreCase(text, modifier)) return true;
+    }
+    return types.Color.fromString(text) != null or
+        types.ParticleSize.fromString(text) != null or
+        types.Consistency.fromString(text) != null or
+        types.Density.fromString(text) != null;
+}
+
+fn isNonMaterial(text: []const u8) bool {
+    for (non_materials) |word| {
+        if (std.ascii.eqlIgnoreCase(text, word)) return true;
+    }
+    return false;
+}
+
+fn isJoiner(text: []const u8) bool {
+    return std.ascii.eqlIgnoreCase(text, "and") or std.ascii.eqlIgnoreCase(text, "with");
+}
+
+test "read chained with clauses" {
+    const allocator = std.testing.allocator;
+
+    const matches = try find(allocator, "Firm brown CLAY with gravel, occasional cobbles and boulders with pockets of soft grey silt");
+    defer allocator.free(matches);
+
+    try std.testing.expectEqual(@as(usize, 4), matches.len);
+    try std.testing.expectEqual(SoilType.gravel, matches[0].inclusion.soil_type);
+    try std.testing.expect(matches[0].inclusion.frequency == null);
+    try std.testing.expectEqual(Frequency.occasional, matches[1].inclusion.frequency.?);
+    try std.testing.expectEqual(SoilType.cobbles, matches[1].inclusion.soil_type);
+    try std.testing.expectEqual(Frequency.occasional, matches[2].inclusion.frequency.?);
+    try std.testing.expectEqual(SoilType.boulders, matches[2].inclusion.soil_type);
+    try std.testing.expectEqual(InclusionForm.pockets, matches[3].inclusion.form);
+    try std.testing.expectEqual(SoilType.silt, matches[3].inclusion.soil_type);
+    try std.testing.expectEqual(types.Consistency.soft, matches[3].inclusion.consistency.?);
+    try std.testing.expectEqual(types.Color.grey, matches[3].inclusion.color.?);
+    try std.testing.expectEqualStrings("pockets of soft grey silt", "Firm brown CLAY with gravel, occasional cobbles and boulders with pockets of soft grey silt"[matches[3].start..matches[3].end]);
+}
+
+test "read forms and thickness" {
+    const allocator = std.testing.allocator;
+
+    const partings = try find(allocator, "Stiff grey CLAY with thin sand partings");
+    defer allocator.free(partings);
+    try std.testing.expectEqual(@as(usize, 1), partings.len);
+    try std.testing.expectEqual(Thickness.thin, partings[0].inclusion.thickness.?);
+    try std.testing.expectEqual(InclusionForm.partings, partings[0].inclusion.form);
+    try std.testing.expectEqual(SoilType.sand, partings[0].inclusion.soil_type);
+
+    const bands = try find(allocator, "Soft CLAY with thin bands of sand and silt");
+    defer allocator.free(bands);
+    try std.testing.expectEqual(@as(usize, 2), bands.len);
+    try std.testing.expectEqual(InclusionForm.bands, bands[1].inclusion.form);
+    try std.testing.expectEqual(SoilType.silt, bands[1].inclusion.soil_type);
+
+    const laminae = try find(allocator, "Soft grey SILT with thin laminae of fine sand");
+    defer allocator.free(laminae);
+    try std.testing.expectEqual(@as(usize, 1), laminae.len);
+    try std.testing.expectEqual(InclusionForm.laminae, laminae[0].inclusion.form);
+    try std.testing.expectEqual(types.ParticleSize.fine, laminae[0].inclusion.particle_size.?);
+    try std.testing.expectEqual(SoilType.sand, laminae[0].inclusion.soil_type);
+
+    const none = try find(allocator, "Soft CLAY becoming firm with depth. Sand with shell fragments");
+    defer allocator.free(none);
+    try std.testing.expectEqual(@as(usize, 0), none.len);
+}
+
+test "keep items that are not soils as tertiary constituents" {
+    const allocator = std.testing.allocator;
+    const text = "Soft grey CLAY with occasional gravel and rare shell fragments and rootlets with depth";
+    const clauses = try read(allocator, text);
+    defer clauses.deinit(allocator);
+
+    try std.testing.expectEqual(@as(usize, 1), clauses.inclusions.len);
+    try std.testing.expectEqual(SoilType.gravel, clauses.inclusions[0].inclusion.soil_type);
+
+    try std.testing.expectEqual(@as(usize, 2), clauses.tertiary.len);
+    try std.testing.expectEqual(Frequency.rare, clauses.tertiary[0].frequency.?);
+    try std.testing.expectEqual(Frequency.rare, clauses.tertiary[1].frequency.?);
+    const name = try nameOf(allocator, text, clauses.tertiary[0]);
+    defer allocator.free(name);
+    try std.testing.expectEqualStrings("shell fragments", name);
+    try std.testing.expectEqualStrings("rare shell fragments", text[clauses.tertiary[0].start..clauses.tertiary[0].end]);
+
+    const odour = try read(allocator, "Soft black SILT with a strong organic odour");
+    defer odour.deinit(allocator);
+    try std.testing.expectEqual(@as(usize, 1), odour.tertiary.len);
+    try std.testing.expect(odour.tertiary[0].frequency == null);
+
+    // Properties, structure and man-made materials are read elsewhere
+    const properties = try read(allocator, "Firm grey CLAY with high plasticity with fissures and occasional brick fragments");
+    defer properties.deinit(allocator);
+    try std.testing.expectEqual(@as(usize, 0), properties.tertiary.len);
+}
@@ -0,0 +1,108 @@
+const std = @import("std");
+const types = @import("types.zig");
+
+const KarstGrade = types.KarstGrade;
+
+/// A karst grade found in a description
+pub const Match = struct {
+    grade: KarstGrade,
+    start: usize,
+    end: usize,
+    /// False when the grade was inferred from dissolution features rather
+    /// than stated ("kIII", "mature karst")
+    explicit: bool,
+};
+
+const stages = [_]struct { phrase: []const u8, grade: KarstGrade }{
+    .{ .phrase = "juvenile karst", .grade = .juvenile },
+    .{ .phrase = "youthful karst", .grade = .youthful },
+    .{ .phrase = "mature karst", .grade = .mature },
+    .{ .phrase = "complex karst", .grade = .complex },
+    .{ .phrase = "extreme karst", .grade = .extreme },
+};
+
+/// Dissolution features and the least grade at which they occur
+const features = [_]struct { phrase: []const u8, grade: KarstGrade }{
+    .{ .phrase = "solution widened", .grade = .youthful },
+    .{ .phrase = "solution-widened", .grade = .youthful },
+    .{ .phrase = "solution cavity", .grade = .mature },
+    .{ .phrase = "solution cavities", .grade = .mature },
+    .{ .phrase = "cavities", .grade = .mature },
+    .{ .phrase = "caves", .grade = .mature },
+    .{ .phrase = "doline", .grade = .mature },
+    .{ .phrase = "dolines", .grade = .mature },
+    .{ .phrase = "sinkhole", .grade = .mature },
+    .{ .phrase = "sinkholes", .grade = .mature },
+    .{ .phrase = "swallow hole", .grade = .mature },
+    .{ .phrase = "swallow holes", .grade = .mature },
+    .{ .phrase = "pinnacles", .grade = .complex },
+    .{ .phrase = "pinnacled", .grade = .complex },
+};
+
+/// The karst grade of a carbonate rock description. A stated grade ("kIII",
+/// "KDG 3", "mature karst") wins; otherwise the highest grade implied by
+/// dissolution features ("with solution cavities") is returned.
+pub fn find(text: []const u8) ?Match {
+    var inferred: ?Match = null;
+
+    var pos: usize = 0;
+    while (pos < text.len) : (pos += 1) {
+        if (pos > 0 and std.ascii.isAlphanumeric(text[pos - 1])) continue;
+        const word_end = wordEnd(text, pos);
+
+        if (KarstGrade.fromCode(text[pos..word_end])) |grade| {
+            return Match{ .grade = grade, .start = pos, .end = word_end, .explicit = true };
+        }
+        if (std.ascii.eqlIgnoreCase(text[pos..word_end], "kdg")) {
+            var code_start = word_end;
+            while (code_start < text.len and (text[code_start] == ' ' or text[code_start] == '-')) code_start += 1;
+            const code_end = wordEnd(text, code_start);
+            const code = text[code_start..code_end];
+            if (KarstGrade.fromCode(code) orelse KarstGrade.fromNumber(code)) |grade| {
+                return Match{ .grade = grade, .start = pos, .end = code_end, .explicit = true };
+            }
+        }
+        for (stages) |stage| {
+            if (phraseEnd(text, pos, stage.phrase)) |end| {
+                return Match{ .grade = stage.grade, .start = pos, .end = end, .explicit = true };
+            }
+        }
+        for (features) |feature| {
+            const end = phraseEnd(text, pos, feature.phrase) orelse continue;
+            if (inferred) |current| {
+                if (@intFromEnum(current.grade) >= @intFromEnum(feature.grade)) continue;
+            }
+            inferred = Match{ .grade = feature.grade, .start = pos, .end = end, .explicit = false };
+        }
+    }
+    return inferred;
+}
+
+fn wordEnd(text: []const u8, start: usize) usize {
+    var end = start;
+    while (end < text.len and std.ascii.isAlphanumeric(text[end])) end += 1;
+    return end;
+}
+
+/// End of `phrase` if it is at `pos` as whole words
+fn phraseEnd(text: []const u8, pos: usize, phrase: []const u8) ?usize {
+    if (!std.ascii.startsWithIgnoreCase(text[pos..], phrase)) return null;
+    const end = pos + phrase.len;
+    if (end < text.len and std.ascii.isAlphanumeric(text[end])) return null;
+    return end;
+}
+
+test "find stated and inferred karst grades" {
+    const stated = find("Strong grey LIMESTONE, karst grade kIII").?;
+    try std.testing.expectEqual(KarstGrade.mature, stated.grade);
+    try std.testing.expect(stated.explicit);
+
+    try std.testing.expectEqual(KarstGrade.complex, find("Strong LIMESTONE, KDG 4").?.grade);
+    try std.testing.expectEqual(KarstGrade.youthful, find("Strong youthful karst LIMESTONE").?.grade);
+
+    const inferred = find("Strong LIMESTONE with solution-widened joints and rare cavities").?;
+    try std.testing.expectEqual(KarstGrade.mature, inferred.grade);
+    try std.testing.expect(!inferred.explicit);
+
+    try std.testing.expect(find("Strong grey LIMESTONE") == null);
+}
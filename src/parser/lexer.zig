@@ -1,6 +1,8 @@
 const std = @import("std");
 const fuzzy = @import("fuzzy.zig");
 const typos = @import("typos.zig");
+const vocabulary = @import("vocabulary.zig");
+const Deadline = @import("deadline.zig").Deadline;
 
 pub const TokenType = enum {
     word,
@@ -28,6 +30,7 @@ pub const Token = struct {
     end: usize,
     corrected_from: ?[]const u8 = null, // Original typo if spelling was corrected
     similarity_score: ?f32 = null, // Fuzzy match score if corrected
+    joiner: ?u8 = null, // '-' or '/' tying this token to the next ("firm-stiff", "grey/brown")
 };
 
 // Classification result with potential correction
@@ -37,11 +40,37 @@ const ClassificationResult = struct {
     similarity_score: ?f32 = null,
 };
 
+/// Characters that always separate words
+fn isSeparator(ch: u8) bool {
+    return switch (ch) {
+        ',', ';', '(', ')', '[', ']', '{', '}', '/', '-' => true,
+        else => std.ascii.isWhitespace(ch),
+    };
+}
+
+/// Characters allowed between the words of a multi-word term
+fn isGap(ch: u8) bool {
+    return ch == '-' or std.ascii.isWhitespace(ch);
+}
+
+fn isTrailingPunctuation(ch: u8) bool {
+    return ch == '.' or ch == ':' or ch == '!' or ch == '?';
+}
+
+fn isWordBoundary(ch: u8) bool {
+    return isSeparator(ch) or isTrailingPunctuation(ch);
+}
+
 pub const Lexer = struct {
     input: []const u8,
     tokens: std.ArrayList(Token),
     allocator: std.mem.Allocator,
     fuzzy_threshold: f32 = 0.80, // Threshold for fuzzy matching
+    fuzzy_matching: bool = true, // Off: only exact terms and known typos are recognised
+    match_mode: fuzzy.MatchMode = .edit_distance, // How fuzzy candidates are scored
+    algorithm: fuzzy.Algorithm = .levenshtein, // Similarity measure for fuzzy candidates
+    trie: *const vocabulary.Trie = &vocabulary.builtin, // Exact-match vocabulary
+    deadline: ?Deadline = null, // Checked before each word; error.ParseTimeout once passed
 
     pub fn init(allocator: std.mem.Allocator, input: []const u8) Lexer {
         return Lexer{
@@ -57,13 +86,25 @@ pub const Lexer = struct {
 
     pub fn tokenize(self: *Lexer) ![]Token {
         var pos: usize = 0;
+        errdefer for (self.tokens.items) |token| {
+            if (token.corrected_from != null) self.allocator.free(token.value);
+        };
 
         while (pos < self.input.len) {
-            // Skip whitespace
-            while (pos < self.input.len and std.ascii.isWhitespace(self.input[pos])) {
+            if (self.deadline) |deadline| try deadline.check();
+
+            // Skip whitespace and punctuation between words
+            const gap_start = pos;
+            while (pos < self.input.len and isWordBoundary(self.input[pos])) {
                 pos += 1;
             }
 
+            // A bare hyphen or slash joins two terms into a range or dual value
+            const gap = self.input[gap_start..pos];
+            if (gap.len == 1 and (gap[0] == '-' or gap[0] == '/') and self.tokens.items.len > 0) {
+                self.tokens.items[self.tokens.items.len - 1].joiner = gap[0];
+            }
+
             if (pos >= self.input.len) break;
 
             // Try to match multi-word patterns first
@@ -79,18 +120,25 @@ pub const Lexer = struct {
 
             // Match single words
             const start = pos;
-            while (pos < self.input.len and !std.ascii.isWhitespace(self.input[pos])) {
+            while (pos < self.input.len and !isSeparator(self.input[pos])) {
                 pos += 1;
             }
 
-            const word = self.input[start..pos];
+            // Trailing full stops and colons end a word ("CLAY.") but decimals ("1.5") are kept
+            var end = pos;
+            while (end > start and isTrailingPunctuation(self.input[end - 1])) {
+                end -= 1;
+            }
+            if (end == start) continue;
+
+            const word = self.input[start..end];
             const classification = try self.classifyWord(word);
 
             try self.tokens.append(Token{
                 .type = classification.token_type,
                 .value = classification.corrected_value orelse word,
                 .start = start,
-                .end = pos,
+                .end = end,
                 .corrected_from = if (classification.corrected_value != null) word else null,
                 .similarity_score = classification.similarity_score,
             });
@@ -111,7 +159,7 @@ pub const Lexer = struct {
             if (self.matchPattern(pattern, pos.*)) |end_pos| {
                 const token = Token{
                     .type = .consistency_range,
-                    .value = self.input[pos.*..end_pos],
+                    .value = self.patternValue(pattern, pos.*, end_pos),
                     .start = pos.*,
                     .end = end_pos,
                 };
@@ -139,6 +187,7 @@ pub const Lexer = struct {
             .{ .pattern = "moderately strong", .token_type = .rock_strength },
             .{ .pattern = "very strong", .token_type = .rock_strength },
             .{ .pattern = "extremely strong", .token_type = .rock_strength },
+            .{ .pattern = "extremely weak", .token_type = .rock_strength },
             // Weathering grade patterns
             .{ .pattern = "slightly weathered", .token_type = .weathering_grade },
             .{ .pattern = "moderately weathered", .token_type = .weathering_grade },
@@ -156,8 +205,10 @@ pub const Lexer = struct {
             // Plasticity patterns
             .{ .pattern = "non plastic", .token_type = .plasticity_index },
             .{ .pattern = "non-plastic", .token_type = .plasticity_index },
+            .{ .pattern = "nonplastic", .token_type = .plasticity_index },
             .{ .pattern = "low plasticity", .token_type = .plasticity_index },
             .{ .pattern = "intermediate plasticity", .token_type = .plasticity_index },
+            .{ .pattern = "medium plasticity", .token_type = .plasticity_index },
             .{ .pattern = "high plasticity", .token_type = .plasticity_index },
             .{ .pattern = "extremely high plasticity", .token_type = .plasticity_index },
             // Particle size patterns
@@ -171,7 +222,7 @@ pub const Lexer = struct {
             if (self.matchPattern(pattern_info.pattern, pos.*)) |end_pos| {
                 const token = Token{
                     .type = pattern_info.token_type,
-                    .value = self.input[pos.*..end_pos],
+                    .value = self.patternValue(pattern_info.pattern, pos.*, end_pos),
                     .start = pos.*,
                     .end = end_pos,
                 };
@@ -238,32 +289,41 @@ pub const Lexer = struct {
         return null;
     }
 
+    /// Match a multi-word pattern case-insensitively. A space in the pattern
+    /// matches any run of spaces or hyphens, so "fine-to-coarse" and
+    /// "firm  to stiff" match "fine to coarse" and "firm to stiff".
     fn matchPattern(self: *Lexer, pattern: []const u8, start_pos: usize) ?usize {
-        if (start_pos + pattern.len > self.input.len) return null;
-
-        const slice = self.input[start_pos .. start_pos + pattern.len];
-        var lower_buf: [64]u8 = undefined;
-        if (slice.len >= lower_buf.len) return null;
-
-        const lower_slice = std.ascii.lowerString(lower_buf[0..slice.len], slice);
-        var lower_pattern_buf: [64]u8 = undefined;
-        if (pattern.len >= lower_pattern_buf.len) return null;
-
-        const lower_pattern = std.ascii.lowerString(lower_pattern_buf[0..pattern.len], pattern);
+        if (start_pos > 0 and !isWordBoundary(self.input[start_pos - 1])) {
+            return null; // Not at word boundary
+        }
 
-        if (std.mem.eql(u8, lower_slice, lower_pattern)) {
-            // Check word boundaries
-            const end_pos = start_pos + pattern.len;
-            if (end_pos < self.input.len and !std.ascii.isWhitespace(self.input[end_pos])) {
-                return null; // Not a complete word
-            }
-            if (start_pos > 0 and !std.ascii.isWhitespace(self.input[start_pos - 1])) {
-                return null; // Not at word boundary
+        var pos = start_pos;
+        for (pattern) |pattern_char| {
+            if (pattern_char == ' ') {
+                const gap_start = pos;
+                while (pos < self.input.len and isGap(self.input[pos])) {
+                    pos += 1;
+                }
+                if (pos == gap_start) return null;
+            } else {
+                if (pos >= self.input.len) return null;
+                if (std.ascii.toLower(self.input[pos]) != std.ascii.toLower(pattern_char)) return null;
+                pos += 1;
             }
-            return end_pos;
         }
 
-        return null;
+        if (pos < self.input.len and !isWordBoundary(self.input[pos])) {
+            return null; // Not a complete word
+        }
+        return pos;
+    }
+
+    /// Token value for a matched pattern: the input text when it is written
+    /// exactly as the pattern, otherwise the canonical pattern text
+    fn patternValue(self: *Lexer, pattern: []const u8, start_pos: usize, end_pos: usize) []const u8 {
+        const matched = self.input[start_pos..end_pos];
+        if (std.ascii.eqlIgnoreCase(matched, pattern)) return matched;
+        return pattern;
     }
 
     fn classifyWord(self: *Lexer, word: []const u8) !ClassificationResult {
@@ -295,7 +355,7 @@ pub const Lexer = struct {
 
         // Only try fuzzy matching if the word looks like it might be a geological term
         // (within reasonable length range and starts with common geological term letters)
-        if (self.mightBeGeologicalTerm(lower)) {
+        if (self.fuzzy_matching and self.mightBeGeologicalTerm(lower)) {
             return try self.tryFuzzyMatch(lower);
         }
 
@@ -321,81 +381,8 @@ pub const Lexer = struct {
     }
 
     fn tryExactMatch(self: *Lexer, lower: []const u8) ClassificationResult {
-        _ = self;
-
-        // Consistency
-        const consistencies = [_][]const u8{ "soft", "firm", "stiff", "hard" };
-        for (consistencies) |c| {
-            if (std.mem.eql(u8, lower, c)) return .{ .token_type = .consistency };
-        }
-
-        // Density
-        const densities = [_][]const u8{ "loose", "dense" };
-        for (densities) |d| {
-            if (std.mem.eql(u8, lower, d)) return .{ .token_type = .density };
-        }
-
-        // Rock strength
-        const rock_strengths = [_][]const u8{ "weak", "strong" };
-        for (rock_strengths) |rs| {
-            if (std.mem.eql(u8, lower, rs)) return .{ .token_type = .rock_strength };
-        }
-
-        // Weathering grade
-        const weathering_grades = [_][]const u8{ "fresh", "weathered" };
-        for (weathering_grades) |wg| {
-            if (std.mem.eql(u8, lower, wg)) return .{ .token_type = .weathering_grade };
-        }
-
-        // Rock structure
-        const rock_structures = [_][]const u8{ "massive", "bedded", "jointed", "fractured", "foliated", "laminated" };
-        for (rock_structures) |rs| {
-            if (std.mem.eql(u8, lower, rs)) return .{ .token_type = .rock_structure };
-        }
-
-        // Proportions
-        const proportions = [_][]const u8{ "slightly", "moderately", "very" };
-        for (proportions) |p| {
-            if (std.mem.eql(u8, lower, p)) return .{ .token_type = .proportion };
-        }
-
-        // Soil types
-        const soil_types = [_][]const u8{ "clay", "silt", "sand", "gravel", "cobbles", "boulders", "peat", "organic" };
-        for (soil_types) |st| {
-            if (std.mem.eql(u8, lower, st)) return .{ .token_type = .soil_type };
-        }
-
-        // Rock types
-        const rock_types = [_][]const u8{ "limestone", "sandstone", "mudstone", "shale", "granite", "basalt", "chalk", "dolomite", "quartzite", "slate", "schist", "gneiss", "marble", "conglomerate", "breccia" };
-        for (rock_types) |rt| {
-            if (std.mem.eql(u8, lower, rt)) return .{ .token_type = .rock_type };
-        }
-
-        // Adjectives
-        const adjectives = [_][]const u8{ "sandy", "silty", "clayey", "gravelly" };
-        for (adjectives) |adj| {
-            if (std.mem.eql(u8, lower, adj)) return .{ .token_type = .adjective };
-        }
-
-        // Colors
-        const colors = [_][]const u8{ "gray", "grey", "brown", "red", "yellow", "orange", "black", "white", "green", "blue", "pink", "purple", "tan", "buff" };
-        for (colors) |color| {
-            if (std.mem.eql(u8, lower, color)) return .{ .token_type = .color };
-        }
-
-        // Moisture content
-        const moisture_contents = [_][]const u8{ "dry", "moist", "wet", "saturated" };
-        for (moisture_contents) |mc| {
-            if (std.mem.eql(u8, lower, mc)) return .{ .token_type = .moisture_content };
-        }
-
-        // Particle size
-        const particle_sizes = [_][]const u8{ "fine", "medium", "coarse" };
-        for (particle_sizes) |ps| {
-            if (std.mem.eql(u8, lower, ps)) return .{ .token_type = .particle_size };
-        }
-
-        return .{ .token_type = .word };
+        const token_type = self.trie.lookup(lower) orelse return .{ .token_type = .word };
+        return .{ .token_type = token_type };
     }
 
     fn tryFuzzyMatch(self: *Lexer, lower: []const u8) !ClassificationResult {
@@ -404,25 +391,6 @@ pub const Lexer = struct {
             return ClassificationResult{ .token_type = .word };
         }
 
-        // Define all term categories with their types
-        const categories = [_]struct {
-            terms: []const []const u8,
-            token_type: TokenType,
-        }{
-            .{ .terms = &[_][]const u8{ "soft", "firm", "stiff", "hard" }, .token_type = .consistency },
-            .{ .terms = &[_][]const u8{ "loose", "dense" }, .token_type = .density },
-            .{ .terms = &[_][]const u8{ "weak", "strong" }, .token_type = .rock_strength },
-            .{ .terms = &[_][]const u8{ "fresh", "weathered" }, .token_type = .weathering_grade },
-            .{ .terms = &[_][]const u8{ "massive", "bedded", "jointed", "fractured", "foliated", "laminated" }, .token_type = .rock_structure },
-            .{ .terms = &[_][]const u8{ "slightly", "moderately", "very" }, .token_type = .proportion },
-            .{ .terms = &[_][]const u8{ "clay", "silt", "sand", "gravel", "cobbles", "boulders", "peat", "organic" }, .token_type = .soil_type },
-            .{ .terms = &[_][]const u8{ "limestone", "sandstone", "mudstone", "shale", "granite", "basalt", "chalk", "dolomite", "quartzite", "slate", "schist", "gneiss", "marble", "conglomerate", "breccia" }, .token_type = .rock_type },
-            .{ .terms = &[_][]const u8{ "sandy", "silty", "clayey", "gravelly" }, .token_type = .adjective },
-            .{ .terms = &[_][]const u8{ "gray", "grey", "brown", "red", "yellow", "orange", "black", "white", "green", "blue", "pink", "purple", "tan", "buff" }, .token_type = .color },
-            .{ .terms = &[_][]const u8{ "dry", "moist", "wet", "saturated" }, .token_type = .moisture_content },
-            .{ .terms = &[_][]const u8{ "fine", "medium", "coarse" }, .token_type = .particle_size },
-        };
-
         var best_match: ?struct {
             term: []const u8,
             token_type: TokenType,
@@ -431,7 +399,7 @@ pub const Lexer = struct {
 
         // Search across all categories for best match
         // Only check terms with similar length (within 3 characters) for performance
-        for (categories) |category| {
+        for (vocabulary.categories) |category| {
             for (category.terms) |term| {
                 // Quick length check before expensive fuzzy matching
                 const len_diff = if (lower.len > term.len) lower.len - term.len else term.len - lower.len;
@@ -440,7 +408,7 @@ pub const Lexer = struct {
                 // Quick first-letter check (most typos preserve first letter)
                 if (lower[0] != term[0]) continue;
 
-                const score = try fuzzy.similarityRatio(lower, term, self.allocator);
+                const score = try fuzzy.compare(lower, term, .{ .algorithm = self.algorithm, .mode = self.match_mode }, self.allocator);
                 if (score >= self.fuzzy_threshold) {
                     if (best_match == null or score > best_match.?.score) {
                         best_match = .{
@@ -0,0 +1,259 @@
+const std = @import("std");
+const types = @import("types.zig");
+const lexer = @import("lexer.zig");
+
+/// Parts of a description that house-style rules can order or require
+pub const LintElement = enum {
+    strength, // consistency, density or rock strength
+    weathering,
+    structure,
+    colour,
+    constituent,
+    particle_size,
+    primary_type,
+
+    pub fn fromString(str: []const u8) ?LintElement {
+        if (std.ascii.eqlIgnoreCase(str, "strength") or std.ascii.eqlIgnoreCase(str, "consistency") or std.ascii.eqlIgnoreCase(str, "density")) return .strength;
+        if (std.ascii.eqlIgnoreCase(str, "weathering")) return .weathering;
+        if (std.ascii.eqlIgnoreCase(str, "structure")) return .structure;
+        if (std.ascii.eqlIgnoreCase(str, "colour") or std.ascii.eqlIgnoreCase(str, "color")) return .colour;
+        if (std.ascii.eqlIgnoreCase(str, "constituent")) return .constituent;
+        if (std.ascii.eqlIgnoreCase(str, "particle_size")) return .particle_size;
+        if (std.ascii.eqlIgnoreCase(str, "primary_type") or std.ascii.eqlIgnoreCase(str, "type")) return .primary_type;
+        return null;
+    }
+
+    pub fn toString(self: LintElement) []const u8 {
+        return switch (self) {
+            .strength => "strength",
+            .weathering => "weathering",
+            .structure => "structure",
+            .colour => "colour",
+            .constituent => "constituent",
+            .particle_size => "particle_size",
+            .primary_type => "primary_type",
+        };
+    }
+
+    fn fromToken(token_type: lexer.TokenType) ?LintElement {
+        return switch (token_type) {
+            .consistency_range, .consistency, .density, .rock_strength => .strength,
+            .weathering_grade => .weathering,
+            .rock_structure => .structure,
+            .color => .colour,
+            .adjective, .proportion => .constituent,
+            .particle_size => .particle_size,
+            .soil_type, .rock_type => .primary_type,
+            else => null,
+        };
+    }
+};
+
+/// A contractor's house style. Unlike compliance checks these are project
+/// conventions rather than requirements of the standard.
+pub const LintRules = struct {
+    /// Longest description allowed, in characters
+    max_length: ?usize = null,
+    /// Mandated order; elements not listed may appear anywhere
+    order: []const LintElement = &.{ .strength, .colour, .constituent, .primary_type },
+    required_soil: []const LintElement = &.{ .strength, .primary_type },
+    required_rock: []const LintElement = &.{ .strength, .weathering, .primary_type },
+};
+
+pub const LintIssue = struct {
+    kind: Kind,
+    element: ?LintElement,
+    message: []const u8,
+
+    pub const Kind = enum {
+        too_long,
+        out_of_order,
+        missing_required,
+
+        pub fn toString(self: Kind) []const u8 {
+            return switch (self) {
+                .too_long => "too_long",
+                .out_of_order => "out_of_order",
+                .missing_required => "missing_required",
+            };
+        }
+    };
+};
+
+pub const LintReport = struct {
+    issues: []LintIssue,
+
+    pub fn passed(self: LintReport) bool {
+        return self.issues.len == 0;
+    }
+
+    pub fn deinit(self: *LintReport, allocator: std.mem.Allocator) void {
+        for (self.issues) |issue| allocator.free(issue.message);
+        allocator.free(self.issues);
+    }
+
+    pub fn format(self: *const LintReport, allocator: std.mem.Allocator) ![]const u8 {
+        var buf = std.ArrayList(u8).init(allocator);
+        errdefer buf.deinit();
+
+        const writer = buf.writer();
+
+        if (self.passed()) {
+            try writer.writeAll("✓ Matches house style\n");
+        } else {
+            try writer.print("✗ House style issues ({d}):\n", .{self.issues.len});
+            for (self.issues, 1..) |issue, idx| {
+                try writer.print("  {d}. [{s}] {s}\n", .{ idx, issue.kind.toString(), issue.message });
+            }
+        }
+
+        return buf.toOwnedSlice();
+    }
+};
+
+pub const HouseStyleLinter = struct {
+    allocator: std.mem.Allocator,
+    rules: LintRules,
+
+    pub fn init(allocator: std.mem.Allocator, rules: LintRules) HouseStyleLinter {
+        return HouseStyleLinter{ .allocator = allocator, .rules = rules };
+    }
+
+    pub fn lint(self: *HouseStyleLinter, description: *const types.GeologicalDescription) !LintReport {
+        var issues = std.ArrayList(LintIssue).init(self.allocator);
+        errdefer {
+            for (issues.items) |issue| self.allocator.free(issue.message);
+            issues.deinit();
+        }
+
+        const text = description.raw_description;
+        if (self.rules.max_length) |max_length| {
+            if (text.len > max_length) {
+                try self.addIssue(&issues, .too_long, null, "Description is {d} characters; house style allows {d}", .{ text.len, max_length });
+            }
+        }
+
+        try self.checkOrder(&issues, text);
+
+        const required = switch (description.material_type) {
+            .soil => self.rules.required_soil,
+            .rock => self.rules.required_rock,
+        };
+        for (required) |element| {
+            if (!isPresent(description, element)) {
+                try self.addIssue(&issues, .missing_required, element, "Missing required {s} for {s} descriptions", .{ element.toString(), description.material_type.toString() });
+            }
+        }
+
+        return LintReport{ .issues = try issues.toOwnedSlice() };
+    }
+
+    /// Compare the first position of each ordered element with its successors
+    fn checkOrder(self: *HouseStyleLinter, issues: *std.ArrayList(LintIssue), text: []const u8) !void {
+        if (self.rules.order.len < 2) return;
+
+        var lex = lexer.Lexer.init(self.allocator, text);
+        defer lex.deinit();
+        const tokens = try lex.tokenize();
+        defer {
+            for (tokens) |token| {
+                if (token.corrected_from) |_| self.allocator.free(token.value);
+            }
+            self.allocator.free(tokens);
+        }
+
+        var first_seen = std.EnumArray(LintElement, ?usize).initFill(null);
+        for (tokens, 0..) |token, i| {
+            const element = LintElement.fromToken(token.type) orelse continue;
+            if (first_seen.get(element) == null) first_seen.set(element, i);
+        }
+
+        var previous: ?LintElement = null;
+        for (self.rules.order) |element| {
+            const position = first_seen.get(element) orelse continue;
+            if (previous) |prev| {
+                if (position < first_seen.get(prev).?) {
+                    try self.addIssue(issues, .out_of_order, element, "{s} should come after {s}", .{ element.toString(), prev.toString() });
+                    continue;
+                }
+            }
+            previous = element;
+        }
+    }
+
+    fn addIssue(
+        self: *HouseStyleLinter,
+        issues: *std.ArrayList(LintIssue),
+        kind: LintIssue.Kind,
+        element: ?LintElement,
+        comptime fmt: []const u8,
+        args: anytype,
+    ) !void {
+        const message = try std.fmt.allocPrint(self.allocator, fmt, args);
+        errdefer self.allocator.free(message);
+        try issues.append(LintIssue{ .kind = kind, .element = element, .message = message });
+    }
+};
+
+fn isPresent(description: *const types.GeologicalDescription, element: LintElement) bool {
+    return switch (element) {
+        .strength => switch (description.material_type) {
+            .soil => description.consistency != null or description.density != null,
+            .rock => description.rock_strength != null,
+        },
+        .weathering => description.weathering_grade != null,
+        .structure => description.rock_structure != null,
+        .colour => description.color != null or description.colour_detail != null,
+        .constituent => description.secondary_constituents.len > 0,
+        .particle_size => description.particle_size != null,
+        .primary_type => switch (description.material_type) {
+            .soil => description.primary_soil_type != null or description.is_made_ground or description.is_topsoil,
+            .rock => description.primary_rock_type != null,
+        },
+    };
+}
+
+test "lint house style order and length" {
+    const allocator = std.testing.allocator;
+
+    // Colour before consistency, as some contractors mandate
+    var linter = HouseStyleLinter.init(allocator, .{
+        .max_length = 20,
+        .order = &.{ .colour, .strength, .primary_type },
+    });
+
+    const description = types.SoilDescription{
+        .raw_description = "Firm brown slightly sandy CLAY",
+        .material_type = .soil,
+        .consistency = .firm,
+        .color = .brown,
+        .primary_soil_type = .clay,
+    };
+
+    var report = try linter.lint(&description);
+    defer report.deinit(allocator);
+
+    try std.testing.expectEqual(@as(usize, 2), report.issues.len);
+    try std.testing.expectEqual(LintIssue.Kind.too_long, report.issues[0].kind);
+    try std.testing.expectEqual(LintIssue.Kind.out_of_order, report.issues[1].kind);
+    try std.testing.expectEqual(LintElement.strength, report.issues[1].element.?);
+}
+
+test "lint required fields per material type" {
+    const allocator = std.testing.allocator;
+    var linter = HouseStyleLinter.init(allocator, .{});
+
+    const description = types.SoilDescription{
+        .raw_description = "Strong LIMESTONE",
+        .material_type = .rock,
+        .rock_strength = .strong,
+        .primary_rock_type = .limestone,
+    };
+
+    var report = try linter.lint(&description);
+    defer report.deinit(allocator);
+
+    try std.testing.expect(!report.passed());
+    try std.testing.expectEqual(LintIssue.Kind.missing_required, report.issues[0].kind);
+    try std.testing.expectEqual(LintElement.weathering, report.issues[0].element.?);
+}
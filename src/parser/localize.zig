@@ -0,0 +1,229 @@
+const std = @import("std");
+const types = @import("types.zig");
+
+const MaterialType = types.MaterialType;
+const Consistency = types.Consistency;
+const Density = types.Density;
+const SoilType = types.SoilType;
+const RockType = types.RockType;
+const RockStrength = types.RockStrength;
+const WeatheringGrade = types.WeatheringGrade;
+const Sensitivity = types.Sensitivity;
+
+/// Languages for report display strings. Parsing is always in English.
+pub const Locale = enum {
+    en,
+    fr,
+    de,
+    es,
+    nb, // Norwegian Bokmål
+    /// For tests: the English term between `pseudo_open` and `pseudo_close`
+    /// ("⟦firm⟧"), so text that did not go through `localize` stands out
+    pseudo,
+
+    /// "fr", "de-DE", "es_ES", "no" or "nb"; case-insensitive. The
+    /// pseudo-locale is "qps-ploc" or "qps".
+    pub fn fromString(str: []const u8) ?Locale {
+        const end = std.mem.indexOfAny(u8, str, "-_") orelse str.len;
+        if (end == 3 and std.ascii.eqlIgnoreCase(str[0..3], "qps")) return .pseudo;
+        if (end != 2) return null;
+        var lower: [2]u8 = undefined;
+        _ = std.ascii.lowerString(&lower, str[0..2]);
+        if (std.mem.eql(u8, &lower, "no")) return .nb;
+        return std.meta.stringToEnum(Locale, &lower);
+    }
+};
+
+const Translation = struct {
+    fr: []const u8,
+    de: []const u8,
+    es: []const u8,
+    nb: []const u8,
+};
+
+fn Table(comptime T: type) type {
+    return std.EnumArray(T, Translation);
+}
+
+const material_types = Table(MaterialType).init(.{
+    .soil = .{ .fr = "sol", .de = "Boden", .es = "suelo", .nb = "løsmasse" },
+    .rock = .{ .fr = "roche", .de = "Fels", .es = "roca", .nb = "berg" },
+});
+
+const consistencies = Table(Consistency).init(.{
+    .very_soft = .{ .fr = "très molle", .de = "breiig", .es = "muy blanda", .nb = "svært bløt" },
+    .soft = .{ .fr = "molle", .de = "weich", .es = "blanda", .nb = "bløt" },
+    .firm = .{ .fr = "ferme", .de = "steif", .es = "firme", .nb = "middels fast" },
+    .stiff = .{ .fr = "raide", .de = "halbfest", .es = "rígida", .nb = "fast" },
+    .very_stiff = .{ .fr = "très raide", .de = "fest", .es = "muy rígida", .nb = "svært fast" },
+    .hard = .{ .fr = "dure", .de = "hart", .es = "dura", .nb = "hard" },
+    .soft_to_firm = .{ .fr = "molle à ferme", .de = "weich bis steif", .es = "blanda a firme", .nb = "bløt til middels fast" },
+    .firm_to_stiff = .{ .fr = "ferme à raide", .de = "steif bis halbfest", .es = "firme a rígida", .nb = "middels fast til fast" },
+    .stiff_to_very_stiff = .{ .fr = "raide à très raide", .de = "halbfest bis fest", .es = "rígida a muy rígida", .nb = "fast til svært fast" },
+});
+
+const densities = Table(Density).init(.{
+    .very_loose = .{ .fr = "très lâche", .de = "sehr locker", .es = "muy suelta", .nb = "svært løst lagret" },
+    .loose = .{ .fr = "lâche", .de = "locker", .es = "suelta", .nb = "løst lagret" },
+    .loose_to_medium_dense = .{ .fr = "lâche à moyennement dense", .de = "locker bis mitteldicht", .es = "suelta a medianamente densa", .nb = "løst til middels fast lagret" },
+    .medium_dense = .{ .fr = "moyennement dense", .de = "mitteldicht", .es = "medianamente densa", .nb = "middels fast lagret" },
+    .medium_dense_to_dense = .{ .fr = "moyennement dense à dense", .de = "mitteldicht bis dicht", .es = "medianamente densa a densa", .nb = "middels til fast lagret" },
+    .dense = .{ .fr = "dense", .de = "dicht", .es = "densa", .nb = "fast lagret" },
+    .very_dense = .{ .fr = "très dense", .de = "sehr dicht", .es = "muy densa", .nb = "svært fast lagret" },
+});
+
+const soil_types = Table(SoilType).init(.{
+    .clay = .{ .fr = "ARGILE", .de = "TON", .es = "ARCILLA", .nb = "LEIRE" },
+    .silt = .{ .fr = "LIMON", .de = "SCHLUFF", .es = "LIMO", .nb = "SILT" },
+    .sand = .{ .fr = "SABLE", .de = "SAND", .es = "ARENA", .nb = "SAND" },
+    .gravel = .{ .fr = "GRAVIER", .de = "KIES", .es = "GRAVA", .nb = "GRUS" },
+    .cobbles = .{ .fr = "GALETS", .de = "STEINE", .es = "CANTOS", .nb = "STEIN" },
+    .boulders = .{ .fr = "BLOCS", .de = "BLÖCKE", .es = "BOLOS", .nb = "BLOKK" },
+    .peat = .{ .fr = "TOURBE", .de = "TORF", .es = "TURBA", .nb = "TORV" },
+    .organic = .{ .fr = "ORGANIQUE", .de = "ORGANISCH", .es = "ORGÁNICO", .nb = "ORGANISK" },
+});
+
+const rock_types = Table(RockType).init(.{
+    .limestone = .{ .fr = "CALCAIRE", .de = "KALKSTEIN", .es = "CALIZA", .nb = "KALKSTEIN" },
+    .sandstone = .{ .fr = "GRÈS", .de = "SANDSTEIN", .es = "ARENISCA", .nb = "SANDSTEIN" },
+    .mudstone = .{ .fr = "ARGILITE", .de = "TONSTEIN", .es = "LODOLITA", .nb = "SLAMSTEIN" },
+    .shale = .{ .fr = "SCHISTE ARGILEUX", .de = "SCHIEFERTON", .es = "LUTITA", .nb = "LEIRSKIFER" },
+    .granite = .{ .fr = "GRANITE", .de = "GRANIT", .es = "GRANITO", .nb = "GRANITT" },
+    .basalt = .{ .fr = "BASALTE", .de = "BASALT", .es = "BASALTO", .nb = "BASALT" },
+    .chalk = .{ .fr = "CRAIE", .de = "KREIDE", .es = "CRETA", .nb = "KRITT" },
+    .dolomite = .{ .fr = "DOLOMIE", .de = "DOLOMIT", .es = "DOLOMÍA", .nb = "DOLOMITT" },
+    .quartzite = .{ .fr = "QUARTZITE", .de = "QUARZIT", .es = "CUARCITA", .nb = "KVARTSITT" },
+    .slate = .{ .fr = "ARDOISE", .de = "TONSCHIEFER", .es = "PIZARRA", .nb = "SKIFER" },
+    .schist = .{ .fr = "SCHISTE", .de = "GLIMMERSCHIEFER", .es = "ESQUISTO", .nb = "GLIMMERSKIFER" },
+    .gneiss = .{ .fr = "GNEISS", .de = "GNEIS", .es = "GNEIS", .nb = "GNEIS" },
+    .marble = .{ .fr = "MARBRE", .de = "MARMOR", .es = "MÁRMOL", .nb = "MARMOR" },
+    .conglomerate = .{ .fr = "CONGLOMÉRAT", .de = "KONGLOMERAT", .es = "CONGLOMERADO", .nb = "KONGLOMERAT" },
+    .breccia = .{ .fr = "BRÈCHE", .de = "BREKZIE", .es = "BRECHA", .nb = "BREKSJE" },
+});
+
+const rock_strengths = Table(RockStrength).init(.{
+    .very_weak = .{ .fr = "très faible", .de = "sehr gering fest", .es = "muy débil", .nb = "svært svak" },
+    .weak = .{ .fr = "faible", .de = "gering fest", .es = "débil", .nb = "svak" },
+    .moderately_weak = .{ .fr = "moyennement faible", .de = "mäßig gering fest", .es = "moderadamente débil", .nb = "middels svak" },
+    .moderately_strong = .{ .fr = "moyennement résistante", .de = "mäßig fest", .es = "moderadamente resistente", .nb = "middels sterk" },
+    .strong = .{ .fr = "résistante", .de = "fest", .es = "resistente", .nb = "sterk" },
+    .very_strong = .{ .fr = "très résistante", .de = "sehr fest", .es = "muy resistente", .nb = "svært sterk" },
+    .extremely_strong = .{ .fr = "extrêmement résistante", .de = "äußerst fest", .es = "extremadamente resistente", .nb = "ekstremt sterk" },
+});
+
+const weathering_grades = Table(WeatheringGrade).init(.{
+    .fresh = .{ .fr = "saine", .de = "unverwittert", .es = "sana", .nb = "frisk" },
+    .slightly_weathered = .{ .fr = "légèrement altérée", .de = "schwach verwittert", .es = "ligeramente meteorizada", .nb = "lett forvitret" },
+    .moderately_weathered = .{ .fr = "moyennement altérée", .de = "mäßig verwittert", .es = "moderadamente meteorizada", .nb = "middels forvitret" },
+    .highly_weathered = .{ .fr = "fortement altérée", .de = "stark verwittert", .es = "altamente meteorizada", .nb = "sterkt forvitret" },
+    .completely_weathered = .{ .fr = "complètement altérée", .de = "vollständig verwittert", .es = "completamente meteorizada", .nb = "fullstendig forvitret" },
+});
+
+const sensitivities = Table(Sensitivity).init(.{
+    .low = .{ .fr = "faible", .de = "gering", .es = "baja", .nb = "lav" },
+    .medium = .{ .fr = "moyenne", .de = "mittel", .es = "media", .nb = "middels" },
+    .high = .{ .fr = "élevée", .de = "hoch", .es = "alta", .nb = "høy" },
+    .brittle = .{ .fr = "fragile", .de = "sprödbrüchig", .es = "frágil", .nb = "sprøbrudd" },
+    .quick = .{ .fr = "extra-sensible", .de = "Quickton", .es = "extrasensible", .nb = "kvikk" },
+});
+
+fn tableFor(comptime T: type) Table(T) {
+    if (T == MaterialType) return material_types;
+    if (T == Consistency) return consistencies;
+    if (T == Density) return densities;
+    if (T == SoilType) return soil_types;
+    if (T == RockType) return rock_types;
+    if (T == RockStrength) return rock_strengths;
+    if (T == WeatheringGrade) return weathering_grades;
+    if (T == Sensitivity) return sensitivities;
+    @compileError("no translations for " ++ @typeName(T));
+}
+
+/// Markers around each term in the pseudo-locale
+pub const pseudo_open = "⟦";
+pub const pseudo_close = "⟧";
+
+/// The marked English terms of `T`, built once at compile time
+fn pseudoTable(comptime T: type) std.EnumArray(T, []const u8) {
+    @setEvalBranchQuota(10_000);
+    var table = std.EnumArray(T, []const u8).initUndefined();
+    for (std.enums.values(T)) |value| {
+        table.set(value, std.fmt.comptimePrint("{s}{s}{s}", .{ pseudo_open, value.toString(), pseudo_close }));
+    }
+    return table;
+}
+
+/// Display string for a classification term in `locale`. English gives the
+/// usual `toString()`. Translations are the terms a local engineer would
+/// use on a log, not word-for-word renderings of the BS 5930 wording.
+pub fn localize(value: anytype, locale: Locale) []const u8 {
+    if (locale == .en) return value.toString();
+    if (locale == .pseudo) {
+        const table = comptime pseudoTable(@TypeOf(value));
+        return table.get(value);
+    }
+    const translation = tableFor(@TypeOf(value)).get(value);
+    return switch (locale) {
+        .en, .pseudo => unreachable,
+        .fr => translation.fr,
+        .de => translation.de,
+        .es => translation.es,
+        .nb => translation.nb,
+    };
+}
+
+/// Every English term `localize` translates, for `findUnlocalized`
+const english_terms = blk: {
+    @setEvalBranchQuota(10_000);
+    var terms: []const []const u8 = &.{};
+    for ([_]type{ MaterialType, Consistency, Density, SoilType, RockType, RockStrength, WeatheringGrade, Sensitivity }) |T| {
+        for (std.enums.values(T)) |value| terms = terms ++ &[_][]const u8{value.toString()};
+    }
+    break :blk terms;
+};
+
+/// The first English classification term in `text` outside pseudo-locale
+/// markers, or null. Render a report with `.pseudo` and check it with this
+/// to catch terms written without going through `localize`.
+pub fn findUnlocalized(text: []const u8) ?[]const u8 {
+    var pos: usize = 0;
+    while (pos < text.len) {
+        if (std.mem.startsWith(u8, text[pos..], pseudo_open)) {
+            const close = std.mem.indexOfPos(u8, text, pos, pseudo_close) orelse return null;
+            pos = close + pseudo_close.len;
+            continue;
+        }
+        if (pos == 0 or !std.ascii.isAlphanumeric(text[pos - 1])) {
+            var longest: ?[]const u8 = null;
+            for (english_terms) |term| {
+                if (!std.ascii.startsWithIgnoreCase(text[pos..], term)) continue;
+                const end = pos + term.len;
+                if (end < text.len and std.ascii.isAlphanumeric(text[end])) continue;
+                if (longest == null or term.len > longest.?.len) longest = text[pos..end];
+            }
+            if (longest) |term| return term;
+        }
+        pos += 1;
+    }
+    return null;
+}
+
+test "localize translates terms and keeps English as-is" {
+    try std.testing.expectEqualStrings("firm", localize(Consistency.firm, .en));
+    try std.testing.expectEqualStrings("ferme", localize(Consistency.firm, .fr));
+    try std.testing.expectEqualStrings("TON", localize(SoilType.clay, .de));
+    try std.testing.expectEqualStrings("muy densa", localize(Density.very_dense, .es));
+    try std.testing.expectEqualStrings("lett forvitret", localize(WeatheringGrade.slightly_weathered, Locale.fromString("no").?));
+    try std.testing.expectEqual(Locale.de, Locale.fromString("DE-at").?);
+    try std.testing.expect(Locale.fromString("english") == null);
+}
+
+test "pseudo-locale marks terms so hard-coded English can be found" {
+    try std.testing.expectEqualStrings("⟦firm⟧", localize(Consistency.firm, .pseudo));
+    try std.testing.expectEqualStrings("⟦CLAY⟧", localize(SoilType.clay, Locale.fromString("qps-ploc").?));
+
+    try std.testing.expect(findUnlocalized("Consistency: ⟦very stiff⟧, type: ⟦CLAY⟧") == null);
+    try std.testing.expectEqualStrings("very stiff", findUnlocalized("Consistency: very stiff, type: ⟦CLAY⟧").?);
+    try std.testing.expectEqualStrings("Clay", findUnlocalized("Clay content").?);
+    try std.testing.expect(findUnlocalized("Claystone content") == null);
+}
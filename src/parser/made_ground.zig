@@ -0,0 +1,154 @@
+const std = @import("std");
+const types = @import("types.zig");
+const scan = @import("scan.zig");
+
+const AnthropogenicMaterial = types.AnthropogenicMaterial;
+const Frequency = types.Frequency;
+const MadeGroundConstituent = types.MadeGroundConstituent;
+const Word = scan.Word;
+
+/// A man-made material named in a description
+pub const Match = struct {
+    constituent: MadeGroundConstituent,
+    start: usize,
+    end: usize,
+};
+
+/// Words that may stand between a frequency and the material it applies to
+/// ("occasional fragments of brick", "frequent brick and concrete pieces")
+const linking_words = [_][]const u8{
+    "and", "of", "fragments", "fragment", "pieces", "piece", "fine", "coarse",
+    "angular", "subangular", "red", "grey", "black", "broken",
+};
+
+/// Steps through the man-made materials in a description in order. A
+/// frequency carries over "and" and commas to the materials after it, so
+/// "occasional brick and concrete fragments" is occasional brick and
+/// occasional concrete. "Volcanic ash" is natural and is skipped.
+pub const Iterator = struct {
+    text: []const u8,
+    pos: usize = 0,
+    frequency: ?Frequency = null,
+    previous: []const u8 = "",
+
+    pub fn next(self: *Iterator) ?Match {
+        while (scan.next(self.text, self.pos, .letters)) |word| {
+            self.pos = word.end;
+            const previous = self.previous;
+            self.previous = word.text;
+            defer if (word.stop or word.bracket) self.frequency = null;
+
+            if (Frequency.fromString(word.text)) |frequency| {
+                self.frequency = frequency;
+                continue;
+            }
+            if (AnthropogenicMaterial.fromString(word.text)) |material| {
+                if (material == .ash and std.ascii.eqlIgnoreCase(previous, "volcanic")) continue;
+                if (material == .plastic and !isPlasticItem(self.text, word)) continue;
+                return Match{
+                    .constituent = .{ .material = material, .frequency = self.frequency },
+                    .start = word.start,
+                    .end = word.end,
+                };
+            }
+            if (!isLinkingWord(word.text)) self.frequency = null;
+        }
+        return null;
+    }
+};
+
+pub fn iterate(text: []const u8) Iterator {
+    return Iterator{ .text = text };
+}
+
+/// Each material once, at the first place it is named. Caller owns the
+/// result.
+pub fn findAll(allocator: std.mem.Allocator, text: []const u8) ![]Match {
+    var matches = std.ArrayList(Match).init(allocator);
+    errdefer matches.deinit();
+
+    var seen = std.EnumSet(AnthropogenicMaterial){};
+    var it = iterate(text);
+    while (it.next()) |match| {
+        if (seen.contains(match.constituent.material)) continue;
+        seen.insert(match.constituent.material);
+        try matches.append(match);
+    }
+    return matches.toOwnedSlice();
+}
+
+/// Whether the constituents alone show made ground, without a MADE GROUND
+/// label. Brick, concrete, asphalt and slag are never natural; anything
+/// else ("chalk rubble", "tile drain fragments" in topsoil) needs a second
+/// man-made material beside it.
+pub fn indicatesMadeGround(constituents: []const MadeGroundConstituent) bool {
+    for (constituents) |constituent| {
+        switch (constituent.material) {
+            .brick, .concrete, .asphalt, .slag => return true,
+            else => {},
+        }
+    }
+    return constituents.len >= 2;
+}
+
+/// "plastic" alone is usually plasticity ("slightly plastic CLAY"), so the
+/// material needs the plural or a noun after it ("plastic sheeting")
+fn isPlasticItem(text: []const u8, word: Word) bool {
+    if (!std.ascii.eqlIgnoreCase(word.text, "plastic")) return true;
+    const after = scan.next(text, word.end, .letters) orelse return false;
+    for ([_][]const u8{ "fragments", "fragment", "pieces", "piece", "sheeting", "sheet", "bags", "bag", "waste" }) |noun| {
+        if (std.ascii.eqlIgnoreCase(after.text, noun)) return true;
+    }
+    return false;
+}
+
+fn isLinkingWord(word: []const u8) bool {
+    for (linking_words) |linking| {
+        if (std.ascii.eqlIgnoreCase(word, linking)) return true;
+    }
+    return false;
+}
+
+test "find made ground constituents" {
+    const allocator = std.testing.allocator;
+    const matches = try findAll(allocator, "firm brown sandy clay with occasional brick and concrete fragments, rare glass and frequent ash");
+    defer allocator.free(matches);
+
+    try std.testing.expectEqual(@as(usize, 4), matches.len);
+    try std.testing.expectEqual(AnthropogenicMaterial.brick, matches[0].constituent.material);
+    try std.testing.expectEqual(Frequency.occasional, matches[0].constituent.frequency.?);
+    try std.testing.expectEqual(AnthropogenicMaterial.concrete, matches[1].constituent.material);
+    try std.testing.expectEqual(Frequency.occasional, matches[1].constituent.frequency.?);
+    try std.testing.expectEqual(AnthropogenicMaterial.glass, matches[2].constituent.material);
+    try std.testing.expectEqual(Frequency.rare, matches[2].constituent.frequency.?);
+    try std.testing.expectEqual(AnthropogenicMaterial.ash, matches[3].constituent.material);
+    try std.testing.expectEqual(Frequency.frequent, matches[3].constituent.frequency.?);
+}
+
+test "made ground synonyms and natural materials" {
+    const allocator = std.testing.allocator;
+    const matches = try findAll(allocator, "black sandy GRAVEL of clinker, cinders and tarmac with bricks");
+    defer allocator.free(matches);
+
+    try std.testing.expectEqual(@as(usize, 3), matches.len);
+    try std.testing.expectEqual(AnthropogenicMaterial.clinker, matches[0].constituent.material);
+    try std.testing.expectEqual(AnthropogenicMaterial.asphalt, matches[1].constituent.material);
+    try std.testing.expectEqual(AnthropogenicMaterial.brick, matches[2].constituent.material);
+    try std.testing.expect(matches[2].constituent.frequency == null);
+
+    var natural = iterate("stiff grey CLAY with bands of volcanic ash");
+    try std.testing.expect(natural.next() == null);
+    var clay = iterate("Firm brown slightly plastic CLAY");
+    try std.testing.expect(clay.next() == null);
+    var sheeting = iterate("MADE GROUND of clay with plastic sheeting");
+    try std.testing.expectEqual(AnthropogenicMaterial.plastic, sheeting.next().?.constituent.material);
+}
+
+test "made ground needs a telling material or two" {
+    const brick = [_]MadeGroundConstituent{.{ .material = .brick }};
+    try std.testing.expect(indicatesMadeGround(&brick));
+    const rubble = [_]MadeGroundConstituent{.{ .material = .rubble }};
+    try std.testing.expect(!indicatesMadeGround(&rubble));
+    const mixed = [_]MadeGroundConstituent{ .{ .material = .glass }, .{ .material = .timber } };
+    try std.testing.expect(indicatesMadeGround(&mixed));
+}
@@ -0,0 +1,87 @@
+const std = @import("std");
+const types = @import("types.zig");
+
+const MarineIndicator = types.MarineIndicator;
+
+/// A marine indicator phrase found in a description
+pub const Match = struct {
+    indicator: MarineIndicator,
+    start: usize,
+    end: usize,
+};
+
+const phrases = [_]struct { phrase: []const u8, indicator: MarineIndicator }{
+    .{ .phrase = "shell fragments", .indicator = .shell_fragments },
+    .{ .phrase = "shell fragment", .indicator = .shell_fragments },
+    .{ .phrase = "shell debris", .indicator = .shell_fragments },
+    .{ .phrase = "shell hash", .indicator = .shell_fragments },
+    .{ .phrase = "shells", .indicator = .shell_fragments },
+    .{ .phrase = "shelly", .indicator = .shell_fragments },
+    .{ .phrase = "h2s", .indicator = .h2s_odour },
+    .{ .phrase = "h2s odour", .indicator = .h2s_odour },
+    .{ .phrase = "h2s odor", .indicator = .h2s_odour },
+    .{ .phrase = "hydrogen sulphide", .indicator = .h2s_odour },
+    .{ .phrase = "hydrogen sulfide", .indicator = .h2s_odour },
+    .{ .phrase = "hydrogen sulphide odour", .indicator = .h2s_odour },
+    .{ .phrase = "hydrogen sulfide odor", .indicator = .h2s_odour },
+    .{ .phrase = "rotten egg odour", .indicator = .h2s_odour },
+    .{ .phrase = "coral", .indicator = .coral },
+    .{ .phrase = "coral fragments", .indicator = .coral },
+    .{ .phrase = "foraminifera", .indicator = .foraminifera },
+    .{ .phrase = "forams", .indicator = .foraminifera },
+    .{ .phrase = "bioturbated", .indicator = .bioturbation },
+    .{ .phrase = "bioturbation", .indicator = .bioturbation },
+    .{ .phrase = "worm burrows", .indicator = .bioturbation },
+    .{ .phrase = "carbonate", .indicator = .carbonate },
+};
+
+/// Walks the marine indicator phrases in a description ("with shell
+/// fragments, H2S odour") left to right, longest phrase first at each
+/// position. Whole words only, case-insensitive.
+pub const Iterator = struct {
+    text: []const u8,
+    pos: usize = 0,
+
+    pub fn next(self: *Iterator) ?Match {
+        while (self.pos < self.text.len) : (self.pos += 1) {
+            if (self.pos > 0 and std.ascii.isAlphanumeric(self.text[self.pos - 1])) continue;
+
+            var best: ?Match = null;
+            for (phrases) |entry| {
+                if (!std.ascii.startsWithIgnoreCase(self.text[self.pos..], entry.phrase)) continue;
+                const end = self.pos + entry.phrase.len;
+                if (end < self.text.len and std.ascii.isAlphanumeric(self.text[end])) continue;
+                if (best) |current| {
+                    if (current.end >= end) continue;
+                }
+                best = Match{ .indicator = entry.indicator, .start = self.pos, .end = end };
+            }
+            if (best) |match| {
+                self.pos = match.end;
+                return match;
+            }
+        }
+        return null;
+    }
+};
+
+pub fn iterate(text: []const u8) Iterator {
+    return Iterator{ .text = text };
+}
+
+test "iterate marine indicators" {
+    var matches = iterate("very soft dark grey organic clayey SILT with shell fragments, H2S odour");
+
+    const shells = matches.next().?;
+    try std.testing.expectEqual(MarineIndicator.shell_fragments, shells.indicator);
+    try std.testing.expectEqual(@as(usize, 45), shells.start);
+
+    const odour = matches.next().?;
+    try std.testing.expectEqual(MarineIndicator.h2s_odour, odour.indicator);
+    try std.testing.expectEqualStrings("H2S odour", "very soft dark grey organic clayey SILT with shell fragments, H2S odour"[odour.start..odour.end]);
+
+    try std.testing.expect(matches.next() == null);
+
+    var none = iterate("Firm brown CLAY with seashells");
+    try std.testing.expect(none.next() == null);
+}
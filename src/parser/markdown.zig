@@ -0,0 +1,102 @@
+const std = @import("std");
+const types = @import("types.zig");
+const csvio = @import("csvio.zig");
+
+const GeologicalDescription = types.GeologicalDescription;
+
+/// A GitHub-flavoured Markdown table with one row per result, for pasting
+/// parse summaries into issues and wiki pages. Columns are field paths as
+/// for CSV export (`csvio.default_columns` is a good start); numeric columns
+/// are right-aligned. Returns caller-owned text.
+pub fn table(allocator: std.mem.Allocator, results: []const GeologicalDescription, columns: []const []const u8) ![]u8 {
+    const options = csvio.Options{ .columns = columns, .separator = ", " };
+
+    // Resolve every cell first so column alignment can be decided
+    const cells = try allocator.alloc([]u8, results.len * columns.len);
+    var filled: usize = 0;
+    defer {
+        for (cells[0..filled]) |text| allocator.free(text);
+        allocator.free(cells);
+    }
+    var cell = std.ArrayList(u8).init(allocator);
+    defer cell.deinit();
+    for (results) |*desc| {
+        for (columns) |column| {
+            cell.clearRetainingCapacity();
+            try csvio.resolveColumn(&cell, desc, column, options);
+            cells[filled] = try allocator.dupe(u8, cell.items);
+            filled += 1;
+        }
+    }
+
+    var out = std.ArrayList(u8).init(allocator);
+    errdefer out.deinit();
+    const writer = out.writer();
+
+    try writer.writeByte('|');
+    for (columns) |column| {
+        try writer.writeByte(' ');
+        try writeEscaped(writer, column);
+        try writer.writeAll(" |");
+    }
+    try writer.writeAll("\n|");
+    for (columns, 0..) |_, col| {
+        try writer.writeAll(if (isNumeric(cells, columns.len, col)) " ---: |" else " --- |");
+    }
+    try writer.writeByte('\n');
+
+    for (0..results.len) |row| {
+        try writer.writeByte('|');
+        for (cells[row * columns.len ..][0..columns.len]) |text| {
+            try writer.writeByte(' ');
+            try writeEscaped(writer, text);
+            try writer.writeAll(" |");
+        }
+        try writer.writeByte('\n');
+    }
+    return out.toOwnedSlice();
+}
+
+/// Every non-empty cell in the column is a number, and there is at least one
+fn isNumeric(cells: []const []u8, width: usize, col: usize) bool {
+    var any = false;
+    var index = col;
+    while (index < cells.len) : (index += width) {
+        if (cells[index].len == 0) continue;
+        _ = std.fmt.parseFloat(f64, cells[index]) catch return false;
+        any = true;
+    }
+    return any;
+}
+
+/// Pipes would end the cell and newlines the row
+fn writeEscaped(writer: anytype, text: []const u8) !void {
+    for (text) |ch| {
+        switch (ch) {
+            '|' => try writer.writeAll("\\|"),
+            '\n' => try writer.writeAll("<br>"),
+            '\r' => {},
+            else => try writer.writeByte(ch),
+        }
+    }
+}
+
+test "markdown table aligns numbers and escapes pipes" {
+    const allocator = std.testing.allocator;
+
+    const results = [_]GeologicalDescription{
+        .{ .raw_description = "Firm CLAY | sample A", .material_type = .soil, .consistency = .firm, .confidence = 0.9 },
+        .{ .raw_description = "Dense SAND", .material_type = .soil, .density = .dense, .confidence = 1.0 },
+    };
+
+    const text = try table(allocator, &results, &.{ "raw_description", "consistency", "confidence" });
+    defer allocator.free(text);
+
+    try std.testing.expectEqualStrings(
+        \\| raw_description | consistency | confidence |
+        \\| --- | --- | ---: |
+        \\| Firm CLAY \| sample A | firm | 0.9 |
+        \\| Dense SAND |  | 1 |
+        \\
+    , text);
+}
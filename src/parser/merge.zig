@@ -0,0 +1,194 @@
+const std = @import("std");
+const types = @import("types.zig");
+
+const Field = types.Field;
+const GeologicalDescription = types.GeologicalDescription;
+const ObjectMap = std.json.ObjectMap;
+
+/// Which description wins a field that both give with different values
+pub const Policy = enum {
+    prefer_first,
+    prefer_second,
+    /// The description with the higher confidence, the first on a tie
+    prefer_confident,
+
+    pub fn toString(self: Policy) []const u8 {
+        return @tagName(self);
+    }
+};
+
+pub const Side = enum {
+    first,
+    second,
+
+    pub fn toString(self: Side) []const u8 {
+        return @tagName(self);
+    }
+};
+
+/// A field the two descriptions disagree on. The values are the field's
+/// JSON members ("{\"consistency\":\"firm\"}").
+pub const Conflict = struct {
+    field: Field,
+    kept: Side,
+    first: []const u8,
+    second: []const u8,
+};
+
+pub const Merged = struct {
+    description: GeologicalDescription,
+    /// In `Field` order
+    conflicts: []Conflict,
+
+    pub fn deinit(self: Merged, allocator: std.mem.Allocator) void {
+        for (self.conflicts) |conflict| {
+            allocator.free(conflict.first);
+            allocator.free(conflict.second);
+        }
+        allocator.free(self.conflicts);
+        self.description.deinit(allocator);
+    }
+};
+
+/// Keys written for every description rather than for one field
+const shared_keys = [_][]const u8{ "raw_description", "sources", "spans", "warnings", "confidence", "is_valid" };
+
+/// Combine two partial descriptions of the same stratum, such as a driller's
+/// and an engineer's log. Each field comes from whichever description gives
+/// it; a field both give with different values is taken by `policy` and
+/// reported as a conflict. Sources are kept per field. The raw descriptions
+/// are joined with "; ", warnings are concatenated, and the confidence is the
+/// lower of the two. Spans are dropped, since they point into the separate
+/// raw descriptions. Caller owns the result.
+pub fn merge(allocator: std.mem.Allocator, first: GeologicalDescription, second: GeologicalDescription, policy: Policy) !Merged {
+    var arena_state = std.heap.ArenaAllocator.init(allocator);
+    defer arena_state.deinit();
+    const arena = arena_state.allocator();
+
+    const preferred: Side = switch (policy) {
+        .prefer_first => .first,
+        .prefer_second => .second,
+        .prefer_confident => if (second.confidence > first.confidence) .second else .first,
+    };
+
+    var conflicts = std.ArrayList(Conflict).init(allocator);
+    errdefer {
+        for (conflicts.items) |conflict| {
+            allocator.free(conflict.first);
+            allocator.free(conflict.second);
+        }
+        conflicts.deinit();
+    }
+
+    var object = ObjectMap.init(arena);
+    var sources = ObjectMap.init(arena);
+    for (std.enums.values(Field)) |field| {
+        const in_first = first.hasField(field);
+        const in_second = second.hasField(field);
+        if (!in_first and !in_second) continue;
+
+        var side: Side = if (in_first) .first else .second;
+        if (in_first and in_second) {
+            side = preferred;
+            const first_json = try membersJson(arena, first, field);
+            const second_json = try membersJson(arena, second, field);
+            if (!std.mem.eql(u8, first_json, second_json)) {
+                const first_copy = try allocator.dupe(u8, first_json);
+                errdefer allocator.free(first_copy);
+                const second_copy = try allocator.dupe(u8, second_json);
+                errdefer allocator.free(second_copy);
+                try conflicts.append(.{ .field = field, .kept = side, .first = first_copy, .second = second_copy });
+            }
+        }
+
+        const from = if (side == .first) first else second;
+        const members = try membersOf(arena, from, field);
+        var it = members.iterator();
+        while (it.next()) |entry| try object.put(entry.key_ptr.*, entry.value_ptr.*);
+        if (from.sourceOf(field)) |source| try sources.put(@tagName(field), .{ .string = source.toString() });
+    }
+
+    const raw = try std.fmt.allocPrint(arena, "{s}; {s}", .{ first.raw_description, second.raw_description });
+    try object.put("raw_description", .{ .string = raw });
+    if (sources.count() > 0) try object.put("sources", .{ .object = sources });
+    try object.put("confidence", .{ .float = @min(first.confidence, second.confidence) });
+    try object.put("is_valid", .{ .bool = first.is_valid and second.is_valid });
+
+    const json = try std.json.stringifyAlloc(arena, std.json.Value{ .object = object }, .{});
+    var description = try GeologicalDescription.fromJson(json, allocator);
+    errdefer description.deinit(allocator);
+    description.warnings = try joinWarnings(allocator, first.warnings, second.warnings);
+
+    return Merged{ .description = description, .conflicts = try conflicts.toOwnedSlice() };
+}
+
+/// The JSON members one field is written as, without the shared keys
+fn membersOf(arena: std.mem.Allocator, desc: GeologicalDescription, field: Field) !ObjectMap {
+    // The raw description is written unescaped and is not needed here
+    var bare = desc;
+    bare.raw_description = "";
+    const json = try bare.toJsonWith(arena, &.{field});
+    const value = try std.json.parseFromSliceLeaky(std.json.Value, arena, json, .{ .parse_numbers = false });
+    if (value != .object) return error.InvalidJson;
+
+    var members = value.object;
+    for (shared_keys) |key| _ = members.orderedRemove(key);
+    return members;
+}
+
+fn membersJson(arena: std.mem.Allocator, desc: GeologicalDescription, field: Field) ![]const u8 {
+    const members = try membersOf(arena, desc, field);
+    return std.json.stringifyAlloc(arena, std.json.Value{ .object = members }, .{});
+}
+
+fn joinWarnings(allocator: std.mem.Allocator, first: []const []const u8, second: []const []const u8) ![][]const u8 {
+    const warnings = try allocator.alloc([]const u8, first.len + second.len);
+    var filled: usize = 0;
+    errdefer {
+        for (warnings[0..filled]) |warning| allocator.free(warning);
+        allocator.free(warnings);
+    }
+    for ([_][]const []const u8{ first, second }) |list| {
+        for (list) |warning| {
+            warnings[filled] = try allocator.dupe(u8, warning);
+            filled += 1;
+        }
+    }
+    return warnings;
+}
+
+test "merge complementary descriptions" {
+    const allocator = std.testing.allocator;
+    const driller = GeologicalDescription{ .raw_description = "brown CLAY", .material_type = .soil, .primary_soil_type = .clay, .color = .brown, .confidence = 0.6 };
+    const engineer = GeologicalDescription{ .raw_description = "Firm CLAY", .material_type = .soil, .consistency = .firm, .primary_soil_type = .clay, .confidence = 0.9 };
+
+    const merged = try merge(allocator, driller, engineer, .prefer_confident);
+    defer merged.deinit(allocator);
+
+    const desc = merged.description;
+    try std.testing.expectEqual(@as(usize, 0), merged.conflicts.len);
+    try std.testing.expect(desc.consistency.? == .firm);
+    try std.testing.expect(desc.color.? == .brown);
+    try std.testing.expect(desc.primary_soil_type.? == .clay);
+    try std.testing.expectEqualStrings("brown CLAY; Firm CLAY", desc.raw_description);
+    try std.testing.expectApproxEqAbs(@as(f32, 0.6), desc.confidence, 0.001);
+}
+
+test "report and resolve conflicts by policy" {
+    const allocator = std.testing.allocator;
+    const driller = GeologicalDescription{ .raw_description = "soft CLAY", .material_type = .soil, .consistency = .soft, .primary_soil_type = .clay, .confidence = 0.6 };
+    const engineer = GeologicalDescription{ .raw_description = "Stiff CLAY", .material_type = .soil, .consistency = .stiff, .primary_soil_type = .clay, .confidence = 0.9 };
+
+    const confident = try merge(allocator, driller, engineer, .prefer_confident);
+    defer confident.deinit(allocator);
+    try std.testing.expect(confident.description.consistency.? == .stiff);
+    try std.testing.expectEqual(@as(usize, 1), confident.conflicts.len);
+    try std.testing.expectEqual(Field.consistency, confident.conflicts[0].field);
+    try std.testing.expectEqual(Side.second, confident.conflicts[0].kept);
+    try std.testing.expectEqualStrings("{\"consistency\":\"soft\"}", confident.conflicts[0].first);
+    try std.testing.expectEqualStrings("{\"consistency\":\"stiff\"}", confident.conflicts[0].second);
+
+    const first = try merge(allocator, driller, engineer, .prefer_first);
+    defer first.deinit(allocator);
+    try std.testing.expect(first.description.consistency.? == .soft);
+}
@@ -0,0 +1,180 @@
+const std = @import("std");
+const types = @import("types.zig");
+
+const Field = types.Field;
+const GeologicalDescription = types.GeologicalDescription;
+const ObjectMap = std.json.ObjectMap;
+
+/// One schema change, filled in for results from releases that predate it
+const Step = struct {
+    /// Newest release whose results lack the change
+    through: std.SemanticVersion,
+    apply: *const fn (std.mem.Allocator, *ObjectMap) std.mem.Allocator.Error!void,
+};
+
+const release_0_7_0 = std.SemanticVersion{ .major = 0, .minor = 7, .patch = 0 };
+
+/// In release order. Steps only add what is missing, so running one over a
+/// result that already has the change leaves it alone.
+const steps = [_]Step{
+    .{ .through = release_0_7_0, .apply = addStrengthProvenance },
+    .{ .through = release_0_7_0, .apply = addSources },
+};
+
+/// Convert a result serialised by litholog `from_version` ("0.7.0" or
+/// "v0.7.0") to the current schema, so stored results can be read without
+/// parsing the descriptions again. Keys come out in `json_key_order`;
+/// keys the current schema does not know are kept at the end. Numbers are
+/// copied as written. Caller owns the result.
+pub fn upgrade(allocator: std.mem.Allocator, old_json: []const u8, from_version: []const u8) ![]u8 {
+    const version_text = if (std.mem.startsWith(u8, from_version, "v")) from_version[1..] else from_version;
+    const from = std.SemanticVersion.parse(version_text) catch return error.InvalidVersion;
+
+    const parsed = std.json.parseFromSlice(std.json.Value, allocator, old_json, .{ .parse_numbers = false }) catch return error.InvalidJson;
+    defer parsed.deinit();
+    if (parsed.value != .object) return error.InvalidJson;
+
+    var object = parsed.value.object;
+    for (steps) |step| {
+        if (from.order(step.through) == .gt) continue;
+        try step.apply(parsed.arena.allocator(), &object);
+    }
+
+    var out = std.ArrayList(u8).init(allocator);
+    errdefer out.deinit();
+    try writeOrdered(object, out.writer());
+    return out.toOwnedSlice();
+}
+
+fn writeOrdered(object: ObjectMap, writer: anytype) !void {
+    try writer.writeAll("{");
+    var first = true;
+    for (GeologicalDescription.json_key_order) |key| {
+        const value = object.get(key) orelse continue;
+        try writeMember(writer, key, value, &first);
+    }
+    var it = object.iterator();
+    while (it.next()) |entry| {
+        if (isKnownKey(entry.key_ptr.*)) continue;
+        try writeMember(writer, entry.key_ptr.*, entry.value_ptr.*, &first);
+    }
+    try writer.writeAll("}");
+}
+
+fn writeMember(writer: anytype, key: []const u8, value: std.json.Value, first: *bool) !void {
+    if (!first.*) try writer.writeAll(",");
+    first.* = false;
+    try std.json.stringify(key, .{}, writer);
+    try writer.writeAll(":");
+    try std.json.stringify(value, .{}, writer);
+}
+
+fn isKnownKey(key: []const u8) bool {
+    for (GeologicalDescription.json_key_order) |known| {
+        if (std.mem.eql(u8, key, known)) return true;
+    }
+    return false;
+}
+
+/// Strength ranges were always inferred before measurements could be merged
+fn addStrengthProvenance(_: std.mem.Allocator, object: *ObjectMap) std.mem.Allocator.Error!void {
+    if (object.get("strength_parameter_type") == null or object.contains("strength_provenance")) return;
+    try object.put("strength_provenance", .{ .string = "inferred" });
+}
+
+/// Tag each field present as the parser would have: lookups inferred, an
+/// unmarked soil defaulted, everything else parsed
+fn addSources(allocator: std.mem.Allocator, object: *ObjectMap) std.mem.Allocator.Error!void {
+    if (object.contains("sources")) return;
+
+    var sources = ObjectMap.init(allocator);
+    for (std.enums.values(Field)) |field| {
+        const value = object.get(jsonKey(field)) orelse continue;
+        if (!isPresent(value)) continue;
+        const source: types.Source = switch (field) {
+            .strength_parameters, .constituent_guidance => .inferred,
+            .material_type => if (isDefaultSoil(object.*)) .default else .parsed,
+            else => .parsed,
+        };
+        try sources.put(@tagName(field), .{ .string = source.toString() });
+    }
+    try object.put("sources", .{ .object = sources });
+}
+
+/// The key a field is written under
+fn jsonKey(field: Field) []const u8 {
+    return switch (field) {
+        .strength_parameters => "strength_parameter_type",
+        .constituent_guidance => "constituent_proportions",
+        else => @tagName(field),
+    };
+}
+
+fn isPresent(value: std.json.Value) bool {
+    return switch (value) {
+        .null => false,
+        .bool => |flag| flag,
+        .array => |array| array.items.len > 0,
+        else => true,
+    };
+}
+
+fn isDefaultSoil(object: ObjectMap) bool {
+    const material = object.get("material_type") orelse return false;
+    if (material != .string or !std.mem.eql(u8, material.string, "soil")) return false;
+    if (object.get("primary_soil_type")) |soil_type| {
+        if (isPresent(soil_type)) return false;
+    }
+    if (object.get("is_made_ground")) |made_ground| {
+        if (isPresent(made_ground)) return false;
+    }
+    return true;
+}
+
+test "upgrade a 0.7.0 result" {
+    const allocator = std.testing.allocator;
+    const old =
+        \\{"raw_description":"Firm CLAY","material_type":"soil","consistency":"firm","primary_soil_type":"clay","strength_parameter_type":"cu","strength_parameter_units":"kPa","strength_lower_bound":25.00,"strength_upper_bound":50.00,"strength_typical_value":37.00,"strength_confidence":0.80,"secondary_constituents":[],"warnings":[],"confidence":0.95,"is_valid":true,"project_code":"A12"}
+    ;
+    const upgraded = try upgrade(allocator, old, "v0.7.0");
+    defer allocator.free(upgraded);
+
+    try std.testing.expectEqualStrings(
+        \\{"raw_description":"Firm CLAY","material_type":"soil","consistency":"firm","primary_soil_type":"clay","strength_parameter_type":"cu","strength_parameter_units":"kPa","strength_lower_bound":25.00,"strength_upper_bound":50.00,"strength_typical_value":37.00,"strength_confidence":0.80,"strength_provenance":"inferred","secondary_constituents":[],"sources":{"material_type":"parsed","consistency":"parsed","primary_soil_type":"parsed","strength_parameters":"inferred"},"warnings":[],"confidence":0.95,"is_valid":true,"project_code":"A12"}
+    , upgraded);
+
+    const restored = try GeologicalDescription.fromJson(upgraded, allocator);
+    defer restored.deinit(allocator);
+    try std.testing.expect(restored.sources.get(.consistency).? == .parsed);
+}
+
+test "upgrading twice changes nothing" {
+    const allocator = std.testing.allocator;
+    const desc = GeologicalDescription{ .raw_description = "Dense SAND", .material_type = .soil, .density = .dense, .primary_soil_type = .sand };
+    const current = try desc.toJson(allocator);
+    defer allocator.free(current);
+
+    const upgraded = try upgrade(allocator, current, "0.7.0");
+    defer allocator.free(upgraded);
+    const again = try upgrade(allocator, upgraded, "0.7.0");
+    defer allocator.free(again);
+    try std.testing.expectEqualStrings(upgraded, again);
+}
+
+test "a current result passes through unchanged" {
+    const allocator = std.testing.allocator;
+    const desc = GeologicalDescription{ .raw_description = "Stiff CLAY", .material_type = .soil, .consistency = .stiff, .primary_soil_type = .clay };
+    const current = try desc.toJson(allocator);
+    defer allocator.free(current);
+
+    const upgraded = try upgrade(allocator, current, "0.8.0");
+    defer allocator.free(upgraded);
+    try std.testing.expectEqualStrings(current, upgraded);
+}
+
+test "reject bad input" {
+    const allocator = std.testing.allocator;
+    try std.testing.expectError(error.InvalidVersion, upgrade(allocator, "{}", "seven"));
+    try std.testing.expectError(error.InvalidJson, upgrade(allocator, "[1,2]", "0.7.0"));
+    try std.testing.expectError(error.InvalidJson, upgrade(allocator, "{", "0.7.0"));
+}
@@ -0,0 +1,87 @@
+const std = @import("std");
+const types = @import("types.zig");
+
+const MunsellColour = types.MunsellColour;
+
+/// A Munsell code in a description
+pub const Match = struct {
+    colour: MunsellColour,
+    start: usize,
+    end: usize,
+};
+
+/// The first Munsell code in the text: "10YR 5/3", "2.5Y 6/2", "7.5YR4/6"
+/// or the neutral "N 5/"
+pub fn find(text: []const u8) ?Match {
+    var pos: usize = 0;
+    while (pos < text.len) : (pos += 1) {
+        if (pos > 0 and (std.ascii.isAlphanumeric(text[pos - 1]) or text[pos - 1] == '.')) continue;
+        const read = MunsellColour.readAt(text, pos) orelse continue;
+        return Match{ .colour = read.colour, .start = pos, .end = read.end };
+    }
+    return null;
+}
+
+/// Overwrite the code with spaces so the words around it parse as usual. A
+/// code alone in brackets takes the brackets with it, so "(10YR 5/3)" is not
+/// read as a formation name.
+pub fn blank(text: []u8, match: Match) void {
+    var start = match.start;
+    var end = match.end;
+    var before = start;
+    while (before > 0 and text[before - 1] == ' ') before -= 1;
+    var after = end;
+    while (after < text.len and text[after] == ' ') after += 1;
+    if (before > 0 and after < text.len and text[before - 1] == '(' and text[after] == ')') {
+        start = before - 1;
+        end = after + 1;
+    }
+    @memset(text[start..end], ' ');
+}
+
+test "find Munsell codes" {
+    const match = find("Firm brown (10YR 5/3) slightly sandy CLAY").?;
+    try std.testing.expectEqual(types.MunsellHue.yr, match.colour.hue);
+    try std.testing.expectEqual(@as(f32, 10), match.colour.step);
+    try std.testing.expectEqual(@as(f32, 5), match.colour.value);
+    try std.testing.expectEqual(@as(f32, 3), match.colour.chroma);
+    try std.testing.expectEqual(@as(usize, 12), match.start);
+    try std.testing.expectEqual(@as(usize, 20), match.end);
+
+    const decimal = find("Soft grey 2.5Y6/2 SILT").?;
+    try std.testing.expectEqual(@as(f32, 2.5), decimal.colour.step);
+    try std.testing.expectEqual(types.MunsellHue.y, decimal.colour.hue);
+
+    const neutral = find("Stiff dark grey CLAY, N 4/.").?;
+    try std.testing.expectEqual(types.MunsellHue.n, neutral.colour.hue);
+    try std.testing.expect(neutral.colour.isValid());
+
+    var buf: [32]u8 = undefined;
+    try std.testing.expectEqualStrings("2.5Y 6/2", try std.fmt.bufPrint(&buf, "{}", .{decimal.colour}));
+    try std.testing.expectEqualStrings("N 4/", try std.fmt.bufPrint(&buf, "{}", .{neutral.colour}));
+}
+
+test "ignore text that is not a Munsell code" {
+    try std.testing.expect(find("Firm CLAY with 5/3 split") == null);
+    try std.testing.expect(find("Dense SAND at 10 m") == null);
+    try std.testing.expect(find("Firm CLAY, SPT N 25") == null);
+    try std.testing.expect(find("Stiff CLAY (10XR 5/3)") == null);
+}
+
+test "keep out-of-range codes but report them invalid" {
+    const value = find("Firm brown 10YR 12/3 CLAY").?;
+    try std.testing.expect(!value.colour.isValid());
+    try std.testing.expect(!find("Firm brown 12.5YR 5/3 CLAY").?.colour.isValid());
+    try std.testing.expect(!find("Firm grey N 5/2 CLAY").?.colour.isValid());
+    try std.testing.expect(find("Firm brown 10YR 5/3 CLAY").?.colour.isValid());
+}
+
+test "blank a bracketed code" {
+    var text = "Firm brown ( 10YR 5/3 ) CLAY".*;
+    blank(&text, find(&text).?);
+    try std.testing.expectEqualStrings("Firm brown              CLAY", &text);
+
+    var loose = "Firm brown 10YR 5/3, CLAY".*;
+    blank(&loose, find(&loose).?);
+    try std.testing.expectEqualStrings("Firm brown         , CLAY", &loose);
+}
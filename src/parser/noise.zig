@@ -0,0 +1,72 @@
+const std = @import("std");
+
+/// Drilling log jargon that carries no material information
+pub const drilling_jargon = [_][]const u8{
+    "approximately",
+    "approx.",
+    "approx",
+    "recovered as",
+    "driller's description",
+    "drilling fluid",
+    "drilling mud",
+    "water added",
+    "bentonite",
+    "polymer",
+    "flush",
+};
+
+/// Terms to ignore while parsing, matched case-insensitively on word
+/// boundaries. Ignored text is blanked rather than removed, so token offsets
+/// still line up with the input.
+pub const NoiseFilter = struct {
+    /// Project-specific terms, checked alongside the defaults
+    terms: []const []const u8 = &.{},
+    /// Whether `drilling_jargon` is ignored too
+    use_defaults: bool = true,
+
+    /// Overwrite every ignored term in `text` with spaces
+    pub fn blank(self: NoiseFilter, text: []u8) void {
+        var pos: usize = 0;
+        while (pos < text.len) {
+            if (pos > 0 and std.ascii.isAlphanumeric(text[pos - 1])) {
+                pos += 1;
+                continue;
+            }
+
+            const len = self.longestMatch(text[pos..]);
+            if (len == 0) {
+                pos += 1;
+                continue;
+            }
+            @memset(text[pos .. pos + len], ' ');
+            pos += len;
+        }
+    }
+
+    fn longestMatch(self: NoiseFilter, text: []const u8) usize {
+        var longest: usize = 0;
+        if (self.use_defaults) {
+            for (drilling_jargon) |term| longest = @max(longest, matchLength(text, term));
+        }
+        for (self.terms) |term| longest = @max(longest, matchLength(text, term));
+        return longest;
+    }
+};
+
+fn matchLength(text: []const u8, term: []const u8) usize {
+    if (term.len == 0 or !std.ascii.startsWithIgnoreCase(text, term)) return 0;
+    // "flush" must not match the start of "flushed"
+    if (text.len > term.len and std.ascii.isAlphanumeric(text[term.len]) and std.ascii.isAlphanumeric(term[term.len - 1])) return 0;
+    return term.len;
+}
+
+test "noise filter blanks jargon on word boundaries" {
+    var text = "Recovered as firm CLAY, approx. bentonite flushed".*;
+    const filter = NoiseFilter{ .terms = &.{"firm clay"} };
+    filter.blank(&text);
+    try std.testing.expectEqualStrings("                      ,                   flushed", &text);
+
+    var kept = "Firm CLAY".*;
+    (NoiseFilter{ .use_defaults = false }).blank(&kept);
+    try std.testing.expectEqualStrings("Firm CLAY", &kept);
+}
@@ -0,0 +1,247 @@
+const std = @import("std");
+const types = @import("types.zig");
+
+/// Kinds of OCR damage repaired before parsing
+pub const OcrFixKind = enum {
+    character_confusion, // "C1AY" -> "CLAY", "firrn" -> "firm"
+    missing_space, // "FirmCLAY" -> "Firm CLAY"
+
+    pub fn toString(self: OcrFixKind) []const u8 {
+        return switch (self) {
+            .character_confusion => "character_confusion",
+            .missing_space => "missing_space",
+        };
+    }
+};
+
+/// A single repair applied to the input text
+pub const OcrFix = struct {
+    original: []const u8,
+    corrected: []const u8,
+    kind: OcrFixKind,
+};
+
+pub const OcrNormalization = struct {
+    text: []u8,
+    fixes: []OcrFix,
+
+    pub fn deinit(self: *OcrNormalization, allocator: std.mem.Allocator) void {
+        for (self.fixes) |fix| {
+            allocator.free(fix.original);
+            allocator.free(fix.corrected);
+        }
+        allocator.free(self.fixes);
+        allocator.free(self.text);
+    }
+};
+
+// Vocabulary terms not covered by the enum parsers
+const extra_terms = [_][]const u8{ "slightly", "moderately", "very", "sandy", "silty", "clayey", "gravelly", "weathered", "fine", "medium", "coarse", "with", "and", "occasional", "frequent", "rare", "rootlets", "fragments" };
+
+/// Apply targeted OCR repairs. Every fix must produce recognised geological
+/// vocabulary, so ordinary words, depths and units are left alone.
+pub fn normalize(allocator: std.mem.Allocator, input: []const u8) !OcrNormalization {
+    var output = std.ArrayList(u8).init(allocator);
+    errdefer output.deinit();
+
+    var fixes = std.ArrayList(OcrFix).init(allocator);
+    errdefer {
+        for (fixes.items) |fix| {
+            allocator.free(fix.original);
+            allocator.free(fix.corrected);
+        }
+        fixes.deinit();
+    }
+
+    var words = std.mem.tokenizeAny(u8, input, " \t\r\n");
+    while (words.next()) |raw_word| {
+        if (output.items.len > 0) try output.append(' ');
+
+        // Keep trailing punctuation out of the vocabulary checks
+        const core = std.mem.trimRight(u8, raw_word, ",;:.");
+        const trailing = raw_word[core.len..];
+
+        var buf: [64]u8 = undefined;
+        if (core.len == 0 or core.len > buf.len) {
+            try output.appendSlice(raw_word);
+            continue;
+        }
+        @memcpy(buf[0..core.len], core);
+        var word: []u8 = buf[0..core.len];
+
+        if (!isKnownTerm(word)) {
+            if (fixConfusions(word, &buf)) |fixed| {
+                try recordFix(allocator, &fixes, core, fixed, .character_confusion);
+                word = fixed;
+            }
+        }
+
+        if (splitPoint(word)) |split| {
+            const spaced = try std.fmt.allocPrint(allocator, "{s} {s}", .{ word[0..split], word[split..] });
+            defer allocator.free(spaced);
+            try recordFix(allocator, &fixes, word, spaced, .missing_space);
+            try output.appendSlice(spaced);
+        } else {
+            try output.appendSlice(word);
+        }
+        try output.appendSlice(trailing);
+    }
+
+    return OcrNormalization{
+        .text = try output.toOwnedSlice(),
+        .fixes = try fixes.toOwnedSlice(),
+    };
+}
+
+fn recordFix(allocator: std.mem.Allocator, fixes: *std.ArrayList(OcrFix), original: []const u8, corrected: []const u8, kind: OcrFixKind) !void {
+    const original_owned = try allocator.dupe(u8, original);
+    errdefer allocator.free(original_owned);
+    const corrected_owned = try allocator.dupe(u8, corrected);
+    errdefer allocator.free(corrected_owned);
+    try fixes.append(OcrFix{ .original = original_owned, .corrected = corrected_owned, .kind = kind });
+}
+
+/// Try digit-for-letter swaps (0/O, 1/l, 5/S) and "rn" for "m".
+/// Returns the repaired word in `buf` when it becomes recognisable.
+fn fixConfusions(word: []const u8, buf: *[64]u8) ?[]u8 {
+    var scratch: [64]u8 = undefined;
+    @memcpy(scratch[0..word.len], word);
+    const candidate: []u8 = scratch[0..word.len];
+
+    var letters: usize = 0;
+    var changed = false;
+    for (candidate, 0..) |ch, i| {
+        if (std.ascii.isAlphabetic(ch)) {
+            letters += 1;
+            continue;
+        }
+        const upper = neighbourIsUpper(word, i);
+        candidate[i] = switch (ch) {
+            '0' => if (upper) 'O' else 'o',
+            '1' => if (upper) 'I' else 'l',
+            '5' => if (upper) 'S' else 's',
+            else => return null, // Any other character means this is not a damaged word
+        };
+        changed = true;
+    }
+    if (letters < 2) return null;
+
+    if (changed and recognisable(candidate)) {
+        @memcpy(buf[0..candidate.len], candidate);
+        return buf[0..candidate.len];
+    }
+
+    // "1" reads as "I" in upper-case words but as "l" in "CLAY"; retry with "L"
+    if (changed) {
+        for (candidate, 0..) |*ch, i| {
+            if (word[i] == '1' and ch.* == 'I') ch.* = 'L';
+        }
+        if (recognisable(candidate)) {
+            @memcpy(buf[0..candidate.len], candidate);
+            return buf[0..candidate.len];
+        }
+    }
+
+    // "rn" is a common misread of "m"
+    if (std.ascii.indexOfIgnoreCase(candidate, "rn")) |idx| {
+        var out_len: usize = 0;
+        for (candidate[0..idx]) |ch| {
+            buf[out_len] = ch;
+            out_len += 1;
+        }
+        buf[out_len] = if (std.ascii.isUpper(candidate[idx])) 'M' else 'm';
+        out_len += 1;
+        for (candidate[idx + 2 ..]) |ch| {
+            buf[out_len] = ch;
+            out_len += 1;
+        }
+        if (recognisable(buf[0..out_len])) return buf[0..out_len];
+    }
+
+    return null;
+}
+
+fn neighbourIsUpper(word: []const u8, index: usize) bool {
+    var i = index;
+    while (i > 0) {
+        i -= 1;
+        if (std.ascii.isAlphabetic(word[i])) return std.ascii.isUpper(word[i]);
+    }
+    for (word[index + 1 ..]) |ch| {
+        if (std.ascii.isAlphabetic(ch)) return std.ascii.isUpper(ch);
+    }
+    return false;
+}
+
+fn recognisable(word: []const u8) bool {
+    return isKnownTerm(word) or splitPoint(word) != null;
+}
+
+/// Where a run-together word should be split, if it is two known terms
+fn splitPoint(word: []const u8) ?usize {
+    if (isKnownTerm(word)) return null;
+
+    // Case boundary: "FirmCLAY", "sandyGRAVEL"
+    var i: usize = 1;
+    while (i + 1 < word.len) : (i += 1) {
+        if (std.ascii.isLower(word[i - 1]) and std.ascii.isUpper(word[i]) and std.ascii.isUpper(word[i + 1])) {
+            if (isKnownTerm(word[0..i]) and isKnownTerm(word[i..])) return i;
+        }
+    }
+
+    // No case hint: "firmclay", "FIRMCLAY"
+    i = 3;
+    while (i + 3 <= word.len) : (i += 1) {
+        if (isKnownTerm(word[0..i]) and isKnownTerm(word[i..])) return i;
+    }
+    return null;
+}
+
+fn isKnownTerm(word: []const u8) bool {
+    if (word.len < 3) return false;
+    if (types.SoilType.fromString(word) != null) return true;
+    if (types.RockType.fromString(word) != null) return true;
+    if (types.Consistency.fromString(word) != null) return true;
+    if (types.Density.fromString(word) != null) return true;
+    if (types.RockStrength.fromString(word) != null) return true;
+    if (types.WeatheringGrade.fromString(word) != null) return true;
+    if (types.RockStructure.fromString(word) != null) return true;
+    if (types.Color.fromString(word) != null) return true;
+    if (types.MoistureContent.fromString(word) != null) return true;
+    for (extra_terms) |term| {
+        if (std.ascii.eqlIgnoreCase(word, term)) return true;
+    }
+    return false;
+}
+
+test "ocr normalise character confusions" {
+    const allocator = std.testing.allocator;
+
+    var result = try normalize(allocator, "Firrn brown C1AY");
+    defer result.deinit(allocator);
+
+    try std.testing.expectEqualStrings("Firm brown CLAY", result.text);
+    try std.testing.expectEqual(@as(usize, 2), result.fixes.len);
+    try std.testing.expectEqual(OcrFixKind.character_confusion, result.fixes[0].kind);
+}
+
+test "ocr normalise missing spaces" {
+    const allocator = std.testing.allocator;
+
+    var result = try normalize(allocator, "FirmCLAY, stiffclay");
+    defer result.deinit(allocator);
+
+    try std.testing.expectEqualStrings("Firm CLAY, stiff clay", result.text);
+    try std.testing.expectEqual(@as(usize, 2), result.fixes.len);
+    try std.testing.expectEqual(OcrFixKind.missing_space, result.fixes[0].kind);
+}
+
+test "ocr normalise leaves depths and units alone" {
+    const allocator = std.testing.allocator;
+
+    var result = try normalize(allocator, "Dense SAND with 20mm gravel at 1.50m");
+    defer result.deinit(allocator);
+
+    try std.testing.expectEqualStrings("Dense SAND with 20mm gravel at 1.50m", result.text);
+    try std.testing.expectEqual(@as(usize, 0), result.fixes.len);
+}
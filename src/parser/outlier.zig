@@ -0,0 +1,317 @@
+const std = @import("std");
+const types = @import("types.zig");
+
+const GeologicalDescription = types.GeologicalDescription;
+const SoilDescription = types.SoilDescription;
+const Consistency = types.Consistency;
+const Density = types.Density;
+const RockStrength = types.RockStrength;
+
+/// Kinds of semantic outlier detected within a unit
+pub const OutlierKind = enum {
+    strength, // e.g. one "soft" record in a "stiff to very stiff" unit
+    primary_type, // e.g. one SAND record in a CLAY unit
+    material_type, // e.g. one rock record in a soil unit
+
+    pub fn toString(self: OutlierKind) []const u8 {
+        return switch (self) {
+            .strength => "strength",
+            .primary_type => "primary_type",
+            .material_type => "material_type",
+        };
+    }
+};
+
+/// A description flagged as inconsistent with the rest of its unit
+pub const Outlier = struct {
+    index: usize, // Index into the descriptions passed to detectOutliers
+    kind: OutlierKind,
+    score: f64, // Robust z-score for strength, agreement fraction of the majority for categorical kinds
+    message: []const u8,
+
+    pub fn deinit(self: *Outlier, allocator: std.mem.Allocator) void {
+        allocator.free(self.message);
+    }
+};
+
+pub const OutlierResult = struct {
+    outliers: []Outlier,
+
+    pub fn deinit(self: *OutlierResult, allocator: std.mem.Allocator) void {
+        for (self.outliers) |*outlier| {
+            outlier.deinit(allocator);
+        }
+        allocator.free(self.outliers);
+    }
+};
+
+pub const OutlierOptions = struct {
+    /// Absolute robust z-score above which a strength value is flagged
+    z_threshold: f64 = 3.5,
+    /// Fraction of the unit that must agree on a category before dissenters are flagged
+    majority_fraction: f64 = 0.66,
+    /// Units smaller than this are too small to judge
+    min_unit_size: usize = 3,
+};
+
+/// Ordinal strength rank for a description on a common scale.
+/// Ranges sit halfway between their end members.
+pub fn strengthRank(desc: *const GeologicalDescription) ?f64 {
+    if (desc.consistency) |c| return consistencyRank(c);
+    if (desc.density) |d| return densityRank(d);
+    if (desc.rock_strength) |rs| return @floatFromInt(@intFromEnum(rs));
+    return null;
+}
+
+/// Consistency on a 0 (very soft) to 5 (hard) scale
+pub fn consistencyRank(consistency: Consistency) f64 {
+    return switch (consistency) {
+        .very_soft => 0,
+        .soft => 1,
+        .soft_to_firm => 1.5,
+        .firm => 2,
+        .firm_to_stiff => 2.5,
+        .stiff => 3,
+        .stiff_to_very_stiff => 3.5,
+        .very_stiff => 4,
+        .hard => 5,
+    };
+}
+
+/// Density on a 0 (very loose) to 4 (very dense) scale
+pub fn densityRank(density: Density) f64 {
+    return switch (density) {
+        .very_loose => 0,
+        .loose => 1,
+        .loose_to_medium_dense => 1.5,
+        .medium_dense => 2,
+        .medium_dense_to_dense => 2.5,
+        .dense => 3,
+        .very_dense => 4,
+    };
+}
+
+/// Flag semantic outliers among descriptions grouped into a single unit
+pub fn detectOutliers(allocator: std.mem.Allocator, descriptions: []const GeologicalDescription) !OutlierResult {
+    return detectOutliersWithOptions(allocator, descriptions, .{});
+}
+
+pub fn detectOutliersWithOptions(
+    allocator: std.mem.Allocator,
+    descriptions: []const GeologicalDescription,
+    options: OutlierOptions,
+) !OutlierResult {
+    var outliers = std.ArrayList(Outlier).init(allocator);
+    errdefer {
+        for (outliers.items) |*outlier| {
+            outlier.deinit(allocator);
+        }
+        outliers.deinit();
+    }
+
+    if (descriptions.len < options.min_unit_size) {
+        return OutlierResult{ .outliers = try outliers.toOwnedSlice() };
+    }
+
+    try checkMaterialType(allocator, descriptions, options, &outliers);
+    try checkPrimaryType(allocator, descriptions, options, &outliers);
+    try checkStrength(allocator, descriptions, options, &outliers);
+
+    return OutlierResult{ .outliers = try outliers.toOwnedSlice() };
+}
+
+fn checkMaterialType(
+    allocator: std.mem.Allocator,
+    descriptions: []const GeologicalDescription,
+    options: OutlierOptions,
+    outliers: *std.ArrayList(Outlier),
+) !void {
+    var soil_count: usize = 0;
+    for (descriptions) |desc| {
+        if (desc.material_type == .soil) soil_count += 1;
+    }
+
+    const total: f64 = @floatFromInt(descriptions.len);
+    const majority: types.MaterialType = if (soil_count * 2 >= descriptions.len) .soil else .rock;
+    const majority_count = if (majority == .soil) soil_count else descriptions.len - soil_count;
+    const fraction = @as(f64, @floatFromInt(majority_count)) / total;
+    if (fraction < options.majority_fraction) return;
+
+    for (descriptions, 0..) |desc, i| {
+        if (desc.material_type != majority) {
+            try outliers.append(Outlier{
+                .index = i,
+                .kind = .material_type,
+                .score = fraction,
+                .message = try std.fmt.allocPrint(allocator, "Record is {s} but {d:.0}% of the unit is {s}", .{
+                    desc.material_type.toString(),
+                    fraction * 100.0,
+                    majority.toString(),
+                }),
+            });
+        }
+    }
+}
+
+fn checkPrimaryType(
+    allocator: std.mem.Allocator,
+    descriptions: []const GeologicalDescription,
+    options: OutlierOptions,
+    outliers: *std.ArrayList(Outlier),
+) !void {
+    var counts = std.EnumArray(types.SoilType, usize).initFill(0);
+    var typed: usize = 0;
+    for (descriptions) |desc| {
+        if (desc.primary_soil_type) |pst| {
+            counts.getPtr(pst).* += 1;
+            typed += 1;
+        }
+    }
+    if (typed < options.min_unit_size) return;
+
+    var majority: types.SoilType = .clay;
+    var majority_count: usize = 0;
+    for (std.enums.values(types.SoilType)) |soil_type| {
+        if (counts.get(soil_type) > majority_count) {
+            majority = soil_type;
+            majority_count = counts.get(soil_type);
+        }
+    }
+
+    const fraction = @as(f64, @floatFromInt(majority_count)) / @as(f64, @floatFromInt(typed));
+    if (fraction < options.majority_fraction) return;
+
+    for (descriptions, 0..) |desc, i| {
+        const pst = desc.primary_soil_type orelse continue;
+        if (pst != majority) {
+            try outliers.append(Outlier{
+                .index = i,
+                .kind = .primary_type,
+                .score = fraction,
+                .message = try std.fmt.allocPrint(allocator, "Primary type {s} differs from unit majority {s} ({d:.0}%)", .{
+                    pst.toString(),
+                    majority.toString(),
+                    fraction * 100.0,
+                }),
+            });
+        }
+    }
+}
+
+fn checkStrength(
+    allocator: std.mem.Allocator,
+    descriptions: []const GeologicalDescription,
+    options: OutlierOptions,
+    outliers: *std.ArrayList(Outlier),
+) !void {
+    var ranks = std.ArrayList(f64).init(allocator);
+    defer ranks.deinit();
+    for (descriptions) |*desc| {
+        if (strengthRank(desc)) |rank| try ranks.append(rank);
+    }
+    if (ranks.items.len < options.min_unit_size) return;
+
+    const center = try median(allocator, ranks.items);
+    const scale = try robustScale(allocator, ranks.items, center);
+    if (scale == 0) return; // All records agree
+
+    for (descriptions, 0..) |*desc, i| {
+        const rank = strengthRank(desc) orelse continue;
+        const z = (rank - center) / scale;
+        if (@abs(z) > options.z_threshold) {
+            try outliers.append(Outlier{
+                .index = i,
+                .kind = .strength,
+                .score = z,
+                .message = try std.fmt.allocPrint(allocator, "Strength descriptor is {s} than the rest of the unit (z = {d:.1})", .{
+                    if (z < 0) "weaker" else "stronger",
+                    z,
+                }),
+            });
+        }
+    }
+}
+
+/// Median of values (input is not modified)
+fn median(allocator: std.mem.Allocator, values: []const f64) !f64 {
+    const sorted = try allocator.dupe(f64, values);
+    defer allocator.free(sorted);
+    std.sort.pdq(f64, sorted, {}, std.sort.asc(f64));
+
+    const mid = sorted.len / 2;
+    if (sorted.len % 2 == 1) return sorted[mid];
+    return (sorted[mid - 1] + sorted[mid]) / 2.0;
+}
+
+/// Scale estimate for the modified z-score: MAD / 0.6745, falling back to the
+/// mean absolute deviation when more than half the values are identical.
+fn robustScale(allocator: std.mem.Allocator, values: []const f64, center: f64) !f64 {
+    const deviations = try allocator.alloc(f64, values.len);
+    defer allocator.free(deviations);
+
+    var total: f64 = 0;
+    for (values, 0..) |v, i| {
+        deviations[i] = @abs(v - center);
+        total += deviations[i];
+    }
+
+    const mad = try median(allocator, deviations);
+    if (mad > 0) return mad / 0.6745;
+
+    const mean_ad = total / @as(f64, @floatFromInt(values.len));
+    return mean_ad * 1.2533;
+}
+
+test "strength outlier in stiff unit" {
+    const allocator = std.testing.allocator;
+
+    const unit = [_]SoilDescription{
+        .{ .raw_description = "Stiff to very stiff CLAY", .material_type = .soil, .primary_soil_type = .clay, .consistency = .stiff_to_very_stiff },
+        .{ .raw_description = "Stiff to very stiff CLAY", .material_type = .soil, .primary_soil_type = .clay, .consistency = .stiff_to_very_stiff },
+        .{ .raw_description = "Very stiff CLAY", .material_type = .soil, .primary_soil_type = .clay, .consistency = .very_stiff },
+        .{ .raw_description = "Stiff CLAY", .material_type = .soil, .primary_soil_type = .clay, .consistency = .stiff },
+        .{ .raw_description = "Stiff to very stiff CLAY", .material_type = .soil, .primary_soil_type = .clay, .consistency = .stiff_to_very_stiff },
+        .{ .raw_description = "Soft CLAY", .material_type = .soil, .primary_soil_type = .clay, .consistency = .soft },
+    };
+
+    var result = try detectOutliers(allocator, &unit);
+    defer result.deinit(allocator);
+
+    try std.testing.expectEqual(@as(usize, 1), result.outliers.len);
+    try std.testing.expectEqual(@as(usize, 5), result.outliers[0].index);
+    try std.testing.expectEqual(OutlierKind.strength, result.outliers[0].kind);
+    try std.testing.expect(result.outliers[0].score < 0);
+}
+
+test "primary type outlier" {
+    const allocator = std.testing.allocator;
+
+    const unit = [_]SoilDescription{
+        .{ .raw_description = "Firm CLAY", .material_type = .soil, .primary_soil_type = .clay, .consistency = .firm },
+        .{ .raw_description = "Firm CLAY", .material_type = .soil, .primary_soil_type = .clay, .consistency = .firm },
+        .{ .raw_description = "Firm CLAY", .material_type = .soil, .primary_soil_type = .clay, .consistency = .firm },
+        .{ .raw_description = "Firm SILT", .material_type = .soil, .primary_soil_type = .silt, .consistency = .firm },
+    };
+
+    var result = try detectOutliers(allocator, &unit);
+    defer result.deinit(allocator);
+
+    try std.testing.expectEqual(@as(usize, 1), result.outliers.len);
+    try std.testing.expectEqual(OutlierKind.primary_type, result.outliers[0].kind);
+    try std.testing.expectEqual(@as(usize, 3), result.outliers[0].index);
+}
+
+test "consistent unit has no outliers" {
+    const allocator = std.testing.allocator;
+
+    const unit = [_]SoilDescription{
+        .{ .raw_description = "Firm CLAY", .material_type = .soil, .primary_soil_type = .clay, .consistency = .firm },
+        .{ .raw_description = "Firm to stiff CLAY", .material_type = .soil, .primary_soil_type = .clay, .consistency = .firm_to_stiff },
+        .{ .raw_description = "Stiff CLAY", .material_type = .soil, .primary_soil_type = .clay, .consistency = .stiff },
+    };
+
+    var result = try detectOutliers(allocator, &unit);
+    defer result.deinit(allocator);
+
+    try std.testing.expectEqual(@as(usize, 0), result.outliers.len);
+}
@@ -0,0 +1,135 @@
+const std = @import("std");
+const types = @import("types.zig");
+const inclusions = @import("inclusions.zig");
+
+const AlternativeType = types.AlternativeType;
+const Condition = types.Condition;
+
+/// What a bracketed group says about the stratum
+pub const Reading = struct {
+    conditions: std.EnumSet(Condition) = .{},
+    /// Hedged with "possibly", "probably" or a question mark
+    tentative: bool = false,
+    alternative: ?AlternativeType = null,
+
+    /// Neither a condition note nor an alternative name, so the group names
+    /// the deposit or formation ("GLACIAL TILL", "LONDON CLAY FORMATION")
+    pub fn isDeposit(self: Reading) bool {
+        return self.conditions.count() == 0 and self.alternative == null;
+    }
+};
+
+const condition_phrases = [_]struct { phrase: []const u8, condition: Condition }{
+    .{ .phrase = "recovered as", .condition = .non_intact },
+    .{ .phrase = "non-intact", .condition = .non_intact },
+    .{ .phrase = "non intact", .condition = .non_intact },
+    .{ .phrase = "disturbed", .condition = .disturbed },
+    .{ .phrase = "remoulded", .condition = .remoulded },
+    .{ .phrase = "remolded", .condition = .remoulded },
+    .{ .phrase = "reworked", .condition = .reworked },
+};
+
+const hedges = [_][]const u8{ "possibly", "probably", "possible", "probable", "poss", "prob" };
+
+/// Words that may come before an alternative name ("or SILT", "recovered as
+/// CLAY", "possibly SAND")
+const lead_words = [_][]const u8{ "or", "possibly", "probably", "poss", "prob", "recovered", "as", "i.e.", "ie" };
+
+/// Reads the text inside a pair of brackets. "(recovered as non-intact)" and
+/// "(possibly reworked)" are condition notes; "(or SILT)" and "(recovered as
+/// CLAY)" name an alternative soil or rock type. "Recovered as" is always a
+/// non-intact note, so "(recovered as CLAY)" is both.
+pub fn read(text: []const u8) Reading {
+    var reading = Reading{};
+
+    var pos: usize = 0;
+    while (pos < text.len) : (pos += 1) {
+        if (pos > 0 and std.ascii.isAlphanumeric(text[pos - 1])) continue;
+        for (condition_phrases) |entry| {
+            if (!phraseAt(text, pos, entry.phrase)) continue;
+            // "recovered as intact core"
+            if (entry.condition == .non_intact and phraseAt(text, pos, "recovered as intact")) continue;
+            reading.conditions.insert(entry.condition);
+        }
+        for (hedges) |hedge| {
+            if (phraseAt(text, pos, hedge)) reading.tentative = true;
+        }
+    }
+    if (std.mem.indexOfScalar(u8, text, '?') != null) reading.tentative = true;
+
+    reading.alternative = alternativeName(text);
+    return reading;
+}
+
+/// All letters are capitals, as deposit names are written
+pub fn isUpperCase(text: []const u8) bool {
+    var letters = false;
+    for (text) |c| {
+        if (std.ascii.isLower(c)) return false;
+        if (std.ascii.isUpper(c)) letters = true;
+    }
+    return letters;
+}
+
+/// A soil or rock name, optionally after lead words and modifiers
+/// ("or clayey SILT"); anything else in the group rules it out
+fn alternativeName(text: []const u8) ?AlternativeType {
+    var words: [8][]const u8 = undefined;
+    var len: usize = 0;
+    var iter = std.mem.tokenizeAny(u8, text, " \t,?");
+    while (iter.next()) |word| {
+        if (len == words.len) return null;
+        words[len] = word;
+        len += 1;
+    }
+
+    var first: usize = 0;
+    while (first < len and isLeadWord(words[first])) first += 1;
+    if (first == len) return null;
+
+    for (words[first .. len - 1]) |word| {
+        if (!inclusions.isModifier(word)) return null;
+    }
+    return AlternativeType.fromString(words[len - 1]);
+}
+
+fn isLeadWord(word: []const u8) bool {
+    for (lead_words) |lead| {
+        if (std.ascii.eqlIgnoreCase(word, lead)) return true;
+    }
+    return false;
+}
+
+fn phraseAt(text: []const u8, pos: usize, phrase: []const u8) bool {
+    if (!std.ascii.startsWithIgnoreCase(text[pos..], phrase)) return false;
+    const end = pos + phrase.len;
+    return end == text.len or !std.ascii.isAlphanumeric(text[end]);
+}
+
+test "read condition notes" {
+    const non_intact = read("recovered as non-intact");
+    try std.testing.expect(non_intact.conditions.contains(.non_intact));
+    try std.testing.expect(!non_intact.tentative);
+    try std.testing.expect(non_intact.alternative == null);
+
+    const reworked = read("possibly reworked");
+    try std.testing.expect(reworked.conditions.contains(.reworked));
+    try std.testing.expect(reworked.tentative);
+
+    try std.testing.expect(read("undisturbed").isDeposit());
+    try std.testing.expect(read("recovered as intact core").isDeposit());
+}
+
+test "read alternative names and deposits" {
+    try std.testing.expectEqual(types.SoilType.silt, read("or clayey SILT").alternative.?.soil);
+    try std.testing.expectEqual(types.RockType.mudstone, read("possibly MUDSTONE").alternative.?.rock);
+
+    const recovered = read("recovered as CLAY");
+    try std.testing.expect(recovered.conditions.contains(.non_intact));
+    try std.testing.expectEqual(types.SoilType.clay, recovered.alternative.?.soil);
+
+    try std.testing.expect(read("LONDON CLAY FORMATION").isDeposit());
+    try std.testing.expect(read("GLACIAL TILL").isDeposit());
+    try std.testing.expect(isUpperCase("GLACIAL TILL"));
+    try std.testing.expect(!isUpperCase("or SILT"));
+}
@@ -0,0 +1,165 @@
+const std = @import("std");
+const types = @import("types.zig");
+const scan = @import("scan.zig");
+
+const Angularity = types.Angularity;
+const ParticleForm = types.ParticleForm;
+const ParticleShape = types.ParticleShape;
+const Word = scan.Word;
+
+/// The particle shape phrase of a description
+pub const Match = struct {
+    shape: ParticleShape,
+    start: usize,
+    end: usize,
+};
+
+const max_words = 96;
+
+/// The first angularity phrase, with a range ("subangular to rounded",
+/// "angular-subangular") and a form word just before or after it
+/// ("subrounded flat", "tabular subangular")
+pub fn find(text: []const u8) ?Match {
+    var words: [max_words]Word = undefined;
+    var len: usize = 0;
+    var pos: usize = 0;
+    while (len < words.len) {
+        const word = scan.next(text, pos, .letters) orelse break;
+        words[len] = word;
+        len += 1;
+        pos = word.end;
+    }
+
+    for (0..len) |i| {
+        if (readShape(words[0..len], i)) |match| return match;
+    }
+    return null;
+}
+
+fn readShape(words: []const Word, first: usize) ?Match {
+    const from = readAngularity(words, first) orelse return null;
+    var shape = ParticleShape{ .angularity = from.angularity };
+    var last = from.end - 1;
+
+    // "subangular to rounded", "subangular-rounded"
+    if (!words[last].endsPhrase()) {
+        const next = if (words[last].joiner != null) last + 1 else if (last + 2 < words.len and std.ascii.eqlIgnoreCase(words[last + 1].text, "to")) last + 2 else null;
+        if (next) |index| {
+            if (readAngularity(words, index)) |to| {
+                shape.angularity_to = to.angularity;
+                last = to.end - 1;
+            }
+        }
+    }
+    // Write the range most angular first
+    if (shape.angularity_to) |to| {
+        if (@intFromEnum(to) < @intFromEnum(from.angularity)) {
+            shape.angularity = to;
+            shape.angularity_to = from.angularity;
+        } else if (to == from.angularity) {
+            shape.angularity_to = null;
+        }
+    }
+
+    var start = words[first].start;
+    if (!words[last].endsPhrase()) {
+        if (readForm(words, last + 1)) |form| {
+            shape.form = form.form;
+            last = form.end - 1;
+        }
+    }
+    if (shape.form == null and first > 0 and !words[first - 1].endsPhrase()) {
+        if (formBefore(words, first)) |form| {
+            shape.form = form.form;
+            start = words[form.first].start;
+        }
+    }
+    return Match{ .shape = shape, .start = start, .end = words[last].end };
+}
+
+const ReadAngularity = struct {
+    angularity: Angularity,
+    /// Index of the word after the term
+    end: usize,
+};
+
+fn readAngularity(words: []const Word, first: usize) ?ReadAngularity {
+    if (first >= words.len) return null;
+    // "sub-angular", "sub angular", "well-rounded", "well rounded"
+    if (first + 1 < words.len and !words[first].endsPhrase()) {
+        const lead = words[first].text;
+        if (std.ascii.eqlIgnoreCase(lead, "sub") or std.ascii.eqlIgnoreCase(lead, "well")) {
+            var buf: [32]u8 = undefined;
+            const joined = std.fmt.bufPrint(&buf, "{s} {s}", .{ lead, words[first + 1].text }) catch return null;
+            if (Angularity.fromString(joined)) |angularity| return ReadAngularity{ .angularity = angularity, .end = first + 2 };
+        }
+    }
+    const angularity = Angularity.fromString(words[first].text) orelse return null;
+    return ReadAngularity{ .angularity = angularity, .end = first + 1 };
+}
+
+const ReadForm = struct {
+    form: ParticleForm,
+    /// Index of the first word of the term
+    first: usize,
+    /// Index of the word after the term
+    end: usize,
+};
+
+fn readForm(words: []const Word, first: usize) ?ReadForm {
+    if (first >= words.len) return null;
+    const form = ParticleForm.fromString(words[first].text) orelse return null;
+    // "flat and elongate"
+    if (form == .flat and !words[first].endsPhrase() and first + 2 < words.len and std.ascii.eqlIgnoreCase(words[first + 1].text, "and")) {
+        if (ParticleForm.fromString(words[first + 2].text)) |second| {
+            if (second == .elongate) return ReadForm{ .form = .flat_and_elongate, .first = first, .end = first + 3 };
+        }
+    }
+    return ReadForm{ .form = form, .first = first, .end = first + 1 };
+}
+
+/// A form term ending just before `index`
+fn formBefore(words: []const Word, index: usize) ?ReadForm {
+    if (index >= 3) {
+        if (readForm(words, index - 3)) |form| {
+            if (form.end == index) return form;
+        }
+    }
+    const form = readForm(words, index - 1) orelse return null;
+    return if (form.end == index) form else null;
+}
+
+test "find angularity ranges" {
+    const match = find("Dense subangular to rounded fine to coarse GRAVEL").?;
+    try std.testing.expectEqual(Angularity.subangular, match.shape.angularity.?);
+    try std.testing.expectEqual(Angularity.rounded, match.shape.angularity_to.?);
+    try std.testing.expect(match.shape.form == null);
+    try std.testing.expectEqual(@as(usize, 6), match.start);
+    try std.testing.expectEqual(@as(usize, 27), match.end);
+
+    const hyphenated = find("Medium dense sub-angular to well-rounded GRAVEL").?;
+    try std.testing.expectEqual(Angularity.subangular, hyphenated.shape.angularity.?);
+    try std.testing.expectEqual(Angularity.well_rounded, hyphenated.shape.angularity_to.?);
+
+    const reversed = find("Loose rounded/angular GRAVEL").?;
+    try std.testing.expectEqual(Angularity.angular, reversed.shape.angularity.?);
+    try std.testing.expectEqual(Angularity.rounded, reversed.shape.angularity_to.?);
+}
+
+test "find particle form" {
+    const after = find("Firm sandy CLAY. Gravel is subrounded flat and elongate flint.").?;
+    try std.testing.expectEqual(Angularity.subrounded, after.shape.angularity.?);
+    try std.testing.expectEqual(ParticleForm.flat_and_elongate, after.shape.form.?);
+
+    const before = find("Dense tabular angular GRAVEL of mudstone").?;
+    try std.testing.expectEqual(ParticleForm.flat, before.shape.form.?);
+    try std.testing.expectEqual(@as(usize, 6), before.start);
+
+    var buf: [64]u8 = undefined;
+    try std.testing.expectEqualStrings("subrounded flat and elongate", try std.fmt.bufPrint(&buf, "{}", .{after.shape}));
+}
+
+test "no shape without an angularity term" {
+    try std.testing.expect(find("Dense flat GRAVEL") == null);
+    try std.testing.expect(find("Firm CLAY") == null);
+}
@@ -0,0 +1,140 @@
+const std = @import("std");
+const types = @import("types.zig");
+const scan = @import("scan.zig");
+
+const GeologicalDescription = types.GeologicalDescription;
+const PeatFabric = types.PeatFabric;
+const PeatProperties = types.PeatProperties;
+const VonPost = types.VonPost;
+
+/// Peat qualifiers in a description
+pub const Match = struct {
+    properties: PeatProperties,
+    start: usize,
+    end: usize,
+    /// Where the von Post grade is, for `blank`
+    grade_start: usize = 0,
+    grade_end: usize = 0,
+};
+
+/// The first fabric term ("fibrous", "pseudo-fibrous", "amorphous") and
+/// von Post grade ("H4"), spanning from the first to the last
+pub fn find(text: []const u8) ?Match {
+    var properties = PeatProperties{};
+    var match = Match{ .properties = undefined, .start = 0, .end = 0 };
+    var found = false;
+
+    var pos: usize = 0;
+    while (scan.next(text, pos, .alphanumeric)) |word| {
+        pos = word.end;
+        var end = word.end;
+        if (properties.fabric == null) {
+            // "pseudo fibrous", "pseudo-fibrous"
+            if (std.ascii.eqlIgnoreCase(word.text, "pseudo")) {
+                if (scan.next(text, word.end, .alphanumeric)) |next| {
+                    if (std.ascii.eqlIgnoreCase(next.text, "fibrous")) {
+                        properties.fabric = .pseudo_fibrous;
+                        end = next.end;
+                        pos = next.end;
+                    }
+                }
+            } else if (PeatFabric.fromString(word.text)) |fabric| {
+                properties.fabric = fabric;
+            }
+            if (properties.fabric != null) {
+                if (!found) match.start = word.start;
+                match.end = end;
+                found = true;
+                continue;
+            }
+        }
+        if (properties.humification == null) {
+            if (VonPost.fromString(word.text)) |humification| {
+                properties.humification = humification;
+                match.grade_start = word.start;
+                match.grade_end = word.end;
+                if (!found) match.start = word.start;
+                match.end = word.end;
+                found = true;
+            }
+        }
+    }
+
+    if (!found) return null;
+    match.properties = properties;
+    return match;
+}
+
+/// Overwrite the von Post grade with spaces so the words around it parse as
+/// usual. A grade alone in brackets takes the brackets with it, so "(H4)" is
+/// not read as a formation name.
+pub fn blank(text: []u8, match: Match) void {
+    if (match.grade_end == 0) return;
+    var start = match.grade_start;
+    var end = match.grade_end;
+    // "von Post H7"
+    const lead = "von post ";
+    if (start >= lead.len and std.ascii.eqlIgnoreCase(text[start - lead.len .. start], lead)) start -= lead.len;
+    var before = start;
+    while (before > 0 and text[before - 1] == ' ') before -= 1;
+    var after = end;
+    while (after < text.len and text[after] == ' ') after += 1;
+    if (before > 0 and after < text.len and text[before - 1] == '(' and text[after] == ')') {
+        start = before - 1;
+        end = after + 1;
+    }
+    @memset(text[start..end], ' ');
+}
+
+/// The description is of a peat, or of a soil with peat in it
+pub fn describesPeat(desc: GeologicalDescription) bool {
+    if (desc.primary_soil_type) |soil_type| {
+        if (soil_type == .peat) return true;
+    }
+    if (desc.secondary_primary_soil_type) |soil_type| {
+        if (soil_type == .peat) return true;
+    }
+    for (desc.secondary_constituents) |constituent| {
+        if (std.ascii.eqlIgnoreCase(constituent.soil_type, "peaty")) return true;
+    }
+    return false;
+}
+
+test "find peat fabric and humification" {
+    const match = find("Dark brown fibrous PEAT (H3)").?;
+    try std.testing.expectEqual(PeatFabric.fibrous, match.properties.fabric.?);
+    try std.testing.expectEqual(VonPost.h3, match.properties.humification.?);
+    try std.testing.expectEqual(@as(usize, 11), match.start);
+    try std.testing.expectEqual(@as(usize, 27), match.end);
+    try std.testing.expect(match.properties.isConsistent());
+
+    const hyphenated = find("Soft pseudo-fibrous PEAT, von Post H5").?;
+    try std.testing.expectEqual(PeatFabric.pseudo_fibrous, hyphenated.properties.fabric.?);
+    try std.testing.expectEqual(VonPost.h5, hyphenated.properties.humification.?);
+
+    const spaced = find("Black pseudo fibrous PEAT").?;
+    try std.testing.expectEqual(PeatFabric.pseudo_fibrous, spaced.properties.fabric.?);
+    try std.testing.expect(spaced.properties.humification == null);
+
+    const grade_only = find("Black PEAT, H10").?;
+    try std.testing.expectEqual(VonPost.h10, grade_only.properties.humification.?);
+    try std.testing.expectEqual(PeatFabric.amorphous, grade_only.properties.humification.?.fabric());
+
+    try std.testing.expect(!(PeatProperties{ .fabric = .fibrous, .humification = .h8 }).isConsistent());
+}
+
+test "ignore text that is not a peat qualifier" {
+    try std.testing.expect(find("Firm brown CLAY") == null);
+    try std.testing.expect(find("Black PEAT, H11") == null);
+    try std.testing.expect(find("Dense SAND at 10 m, BH1") == null);
+}
+
+test "blank a von Post grade" {
+    var text = "Brown fibrous PEAT (H3)".*;
+    blank(&text, find(&text).?);
+    try std.testing.expectEqualStrings("Brown fibrous PEAT     ", &text);
+
+    var named = "Black amorphous PEAT, von Post H8".*;
+    blank(&named, find(&named).?);
+    try std.testing.expectEqualStrings("Black amorphous PEAT,            ", &named);
+}
@@ -0,0 +1,239 @@
+const std = @import("std");
+const types = @import("types.zig");
+const config = @import("config.zig");
+const generator = @import("generator.zig");
+const us_sieve = @import("us_sieve.zig");
+const regional = @import("regional.zig");
+
+const GeologicalDescription = types.GeologicalDescription;
+const CustomDictionary = config.CustomDictionary;
+
+/// A regional parsing pack. `normalize` rewrites local wording into standard
+/// BS 5930 terms before the description is tokenised.
+pub const Dialect = struct {
+    name: []const u8,
+    ptr: *anyopaque,
+    vtable: *const VTable,
+
+    pub const VTable = struct {
+        normalize: *const fn (ptr: *anyopaque, allocator: std.mem.Allocator, text: []const u8) anyerror![]u8,
+    };
+
+    /// Returns caller-owned text
+    pub fn normalize(self: Dialect, allocator: std.mem.Allocator, text: []const u8) anyerror![]u8 {
+        return self.vtable.normalize(self.ptr, allocator, text);
+    }
+};
+
+/// An output format for parsed descriptions
+pub const Exporter = struct {
+    name: []const u8,
+    ptr: *anyopaque,
+    vtable: *const VTable,
+
+    pub const VTable = struct {
+        write: *const fn (ptr: *anyopaque, allocator: std.mem.Allocator, descriptions: []const GeologicalDescription, writer: std.io.AnyWriter) anyerror!void,
+    };
+
+    pub fn write(self: Exporter, allocator: std.mem.Allocator, descriptions: []const GeologicalDescription, writer: std.io.AnyWriter) anyerror!void {
+        return self.vtable.write(self.ptr, allocator, descriptions, writer);
+    }
+};
+
+/// Dialects and exporters by name. Names and plugin state are borrowed and
+/// must outlive the registry.
+pub const Registry = struct {
+    dialects: std.StringHashMap(Dialect),
+    exporters: std.StringHashMap(Exporter),
+
+    pub fn init(allocator: std.mem.Allocator) Registry {
+        return Registry{
+            .dialects = std.StringHashMap(Dialect).init(allocator),
+            .exporters = std.StringHashMap(Exporter).init(allocator),
+        };
+    }
+
+    /// A registry with the built-in "json" and "text" exporters and the "us",
+    /// "is1498", "saice", "nordic" and "offshore" dialects
+    pub fn initWithBuiltins(allocator: std.mem.Allocator) !Registry {
+        var registry = Registry.init(allocator);
+        errdefer registry.deinit();
+        try registry.registerDialect(us_dialect);
+        try registry.registerDialect(is1498_dialect);
+        try registry.registerDialect(saice_dialect);
+        try registry.registerDialect(nordic_dialect);
+        try registry.registerDialect(offshore_dialect);
+        try registry.registerExporter(json_exporter);
+        try registry.registerExporter(text_exporter);
+        return registry;
+    }
+
+    pub fn deinit(self: *Registry) void {
+        self.dialects.deinit();
+        self.exporters.deinit();
+    }
+
+    pub fn registerDialect(self: *Registry, dialect: Dialect) !void {
+        const entry = try self.dialects.getOrPut(dialect.name);
+        if (entry.found_existing) return error.DuplicateDialect;
+        entry.value_ptr.* = dialect;
+    }
+
+    pub fn registerExporter(self: *Registry, exporter: Exporter) !void {
+        const entry = try self.exporters.getOrPut(exporter.name);
+        if (entry.found_existing) return error.DuplicateExporter;
+        entry.value_ptr.* = exporter;
+    }
+
+    pub fn getDialect(self: *const Registry, name: []const u8) ?Dialect {
+        return self.dialects.get(name);
+    }
+
+    pub fn getExporter(self: *const Registry, name: []const u8) ?Exporter {
+        return self.exporters.get(name);
+    }
+};
+
+/// A dialect backed by a term dictionary, for packs that are pure vocabulary
+pub const DictionaryDialect = struct {
+    name: []const u8,
+    dictionary: *const CustomDictionary,
+
+    pub fn dialect(self: *DictionaryDialect) Dialect {
+        return Dialect{
+            .name = self.name,
+            .ptr = self,
+            .vtable = &.{ .normalize = normalizeFn },
+        };
+    }
+
+    fn normalizeFn(ptr: *anyopaque, allocator: std.mem.Allocator, text: []const u8) anyerror![]u8 {
+        const self: *DictionaryDialect = @ptrCast(@alignCast(ptr));
+        return self.dictionary.substitute(allocator, text);
+    }
+};
+
+// Built-in exporters are stateless; the pointer is never dereferenced
+var builtin_state: u8 = 0;
+
+/// One compact JSON document per line
+pub const json_exporter = Exporter{
+    .name = "json",
+    .ptr = &builtin_state,
+    .vtable = &.{ .write = writeJson },
+};
+
+/// One generated BS 5930 description per line
+pub const text_exporter = Exporter{
+    .name = "text",
+    .ptr = &builtin_state,
+    .vtable = &.{ .write = writeText },
+};
+
+/// US sieve and inch-minus grading descriptors rewritten as BS 5930 size terms
+pub const us_dialect = Dialect{
+    .name = "us",
+    .ptr = &builtin_state,
+    .vtable = &.{ .normalize = normalizeUs },
+};
+
+fn normalizeUs(_: *anyopaque, allocator: std.mem.Allocator, text: []const u8) anyerror![]u8 {
+    return us_sieve.normalize(allocator, text);
+}
+
+/// Indian IS 1498 terms ("medium stiff", "high compressibility", "kankar")
+pub const is1498_dialect = Dialect{
+    .name = "is1498",
+    .ptr = &builtin_state,
+    .vtable = &.{ .normalize = normalizeIs1498 },
+};
+
+/// South African SAICE terms, including rock hardness classes
+pub const saice_dialect = Dialect{
+    .name = "saice",
+    .ptr = &builtin_state,
+    .vtable = &.{ .normalize = normalizeSaice },
+};
+
+/// Norwegian and Swedish terms ("kvikkleire", "sprøbruddmateriale")
+pub const nordic_dialect = Dialect{
+    .name = "nordic",
+    .ptr = &builtin_state,
+    .vtable = &.{ .normalize = normalizeNordic },
+};
+
+/// Offshore ISO 19901-8 strength classes and marine logging terms
+pub const offshore_dialect = Dialect{
+    .name = "offshore",
+    .ptr = &builtin_state,
+    .vtable = &.{ .normalize = normalizeOffshore },
+};
+
+const builtin_dialects = [_]Dialect{ us_dialect, is1498_dialect, saice_dialect, nordic_dialect, offshore_dialect };
+
+/// A built-in dialect by name, e.g. the one named by a profile's `dialect`
+pub fn builtinDialect(name: []const u8) ?Dialect {
+    for (builtin_dialects) |dialect| {
+        if (std.mem.eql(u8, dialect.name, name)) return dialect;
+    }
+    return null;
+}
+
+fn normalizeIs1498(_: *anyopaque, allocator: std.mem.Allocator, text: []const u8) anyerror![]u8 {
+    return regional.substitute(allocator, &regional.is1498_terms, text);
+}
+
+fn normalizeSaice(_: *anyopaque, allocator: std.mem.Allocator, text: []const u8) anyerror![]u8 {
+    return regional.substitute(allocator, &regional.saice_terms, text);
+}
+
+fn normalizeNordic(_: *anyopaque, allocator: std.mem.Allocator, text: []const u8) anyerror![]u8 {
+    return regional.substitute(allocator, &regional.nordic_terms, text);
+}
+
+fn normalizeOffshore(_: *anyopaque, allocator: std.mem.Allocator, text: []const u8) anyerror![]u8 {
+    return regional.substitute(allocator, &regional.offshore_terms, text);
+}
+
+fn writeJson(_: *anyopaque, allocator: std.mem.Allocator, descriptions: []const GeologicalDescription, writer: std.io.AnyWriter) anyerror!void {
+    for (descriptions) |desc| {
+        const json = try desc.toJson(allocator);
+        defer allocator.free(json);
+        try writer.print("{s}\n", .{json});
+    }
+}
+
+fn writeText(_: *anyopaque, allocator: std.mem.Allocator, descriptions: []const GeologicalDescription, writer: std.io.AnyWriter) anyerror!void {
+    for (descriptions) |desc| {
+        const text = try generator.generate(desc, allocator);
+        defer allocator.free(text);
+        try writer.print("{s}\n", .{text});
+    }
+}
+
+test "registry rejects duplicate names" {
+    const allocator = std.testing.allocator;
+    var registry = try Registry.initWithBuiltins(allocator);
+    defer registry.deinit();
+
+    try std.testing.expect(registry.getExporter("json") != null);
+    try std.testing.expect(registry.getExporter("xml") == null);
+    try std.testing.expectError(error.DuplicateExporter, registry.registerExporter(json_exporter));
+}
+
+test "dictionary dialect rewrites regional terms" {
+    const allocator = std.testing.allocator;
+
+    var dictionary = CustomDictionary.init(allocator);
+    defer dictionary.deinit();
+    try dictionary.addSoilType("boulder clay", .clay);
+
+    var pack = DictionaryDialect{ .name = "glacial", .dictionary = &dictionary };
+    var registry = Registry.init(allocator);
+    defer registry.deinit();
+    try registry.registerDialect(pack.dialect());
+
+    const normalized = try registry.getDialect("glacial").?.normalize(allocator, "Stiff boulder clay");
+    defer allocator.free(normalized);
+    try std.testing.expectEqualStrings("Stiff CLAY", normalized);
+}
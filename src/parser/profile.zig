@@ -0,0 +1,476 @@
+const std = @import("std");
+const types = @import("types.zig");
+const config = @import("config.zig");
+const validation = @import("validation.zig");
+const correlation = @import("correlation.zig");
+const lint = @import("lint.zig");
+
+const ParserConfig = config.ParserConfig;
+const CustomDictionary = config.CustomDictionary;
+
+pub const ProfileFormat = enum {
+    toml,
+    yaml,
+
+    /// Pick the format from the file extension, defaulting to TOML
+    pub fn fromPath(path: []const u8) ProfileFormat {
+        if (std.ascii.endsWithIgnoreCase(path, ".yaml") or std.ascii.endsWithIgnoreCase(path, ".yml")) return .yaml;
+        return .toml;
+    }
+};
+
+/// A project profile: dialect, standard, vocabulary, validation rules,
+/// correlation settings and output templates loaded from one file and shared
+/// by the API and the CLI.
+///
+///     name = "Northern Line Extension"
+///     standard = "BS5930:2015"
+///
+///     [validation]
+///     capitalization = "required"
+///     suppress = ["W002"]
+///
+///     [vocabulary.soil_types]
+///     "boulder clay" = "clay"
+///
+///     [lint]
+///     max_length = 120
+///     order = ["colour", "strength", "primary_type"]
+pub const Profile = struct {
+    arena: std.heap.ArenaAllocator,
+    name: ?[]const u8 = null,
+    dialect: ?[]const u8 = null, // e.g. "uk", "hong-kong"
+    standard: ?[]const u8 = null, // e.g. "BS5930:2015"
+    config: ParserConfig = .{},
+    ocr_mode: bool = false,
+    vocabulary: CustomDictionary,
+    correlation: correlation.CorrelationOptions = .{},
+    output_mode: ?[]const u8 = null, // CLI output mode name
+    output_template: ?[]const u8 = null, // Generator format name, e.g. "concise"
+    lint: lint.LintRules = .{},
+
+    pub fn init(allocator: std.mem.Allocator) Profile {
+        return Profile{
+            .arena = std.heap.ArenaAllocator.init(allocator),
+            .vocabulary = CustomDictionary.init(allocator),
+        };
+    }
+
+    pub fn deinit(self: *Profile) void {
+        self.vocabulary.deinit();
+        self.arena.deinit();
+    }
+
+    fn set(self: *Profile, path: []const []const u8, value: RawValue) !void {
+        const arena = self.arena.allocator();
+
+        if (path.len == 1) {
+            const key = path[0];
+            if (std.mem.eql(u8, key, "name")) {
+                self.name = try arena.dupe(u8, try value.scalar());
+            } else if (std.mem.eql(u8, key, "dialect")) {
+                self.dialect = try arena.dupe(u8, try value.scalar());
+            } else if (std.mem.eql(u8, key, "standard")) {
+                const name = try value.scalar();
+                if (types.Standard.fromString(name) == null) return error.InvalidProfileValue;
+                self.standard = try arena.dupe(u8, name);
+            } else return error.UnknownProfileKey;
+            return;
+        }
+
+        if (path.len == 2) {
+            const section = path[0];
+            const key = path[1];
+            if (std.mem.eql(u8, section, "parser")) {
+                if (std.mem.eql(u8, key, "min_confidence")) {
+                    self.config.min_confidence = try value.float(f32);
+                } else if (std.mem.eql(u8, key, "fuzzy_matching")) {
+                    self.config.enable_fuzzy_matching = try value.boolean();
+                } else if (std.mem.eql(u8, key, "fuzzy_threshold")) {
+                    self.config.fuzzy_threshold = try value.float(f32);
+                } else if (std.mem.eql(u8, key, "ocr_mode")) {
+                    self.ocr_mode = try value.boolean();
+                } else return error.UnknownProfileKey;
+            } else if (std.mem.eql(u8, section, "validation")) {
+                if (std.mem.eql(u8, key, "capitalization")) {
+                    self.config.capitalization_policy = validation.CapitalizationPolicy.fromString(try value.scalar()) orelse return error.InvalidProfileValue;
+                } else if (std.mem.eql(u8, key, "suppress")) {
+                    const items = try value.list();
+                    const rules = try arena.alloc([]const u8, items.len);
+                    for (items, 0..) |item, i| rules[i] = try arena.dupe(u8, item);
+                    self.config.suppressed_rules = rules;
+                } else return error.UnknownProfileKey;
+            } else if (std.mem.eql(u8, section, "noise")) {
+                if (std.mem.eql(u8, key, "ignore")) {
+                    const items = try value.list();
+                    const terms = try arena.alloc([]const u8, items.len);
+                    for (items, 0..) |item, i| terms[i] = try arena.dupe(u8, item);
+                    self.config.noise_filter.terms = terms;
+                } else if (std.mem.eql(u8, key, "defaults")) {
+                    self.config.noise_filter.use_defaults = try value.boolean();
+                } else return error.UnknownProfileKey;
+            } else if (std.mem.eql(u8, section, "correlation")) {
+                if (std.mem.eql(u8, key, "semantic_weight")) {
+                    self.correlation.semantic_weight = try value.float(f64);
+                } else if (std.mem.eql(u8, key, "depth_tolerance")) {
+                    self.correlation.depth_tolerance = try value.float(f64);
+                } else if (std.mem.eql(u8, key, "min_confidence")) {
+                    self.correlation.min_confidence = try value.float(f64);
+                } else return error.UnknownProfileKey;
+            } else if (std.mem.eql(u8, section, "output")) {
+                if (std.mem.eql(u8, key, "mode")) {
+                    self.output_mode = try arena.dupe(u8, try value.scalar());
+                } else if (std.mem.eql(u8, key, "template")) {
+                    const name = try value.scalar();
+                    if (!isTemplate(name)) return error.InvalidProfileValue;
+                    self.output_template = try arena.dupe(u8, name);
+                } else return error.UnknownProfileKey;
+            } else if (std.mem.eql(u8, section, "lint")) {
+                if (std.mem.eql(u8, key, "max_length")) {
+                    self.lint.max_length = std.fmt.parseInt(usize, try value.scalar(), 10) catch return error.InvalidProfileValue;
+                } else if (std.mem.eql(u8, key, "order")) {
+                    self.lint.order = try lintElements(arena, try value.list());
+                } else if (std.mem.eql(u8, key, "required_soil")) {
+                    self.lint.required_soil = try lintElements(arena, try value.list());
+                } else if (std.mem.eql(u8, key, "required_rock")) {
+                    self.lint.required_rock = try lintElements(arena, try value.list());
+                } else return error.UnknownProfileKey;
+            } else return error.UnknownProfileKey;
+            return;
+        }
+
+        if (path.len == 3 and std.mem.eql(u8, path[0], "vocabulary")) {
+            const term = path[2];
+            const target = try value.scalar();
+            if (std.mem.eql(u8, path[1], "soil_types")) {
+                try self.vocabulary.addSoilType(term, types.SoilType.fromString(target) orelse return error.InvalidProfileValue);
+            } else if (std.mem.eql(u8, path[1], "rock_types")) {
+                try self.vocabulary.addRockType(term, types.RockType.fromString(target) orelse return error.InvalidProfileValue);
+            } else if (std.mem.eql(u8, path[1], "consistency")) {
+                try self.vocabulary.addConsistencyTerm(term, types.Consistency.fromString(target) orelse return error.InvalidProfileValue);
+            } else return error.UnknownProfileKey;
+            return;
+        }
+
+        return error.UnknownProfileKey;
+    }
+};
+
+/// Generator formats an `[output] template` may name
+const templates = [_][]const u8{ "standard", "concise", "verbose", "bs5930" };
+
+fn isTemplate(name: []const u8) bool {
+    for (templates) |template| {
+        if (std.mem.eql(u8, name, template)) return true;
+    }
+    return false;
+}
+
+fn lintElements(arena: std.mem.Allocator, items: []const []const u8) ![]const lint.LintElement {
+    const elements = try arena.alloc(lint.LintElement, items.len);
+    for (items, 0..) |item, i| {
+        elements[i] = lint.LintElement.fromString(item) orelse return error.InvalidProfileValue;
+    }
+    return elements;
+}
+
+/// Read a profile file, choosing TOML or YAML from its extension
+pub fn loadProfile(allocator: std.mem.Allocator, path: []const u8) !Profile {
+    const file = try std.fs.cwd().openFile(path, .{});
+    defer file.close();
+
+    const text = try file.readToEndAlloc(allocator, 1024 * 1024);
+    defer allocator.free(text);
+
+    return parseProfile(allocator, text, ProfileFormat.fromPath(path));
+}
+
+/// Parse profile text. Supports the subset of TOML and YAML used by profiles:
+/// sections, string/number/boolean scalars and lists of strings.
+pub fn parseProfile(allocator: std.mem.Allocator, text: []const u8, format: ProfileFormat) !Profile {
+    var profile = Profile.init(allocator);
+    errdefer profile.deinit();
+
+    switch (format) {
+        .toml => try parseToml(allocator, &profile, text),
+        .yaml => try parseYaml(allocator, &profile, text),
+    }
+
+    try profile.config.validate();
+    return profile;
+}
+
+const RawValue = union(enum) {
+    text: []const u8,
+    items: []const []const u8,
+
+    fn scalar(self: RawValue) ![]const u8 {
+        return switch (self) {
+            .text => |t| unquote(t),
+            .items => error.InvalidProfileValue,
+        };
+    }
+
+    fn list(self: RawValue) ![]const []const u8 {
+        return switch (self) {
+            .items => |items| items,
+            .text => error.InvalidProfileValue,
+        };
+    }
+
+    fn float(self: RawValue, comptime T: type) !T {
+        return std.fmt.parseFloat(T, try self.scalar()) catch return error.InvalidProfileValue;
+    }
+
+    fn boolean(self: RawValue) !bool {
+        const value = try self.scalar();
+        if (std.mem.eql(u8, value, "true")) return true;
+        if (std.mem.eql(u8, value, "false")) return false;
+        return error.InvalidProfileValue;
+    }
+};
+
+fn parseToml(allocator: std.mem.Allocator, profile: *Profile, text: []const u8) !void {
+    var section = std.ArrayList([]const u8).init(allocator);
+    defer section.deinit();
+    var path = std.ArrayList([]const u8).init(allocator);
+    defer path.deinit();
+
+    var lines = std.mem.splitScalar(u8, text, '\n');
+    while (lines.next()) |raw_line| {
+        const line = std.mem.trim(u8, stripComment(raw_line), " \t\r");
+        if (line.len == 0) continue;
+
+        if (line[0] == '[') {
+            if (line[line.len - 1] != ']') return error.InvalidProfileSyntax;
+            section.clearRetainingCapacity();
+            var parts = std.mem.splitScalar(u8, line[1 .. line.len - 1], '.');
+            while (parts.next()) |part| {
+                try section.append(unquote(std.mem.trim(u8, part, " \t")));
+            }
+            continue;
+        }
+
+        const eq = indexOfUnquoted(line, '=') orelse return error.InvalidProfileSyntax;
+        const key = unquote(std.mem.trim(u8, line[0..eq], " \t"));
+        const value = std.mem.trim(u8, line[eq + 1 ..], " \t");
+
+        path.clearRetainingCapacity();
+        try path.appendSlice(section.items);
+        try path.append(key);
+
+        if (value.len > 0 and value[0] == '[') {
+            if (value[value.len - 1] != ']') return error.InvalidProfileSyntax;
+            const items = try parseInlineList(allocator, value[1 .. value.len - 1]);
+            defer allocator.free(items);
+            try profile.set(path.items, .{ .items = items });
+        } else {
+            try profile.set(path.items, .{ .text = value });
+        }
+    }
+}
+
+const YamlLevel = struct {
+    indent: usize,
+    key: []const u8,
+};
+
+fn parseYaml(allocator: std.mem.Allocator, profile: *Profile, text: []const u8) !void {
+    var stack = std.ArrayList(YamlLevel).init(allocator);
+    defer stack.deinit();
+    var path = std.ArrayList([]const u8).init(allocator);
+    defer path.deinit();
+
+    // Block list ("- W002") items collected for the key on top of the stack
+    var list_items = std.ArrayList([]const u8).init(allocator);
+    defer list_items.deinit();
+    var list_depth: ?usize = null;
+
+    var lines = std.mem.splitScalar(u8, text, '\n');
+    while (lines.next()) |raw_line| {
+        const without_comment = std.mem.trimRight(u8, stripComment(raw_line), " \t\r");
+        const line = std.mem.trimLeft(u8, without_comment, " ");
+        if (line.len == 0 or std.mem.eql(u8, line, "---")) continue;
+        const indent = without_comment.len - line.len;
+
+        if (std.mem.startsWith(u8, line, "- ")) {
+            if (stack.items.len == 0) return error.InvalidProfileSyntax;
+            list_depth = stack.items.len;
+            try list_items.append(std.mem.trim(u8, line[2..], " \t"));
+            continue;
+        }
+
+        // A non-item line ends any block list in progress
+        if (list_depth) |depth| {
+            try flushYamlList(profile, &path, stack.items[0..depth], list_items.items);
+            list_items.clearRetainingCapacity();
+            list_depth = null;
+        }
+
+        while (stack.items.len > 0 and stack.items[stack.items.len - 1].indent >= indent) {
+            _ = stack.pop();
+        }
+
+        const colon = indexOfUnquoted(line, ':') orelse return error.InvalidProfileSyntax;
+        const key = unquote(std.mem.trim(u8, line[0..colon], " \t"));
+        const value = std.mem.trim(u8, line[colon + 1 ..], " \t");
+
+        if (value.len == 0) {
+            try stack.append(.{ .indent = indent, .key = key });
+            continue;
+        }
+
+        path.clearRetainingCapacity();
+        for (stack.items) |level| try path.append(level.key);
+        try path.append(key);
+
+        if (value[0] == '[') {
+            if (value[value.len - 1] != ']') return error.InvalidProfileSyntax;
+            const items = try parseInlineList(allocator, value[1 .. value.len - 1]);
+            defer allocator.free(items);
+            try profile.set(path.items, .{ .items = items });
+        } else {
+            try profile.set(path.items, .{ .text = value });
+        }
+    }
+
+    if (list_depth) |depth| {
+        try flushYamlList(profile, &path, stack.items[0..depth], list_items.items);
+    }
+}
+
+fn flushYamlList(profile: *Profile, path: *std.ArrayList([]const u8), levels: []const YamlLevel, items: []const []const u8) !void {
+    path.clearRetainingCapacity();
+    for (levels) |level| try path.append(level.key);
+
+    const unquoted = try path.allocator.alloc([]const u8, items.len);
+    defer path.allocator.free(unquoted);
+    for (items, 0..) |item, i| unquoted[i] = unquote(item);
+
+    try profile.set(path.items, .{ .items = unquoted });
+}
+
+/// Split "\"W002\", 'E010', W020" into unquoted items
+fn parseInlineList(allocator: std.mem.Allocator, body: []const u8) ![]const []const u8 {
+    var items = std.ArrayList([]const u8).init(allocator);
+    errdefer items.deinit();
+
+    var parts = std.mem.splitScalar(u8, body, ',');
+    while (parts.next()) |part| {
+        const item = std.mem.trim(u8, part, " \t");
+        if (item.len == 0) continue;
+        try items.append(unquote(item));
+    }
+
+    return items.toOwnedSlice();
+}
+
+fn unquote(value: []const u8) []const u8 {
+    if (value.len >= 2) {
+        const first = value[0];
+        if ((first == '"' or first == '\'') and value[value.len - 1] == first) {
+            return value[1 .. value.len - 1];
+        }
+    }
+    return value;
+}
+
+fn indexOfUnquoted(line: []const u8, needle: u8) ?usize {
+    var quote: ?u8 = null;
+    for (line, 0..) |ch, i| {
+        if (quote) |q| {
+            if (ch == q) quote = null;
+        } else if (ch == '"' or ch == '\'') {
+            quote = ch;
+        } else if (ch == needle) {
+            return i;
+        }
+    }
+    return null;
+}
+
+fn stripComment(line: []const u8) []const u8 {
+    const hash = indexOfUnquoted(line, '#') orelse return line;
+    return line[0..hash];
+}
+
+test "parse toml profile" {
+    const allocator = std.testing.allocator;
+
+    const text =
+        \\# Project-wide settings
+        \\name = "Northern Line Extension"
+        \\standard = "BS5930:2015"
+        \\
+        \\[parser]
+        \\min_confidence = 0.6
+        \\ocr_mode = true
+        \\
+        \\[validation]
+        \\capitalization = "required"
+        \\suppress = ["W002", "MissingDensity"]
+        \\
+        \\[vocabulary.soil_types]
+        \\"boulder clay" = "clay"
+        \\
+        \\[correlation]
+        \\depth_tolerance = 2.5
+        \\
+        \\[lint]
+        \\max_length = 80
+        \\order = ["colour", "consistency", "type"]
+    ;
+
+    var profile = try parseProfile(allocator, text, .toml);
+    defer profile.deinit();
+
+    try std.testing.expectEqualStrings("Northern Line Extension", profile.name.?);
+    try std.testing.expectEqual(@as(f32, 0.6), profile.config.min_confidence);
+    try std.testing.expect(profile.ocr_mode);
+    try std.testing.expectEqual(validation.CapitalizationPolicy.required, profile.config.capitalization_policy);
+    try std.testing.expectEqual(@as(usize, 2), profile.config.suppressed_rules.len);
+    try std.testing.expectEqual(types.SoilType.clay, profile.vocabulary.lookupSoilType("boulder clay").?);
+    try std.testing.expectEqual(@as(f64, 2.5), profile.correlation.depth_tolerance);
+    try std.testing.expectEqual(@as(usize, 80), profile.lint.max_length.?);
+    try std.testing.expectEqual(lint.LintElement.colour, profile.lint.order[0]);
+    try std.testing.expectEqual(lint.LintElement.primary_type, profile.lint.order[2]);
+}
+
+test "parse yaml profile" {
+    const allocator = std.testing.allocator;
+
+    const text =
+        \\name: Crossrail East
+        \\dialect: uk
+        \\validation:
+        \\  capitalization: preferred
+        \\  suppress:
+        \\    - W002
+        \\    - W003
+        \\vocabulary:
+        \\  rock_types:
+        \\    "magnesian limestone": limestone
+        \\output:
+        \\  mode: pretty
+    ;
+
+    var profile = try parseProfile(allocator, text, .yaml);
+    defer profile.deinit();
+
+    try std.testing.expectEqualStrings("uk", profile.dialect.?);
+    try std.testing.expectEqual(validation.CapitalizationPolicy.preferred, profile.config.capitalization_policy);
+    try std.testing.expectEqual(@as(usize, 2), profile.config.suppressed_rules.len);
+    try std.testing.expectEqualStrings("W003", profile.config.suppressed_rules[1]);
+    try std.testing.expectEqual(types.RockType.limestone, profile.vocabulary.lookupRockType("magnesian limestone").?);
+    try std.testing.expectEqualStrings("pretty", profile.output_mode.?);
+}
+
+test "profile rejects unknown keys and rules" {
+    const allocator = std.testing.allocator;
+
+    try std.testing.expectError(error.UnknownProfileKey, parseProfile(allocator, "colour = \"red\"", .toml));
+    try std.testing.expectError(error.UnknownProfileKey, parseProfile(allocator, "[parser]\nstrict_bs5930 = true", .toml));
+    try std.testing.expectError(error.InvalidProfileValue, parseProfile(allocator, "standard = \"BS5390\"", .toml));
+    try std.testing.expectError(error.InvalidProfileValue, parseProfile(allocator, "[output]\ntemplate = \"brief\"", .toml));
+    try std.testing.expectError(error.UnknownValidationRule, parseProfile(allocator, "[validation]\nsuppress = [\"X999\"]", .toml));
+}
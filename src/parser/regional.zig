@@ -0,0 +1,141 @@
+const std = @import("std");
+
+/// A regional term and the BS 5930 wording it stands for
+pub const Term = struct {
+    local: []const u8,
+    standard: []const u8,
+};
+
+/// IS 1498 (India). Consistency "medium" is firm; plasticity is described as
+/// compressibility; local materials are given their nearest BS 5930 name.
+pub const is1498_terms = [_]Term{
+    .{ .local = "medium stiff", .standard = "firm" },
+    .{ .local = "medium clay", .standard = "firm CLAY" },
+    .{ .local = "medium silt", .standard = "firm SILT" },
+    .{ .local = "low compressibility", .standard = "low plasticity" },
+    .{ .local = "medium compressibility", .standard = "intermediate plasticity" },
+    .{ .local = "intermediate compressibility", .standard = "intermediate plasticity" },
+    .{ .local = "high compressibility", .standard = "high plasticity" },
+    .{ .local = "black cotton soil", .standard = "high plasticity CLAY" },
+    .{ .local = "kankar", .standard = "calcareous nodules" },
+    .{ .local = "moorum", .standard = "GRAVEL" },
+    .{ .local = "murrum", .standard = "GRAVEL" },
+    .{ .local = "murum", .standard = "GRAVEL" },
+};
+
+/// SAICE (South Africa, MCCSSO order). Soil terms match BS 5930 already;
+/// rock hardness classes map to the nearest BS 5930 strength class.
+pub const saice_terms = [_]Term{
+    .{ .local = "very soft rock", .standard = "very weak" },
+    .{ .local = "soft rock", .standard = "weak" },
+    .{ .local = "medium hard rock", .standard = "moderately strong" },
+    .{ .local = "hard rock", .standard = "strong" },
+    .{ .local = "very hard rock", .standard = "very strong" },
+    .{ .local = "extremely hard rock", .standard = "extremely strong" },
+    .{ .local = "slightly moist", .standard = "moist" },
+    .{ .local = "very moist", .standard = "wet" },
+    .{ .local = "shattered", .standard = "fractured" },
+};
+
+/// Norwegian and Swedish terms, including the quick clay and sensitivity
+/// classes, which are read into `sensitivity`
+pub const nordic_terms = [_]Term{
+    .{ .local = "kvikkleire", .standard = "quick CLAY" },
+    .{ .local = "kvicklera", .standard = "quick CLAY" },
+    .{ .local = "sprøbruddmateriale", .standard = "brittle" },
+    .{ .local = "høysensitiv", .standard = "highly sensitive" },
+    .{ .local = "middels sensitiv", .standard = "medium sensitivity" },
+    .{ .local = "lavsensitiv", .standard = "low sensitivity" },
+    .{ .local = "högsensitiv", .standard = "highly sensitive" },
+    .{ .local = "mellansensitiv", .standard = "medium sensitivity" },
+    .{ .local = "lågsensitiv", .standard = "low sensitivity" },
+    .{ .local = "leire", .standard = "CLAY" },
+    .{ .local = "lera", .standard = "CLAY" },
+    .{ .local = "grus", .standard = "GRAVEL" },
+    .{ .local = "siltig", .standard = "silty" },
+    .{ .local = "sandig", .standard = "sandy" },
+    .{ .local = "leirig", .standard = "clayey" },
+    .{ .local = "lerig", .standard = "clayey" },
+    .{ .local = "grusig", .standard = "gravelly" },
+    .{ .local = "bløt", .standard = "soft" },
+};
+
+/// Offshore (ISO 19901-8) logging terms. Undrained strength classes map to
+/// the BS 5930 consistency covering the same shear strength range (below
+/// 10 and 10-20 kPa are both very soft, 20-40 soft, 40-75 firm and so on);
+/// spellings are brought into line so marine indicators read the same
+/// either way.
+pub const offshore_terms = [_]Term{
+    .{ .local = "extremely low strength", .standard = "very soft" },
+    .{ .local = "very low strength", .standard = "very soft" },
+    .{ .local = "low strength", .standard = "soft" },
+    .{ .local = "medium strength", .standard = "firm" },
+    .{ .local = "high strength", .standard = "stiff" },
+    .{ .local = "very high strength", .standard = "very stiff" },
+    .{ .local = "extremely high strength", .standard = "hard" },
+    .{ .local = "h2s odor", .standard = "H2S odour" },
+    .{ .local = "hydrogen sulfide odor", .standard = "H2S odour" },
+    .{ .local = "shell hash", .standard = "shell fragments" },
+    .{ .local = "shell debris", .standard = "shell fragments" },
+    .{ .local = "gray", .standard = "grey" },
+};
+
+/// Replace whole-word regional terms, case-insensitively and longest first.
+/// Returns caller-owned text.
+pub fn substitute(allocator: std.mem.Allocator, terms: []const Term, text: []const u8) ![]u8 {
+    var output = std.ArrayList(u8).init(allocator);
+    errdefer output.deinit();
+
+    var pos: usize = 0;
+    while (pos < text.len) {
+        if (pos == 0 or !std.ascii.isAlphanumeric(text[pos - 1])) {
+            if (longestAt(terms, text, pos)) |term| {
+                try output.appendSlice(term.standard);
+                pos += term.local.len;
+                continue;
+            }
+        }
+        try output.append(text[pos]);
+        pos += 1;
+    }
+
+    return output.toOwnedSlice();
+}
+
+fn longestAt(terms: []const Term, text: []const u8, pos: usize) ?Term {
+    var best: ?Term = null;
+    for (terms) |term| {
+        if (!std.ascii.startsWithIgnoreCase(text[pos..], term.local)) continue;
+        const end = pos + term.local.len;
+        if (end < text.len and std.ascii.isAlphanumeric(text[end])) continue;
+        if (best) |current| {
+            if (current.local.len >= term.local.len) continue;
+        }
+        best = term;
+    }
+    return best;
+}
+
+test "regional terms map onto BS 5930 wording" {
+    const allocator = std.testing.allocator;
+
+    const indian = try substitute(allocator, &is1498_terms, "Medium stiff grey silty clay of high compressibility with kankar");
+    defer allocator.free(indian);
+    try std.testing.expectEqualStrings("firm grey silty clay of high plasticity with calcareous nodules", indian);
+
+    const south_african = try substitute(allocator, &saice_terms, "Slightly moist pinkish grey very soft rock granite");
+    defer allocator.free(south_african);
+    try std.testing.expectEqualStrings("moist pinkish grey very weak granite", south_african);
+
+    const nordic = try substitute(allocator, &nordic_terms, "Bløt siltig kvikkleire");
+    defer allocator.free(nordic);
+    try std.testing.expectEqualStrings("soft silty quick CLAY", nordic);
+
+    const offshore = try substitute(allocator, &offshore_terms, "Extremely low strength dark gray CLAY with shell hash, H2S odor");
+    defer allocator.free(offshore);
+    try std.testing.expectEqualStrings("very soft dark grey CLAY with shell fragments, H2S odour", offshore);
+
+    const low = try substitute(allocator, &offshore_terms, "Low strength CLAY over medium strength CLAY");
+    defer allocator.free(low);
+    try std.testing.expectEqualStrings("soft CLAY over firm CLAY", low);
+}
@@ -0,0 +1,159 @@
+const std = @import("std");
+const types = @import("types.zig");
+const borehole = @import("borehole.zig");
+
+const BoreholeLog = borehole.BoreholeLog;
+const Field = types.Field;
+const GeologicalDescription = types.GeologicalDescription;
+
+pub const StratumChange = enum {
+    unchanged,
+    /// The description says something different, but not weaker
+    changed,
+    /// Weaker, more weathered or softer than before, or rock now logged as soil
+    degraded,
+    /// Only in the later log
+    added,
+    /// Only in the earlier log
+    removed,
+
+    pub fn toString(self: StratumChange) []const u8 {
+        return @tagName(self);
+    }
+};
+
+/// A stratum of the later log against the earlier stratum at its mid-depth
+pub const StratumComparison = struct {
+    before_index: ?usize,
+    after_index: ?usize,
+    change: StratumChange,
+    /// Fields whose values differ between the two descriptions
+    fields: std.EnumSet(Field) = .{},
+};
+
+pub const LogComparison = struct {
+    /// Later strata in depth order, then earlier strata that no later
+    /// stratum falls in
+    strata: []StratumComparison,
+
+    pub fn deinit(self: LogComparison, allocator: std.mem.Allocator) void {
+        allocator.free(self.strata);
+    }
+
+    /// How many strata are anything other than unchanged
+    pub fn changedCount(self: LogComparison) usize {
+        var count: usize = 0;
+        for (self.strata) |item| {
+            if (item.change != .unchanged) count += 1;
+        }
+        return count;
+    }
+};
+
+/// The fields compared between logging events
+const compared_fields = [_]Field{
+    .material_type,
+    .consistency,
+    .density,
+    .primary_soil_type,
+    .primary_rock_type,
+    .rock_strength,
+    .weathering_grade,
+    .rock_structure,
+    .color,
+    .moisture_content,
+    .karst_grade,
+};
+
+/// Compares two logging events of the same borehole, such as the original
+/// log and a re-log of monitoring core, stratum by stratum. Each stratum of
+/// `after` is matched to the `before` stratum containing its mid-depth;
+/// strata that have grown weaker, more weathered or softer are `degraded`.
+pub fn compareLogs(allocator: std.mem.Allocator, before: BoreholeLog, after: BoreholeLog) !LogComparison {
+    var strata = std.ArrayList(StratumComparison).init(allocator);
+    errdefer strata.deinit();
+
+    const matched = try allocator.alloc(bool, before.strata.len);
+    defer allocator.free(matched);
+    @memset(matched, false);
+
+    for (after.strata, 0..) |stratum, after_index| {
+        const before_index = stratumAt(before, stratum.midDepth()) orelse {
+            try strata.append(.{ .before_index = null, .after_index = after_index, .change = .added });
+            continue;
+        };
+        matched[before_index] = true;
+
+        const earlier = before.strata[before_index].description;
+        const fields = differingFields(earlier, stratum.description);
+        const change: StratumChange = if (fields.count() == 0)
+            .unchanged
+        else if (isDegraded(earlier, stratum.description))
+            .degraded
+        else
+            .changed;
+        try strata.append(.{ .before_index = before_index, .after_index = after_index, .change = change, .fields = fields });
+    }
+
+    for (matched, 0..) |was_matched, before_index| {
+        if (was_matched) continue;
+        try strata.append(.{ .before_index = before_index, .after_index = null, .change = .removed });
+    }
+
+    return LogComparison{ .strata = try strata.toOwnedSlice() };
+}
+
+fn stratumAt(log: BoreholeLog, depth: f64) ?usize {
+    for (log.strata, 0..) |stratum, i| {
+        if (depth >= stratum.depth_top and depth < stratum.depth_bottom) return i;
+    }
+    return null;
+}
+
+fn differingFields(a: GeologicalDescription, b: GeologicalDescription) std.EnumSet(Field) {
+    var fields = std.EnumSet(Field){};
+    inline for (compared_fields) |field| {
+        if (!std.meta.eql(@field(a, @tagName(field)), @field(b, @tagName(field)))) fields.insert(field);
+    }
+    return fields;
+}
+
+fn isDegraded(before: GeologicalDescription, after: GeologicalDescription) bool {
+    if (before.material_type == .rock and after.material_type == .soil) return true;
+    if (before.rock_strength != null and after.rock_strength != null and
+        @intFromEnum(after.rock_strength.?) < @intFromEnum(before.rock_strength.?)) return true;
+    if (before.weathering_grade != null and after.weathering_grade != null and
+        @intFromEnum(after.weathering_grade.?) > @intFromEnum(before.weathering_grade.?)) return true;
+    // Ranges such as "firm to stiff" compare by their undrained strength
+    if (before.consistency != null and after.consistency != null and
+        after.consistency.?.cuRange().getMidpoint() < before.consistency.?.cuRange().getMidpoint()) return true;
+    return false;
+}
+
+test "compare re-logged borehole" {
+    const before_strata = [_]borehole.Stratum{
+        .{ .depth_top = 0.0, .depth_bottom = 2.0, .description = .{ .raw_description = "Firm CLAY", .material_type = .soil, .consistency = .firm, .primary_soil_type = .clay } },
+        .{ .depth_top = 2.0, .depth_bottom = 5.0, .description = .{ .raw_description = "Strong slightly weathered MUDSTONE", .material_type = .rock, .rock_strength = .strong, .weathering_grade = .slightly_weathered, .primary_rock_type = .mudstone } },
+        .{ .depth_top = 5.0, .depth_bottom = 8.0, .description = .{ .raw_description = "Strong LIMESTONE", .material_type = .rock, .rock_strength = .strong, .primary_rock_type = .limestone } },
+    };
+    const after_strata = [_]borehole.Stratum{
+        .{ .depth_top = 0.0, .depth_bottom = 2.0, .description = .{ .raw_description = "Firm CLAY", .material_type = .soil, .consistency = .firm, .primary_soil_type = .clay } },
+        .{ .depth_top = 2.0, .depth_bottom = 4.0, .description = .{ .raw_description = "Weak moderately weathered MUDSTONE", .material_type = .rock, .rock_strength = .weak, .weathering_grade = .moderately_weathered, .primary_rock_type = .mudstone } },
+        .{ .depth_top = 8.0, .depth_bottom = 9.0, .description = .{ .raw_description = "Stiff CLAY", .material_type = .soil, .consistency = .stiff, .primary_soil_type = .clay } },
+    };
+    const before = BoreholeLog{ .id = "BH01", .logged_on = "2024-03-01", .strata = &before_strata };
+    const after = BoreholeLog{ .id = "BH01", .logged_on = "2026-03-01", .strata = &after_strata };
+
+    const comparison = try compareLogs(std.testing.allocator, before, after);
+    defer comparison.deinit(std.testing.allocator);
+
+    try std.testing.expectEqual(@as(usize, 4), comparison.strata.len);
+    try std.testing.expectEqual(StratumChange.unchanged, comparison.strata[0].change);
+    try std.testing.expectEqual(StratumChange.degraded, comparison.strata[1].change);
+    try std.testing.expect(comparison.strata[1].fields.contains(.rock_strength));
+    try std.testing.expect(comparison.strata[1].fields.contains(.weathering_grade));
+    try std.testing.expectEqual(StratumChange.added, comparison.strata[2].change);
+    try std.testing.expectEqual(StratumChange.removed, comparison.strata[3].change);
+    try std.testing.expectEqual(@as(usize, 2), comparison.strata[3].before_index.?);
+    try std.testing.expectEqual(@as(usize, 3), comparison.changedCount());
+}
@@ -0,0 +1,243 @@
+const std = @import("std");
+const types = @import("types.zig");
+const validation = @import("validation.zig");
+
+const GeologicalDescription = types.GeologicalDescription;
+const SoilDescription = types.SoilDescription;
+const Validator = validation.Validator;
+const ValidationResult = validation.ValidationResult;
+
+pub const ReportFormat = enum {
+    csv,
+    html,
+
+    pub fn fromString(str: []const u8) ?ReportFormat {
+        if (std.ascii.eqlIgnoreCase(str, "csv")) return .csv;
+        if (std.ascii.eqlIgnoreCase(str, "html")) return .html;
+        return null;
+    }
+
+    /// Pick the format from a file extension, defaulting to CSV
+    pub fn fromPath(path: []const u8) ReportFormat {
+        if (std.ascii.endsWithIgnoreCase(path, ".html") or std.ascii.endsWithIgnoreCase(path, ".htm")) return .html;
+        return .csv;
+    }
+};
+
+/// One parsed description and the source row it came from (1-based)
+pub const ReportRow = struct {
+    row: usize,
+    description: *const GeologicalDescription,
+};
+
+pub const BatchSummary = struct {
+    total: usize = 0,
+    valid: usize = 0,
+    errors: usize = 0,
+    warnings: usize = 0,
+    infos: usize = 0,
+    /// Descriptions with no findings at all
+    clean: usize = 0,
+};
+
+/// Validate each row and write a reviewer-friendly report: one line per
+/// finding keyed by source row number, plus summary counts in HTML.
+pub fn writeBatchReport(
+    allocator: std.mem.Allocator,
+    validator: *Validator,
+    rows: []const ReportRow,
+    writer: anytype,
+    format: ReportFormat,
+) !BatchSummary {
+    const results = try allocator.alloc(ValidationResult, rows.len);
+    var checked: usize = 0;
+    defer {
+        for (results[0..checked]) |result| result.deinit(allocator);
+        allocator.free(results);
+    }
+
+    var summary = BatchSummary{};
+    for (rows, 0..) |row, i| {
+        results[i] = try validator.check(row.description);
+        checked += 1;
+
+        summary.total += 1;
+        if (results[i].is_valid) summary.valid += 1;
+        if (results[i].findings.len == 0) summary.clean += 1;
+        summary.errors += results[i].count(.err);
+        summary.warnings += results[i].count(.warning);
+        summary.infos += results[i].count(.info);
+    }
+
+    switch (format) {
+        .csv => try writeCsv(rows, results, writer),
+        .html => try writeHtml(rows, results, summary, writer),
+    }
+
+    return summary;
+}
+
+fn writeCsv(rows: []const ReportRow, results: []const ValidationResult, writer: anytype) !void {
+    try writer.writeAll("row,description,valid,confidence,code,rule,level,message\n");
+
+    for (rows, results) |row, result| {
+        const desc = row.description;
+        if (result.findings.len == 0) {
+            try writer.print("{d},", .{row.row});
+            try writeCsvField(writer, desc.raw_description);
+            try writer.print(",{s},{d:.2},,,,\n", .{ if (result.is_valid) "true" else "false", desc.confidence });
+            continue;
+        }
+
+        for (result.findings) |finding| {
+            try writer.print("{d},", .{row.row});
+            try writeCsvField(writer, desc.raw_description);
+            try writer.print(",{s},{d:.2},{s},{s},{s},", .{
+                if (result.is_valid) "true" else "false",
+                desc.confidence,
+                finding.code(),
+                finding.rule.name(),
+                finding.level().toString(),
+            });
+            try writeCsvField(writer, finding.message());
+            try writer.writeAll("\n");
+        }
+    }
+}
+
+fn writeCsvField(writer: anytype, value: []const u8) !void {
+    const needs_quotes = std.mem.indexOfAny(u8, value, ",\"\n\r") != null;
+    if (!needs_quotes) return writer.writeAll(value);
+
+    try writer.writeByte('"');
+    for (value) |ch| {
+        if (ch == '"') try writer.writeByte('"');
+        try writer.writeByte(ch);
+    }
+    try writer.writeByte('"');
+}
+
+fn writeHtml(rows: []const ReportRow, results: []const ValidationResult, summary: BatchSummary, writer: anytype) !void {
+    try writer.writeAll(
+        \\<!DOCTYPE html>
+        \\<html>
+        \\<head>
+        \\<meta charset="utf-8">
+        \\<title>Litholog validation report</title>
+        \\<style>
+        \\body { font-family: sans-serif; margin: 2em; }
+        \\table { border-collapse: collapse; width: 100%; }
+        \\th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; vertical-align: top; }
+        \\tr.invalid td.valid { color: #b00020; font-weight: bold; }
+        \\.error { color: #b00020; }
+        \\.warning { color: #b36b00; }
+        \\.info { color: #555; }
+        \\</style>
+        \\</head>
+        \\<body>
+        \\<h1>Validation report</h1>
+        \\
+    );
+
+    try writer.print(
+        \\<table class="summary">
+        \\<tr><th>Descriptions</th><td>{d}</td></tr>
+        \\<tr><th>Valid</th><td>{d}</td></tr>
+        \\<tr><th>No findings</th><td>{d}</td></tr>
+        \\<tr><th>Errors</th><td class="error">{d}</td></tr>
+        \\<tr><th>Warnings</th><td class="warning">{d}</td></tr>
+        \\<tr><th>Info</th><td class="info">{d}</td></tr>
+        \\</table>
+        \\
+    , .{ summary.total, summary.valid, summary.clean, summary.errors, summary.warnings, summary.infos });
+
+    // Quick links to every row that needs attention
+    if (summary.clean < summary.total) {
+        try writer.writeAll("<h2>Rows with findings</h2>\n<p>");
+        var first = true;
+        for (rows, results) |row, result| {
+            if (result.findings.len == 0) continue;
+            if (!first) try writer.writeAll(", ");
+            first = false;
+            try writer.print("<a href=\"#row-{d}\">{d}</a>", .{ row.row, row.row });
+        }
+        try writer.writeAll("</p>\n");
+    }
+
+    try writer.writeAll("<h2>Findings</h2>\n<table class=\"findings\">\n<tr><th>Row</th><th>Description</th><th>Valid</th><th>Confidence</th><th>Findings</th></tr>\n");
+    for (rows, results) |row, result| {
+        try writer.print("<tr id=\"row-{d}\" class=\"{s}\"><td><a href=\"#row-{d}\">{d}</a></td><td>", .{
+            row.row,
+            if (result.is_valid) "valid" else "invalid",
+            row.row,
+            row.row,
+        });
+        try writeHtmlEscaped(writer, row.description.raw_description);
+        try writer.print("</td><td class=\"valid\">{s}</td><td>{d:.2}</td><td>", .{
+            if (result.is_valid) "yes" else "no",
+            row.description.confidence,
+        });
+        for (result.findings, 0..) |finding, i| {
+            if (i > 0) try writer.writeAll("<br>");
+            try writer.print("<span class=\"{s}\">{s} {s}</span>: ", .{ finding.level().toString(), finding.code(), finding.rule.name() });
+            try writeHtmlEscaped(writer, finding.message());
+        }
+        try writer.writeAll("</td></tr>\n");
+    }
+    try writer.writeAll("</table>\n</body>\n</html>\n");
+}
+
+fn writeHtmlEscaped(writer: anytype, text: []const u8) !void {
+    for (text) |ch| {
+        switch (ch) {
+            '<' => try writer.writeAll("&lt;"),
+            '>' => try writer.writeAll("&gt;"),
+            '&' => try writer.writeAll("&amp;"),
+            '"' => try writer.writeAll("&quot;"),
+            '\'' => try writer.writeAll("&#39;"),
+            else => try writer.writeByte(ch),
+        }
+    }
+}
+
+test "csv batch report lists findings by row" {
+    const allocator = std.testing.allocator;
+    var validator = Validator.init(allocator);
+
+    const firm_clay = SoilDescription{ .raw_description = "Firm CLAY", .material_type = .soil, .primary_soil_type = .clay, .consistency = .firm };
+    const sand = SoilDescription{ .raw_description = "SAND, with gravel", .material_type = .soil, .primary_soil_type = .sand };
+    const rows = [_]ReportRow{
+        .{ .row = 2, .description = &firm_clay },
+        .{ .row = 3, .description = &sand },
+    };
+
+    var output = std.ArrayList(u8).init(allocator);
+    defer output.deinit();
+
+    const summary = try writeBatchReport(allocator, &validator, &rows, output.writer(), .csv);
+
+    try std.testing.expectEqual(@as(usize, 2), summary.total);
+    try std.testing.expectEqual(@as(usize, 1), summary.clean);
+    try std.testing.expectEqual(@as(usize, 1), summary.warnings);
+    try std.testing.expect(std.mem.indexOf(u8, output.items, "2,Firm CLAY,true,1.00,,,,\n") != null);
+    try std.testing.expect(std.mem.indexOf(u8, output.items, "3,\"SAND, with gravel\",true,1.00,W003,MissingDensity,warning,") != null);
+}
+
+test "html batch report escapes text and links rows" {
+    const allocator = std.testing.allocator;
+    var validator = Validator.init(allocator);
+
+    const dense_clay = SoilDescription{ .raw_description = "Dense CLAY <fill>", .material_type = .soil, .primary_soil_type = .clay, .density = .dense };
+    const rows = [_]ReportRow{.{ .row = 7, .description = &dense_clay }};
+
+    var output = std.ArrayList(u8).init(allocator);
+    defer output.deinit();
+
+    const summary = try writeBatchReport(allocator, &validator, &rows, output.writer(), .html);
+
+    try std.testing.expectEqual(@as(usize, 0), summary.valid);
+    try std.testing.expect(summary.errors > 0);
+    try std.testing.expect(std.mem.indexOf(u8, output.items, "Dense CLAY &lt;fill&gt;") != null);
+    try std.testing.expect(std.mem.indexOf(u8, output.items, "<a href=\"#row-7\">7</a>") != null);
+    try std.testing.expect(std.mem.indexOf(u8, output.items, "E012 DensityOnCohesiveSoil") != null);
+}
@@ -0,0 +1,176 @@
+const std = @import("std");
+const types = @import("types.zig");
+
+const GeologicalDescription = types.GeologicalDescription;
+const SoilProperties = types.SoilProperties;
+const RockProperties = types.RockProperties;
+
+/// Fields every description has, whatever the material. Slices borrow from
+/// the result they came from.
+pub const CommonFields = struct {
+    raw_description: []const u8,
+    geological_formation: ?[]const u8 = null,
+    condition_notes: []const types.ConditionNote = &.{},
+    alternative_type: ?types.AlternativeType = null,
+    /// Interbedded or interlaminated lithologies, with their proportions
+    composite: ?types.CompositeDescription = null,
+    color: ?types.Color = null,
+    secondary_color: ?types.Color = null,
+    colour_detail: ?types.Colour = null,
+    munsell_colour: ?types.MunsellColour = null,
+    moisture_content: ?types.MoistureContent = null,
+    /// Everything named after "with", soils included
+    tertiary_constituents: []const types.TertiaryConstituent = &.{},
+    marine_indicators: std.EnumSet(types.MarineIndicator) = .{},
+    gas_indicators: ?types.GasIndicators = null,
+    fossil_frequency: ?types.Frequency = null,
+    strength_parameters: ?types.StrengthParameters = null,
+    boundary_advisory: ?types.BoundaryAdvisory = null,
+    structure: ?[]const u8 = null,
+    confidence: f32 = 1.0,
+    warnings: []const []const u8 = &.{},
+    spelling_corrections: []const types.SpellingCorrection = &.{},
+    absences: []const types.Absence = &.{},
+    is_valid: bool = true,
+    sources: std.EnumMap(types.Field, types.Source) = .{},
+    spans: std.EnumMap(types.Field, types.Span) = .{},
+
+    fn of(desc: *const GeologicalDescription) CommonFields {
+        return CommonFields{
+            .raw_description = desc.raw_description,
+            .geological_formation = desc.geological_formation,
+            .condition_notes = desc.condition_notes,
+            .alternative_type = desc.alternative_type,
+            .composite = desc.composite,
+            .color = desc.color,
+            .secondary_color = desc.secondary_color,
+            .colour_detail = desc.colour_detail,
+            .munsell_colour = desc.munsell_colour,
+            .moisture_content = desc.moisture_content,
+            .tertiary_constituents = desc.tertiary_constituents,
+            .marine_indicators = desc.marine_indicators,
+            .gas_indicators = desc.gas_indicators,
+            .fossil_frequency = desc.fossil_frequency,
+            .strength_parameters = desc.strength_parameters,
+            .boundary_advisory = desc.boundary_advisory,
+            .structure = desc.structure,
+            .confidence = desc.confidence,
+            .warnings = desc.warnings,
+            .spelling_corrections = desc.spelling_corrections,
+            .absences = desc.absences,
+            .is_valid = desc.is_valid,
+            .sources = desc.sources,
+            .spans = desc.spans,
+        };
+    }
+};
+
+/// A soil description: the common fields and the soil-only ones
+pub const SoilResult = struct {
+    common: CommonFields,
+    properties: SoilProperties,
+    /// Soils named after "with", such as the cobbles and boulders of a till
+    inclusions: []const types.Inclusion = &.{},
+    constituent_guidance: ?types.ConstituentGuidance = null,
+    is_made_ground: bool = false,
+    made_ground_label: ?[]const u8 = null,
+    is_topsoil: bool = false,
+    material_origin: ?types.MaterialOrigin = null,
+    made_ground_constituents: []const types.MadeGroundConstituent = &.{},
+};
+
+/// A rock description: the common fields and the rock-only ones
+pub const RockResult = struct {
+    common: CommonFields,
+    properties: RockProperties,
+};
+
+/// A parse result typed by material. Switch on `material`, or use
+/// `asSoil`/`asRock` when only one material is of interest.
+pub const Description = struct {
+    material: union(types.MaterialType) {
+        soil: SoilResult,
+        rock: RockResult,
+    },
+    /// The parse result the fields borrow from, kept only to free it
+    owned: *anyopaque,
+
+    /// Takes ownership of `desc`, which must have been allocated with
+    /// `allocator`; on error `desc` is left to the caller
+    pub fn fromGeological(allocator: std.mem.Allocator, desc: GeologicalDescription) !Description {
+        const owned = try allocator.create(GeologicalDescription);
+        owned.* = desc;
+
+        const shared = CommonFields.of(owned);
+        return Description{
+            .material = switch (owned.material_type) {
+                .soil => .{ .soil = .{
+                    .common = shared,
+                    .properties = owned.soil().?,
+                    .inclusions = owned.inclusions,
+                    .constituent_guidance = owned.constituent_guidance,
+                    .is_made_ground = owned.is_made_ground,
+                    .made_ground_label = owned.made_ground_label,
+                    .is_topsoil = owned.is_topsoil,
+                    .material_origin = owned.material_origin,
+                    .made_ground_constituents = owned.made_ground_constituents,
+                } },
+                .rock => .{ .rock = .{ .common = shared, .properties = owned.rock().? } },
+            },
+            .owned = owned,
+        };
+    }
+
+    pub fn deinit(self: Description, allocator: std.mem.Allocator) void {
+        const owned: *GeologicalDescription = @ptrCast(@alignCast(self.owned));
+        owned.deinit(allocator);
+        allocator.destroy(owned);
+    }
+
+    /// Fields every description has: colour, strength, confidence, warnings...
+    pub fn common(self: *const Description) *const CommonFields {
+        return switch (self.material) {
+            .soil => |*soil| &soil.common,
+            .rock => |*rock| &rock.common,
+        };
+    }
+
+    pub fn rawDescription(self: *const Description) []const u8 {
+        return self.common().raw_description;
+    }
+
+    pub fn confidence(self: *const Description) f32 {
+        return self.common().confidence;
+    }
+
+    pub fn asSoil(self: *const Description) ?*const SoilResult {
+        return switch (self.material) {
+            .soil => |*soil| soil,
+            .rock => null,
+        };
+    }
+
+    pub fn asRock(self: *const Description) ?*const RockResult {
+        return switch (self.material) {
+            .rock => |*rock| rock,
+            .soil => null,
+        };
+    }
+};
+
+test "description switches on material" {
+    const allocator = std.testing.allocator;
+
+    const raw = try allocator.dupe(u8, "Strong LIMESTONE");
+    const typed = try Description.fromGeological(allocator, .{
+        .raw_description = raw,
+        .material_type = .rock,
+        .rock_strength = .strong,
+        .primary_rock_type = .limestone,
+    });
+    defer typed.deinit(allocator);
+
+    try std.testing.expect(typed.asSoil() == null);
+    try std.testing.expectEqual(types.RockStrength.strong, typed.asRock().?.properties.rock_strength.?);
+    try std.testing.expectEqualStrings("Strong LIMESTONE", typed.rawDescription());
+}
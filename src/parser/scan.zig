@@ -0,0 +1,109 @@
+const std = @import("std");
+
+/// A word of a description, with the punctuation that follows it
+pub const Word = struct {
+    text: []const u8,
+    start: usize,
+    end: usize,
+    /// The hyphen or slash tying the word to the next one ("grey-brown",
+    /// "subangular/subrounded")
+    joiner: ?u8 = null,
+    /// A comma follows the word
+    comma: bool = false,
+    /// A percent sign follows the word
+    percent: bool = false,
+    /// A full stop, semicolon or colon follows; the sentence ends here
+    stop: bool = false,
+    /// A bracket follows the word
+    bracket: bool = false,
+
+    pub fn joinedBy(self: Word, joiner: u8) bool {
+        const found = self.joiner orelse return false;
+        return found == joiner;
+    }
+
+    /// Punctuation other than a joining hyphen or slash follows, so a
+    /// phrase ends here
+    pub fn endsPhrase(self: Word) bool {
+        return self.comma or self.percent or self.stop or self.bracket;
+    }
+};
+
+/// What a word is made of
+pub const Kind = enum {
+    /// Letters only, so "sub-angular" is two words joined by a hyphen
+    letters,
+    /// Letters and digits ("H4", "60")
+    alphanumeric,
+    /// Letters and digits with hyphens, apostrophes and decimal points
+    /// ("well-graded", "0.5m")
+    terms,
+};
+
+/// The first word at or after `from`, or null at the end of the text
+pub fn next(text: []const u8, from: usize, kind: Kind) ?Word {
+    var start = from;
+    while (start < text.len and !isWordChar(text, start, kind)) start += 1;
+    if (start >= text.len) return null;
+
+    var end = start;
+    while (end < text.len and isWordChar(text, end, kind)) end += 1;
+
+    var word = Word{ .text = text[start..end], .start = start, .end = end };
+    if (end < text.len and (text[end] == '-' or text[end] == '/')) {
+        word.joiner = text[end];
+        return word;
+    }
+    var after = end;
+    while (after < text.len and !isWordChar(text, after, kind)) : (after += 1) {
+        switch (text[after]) {
+            ',' => word.comma = true,
+            '%' => word.percent = true,
+            '.', ';', ':' => word.stop = true,
+            '(', ')', '[', ']' => word.bracket = true,
+            else => {},
+        }
+    }
+    return word;
+}
+
+fn isWordChar(text: []const u8, i: usize, kind: Kind) bool {
+    const c = text[i];
+    return switch (kind) {
+        .letters => std.ascii.isAlphabetic(c),
+        .alphanumeric => std.ascii.isAlphanumeric(c),
+        .terms => std.ascii.isAlphanumeric(c) or c == '-' or c == '\'' or
+            // Decimal point ("0.5m")
+            (c == '.' and i > 0 and i + 1 < text.len and std.ascii.isDigit(text[i - 1]) and std.ascii.isDigit(text[i + 1])),
+    };
+}
+
+test "scan words and the punctuation after them" {
+    const text = "grey-brown, sub-angular (60%) well-graded 0.5m. SAND";
+
+    const grey = next(text, 0, .letters).?;
+    try std.testing.expectEqualStrings("grey", grey.text);
+    try std.testing.expect(grey.joinedBy('-'));
+    try std.testing.expect(!grey.endsPhrase());
+
+    const brown = next(text, grey.end, .letters).?;
+    try std.testing.expect(brown.comma);
+
+    const angular = next(text, next(text, brown.end, .letters).?.end, .letters).?;
+    try std.testing.expectEqualStrings("angular", angular.text);
+    try std.testing.expect(angular.bracket);
+
+    const percent = next(text, angular.end, .alphanumeric).?;
+    try std.testing.expectEqualStrings("60", percent.text);
+    try std.testing.expect(percent.percent and percent.bracket);
+
+    const graded = next(text, percent.end, .terms).?;
+    try std.testing.expectEqualStrings("well-graded", graded.text);
+
+    const thickness = next(text, graded.end, .terms).?;
+    try std.testing.expectEqualStrings("0.5m", thickness.text);
+    try std.testing.expect(thickness.stop);
+
+    try std.testing.expectEqualStrings("SAND", next(text, thickness.end, .terms).?.text);
+    try std.testing.expect(next(text, text.len, .letters) == null);
+}
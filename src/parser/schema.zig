@@ -0,0 +1,232 @@
+const std = @import("std");
+const types = @import("types.zig");
+
+const GeologicalDescription = types.GeologicalDescription;
+
+const Column = struct {
+    name: []const u8,
+    sql_type: []const u8,
+};
+
+/// Columns of the results table, in the order COPY rows are written
+const columns = [_]Column{
+    .{ .name = "raw_description", .sql_type = "text NOT NULL" },
+    .{ .name = "material_type", .sql_type = "text NOT NULL" },
+    .{ .name = "consistency", .sql_type = "text" },
+    .{ .name = "density", .sql_type = "text" },
+    .{ .name = "primary_soil_type", .sql_type = "text" },
+    .{ .name = "secondary_primary_soil_type", .sql_type = "text" },
+    .{ .name = "secondary_constituents", .sql_type = "text[] NOT NULL DEFAULT '{}'" },
+    .{ .name = "rock_strength", .sql_type = "text" },
+    .{ .name = "weathering_grade", .sql_type = "text" },
+    .{ .name = "rock_structure", .sql_type = "text" },
+    .{ .name = "primary_rock_type", .sql_type = "text" },
+    .{ .name = "geological_formation", .sql_type = "text" },
+    .{ .name = "is_made_ground", .sql_type = "boolean NOT NULL DEFAULT false" },
+    .{ .name = "color", .sql_type = "text" },
+    .{ .name = "moisture_content", .sql_type = "text" },
+    .{ .name = "plasticity_index", .sql_type = "text" },
+    .{ .name = "particle_size", .sql_type = "text" },
+    .{ .name = "strength_parameter", .sql_type = "text" },
+    .{ .name = "strength_lower", .sql_type = "real" },
+    .{ .name = "strength_upper", .sql_type = "real" },
+    .{ .name = "strength_typical", .sql_type = "real" },
+    .{ .name = "confidence", .sql_type = "real NOT NULL" },
+    .{ .name = "is_valid", .sql_type = "boolean NOT NULL" },
+    .{ .name = "warnings", .sql_type = "text[] NOT NULL DEFAULT '{}'" },
+};
+
+/// Recommended Postgres schema for parsed results: the table plus indexes
+/// for the usual filters. `table` may be schema-qualified ("geo.descriptions").
+pub fn postgresDdl(allocator: std.mem.Allocator, table: []const u8) ![]u8 {
+    try checkTableName(table);
+
+    var ddl = std.ArrayList(u8).init(allocator);
+    errdefer ddl.deinit();
+    const writer = ddl.writer();
+
+    try writer.print("CREATE TABLE IF NOT EXISTS {s} (\n    id bigserial PRIMARY KEY,\n", .{table});
+    for (columns) |column| {
+        try writer.print("    {s} {s},\n", .{ column.name, column.sql_type });
+    }
+    try writer.writeAll("    parsed_at timestamptz NOT NULL DEFAULT now()\n);\n");
+
+    // Index names cannot be qualified, so drop any schema prefix
+    const base = if (std.mem.lastIndexOfScalar(u8, table, '.')) |dot| table[dot + 1 ..] else table;
+    for ([_][]const u8{ "material_type", "primary_soil_type", "primary_rock_type", "geological_formation" }) |name| {
+        try writer.print("CREATE INDEX IF NOT EXISTS {s}_{s}_idx ON {s} ({s});\n", .{ base, name, table, name });
+    }
+
+    return ddl.toOwnedSlice();
+}
+
+fn checkTableName(table: []const u8) !void {
+    if (table.len == 0) return error.InvalidTableName;
+    var parts = std.mem.splitScalar(u8, table, '.');
+    var count: usize = 0;
+    while (parts.next()) |part| : (count += 1) {
+        if (part.len == 0 or std.ascii.isDigit(part[0])) return error.InvalidTableName;
+        for (part) |ch| {
+            if (!std.ascii.isAlphanumeric(ch) and ch != '_') return error.InvalidTableName;
+        }
+    }
+    if (count > 2) return error.InvalidTableName;
+}
+
+/// Writes descriptions in Postgres COPY text format, ready to pipe into
+/// `psql` or a driver's COPY FROM STDIN:
+///
+///     var copy = CopyWriter.init(allocator);
+///     try copy.begin(writer, "descriptions");
+///     for (descriptions) |*desc| try copy.write(writer, desc);
+///     try copy.end(writer);
+pub const CopyWriter = struct {
+    allocator: std.mem.Allocator,
+
+    pub fn init(allocator: std.mem.Allocator) CopyWriter {
+        return CopyWriter{ .allocator = allocator };
+    }
+
+    /// The COPY statement naming the table and columns
+    pub fn begin(self: *const CopyWriter, writer: anytype, table: []const u8) !void {
+        _ = self;
+        try checkTableName(table);
+        try writer.print("COPY {s} (", .{table});
+        for (columns, 0..) |column, i| {
+            if (i > 0) try writer.writeAll(", ");
+            try writer.writeAll(column.name);
+        }
+        try writer.writeAll(") FROM STDIN;\n");
+    }
+
+    /// One row per description. Rows alone, without `begin` and `end`, suit
+    /// drivers that stream COPY data themselves.
+    pub fn write(self: *const CopyWriter, writer: anytype, desc: *const GeologicalDescription) !void {
+        try writeText(writer, desc.raw_description);
+        try writeTab(writer);
+        try writeText(writer, desc.material_type.toString());
+        try writeEnum(writer, desc.consistency);
+        try writeEnum(writer, desc.density);
+        try writeEnum(writer, desc.primary_soil_type);
+        try writeEnum(writer, desc.secondary_primary_soil_type);
+
+        try writeTab(writer);
+        const constituents = try self.allocator.alloc([]const u8, desc.secondary_constituents.len);
+        var built: usize = 0;
+        defer {
+            for (constituents[0..built]) |text| self.allocator.free(text);
+            self.allocator.free(constituents);
+        }
+        for (desc.secondary_constituents) |constituent| {
+            constituents[built] = try constituent.toString(self.allocator);
+            built += 1;
+        }
+        try writeArray(writer, constituents);
+
+        try writeEnum(writer, desc.rock_strength);
+        try writeEnum(writer, desc.weathering_grade);
+        try writeEnum(writer, desc.rock_structure);
+        try writeEnum(writer, desc.primary_rock_type);
+        try writeTab(writer);
+        try writeOptionalText(writer, desc.geological_formation);
+        try writer.print("\t{s}", .{if (desc.is_made_ground) "t" else "f"});
+        try writeEnum(writer, desc.color);
+        try writeEnum(writer, desc.moisture_content);
+        try writeEnum(writer, desc.plasticity_index);
+        try writeEnum(writer, desc.particle_size);
+
+        if (desc.strength_parameters) |params| {
+            const range = params.range;
+            try writer.print("\t{s}\t{d}\t{d}\t{d}", .{
+                params.parameter_type.toString(),
+                range.lower_bound,
+                range.upper_bound,
+                range.typical_value orelse range.getMidpoint(),
+            });
+        } else {
+            try writer.writeAll("\t\\N\t\\N\t\\N\t\\N");
+        }
+
+        try writer.print("\t{d}\t{s}\t", .{ desc.confidence, if (desc.is_valid) "t" else "f" });
+        try writeArray(writer, desc.warnings);
+        try writer.writeByte('\n');
+    }
+
+    /// The end-of-data marker psql expects after the rows
+    pub fn end(self: *const CopyWriter, writer: anytype) !void {
+        _ = self;
+        try writer.writeAll("\\.\n");
+    }
+};
+
+fn writeTab(writer: anytype) !void {
+    try writer.writeByte('\t');
+}
+
+fn writeEnum(writer: anytype, value: anytype) !void {
+    try writeTab(writer);
+    if (value) |v| return writeText(writer, v.toString());
+    try writer.writeAll("\\N");
+}
+
+fn writeOptionalText(writer: anytype, value: ?[]const u8) !void {
+    if (value) |text| return writeText(writer, text);
+    try writer.writeAll("\\N");
+}
+
+/// Escape a value for COPY text format
+fn writeText(writer: anytype, text: []const u8) !void {
+    for (text) |ch| {
+        switch (ch) {
+            '\\' => try writer.writeAll("\\\\"),
+            '\t' => try writer.writeAll("\\t"),
+            '\n' => try writer.writeAll("\\n"),
+            '\r' => try writer.writeAll("\\r"),
+            else => try writer.writeByte(ch),
+        }
+    }
+}
+
+/// A text[] literal with every element quoted, then escaped for COPY
+fn writeArray(writer: anytype, items: []const []const u8) !void {
+    try writer.writeByte('{');
+    for (items, 0..) |item, i| {
+        if (i > 0) try writer.writeByte(',');
+        try writer.writeByte('"');
+        for (item) |ch| {
+            // Array quoting first: \ and " gain a backslash, which COPY then doubles
+            if (ch == '"' or ch == '\\') try writer.writeAll("\\\\");
+            try writeText(writer, &[_]u8{ch});
+        }
+        try writer.writeByte('"');
+    }
+    try writer.writeByte('}');
+}
+
+test "postgres ddl and copy rows share the column list" {
+    const allocator = std.testing.allocator;
+
+    const ddl = try postgresDdl(allocator, "geo.descriptions");
+    defer allocator.free(ddl);
+    try std.testing.expect(std.mem.startsWith(u8, ddl, "CREATE TABLE IF NOT EXISTS geo.descriptions (\n"));
+    try std.testing.expect(std.mem.indexOf(u8, ddl, "CREATE INDEX IF NOT EXISTS descriptions_material_type_idx ON geo.descriptions (material_type);") != null);
+    try std.testing.expectError(error.InvalidTableName, postgresDdl(allocator, "descriptions; DROP TABLE x"));
+
+    var constituents = [_]types.SecondaryConstituent{.{ .amount = "slightly", .soil_type = "sandy" }};
+    const desc = GeologicalDescription{
+        .raw_description = "Firm slightly sandy CLAY\twith \"quotes\"",
+        .material_type = .soil,
+        .consistency = .firm,
+        .primary_soil_type = .clay,
+        .secondary_constituents = &constituents,
+    };
+
+    var output = std.ArrayList(u8).init(allocator);
+    defer output.deinit();
+    const copy = CopyWriter.init(allocator);
+    try copy.write(output.writer(), &desc);
+
+    const row = std.mem.trimRight(u8, output.items, "\n");
+    try std.testing.expectEqual(columns.len, std.mem.count(u8, row, "\t") + 1);
+    try std.testing.expect(std.mem.startsWith(u8, row, "Firm slightly sandy CLAY\\twith \"quotes\"\tsoil\tfirm\t\\N\tCLAY\t\\N\t{\"slightly sandy\"}\t"));
+}
@@ -0,0 +1,90 @@
+const std = @import("std");
+const types = @import("types.zig");
+const bs5930 = @import("bs5930.zig");
+
+/// A description and the fields it must parse to. Fields left null must
+/// also be null in the result.
+const Case = struct {
+    description: []const u8,
+    material_type: types.MaterialType,
+    consistency: ?types.Consistency = null,
+    density: ?types.Density = null,
+    primary_soil_type: ?types.SoilType = null,
+    rock_strength: ?types.RockStrength = null,
+    weathering_grade: ?types.WeatheringGrade = null,
+    rock_structure: ?types.RockStructure = null,
+    primary_rock_type: ?types.RockType = null,
+    constituents: usize = 0,
+};
+
+/// Small enough to run on every startup, broad enough to cover soil, rock,
+/// ranges and constituents
+pub const corpus = [_]Case{
+    .{ .description = "Firm CLAY", .material_type = .soil, .consistency = .firm, .primary_soil_type = .clay },
+    .{ .description = "Dense SAND", .material_type = .soil, .density = .dense, .primary_soil_type = .sand },
+    .{ .description = "Firm to stiff CLAY", .material_type = .soil, .consistency = .firm_to_stiff, .primary_soil_type = .clay },
+    .{ .description = "Firm slightly sandy slightly gravelly CLAY", .material_type = .soil, .consistency = .firm, .primary_soil_type = .clay, .constituents = 2 },
+    .{ .description = "Strong LIMESTONE", .material_type = .rock, .rock_strength = .strong, .primary_rock_type = .limestone },
+    .{
+        .description = "Strong slightly weathered jointed LIMESTONE",
+        .material_type = .rock,
+        .rock_strength = .strong,
+        .weathering_grade = .slightly_weathered,
+        .rock_structure = .jointed,
+        .primary_rock_type = .limestone,
+    },
+};
+
+/// The first corpus result that did not match
+pub const Failure = struct {
+    /// Position in `corpus`
+    index: usize,
+    description: []const u8,
+    /// Field name as in the JSON output, or "parse" if parsing failed
+    field: []const u8,
+};
+
+/// Parse the corpus and report the first mismatch, or null if every case
+/// matched. A parse error is reported as a failure, not returned.
+pub fn run(allocator: std.mem.Allocator) !?Failure {
+    var parser = bs5930.Parser.init(allocator);
+
+    for (corpus, 0..) |case, index| {
+        const result = parser.parse(case.description) catch |err| switch (err) {
+            error.OutOfMemory => return err,
+            else => return Failure{ .index = index, .description = case.description, .field = "parse" },
+        };
+        defer result.deinit(allocator);
+
+        if (mismatch(case, result)) |field| return Failure{ .index = index, .description = case.description, .field = field };
+    }
+    return null;
+}
+
+/// Check the parser gives the expected results before serving traffic, so a
+/// broken build or install fails at startup rather than on real data
+pub fn selfTest(allocator: std.mem.Allocator) !void {
+    if (try run(allocator) != null) return error.SelfTestFailed;
+}
+
+fn mismatch(case: Case, result: types.GeologicalDescription) ?[]const u8 {
+    inline for (.{
+        "material_type",
+        "consistency",
+        "density",
+        "primary_soil_type",
+        "rock_strength",
+        "weathering_grade",
+        "rock_structure",
+        "primary_rock_type",
+    }) |field| {
+        if (!std.meta.eql(@field(case, field), @field(result, field))) return field;
+    }
+    if (case.constituents != result.secondary_constituents.len) return "secondary_constituents";
+    return null;
+}
+
+test "self-test corpus passes" {
+    try std.testing.expectEqual(@as(?Failure, null), try run(std.testing.allocator));
+    try selfTest(std.testing.allocator);
+}
@@ -0,0 +1,59 @@
+const std = @import("std");
+const types = @import("types.zig");
+
+const Sensitivity = types.Sensitivity;
+
+/// A sensitivity phrase found in a description
+pub const Match = struct {
+    sensitivity: Sensitivity,
+    start: usize,
+    end: usize,
+};
+
+const phrases = [_]struct { phrase: []const u8, sensitivity: Sensitivity }{
+    .{ .phrase = "quick", .sensitivity = .quick },
+    .{ .phrase = "extra sensitive", .sensitivity = .quick },
+    .{ .phrase = "brittle", .sensitivity = .brittle },
+    .{ .phrase = "highly sensitive", .sensitivity = .high },
+    .{ .phrase = "high sensitivity", .sensitivity = .high },
+    .{ .phrase = "sensitive", .sensitivity = .medium },
+    .{ .phrase = "moderately sensitive", .sensitivity = .medium },
+    .{ .phrase = "medium sensitivity", .sensitivity = .medium },
+    .{ .phrase = "slightly sensitive", .sensitivity = .low },
+    .{ .phrase = "low sensitivity", .sensitivity = .low },
+};
+
+/// The first sensitivity phrase in `text` ("quick CLAY", "highly sensitive
+/// silty CLAY"), preferring the longest phrase at a position. Whole words
+/// only, case-insensitive.
+pub fn find(text: []const u8) ?Match {
+    var pos: usize = 0;
+    while (pos < text.len) : (pos += 1) {
+        if (pos > 0 and std.ascii.isAlphanumeric(text[pos - 1])) continue;
+
+        var best: ?Match = null;
+        for (phrases) |entry| {
+            if (!std.ascii.startsWithIgnoreCase(text[pos..], entry.phrase)) continue;
+            const end = pos + entry.phrase.len;
+            if (end < text.len and std.ascii.isAlphanumeric(text[end])) continue;
+            if (best) |current| {
+                if (current.end >= end) continue;
+            }
+            best = Match{ .sensitivity = entry.sensitivity, .start = pos, .end = end };
+        }
+        if (best) |match| return match;
+    }
+    return null;
+}
+
+test "find sensitivity phrases" {
+    try std.testing.expectEqual(Sensitivity.quick, find("Soft grey quick CLAY").?.sensitivity);
+    try std.testing.expectEqual(Sensitivity.high, find("Soft highly sensitive silty CLAY").?.sensitivity);
+
+    const match = find("Firm slightly sensitive CLAY").?;
+    try std.testing.expectEqual(Sensitivity.low, match.sensitivity);
+    try std.testing.expectEqual(@as(usize, 5), match.start);
+
+    try std.testing.expect(find("Firm CLAY") == null);
+    try std.testing.expect(find("Firm insensitive CLAY") == null);
+}
@@ -0,0 +1,173 @@
+const std = @import("std");
+const types = @import("types.zig");
+const borehole = @import("borehole.zig");
+const generator = @import("generator.zig");
+
+const GeologicalDescription = types.GeologicalDescription;
+const Stratum = borehole.Stratum;
+const BoreholeLog = borehole.BoreholeLog;
+
+/// How a subordinate lithology sits within a layer
+pub const Relation = enum {
+    interbedded, // "Interbedded strong LIMESTONE and weak MUDSTONE"
+    with_bands_of, // "Firm CLAY with bands of dense SAND"
+};
+
+/// A second lithology within a layer
+pub const Minor = struct {
+    relation: Relation,
+    description: GeologicalDescription,
+};
+
+pub const Layer = struct {
+    stratum: Stratum,
+    minor: ?Minor = null,
+};
+
+/// Builds a layered sequence from the top down. Descriptions are borrowed
+/// and must outlive the builder and anything built from it.
+///
+///     var builder = SequenceBuilder.init(allocator);
+///     try builder.layer(1.2, made_ground);
+///     try builder.layer(3.0, clay);
+///     try builder.withBandsOf(sand);
+pub const SequenceBuilder = struct {
+    allocator: std.mem.Allocator,
+    layers: std.ArrayList(Layer),
+    depth: f64 = 0,
+
+    pub fn init(allocator: std.mem.Allocator) SequenceBuilder {
+        return SequenceBuilder{
+            .allocator = allocator,
+            .layers = std.ArrayList(Layer).init(allocator),
+        };
+    }
+
+    /// Start at a depth other than ground level
+    pub fn initAt(allocator: std.mem.Allocator, depth_top: f64) SequenceBuilder {
+        var builder = SequenceBuilder.init(allocator);
+        builder.depth = depth_top;
+        return builder;
+    }
+
+    pub fn deinit(self: *SequenceBuilder) void {
+        self.layers.deinit();
+    }
+
+    /// Add a layer of `thickness` metres below the previous one
+    pub fn layer(self: *SequenceBuilder, thickness: f64, description: GeologicalDescription) !void {
+        if (thickness <= 0) return error.InvalidThickness;
+        try self.layers.append(Layer{ .stratum = .{
+            .depth_top = self.depth,
+            .depth_bottom = self.depth + thickness,
+            .description = description,
+        } });
+        self.depth += thickness;
+    }
+
+    /// Make the last layer interbedded with `other`
+    pub fn interbedded(self: *SequenceBuilder, other: GeologicalDescription) !void {
+        try self.setMinor(.interbedded, other);
+    }
+
+    /// Give the last layer bands of `other`
+    pub fn withBandsOf(self: *SequenceBuilder, other: GeologicalDescription) !void {
+        try self.setMinor(.with_bands_of, other);
+    }
+
+    fn setMinor(self: *SequenceBuilder, relation: Relation, other: GeologicalDescription) !void {
+        if (self.layers.items.len == 0) return error.NoLayer;
+        const last = &self.layers.items[self.layers.items.len - 1];
+        if (last.minor != null) return error.MinorAlreadySet;
+        last.minor = Minor{ .relation = relation, .description = other };
+    }
+
+    /// BS 5930 text for each layer, top down. Caller frees each string and
+    /// the slice.
+    pub fn generate(self: *const SequenceBuilder, allocator: std.mem.Allocator) ![][]u8 {
+        const texts = try allocator.alloc([]u8, self.layers.items.len);
+        var count: usize = 0;
+        errdefer {
+            for (texts[0..count]) |text| allocator.free(text);
+            allocator.free(texts);
+        }
+
+        for (self.layers.items) |item| {
+            texts[count] = try layerText(item, allocator);
+            count += 1;
+        }
+        return texts;
+    }
+
+    /// The whole sequence as a log, one "top-bottom m: text" line per layer.
+    /// Returns caller-owned text.
+    pub fn generateLog(self: *const SequenceBuilder, allocator: std.mem.Allocator) ![]u8 {
+        var log = std.ArrayList(u8).init(allocator);
+        errdefer log.deinit();
+
+        for (self.layers.items) |item| {
+            const text = try layerText(item, allocator);
+            defer allocator.free(text);
+            try log.writer().print("{d:.2}-{d:.2} m: {s}\n", .{ item.stratum.depth_top, item.stratum.depth_bottom, text });
+        }
+        return log.toOwnedSlice();
+    }
+
+    /// A borehole log of the layers' main lithologies. The caller frees
+    /// `strata` with the same allocator.
+    pub fn boreholeLog(self: *const SequenceBuilder, allocator: std.mem.Allocator, id: []const u8, ground_level: ?f64) !BoreholeLog {
+        const strata = try allocator.alloc(Stratum, self.layers.items.len);
+        for (self.layers.items, 0..) |item, i| strata[i] = item.stratum;
+        return BoreholeLog{ .id = id, .ground_level = ground_level, .strata = strata };
+    }
+};
+
+fn layerText(item: Layer, allocator: std.mem.Allocator) ![]u8 {
+    const main = try generator.generateBS5930(item.stratum.description, allocator);
+    defer allocator.free(main);
+
+    const minor = item.minor orelse return capitalized(allocator, "{s}", .{main});
+    const other = try generator.generateBS5930(minor.description, allocator);
+    defer allocator.free(other);
+
+    return switch (minor.relation) {
+        .interbedded => std.fmt.allocPrint(allocator, "Interbedded {s} and {s}", .{ main, other }),
+        .with_bands_of => capitalized(allocator, "{s} with bands of {s}", .{ main, other }),
+    };
+}
+
+fn capitalized(allocator: std.mem.Allocator, comptime format: []const u8, args: anytype) ![]u8 {
+    const text = try std.fmt.allocPrint(allocator, format, args);
+    if (text.len > 0) text[0] = std.ascii.toUpper(text[0]);
+    return text;
+}
+
+test "sequence builder composes interbedded and banded layers" {
+    const allocator = std.testing.allocator;
+
+    const clay = GeologicalDescription{ .raw_description = "", .material_type = .soil, .consistency = .firm, .primary_soil_type = .clay };
+    const sand = GeologicalDescription{ .raw_description = "", .material_type = .soil, .density = .dense, .primary_soil_type = .sand };
+    const limestone = GeologicalDescription{ .raw_description = "", .material_type = .rock, .rock_strength = .strong, .primary_rock_type = .limestone };
+    const mudstone = GeologicalDescription{ .raw_description = "", .material_type = .rock, .rock_strength = .weak, .primary_rock_type = .mudstone };
+
+    var builder = SequenceBuilder.init(allocator);
+    defer builder.deinit();
+    try builder.layer(2.5, clay);
+    try builder.withBandsOf(sand);
+    try builder.layer(4.0, limestone);
+    try builder.interbedded(mudstone);
+    try std.testing.expectError(error.MinorAlreadySet, builder.interbedded(clay));
+
+    const texts = try builder.generate(allocator);
+    defer {
+        for (texts) |text| allocator.free(text);
+        allocator.free(texts);
+    }
+    try std.testing.expectEqualStrings("Firm CLAY with bands of dense SAND", texts[0]);
+    try std.testing.expectEqualStrings("Interbedded strong LIMESTONE and weak MUDSTONE", texts[1]);
+
+    const log = try builder.boreholeLog(allocator, "BH01", 30.0);
+    defer allocator.free(log.strata);
+    try std.testing.expectApproxEqAbs(@as(f64, 6.5), log.finalDepth(), 0.0001);
+    try std.testing.expectApproxEqAbs(@as(f64, 2.5), log.strata[1].depth_top, 0.0001);
+}
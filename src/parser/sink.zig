@@ -0,0 +1,193 @@
+const std = @import("std");
+const types = @import("types.zig");
+const hooks = @import("hooks.zig");
+const csvio = @import("csvio.zig");
+
+const GeologicalDescription = types.GeologicalDescription;
+
+/// One parsed row on its way to a sink. `description` is only valid for the
+/// duration of the `write` call.
+pub const Result = struct {
+    /// Source row number, 1-based
+    row: usize,
+    description: *const GeologicalDescription,
+};
+
+/// Where processed results go, so parsing does not care how output is handled
+pub const Sink = struct {
+    ptr: *anyopaque,
+    vtable: *const VTable,
+
+    pub const VTable = struct {
+        write: *const fn (ptr: *anyopaque, result: Result) anyerror!void,
+        flush: *const fn (ptr: *anyopaque) anyerror!void,
+    };
+
+    pub fn write(self: Sink, result: Result) anyerror!void {
+        return self.vtable.write(self.ptr, result);
+    }
+
+    pub fn flush(self: Sink) anyerror!void {
+        return self.vtable.flush(self.ptr);
+    }
+};
+
+fn noFlush(_: *anyopaque) anyerror!void {}
+
+/// One JSON document per line, like the "json" exporter
+pub const JsonlSink = struct {
+    allocator: std.mem.Allocator,
+    writer: std.io.AnyWriter,
+
+    pub fn sink(self: *JsonlSink) Sink {
+        return Sink{ .ptr = self, .vtable = &.{ .write = writeFn, .flush = noFlush } };
+    }
+
+    fn writeFn(ptr: *anyopaque, result: Result) anyerror!void {
+        const self: *JsonlSink = @ptrCast(@alignCast(ptr));
+        const json = try result.description.toJson(self.allocator);
+        defer self.allocator.free(json);
+        try self.writer.print("{s}\n", .{json});
+    }
+};
+
+/// Flattened CSV rows; see `csvio.Options` for the columns
+pub const CsvSink = struct {
+    csv: csvio.Writer,
+    writer: std.io.AnyWriter,
+
+    pub fn init(allocator: std.mem.Allocator, writer: std.io.AnyWriter, options: csvio.Options) CsvSink {
+        return CsvSink{ .csv = csvio.Writer.init(allocator, options), .writer = writer };
+    }
+
+    pub fn sink(self: *CsvSink) Sink {
+        return Sink{ .ptr = self, .vtable = &.{ .write = writeFn, .flush = noFlush } };
+    }
+
+    fn writeFn(ptr: *anyopaque, result: Result) anyerror!void {
+        const self: *CsvSink = @ptrCast(@alignCast(ptr));
+        try self.csv.write(self.writer, result.description);
+    }
+};
+
+/// Calls a function with each result, e.g. to insert into a database
+pub const CallbackSink = struct {
+    hook: hooks.Hook(Result),
+
+    pub fn init(context: anytype, comptime callback: anytype) CallbackSink {
+        return CallbackSink{ .hook = hooks.Hook(Result).init(context, callback) };
+    }
+
+    pub fn sink(self: *CallbackSink) Sink {
+        return Sink{ .ptr = self, .vtable = &.{ .write = writeFn, .flush = noFlush } };
+    }
+
+    fn writeFn(ptr: *anyopaque, result: Result) anyerror!void {
+        const self: *CallbackSink = @ptrCast(@alignCast(ptr));
+        try self.hook.call(result);
+    }
+};
+
+/// A queue of results as JSON, for handing output to another thread. The
+/// consumer calls `receive` until it returns null, which happens once the
+/// queue is empty and the channel is closed, by `close` or by `flush` at the
+/// end of a job.
+pub const ChannelSink = struct {
+    allocator: std.mem.Allocator,
+    mutex: std.Thread.Mutex = .{},
+    ready: std.Thread.Condition = .{},
+    queue: std.ArrayList([]u8),
+    head: usize = 0,
+    closed: bool = false,
+
+    pub fn init(allocator: std.mem.Allocator) ChannelSink {
+        return ChannelSink{ .allocator = allocator, .queue = std.ArrayList([]u8).init(allocator) };
+    }
+
+    /// Frees anything not yet received
+    pub fn deinit(self: *ChannelSink) void {
+        for (self.queue.items[self.head..]) |json| self.allocator.free(json);
+        self.queue.deinit();
+    }
+
+    pub fn sink(self: *ChannelSink) Sink {
+        return Sink{ .ptr = self, .vtable = &.{ .write = writeFn, .flush = flushFn } };
+    }
+
+    /// No more results will be written
+    pub fn close(self: *ChannelSink) void {
+        self.mutex.lock();
+        defer self.mutex.unlock();
+        self.closed = true;
+        self.ready.broadcast();
+    }
+
+    /// The next result as caller-owned JSON, blocking until one arrives
+    pub fn receive(self: *ChannelSink) ?[]u8 {
+        self.mutex.lock();
+        defer self.mutex.unlock();
+        while (self.head == self.queue.items.len and !self.closed) self.ready.wait(&self.mutex);
+        if (self.head == self.queue.items.len) return null;
+
+        const json = self.queue.items[self.head];
+        self.head += 1;
+        if (self.head == self.queue.items.len) {
+            self.queue.clearRetainingCapacity();
+            self.head = 0;
+        }
+        return json;
+    }
+
+    fn writeFn(ptr: *anyopaque, result: Result) anyerror!void {
+        const self: *ChannelSink = @ptrCast(@alignCast(ptr));
+        const json = try result.description.toJson(self.allocator);
+        errdefer self.allocator.free(json);
+
+        self.mutex.lock();
+        defer self.mutex.unlock();
+        if (self.closed) return error.SinkClosed;
+        try self.queue.append(json);
+        self.ready.signal();
+    }
+
+    fn flushFn(ptr: *anyopaque) anyerror!void {
+        const self: *ChannelSink = @ptrCast(@alignCast(ptr));
+        self.close();
+    }
+};
+
+test "sinks receive every result" {
+    const allocator = std.testing.allocator;
+    const desc = GeologicalDescription{ .raw_description = "Firm CLAY", .material_type = .soil, .consistency = .firm };
+
+    var lines = std.ArrayList(u8).init(allocator);
+    defer lines.deinit();
+    const lines_writer = lines.writer();
+    var jsonl = JsonlSink{ .allocator = allocator, .writer = lines_writer.any() };
+    try jsonl.sink().write(.{ .row = 1, .description = &desc });
+    try std.testing.expect(std.mem.startsWith(u8, lines.items, "{\"raw_description\":\"Firm CLAY\""));
+
+    const Counter = struct {
+        rows: usize = 0,
+
+        fn record(self: *@This(), result: Result) !void {
+            self.rows += result.row;
+        }
+    };
+    var counter = Counter{};
+    var callback = CallbackSink.init(&counter, Counter.record);
+    try callback.sink().write(.{ .row = 7, .description = &desc });
+    try std.testing.expectEqual(@as(usize, 7), counter.rows);
+
+    var channel = ChannelSink.init(allocator);
+    defer channel.deinit();
+    const output = channel.sink();
+    try output.write(.{ .row = 1, .description = &desc });
+    try output.flush();
+    try std.testing.expectError(error.SinkClosed, output.write(.{ .row = 2, .description = &desc }));
+
+    const json = channel.receive().?;
+    defer allocator.free(json);
+    try std.testing.expect(std.mem.indexOf(u8, json, "\"consistency\":\"firm\"") != null);
+    try std.testing.expect(channel.receive() == null);
+}
@@ -0,0 +1,98 @@
+const std = @import("std");
+const profile = @import("profile.zig");
+
+const Profile = profile.Profile;
+
+/// An immutable, reference-counted profile. Parses hold a reference for their
+/// whole run, so a reload never frees settings that are still in use.
+pub const ProfileSnapshot = struct {
+    allocator: std.mem.Allocator,
+    profile: Profile,
+    refs: std.atomic.Value(usize),
+
+    pub fn release(self: *ProfileSnapshot) void {
+        if (self.refs.fetchSub(1, .acq_rel) == 1) {
+            self.profile.deinit();
+            self.allocator.destroy(self);
+        }
+    }
+
+    fn retain(self: *ProfileSnapshot) void {
+        _ = self.refs.fetchAdd(1, .monotonic);
+    }
+};
+
+/// The current project profile for a server. `replace` and `reload` swap in a
+/// new snapshot atomically; in-flight parses keep the one they started with.
+pub const ProfileStore = struct {
+    allocator: std.mem.Allocator,
+    mutex: std.Thread.Mutex = .{},
+    current: *ProfileSnapshot,
+
+    /// Takes ownership of `initial`
+    pub fn init(allocator: std.mem.Allocator, initial: Profile) !ProfileStore {
+        return ProfileStore{
+            .allocator = allocator,
+            .current = try createSnapshot(allocator, initial),
+        };
+    }
+
+    pub fn deinit(self: *ProfileStore) void {
+        self.current.release();
+    }
+
+    /// The current snapshot with a reference taken; call `release` when done
+    pub fn acquire(self: *ProfileStore) *ProfileSnapshot {
+        self.mutex.lock();
+        defer self.mutex.unlock();
+        self.current.retain();
+        return self.current;
+    }
+
+    /// Swap in a new profile, taking ownership of it on success
+    pub fn replace(self: *ProfileStore, next: Profile) !void {
+        const snapshot = try createSnapshot(self.allocator, next);
+
+        self.mutex.lock();
+        const previous = self.current;
+        self.current = snapshot;
+        self.mutex.unlock();
+
+        previous.release();
+    }
+
+    /// Load a profile file and swap it in. On error the current profile stays.
+    pub fn reload(self: *ProfileStore, path: []const u8) !void {
+        var next = try profile.loadProfile(self.allocator, path);
+        errdefer next.deinit();
+        try self.replace(next);
+    }
+
+    fn createSnapshot(allocator: std.mem.Allocator, value: Profile) !*ProfileSnapshot {
+        const snapshot = try allocator.create(ProfileSnapshot);
+        snapshot.* = ProfileSnapshot{
+            .allocator = allocator,
+            .profile = value,
+            .refs = std.atomic.Value(usize).init(1),
+        };
+        return snapshot;
+    }
+};
+
+test "replaced profile lives until its last reader releases it" {
+    const allocator = std.testing.allocator;
+
+    var store = try ProfileStore.init(allocator, try profile.parseProfile(allocator, "name = \"first\"", .toml));
+    defer store.deinit();
+
+    const in_flight = store.acquire();
+    try store.replace(try profile.parseProfile(allocator, "name = \"second\"", .toml));
+
+    // The old snapshot is still readable by the parse that holds it
+    try std.testing.expectEqualStrings("first", in_flight.profile.name.?);
+    in_flight.release();
+
+    const latest = store.acquire();
+    defer latest.release();
+    try std.testing.expectEqualStrings("second", latest.profile.name.?);
+}
@@ -0,0 +1,222 @@
+const std = @import("std");
+const types = @import("types.zig");
+const scan = @import("scan.zig");
+
+const DiscontinuitySpacing = types.DiscontinuitySpacing;
+const SpacingTerm = types.SpacingTerm;
+const Word = scan.Word;
+
+/// A spacing found in a description
+pub const Match = struct {
+    spacing: DiscontinuitySpacing,
+    start: usize,
+    end: usize,
+};
+
+/// A stated spacing in mm
+const Range = struct {
+    min: u32,
+    max: u32,
+    end: usize,
+};
+
+/// The first discontinuity spacing, written as a term ("very closely
+/// spaced"), as a range after "spaced" or "spacing" ("spaced 200-600 mm",
+/// "spacing of 0.2 to 0.6 m"), or both ("closely spaced (60-150 mm)").
+/// A range sets the bounds and, when it sits within one band, the term.
+pub fn find(text: []const u8) ?Match {
+    var previous: ?Word = null;
+    var before_previous: ?Word = null;
+
+    var pos: usize = 0;
+    while (scan.next(text, pos, .letters)) |word| {
+        pos = word.end;
+        defer {
+            before_previous = previous;
+            previous = word;
+        }
+
+        const spaced = std.ascii.eqlIgnoreCase(word.text, "spaced");
+        if (!spaced and !std.ascii.eqlIgnoreCase(word.text, "spacing")) continue;
+
+        var start = word.start;
+        var term: ?SpacingTerm = null;
+        if (spaced) {
+            if (previous) |adverb| {
+                // "very closely", "extremely closely"
+                if (before_previous) |modifier| {
+                    if (std.mem.eql(u8, text[modifier.end..adverb.start], " ")) {
+                        if (SpacingTerm.fromString(text[modifier.start..adverb.end])) |found| {
+                            term = found;
+                            start = modifier.start;
+                        }
+                    }
+                }
+                if (term == null) {
+                    if (SpacingTerm.fromString(adverb.text)) |found| {
+                        term = found;
+                        start = adverb.start;
+                    }
+                }
+            }
+        }
+
+        const range = readRange(text, skipLinkWord(text, word.end));
+        if (term == null and range == null) continue;
+
+        var spacing = DiscontinuitySpacing{ .term = term, .min_mm = 0 };
+        if (range) |stated| {
+            spacing.min_mm = stated.min;
+            spacing.max_mm = stated.max;
+            if (spacing.term == null) spacing.term = SpacingTerm.fromMm(stated.min, stated.max);
+        } else {
+            const band = term.?.rangeMm();
+            spacing.min_mm = band.min;
+            spacing.max_mm = band.max;
+        }
+        return Match{ .spacing = spacing, .start = start, .end = if (range) |stated| stated.end else word.end };
+    }
+    return null;
+}
+
+/// Overwrite the spacing with spaces, so "medium" is not read as a particle
+/// size and the figures are not left for the lexer
+pub fn blank(text: []u8, match: Match) void {
+    @memset(text[match.start..match.end], ' ');
+}
+
+/// Past "at" or "of" after "spaced" or "spacing"
+fn skipLinkWord(text: []const u8, from: usize) usize {
+    const start = skipSpaces(text, from);
+    const word = scan.next(text, start, .letters) orelse return from;
+    if (word.start != start) return from;
+    if (std.ascii.eqlIgnoreCase(word.text, "at") or std.ascii.eqlIgnoreCase(word.text, "of")) return word.end;
+    return from;
+}
+
+/// "200-600 mm", "200–600mm", "0.2 to 0.6 m", "(300 mm)"
+fn readRange(text: []const u8, from: usize) ?Range {
+    var pos = skipSpaces(text, from);
+    const bracketed = pos < text.len and text[pos] == '(';
+    if (bracketed) pos = skipSpaces(text, pos + 1);
+
+    const low = readNumber(text, pos) orelse return null;
+    pos = skipSpaces(text, low.end);
+    var high = low;
+    if (separatorEnd(text, pos)) |after| {
+        high = readNumber(text, skipSpaces(text, after)) orelse return null;
+        pos = skipSpaces(text, high.end);
+    }
+
+    const unit = readUnit(text, pos) orelse return null;
+    pos = unit.end;
+    if (bracketed) {
+        pos = skipSpaces(text, pos);
+        if (pos >= text.len or text[pos] != ')') return null;
+        pos += 1;
+    }
+
+    const min = toMm(low.value, unit.scale) orelse return null;
+    const max = toMm(high.value, unit.scale) orelse return null;
+    if (max < min) return null;
+    return Range{ .min = min, .max = max, .end = pos };
+}
+
+const Number = struct {
+    value: f64,
+    end: usize,
+};
+
+fn readNumber(text: []const u8, from: usize) ?Number {
+    var end = from;
+    while (end < text.len and (std.ascii.isDigit(text[end]) or text[end] == '.')) end += 1;
+    if (end == from) return null;
+    const value = std.fmt.parseFloat(f64, text[from..end]) catch return null;
+    return Number{ .value = value, .end = end };
+}
+
+/// End of a range separator: a hyphen, an en dash or "to"
+fn separatorEnd(text: []const u8, pos: usize) ?usize {
+    const rest = text[pos..];
+    if (std.mem.startsWith(u8, rest, "-")) return pos + 1;
+    if (std.mem.startsWith(u8, rest, "\u{2013}")) return pos + "\u{2013}".len;
+    if (rest.len > 2 and std.ascii.eqlIgnoreCase(rest[0..2], "to") and rest[2] == ' ') return pos + 2;
+    return null;
+}
+
+const Unit = struct {
+    scale: f64,
+    end: usize,
+};
+
+fn readUnit(text: []const u8, pos: usize) ?Unit {
+    const units = [_]struct { symbol: []const u8, scale: f64 }{
+        .{ .symbol = "mm", .scale = 1 },
+        .{ .symbol = "cm", .scale = 10 },
+        .{ .symbol = "m", .scale = 1000 },
+    };
+    for (units) |unit| {
+        const end = pos + unit.symbol.len;
+        if (end > text.len or !std.ascii.eqlIgnoreCase(text[pos..end], unit.symbol)) continue;
+        if (end < text.len and std.ascii.isAlphabetic(text[end])) continue;
+        return Unit{ .scale = unit.scale, .end = end };
+    }
+    return null;
+}
+
+fn toMm(value: f64, scale: f64) ?u32 {
+    const mm = @round(value * scale);
+    if (mm > std.math.maxInt(u32)) return null;
+    const result: u32 = @intFromFloat(mm);
+    return result;
+}
+
+fn skipSpaces(text: []const u8, from: usize) usize {
+    var pos = from;
+    while (pos < text.len and (text[pos] == ' ' or text[pos] == '\t')) pos += 1;
+    return pos;
+}
+
+test "find spacing terms" {
+    const text = "Strong grey SANDSTONE with very closely spaced subvertical joints";
+    const match = find(text).?;
+    try std.testing.expectEqual(SpacingTerm.very_close, match.spacing.term.?);
+    try std.testing.expectEqual(@as(u32, 20), match.spacing.min_mm);
+    try std.testing.expectEqual(@as(u32, 60), match.spacing.max_mm.?);
+    try std.testing.expectEqualStrings("very closely spaced", text[match.start..match.end]);
+
+    const wide = find("Weak CHALK, fractures widely spaced").?;
+    try std.testing.expectEqual(SpacingTerm.wide, wide.spacing.term.?);
+
+    const very_wide = find("Massive GRANITE, joints very widely spaced").?;
+    try std.testing.expectEqual(SpacingTerm.very_wide, very_wide.spacing.term.?);
+    try std.testing.expectEqual(@as(u32, 6000), very_wide.spacing.max_mm.?);
+
+    const extremely_wide = find("Massive GRANITE, joints extremely widely spaced").?;
+    try std.testing.expectEqual(SpacingTerm.extremely_wide, extremely_wide.spacing.term.?);
+    try std.testing.expectEqual(@as(u32, 6000), extremely_wide.spacing.min_mm);
+    try std.testing.expect(extremely_wide.spacing.max_mm == null);
+    try std.testing.expectEqual(SpacingTerm.extremely_wide, SpacingTerm.fromMm(8000, null).?);
+
+    try std.testing.expect(find("Medium dense SAND") == null);
+    try std.testing.expect(find("Firm CLAY, spaced out") == null);
+}
+
+test "find stated spacing ranges" {
+    const text = "MUDSTONE, fissures spaced 200\u{2013}600mm";
+    const match = find(text).?;
+    try std.testing.expectEqual(@as(u32, 200), match.spacing.min_mm);
+    try std.testing.expectEqual(@as(u32, 600), match.spacing.max_mm.?);
+    try std.testing.expectEqual(SpacingTerm.medium, match.spacing.term.?);
+    try std.testing.expectEqualStrings("spaced 200\u{2013}600mm", text[match.start..match.end]);
+
+    const metres = find("joint spacing of 0.2 to 0.8 m").?;
+    try std.testing.expectEqual(@as(u32, 200), metres.spacing.min_mm);
+    try std.testing.expectEqual(@as(u32, 800), metres.spacing.max_mm.?);
+    try std.testing.expect(metres.spacing.term == null);
+
+    const both = find("closely spaced (60-150 mm) joints").?;
+    try std.testing.expectEqual(SpacingTerm.close, both.spacing.term.?);
+    try std.testing.expectEqual(@as(u32, 150), both.spacing.max_mm.?);
+    try std.testing.expectEqual(@as(u8, 8), both.spacing.rmrRating());
+}
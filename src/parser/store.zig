@@ -0,0 +1,574 @@
+const std = @import("std");
+const types = @import("types.zig");
+
+const GeologicalDescription = types.GeologicalDescription;
+const Sha256 = std.crypto.hash.sha2.Sha256;
+
+const index_file = "results.jsonl";
+const max_index_size = 1 << 30;
+const max_entry_size = 1 << 24;
+
+/// Hex SHA-256 of a normalised description
+pub const Hash = [Sha256.digest_length * 2]u8;
+
+/// One distinct description and when it was seen
+pub const Entry = struct {
+    hash: []const u8,
+    /// Normalised text the hash was taken from
+    description: []const u8,
+    /// Parse result as JSON
+    result: []const u8,
+    first_seen: i64,
+    last_seen: i64,
+    count: u64,
+};
+
+pub const StoreStats = struct {
+    /// Distinct normalised descriptions
+    unique: usize = 0,
+    /// Every description recorded, repeats included
+    total: u64 = 0,
+
+    pub fn duplicates(self: StoreStats) u64 {
+        return self.total - self.unique;
+    }
+};
+
+/// Lower-case with runs of whitespace collapsed, so trivially different
+/// spellings of one description share a hash. Caller owns the result.
+pub fn normalize(allocator: std.mem.Allocator, text: []const u8) ![]u8 {
+    var out = std.ArrayList(u8).init(allocator);
+    errdefer out.deinit();
+
+    var words = std.mem.tokenizeAny(u8, text, " \t\r\n");
+    while (words.next()) |word| {
+        if (out.items.len > 0) try out.append(' ');
+        for (word) |ch| try out.append(std.ascii.toLower(ch));
+    }
+    return out.toOwnedSlice();
+}
+
+pub fn hashNormalized(normalized: []const u8) Hash {
+    var digest: [Sha256.digest_length]u8 = undefined;
+    Sha256.hash(normalized, &digest, .{});
+    return std.fmt.bytesToHex(digest, .lower);
+}
+
+/// A small on-disk store of parse results keyed by normalised description
+/// hash, so growing archives can be processed incrementally: skip what is
+/// already `contains`, `record` the rest, then `flush`.
+pub const ResultStore = struct {
+    allocator: std.mem.Allocator,
+    dir: std.fs.Dir,
+    entries: std.StringHashMap(Entry),
+
+    /// Open the store in `path`, creating the directory if needed
+    pub fn open(allocator: std.mem.Allocator, path: []const u8) !ResultStore {
+        var dir = try std.fs.cwd().makeOpenPath(path, .{});
+        errdefer dir.close();
+
+        var store = ResultStore{
+            .allocator = allocator,
+            .dir = dir,
+            .entries = std.StringHashMap(Entry).init(allocator),
+        };
+        errdefer store.freeEntries();
+
+        const data = dir.readFileAlloc(allocator, index_file, max_index_size) catch |err| switch (err) {
+            error.FileNotFound => return store,
+            else => return err,
+        };
+        defer allocator.free(data);
+
+        var lines = std.mem.tokenizeScalar(u8, data, '\n');
+        while (lines.next()) |line| {
+            const parsed = std.json.parseFromSlice(Entry, allocator, line, .{}) catch return error.CorruptStore;
+            defer parsed.deinit();
+            const entry = try store.dupeEntry(parsed.value);
+            store.entries.put(entry.hash, entry) catch |err| {
+                store.freeEntry(entry);
+                return err;
+            };
+        }
+        return store;
+    }
+
+    /// Close without writing; call `flush` first to keep changes
+    pub fn close(self: *ResultStore) void {
+        self.freeEntries();
+        self.dir.close();
+    }
+
+    pub fn get(self: *const ResultStore, raw_description: []const u8) !?Entry {
+        const normalized = try normalize(self.allocator, raw_description);
+        defer self.allocator.free(normalized);
+        const hash = hashNormalized(normalized);
+        return self.entries.get(&hash);
+    }
+
+    pub fn contains(self: *const ResultStore, raw_description: []const u8) !bool {
+        return (try self.get(raw_description)) != null;
+    }
+
+    /// Record a parse result now. Returns true if the description is new.
+    pub fn record(self: *ResultStore, desc: *const GeologicalDescription) !bool {
+        return self.recordAt(desc, std.time.timestamp());
+    }
+
+    /// Record a parse result seen at `timestamp` (Unix seconds). A repeat
+    /// bumps the count and last-seen time and keeps the first result.
+    pub fn recordAt(self: *ResultStore, desc: *const GeologicalDescription, timestamp: i64) !bool {
+        const normalized = try normalize(self.allocator, desc.raw_description);
+        defer self.allocator.free(normalized);
+        const hash = hashNormalized(normalized);
+
+        if (self.entries.getPtr(&hash)) |entry| {
+            entry.count += 1;
+            entry.first_seen = @min(entry.first_seen, timestamp);
+            entry.last_seen = @max(entry.last_seen, timestamp);
+            return false;
+        }
+
+        const result = try desc.toJson(self.allocator);
+        defer self.allocator.free(result);
+        const entry = try self.dupeEntry(.{
+            .hash = &hash,
+            .description = normalized,
+            .result = result,
+            .first_seen = timestamp,
+            .last_seen = timestamp,
+            .count = 1,
+        });
+        self.entries.put(entry.hash, entry) catch |err| {
+            self.freeEntry(entry);
+            return err;
+        };
+        return true;
+    }
+
+    pub fn stats(self: *const ResultStore) StoreStats {
+        var result = StoreStats{ .unique = self.entries.count() };
+        var it = self.entries.valueIterator();
+        while (it.next()) |entry| result.total += entry.count;
+        return result;
+    }
+
+    /// Write every entry to disk, replacing the previous index atomically
+    pub fn flush(self: *ResultStore) !void {
+        const sorted = try self.allocator.alloc(Entry, self.entries.count());
+        defer self.allocator.free(sorted);
+        var it = self.entries.valueIterator();
+        var i: usize = 0;
+        while (it.next()) |entry| : (i += 1) sorted[i] = entry.*;
+        std.mem.sort(Entry, sorted, {}, hashLessThan);
+
+        var atomic = try self.dir.atomicFile(index_file, .{});
+        defer atomic.deinit();
+        var buffered = std.io.bufferedWriter(atomic.file.writer());
+        const writer = buffered.writer();
+        for (sorted) |entry| {
+            try std.json.stringify(entry, .{}, writer);
+            try writer.writeByte('\n');
+        }
+        try buffered.flush();
+        try atomic.finish();
+    }
+
+    fn hashLessThan(_: void, a: Entry, b: Entry) bool {
+        return std.mem.lessThan(u8, a.hash, b.hash);
+    }
+
+    fn dupeEntry(self: *ResultStore, entry: Entry) !Entry {
+        const hash = try self.allocator.dupe(u8, entry.hash);
+        errdefer self.allocator.free(hash);
+        const description = try self.allocator.dupe(u8, entry.description);
+        errdefer self.allocator.free(description);
+        const result = try self.allocator.dupe(u8, entry.result);
+        return Entry{
+            .hash = hash,
+            .description = description,
+            .result = result,
+            .first_seen = entry.first_seen,
+            .last_seen = entry.last_seen,
+            .count = entry.count,
+        };
+    }
+
+    fn freeEntry(self: *ResultStore, entry: Entry) void {
+        self.allocator.free(entry.hash);
+        self.allocator.free(entry.description);
+        self.allocator.free(entry.result);
+    }
+
+    fn freeEntries(self: *ResultStore) void {
+        var it = self.entries.valueIterator();
+        while (it.next()) |entry| self.freeEntry(entry.*);
+        self.entries.deinit();
+    }
+};
+
+/// A result field to match, by JSON key and value ("primary_soil_type",
+/// "clay")
+pub const FieldMatch = struct {
+    key: []const u8,
+    value: []const u8,
+};
+
+/// Which entries a `ResultSet` yields. Every condition set must hold.
+pub const Filter = struct {
+    /// Text in the normalised (lower-case) description
+    description_contains: ?[]const u8 = null,
+    fields: []const FieldMatch = &.{},
+    min_count: u64 = 0,
+    /// Last seen at or after this time (Unix seconds)
+    seen_since: ?i64 = null,
+    /// First seen before this time (Unix seconds)
+    seen_before: ?i64 = null,
+
+    pub fn matches(self: Filter, entry: Entry) bool {
+        if (entry.count < self.min_count) return false;
+        if (self.seen_since) |since| {
+            if (entry.last_seen < since) return false;
+        }
+        if (self.seen_before) |before| {
+            if (entry.first_seen >= before) return false;
+        }
+        if (self.description_contains) |text| {
+            if (std.mem.indexOf(u8, entry.description, text) == null) return false;
+        }
+        for (self.fields) |field| {
+            if (!hasJsonField(entry.result, field)) return false;
+        }
+        return true;
+    }
+};
+
+/// `"key":"value"` or `"key":value` in compact JSON
+fn hasJsonField(json: []const u8, field: FieldMatch) bool {
+    var pos: usize = 0;
+    while (std.mem.indexOfPos(u8, json, pos, field.key)) |start| {
+        pos = start + 1;
+        const end = start + field.key.len;
+        if (start == 0 or json[start - 1] != '"') continue;
+        if (!std.mem.startsWith(u8, json[end..], "\":")) continue;
+
+        var rest = json[end + 2 ..];
+        if (std.mem.startsWith(u8, rest, "\"")) {
+            rest = rest[1..];
+            if (std.mem.startsWith(u8, rest, field.value) and rest.len > field.value.len and rest[field.value.len] == '"') return true;
+        } else {
+            const len = std.mem.indexOfAny(u8, rest, ",}") orelse rest.len;
+            if (std.mem.eql(u8, rest[0..len], field.value)) return true;
+        }
+    }
+    return false;
+}
+
+/// Where reading resumes: after the entry with hash `after`, or from the
+/// first entry. The index is sorted by hash, so a cursor stays valid when
+/// the store is flushed between pages; entries added since are read if
+/// their hash sorts after it.
+pub const Cursor = struct {
+    after: ?Hash = null,
+
+    pub const start = Cursor{};
+};
+
+/// One page of a `ResultSet`. Entries belong to the page.
+pub const Page = struct {
+    arena: std.heap.ArenaAllocator,
+    entries: []Entry,
+    /// Where the next page starts, or null after the last entry. The next
+    /// page can be empty when the last entries did not match.
+    next: ?Cursor,
+
+    pub fn deinit(self: *Page) void {
+        self.arena.deinit();
+    }
+};
+
+/// Filtered, paged reading of a store as last flushed. Entries are read
+/// from disk a line at a time, so only the requested page is ever held in
+/// memory, however large the store. Order is the index order (by hash).
+/// A result set reads the index as it was when opened; reopen it after a
+/// flush and carry on from the last page's cursor. Pages and iterators
+/// share the open file, so use one at a time.
+pub const ResultSet = struct {
+    /// Null for a store that has never been flushed
+    file: ?std.fs.File,
+    filter: Filter,
+
+    pub fn open(path: []const u8, filter: Filter) !ResultSet {
+        var dir = try std.fs.cwd().openDir(path, .{});
+        defer dir.close();
+        const file = dir.openFile(index_file, .{}) catch |err| switch (err) {
+            error.FileNotFound => return ResultSet{ .file = null, .filter = filter },
+            else => return err,
+        };
+        return ResultSet{ .file = file, .filter = filter };
+    }
+
+    pub fn close(self: *ResultSet) void {
+        if (self.file) |file| file.close();
+    }
+
+    /// Up to `limit` matching entries from `cursor` on
+    pub fn page(self: *const ResultSet, allocator: std.mem.Allocator, cursor: Cursor, limit: usize) !Page {
+        var result = Page{ .arena = std.heap.ArenaAllocator.init(allocator), .entries = &.{}, .next = null };
+        errdefer result.arena.deinit();
+        const arena = result.arena.allocator();
+
+        var entries = std.ArrayList(Entry).init(arena);
+        var it = try self.iterator(allocator, cursor);
+        defer it.deinit();
+        while (entries.items.len < limit) {
+            const entry = try it.next() orelse break;
+            const hash = try arena.dupe(u8, entry.hash);
+            const description = try arena.dupe(u8, entry.description);
+            const json = try arena.dupe(u8, entry.result);
+            try entries.append(.{
+                .hash = hash,
+                .description = description,
+                .result = json,
+                .first_seen = entry.first_seen,
+                .last_seen = entry.last_seen,
+                .count = entry.count,
+            });
+        }
+        result.entries = try entries.toOwnedSlice();
+        result.next = if (it.done) null else it.cursor;
+        return result;
+    }
+
+    /// Matching entries one at a time from `cursor`. Lines before the
+    /// cursor are skipped by their leading hash without being parsed.
+    pub fn iterator(self: *const ResultSet, allocator: std.mem.Allocator, cursor: Cursor) !Iterator {
+        const file = self.file orelse return Iterator{ .reader = null, .arena = std.heap.ArenaAllocator.init(allocator), .filter = self.filter, .cursor = cursor, .done = true };
+        try file.seekTo(0);
+        return Iterator{
+            .reader = std.io.bufferedReader(file.reader()),
+            .arena = std.heap.ArenaAllocator.init(allocator),
+            .filter = self.filter,
+            .cursor = cursor,
+        };
+    }
+
+    /// Matching entries in the whole store; reads every line
+    pub fn count(self: *const ResultSet, allocator: std.mem.Allocator) !usize {
+        var it = try self.iterator(allocator, .start);
+        defer it.deinit();
+        var total: usize = 0;
+        while (try it.next()) |_| total += 1;
+        return total;
+    }
+
+    pub const Iterator = struct {
+        reader: ?std.io.BufferedReader(4096, std.fs.File.Reader),
+        /// Holds the current entry; reset on each `next`
+        arena: std.heap.ArenaAllocator,
+        filter: Filter,
+        /// After the last line read
+        cursor: Cursor,
+        done: bool = false,
+
+        pub fn deinit(self: *Iterator) void {
+            self.arena.deinit();
+        }
+
+        /// The next matching entry, valid until the following call
+        pub fn next(self: *Iterator) !?Entry {
+            const reader = if (self.reader) |*buffered| buffered.reader() else return null;
+            while (!self.done) {
+                _ = self.arena.reset(.retain_capacity);
+                const arena = self.arena.allocator();
+                const line = try reader.readUntilDelimiterOrEofAlloc(arena, '\n', max_entry_size) orelse {
+                    self.done = true;
+                    break;
+                };
+                if (line.len == 0) continue;
+
+                const hash = lineHash(line) orelse return error.CorruptStore;
+                if (self.cursor.after) |after| {
+                    if (!std.mem.lessThan(u8, &after, hash)) continue;
+                }
+                self.cursor.after = hash.*;
+
+                const entry = std.json.parseFromSliceLeaky(Entry, arena, line, .{}) catch return error.CorruptStore;
+                if (self.filter.matches(entry)) return entry;
+            }
+            return null;
+        }
+    };
+};
+
+/// The hash an index line starts with, as `flush` writes it
+fn lineHash(line: []const u8) ?*const Hash {
+    const prefix = "{\"hash\":\"";
+    if (!std.mem.startsWith(u8, line, prefix) or line.len < prefix.len + @sizeOf(Hash)) return null;
+    return line[prefix.len..][0..@sizeOf(Hash)];
+}
+
+test "page through a filtered result set" {
+    const allocator = std.testing.allocator;
+
+    var tmp = std.testing.tmpDir(.{});
+    defer tmp.cleanup();
+    const path = try tmp.dir.realpathAlloc(allocator, ".");
+    defer allocator.free(path);
+
+    const descriptions = [_]GeologicalDescription{
+        .{ .raw_description = "Firm CLAY", .material_type = .soil, .consistency = .firm, .primary_soil_type = .clay },
+        .{ .raw_description = "Soft CLAY", .material_type = .soil, .consistency = .soft, .primary_soil_type = .clay },
+        .{ .raw_description = "Stiff CLAY", .material_type = .soil, .consistency = .stiff, .primary_soil_type = .clay },
+        .{ .raw_description = "Dense SAND", .material_type = .soil, .density = .dense, .primary_soil_type = .sand },
+        .{ .raw_description = "Loose SAND", .material_type = .soil, .density = .loose, .primary_soil_type = .sand },
+    };
+    {
+        var store = try ResultStore.open(allocator, path);
+        defer store.close();
+        for (&descriptions, 0..) |*desc, i| _ = try store.recordAt(desc, @intCast(i * 100));
+        _ = try store.recordAt(&descriptions[0], 900);
+        try store.flush();
+    }
+
+    var clays = try ResultSet.open(path, .{ .fields = &.{.{ .key = "primary_soil_type", .value = "clay" }} });
+    defer clays.close();
+    try std.testing.expectEqual(@as(usize, 3), try clays.count(allocator));
+
+    var seen: usize = 0;
+    var cursor: ?Cursor = .start;
+    while (cursor) |from| {
+        var page = try clays.page(allocator, from, 2);
+        defer page.deinit();
+        try std.testing.expect(page.entries.len <= 2);
+        for (page.entries) |entry| try std.testing.expect(std.mem.endsWith(u8, entry.description, "clay"));
+        seen += page.entries.len;
+        cursor = page.next;
+    }
+    try std.testing.expectEqual(@as(usize, 3), seen);
+
+    var repeated = try ResultSet.open(path, .{ .min_count = 2 });
+    defer repeated.close();
+    var first = try repeated.page(allocator, .start, 10);
+    defer first.deinit();
+    try std.testing.expectEqual(@as(usize, 1), first.entries.len);
+    try std.testing.expectEqualStrings("firm clay", first.entries[0].description);
+    try std.testing.expect(first.next == null);
+
+    var recent = try ResultSet.open(path, .{ .seen_since = 300, .description_contains = "sand" });
+    defer recent.close();
+    try std.testing.expectEqual(@as(usize, 2), try recent.count(allocator));
+}
+
+test "keep paging after a flush adds entries" {
+    const allocator = std.testing.allocator;
+
+    var tmp = std.testing.tmpDir(.{});
+    defer tmp.cleanup();
+    const path = try tmp.dir.realpathAlloc(allocator, ".");
+    defer allocator.free(path);
+
+    const before = [_]GeologicalDescription{
+        .{ .raw_description = "Firm CLAY", .material_type = .soil },
+        .{ .raw_description = "Soft CLAY", .material_type = .soil },
+        .{ .raw_description = "Stiff CLAY", .material_type = .soil },
+        .{ .raw_description = "Dense SAND", .material_type = .soil },
+        .{ .raw_description = "Loose SAND", .material_type = .soil },
+    };
+    const added = [_]GeologicalDescription{
+        .{ .raw_description = "Hard CLAY", .material_type = .soil },
+        .{ .raw_description = "Very soft CLAY", .material_type = .soil },
+        .{ .raw_description = "Medium dense SAND", .material_type = .soil },
+        .{ .raw_description = "Very dense SAND", .material_type = .soil },
+    };
+
+    var store = try ResultStore.open(allocator, path);
+    defer store.close();
+    for (&before) |*desc| _ = try store.recordAt(desc, 100);
+    try store.flush();
+
+    var last: Hash = undefined;
+    var seen: usize = 0;
+    var originals: usize = 0;
+    var cursor: ?Cursor = .start;
+    var pages: usize = 0;
+    while (cursor) |from| : (pages += 1) {
+        // Flush new entries, some sorting before the cursor, after the first page
+        if (pages == 1) {
+            for (&added) |*desc| _ = try store.recordAt(desc, 200);
+            try store.flush();
+        }
+
+        var results = try ResultSet.open(path, .{});
+        defer results.close();
+        var page = try results.page(allocator, from, 2);
+        defer page.deinit();
+        for (page.entries) |entry| {
+            if (seen > 0) try std.testing.expect(std.mem.lessThan(u8, &last, entry.hash));
+            @memcpy(&last, entry.hash);
+            seen += 1;
+            if (entry.first_seen == 100) originals += 1;
+        }
+        cursor = page.next;
+    }
+    // Every original entry exactly once, none repeated or skipped
+    try std.testing.expectEqual(before.len, originals);
+}
+
+test "empty result set before the first flush" {
+    const allocator = std.testing.allocator;
+
+    var tmp = std.testing.tmpDir(.{});
+    defer tmp.cleanup();
+    const path = try tmp.dir.realpathAlloc(allocator, ".");
+    defer allocator.free(path);
+
+    var results = try ResultSet.open(path, .{});
+    defer results.close();
+    var page = try results.page(allocator, .start, 10);
+    defer page.deinit();
+    try std.testing.expectEqual(@as(usize, 0), page.entries.len);
+    try std.testing.expect(page.next == null);
+}
+
+test "match compact JSON fields" {
+    const json = "{\"consistency\":\"firm\",\"is_valid\":true,\"primary_soil_type\":\"clay\"}";
+    try std.testing.expect(hasJsonField(json, .{ .key = "consistency", .value = "firm" }));
+    try std.testing.expect(hasJsonField(json, .{ .key = "is_valid", .value = "true" }));
+    try std.testing.expect(!hasJsonField(json, .{ .key = "consistency", .value = "fir" }));
+    try std.testing.expect(!hasJsonField(json, .{ .key = "soil_type", .value = "clay" }));
+}
+
+test "result store dedups normalised descriptions across reopen" {
+    const allocator = std.testing.allocator;
+
+    var tmp = std.testing.tmpDir(.{});
+    defer tmp.cleanup();
+    const path = try tmp.dir.realpathAlloc(allocator, ".");
+    defer allocator.free(path);
+
+    const first = GeologicalDescription{ .raw_description = "Firm  brown CLAY", .material_type = .soil, .consistency = .firm };
+    const repeat = GeologicalDescription{ .raw_description = "firm brown clay ", .material_type = .soil, .consistency = .firm };
+
+    {
+        var store = try ResultStore.open(allocator, path);
+        defer store.close();
+        try std.testing.expect(try store.recordAt(&first, 100));
+        try std.testing.expect(!try store.recordAt(&repeat, 250));
+        try store.flush();
+    }
+
+    var store = try ResultStore.open(allocator, path);
+    defer store.close();
+
+    const entry = (try store.get("FIRM BROWN CLAY")).?;
+    try std.testing.expectEqualStrings("firm brown clay", entry.description);
+    try std.testing.expectEqual(@as(u64, 2), entry.count);
+    try std.testing.expectEqual(@as(i64, 100), entry.first_seen);
+    try std.testing.expectEqual(@as(i64, 250), entry.last_seen);
+    try std.testing.expect(!try store.contains("Stiff CLAY"));
+
+    const totals = store.stats();
+    try std.testing.expectEqual(@as(usize, 1), totals.unique);
+    try std.testing.expectEqual(@as(u64, 1), totals.duplicates());
+}
@@ -0,0 +1,161 @@
+const std = @import("std");
+const types = @import("types.zig");
+const scan = @import("scan.zig");
+
+const Word = scan.Word;
+
+/// Rank of a lithostratigraphic unit, from the last word of its name
+pub const Rank = enum {
+    supergroup,
+    group,
+    subgroup,
+    formation,
+    member,
+    bed,
+
+    pub fn fromString(str: []const u8) ?Rank {
+        if (std.ascii.eqlIgnoreCase(str, "supergroup")) return .supergroup;
+        if (std.ascii.eqlIgnoreCase(str, "group")) return .group;
+        if (std.ascii.eqlIgnoreCase(str, "subgroup")) return .subgroup;
+        if (std.ascii.eqlIgnoreCase(str, "formation")) return .formation;
+        if (std.ascii.eqlIgnoreCase(str, "member")) return .member;
+        if (std.ascii.eqlIgnoreCase(str, "bed") or std.ascii.eqlIgnoreCase(str, "beds")) return .bed;
+        return null;
+    }
+
+    pub fn toString(self: Rank) []const u8 {
+        return @tagName(self);
+    }
+};
+
+/// A unit name written in a description, a slice of the searched text
+pub const Match = struct {
+    name: []const u8,
+    rank: Rank,
+    start: usize,
+    end: usize,
+};
+
+const max_name_words = 6;
+
+/// The rank of a unit name ("MERCIA MUDSTONE GROUP" is a group), or null
+/// for deposits such as "GLACIAL TILL" that have none
+pub fn rankOf(name: []const u8) ?Rank {
+    const trimmed = std.mem.trimRight(u8, name, " \t.");
+    const last_space = std.mem.lastIndexOfScalar(u8, trimmed, ' ') orelse return null;
+    return Rank.fromString(trimmed[last_space + 1 ..]);
+}
+
+/// Finds a unit name outside brackets: words in capitals ending in a rank
+/// ("LONDON CLAY FORMATION"), or in title case ("Mercia Mudstone Group").
+/// The name runs back from the rank over words of the same case, up to
+/// punctuation or a lower-case word. A soil or rock name at its start is
+/// taken as the end of the description ("stiff grey CLAY LONDON CLAY
+/// FORMATION") unless nothing else would be left.
+pub fn find(text: []const u8) ?Match {
+    var words: [max_name_words + 1]Word = undefined;
+    var len: usize = 0;
+
+    var pos: usize = 0;
+    while (scan.next(text, pos, .terms)) |word| {
+        // Words joined only by spaces continue the run
+        if (len > 0 and !onlySpaces(text[words[len - 1].end..word.start])) len = 0;
+        pos = word.end;
+
+        if (len == words.len) {
+            std.mem.copyForwards(Word, words[0 .. len - 1], words[1..len]);
+            len -= 1;
+        }
+        words[len] = word;
+        len += 1;
+
+        const rank = Rank.fromString(word.text) orelse continue;
+        const upper = isAllUpper(word.text);
+        if (!upper and !std.ascii.isUpper(word.text[0])) continue;
+
+        // Walk back over the name words written like the rank
+        var first = len - 1;
+        while (first > 0) {
+            const previous = words[first - 1].text;
+            const same_case = if (upper) isAllUpper(previous) else std.ascii.isUpper(previous[0]) and !isAllUpper(previous);
+            if (!same_case) break;
+            first -= 1;
+        }
+        if (first + 1 < len - 1 and isLithology(words[first].text)) first += 1;
+        if (first == len - 1) continue;
+
+        return Match{
+            .name = text[words[first].start..word.end],
+            .rank = rank,
+            .start = words[first].start,
+            .end = word.end,
+        };
+    }
+    return null;
+}
+
+/// Where to start blanking `match` out of `text` so its words are not read
+/// as the description's own. A soil or rock name opening the unit name is
+/// kept when the text before it names none, since the description needs it
+/// ("Weak white CHALK GROUP" is still a chalk).
+pub fn blankFrom(text: []const u8, match: Match) usize {
+    const first = scan.next(text, match.start, .terms) orelse return match.start;
+    if (first.end >= match.end or !isLithology(first.text)) return match.start;
+    var pos: usize = 0;
+    while (scan.next(text[0..match.start], pos, .terms)) |word| {
+        pos = word.end;
+        if (isLithology(word.text)) return match.start;
+    }
+    return first.end;
+}
+
+fn isLithology(text: []const u8) bool {
+    return types.SoilType.fromString(text) != null or types.RockType.fromString(text) != null;
+}
+
+fn isAllUpper(text: []const u8) bool {
+    var letters = false;
+    for (text) |c| {
+        if (std.ascii.isLower(c)) return false;
+        if (std.ascii.isUpper(c)) letters = true;
+    }
+    return letters;
+}
+
+fn onlySpaces(text: []const u8) bool {
+    for (text) |c| {
+        if (c != ' ' and c != '\t') return false;
+    }
+    return true;
+}
+
+test "find unit names outside brackets" {
+    const after = "Stiff fissured grey CLAY. LONDON CLAY FORMATION";
+    const formation = find(after).?;
+    try std.testing.expectEqualStrings("LONDON CLAY FORMATION", formation.name);
+    try std.testing.expectEqual(Rank.formation, formation.rank);
+    try std.testing.expectEqualStrings(formation.name, after[formation.start..formation.end]);
+
+    try std.testing.expectEqualStrings("LONDON CLAY FORMATION", find("stiff grey CLAY LONDON CLAY FORMATION").?.name);
+    try std.testing.expectEqualStrings("Mercia Mudstone Group", find("Red MUDSTONE - Mercia Mudstone Group").?.name);
+    try std.testing.expectEqualStrings("CHALK GROUP", find("Weak white CHALK GROUP").?.name);
+
+    try std.testing.expect(find("Firm CLAY (GLACIAL TILL)") == null);
+    try std.testing.expect(find("Formation level reached") == null);
+    try std.testing.expect(find("stiff grey CLAY with a weathered group of cobbles") == null);
+}
+
+test "keep the only lithology when blanking a unit name" {
+    const chalk = "Weak white CHALK GROUP";
+    try std.testing.expectEqualStrings(" GROUP", chalk[blankFrom(chalk, find(chalk).?)..]);
+
+    const clay = "Stiff grey CLAY. LONDON CLAY FORMATION";
+    const formation = find(clay).?;
+    try std.testing.expectEqual(formation.start, blankFrom(clay, formation));
+}
+
+test "rank of a unit name" {
+    try std.testing.expectEqual(Rank.group, rankOf("MERCIA MUDSTONE GROUP").?);
+    try std.testing.expectEqual(Rank.bed, rankOf("Woolwich and Reading Beds").?);
+    try std.testing.expect(rankOf("GLACIAL TILL") == null);
+}
@@ -42,23 +42,138 @@ pub const StrengthRange = struct {
     }
 };
 
+/// Whether a strength range was correlated from the description or tested
+pub const StrengthProvenance = types.Source;
+
+/// How a strength range was changed or flagged because the soil is wet or
+/// saturated
+pub const GroundwaterAdjustment = enum {
+    /// Measured SPT N above 15 in wet or saturated fine or silty sand reduced
+    /// for dilatancy (Terzaghi and Peck): N' = 15 + (N - 15) / 2
+    dilatancy,
+    /// Range unchanged, but the correlation assumes a higher effective
+    /// stress than a saturated soil has
+    reduced_effective_stress,
+
+    pub fn toString(self: GroundwaterAdjustment) []const u8 {
+        return @tagName(self);
+    }
+};
+
+/// A lab or in-situ test result, e.g. a hand vane cu or an SPT N-value
+pub const Measurement = struct {
+    parameter_type: StrengthParameterType,
+    value: f32,
+};
+
+/// The same strength expressed in the other family's parameter (UCS for a
+/// hard clay, cu for a very weak rock)
+pub const AlternateStrength = struct {
+    parameter_type: StrengthParameterType,
+    range: StrengthRange,
+    provenance: StrengthProvenance = .inferred,
+};
+
+/// A description at the soil/rock boundary ("hard CLAY", "extremely weak
+/// MUDSTONE"), which may be designed as either, with the parameters for both
+pub const BoundaryAdvisory = struct {
+    /// How the description was read; the other family is the alternative
+    described_as: types.MaterialType,
+    /// Undrained shear strength (kPa) if taken as a hard soil
+    as_soil: StrengthRange,
+    /// Unconfined compressive strength (MPa) if taken as a weak rock
+    as_rock: StrengthRange,
+
+    /// "read as soil; as soil cu 200.0-400.0 kPa, as rock UCS 0.40-0.80 MPa"
+    pub fn format(self: BoundaryAdvisory, comptime _: []const u8, _: std.fmt.FormatOptions, writer: anytype) !void {
+        try writer.print("read as {s}; as soil cu {d:.1}-{d:.1} kPa, as rock UCS {d:.2}-{d:.2} MPa", .{
+            self.described_as.toString(),
+            self.as_soil.lower_bound,
+            self.as_soil.upper_bound,
+            self.as_rock.lower_bound,
+            self.as_rock.upper_bound,
+        });
+    }
+};
+
 pub const StrengthParameters = struct {
     parameter_type: StrengthParameterType,
     range: StrengthRange,
     confidence: f32 = 0.8, // Default confidence level
+    /// Material sits at the soil/rock boundary; both cu and UCS are reported
+    intermediate_geomaterial: bool = false,
+    alternate: ?AlternateStrength = null,
+    provenance: StrengthProvenance = .inferred,
+    /// Set when the range was adjusted or flagged for saturation
+    groundwater_adjustment: ?GroundwaterAdjustment = null,
 
     pub fn toString(self: StrengthParameters, allocator: std.mem.Allocator) ![]u8 {
         const typical = if (self.range.typical_value) |tv| tv else self.range.getMidpoint();
-        return std.fmt.allocPrint(allocator, "{s}: {d:.1}-{d:.1} {s} (typical: {d:.1})", .{
+        const measured = if (self.provenance == .measured) " [measured]" else "";
+        const groundwater = if (self.groundwater_adjustment) |adjustment| switch (adjustment) {
+            .dilatancy => " [corrected for dilatancy]",
+            .reduced_effective_stress => " [saturated]",
+        } else "";
+        const alternate = self.alternate orelse {
+            return std.fmt.allocPrint(allocator, "{s}: {d:.1}-{d:.1} {s} (typical: {d:.1}){s}{s}", .{
+                self.parameter_type.toString(),
+                self.range.lower_bound,
+                self.range.upper_bound,
+                self.parameter_type.getUnits(),
+                typical,
+                measured,
+                groundwater,
+            });
+        };
+
+        return std.fmt.allocPrint(allocator, "{s}: {d:.1}-{d:.1} {s} (typical: {d:.1}){s}{s} / {s}: {d:.2}-{d:.2} {s}{s} [intermediate geomaterial]", .{
             self.parameter_type.toString(),
             self.range.lower_bound,
             self.range.upper_bound,
             self.parameter_type.getUnits(),
             typical,
+            measured,
+            groundwater,
+            alternate.parameter_type.toString(),
+            alternate.range.lower_bound,
+            alternate.range.upper_bound,
+            alternate.parameter_type.getUnits(),
+            if (alternate.provenance == .measured) " [measured]" else "",
         });
     }
 };
 
+/// UCS (MPa) from cu (kPa), taking qu = 2cu
+fn cuToUcs(range: StrengthRange) StrengthRange {
+    return StrengthRange{
+        .lower_bound = range.lower_bound * 2.0 / 1000.0,
+        .upper_bound = range.upper_bound * 2.0 / 1000.0,
+        .typical_value = if (range.typical_value) |tv| tv * 2.0 / 1000.0 else null,
+    };
+}
+
+/// cu (kPa) from UCS (MPa), taking cu = qu/2
+fn ucsToCu(range: StrengthRange) StrengthRange {
+    return StrengthRange{
+        .lower_bound = range.lower_bound * 1000.0 / 2.0,
+        .upper_bound = range.upper_bound * 1000.0 / 2.0,
+        .typical_value = if (range.typical_value) |tv| tv * 1000.0 / 2.0 else null,
+    };
+}
+
+/// SPT N corrected for dilatancy in wet or saturated fine or silty sand
+fn dilatancyCorrected(n: f32) f32 {
+    return if (n > 15) 15 + (n - 15) / 2 else n;
+}
+
+/// Very stiff and hard clays overlap the strength of very weak rock
+fn isIntermediateConsistency(consistency: Consistency) bool {
+    return switch (consistency) {
+        .very_stiff, .hard, .stiff_to_very_stiff => true,
+        else => false,
+    };
+}
+
 // Database for cohesive soil strength parameters (cu in kPa) based on consistency
 const COHESIVE_STRENGTH_DB = std.EnumMap(Consistency, StrengthRange).init(.{
     .very_soft = StrengthRange{ .lower_bound = 0, .upper_bound = 12, .typical_value = 6 },
@@ -95,6 +210,21 @@ const ROCK_STRENGTH_DB = std.EnumMap(RockStrength, StrengthRange).init(.{
     .extremely_strong = StrengthRange{ .lower_bound = 200.0, .upper_bound = 500.0, .typical_value = 300.0 },
 });
 
+/// Typical cu (kPa) for a consistency; see `Consistency.cuRange`
+pub fn cuRange(consistency: Consistency) StrengthRange {
+    return COHESIVE_STRENGTH_DB.get(consistency).?;
+}
+
+/// Typical SPT N-value for a density; see `Density.sptRange`
+pub fn sptRange(density: Density) StrengthRange {
+    return GRANULAR_STRENGTH_DB.get(density).?;
+}
+
+/// Typical UCS (MPa) for a rock strength; see `RockStrength.ucsRange`
+pub fn ucsRange(strength: RockStrength) StrengthRange {
+    return ROCK_STRENGTH_DB.get(strength).?;
+}
+
 pub const StrengthDatabase = struct {
     pub fn getStrengthParameters(
         material_type: types.MaterialType,
@@ -109,25 +239,27 @@ pub const StrengthDatabase = struct {
                 if (primary_soil_type) |soil_type| {
                     if (soil_type.isCohesive()) {
                         if (consistency) |c| {
-                            if (COHESIVE_STRENGTH_DB.get(c)) |range| {
-                                return StrengthParameters{
-                                    .parameter_type = .undrained_shear_strength,
-                                    .range = range,
-                                    .confidence = 0.8,
-                                };
+                            const range = c.cuRange();
+                            var params = StrengthParameters{
+                                .parameter_type = .undrained_shear_strength,
+                                .range = range,
+                                .confidence = 0.8,
+                            };
+                            if (isIntermediateConsistency(c)) {
+                                params.intermediate_geomaterial = true;
+                                params.alternate = .{ .parameter_type = .ucs, .range = cuToUcs(range) };
                             }
+                            return params;
                         }
                     }
                     // For granular soils, use SPT N-value
                     else if (soil_type.isGranular()) {
                         if (density) |d| {
-                            if (GRANULAR_STRENGTH_DB.get(d)) |range| {
-                                return StrengthParameters{
-                                    .parameter_type = .spt_n_value,
-                                    .range = range,
-                                    .confidence = 0.75, // Slightly lower confidence for SPT correlations
-                                };
-                            }
+                            return StrengthParameters{
+                                .parameter_type = .spt_n_value,
+                                .range = d.sptRange(),
+                                .confidence = 0.75, // Slightly lower confidence for SPT correlations
+                            };
                         }
                     }
                 }
@@ -135,19 +267,135 @@ pub const StrengthDatabase = struct {
             .rock => {
                 // For rock, use unconfined compressive strength
                 if (rock_strength) |rs| {
-                    if (ROCK_STRENGTH_DB.get(rs)) |range| {
-                        return StrengthParameters{
-                            .parameter_type = .ucs,
-                            .range = range,
-                            .confidence = 0.7, // Lower confidence for rock strength correlations
-                        };
+                    const range = rs.ucsRange();
+                    var params = StrengthParameters{
+                        .parameter_type = .ucs,
+                        .range = range,
+                        .confidence = 0.7, // Lower confidence for rock strength correlations
+                    };
+                    // Very weak rock is usually tested and designed like a hard soil
+                    if (rs == .very_weak) {
+                        params.intermediate_geomaterial = true;
+                        params.alternate = .{ .parameter_type = .undrained_shear_strength, .range = ucsToCu(range) };
                     }
+                    return params;
                 }
             },
         }
         return null;
     }
 
+    /// The advisory for parameters given for both soil and rock, null for
+    /// descriptions clearly one or the other
+    pub fn boundaryAdvisory(material_type: types.MaterialType, params: StrengthParameters) ?BoundaryAdvisory {
+        if (!params.intermediate_geomaterial) return null;
+        const alternate = params.alternate orelse return null;
+        return switch (params.parameter_type) {
+            .undrained_shear_strength => .{ .described_as = material_type, .as_soil = params.range, .as_rock = alternate.range },
+            .ucs => .{ .described_as = material_type, .as_soil = alternate.range, .as_rock = params.range },
+            .spt_n_value => null,
+        };
+    }
+
+    /// Replace inferred strength ranges with test results. Each parameter
+    /// measured at least once takes the measured min-max range and mean, and
+    /// its provenance becomes `.measured`. A description with no strength
+    /// parameters takes the first measured type; otherwise only the reported
+    /// parameter and its alternate are replaced, and other measurements are
+    /// ignored. The boundary advisory is rebuilt from the merged ranges.
+    pub fn mergeMeasured(desc: *types.GeologicalDescription, measurements: []const Measurement) void {
+        for (std.enums.values(StrengthParameterType)) |parameter_type| {
+            const range = measuredRange(measurements, parameter_type) orelse continue;
+
+            const params = if (desc.strength_parameters) |*existing| existing else {
+                desc.strength_parameters = StrengthParameters{
+                    .parameter_type = parameter_type,
+                    .range = range,
+                    .confidence = 1.0,
+                    .provenance = .measured,
+                };
+                desc.sources.put(.strength_parameters, .measured);
+                continue;
+            };
+
+            if (params.parameter_type == parameter_type) {
+                params.range = range;
+                params.confidence = 1.0;
+                params.provenance = .measured;
+                params.groundwater_adjustment = null;
+                desc.sources.put(.strength_parameters, .measured);
+            } else if (params.alternate) |*alternate| {
+                if (alternate.parameter_type == parameter_type) {
+                    alternate.range = range;
+                    alternate.provenance = .measured;
+                }
+            }
+        }
+
+        const params = desc.strength_parameters orelse return;
+        desc.boundary_advisory = boundaryAdvisory(desc.material_type, params);
+        if (desc.boundary_advisory == null) {
+            desc.sources.remove(.boundary_advisory);
+        } else if (params.provenance == .measured or params.alternate.?.provenance == .measured) {
+            desc.sources.put(.boundary_advisory, .measured);
+        }
+    }
+
+    /// Adjust or flag a strength range when the description reports a wet
+    /// or saturated soil. A measured SPT N range in fine or silty sand gets
+    /// the dilatancy correction, which applies to field N-values only; call
+    /// this after `mergeMeasured` to correct merged results. Ranges inferred
+    /// from the description keep their values with lower confidence, since a
+    /// density term already describes the ground as found. Other measured
+    /// ranges are left alone.
+    pub fn adjustForGroundwater(desc: *types.GeologicalDescription) void {
+        const params = if (desc.strength_parameters) |*existing| existing else return;
+        if (params.groundwater_adjustment != null) return;
+        const moisture = desc.moisture_content orelse return;
+        if (moisture != .wet and moisture != .saturated) return;
+
+        if (params.provenance != .measured) {
+            params.confidence -= 0.05;
+            params.groundwater_adjustment = .reduced_effective_stress;
+            return;
+        }
+
+        const sand = if (desc.primary_soil_type) |soil_type| soil_type == .sand else false;
+        if (params.parameter_type != .spt_n_value or !sand or !isFineOrSilty(desc.*)) return;
+        params.range.lower_bound = dilatancyCorrected(params.range.lower_bound);
+        params.range.upper_bound = dilatancyCorrected(params.range.upper_bound);
+        if (params.range.typical_value) |typical| params.range.typical_value = dilatancyCorrected(typical);
+        params.groundwater_adjustment = .dilatancy;
+    }
+
+    fn isFineOrSilty(desc: types.GeologicalDescription) bool {
+        if (desc.particle_size) |size| {
+            if (size == .fine or size == .fine_to_medium) return true;
+        }
+        for (desc.secondary_constituents) |constituent| {
+            if (std.ascii.eqlIgnoreCase(constituent.soil_type, "silty")) return true;
+        }
+        return false;
+    }
+
+    fn measuredRange(measurements: []const Measurement, parameter_type: StrengthParameterType) ?StrengthRange {
+        var count: usize = 0;
+        var sum: f32 = 0;
+        var range = StrengthRange{ .lower_bound = std.math.floatMax(f32), .upper_bound = -std.math.floatMax(f32) };
+
+        for (measurements) |measurement| {
+            if (measurement.parameter_type != parameter_type) continue;
+            count += 1;
+            sum += measurement.value;
+            range.lower_bound = @min(range.lower_bound, measurement.value);
+            range.upper_bound = @max(range.upper_bound, measurement.value);
+        }
+
+        if (count == 0) return null;
+        range.typical_value = sum / @as(f32, @floatFromInt(count));
+        return range;
+    }
+
     pub fn estimateParameterFromValue(parameter_type: StrengthParameterType, value: f32) ?[]const u8 {
         switch (parameter_type) {
             .undrained_shear_strength => {
@@ -195,6 +443,58 @@ test "cohesive strength parameters" {
     try std.testing.expect(params.?.range.typical_value.? == 37);
 }
 
+test "groundwater adjustment" {
+    var desc = types.GeologicalDescription{
+        .raw_description = "Very dense saturated fine SAND",
+        .material_type = .soil,
+        .density = .very_dense,
+        .moisture_content = .saturated,
+        .particle_size = .fine,
+        .primary_soil_type = .sand,
+    };
+    desc.strength_parameters = StrengthDatabase.getStrengthParameters(.soil, null, .very_dense, null, .sand);
+    StrengthDatabase.adjustForGroundwater(&desc);
+
+    // The very dense band describes the ground as found, so it is only flagged
+    const inferred = desc.strength_parameters.?;
+    try std.testing.expect(inferred.groundwater_adjustment.? == .reduced_effective_stress);
+    try std.testing.expectEqual(@as(f32, 50), inferred.range.lower_bound);
+
+    // Field N-values are corrected for dilatancy
+    const measurements = [_]Measurement{
+        .{ .parameter_type = .spt_n_value, .value = 11 },
+        .{ .parameter_type = .spt_n_value, .value = 35 },
+    };
+    StrengthDatabase.mergeMeasured(&desc, &measurements);
+    StrengthDatabase.adjustForGroundwater(&desc);
+
+    const params = desc.strength_parameters.?;
+    try std.testing.expect(params.groundwater_adjustment.? == .dilatancy);
+    try std.testing.expectEqual(@as(f32, 11), params.range.lower_bound);
+    try std.testing.expectEqual(@as(f32, 25), params.range.upper_bound);
+    try std.testing.expectEqual(@as(f32, 19), params.range.typical_value.?);
+
+    // Applied once only
+    StrengthDatabase.adjustForGroundwater(&desc);
+    try std.testing.expectEqual(@as(f32, 25), desc.strength_parameters.?.range.upper_bound);
+
+    var wet = types.GeologicalDescription{ .raw_description = "Dense wet SAND", .material_type = .soil, .moisture_content = .wet, .primary_soil_type = .sand };
+    wet.strength_parameters = StrengthDatabase.getStrengthParameters(.soil, null, .dense, null, .sand);
+    StrengthDatabase.adjustForGroundwater(&wet);
+    try std.testing.expect(wet.strength_parameters.?.groundwater_adjustment.? == .reduced_effective_stress);
+
+    var clay = types.GeologicalDescription{ .raw_description = "Soft saturated CLAY", .material_type = .soil, .moisture_content = .saturated, .primary_soil_type = .clay };
+    clay.strength_parameters = StrengthDatabase.getStrengthParameters(.soil, .soft, null, null, .clay);
+    StrengthDatabase.adjustForGroundwater(&clay);
+    try std.testing.expect(clay.strength_parameters.?.groundwater_adjustment.? == .reduced_effective_stress);
+    try std.testing.expectEqual(@as(f32, 12), clay.strength_parameters.?.range.lower_bound);
+
+    var dry = types.GeologicalDescription{ .raw_description = "Dense SAND", .material_type = .soil, .moisture_content = .dry, .primary_soil_type = .sand };
+    dry.strength_parameters = StrengthDatabase.getStrengthParameters(.soil, null, .dense, null, .sand);
+    StrengthDatabase.adjustForGroundwater(&dry);
+    try std.testing.expect(dry.strength_parameters.?.groundwater_adjustment == null);
+}
+
 test "granular strength parameters" {
     const params = StrengthDatabase.getStrengthParameters(
         .soil,
@@ -225,6 +525,71 @@ test "rock strength parameters" {
     try std.testing.expect(params.?.range.upper_bound == 100.0);
 }
 
+test "intermediate geomaterials report both cu and UCS" {
+    const hard_clay = StrengthDatabase.getStrengthParameters(.soil, .hard, null, null, .clay).?;
+    try std.testing.expect(hard_clay.intermediate_geomaterial);
+    try std.testing.expect(hard_clay.alternate.?.parameter_type == .ucs);
+    try std.testing.expectApproxEqAbs(@as(f32, 0.4), hard_clay.alternate.?.range.lower_bound, 0.001);
+    try std.testing.expectApproxEqAbs(@as(f32, 0.8), hard_clay.alternate.?.range.upper_bound, 0.001);
+
+    const very_weak = StrengthDatabase.getStrengthParameters(.rock, null, null, .very_weak, null).?;
+    try std.testing.expect(very_weak.intermediate_geomaterial);
+    try std.testing.expect(very_weak.alternate.?.parameter_type == .undrained_shear_strength);
+    try std.testing.expectApproxEqAbs(@as(f32, 125), very_weak.alternate.?.range.lower_bound, 0.001);
+
+    const firm_clay = StrengthDatabase.getStrengthParameters(.soil, .firm, null, null, .clay).?;
+    try std.testing.expect(!firm_clay.intermediate_geomaterial);
+    try std.testing.expect(firm_clay.alternate == null);
+}
+
+test "boundary advisory gives both parameter sets" {
+    const hard_clay = StrengthDatabase.getStrengthParameters(.soil, .hard, null, null, .clay).?;
+    const soil_side = StrengthDatabase.boundaryAdvisory(.soil, hard_clay).?;
+    try std.testing.expectEqual(types.MaterialType.soil, soil_side.described_as);
+    try std.testing.expectApproxEqAbs(@as(f32, 200), soil_side.as_soil.lower_bound, 0.001);
+    try std.testing.expectApproxEqAbs(@as(f32, 0.8), soil_side.as_rock.upper_bound, 0.001);
+
+    const very_weak = StrengthDatabase.getStrengthParameters(.rock, null, null, .very_weak, null).?;
+    const rock_side = StrengthDatabase.boundaryAdvisory(.rock, very_weak).?;
+    try std.testing.expectApproxEqAbs(@as(f32, 125), rock_side.as_soil.lower_bound, 0.001);
+    try std.testing.expectApproxEqAbs(@as(f32, 1.0), rock_side.as_rock.upper_bound, 0.001);
+
+    const firm_clay = StrengthDatabase.getStrengthParameters(.soil, .firm, null, null, .clay).?;
+    try std.testing.expect(StrengthDatabase.boundaryAdvisory(.soil, firm_clay) == null);
+}
+
+test "measured strength overrides inferred range" {
+    var desc = types.SoilDescription{
+        .raw_description = "Hard CLAY",
+        .material_type = .soil,
+        .consistency = .hard,
+        .primary_soil_type = .clay,
+    };
+    desc.strength_parameters = StrengthDatabase.getStrengthParameters(.soil, .hard, null, null, .clay);
+
+    const measurements = [_]Measurement{
+        .{ .parameter_type = .undrained_shear_strength, .value = 220 },
+        .{ .parameter_type = .undrained_shear_strength, .value = 260 },
+        .{ .parameter_type = .spt_n_value, .value = 40 },
+    };
+    StrengthDatabase.mergeMeasured(&desc, &measurements);
+
+    const params = desc.strength_parameters.?;
+    try std.testing.expect(params.provenance == .measured);
+    try std.testing.expect(params.range.lower_bound == 220);
+    try std.testing.expect(params.range.upper_bound == 260);
+    try std.testing.expect(params.range.typical_value.? == 240);
+    // UCS was not tested, so it stays inferred
+    try std.testing.expect(params.alternate.?.provenance == .inferred);
+
+    // The advisory follows the merged cu range
+    const advisory = desc.boundary_advisory.?;
+    try std.testing.expect(advisory.as_soil.lower_bound == 220);
+    try std.testing.expect(advisory.as_soil.upper_bound == 260);
+    try std.testing.expectApproxEqAbs(@as(f32, 0.4), advisory.as_rock.lower_bound, 0.001);
+    try std.testing.expectEqual(types.Source.measured, desc.sourceOf(.boundary_advisory).?);
+}
+
 test "parameter estimation from value" {
     const cu_desc = StrengthDatabase.estimateParameterFromValue(.undrained_shear_strength, 75);
     try std.testing.expect(std.mem.eql(u8, cu_desc.?, "stiff"));
@@ -0,0 +1,129 @@
+const std = @import("std");
+const types = @import("types.zig");
+const borehole = @import("borehole.zig");
+const bs5930 = @import("bs5930.zig");
+const Random = std.Random;
+
+const Stratum = borehole.Stratum;
+const BoreholeLog = borehole.BoreholeLog;
+
+/// Typical ground models for synthetic logs
+pub const Preset = enum {
+    london, // Made ground over alluvium, terrace gravels and London Clay
+    glacial, // Topsoil over glacial till on mudstone
+    chalk, // Topsoil over head deposits on chalk
+
+    pub fn fromString(str: []const u8) ?Preset {
+        return std.meta.stringToEnum(Preset, str);
+    }
+};
+
+/// One unit of a preset. `texts` are alternatives for the upper units; in
+/// the bottom unit they run in order, so it stiffens or strengthens with depth.
+const Unit = struct {
+    texts: []const []const u8,
+    min_thickness: f64,
+    max_thickness: f64,
+};
+
+fn units(preset: Preset) []const Unit {
+    return switch (preset) {
+        .london => &.{
+            .{ .texts = &.{ "MADE GROUND: loose brown gravelly SAND", "MADE GROUND: soft dark brown sandy CLAY" }, .min_thickness = 0.4, .max_thickness = 2.0 },
+            .{ .texts = &.{ "Soft grey silty CLAY (Alluvium)", "Soft to firm brown CLAY (Alluvium)" }, .min_thickness = 1.0, .max_thickness = 4.0 },
+            .{ .texts = &.{ "Medium dense brown sandy GRAVEL (River Terrace Deposits)", "Dense orange brown gravelly SAND (River Terrace Deposits)" }, .min_thickness = 1.5, .max_thickness = 5.0 },
+            .{ .texts = &.{ "Firm brown slightly sandy CLAY (London Clay Formation)", "Stiff grey CLAY (London Clay Formation)", "Very stiff dark grey CLAY (London Clay Formation)" }, .min_thickness = 3.0, .max_thickness = 8.0 },
+        },
+        .glacial => &.{
+            .{ .texts = &.{ "TOPSOIL: soft dark brown sandy CLAY", "TOPSOIL: loose dark brown clayey SAND" }, .min_thickness = 0.2, .max_thickness = 0.5 },
+            .{ .texts = &.{ "Firm brown slightly sandy slightly gravelly CLAY (Glacial Till)", "Stiff grey sandy gravelly CLAY (Glacial Till)" }, .min_thickness = 2.0, .max_thickness = 10.0 },
+            .{ .texts = &.{ "Very weak highly weathered grey MUDSTONE", "Weak moderately weathered grey MUDSTONE", "Moderately strong slightly weathered grey MUDSTONE" }, .min_thickness = 1.5, .max_thickness = 4.0 },
+        },
+        .chalk => &.{
+            .{ .texts = &.{ "TOPSOIL: soft brown silty CLAY", "TOPSOIL: firm dark brown silty CLAY" }, .min_thickness = 0.2, .max_thickness = 0.4 },
+            .{ .texts = &.{ "Firm brown slightly gravelly CLAY (Head)", "Medium dense white clayey GRAVEL (Head)" }, .min_thickness = 0.5, .max_thickness = 3.0 },
+            .{ .texts = &.{ "Very weak completely weathered white CHALK", "Weak highly weathered white CHALK", "Moderately weak white CHALK" }, .min_thickness = 2.0, .max_thickness = 6.0 },
+        },
+    };
+}
+
+/// A generated log and the strata it owns
+pub const SyntheticLog = struct {
+    strata: []Stratum,
+
+    pub fn log(self: SyntheticLog, id: []const u8) BoreholeLog {
+        return BoreholeLog{ .id = id, .strata = self.strata };
+    }
+
+    pub fn deinit(self: SyntheticLog, allocator: std.mem.Allocator) void {
+        for (self.strata) |stratum| stratum.description.deinit(allocator);
+        allocator.free(self.strata);
+    }
+};
+
+/// Plausibly ordered strata down to `depth` metres for demos, UI work and
+/// load tests. The same seed always gives the same log.
+pub fn generateSyntheticLog(allocator: std.mem.Allocator, depth: f64, preset: Preset, seed: u64) !SyntheticLog {
+    if (depth <= 0) return error.InvalidDepth;
+
+    var prng = Random.DefaultPrng.init(seed);
+    const random = prng.random();
+    var parser = bs5930.Parser.init(allocator);
+
+    var strata = std.ArrayList(Stratum).init(allocator);
+    errdefer {
+        for (strata.items) |stratum| stratum.description.deinit(allocator);
+        strata.deinit();
+    }
+
+    const sequence = units(preset);
+    var top: f64 = 0;
+    for (sequence, 0..) |unit, unit_index| {
+        const is_last = unit_index == sequence.len - 1;
+        var step: usize = 0;
+        while (top < depth) : (step += 1) {
+            const text = if (is_last)
+                unit.texts[@min(step, unit.texts.len - 1)]
+            else
+                unit.texts[random.uintLessThan(usize, unit.texts.len)];
+
+            // Round to the 0.1 m a logger would record
+            const span = unit.min_thickness + random.float(f64) * (unit.max_thickness - unit.min_thickness);
+            const bottom = @min(depth, @round((top + span) * 10.0) / 10.0);
+
+            const description = try parser.parse(text);
+            strata.append(.{ .depth_top = top, .depth_bottom = bottom, .description = description }) catch |err| {
+                description.deinit(allocator);
+                return err;
+            };
+            top = bottom;
+
+            // Upper units are a single stratum; the bottom unit repeats
+            if (!is_last) break;
+        }
+    }
+
+    return SyntheticLog{ .strata = try strata.toOwnedSlice() };
+}
+
+test "synthetic london log is ordered and reaches the target depth" {
+    const allocator = std.testing.allocator;
+
+    const synthetic = try generateSyntheticLog(allocator, 25.0, .london, 42);
+    defer synthetic.deinit(allocator);
+
+    const log = synthetic.log("SYN01");
+    try std.testing.expectApproxEqAbs(@as(f64, 25.0), log.finalDepth(), 0.0001);
+    try std.testing.expect(synthetic.strata[0].description.is_made_ground);
+
+    var previous_bottom: f64 = 0;
+    for (synthetic.strata) |stratum| {
+        try std.testing.expectApproxEqAbs(previous_bottom, stratum.depth_top, 0.0001);
+        try std.testing.expect(stratum.depth_bottom > stratum.depth_top);
+        previous_bottom = stratum.depth_bottom;
+    }
+
+    const again = try generateSyntheticLog(allocator, 25.0, .london, 42);
+    defer again.deinit(allocator);
+    try std.testing.expectEqual(synthetic.strata.len, again.strata.len);
+}
@@ -0,0 +1,214 @@
+const std = @import("std");
+const types = @import("types.zig");
+const lexer = @import("lexer.zig");
+
+const GeologicalDescription = types.GeologicalDescription;
+const SoilDescription = types.SoilDescription;
+const Lexer = lexer.Lexer;
+const Token = lexer.Token;
+
+/// Entity classes emitted in BIO labels
+pub const LabelEntity = enum {
+    consistency,
+    density,
+    rock_strength,
+    weathering,
+    structure,
+    constituent,
+    primary_type,
+    colour,
+    moisture,
+    plasticity,
+    particle_size,
+
+    pub fn toString(self: LabelEntity) []const u8 {
+        return switch (self) {
+            .consistency => "CONSISTENCY",
+            .density => "DENSITY",
+            .rock_strength => "ROCK_STRENGTH",
+            .weathering => "WEATHERING",
+            .structure => "STRUCTURE",
+            .constituent => "CONSTITUENT",
+            .primary_type => "PRIMARY_TYPE",
+            .colour => "COLOUR",
+            .moisture => "MOISTURE",
+            .plasticity => "PLASTICITY",
+            .particle_size => "PARTICLE_SIZE",
+        };
+    }
+};
+
+pub const BioTag = enum {
+    begin,
+    inside,
+    outside,
+};
+
+/// A whitespace-delimited word with its BIO label.
+/// `text` slices the description passed to labelDescription.
+pub const LabelledWord = struct {
+    text: []const u8,
+    start: usize,
+    end: usize,
+    tag: BioTag = .outside,
+    entity: ?LabelEntity = null,
+
+    pub fn writeLabel(self: LabelledWord, writer: anytype) !void {
+        switch (self.tag) {
+            .outside => try writer.writeAll("O"),
+            .begin => try writer.print("B-{s}", .{self.entity.?.toString()}),
+            .inside => try writer.print("I-{s}", .{self.entity.?.toString()}),
+        }
+    }
+};
+
+/// Label each word of a description using its rule-based parse as a weak labeller.
+/// Spans are only labelled when they agree with the parsed field, so the
+/// labels never claim more than the parser actually extracted.
+pub fn labelDescription(allocator: std.mem.Allocator, text: []const u8, desc: *const GeologicalDescription) ![]LabelledWord {
+    var lex = Lexer.init(allocator, text);
+    defer lex.deinit();
+
+    const tokens = try lex.tokenize();
+    defer {
+        for (tokens) |token| {
+            if (token.corrected_from) |_| allocator.free(token.value);
+        }
+        allocator.free(tokens);
+    }
+
+    var words = std.ArrayList(LabelledWord).init(allocator);
+    errdefer words.deinit();
+
+    var i: usize = 0;
+    while (i < tokens.len) : (i += 1) {
+        const token = tokens[i];
+
+        // Proportion + adjective ("slightly sandy") forms one constituent span
+        if (token.type == .proportion and i + 1 < tokens.len) {
+            const next = tokens[i + 1];
+            if (next.type == .adjective or next.type == .soil_type) {
+                try appendSpan(&words, text, token.start, next.end, .constituent);
+                i += 1;
+                continue;
+            }
+        }
+
+        try appendSpan(&words, text, token.start, token.end, tokenEntity(token, desc));
+    }
+
+    return words.toOwnedSlice();
+}
+
+/// Write labelled words in CoNLL format: one "word<TAB>label" per line,
+/// with a blank line terminating the sentence.
+pub fn writeConll(words: []const LabelledWord, writer: anytype) !void {
+    for (words) |word| {
+        try writer.writeAll(word.text);
+        try writer.writeByte('\t');
+        try word.writeLabel(writer);
+        try writer.writeByte('\n');
+    }
+    try writer.writeByte('\n');
+}
+
+fn appendSpan(words: *std.ArrayList(LabelledWord), text: []const u8, start: usize, end: usize, entity: ?LabelEntity) !void {
+    var pos = start;
+    var first = true;
+    while (pos < end) {
+        while (pos < end and std.ascii.isWhitespace(text[pos])) pos += 1;
+        if (pos >= end) break;
+
+        const word_start = pos;
+        while (pos < end and !std.ascii.isWhitespace(text[pos])) pos += 1;
+
+        try words.append(LabelledWord{
+            .text = text[word_start..pos],
+            .start = word_start,
+            .end = pos,
+            .tag = if (entity == null) .outside else if (first) .begin else .inside,
+            .entity = entity,
+        });
+        first = false;
+    }
+}
+
+fn tokenEntity(token: Token, desc: *const GeologicalDescription) ?LabelEntity {
+    return switch (token.type) {
+        .consistency_range, .consistency => if (agrees(types.Consistency, desc.consistency, types.Consistency.fromString(token.value))) .consistency else null,
+        .density => if (agrees(types.Density, desc.density, types.Density.fromString(token.value))) .density else null,
+        .rock_strength => if (agrees(types.RockStrength, desc.rock_strength, types.RockStrength.fromString(token.value))) .rock_strength else null,
+        .weathering_grade => if (agrees(types.WeatheringGrade, desc.weathering_grade, types.WeatheringGrade.fromString(token.value))) .weathering else null,
+        .rock_structure => if (agrees(types.RockStructure, desc.rock_structure, types.RockStructure.fromString(token.value))) .structure else null,
+        .rock_type => if (agrees(types.RockType, desc.primary_rock_type, types.RockType.fromString(token.value))) .primary_type else null,
+        .soil_type, .word => blk: {
+            const soil_type = types.SoilType.fromString(token.value);
+            if (agrees(types.SoilType, desc.primary_soil_type, soil_type)) break :blk .primary_type;
+            if (agrees(types.SoilType, desc.secondary_primary_soil_type, soil_type)) break :blk .primary_type;
+            if (agrees(types.RockType, desc.primary_rock_type, types.RockType.fromString(token.value))) break :blk .primary_type;
+            break :blk null;
+        },
+        .adjective => if (desc.secondary_constituents.len > 0) .constituent else null,
+        .color => if (agrees(types.Color, desc.color, types.Color.fromString(token.value))) .colour else null,
+        .moisture_content => if (agrees(types.MoistureContent, desc.moisture_content, types.MoistureContent.fromString(token.value))) .moisture else null,
+        .plasticity_index => if (agrees(types.PlasticityIndex, desc.plasticity_index, types.PlasticityIndex.fromString(token.value))) .plasticity else null,
+        .particle_size => if (agrees(types.ParticleSize, desc.particle_size, types.ParticleSize.fromString(token.value))) .particle_size else null,
+        .proportion, .unknown => null,
+    };
+}
+
+fn agrees(comptime T: type, parsed: ?T, token_value: ?T) bool {
+    const p = parsed orelse return false;
+    const v = token_value orelse return false;
+    return p == v;
+}
+
+test "label description with constituent span" {
+    const allocator = std.testing.allocator;
+    var constituents = [_]types.SecondaryConstituent{.{ .amount = "slightly", .soil_type = "sandy" }};
+    const text = "Firm slightly sandy brown CLAY";
+    const desc = SoilDescription{
+        .raw_description = text,
+        .material_type = .soil,
+        .consistency = .firm,
+        .primary_soil_type = .clay,
+        .color = .brown,
+        .secondary_constituents = &constituents,
+    };
+
+    const words = try labelDescription(allocator, text, &desc);
+    defer allocator.free(words);
+
+    try std.testing.expectEqual(@as(usize, 5), words.len);
+    try std.testing.expectEqual(BioTag.begin, words[0].tag);
+    try std.testing.expectEqual(LabelEntity.consistency, words[0].entity.?);
+    try std.testing.expectEqual(BioTag.begin, words[1].tag);
+    try std.testing.expectEqual(LabelEntity.constituent, words[1].entity.?);
+    try std.testing.expectEqual(BioTag.inside, words[2].tag);
+    try std.testing.expectEqual(LabelEntity.colour, words[3].entity.?);
+    try std.testing.expectEqual(LabelEntity.primary_type, words[4].entity.?);
+}
+
+test "write conll output" {
+    const allocator = std.testing.allocator;
+    const text = "Stiff to very stiff grey CLAY";
+    const desc = SoilDescription{
+        .raw_description = text,
+        .material_type = .soil,
+        .consistency = .stiff_to_very_stiff,
+        .primary_soil_type = .clay,
+    };
+
+    const words = try labelDescription(allocator, text, &desc);
+    defer allocator.free(words);
+
+    var output = std.ArrayList(u8).init(allocator);
+    defer output.deinit();
+    try writeConll(words, output.writer());
+
+    // Colour was not parsed, so it is left unlabelled
+    try std.testing.expectEqualStrings(
+        "Stiff\tB-CONSISTENCY\nto\tI-CONSISTENCY\nvery\tI-CONSISTENCY\nstiff\tI-CONSISTENCY\ngrey\tO\nCLAY\tB-PRIMARY_TYPE\n\n",
+        output.items,
+    );
+}
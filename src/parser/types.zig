@@ -1,9 +1,29 @@
 const std = @import("std");
 const builtin = @import("builtin");
 
-pub const MaterialType = enum {
-    soil,
-    rock,
+// Enums with explicit values are stored as integers by callers and cross the
+// C API (include/litholog.h). Never renumber them; give new members the next
+// free value.
+
+/// Stored value for a term this build does not recognise, LITHOLOG_*_UNKNOWN
+/// in the C API. It is not an enum member, so switches stay exhaustive.
+pub const unknown_value: u8 = 255;
+
+/// A pinned enum from its stored value. `unknown_value`, and values added by
+/// a newer release, give null rather than a wrong member.
+pub fn fromStored(comptime T: type, value: u8) ?T {
+    if (value == unknown_value) return null;
+    return std.meta.intToEnum(T, value) catch null;
+}
+
+/// The value to store for a pinned enum, `unknown_value` when there is none
+pub fn toStored(comptime T: type, value: ?T) u8 {
+    return if (value) |member| @intFromEnum(member) else unknown_value;
+}
+
+pub const MaterialType = enum(u8) {
+    soil = 0,
+    rock = 1,
 
     pub fn toString(self: MaterialType) []const u8 {
         return switch (self) {
@@ -13,21 +33,47 @@ pub const MaterialType = enum {
     }
 };
 
+/// The description standard a parser reads and writes
+pub const Standard = enum {
+    bs5930, // BS 5930:2015 (UK)
+    as1726, // AS 1726-2017 (Australia/NZ)
+
+    /// "BS5930", "BS 5930:2015", "AS1726", "AS 1726-2017", ...; case-insensitive
+    pub fn fromString(str: []const u8) ?Standard {
+        var compact_buf: [32]u8 = undefined;
+        var len: usize = 0;
+        for (str) |ch| {
+            if (ch == ' ') continue;
+            if (len == compact_buf.len) return null;
+            compact_buf[len] = std.ascii.toLower(ch);
+            len += 1;
+        }
+        const compact = compact_buf[0..len];
+
+        if (std.mem.startsWith(u8, compact, "bs5930")) return .bs5930;
+        if (std.mem.startsWith(u8, compact, "as1726")) return .as1726;
+        return null;
+    }
+};
+
 // Forward declarations for database modules
 pub const StrengthParameters = @import("strength_db.zig").StrengthParameters;
+pub const BoundaryAdvisory = @import("strength_db.zig").BoundaryAdvisory;
 pub const ConstituentGuidance = @import("constituent_db.zig").ConstituentGuidance;
-
-pub const Consistency = enum {
-    very_soft,
-    soft,
-    firm,
-    stiff,
-    very_stiff,
-    hard,
+const strength_db = @import("strength_db.zig");
+pub const StrengthRange = strength_db.StrengthRange;
+
+pub const Consistency = enum(u8) {
+    very_soft = 0,
+    soft = 1,
+    firm = 2,
+    stiff = 3,
+    very_stiff = 4,
+    hard = 5,
     // Range types
-    soft_to_firm,
-    firm_to_stiff,
-    stiff_to_very_stiff,
+    soft_to_firm = 6,
+    firm_to_stiff = 7,
+    stiff_to_very_stiff = 8,
 
     pub fn fromString(str: []const u8) ?Consistency {
         var lower_buf: [64]u8 = undefined;
@@ -50,6 +96,24 @@ pub const Consistency = enum {
         return null;
     }
 
+    /// Like `fromString`, but an unrecognised term is an error
+    pub fn parse(str: []const u8) !Consistency {
+        return fromString(str) orelse error.InvalidConsistency;
+    }
+
+    /// Combine two adjacent consistencies ("firm-stiff", "firm/stiff") into a range
+    pub fn range(lower: Consistency, upper: Consistency) ?Consistency {
+        if (lower == .soft and upper == .firm) return .soft_to_firm;
+        if (lower == .firm and upper == .stiff) return .firm_to_stiff;
+        if (lower == .stiff and upper == .very_stiff) return .stiff_to_very_stiff;
+        return null;
+    }
+
+    /// Typical undrained shear strength cu (kPa)
+    pub fn cuRange(self: Consistency) StrengthRange {
+        return strength_db.cuRange(self);
+    }
+
     pub fn toString(self: Consistency) []const u8 {
         return switch (self) {
             .very_soft => "very soft",
@@ -65,14 +129,14 @@ pub const Consistency = enum {
     }
 };
 
-pub const Density = enum {
-    very_loose,
-    loose,
-    loose_to_medium_dense,
-    medium_dense,
-    medium_dense_to_dense,
-    dense,
-    very_dense,
+pub const Density = enum(u8) {
+    very_loose = 0,
+    loose = 1,
+    loose_to_medium_dense = 2,
+    medium_dense = 3,
+    medium_dense_to_dense = 4,
+    dense = 5,
+    very_dense = 6,
 
     pub fn fromString(str: []const u8) ?Density {
         var lower_buf: [64]u8 = undefined;
@@ -91,6 +155,23 @@ pub const Density = enum {
         return null;
     }
 
+    /// Like `fromString`, but an unrecognised term is an error
+    pub fn parse(str: []const u8) !Density {
+        return fromString(str) orelse error.InvalidDensity;
+    }
+
+    /// Combine two adjacent densities ("loose-medium dense") into a range
+    pub fn range(lower: Density, upper: Density) ?Density {
+        if (lower == .loose and upper == .medium_dense) return .loose_to_medium_dense;
+        if (lower == .medium_dense and upper == .dense) return .medium_dense_to_dense;
+        return null;
+    }
+
+    /// Typical SPT N-value (blows/300mm)
+    pub fn sptRange(self: Density) StrengthRange {
+        return strength_db.sptRange(self);
+    }
+
     pub fn toString(self: Density) []const u8 {
         return switch (self) {
             .very_loose => "very loose",
@@ -104,22 +185,22 @@ pub const Density = enum {
     }
 };
 
-pub const RockType = enum {
-    limestone,
-    sandstone,
-    mudstone,
-    shale,
-    granite,
-    basalt,
-    chalk,
-    dolomite,
-    quartzite,
-    slate,
-    schist,
-    gneiss,
-    marble,
-    conglomerate,
-    breccia,
+pub const RockType = enum(u8) {
+    limestone = 0,
+    sandstone = 1,
+    mudstone = 2,
+    shale = 3,
+    granite = 4,
+    basalt = 5,
+    chalk = 6,
+    dolomite = 7,
+    quartzite = 8,
+    slate = 9,
+    schist = 10,
+    gneiss = 11,
+    marble = 12,
+    conglomerate = 13,
+    breccia = 14,
 
     pub fn fromString(str: []const u8) ?RockType {
         var lower_buf: [64]u8 = undefined;
@@ -146,6 +227,11 @@ pub const RockType = enum {
         return null;
     }
 
+    /// Like `fromString`, but an unrecognised term is an error
+    pub fn parse(str: []const u8) !RockType {
+        return fromString(str) orelse error.InvalidRockType;
+    }
+
     pub fn toString(self: RockType) []const u8 {
         return switch (self) {
             .limestone => "LIMESTONE",
@@ -167,14 +253,14 @@ pub const RockType = enum {
     }
 };
 
-pub const RockStrength = enum {
-    very_weak,
-    weak,
-    moderately_weak,
-    moderately_strong,
-    strong,
-    very_strong,
-    extremely_strong,
+pub const RockStrength = enum(u8) {
+    very_weak = 0,
+    weak = 1,
+    moderately_weak = 2,
+    moderately_strong = 3,
+    strong = 4,
+    very_strong = 5,
+    extremely_strong = 6,
 
     pub fn fromString(str: []const u8) ?RockStrength {
         var lower_buf: [64]u8 = undefined;
@@ -182,7 +268,8 @@ pub const RockStrength = enum {
 
         const lower = std.ascii.lowerString(lower_buf[0..str.len], str);
 
-        if (std.mem.eql(u8, lower, "very weak")) return .very_weak;
+        // The ISO 14689 term for UCS below 1 MPa, the band very weak covers here
+        if (std.mem.eql(u8, lower, "very weak") or std.mem.eql(u8, lower, "extremely weak")) return .very_weak;
         if (std.mem.eql(u8, lower, "weak")) return .weak;
         if (std.mem.eql(u8, lower, "moderately weak")) return .moderately_weak;
         if (std.mem.eql(u8, lower, "moderately strong")) return .moderately_strong;
@@ -193,6 +280,16 @@ pub const RockStrength = enum {
         return null;
     }
 
+    /// Like `fromString`, but an unrecognised term is an error
+    pub fn parse(str: []const u8) !RockStrength {
+        return fromString(str) orelse error.InvalidRockStrength;
+    }
+
+    /// Typical unconfined compressive strength (MPa)
+    pub fn ucsRange(self: RockStrength) StrengthRange {
+        return strength_db.ucsRange(self);
+    }
+
     pub fn toString(self: RockStrength) []const u8 {
         return switch (self) {
             .very_weak => "very weak",
@@ -206,12 +303,12 @@ pub const RockStrength = enum {
     }
 };
 
-pub const WeatheringGrade = enum {
-    fresh,
-    slightly_weathered,
-    moderately_weathered,
-    highly_weathered,
-    completely_weathered,
+pub const WeatheringGrade = enum(u8) {
+    fresh = 0,
+    slightly_weathered = 1,
+    moderately_weathered = 2,
+    highly_weathered = 3,
+    completely_weathered = 4,
 
     pub fn fromString(str: []const u8) ?WeatheringGrade {
         var lower_buf: [64]u8 = undefined;
@@ -228,6 +325,22 @@ pub const WeatheringGrade = enum {
         return null;
     }
 
+    /// Like `fromString`, but an unrecognised term is an error
+    pub fn parse(str: []const u8) !WeatheringGrade {
+        return fromString(str) orelse error.InvalidWeatheringGrade;
+    }
+
+    /// BS 5930 weathering grade, "I" (fresh) to "V" (completely weathered)
+    pub fn gradeCode(self: WeatheringGrade) []const u8 {
+        return switch (self) {
+            .fresh => "I",
+            .slightly_weathered => "II",
+            .moderately_weathered => "III",
+            .highly_weathered => "IV",
+            .completely_weathered => "V",
+        };
+    }
+
     pub fn toString(self: WeatheringGrade) []const u8 {
         return switch (self) {
             .fresh => "fresh",
@@ -239,13 +352,74 @@ pub const WeatheringGrade = enum {
     }
 };
 
-pub const RockStructure = enum {
-    massive,
-    bedded,
-    jointed,
-    fractured,
-    foliated,
-    laminated,
+/// Karst dissolution grade of a carbonate rock mass (Waltham & Fookes),
+/// from kI (juvenile) to kV (extreme)
+pub const KarstGrade = enum {
+    juvenile, // kI: minor dissolution, rare fissures
+    youthful, // kII: solution-widened fissures, caves < 1 m
+    mature, // kIII: caves 1-5 m, dolines and sinkholes
+    complex, // kIV: caves > 5 m, pinnacled rockhead
+    extreme, // kV: very large caves and extensive voids
+
+    pub fn fromString(str: []const u8) ?KarstGrade {
+        var lower_buf: [64]u8 = undefined;
+        if (str.len >= lower_buf.len) return null;
+
+        const lower = std.ascii.lowerString(lower_buf[0..str.len], str);
+
+        if (std.mem.eql(u8, lower, "juvenile")) return .juvenile;
+        if (std.mem.eql(u8, lower, "youthful")) return .youthful;
+        if (std.mem.eql(u8, lower, "mature")) return .mature;
+        if (std.mem.eql(u8, lower, "complex")) return .complex;
+        if (std.mem.eql(u8, lower, "extreme")) return .extreme;
+
+        return fromCode(str);
+    }
+
+    /// "kI" to "kV" or "k1" to "k5"; case-insensitive
+    pub fn fromCode(text: []const u8) ?KarstGrade {
+        if (text.len < 2 or std.ascii.toLower(text[0]) != 'k') return null;
+        return fromNumber(text[1..]);
+    }
+
+    /// "1" to "5" or "I" to "V"
+    pub fn fromNumber(number: []const u8) ?KarstGrade {
+        const numerals = [_][]const u8{ "i", "ii", "iii", "iv", "v" };
+        for (numerals, 0..) |numeral, index| {
+            if (std.ascii.eqlIgnoreCase(number, numeral)) return @as(KarstGrade, @enumFromInt(index));
+        }
+        if (number.len == 1 and number[0] >= '1' and number[0] <= '5') return @as(KarstGrade, @enumFromInt(number[0] - '1'));
+        return null;
+    }
+
+    pub fn toString(self: KarstGrade) []const u8 {
+        return switch (self) {
+            .juvenile => "juvenile",
+            .youthful => "youthful",
+            .mature => "mature",
+            .complex => "complex",
+            .extreme => "extreme",
+        };
+    }
+
+    pub fn code(self: KarstGrade) []const u8 {
+        return switch (self) {
+            .juvenile => "kI",
+            .youthful => "kII",
+            .mature => "kIII",
+            .complex => "kIV",
+            .extreme => "kV",
+        };
+    }
+};
+
+pub const RockStructure = enum(u8) {
+    massive = 0,
+    bedded = 1,
+    jointed = 2,
+    fractured = 3,
+    foliated = 4,
+    laminated = 5,
 
     pub fn fromString(str: []const u8) ?RockStructure {
         var lower_buf: [64]u8 = undefined;
@@ -263,6 +437,11 @@ pub const RockStructure = enum {
         return null;
     }
 
+    /// Like `fromString`, but an unrecognised term is an error
+    pub fn parse(str: []const u8) !RockStructure {
+        return fromString(str) orelse error.InvalidRockStructure;
+    }
+
     pub fn toString(self: RockStructure) []const u8 {
         return switch (self) {
             .massive => "massive",
@@ -355,6 +534,288 @@ pub const Color = enum {
     }
 };
 
+/// Base colour of a `Colour`, with "gray" read as grey
+pub const Hue = enum {
+    grey,
+    brown,
+    red,
+    yellow,
+    orange,
+    black,
+    white,
+    green,
+    blue,
+    pink,
+    purple,
+    cream,
+    buff,
+    tan,
+
+    pub fn fromString(str: []const u8) ?Hue {
+        if (std.ascii.eqlIgnoreCase(str, "gray")) return .grey;
+        inline for (std.meta.fields(Hue)) |field| {
+            if (std.ascii.eqlIgnoreCase(str, field.name)) return @enumFromInt(field.value);
+        }
+        return null;
+    }
+
+    /// The hue of a qualifying "-ish" word ("brownish", "reddish", "orangey")
+    pub fn fromTint(str: []const u8) ?Hue {
+        if (std.ascii.eqlIgnoreCase(str, "grayish")) return .grey;
+        if (std.ascii.eqlIgnoreCase(str, "orangey")) return .orange;
+        if (std.ascii.eqlIgnoreCase(str, "blueish")) return .blue;
+        for (std.enums.values(Hue)) |hue| {
+            const tint = hue.tintName() orelse continue;
+            if (std.ascii.eqlIgnoreCase(str, tint)) return hue;
+        }
+        return null;
+    }
+
+    pub fn toString(self: Hue) []const u8 {
+        return @tagName(self);
+    }
+
+    pub fn tintName(self: Hue) ?[]const u8 {
+        return switch (self) {
+            .grey => "greyish",
+            .brown => "brownish",
+            .red => "reddish",
+            .yellow => "yellowish",
+            .orange => "orangish",
+            .black => "blackish",
+            .white => "whitish",
+            .green => "greenish",
+            .blue => "bluish",
+            .pink => "pinkish",
+            .purple => "purplish",
+            .cream, .buff, .tan => null,
+        };
+    }
+};
+
+pub const Shade = enum {
+    light,
+    pale,
+    dark,
+
+    pub fn fromString(str: []const u8) ?Shade {
+        if (std.ascii.eqlIgnoreCase(str, "light")) return .light;
+        if (std.ascii.eqlIgnoreCase(str, "pale")) return .pale;
+        if (std.ascii.eqlIgnoreCase(str, "dark")) return .dark;
+        return null;
+    }
+
+    pub fn toString(self: Shade) []const u8 {
+        return @tagName(self);
+    }
+};
+
+/// A shade and tint of one hue ("dark brownish grey", "grey-brown")
+pub const Tone = struct {
+    shade: ?Shade = null,
+    /// The "-ish" or hyphenated qualifier: grey in "greyish brown"
+    tint: ?Hue = null,
+    hue: Hue,
+
+    /// "[shade] [tint] hue", as `format` writes it
+    pub fn fromString(str: []const u8) ?Tone {
+        var tone = Tone{ .hue = undefined };
+        var words = std.mem.tokenizeScalar(u8, str, ' ');
+        var word = words.next() orelse return null;
+        if (Shade.fromString(word)) |shade| {
+            tone.shade = shade;
+            word = words.next() orelse return null;
+        }
+        if (Hue.fromTint(word)) |tint| {
+            tone.tint = tint;
+            word = words.next() orelse return null;
+        } else if (std.mem.indexOfScalar(u8, word, '-')) |dash| {
+            tone.tint = Hue.fromString(word[0..dash]) orelse return null;
+            word = word[dash + 1 ..];
+        }
+        tone.hue = Hue.fromString(word) orelse return null;
+        if (words.next() != null) return null;
+        return tone;
+    }
+
+    pub fn format(self: Tone, comptime _: []const u8, _: std.fmt.FormatOptions, writer: anytype) !void {
+        if (self.shade) |shade| try writer.print("{s} ", .{shade.toString()});
+        if (self.tint) |tint| {
+            if (tint.tintName()) |name| {
+                try writer.print("{s} ", .{name});
+            } else {
+                try writer.print("{s}-", .{tint.toString()});
+            }
+        }
+        try writer.writeAll(self.hue.toString());
+    }
+};
+
+/// The full colour of a soil or rock, mandatory in a BS 5930 description
+/// ("dark brownish grey", "grey mottled orange", "mottled orange and grey")
+pub const Colour = struct {
+    shade: ?Shade = null,
+    tint: ?Hue = null,
+    hue: Hue,
+    /// Colour of the mottles; for "mottled orange and grey" the second colour
+    mottling: ?Tone = null,
+
+    pub fn tone(self: Colour) Tone {
+        return Tone{ .shade = self.shade, .tint = self.tint, .hue = self.hue };
+    }
+
+    pub fn format(self: Colour, comptime _: []const u8, _: std.fmt.FormatOptions, writer: anytype) !void {
+        try writer.print("{}", .{self.tone()});
+        if (self.mottling) |mottles| try writer.print(" mottled {}", .{mottles});
+    }
+};
+
+/// "hue", "shade" and "tint" of a tone as compact JSON members
+fn writeToneFields(writer: anytype, tone: Tone) !void {
+    try writer.print("\"hue\":\"{s}\"", .{tone.hue.toString()});
+    if (tone.shade) |shade| try writer.print(",\"shade\":\"{s}\"", .{shade.toString()});
+    if (tone.tint) |tint| try writer.print(",\"tint\":\"{s}\"", .{tint.toString()});
+}
+
+/// A tone read back from its JSON object; null for an unknown hue
+fn toneFromJson(value: std.json.Value) !?Tone {
+    if (value != .object) return error.InvalidJson;
+    const hue = value.object.get("hue") orelse return error.InvalidJson;
+    if (hue != .string) return error.InvalidJson;
+    var tone = Tone{ .hue = Hue.fromString(hue.string) orelse return null };
+    if (value.object.get("shade")) |shade| {
+        if (shade != .string) return error.InvalidJson;
+        tone.shade = Shade.fromString(shade.string);
+    }
+    if (value.object.get("tint")) |tint| {
+        if (tint != .string) return error.InvalidJson;
+        tone.tint = Hue.fromString(tint.string);
+    }
+    return tone;
+}
+
+/// Hue family of a Munsell colour, with `n` for neutral greys
+pub const MunsellHue = enum {
+    r,
+    yr,
+    y,
+    gy,
+    g,
+    bg,
+    b,
+    pb,
+    p,
+    rp,
+    n,
+
+    pub fn fromString(str: []const u8) ?MunsellHue {
+        for (std.enums.values(MunsellHue)) |hue| {
+            if (std.ascii.eqlIgnoreCase(str, @tagName(hue))) return hue;
+        }
+        return null;
+    }
+
+    pub fn toString(self: MunsellHue) []const u8 {
+        return switch (self) {
+            .r => "R",
+            .yr => "YR",
+            .y => "Y",
+            .gy => "GY",
+            .g => "G",
+            .bg => "BG",
+            .b => "B",
+            .pb => "PB",
+            .p => "P",
+            .rp => "RP",
+            .n => "N",
+        };
+    }
+};
+
+/// A Munsell colour code as read from a soil colour chart: "10YR 5/3" is
+/// hue step 10 of yellow-red, value 5 and chroma 3; "N 5/" is a neutral grey.
+/// Out-of-range codes are kept as written and fail `isValid`.
+pub const MunsellColour = struct {
+    /// Position within the hue family, 0 to 10; unused for neutral
+    step: f32 = 0,
+    hue: MunsellHue,
+    /// Lightness, 0 (black) to 10 (white)
+    value: f32,
+    /// Strength of colour; always 0 for neutral
+    chroma: f32 = 0,
+
+    /// Chroma beyond this is not reached by any soil or rock
+    pub const max_chroma: f32 = 20;
+
+    /// Parse a whole code such as "10YR 5/3", "2.5Y 6/2" or "N 4/"
+    pub fn fromString(str: []const u8) ?MunsellColour {
+        const trimmed = std.mem.trim(u8, str, " \t");
+        const read = readAt(trimmed, 0) orelse return null;
+        if (read.end != trimmed.len) return null;
+        return read.colour;
+    }
+
+    pub const Read = struct {
+        colour: MunsellColour,
+        /// Index just past the code
+        end: usize,
+    };
+
+    /// Read a code starting at `start`, or null if the text there is not one
+    pub fn readAt(text: []const u8, start: usize) ?Read {
+        const step_end = numberEnd(text, start);
+        var pos = step_end;
+        while (pos < text.len and std.ascii.isAlphabetic(text[pos])) pos += 1;
+        const hue = MunsellHue.fromString(text[step_end..pos]) orelse return null;
+
+        var colour = MunsellColour{ .hue = hue, .value = undefined };
+        if (hue == .n) {
+            if (step_end != start) return null;
+        } else {
+            if (step_end == start) return null;
+            colour.step = std.fmt.parseFloat(f32, text[start..step_end]) catch return null;
+        }
+
+        while (pos < text.len and text[pos] == ' ') pos += 1;
+        const value_end = numberEnd(text, pos);
+        if (value_end == pos or value_end >= text.len or text[value_end] != '/') return null;
+        colour.value = std.fmt.parseFloat(f32, text[pos..value_end]) catch return null;
+
+        pos = value_end + 1;
+        const chroma_end = numberEnd(text, pos);
+        if (chroma_end > pos) {
+            colour.chroma = std.fmt.parseFloat(f32, text[pos..chroma_end]) catch return null;
+        } else if (hue != .n) {
+            return null;
+        }
+        if (chroma_end < text.len and std.ascii.isAlphanumeric(text[chroma_end])) return null;
+        return Read{ .colour = colour, .end = chroma_end };
+    }
+
+    /// Digits with an optional decimal part
+    fn numberEnd(text: []const u8, start: usize) usize {
+        var pos = start;
+        while (pos < text.len and std.ascii.isDigit(text[pos])) pos += 1;
+        if (pos > start and pos + 1 < text.len and text[pos] == '.' and std.ascii.isDigit(text[pos + 1])) {
+            pos += 1;
+            while (pos < text.len and std.ascii.isDigit(text[pos])) pos += 1;
+        }
+        return pos;
+    }
+
+    /// Hue step, value and chroma are all within the Munsell system
+    pub fn isValid(self: MunsellColour) bool {
+        if (self.value < 0 or self.value > 10) return false;
+        if (self.hue == .n) return self.chroma == 0;
+        return self.step > 0 and self.step <= 10 and self.chroma > 0 and self.chroma <= max_chroma;
+    }
+
+    pub fn format(self: MunsellColour, comptime _: []const u8, _: std.fmt.FormatOptions, writer: anytype) !void {
+        if (self.hue == .n) return writer.print("N {d}/", .{self.value});
+        try writer.print("{d}{s} {d}/{d}", .{ self.step, self.hue.toString(), self.value, self.chroma });
+    }
+};
+
 pub const MoistureContent = enum {
     dry,
     moist,
@@ -400,8 +861,11 @@ pub const PlasticityIndex = enum {
 
         if (std.mem.eql(u8, lower, "non plastic")) return .non_plastic;
         if (std.mem.eql(u8, lower, "non-plastic")) return .non_plastic;
+        if (std.mem.eql(u8, lower, "nonplastic")) return .non_plastic;
         if (std.mem.eql(u8, lower, "low plasticity")) return .low_plasticity;
         if (std.mem.eql(u8, lower, "intermediate plasticity")) return .intermediate_plasticity;
+        // The AS 1726 and older British name for the same band
+        if (std.mem.eql(u8, lower, "medium plasticity")) return .intermediate_plasticity;
         if (std.mem.eql(u8, lower, "high plasticity")) return .high_plasticity;
         if (std.mem.eql(u8, lower, "extremely high plasticity")) return .extremely_high_plasticity;
 
@@ -455,139 +919,1161 @@ pub const ParticleSize = enum {
     }
 };
 
-pub const SoilType = enum {
-    clay,
-    silt,
-    sand,
-    gravel,
-    cobbles,
-    boulders,
-    peat,
-    organic,
+/// How worn the corners of coarse particles are, most angular first
+pub const Angularity = enum {
+    angular,
+    subangular,
+    subrounded,
+    rounded,
+    well_rounded,
 
-    pub fn fromString(str: []const u8) ?SoilType {
+    /// Accepts "sub-angular", "sub angular" and "well-rounded" spellings
+    pub fn fromString(str: []const u8) ?Angularity {
         var lower_buf: [64]u8 = undefined;
         if (str.len >= lower_buf.len) return null;
 
         const lower = std.ascii.lowerString(lower_buf[0..str.len], str);
+        std.mem.replaceScalar(u8, lower, '-', ' ');
 
-        if (std.mem.eql(u8, lower, "clay")) return .clay;
-        if (std.mem.eql(u8, lower, "silt")) return .silt;
-        if (std.mem.eql(u8, lower, "sand")) return .sand;
-        if (std.mem.eql(u8, lower, "gravel")) return .gravel;
-        if (std.mem.eql(u8, lower, "cobbles")) return .cobbles;
-        if (std.mem.eql(u8, lower, "boulders")) return .boulders;
-        if (std.mem.eql(u8, lower, "peat")) return .peat;
-        if (std.mem.eql(u8, lower, "organic")) return .organic;
+        if (std.mem.eql(u8, lower, "angular")) return .angular;
+        if (std.mem.eql(u8, lower, "subangular") or std.mem.eql(u8, lower, "sub angular")) return .subangular;
+        if (std.mem.eql(u8, lower, "subrounded") or std.mem.eql(u8, lower, "sub rounded")) return .subrounded;
+        if (std.mem.eql(u8, lower, "rounded")) return .rounded;
+        if (std.mem.eql(u8, lower, "well rounded") or std.mem.eql(u8, lower, "wellrounded")) return .well_rounded;
 
         return null;
     }
 
-    pub fn toString(self: SoilType) []const u8 {
+    pub fn toString(self: Angularity) []const u8 {
         return switch (self) {
-            .clay => "CLAY",
-            .silt => "SILT",
-            .sand => "SAND",
-            .gravel => "GRAVEL",
-            .cobbles => "COBBLES",
-            .boulders => "BOULDERS",
-            .peat => "PEAT",
-            .organic => "ORGANIC",
+            .angular => "angular",
+            .subangular => "subangular",
+            .subrounded => "subrounded",
+            .rounded => "rounded",
+            .well_rounded => "well rounded",
         };
     }
+};
 
-    pub fn isCohesive(self: SoilType) bool {
-        return self == .clay or self == .silt;
+/// Overall proportions of coarse particles
+pub const ParticleForm = enum {
+    cubic,
+    flat,
+    elongate,
+    flat_and_elongate,
+
+    /// "Tabular" and "platy" are read as flat, "elongated" as elongate
+    pub fn fromString(str: []const u8) ?ParticleForm {
+        var lower_buf: [64]u8 = undefined;
+        if (str.len >= lower_buf.len) return null;
+
+        const lower = std.ascii.lowerString(lower_buf[0..str.len], str);
+
+        if (std.mem.eql(u8, lower, "cubic") or std.mem.eql(u8, lower, "equant")) return .cubic;
+        if (std.mem.eql(u8, lower, "flat") or std.mem.eql(u8, lower, "tabular") or std.mem.eql(u8, lower, "platy")) return .flat;
+        if (std.mem.eql(u8, lower, "elongate") or std.mem.eql(u8, lower, "elongated")) return .elongate;
+        if (std.mem.eql(u8, lower, "flat and elongate") or std.mem.eql(u8, lower, "flat and elongated")) return .flat_and_elongate;
+
+        return null;
     }
 
-    pub fn isGranular(self: SoilType) bool {
-        return self == .sand or self == .gravel or self == .cobbles or self == .boulders;
+    pub fn toString(self: ParticleForm) []const u8 {
+        return switch (self) {
+            .cubic => "cubic",
+            .flat => "flat",
+            .elongate => "elongate",
+            .flat_and_elongate => "flat and elongate",
+        };
     }
 };
 
-pub const SecondaryConstituent = struct {
-    amount: []const u8,
-    soil_type: []const u8,
+/// Shape of the gravel and coarser particles ("subangular to rounded",
+/// "subrounded flat")
+pub const ParticleShape = struct {
+    angularity: ?Angularity = null,
+    /// The rounder end of a range such as "subangular to rounded"
+    angularity_to: ?Angularity = null,
+    form: ?ParticleForm = null,
+
+    pub fn format(self: ParticleShape, comptime _: []const u8, _: std.fmt.FormatOptions, writer: anytype) !void {
+        if (self.angularity) |angularity| {
+            try writer.writeAll(angularity.toString());
+            if (self.angularity_to) |to| try writer.print(" to {s}", .{to.toString()});
+            if (self.form != null) try writer.writeAll(" ");
+        }
+        if (self.form) |form| try writer.writeAll(form.toString());
+    }
+};
 
-    // Keep the old Proportion enum for backward compatibility in parsing
-    pub const Proportion = enum {
-        slightly,
-        moderately,
-        very,
+/// How much plant structure survives in a peat
+pub const PeatFabric = enum {
+    fibrous,
+    pseudo_fibrous,
+    amorphous,
 
-        pub fn fromString(str: []const u8) ?Proportion {
-            var lower_buf: [64]u8 = undefined;
-            if (str.len >= lower_buf.len) return null;
+    /// Accepts "pseudo-fibrous", "pseudo fibrous" and "pseudofibrous"
+    pub fn fromString(str: []const u8) ?PeatFabric {
+        var lower_buf: [64]u8 = undefined;
+        if (str.len >= lower_buf.len) return null;
 
-            const lower = std.ascii.lowerString(lower_buf[0..str.len], str);
+        const lower = std.ascii.lowerString(lower_buf[0..str.len], str);
+        std.mem.replaceScalar(u8, lower, '-', ' ');
 
-            if (std.mem.eql(u8, lower, "slightly")) return .slightly;
-            if (std.mem.eql(u8, lower, "moderately")) return .moderately;
-            if (std.mem.eql(u8, lower, "very")) return .very;
+        if (std.mem.eql(u8, lower, "fibrous")) return .fibrous;
+        if (std.mem.eql(u8, lower, "pseudo fibrous") or std.mem.eql(u8, lower, "pseudofibrous")) return .pseudo_fibrous;
+        if (std.mem.eql(u8, lower, "amorphous")) return .amorphous;
 
-            return null;
-        }
+        return null;
+    }
 
-        pub fn toString(self: Proportion) []const u8 {
-            return switch (self) {
-                .slightly => "slightly",
-                .moderately => "moderately",
-                .very => "very",
-            };
-        }
-    };
+    pub fn toString(self: PeatFabric) []const u8 {
+        return switch (self) {
+            .fibrous => "fibrous",
+            .pseudo_fibrous => "pseudo-fibrous",
+            .amorphous => "amorphous",
+        };
+    }
+};
 
-    pub fn toString(self: SecondaryConstituent, allocator: std.mem.Allocator) ![]u8 {
-        return std.fmt.allocPrint(allocator, "{s} {s}", .{ self.amount, self.soil_type });
+/// Degree of humification on the von Post scale, from H1 (undecomposed) to
+/// H10 (completely decomposed)
+pub const VonPost = enum(u4) {
+    h1 = 1,
+    h2 = 2,
+    h3 = 3,
+    h4 = 4,
+    h5 = 5,
+    h6 = 6,
+    h7 = 7,
+    h8 = 8,
+    h9 = 9,
+    h10 = 10,
+
+    /// "H4" or "h4"
+    pub fn fromString(str: []const u8) ?VonPost {
+        if (str.len < 2 or std.ascii.toLower(str[0]) != 'h') return null;
+        const grade = std.fmt.parseInt(u4, str[1..], 10) catch return null;
+        if (grade < 1 or grade > 10) return null;
+        return @enumFromInt(grade);
+    }
+
+    pub fn toString(self: VonPost) []const u8 {
+        return switch (self) {
+            .h1 => "H1",
+            .h2 => "H2",
+            .h3 => "H3",
+            .h4 => "H4",
+            .h5 => "H5",
+            .h6 => "H6",
+            .h7 => "H7",
+            .h8 => "H8",
+            .h9 => "H9",
+            .h10 => "H10",
+        };
+    }
+
+    /// The fabric BS 5930 pairs with the grade: H1-H3 fibrous, H4-H6
+    /// pseudo-fibrous, H7-H10 amorphous
+    pub fn fabric(self: VonPost) PeatFabric {
+        return switch (@intFromEnum(self)) {
+            1...3 => .fibrous,
+            4...6 => .pseudo_fibrous,
+            else => .amorphous,
+        };
     }
 };
 
-pub const SpellingCorrection = struct {
-    original: []const u8,
-    corrected: []const u8,
-    similarity_score: f32,
+/// Peat qualifiers ("fibrous PEAT, H3")
+pub const PeatProperties = struct {
+    fabric: ?PeatFabric = null,
+    humification: ?VonPost = null,
+
+    /// False when the fabric term and von Post grade disagree
+    pub fn isConsistent(self: PeatProperties) bool {
+        const fabric = self.fabric orelse return true;
+        const humification = self.humification orelse return true;
+        return humification.fabric() == fabric;
+    }
+
+    pub fn format(self: PeatProperties, comptime _: []const u8, _: std.fmt.FormatOptions, writer: anytype) !void {
+        if (self.fabric) |fabric| {
+            try writer.writeAll(fabric.toString());
+            if (self.humification != null) try writer.writeAll(" ");
+        }
+        if (self.humification) |humification| try writer.writeAll(humification.toString());
+    }
 };
 
-pub const SoilDescription = struct {
-    raw_description: []const u8,
-    material_type: MaterialType,
-    // Soil properties
-    consistency: ?Consistency = null,
-    density: ?Density = null,
-    secondary_constituents: []SecondaryConstituent = &[_]SecondaryConstituent{},
-    primary_soil_type: ?SoilType = null,
-    secondary_primary_soil_type: ?SoilType = null,
-    geological_formation: ?[]const u8 = null,
-    is_made_ground: bool = false,
-    made_ground_label: ?[]const u8 = null,
-    // Rock properties
-    rock_strength: ?RockStrength = null,
-    weathering_grade: ?WeatheringGrade = null,
-    rock_structure: ?RockStructure = null,
-    primary_rock_type: ?RockType = null,
-    // Enhanced geological features
-    color: ?Color = null,
-    moisture_content: ?MoistureContent = null,
-    plasticity_index: ?PlasticityIndex = null,
-    particle_size: ?ParticleSize = null,
-    // Strength parameters
-    strength_parameters: ?StrengthParameters = null,
-    // Constituent guidance
-    constituent_guidance: ?ConstituentGuidance = null,
-    // Common properties
-    structure: ?[]const u8 = null,
-    confidence: f32 = 1.0,
-    warnings: [][]const u8 = &[_][]const u8{},
-    spelling_corrections: []SpellingCorrection = &[_]SpellingCorrection{},
-    is_valid: bool = true,
+/// What an anthropogenic (made ground) stratum is, for mine waste that is
+/// described by grading but is not natural soil
+pub const MaterialOrigin = enum {
+    tailings, // fine process residue from ore extraction
+    spoil, // excavated overburden and colliery or mine waste
+    slag, // smelting and furnace residue
 
-    pub fn deinit(self: SoilDescription, allocator: std.mem.Allocator) void {
+    pub fn fromString(str: []const u8) ?MaterialOrigin {
+        var lower_buf: [64]u8 = undefined;
+        if (str.len >= lower_buf.len) return null;
+
+        const lower = std.ascii.lowerString(lower_buf[0..str.len], str);
+
+        if (std.mem.eql(u8, lower, "tailings")) return .tailings;
+        if (std.mem.eql(u8, lower, "spoil")) return .spoil;
+        if (std.mem.eql(u8, lower, "slag")) return .slag;
+
+        return null;
+    }
+
+    pub fn toString(self: MaterialOrigin) []const u8 {
+        return switch (self) {
+            .tailings => "tailings",
+            .spoil => "spoil",
+            .slag => "slag",
+        };
+    }
+};
+
+/// Man-made material in made ground or fill ("with brick and concrete
+/// fragments"). Natural materials that also turn up in fill, such as coal or
+/// wood, are left out.
+pub const AnthropogenicMaterial = enum {
+    brick,
+    concrete,
+    mortar,
+    tile,
+    ceramic,
+    glass,
+    plastic,
+    metal,
+    timber,
+    asphalt,
+    ash,
+    clinker,
+    slag,
+    rubble,
+
+    /// Also accepts plurals and "pottery", "crockery", "cinders", "tarmac",
+    /// "bitumen" and "steel"
+    pub fn fromString(str: []const u8) ?AnthropogenicMaterial {
+        var lower_buf: [64]u8 = undefined;
+        if (str.len >= lower_buf.len) return null;
+
+        var lower: []const u8 = std.ascii.lowerString(lower_buf[0..str.len], str);
+        if (lower.len > 3 and lower[lower.len - 1] == 's' and lower[lower.len - 2] != 's') lower = lower[0 .. lower.len - 1];
+
+        if (std.mem.eql(u8, lower, "brick")) return .brick;
+        if (std.mem.eql(u8, lower, "concrete")) return .concrete;
+        if (std.mem.eql(u8, lower, "mortar")) return .mortar;
+        if (std.mem.eql(u8, lower, "tile")) return .tile;
+        if (std.mem.eql(u8, lower, "ceramic") or std.mem.eql(u8, lower, "pottery") or std.mem.eql(u8, lower, "crockery")) return .ceramic;
+        if (std.mem.eql(u8, lower, "glass")) return .glass;
+        if (std.mem.eql(u8, lower, "plastic")) return .plastic;
+        if (std.mem.eql(u8, lower, "metal") or std.mem.eql(u8, lower, "steel")) return .metal;
+        if (std.mem.eql(u8, lower, "timber")) return .timber;
+        if (std.mem.eql(u8, lower, "asphalt") or std.mem.eql(u8, lower, "tarmac") or std.mem.eql(u8, lower, "bitumen")) return .asphalt;
+        if (std.mem.eql(u8, lower, "ash")) return .ash;
+        if (std.mem.eql(u8, lower, "clinker") or std.mem.eql(u8, lower, "cinder")) return .clinker;
+        if (std.mem.eql(u8, lower, "slag")) return .slag;
+        if (std.mem.eql(u8, lower, "rubble")) return .rubble;
+
+        return null;
+    }
+
+    pub fn toString(self: AnthropogenicMaterial) []const u8 {
+        return @tagName(self);
+    }
+};
+
+/// Evidence that a soil was laid down in, or dredged from, the sea
+pub const MarineIndicator = enum {
+    shell_fragments,
+    h2s_odour, // hydrogen sulphide from anoxic organic sediment
+    coral,
+    foraminifera,
+    bioturbation,
+    carbonate, // biogenic carbonate grains ("CARBONATE SAND")
+
+    pub fn fromString(str: []const u8) ?MarineIndicator {
+        var lower_buf: [64]u8 = undefined;
+        if (str.len >= lower_buf.len) return null;
+
+        const lower = std.ascii.lowerString(lower_buf[0..str.len], str);
+
+        if (std.mem.eql(u8, lower, "shell fragments")) return .shell_fragments;
+        if (std.mem.eql(u8, lower, "h2s odour")) return .h2s_odour;
+        if (std.mem.eql(u8, lower, "coral")) return .coral;
+        if (std.mem.eql(u8, lower, "foraminifera")) return .foraminifera;
+        if (std.mem.eql(u8, lower, "bioturbation")) return .bioturbation;
+        if (std.mem.eql(u8, lower, "carbonate")) return .carbonate;
+
+        return null;
+    }
+
+    pub fn toString(self: MarineIndicator) []const u8 {
+        return switch (self) {
+            .shell_fragments => "shell fragments",
+            .h2s_odour => "H2S odour",
+            .coral => "coral",
+            .foraminifera => "foraminifera",
+            .bioturbation => "bioturbation",
+            .carbonate => "carbonate",
+        };
+    }
+};
+
+/// Signs of free gas or gas hydrate in recovered core. They mark an offshore
+/// geohazard, and gas expansion disturbs the sample.
+pub const GasIndicators = struct {
+    gas_blisters: bool = false,
+    expanded_core: bool = false,
+    gas_voids: bool = false, // "gas bubbles", "gas pockets"
+    hydrate_dissociation: bool = false,
+
+    /// Whether expanding gas has disturbed the sample, so its consistency or
+    /// density may not be the in situ one
+    pub fn disturbsCore(self: GasIndicators) bool {
+        return self.gas_blisters or self.expanded_core or self.gas_voids;
+    }
+};
+
+/// Sensitivity class of a clay or silt, following Norwegian (NGF) practice:
+/// the ratio of undisturbed to remoulded strength, with brittle and quick
+/// material identified by their remoulded strength
+pub const Sensitivity = enum {
+    low, // St < 8
+    medium, // St 8-30
+    high, // St > 30
+    brittle, // "sprøbruddmateriale": cr < 2 kPa and St >= 15
+    quick, // "kvikkleire": cr < 0.5 kPa
+
+    pub fn fromString(str: []const u8) ?Sensitivity {
+        var lower_buf: [64]u8 = undefined;
+        if (str.len >= lower_buf.len) return null;
+
+        const lower = std.ascii.lowerString(lower_buf[0..str.len], str);
+
+        if (std.mem.eql(u8, lower, "low")) return .low;
+        if (std.mem.eql(u8, lower, "medium")) return .medium;
+        if (std.mem.eql(u8, lower, "high")) return .high;
+        if (std.mem.eql(u8, lower, "brittle")) return .brittle;
+        if (std.mem.eql(u8, lower, "quick")) return .quick;
+
+        return null;
+    }
+
+    pub fn toString(self: Sensitivity) []const u8 {
+        return switch (self) {
+            .low => "low",
+            .medium => "medium",
+            .high => "high",
+            .brittle => "brittle",
+            .quick => "quick",
+        };
+    }
+};
+
+pub const SoilType = enum(u8) {
+    clay = 0,
+    silt = 1,
+    sand = 2,
+    gravel = 3,
+    cobbles = 4,
+    boulders = 5,
+    peat = 6,
+    organic = 7,
+
+    pub fn fromString(str: []const u8) ?SoilType {
+        var lower_buf: [64]u8 = undefined;
+        if (str.len >= lower_buf.len) return null;
+
+        const lower = std.ascii.lowerString(lower_buf[0..str.len], str);
+
+        if (std.mem.eql(u8, lower, "clay")) return .clay;
+        if (std.mem.eql(u8, lower, "silt")) return .silt;
+        if (std.mem.eql(u8, lower, "sand")) return .sand;
+        if (std.mem.eql(u8, lower, "gravel")) return .gravel;
+        if (std.mem.eql(u8, lower, "cobbles")) return .cobbles;
+        if (std.mem.eql(u8, lower, "boulders")) return .boulders;
+        if (std.mem.eql(u8, lower, "peat")) return .peat;
+        if (std.mem.eql(u8, lower, "organic")) return .organic;
+
+        return null;
+    }
+
+    /// Like `fromString`, but an unrecognised term is an error
+    pub fn parse(str: []const u8) !SoilType {
+        return fromString(str) orelse error.InvalidSoilType;
+    }
+
+    pub fn toString(self: SoilType) []const u8 {
+        return switch (self) {
+            .clay => "CLAY",
+            .silt => "SILT",
+            .sand => "SAND",
+            .gravel => "GRAVEL",
+            .cobbles => "COBBLES",
+            .boulders => "BOULDERS",
+            .peat => "PEAT",
+            .organic => "ORGANIC",
+        };
+    }
+
+    /// Fine soils whose strength is described by consistency and cu
+    pub fn isCohesive(self: SoilType) bool {
+        return self == .clay or self == .silt;
+    }
+
+    /// Coarse soils whose strength is described by density and SPT N
+    pub fn isGranular(self: SoilType) bool {
+        return self == .sand or self == .gravel or self == .cobbles or self == .boulders;
+    }
+};
+
+/// How often inclusions, very coarse particles or fossils occur. BS 5930
+/// leaves the terms undefined; litholog reads them as the share of the
+/// exposure or core and, for discrete items, the count per metre of core:
+///
+///   rare        under 1 %    1-2 per metre
+///   occasional  1-5 %        3-10 per metre
+///   frequent    5-20 %       10-30 per metre
+///   abundant    over 20 %    more than 30 per metre
+pub const Frequency = enum {
+    rare,
+    occasional,
+    frequent,
+    abundant,
+
+    /// Also accepts the informal "few", "some", "many", "numerous" and "common"
+    pub fn fromString(str: []const u8) ?Frequency {
+        var lower_buf: [64]u8 = undefined;
+        if (str.len >= lower_buf.len) return null;
+
+        const lower = std.ascii.lowerString(lower_buf[0..str.len], str);
+
+        if (std.mem.eql(u8, lower, "rare") or std.mem.eql(u8, lower, "few")) return .rare;
+        if (std.mem.eql(u8, lower, "occasional") or std.mem.eql(u8, lower, "some")) return .occasional;
+        if (std.mem.eql(u8, lower, "frequent") or std.mem.eql(u8, lower, "many") or
+            std.mem.eql(u8, lower, "numerous") or std.mem.eql(u8, lower, "common")) return .frequent;
+        if (std.mem.eql(u8, lower, "abundant")) return .abundant;
+
+        return null;
+    }
+
+    pub fn toString(self: Frequency) []const u8 {
+        return switch (self) {
+            .rare => "rare",
+            .occasional => "occasional",
+            .frequent => "frequent",
+            .abundant => "abundant",
+        };
+    }
+
+    /// Share of the soil or rock, in percent
+    pub fn percentage(self: Frequency) struct { min: f32, max: f32 } {
+        return switch (self) {
+            .rare => .{ .min = 0, .max = 1 },
+            .occasional => .{ .min = 1, .max = 5 },
+            .frequent => .{ .min = 5, .max = 20 },
+            .abundant => .{ .min = 20, .max = 100 },
+        };
+    }
+
+    /// Items per metre of core; abundant has no upper bound
+    pub fn perMetre(self: Frequency) struct { min: u32, max: ?u32 } {
+        return switch (self) {
+            .rare => .{ .min = 1, .max = 2 },
+            .occasional => .{ .min = 3, .max = 10 },
+            .frequent => .{ .min = 10, .max = 30 },
+            .abundant => .{ .min = 30, .max = null },
+        };
+    }
+};
+
+/// How a soil held in the main soil is arranged
+pub const InclusionForm = enum {
+    particles, // scattered through the soil ("with gravel", "with cobbles")
+    pockets,
+    lenses,
+    bands,
+    layers,
+    partings,
+    laminae,
+
+    /// Singular or plural ("pocket", "pockets"; "lamina", "laminae")
+    pub fn fromString(str: []const u8) ?InclusionForm {
+        var lower_buf: [64]u8 = undefined;
+        if (str.len >= lower_buf.len) return null;
+
+        const lower = std.ascii.lowerString(lower_buf[0..str.len], str);
+
+        if (std.mem.eql(u8, lower, "pocket") or std.mem.eql(u8, lower, "pockets")) return .pockets;
+        if (std.mem.eql(u8, lower, "lens") or std.mem.eql(u8, lower, "lenses")) return .lenses;
+        if (std.mem.eql(u8, lower, "band") or std.mem.eql(u8, lower, "bands")) return .bands;
+        if (std.mem.eql(u8, lower, "layer") or std.mem.eql(u8, lower, "layers")) return .layers;
+        if (std.mem.eql(u8, lower, "parting") or std.mem.eql(u8, lower, "partings")) return .partings;
+        if (std.mem.eql(u8, lower, "lamina") or std.mem.eql(u8, lower, "laminae")) return .laminae;
+        if (std.mem.eql(u8, lower, "particles")) return .particles;
+
+        return null;
+    }
+
+    pub fn toString(self: InclusionForm) []const u8 {
+        return switch (self) {
+            .particles => "particles",
+            .pockets => "pockets",
+            .lenses => "lenses",
+            .bands => "bands",
+            .layers => "layers",
+            .partings => "partings",
+            .laminae => "laminae",
+        };
+    }
+};
+
+/// Thickness qualifier of a band, parting or layer
+pub const Thickness = enum {
+    thin,
+    thick,
+
+    pub fn fromString(str: []const u8) ?Thickness {
+        if (std.ascii.eqlIgnoreCase(str, "thin") or std.ascii.eqlIgnoreCase(str, "thinly")) return .thin;
+        if (std.ascii.eqlIgnoreCase(str, "thick") or std.ascii.eqlIgnoreCase(str, "thickly")) return .thick;
+        return null;
+    }
+
+    pub fn toString(self: Thickness) []const u8 {
+        return switch (self) {
+            .thin => "thin",
+            .thick => "thick",
+        };
+    }
+};
+
+/// A soil held in the main soil, written after "with" ("with occasional
+/// cobbles and boulders", "with pockets of silt", "with thin sand partings")
+pub const Inclusion = struct {
+    frequency: ?Frequency = null,
+    thickness: ?Thickness = null,
+    form: InclusionForm = .particles,
+    /// How the included soil itself is described ("pockets of soft grey
+    /// silt", "laminae of fine sand")
+    consistency: ?Consistency = null,
+    color: ?Color = null,
+    particle_size: ?ParticleSize = null,
+    soil_type: SoilType,
+
+    /// BS 5930 wording, e.g. "occasional cobbles" or "thin partings of sand"
+    pub fn format(self: Inclusion, comptime _: []const u8, _: std.fmt.FormatOptions, writer: anytype) !void {
+        if (self.frequency) |frequency| try writer.print("{s} ", .{frequency.toString()});
+        if (self.thickness) |thickness| try writer.print("{s} ", .{thickness.toString()});
+        if (self.form != .particles) try writer.print("{s} of ", .{self.form.toString()});
+        if (self.consistency) |consistency| try writer.print("{s} ", .{consistency.toString()});
+        if (self.color) |color| try writer.print("{s} ", .{color.toString()});
+        if (self.particle_size) |size| try writer.print("{s} ", .{size.toString()});
+        try writer.writeAll(@tagName(self.soil_type));
+    }
+};
+
+/// Something named after "with" ("rare shell fragments", "occasional
+/// gravel"). Soils named there are also `Inclusion`s, which keep their form
+/// and description.
+pub const TertiaryConstituent = struct {
+    frequency: ?Frequency = null,
+    /// Lower case, as written; the soil type's name for a soil
+    name: []const u8,
+    /// Set when the constituent is a soil
+    soil_type: ?SoilType = null,
+
+    pub fn format(self: TertiaryConstituent, comptime _: []const u8, _: std.fmt.FormatOptions, writer: anytype) !void {
+        if (self.frequency) |frequency| try writer.print("{s} ", .{frequency.toString()});
+        try writer.writeAll(self.name);
+    }
+};
+
+/// A man-made material in made ground, with how much of it there is
+/// ("occasional brick")
+pub const MadeGroundConstituent = struct {
+    material: AnthropogenicMaterial,
+    frequency: ?Frequency = null,
+
+    pub fn format(self: MadeGroundConstituent, comptime _: []const u8, _: std.fmt.FormatOptions, writer: anytype) !void {
+        if (self.frequency) |frequency| try writer.print("{s} ", .{frequency.toString()});
+        try writer.writeAll(self.material.toString());
+    }
+};
+
+/// How the lithologies of a composite stratum alternate
+pub const CompositeArrangement = enum {
+    interbedded,
+    interlaminated,
+
+    pub fn fromString(str: []const u8) ?CompositeArrangement {
+        if (std.ascii.eqlIgnoreCase(str, "interbedded")) return .interbedded;
+        if (std.ascii.eqlIgnoreCase(str, "interlaminated")) return .interlaminated;
+        return null;
+    }
+
+    pub fn toString(self: CompositeArrangement) []const u8 {
+        return @tagName(self);
+    }
+};
+
+/// One lithology of a composite stratum and its share of the stratum
+pub const CompositeComponent = struct {
+    /// Exactly one of the two is set
+    rock_type: ?RockType = null,
+    soil_type: ?SoilType = null,
+    percent: u8,
+
+    /// "sandstone 60%"
+    pub fn format(self: CompositeComponent, comptime _: []const u8, _: std.fmt.FormatOptions, writer: anytype) !void {
+        if (self.rock_type) |rock_type| try writer.writeAll(rock_type.toString());
+        if (self.soil_type) |soil_type| try writer.writeAll(@tagName(soil_type));
+        try writer.print(" {d}%", .{self.percent});
+    }
+};
+
+/// A stratum of alternating lithologies ("Interbedded thinly bedded
+/// SANDSTONE and MUDSTONE", "CLAY interlaminated with SILT"). The primary
+/// type fields keep the first component.
+pub const CompositeDescription = struct {
+    arrangement: CompositeArrangement,
+    /// "thinly bedded", "thickly laminated"
+    thickness: ?Thickness = null,
+    /// In the order described; the percentages add up to 100
+    components: []CompositeComponent,
+    /// False when no proportions were given and the components were split
+    /// evenly
+    proportions_stated: bool = false,
+
+    /// "interbedded sandstone 70%, mudstone 30%"
+    pub fn format(self: CompositeDescription, comptime _: []const u8, _: std.fmt.FormatOptions, writer: anytype) !void {
+        try writer.writeAll(self.arrangement.toString());
+        for (self.components, 0..) |component, i| {
+            try writer.print("{s} {}", .{ if (i == 0) "" else ",", component });
+        }
+    }
+};
+
+/// Standard spacing band of a discontinuity set, in BS 5930 terms
+pub const SpacingTerm = enum {
+    extremely_close,
+    very_close,
+    close,
+    medium,
+    wide,
+    very_wide,
+    extremely_wide,
+
+    /// With or without "spaced" ("very closely", "widely spaced"); also
+    /// takes the ISO 14689 "close", "moderate" and "wide"
+    pub fn fromString(str: []const u8) ?SpacingTerm {
+        var lower_buf: [64]u8 = undefined;
+        if (str.len >= lower_buf.len) return null;
+
+        var lower = std.ascii.lowerString(lower_buf[0..str.len], str);
+        if (std.mem.endsWith(u8, lower, " spaced")) lower = lower[0 .. lower.len - " spaced".len];
+
+        if (std.mem.eql(u8, lower, "extremely closely") or std.mem.eql(u8, lower, "extremely close")) return .extremely_close;
+        if (std.mem.eql(u8, lower, "very closely") or std.mem.eql(u8, lower, "very close")) return .very_close;
+        if (std.mem.eql(u8, lower, "closely") or std.mem.eql(u8, lower, "close")) return .close;
+        if (std.mem.eql(u8, lower, "medium") or std.mem.eql(u8, lower, "moderately") or std.mem.eql(u8, lower, "moderate")) return .medium;
+        if (std.mem.eql(u8, lower, "widely") or std.mem.eql(u8, lower, "wide")) return .wide;
+        if (std.mem.eql(u8, lower, "very widely") or std.mem.eql(u8, lower, "very wide")) return .very_wide;
+        if (std.mem.eql(u8, lower, "extremely widely") or std.mem.eql(u8, lower, "extremely wide")) return .extremely_wide;
+
+        return null;
+    }
+
+    pub fn toString(self: SpacingTerm) []const u8 {
+        return switch (self) {
+            .extremely_close => "extremely closely spaced",
+            .very_close => "very closely spaced",
+            .close => "closely spaced",
+            .medium => "medium spaced",
+            .wide => "widely spaced",
+            .very_wide => "very widely spaced",
+            .extremely_wide => "extremely widely spaced",
+        };
+    }
+
+    /// Spacing in mm; extremely widely spaced has no upper bound
+    pub fn rangeMm(self: SpacingTerm) struct { min: u32, max: ?u32 } {
+        return switch (self) {
+            .extremely_close => .{ .min = 0, .max = 20 },
+            .very_close => .{ .min = 20, .max = 60 },
+            .close => .{ .min = 60, .max = 200 },
+            .medium => .{ .min = 200, .max = 600 },
+            .wide => .{ .min = 600, .max = 2000 },
+            .very_wide => .{ .min = 2000, .max = 6000 },
+            .extremely_wide => .{ .min = 6000, .max = null },
+        };
+    }
+
+    /// The band holding the whole of `[min, max]`, taking each band's lower
+    /// limit as inside it; null when the range crosses a band limit
+    pub fn fromMm(min: u32, max: ?u32) ?SpacingTerm {
+        for (std.enums.values(SpacingTerm)) |term| {
+            const band = term.rangeMm();
+            if (min < band.min) continue;
+            const band_max = band.max orelse return term;
+            const upper = max orelse continue;
+            if (min < band_max and upper <= band_max) return term;
+        }
+        return null;
+    }
+};
+
+/// Spacing of the joints, fissures or other discontinuities ("closely
+/// spaced joints", "fissures spaced 200-600 mm")
+pub const DiscontinuitySpacing = struct {
+    /// As written, or the band holding the stated range
+    term: ?SpacingTerm = null,
+    min_mm: u32,
+    /// Null when there is no upper bound ("extremely widely spaced")
+    max_mm: ?u32 = null,
+
+    /// Spacing rating of the RMR (Bieniawski 1989) rock mass
+    /// classification, from the closest spacing
+    pub fn rmrRating(self: DiscontinuitySpacing) u8 {
+        if (self.min_mm >= 2000) return 20;
+        if (self.min_mm >= 600) return 15;
+        if (self.min_mm >= 200) return 10;
+        if (self.min_mm >= 60) return 8;
+        return 5;
+    }
+
+    /// "closely spaced (60-200 mm)", "150-300 mm"
+    pub fn format(self: DiscontinuitySpacing, comptime _: []const u8, _: std.fmt.FormatOptions, writer: anytype) !void {
+        if (self.term) |term| try writer.print("{s} (", .{term.toString()});
+        if (self.max_mm) |max| {
+            if (max == self.min_mm) {
+                try writer.print("{d} mm", .{max});
+            } else {
+                try writer.print("{d}-{d} mm", .{ self.min_mm, max });
+            }
+        } else {
+            try writer.print("over {d} mm", .{self.min_mm});
+        }
+        if (self.term != null) try writer.writeAll(")");
+    }
+};
+
+/// State of the recovered material noted in brackets ("(recovered as
+/// non-intact)", "(possibly reworked)")
+pub const Condition = enum {
+    non_intact,
+    disturbed,
+    remoulded,
+    reworked,
+
+    pub fn fromString(str: []const u8) ?Condition {
+        var lower_buf: [64]u8 = undefined;
+        if (str.len >= lower_buf.len) return null;
+
+        const lower = std.ascii.lowerString(lower_buf[0..str.len], str);
+
+        if (std.mem.eql(u8, lower, "non-intact") or std.mem.eql(u8, lower, "non intact")) return .non_intact;
+        if (std.mem.eql(u8, lower, "disturbed")) return .disturbed;
+        if (std.mem.eql(u8, lower, "remoulded") or std.mem.eql(u8, lower, "remolded")) return .remoulded;
+        if (std.mem.eql(u8, lower, "reworked")) return .reworked;
+
+        return null;
+    }
+
+    pub fn toString(self: Condition) []const u8 {
+        return switch (self) {
+            .non_intact => "non-intact",
+            .disturbed => "disturbed",
+            .remoulded => "remoulded",
+            .reworked => "reworked",
+        };
+    }
+};
+
+/// A condition note, which the logger may hedge ("possibly reworked")
+pub const ConditionNote = struct {
+    condition: Condition,
+    tentative: bool = false,
+
+    /// "reworked" or "possibly reworked"
+    pub fn fromString(str: []const u8) ?ConditionNote {
+        if (std.ascii.startsWithIgnoreCase(str, "possibly ")) {
+            const condition = Condition.fromString(str["possibly ".len..]) orelse return null;
+            return ConditionNote{ .condition = condition, .tentative = true };
+        }
+        return ConditionNote{ .condition = Condition.fromString(str) orelse return null };
+    }
+
+    pub fn format(self: ConditionNote, comptime _: []const u8, _: std.fmt.FormatOptions, writer: anytype) !void {
+        if (self.tentative) try writer.writeAll("possibly ");
+        try writer.writeAll(self.condition.toString());
+    }
+};
+
+/// Another name for the material given in brackets ("CLAY (or SILT)",
+/// "MUDSTONE (recovered as CLAY)")
+pub const AlternativeType = union(enum) {
+    soil: SoilType,
+    rock: RockType,
+
+    pub fn fromString(str: []const u8) ?AlternativeType {
+        if (SoilType.fromString(str)) |soil_type| return .{ .soil = soil_type };
+        if (RockType.fromString(str)) |rock_type| return .{ .rock = rock_type };
+        return null;
+    }
+
+    pub fn toString(self: AlternativeType) []const u8 {
+        return switch (self) {
+            .soil => |soil_type| soil_type.toString(),
+            .rock => |rock_type| rock_type.toString(),
+        };
+    }
+};
+
+/// An explicitly stated absence ("no visible organic matter", "non-plastic").
+/// Recorded as a negative fact so the feature is not mistaken for a detection.
+pub const Absence = struct {
+    feature: []const u8, // "organic matter"
+    phrase: []const u8, // "no visible organic matter"
+};
+
+/// Where a field's value came from, so reports can separate what the logger
+/// wrote from what litholog worked out
+pub const Source = enum {
+    parsed, // read from the description text
+    inferred, // derived from other fields, e.g. strength from consistency
+    default, // nothing in the text; a fallback value was used
+    user_supplied, // set by the caller, e.g. loaded from JSON
+    measured, // lab or in-situ test result
+    fallback, // from the fallback parser, which replaced the rule-based result
+
+    pub fn toString(self: Source) []const u8 {
+        return switch (self) {
+            .parsed => "parsed",
+            .inferred => "inferred",
+            .default => "default",
+            .user_supplied => "user_supplied",
+            .measured => "measured",
+            .fallback => "fallback",
+        };
+    }
+};
+
+/// Description fields that carry a Source tag
+pub const Field = enum {
+    material_type,
+    consistency,
+    density,
+    secondary_constituents,
+    primary_soil_type,
+    secondary_primary_soil_type,
+    geological_formation,
+    made_ground_label,
+    rock_strength,
+    weathering_grade,
+    rock_structure,
+    primary_rock_type,
+    color,
+    secondary_color,
+    moisture_content,
+    plasticity_index,
+    particle_size,
+    strength_parameters,
+    constituent_guidance,
+    sensitivity,
+    karst_grade,
+    material_origin,
+    marine_indicators,
+    gas_indicators,
+    inclusions,
+    fossil_frequency,
+    condition_notes,
+    alternative_type,
+    colour_detail,
+    munsell_colour,
+    particle_shape,
+    peat_properties,
+    made_ground_constituents,
+    topsoil,
+    tertiary_constituents,
+    composite,
+    discontinuity_spacing,
+    boundary_advisory,
+};
+
+/// Byte range `[start, end)` of the words in the raw description that set a field
+pub const Span = struct {
+    start: usize,
+    end: usize,
+};
+
+pub const SecondaryConstituent = struct {
+    amount: []const u8,
+    soil_type: []const u8,
+
+    // Keep the old Proportion enum for backward compatibility in parsing
+    pub const Proportion = enum {
+        slightly,
+        moderately,
+        very,
+
+        pub fn fromString(str: []const u8) ?Proportion {
+            var lower_buf: [64]u8 = undefined;
+            if (str.len >= lower_buf.len) return null;
+
+            const lower = std.ascii.lowerString(lower_buf[0..str.len], str);
+
+            if (std.mem.eql(u8, lower, "slightly")) return .slightly;
+            if (std.mem.eql(u8, lower, "moderately")) return .moderately;
+            if (std.mem.eql(u8, lower, "very")) return .very;
+
+            return null;
+        }
+
+        pub fn toString(self: Proportion) []const u8 {
+            return switch (self) {
+                .slightly => "slightly",
+                .moderately => "moderately",
+                .very => "very",
+            };
+        }
+    };
+
+    pub fn toString(self: SecondaryConstituent, allocator: std.mem.Allocator) ![]u8 {
+        return std.fmt.allocPrint(allocator, "{s} {s}", .{ self.amount, self.soil_type });
+    }
+};
+
+pub const SpellingCorrection = struct {
+    original: []const u8,
+    corrected: []const u8,
+    similarity_score: f32,
+};
+
+/// The soil-only part of a description. A copy taken by `soil()`: setting a
+/// field here does not change the description, and `secondary_constituents`
+/// still belongs to the description it came from.
+pub const SoilProperties = struct {
+    consistency: ?Consistency = null,
+    density: ?Density = null,
+    primary_soil_type: ?SoilType = null,
+    secondary_primary_soil_type: ?SoilType = null,
+    secondary_constituents: []const SecondaryConstituent = &.{},
+    plasticity_index: ?PlasticityIndex = null,
+    particle_size: ?ParticleSize = null,
+    particle_shape: ?ParticleShape = null,
+    sensitivity: ?Sensitivity = null,
+    peat_properties: ?PeatProperties = null,
+};
+
+/// The rock-only part of a description. A copy taken by `rock()`; setting a
+/// field here does not change the description.
+pub const RockProperties = struct {
+    rock_strength: ?RockStrength = null,
+    weathering_grade: ?WeatheringGrade = null,
+    rock_structure: ?RockStructure = null,
+    discontinuity_spacing: ?DiscontinuitySpacing = null,
+    primary_rock_type: ?RockType = null,
+    karst_grade: ?KarstGrade = null,
+};
+
+/// A parsed soil or rock description. Fields that only apply to one material
+/// are null for the other; `soil()` and `rock()` group them by material.
+pub const GeologicalDescription = struct {
+    raw_description: []const u8,
+    material_type: MaterialType,
+    // Soil properties
+    consistency: ?Consistency = null,
+    density: ?Density = null,
+    /// In the order they appear in the description, which generated text keeps
+    secondary_constituents: []SecondaryConstituent = &[_]SecondaryConstituent{},
+    /// Soils named after "with", such as the cobbles and boulders of a till
+    inclusions: []Inclusion = &[_]Inclusion{},
+    /// Everything named after "with", soils included, such as shell fragments
+    /// or rootlets
+    tertiary_constituents: []TertiaryConstituent = &[_]TertiaryConstituent{},
+    primary_soil_type: ?SoilType = null,
+    secondary_primary_soil_type: ?SoilType = null,
+    geological_formation: ?[]const u8 = null,
+    /// Bracketed notes on the state of the sample ("(possibly reworked)")
+    condition_notes: []ConditionNote = &[_]ConditionNote{},
+    /// Bracketed alternative name ("(or SILT)", "(recovered as CLAY)")
+    alternative_type: ?AlternativeType = null,
+    is_made_ground: bool = false,
+    made_ground_label: ?[]const u8 = null,
+    /// The organic surface layer, natural ground that is stripped before
+    /// building; the soil fields give its texture
+    is_topsoil: bool = false,
+    /// Tailings, spoil or slag; such strata are also made ground
+    material_origin: ?MaterialOrigin = null,
+    /// Brick, concrete, ash and the like, in the order they are described
+    made_ground_constituents: []MadeGroundConstituent = &[_]MadeGroundConstituent{},
+    /// Quick, brittle or sensitive clay, which can flow when disturbed
+    sensitivity: ?Sensitivity = null,
+    // Rock properties
+    rock_strength: ?RockStrength = null,
+    weathering_grade: ?WeatheringGrade = null,
+    rock_structure: ?RockStructure = null,
+    /// Spacing of joints or fissures, as a term and in mm; see `rmrRating`
+    discontinuity_spacing: ?DiscontinuitySpacing = null,
+    primary_rock_type: ?RockType = null,
+    /// Dissolution grade of carbonate rock, stated or implied by features
+    /// such as solution cavities; see `voidRisk`
+    karst_grade: ?KarstGrade = null,
+    /// Interbedded or interlaminated lithologies, with their proportions
+    composite: ?CompositeDescription = null,
+    // Enhanced geological features
+    color: ?Color = null,
+    secondary_color: ?Color = null, // "grey/brown", "grey-brown"
+    /// The whole colour phrase with shade, tint and mottling; `color` keeps
+    /// the first colour word only
+    colour_detail: ?Colour = null,
+    /// Colour chart code given alongside the words ("brown (10YR 5/3)")
+    munsell_colour: ?MunsellColour = null,
+    moisture_content: ?MoistureContent = null,
+    plasticity_index: ?PlasticityIndex = null,
+    particle_size: ?ParticleSize = null,
+    /// Angularity and form of the coarse particles
+    particle_shape: ?ParticleShape = null,
+    /// Fabric and von Post humification of a peat
+    peat_properties: ?PeatProperties = null,
+    /// Shell fragments, H2S odour and other signs of a marine or dredged soil
+    marine_indicators: std.EnumSet(MarineIndicator) = .{},
+    /// Gas blisters, expanded core and hydrate dissociation notes
+    gas_indicators: ?GasIndicators = null,
+    /// "with frequent shell fragments"; see `Frequency` for the bands
+    fossil_frequency: ?Frequency = null,
+    // Strength parameters
+    strength_parameters: ?StrengthParameters = null,
+    /// Set for hard soils and very weak rocks, which may be taken as either
+    boundary_advisory: ?BoundaryAdvisory = null,
+    // Constituent guidance
+    constituent_guidance: ?ConstituentGuidance = null,
+    // Common properties
+    structure: ?[]const u8 = null,
+    confidence: f32 = 1.0,
+    warnings: [][]const u8 = &[_][]const u8{},
+    spelling_corrections: []SpellingCorrection = &[_]SpellingCorrection{},
+    absences: []Absence = &[_]Absence{},
+    is_valid: bool = true,
+    /// Provenance of each field that has a value
+    sources: std.EnumMap(Field, Source) = .{},
+    /// Where each parsed field was written in `raw_description`, for
+    /// highlighting. Fields built from several words span the first to the last.
+    spans: std.EnumMap(Field, Span) = .{},
+
+    pub fn sourceOf(self: *const GeologicalDescription, field: Field) ?Source {
+        return self.sources.get(field);
+    }
+
+    pub fn spanOf(self: *const GeologicalDescription, field: Field) ?Span {
+        return self.spans.get(field);
+    }
+
+    /// Record that `[start, end)` contributed to `field`, widening any existing span
+    pub fn markSpan(self: *GeologicalDescription, field: Field, start: usize, end: usize) void {
+        const span = if (self.spans.get(field)) |existing|
+            Span{ .start = @min(existing.start, start), .end = @max(existing.end, end) }
+        else
+            Span{ .start = start, .end = end };
+        self.spans.put(field, span);
+    }
+
+    /// Tag every field that has a value but no source yet
+    pub fn tagSources(self: *GeologicalDescription, source: Source) void {
+        for (std.enums.values(Field)) |field| {
+            if (self.sources.contains(field) or !self.hasField(field)) continue;
+            self.sources.put(field, source);
+        }
+    }
+
+    /// A copy of the soil properties, or null when the description is of rock
+    pub fn soil(self: *const GeologicalDescription) ?SoilProperties {
+        if (self.material_type != .soil) return null;
+        return SoilProperties{
+            .consistency = self.consistency,
+            .density = self.density,
+            .primary_soil_type = self.primary_soil_type,
+            .secondary_primary_soil_type = self.secondary_primary_soil_type,
+            .secondary_constituents = self.secondary_constituents,
+            .plasticity_index = self.plasticity_index,
+            .particle_size = self.particle_size,
+            .particle_shape = self.particle_shape,
+            .sensitivity = self.sensitivity,
+            .peat_properties = self.peat_properties,
+        };
+    }
+
+    /// A copy of the rock properties, or null when the description is of soil
+    pub fn rock(self: *const GeologicalDescription) ?RockProperties {
+        if (self.material_type != .rock) return null;
+        return RockProperties{
+            .rock_strength = self.rock_strength,
+            .weathering_grade = self.weathering_grade,
+            .rock_structure = self.rock_structure,
+            .discontinuity_spacing = self.discontinuity_spacing,
+            .primary_rock_type = self.primary_rock_type,
+            .karst_grade = self.karst_grade,
+        };
+    }
+
+    pub fn hasField(self: *const GeologicalDescription, field: Field) bool {
+        return switch (field) {
+            .material_type => true,
+            .consistency => self.consistency != null,
+            .density => self.density != null,
+            .secondary_constituents => self.secondary_constituents.len > 0,
+            .primary_soil_type => self.primary_soil_type != null,
+            .secondary_primary_soil_type => self.secondary_primary_soil_type != null,
+            .geological_formation => self.geological_formation != null,
+            .made_ground_label => self.made_ground_label != null,
+            .rock_strength => self.rock_strength != null,
+            .weathering_grade => self.weathering_grade != null,
+            .rock_structure => self.rock_structure != null,
+            .primary_rock_type => self.primary_rock_type != null,
+            .color => self.color != null,
+            .secondary_color => self.secondary_color != null,
+            .moisture_content => self.moisture_content != null,
+            .plasticity_index => self.plasticity_index != null,
+            .particle_size => self.particle_size != null,
+            .strength_parameters => self.strength_parameters != null,
+            .constituent_guidance => self.constituent_guidance != null,
+            .sensitivity => self.sensitivity != null,
+            .karst_grade => self.karst_grade != null,
+            .material_origin => self.material_origin != null,
+            .marine_indicators => self.marine_indicators.count() > 0,
+            .gas_indicators => self.gas_indicators != null,
+            .inclusions => self.inclusions.len > 0,
+            .fossil_frequency => self.fossil_frequency != null,
+            .condition_notes => self.condition_notes.len > 0,
+            .alternative_type => self.alternative_type != null,
+            .colour_detail => self.colour_detail != null,
+            .munsell_colour => self.munsell_colour != null,
+            .particle_shape => self.particle_shape != null,
+            .peat_properties => self.peat_properties != null,
+            .made_ground_constituents => self.made_ground_constituents.len > 0,
+            .topsoil => self.is_topsoil,
+            .tertiary_constituents => self.tertiary_constituents.len > 0,
+            .composite => self.composite != null,
+            .discontinuity_spacing => self.discontinuity_spacing != null,
+            .boundary_advisory => self.boundary_advisory != null,
+        };
+    }
+
+    pub fn deinit(self: GeologicalDescription, allocator: std.mem.Allocator) void {
         allocator.free(self.raw_description);
         for (self.secondary_constituents) |sc| {
             allocator.free(sc.amount);
             allocator.free(sc.soil_type);
         }
         allocator.free(self.secondary_constituents);
+        allocator.free(self.inclusions);
+        for (self.tertiary_constituents) |constituent| allocator.free(constituent.name);
+        allocator.free(self.tertiary_constituents);
+        allocator.free(self.made_ground_constituents);
+        if (self.composite) |composite| allocator.free(composite.components);
+        allocator.free(self.condition_notes);
         if (self.geological_formation) |formation| allocator.free(formation);
         if (self.made_ground_label) |label| allocator.free(label);
 
@@ -604,115 +2090,433 @@ pub const SoilDescription = struct {
         }
         allocator.free(self.spelling_corrections);
 
+        for (self.absences) |item| {
+            allocator.free(item.feature);
+            allocator.free(item.phrase);
+        }
+        allocator.free(self.absences);
+
         // Free constituent guidance if present
         if (self.constituent_guidance) |guidance| {
             guidance.deinit(allocator);
         }
     }
 
-    pub fn toJson(self: SoilDescription, allocator: std.mem.Allocator) ![]u8 {
+    /// Top-level keys in the order `toJson` writes them. Absent fields are
+    /// skipped, but present keys never change order between runs or
+    /// releases; new keys are only ever added, so diffs of stored output
+    /// show real changes.
+    pub const json_key_order = [_][]const u8{
+        "raw_description",
+        "material_type",
+        "consistency",
+        "density",
+        "primary_soil_type",
+        "secondary_primary_soil_type",
+        "geological_formation",
+        "alternative_type",
+        "condition_notes",
+        "is_made_ground",
+        "made_ground_label",
+        "is_topsoil",
+        "material_origin",
+        "rock_strength",
+        "weathering_grade",
+        "rock_structure",
+        "discontinuity_spacing",
+        "primary_rock_type",
+        "karst_grade",
+        "composite",
+        "color",
+        "secondary_color",
+        "colour_detail",
+        "munsell_colour",
+        "moisture_content",
+        "plasticity_index",
+        "particle_size",
+        "particle_shape",
+        "sensitivity",
+        "peat_properties",
+        "marine_indicators",
+        "gas_indicators",
+        "fossil_frequency",
+        "strength_parameter_type",
+        "strength_parameter_units",
+        "strength_lower_bound",
+        "strength_upper_bound",
+        "strength_typical_value",
+        "strength_confidence",
+        "strength_provenance",
+        "strength_groundwater_adjustment",
+        "intermediate_geomaterial",
+        "strength_alternate_type",
+        "strength_alternate_units",
+        "strength_alternate_lower_bound",
+        "strength_alternate_upper_bound",
+        "strength_alternate_provenance",
+        "boundary_advisory",
+        "constituent_proportions",
+        "constituent_confidence",
+        "secondary_constituents",
+        "inclusions",
+        "tertiary_constituents",
+        "made_ground_constituents",
+        "absences",
+        "sources",
+        "spans",
+        "warnings",
+        "confidence",
+        "is_valid",
+    };
+
+    /// Output formatting for `toJsonWithOptions`
+    pub const JsonOptions = struct {
+        /// Fields to write, or null for all; see `toJsonWith`
+        fields: ?[]const Field = null,
+        /// Spaces per nesting level, or null for compact single-line output
+        indent: ?u8 = null,
+        /// Decimal places for strength values and confidences
+        precision: u8 = 2,
+        /// Write absent optional fields as null rather than leaving them out,
+        /// for consumers that expect a fixed set of keys
+        include_nulls: bool = false,
+    };
+
+    /// Compact JSON with keys in `json_key_order`; `sources` and `spans`
+    /// follow `Field` order
+    pub fn toJson(self: GeologicalDescription, allocator: std.mem.Allocator) ![]u8 {
+        return self.toJsonWithOptions(allocator, .{});
+    }
+
+    /// Like `toJson` but with only the listed fields, e.g. everything except
+    /// `.strength_parameters` for sharing outside the project. The raw
+    /// description, absences, warnings, confidence and validity are always
+    /// included; `sources` and `spans` cover just the listed fields.
+    pub fn toJsonWith(self: GeologicalDescription, allocator: std.mem.Allocator, fields: []const Field) ![]u8 {
+        return self.toJsonWithOptions(allocator, .{ .fields = fields });
+    }
+
+    /// JSON formatted by `options`, e.g. `.{ .indent = 2, .precision = 1 }`
+    /// for human-readable exports. Keys keep the `toJson` order.
+    pub fn toJsonWithOptions(self: GeologicalDescription, allocator: std.mem.Allocator, options: JsonOptions) ![]u8 {
         var result = std.ArrayList(u8).init(allocator);
-        var writer = result.writer();
+        errdefer result.deinit();
+        try self.writeJson(result.writer(), options);
+
+        const compact = try result.toOwnedSlice();
+        const indent = options.indent orelse return compact;
+        defer allocator.free(compact);
+        return indentJson(allocator, compact, indent);
+    }
+
+    fn writeJson(self: GeologicalDescription, writer: anytype, options: JsonOptions) !void {
+        var include = std.EnumSet(Field).initFull();
+        if (options.fields) |fields| {
+            include = std.EnumSet(Field).initEmpty();
+            for (fields) |field| include.insert(field);
+        }
+        const out = JsonFieldWriter(@TypeOf(writer)){
+            .writer = writer,
+            .include = include,
+            .include_nulls = options.include_nulls,
+        };
+        const precision = options.precision;
 
         try writer.writeAll("{");
 
         try writer.print("\"raw_description\":\"{s}\"", .{self.raw_description});
-        try writer.print(",\"material_type\":\"{s}\"", .{self.material_type.toString()});
-
-        if (self.consistency) |c| {
-            try writer.print(",\"consistency\":\"{s}\"", .{c.toString()});
+        try out.value(.material_type, self.material_type);
+
+        try out.value(.consistency, self.consistency);
+        try out.value(.density, self.density);
+        try out.value(.primary_soil_type, self.primary_soil_type);
+        try out.value(.secondary_primary_soil_type, self.secondary_primary_soil_type);
+        try out.value(.geological_formation, self.geological_formation);
+        try out.value(.alternative_type, self.alternative_type);
+        if (include.contains(.condition_notes)) {
+            if (self.condition_notes.len > 0) {
+                try writer.writeAll(",\"condition_notes\":[");
+                for (self.condition_notes, 0..) |note, i| {
+                    if (i > 0) try writer.writeAll(",");
+                    try writer.print("\"{}\"", .{note});
+                }
+                try writer.writeAll("]");
+            } else if (options.include_nulls) {
+                try writer.writeAll(",\"condition_notes\":null");
+            }
         }
-
-        if (self.density) |d| {
-            try writer.print(",\"density\":\"{s}\"", .{d.toString()});
+        if (include.contains(.made_ground_label) and self.is_made_ground) {
+            try writer.writeAll(",\"is_made_ground\":true");
         }
-
-        if (self.primary_soil_type) |pst| {
-            try writer.print(",\"primary_soil_type\":\"{s}\"", .{pst.toString()});
+        try out.value(.made_ground_label, self.made_ground_label);
+        if (include.contains(.topsoil) and self.is_topsoil) {
+            try writer.writeAll(",\"is_topsoil\":true");
+        }
+        try out.value(.material_origin, self.material_origin);
+
+        try out.value(.rock_strength, self.rock_strength);
+        try out.value(.weathering_grade, self.weathering_grade);
+        try out.value(.rock_structure, self.rock_structure);
+        if (include.contains(.discontinuity_spacing)) {
+            if (self.discontinuity_spacing) |spacing| {
+                try writer.writeAll(",\"discontinuity_spacing\":{");
+                if (spacing.term) |term| try writer.print("\"term\":\"{s}\",", .{term.toString()});
+                try writer.print("\"min_mm\":{d}", .{spacing.min_mm});
+                if (spacing.max_mm) |max| try writer.print(",\"max_mm\":{d}", .{max});
+                try writer.writeAll("}");
+            } else if (options.include_nulls) {
+                try writer.writeAll(",\"discontinuity_spacing\":null");
+            }
         }
-        if (self.secondary_primary_soil_type) |spst| {
-            try writer.print(",\"secondary_primary_soil_type\":\"{s}\"", .{spst.toString()});
+        try out.value(.primary_rock_type, self.primary_rock_type);
+        try out.value(.karst_grade, self.karst_grade);
+        if (include.contains(.composite)) {
+            if (self.composite) |composite| {
+                try writer.print(",\"composite\":{{\"arrangement\":\"{s}\",", .{composite.arrangement.toString()});
+                if (composite.thickness) |thickness| try writer.print("\"thickness\":\"{s}\",", .{thickness.toString()});
+                try writer.writeAll("\"components\":[");
+                for (composite.components, 0..) |component, i| {
+                    if (i > 0) try writer.writeAll(",");
+                    try writer.writeAll("{");
+                    if (component.rock_type) |rock_type| try writer.print("\"rock_type\":\"{s}\",", .{rock_type.toString()});
+                    if (component.soil_type) |soil_type| try writer.print("\"soil_type\":\"{s}\",", .{@tagName(soil_type)});
+                    try writer.print("\"percent\":{d}}}", .{component.percent});
+                }
+                try writer.print("],\"proportions_stated\":{s}}}", .{if (composite.proportions_stated) "true" else "false"});
+            } else if (options.include_nulls) {
+                try writer.writeAll(",\"composite\":null");
+            }
         }
-        if (self.geological_formation) |formation| {
-            try writer.print(",\"geological_formation\":\"{s}\"", .{formation});
+
+        // Add enhanced geological features to JSON
+        try out.value(.color, self.color);
+        try out.value(.secondary_color, self.secondary_color);
+        if (include.contains(.colour_detail)) {
+            if (self.colour_detail) |colour| {
+                try writer.writeAll(",\"colour_detail\":{");
+                try writeToneFields(writer, colour.tone());
+                if (colour.mottling) |mottles| {
+                    try writer.writeAll(",\"mottling\":{");
+                    try writeToneFields(writer, mottles);
+                    try writer.writeAll("}");
+                }
+                try writer.writeAll("}");
+            } else if (options.include_nulls) {
+                try writer.writeAll(",\"colour_detail\":null");
+            }
         }
-        if (self.is_made_ground) {
-            try writer.writeAll(",\"is_made_ground\":true");
+        if (include.contains(.munsell_colour)) {
+            if (self.munsell_colour) |munsell| {
+                try writer.print(",\"munsell_colour\":\"{}\"", .{munsell});
+            } else if (options.include_nulls) {
+                try writer.writeAll(",\"munsell_colour\":null");
+            }
         }
-        if (self.made_ground_label) |label| {
-            try writer.print(",\"made_ground_label\":\"{s}\"", .{label});
+        try out.value(.moisture_content, self.moisture_content);
+        try out.value(.plasticity_index, self.plasticity_index);
+        try out.value(.particle_size, self.particle_size);
+        if (include.contains(.particle_shape)) {
+            if (self.particle_shape) |shape| {
+                try writer.writeAll(",\"particle_shape\":{");
+                var first = true;
+                inline for (.{ "angularity", "angularity_to", "form" }) |key| {
+                    if (@field(shape, key)) |value| {
+                        if (!first) try writer.writeAll(",");
+                        try writer.print("\"" ++ key ++ "\":\"{s}\"", .{value.toString()});
+                        first = false;
+                    }
+                }
+                try writer.writeAll("}");
+            } else if (options.include_nulls) {
+                try writer.writeAll(",\"particle_shape\":null");
+            }
         }
-
-        if (self.rock_strength) |rs| {
-            try writer.print(",\"rock_strength\":\"{s}\"", .{rs.toString()});
+        try out.value(.sensitivity, self.sensitivity);
+        if (include.contains(.peat_properties)) {
+            if (self.peat_properties) |peat| {
+                try writer.writeAll(",\"peat_properties\":{");
+                var first = true;
+                inline for (.{ "fabric", "humification" }) |key| {
+                    if (@field(peat, key)) |value| {
+                        if (!first) try writer.writeAll(",");
+                        try writer.print("\"" ++ key ++ "\":\"{s}\"", .{value.toString()});
+                        first = false;
+                    }
+                }
+                try writer.writeAll("}");
+            } else if (options.include_nulls) {
+                try writer.writeAll(",\"peat_properties\":null");
+            }
         }
-
-        if (self.weathering_grade) |wg| {
-            try writer.print(",\"weathering_grade\":\"{s}\"", .{wg.toString()});
+        if (include.contains(.marine_indicators)) {
+            if (self.marine_indicators.count() > 0) {
+                try writer.writeAll(",\"marine_indicators\":[");
+                var indicators = self.marine_indicators.iterator();
+                var first = true;
+                while (indicators.next()) |indicator| {
+                    if (!first) try writer.writeAll(",");
+                    first = false;
+                    try writer.print("\"{s}\"", .{indicator.toString()});
+                }
+                try writer.writeAll("]");
+            } else if (options.include_nulls) {
+                try writer.writeAll(",\"marine_indicators\":null");
+            }
         }
-
-        if (self.rock_structure) |rs| {
-            try writer.print(",\"rock_structure\":\"{s}\"", .{rs.toString()});
+        if (include.contains(.gas_indicators)) {
+            if (self.gas_indicators) |gas| {
+                try writer.print(",\"gas_indicators\":{{\"gas_blisters\":{},\"expanded_core\":{},\"gas_voids\":{},\"hydrate_dissociation\":{}}}", .{ gas.gas_blisters, gas.expanded_core, gas.gas_voids, gas.hydrate_dissociation });
+            } else if (options.include_nulls) {
+                try writer.writeAll(",\"gas_indicators\":null");
+            }
         }
+        try out.value(.fossil_frequency, self.fossil_frequency);
 
-        if (self.primary_rock_type) |prt| {
-            try writer.print(",\"primary_rock_type\":\"{s}\"", .{prt.toString()});
+        // Add strength parameters to JSON
+        if (include.contains(.strength_parameters)) {
+            if (self.strength_parameters) |sp| {
+                try writer.print(",\"strength_parameter_type\":\"{s}\"", .{sp.parameter_type.toString()});
+                try writer.print(",\"strength_parameter_units\":\"{s}\"", .{sp.parameter_type.getUnits()});
+                try writer.print(",\"strength_lower_bound\":{d:.[1]}", .{ sp.range.lower_bound, precision });
+                try writer.print(",\"strength_upper_bound\":{d:.[1]}", .{ sp.range.upper_bound, precision });
+                const typical = sp.range.typical_value orelse sp.range.getMidpoint();
+                try writer.print(",\"strength_typical_value\":{d:.[1]}", .{ typical, precision });
+                try writer.print(",\"strength_confidence\":{d:.[1]}", .{ sp.confidence, precision });
+                try writer.print(",\"strength_provenance\":\"{s}\"", .{sp.provenance.toString()});
+                if (sp.groundwater_adjustment) |adjustment| {
+                    try writer.print(",\"strength_groundwater_adjustment\":\"{s}\"", .{adjustment.toString()});
+                } else if (options.include_nulls) {
+                    try writer.writeAll(",\"strength_groundwater_adjustment\":null");
+                }
+                if (sp.alternate) |alt| {
+                    try writer.writeAll(",\"intermediate_geomaterial\":true");
+                    try writer.print(",\"strength_alternate_type\":\"{s}\"", .{alt.parameter_type.toString()});
+                    try writer.print(",\"strength_alternate_units\":\"{s}\"", .{alt.parameter_type.getUnits()});
+                    try writer.print(",\"strength_alternate_lower_bound\":{d:.[1]}", .{ alt.range.lower_bound, precision });
+                    try writer.print(",\"strength_alternate_upper_bound\":{d:.[1]}", .{ alt.range.upper_bound, precision });
+                    try writer.print(",\"strength_alternate_provenance\":\"{s}\"", .{alt.provenance.toString()});
+                }
+            } else if (options.include_nulls) {
+                try writer.writeAll(",\"strength_parameter_type\":null,\"strength_parameter_units\":null");
+                try writer.writeAll(",\"strength_lower_bound\":null,\"strength_upper_bound\":null,\"strength_typical_value\":null");
+                try writer.writeAll(",\"strength_confidence\":null,\"strength_provenance\":null");
+            }
+        }
+        if (include.contains(.boundary_advisory)) {
+            if (self.boundary_advisory) |advisory| {
+                try writer.print(",\"boundary_advisory\":{{\"described_as\":\"{s}\"", .{advisory.described_as.toString()});
+                try writer.print(",\"cu_lower_bound\":{d:.[1]},\"cu_upper_bound\":{d:.[1]}", .{ advisory.as_soil.lower_bound, precision, advisory.as_soil.upper_bound, precision });
+                try writer.print(",\"ucs_lower_bound\":{d:.[1]},\"ucs_upper_bound\":{d:.[1]}}}", .{ advisory.as_rock.lower_bound, precision, advisory.as_rock.upper_bound, precision });
+            } else if (options.include_nulls) {
+                try writer.writeAll(",\"boundary_advisory\":null");
+            }
         }
 
-        // Add enhanced geological features to JSON
-        if (self.color) |color| {
-            try writer.print(",\"color\":\"{s}\"", .{color.toString()});
+        // Add constituent guidance to JSON
+        if (include.contains(.constituent_guidance)) {
+            if (self.constituent_guidance) |cg| {
+                try writer.writeAll(",\"constituent_proportions\":[");
+                for (cg.constituents, 0..) |constituent, i| {
+                    if (i > 0) try writer.writeAll(",");
+                    const typical = if (constituent.range.typical_value) |tv| tv else constituent.range.getMidpoint();
+                    try writer.print("{{\"soil_type\":\"{s}\",\"percentage_range\":\"{d:.0}-{d:.0}\",\"typical_percentage\":{d:.0}}}", .{
+                        constituent.soil_type,
+                        constituent.range.lower_bound,
+                        constituent.range.upper_bound,
+                        typical,
+                    });
+                }
+                try writer.writeAll("]");
+                try writer.print(",\"constituent_confidence\":{d:.[1]}", .{ cg.confidence, precision });
+            } else if (options.include_nulls) {
+                try writer.writeAll(",\"constituent_proportions\":null,\"constituent_confidence\":null");
+            }
         }
 
-        if (self.moisture_content) |moisture| {
-            try writer.print(",\"moisture_content\":\"{s}\"", .{moisture.toString()});
+        if (include.contains(.secondary_constituents)) {
+            try writer.writeAll(",\"secondary_constituents\":[");
+            for (self.secondary_constituents, 0..) |sc, i| {
+                if (i > 0) try writer.writeAll(",");
+                try writer.print("{{\"amount\":\"{s}\",\"soil_type\":\"{s}\"}}", .{ sc.amount, sc.soil_type });
+            }
+            try writer.writeAll("]");
         }
 
-        if (self.plasticity_index) |plasticity| {
-            try writer.print(",\"plasticity_index\":\"{s}\"", .{plasticity.toString()});
+        if (include.contains(.inclusions) and self.inclusions.len > 0) {
+            try writer.writeAll(",\"inclusions\":[");
+            for (self.inclusions, 0..) |inclusion, i| {
+                if (i > 0) try writer.writeAll(",");
+                try writer.writeAll("{");
+                if (inclusion.frequency) |frequency| try writer.print("\"frequency\":\"{s}\",", .{frequency.toString()});
+                if (inclusion.thickness) |thickness| try writer.print("\"thickness\":\"{s}\",", .{thickness.toString()});
+                try writer.print("\"form\":\"{s}\",", .{inclusion.form.toString()});
+                if (inclusion.consistency) |consistency| try writer.print("\"consistency\":\"{s}\",", .{consistency.toString()});
+                if (inclusion.color) |color| try writer.print("\"color\":\"{s}\",", .{color.toString()});
+                if (inclusion.particle_size) |size| try writer.print("\"particle_size\":\"{s}\",", .{size.toString()});
+                try writer.print("\"soil_type\":\"{s}\"}}", .{@tagName(inclusion.soil_type)});
+            }
+            try writer.writeAll("]");
         }
 
-        if (self.particle_size) |particle_size| {
-            try writer.print(",\"particle_size\":\"{s}\"", .{particle_size.toString()});
+        if (include.contains(.tertiary_constituents) and self.tertiary_constituents.len > 0) {
+            try writer.writeAll(",\"tertiary_constituents\":[");
+            for (self.tertiary_constituents, 0..) |constituent, i| {
+                if (i > 0) try writer.writeAll(",");
+                try writer.writeAll("{");
+                if (constituent.frequency) |frequency| try writer.print("\"frequency\":\"{s}\",", .{frequency.toString()});
+                try writer.writeAll("\"name\":");
+                try std.json.stringify(constituent.name, .{}, writer);
+                if (constituent.soil_type) |soil_type| try writer.print(",\"soil_type\":\"{s}\"", .{@tagName(soil_type)});
+                try writer.writeAll("}");
+            }
+            try writer.writeAll("]");
         }
 
-        // Add strength parameters to JSON
-        if (self.strength_parameters) |sp| {
-            try writer.print(",\"strength_parameter_type\":\"{s}\"", .{sp.parameter_type.toString()});
-            try writer.print(",\"strength_parameter_units\":\"{s}\"", .{sp.parameter_type.getUnits()});
-            try writer.print(",\"strength_lower_bound\":{d:.2}", .{sp.range.lower_bound});
-            try writer.print(",\"strength_upper_bound\":{d:.2}", .{sp.range.upper_bound});
-            if (sp.range.typical_value) |tv| {
-                try writer.print(",\"strength_typical_value\":{d:.2}", .{tv});
-            } else {
-                try writer.print(",\"strength_typical_value\":{d:.2}", .{sp.range.getMidpoint()});
+        if (include.contains(.made_ground_constituents) and self.made_ground_constituents.len > 0) {
+            try writer.writeAll(",\"made_ground_constituents\":[");
+            for (self.made_ground_constituents, 0..) |constituent, i| {
+                if (i > 0) try writer.writeAll(",");
+                try writer.writeAll("{");
+                if (constituent.frequency) |frequency| try writer.print("\"frequency\":\"{s}\",", .{frequency.toString()});
+                try writer.print("\"material\":\"{s}\"}}", .{constituent.material.toString()});
             }
-            try writer.print(",\"strength_confidence\":{d:.2}", .{sp.confidence});
+            try writer.writeAll("]");
         }
 
-        // Add constituent guidance to JSON
-        if (self.constituent_guidance) |cg| {
-            try writer.writeAll(",\"constituent_proportions\":[");
-            for (cg.constituents, 0..) |constituent, i| {
+        if (self.absences.len > 0) {
+            try writer.writeAll(",\"absences\":[");
+            for (self.absences, 0..) |item, i| {
                 if (i > 0) try writer.writeAll(",");
-                const typical = if (constituent.range.typical_value) |tv| tv else constituent.range.getMidpoint();
-                try writer.print("{{\"soil_type\":\"{s}\",\"percentage_range\":\"{d:.0}-{d:.0}\",\"typical_percentage\":{d:.0}}}", .{
-                    constituent.soil_type,
-                    constituent.range.lower_bound,
-                    constituent.range.upper_bound,
-                    typical,
-                });
+                try writer.print("{{\"feature\":\"{s}\",\"phrase\":\"{s}\"}}", .{ item.feature, item.phrase });
             }
             try writer.writeAll("]");
-            try writer.print(",\"constituent_confidence\":{d:.2}", .{cg.confidence});
         }
 
-        try writer.writeAll(",\"secondary_constituents\":[");
-        for (self.secondary_constituents, 0..) |sc, i| {
-            if (i > 0) try writer.writeAll(",");
-            try writer.print("{{\"amount\":\"{s}\",\"soil_type\":\"{s}\"}}", .{ sc.amount, sc.soil_type });
+        if (self.sources.count() > 0) {
+            try writer.writeAll(",\"sources\":{");
+            var first = true;
+            for (std.enums.values(Field)) |field| {
+                if (!include.contains(field)) continue;
+                const source = self.sources.get(field) orelse continue;
+                if (!first) try writer.writeAll(",");
+                first = false;
+                try writer.print("\"{s}\":\"{s}\"", .{ @tagName(field), source.toString() });
+            }
+            try writer.writeAll("}");
+        }
+
+        if (self.spans.count() > 0) {
+            try writer.writeAll(",\"spans\":{");
+            var first = true;
+            for (std.enums.values(Field)) |field| {
+                if (!include.contains(field)) continue;
+                const span = self.spans.get(field) orelse continue;
+                if (!first) try writer.writeAll(",");
+                first = false;
+                try writer.print("\"{s}\":[{d},{d}]", .{ @tagName(field), span.start, span.end });
+            }
+            try writer.writeAll("}");
         }
-        try writer.writeAll("]");
 
         try writer.writeAll(",\"warnings\":[");
         for (self.warnings, 0..) |warning, i| {
@@ -721,16 +2525,100 @@ pub const SoilDescription = struct {
         }
         try writer.writeAll("]");
 
-        try writer.print(",\"confidence\":{d:.2}", .{self.confidence});
+        try writer.print(",\"confidence\":{d:.[1]}", .{ self.confidence, precision });
 
         try writer.print(",\"is_valid\":{s}", .{if (self.is_valid) "true" else "false"});
 
         try writer.writeAll("}");
+    }
 
-        return result.toOwnedSlice();
+    /// Writes `,"field":"value"` for fields named after their `Field`
+    fn JsonFieldWriter(comptime Writer: type) type {
+        return struct {
+            writer: Writer,
+            include: std.EnumSet(Field),
+            include_nulls: bool,
+
+            /// `item` is an enum with `toString`, a string, or an optional of either
+            fn value(self: @This(), comptime field: Field, item: anytype) !void {
+                if (!self.include.contains(field)) return;
+
+                const T = @TypeOf(item);
+                const Payload = switch (@typeInfo(T)) {
+                    .optional => |info| info.child,
+                    else => T,
+                };
+                const present: ?Payload = item;
+                if (present) |v| {
+                    const text = if (Payload == []const u8) v else v.toString();
+                    try self.writer.print(",\"{s}\":\"{s}\"", .{ @tagName(field), text });
+                } else if (self.include_nulls) {
+                    try self.writer.print(",\"{s}\":null", .{@tagName(field)});
+                }
+            }
+        };
+    }
+
+    /// Re-indent compact JSON without reparsing it, so numbers keep the
+    /// precision they were written with
+    fn indentJson(allocator: std.mem.Allocator, compact: []const u8, indent: u8) ![]u8 {
+        var out = std.ArrayList(u8).init(allocator);
+        errdefer out.deinit();
+
+        var depth: usize = 0;
+        var in_string = false;
+        var escaped = false;
+        var i: usize = 0;
+        while (i < compact.len) : (i += 1) {
+            const ch = compact[i];
+            if (in_string) {
+                try out.append(ch);
+                if (escaped) {
+                    escaped = false;
+                } else if (ch == '\\') {
+                    escaped = true;
+                } else if (ch == '"') {
+                    in_string = false;
+                }
+                continue;
+            }
+
+            switch (ch) {
+                '"' => {
+                    in_string = true;
+                    try out.append(ch);
+                },
+                '{', '[' => {
+                    try out.append(ch);
+                    // Keep empty objects and arrays on one line
+                    if (i + 1 < compact.len and (compact[i + 1] == '}' or compact[i + 1] == ']')) {
+                        i += 1;
+                        try out.append(compact[i]);
+                    } else {
+                        depth += 1;
+                        try out.append('\n');
+                        try out.appendNTimes(' ', depth * indent);
+                    }
+                },
+                '}', ']' => {
+                    depth -|= 1;
+                    try out.append('\n');
+                    try out.appendNTimes(' ', depth * indent);
+                    try out.append(ch);
+                },
+                ',' => {
+                    try out.append(ch);
+                    try out.append('\n');
+                    try out.appendNTimes(' ', depth * indent);
+                },
+                ':' => try out.appendSlice(": "),
+                else => try out.append(ch),
+            }
+        }
+        return out.toOwnedSlice();
     }
 
-    pub fn toPrettyJson(self: SoilDescription, allocator: std.mem.Allocator) ![]u8 {
+    pub fn toPrettyJson(self: GeologicalDescription, allocator: std.mem.Allocator) ![]u8 {
         var result = std.ArrayList(u8).init(allocator);
         var writer = result.writer();
 
@@ -753,8 +2641,21 @@ pub const SoilDescription = struct {
         if (self.secondary_primary_soil_type) |spst| {
             try writer.print(",\n  \"secondary_primary_soil_type\": \"{s}\"", .{spst.toString()});
         }
-        if (self.geological_formation) |formation| {
-            try writer.print(",\n  \"geological_formation\": \"{s}\"", .{formation});
+        if (self.geological_formation) |formation| {
+            try writer.print(",\n  \"geological_formation\": \"{s}\"", .{formation});
+        }
+
+        if (self.alternative_type) |alternative| {
+            try writer.print(",\n  \"alternative_type\": \"{s}\"", .{alternative.toString()});
+        }
+
+        if (self.condition_notes.len > 0) {
+            try writer.writeAll(",\n  \"condition_notes\": [");
+            for (self.condition_notes, 0..) |note, i| {
+                if (i > 0) try writer.writeAll(", ");
+                try writer.print("\"{}\"", .{note});
+            }
+            try writer.writeAll("]");
         }
         if (self.is_made_ground) {
             try writer.writeAll(",\n  \"is_made_ground\": true");
@@ -762,6 +2663,9 @@ pub const SoilDescription = struct {
         if (self.made_ground_label) |label| {
             try writer.print(",\n  \"made_ground_label\": \"{s}\"", .{label});
         }
+        if (self.is_topsoil) {
+            try writer.writeAll(",\n  \"is_topsoil\": true");
+        }
 
         if (self.rock_strength) |rs| {
             try writer.print(",\n  \"rock_strength\": \"{s}\"", .{rs.toString()});
@@ -775,6 +2679,14 @@ pub const SoilDescription = struct {
             try writer.print(",\n  \"rock_structure\": \"{s}\"", .{rs.toString()});
         }
 
+        if (self.discontinuity_spacing) |spacing| {
+            try writer.writeAll(",\n  \"discontinuity_spacing\": {\n");
+            if (spacing.term) |term| try writer.print("    \"term\": \"{s}\",\n", .{term.toString()});
+            try writer.print("    \"min_mm\": {d}", .{spacing.min_mm});
+            if (spacing.max_mm) |max| try writer.print(",\n    \"max_mm\": {d}", .{max});
+            try writer.writeAll("\n  }");
+        }
+
         if (self.primary_rock_type) |prt| {
             try writer.print(",\n  \"primary_rock_type\": \"{s}\"", .{prt.toString()});
         }
@@ -784,6 +2696,27 @@ pub const SoilDescription = struct {
             try writer.print(",\n  \"color\": \"{s}\"", .{color.toString()});
         }
 
+        if (self.secondary_color) |color| {
+            try writer.print(",\n  \"secondary_color\": \"{s}\"", .{color.toString()});
+        }
+
+        if (self.colour_detail) |colour| {
+            try writer.print(",\n  \"colour_detail\": {{\n    \"hue\": \"{s}\"", .{colour.hue.toString()});
+            if (colour.shade) |shade| try writer.print(",\n    \"shade\": \"{s}\"", .{shade.toString()});
+            if (colour.tint) |tint| try writer.print(",\n    \"tint\": \"{s}\"", .{tint.toString()});
+            if (colour.mottling) |mottles| {
+                try writer.print(",\n    \"mottling\": {{\n      \"hue\": \"{s}\"", .{mottles.hue.toString()});
+                if (mottles.shade) |shade| try writer.print(",\n      \"shade\": \"{s}\"", .{shade.toString()});
+                if (mottles.tint) |tint| try writer.print(",\n      \"tint\": \"{s}\"", .{tint.toString()});
+                try writer.writeAll("\n    }");
+            }
+            try writer.writeAll("\n  }");
+        }
+
+        if (self.munsell_colour) |munsell| {
+            try writer.print(",\n  \"munsell_colour\": \"{}\"", .{munsell});
+        }
+
         if (self.moisture_content) |moisture| {
             try writer.print(",\n  \"moisture_content\": \"{s}\"", .{moisture.toString()});
         }
@@ -796,6 +2729,80 @@ pub const SoilDescription = struct {
             try writer.print(",\n  \"particle_size\": \"{s}\"", .{particle_size.toString()});
         }
 
+        if (self.particle_shape) |shape| {
+            try writer.writeAll(",\n  \"particle_shape\": {");
+            var first = true;
+            inline for (.{ "angularity", "angularity_to", "form" }) |key| {
+                if (@field(shape, key)) |value| {
+                    try writer.print("{s}\n    \"" ++ key ++ "\": \"{s}\"", .{ if (first) "" else ",", value.toString() });
+                    first = false;
+                }
+            }
+            try writer.writeAll("\n  }");
+        }
+
+        if (self.sensitivity) |sensitivity| {
+            try writer.print(",\n  \"sensitivity\": \"{s}\"", .{sensitivity.toString()});
+        }
+
+        if (self.peat_properties) |peat| {
+            try writer.writeAll(",\n  \"peat_properties\": {");
+            var first = true;
+            inline for (.{ "fabric", "humification" }) |key| {
+                if (@field(peat, key)) |value| {
+                    try writer.print("{s}\n    \"" ++ key ++ "\": \"{s}\"", .{ if (first) "" else ",", value.toString() });
+                    first = false;
+                }
+            }
+            try writer.writeAll("\n  }");
+        }
+
+        if (self.karst_grade) |grade| {
+            try writer.print(",\n  \"karst_grade\": \"{s}\"", .{grade.toString()});
+        }
+
+        if (self.composite) |composite| {
+            try writer.print(",\n  \"composite\": {{\n    \"arrangement\": \"{s}\",\n", .{composite.arrangement.toString()});
+            if (composite.thickness) |thickness| try writer.print("    \"thickness\": \"{s}\",\n", .{thickness.toString()});
+            try writer.writeAll("    \"components\": [\n");
+            for (composite.components, 0..) |component, i| {
+                if (i > 0) try writer.writeAll(",\n");
+                try writer.writeAll("      {\n");
+                if (component.rock_type) |rock_type| try writer.print("        \"rock_type\": \"{s}\",\n", .{rock_type.toString()});
+                if (component.soil_type) |soil_type| try writer.print("        \"soil_type\": \"{s}\",\n", .{@tagName(soil_type)});
+                try writer.print("        \"percent\": {d}\n      }}", .{component.percent});
+            }
+            try writer.print("\n    ],\n    \"proportions_stated\": {s}\n  }}", .{if (composite.proportions_stated) "true" else "false"});
+        }
+
+        if (self.material_origin) |origin| {
+            try writer.print(",\n  \"material_origin\": \"{s}\"", .{origin.toString()});
+        }
+
+        if (self.marine_indicators.count() > 0) {
+            try writer.writeAll(",\n  \"marine_indicators\": [");
+            var indicators = self.marine_indicators.iterator();
+            var first = true;
+            while (indicators.next()) |indicator| {
+                if (!first) try writer.writeAll(", ");
+                first = false;
+                try writer.print("\"{s}\"", .{indicator.toString()});
+            }
+            try writer.writeAll("]");
+        }
+
+        if (self.gas_indicators) |gas| {
+            try writer.writeAll(",\n  \"gas_indicators\": {\n");
+            try writer.print("    \"gas_blisters\": {},\n", .{gas.gas_blisters});
+            try writer.print("    \"expanded_core\": {},\n", .{gas.expanded_core});
+            try writer.print("    \"gas_voids\": {},\n", .{gas.gas_voids});
+            try writer.print("    \"hydrate_dissociation\": {}\n  }}", .{gas.hydrate_dissociation});
+        }
+
+        if (self.fossil_frequency) |frequency| {
+            try writer.print(",\n  \"fossil_frequency\": \"{s}\"", .{frequency.toString()});
+        }
+
         // Add strength parameters to JSON
         if (self.strength_parameters) |sp| {
             try writer.print(",\n  \"strength_parameter_type\": \"{s}\"", .{sp.parameter_type.toString()});
@@ -808,6 +2815,24 @@ pub const SoilDescription = struct {
                 try writer.print(",\n  \"strength_typical_value\": {d:.2}", .{sp.range.getMidpoint()});
             }
             try writer.print(",\n  \"strength_confidence\": {d:.2}", .{sp.confidence});
+            try writer.print(",\n  \"strength_provenance\": \"{s}\"", .{sp.provenance.toString()});
+            if (sp.groundwater_adjustment) |adjustment| {
+                try writer.print(",\n  \"strength_groundwater_adjustment\": \"{s}\"", .{adjustment.toString()});
+            }
+            if (sp.alternate) |alt| {
+                try writer.writeAll(",\n  \"intermediate_geomaterial\": true");
+                try writer.print(",\n  \"strength_alternate_type\": \"{s}\"", .{alt.parameter_type.toString()});
+                try writer.print(",\n  \"strength_alternate_units\": \"{s}\"", .{alt.parameter_type.getUnits()});
+                try writer.print(",\n  \"strength_alternate_lower_bound\": {d:.2}", .{alt.range.lower_bound});
+                try writer.print(",\n  \"strength_alternate_upper_bound\": {d:.2}", .{alt.range.upper_bound});
+                try writer.print(",\n  \"strength_alternate_provenance\": \"{s}\"", .{alt.provenance.toString()});
+            }
+        }
+
+        if (self.boundary_advisory) |advisory| {
+            try writer.print(",\n  \"boundary_advisory\": {{\n    \"described_as\": \"{s}\",\n", .{advisory.described_as.toString()});
+            try writer.print("    \"cu_lower_bound\": {d:.2},\n    \"cu_upper_bound\": {d:.2},\n", .{ advisory.as_soil.lower_bound, advisory.as_soil.upper_bound });
+            try writer.print("    \"ucs_lower_bound\": {d:.2},\n    \"ucs_upper_bound\": {d:.2}\n  }}", .{ advisory.as_rock.lower_bound, advisory.as_rock.upper_bound });
         }
 
         // Add constituent guidance to JSON
@@ -834,6 +2859,80 @@ pub const SoilDescription = struct {
         }
         try writer.writeAll("\n  ]");
 
+        if (self.inclusions.len > 0) {
+            try writer.writeAll(",\n  \"inclusions\": [\n");
+            for (self.inclusions, 0..) |inclusion, i| {
+                if (i > 0) try writer.writeAll(",\n");
+                try writer.writeAll("    {\n");
+                if (inclusion.frequency) |frequency| try writer.print("      \"frequency\": \"{s}\",\n", .{frequency.toString()});
+                if (inclusion.thickness) |thickness| try writer.print("      \"thickness\": \"{s}\",\n", .{thickness.toString()});
+                try writer.print("      \"form\": \"{s}\",\n", .{inclusion.form.toString()});
+                if (inclusion.consistency) |consistency| try writer.print("      \"consistency\": \"{s}\",\n", .{consistency.toString()});
+                if (inclusion.color) |color| try writer.print("      \"color\": \"{s}\",\n", .{color.toString()});
+                if (inclusion.particle_size) |size| try writer.print("      \"particle_size\": \"{s}\",\n", .{size.toString()});
+                try writer.print("      \"soil_type\": \"{s}\"\n    }}", .{@tagName(inclusion.soil_type)});
+            }
+            try writer.writeAll("\n  ]");
+        }
+
+        if (self.tertiary_constituents.len > 0) {
+            try writer.writeAll(",\n  \"tertiary_constituents\": [\n");
+            for (self.tertiary_constituents, 0..) |constituent, i| {
+                if (i > 0) try writer.writeAll(",\n");
+                try writer.writeAll("    {\n");
+                if (constituent.frequency) |frequency| try writer.print("      \"frequency\": \"{s}\",\n", .{frequency.toString()});
+                try writer.writeAll("      \"name\": ");
+                try std.json.stringify(constituent.name, .{}, writer);
+                if (constituent.soil_type) |soil_type| try writer.print(",\n      \"soil_type\": \"{s}\"", .{@tagName(soil_type)});
+                try writer.writeAll("\n    }");
+            }
+            try writer.writeAll("\n  ]");
+        }
+
+        if (self.made_ground_constituents.len > 0) {
+            try writer.writeAll(",\n  \"made_ground_constituents\": [\n");
+            for (self.made_ground_constituents, 0..) |constituent, i| {
+                if (i > 0) try writer.writeAll(",\n");
+                try writer.writeAll("    {\n");
+                if (constituent.frequency) |frequency| try writer.print("      \"frequency\": \"{s}\",\n", .{frequency.toString()});
+                try writer.print("      \"material\": \"{s}\"\n    }}", .{constituent.material.toString()});
+            }
+            try writer.writeAll("\n  ]");
+        }
+
+        if (self.absences.len > 0) {
+            try writer.writeAll(",\n  \"absences\": [\n");
+            for (self.absences, 0..) |item, i| {
+                if (i > 0) try writer.writeAll(",\n");
+                try writer.print("    {{\n      \"feature\": \"{s}\",\n      \"phrase\": \"{s}\"\n    }}", .{ item.feature, item.phrase });
+            }
+            try writer.writeAll("\n  ]");
+        }
+
+        if (self.sources.count() > 0) {
+            try writer.writeAll(",\n  \"sources\": {\n");
+            var first = true;
+            for (std.enums.values(Field)) |field| {
+                const source = self.sources.get(field) orelse continue;
+                if (!first) try writer.writeAll(",\n");
+                first = false;
+                try writer.print("    \"{s}\": \"{s}\"", .{ @tagName(field), source.toString() });
+            }
+            try writer.writeAll("\n  }");
+        }
+
+        if (self.spans.count() > 0) {
+            try writer.writeAll(",\n  \"spans\": {\n");
+            var first = true;
+            for (std.enums.values(Field)) |field| {
+                const span = self.spans.get(field) orelse continue;
+                if (!first) try writer.writeAll(",\n");
+                first = false;
+                try writer.print("    \"{s}\": [{d}, {d}]", .{ @tagName(field), span.start, span.end });
+            }
+            try writer.writeAll("\n  }");
+        }
+
         try writer.writeAll(",\n  \"warnings\": [\n");
         for (self.warnings, 0..) |warning, i| {
             if (i > 0) try writer.writeAll(",\n");
@@ -850,7 +2949,7 @@ pub const SoilDescription = struct {
         return result.toOwnedSlice();
     }
 
-    pub fn toColouredTerminal(self: SoilDescription, allocator: std.mem.Allocator, use_colors: bool) ![]u8 {
+    pub fn toColouredTerminal(self: GeologicalDescription, allocator: std.mem.Allocator, use_colors: bool) ![]u8 {
         if (!use_colors) {
             return self.toPrettyJson(allocator);
         }
@@ -895,6 +2994,18 @@ pub const SoilDescription = struct {
             try writer.print(",\n  {s}\"{s}rock_structure{s}\"{s}: {s}\"{s}{s}{s}\"{s}", .{ key_color, reset_color, key_color, reset_color, string_color, reset_color, rs.toString(), string_color, reset_color });
         }
 
+        if (self.discontinuity_spacing) |spacing| {
+            try writer.print(",\n  {s}\"{s}discontinuity_spacing{s}\"{s}: {s}{{{s}\n", .{ key_color, reset_color, key_color, reset_color, bracket_color, reset_color });
+            if (spacing.term) |term| {
+                try writer.print("    {s}\"{s}term{s}\"{s}: {s}\"{s}{s}{s}\"{s},\n", .{ key_color, reset_color, key_color, reset_color, string_color, reset_color, term.toString(), string_color, reset_color });
+            }
+            try writer.print("    {s}\"{s}min_mm{s}\"{s}: {s}{d}{s}", .{ key_color, reset_color, key_color, reset_color, number_color, spacing.min_mm, reset_color });
+            if (spacing.max_mm) |max| {
+                try writer.print(",\n    {s}\"{s}max_mm{s}\"{s}: {s}{d}{s}", .{ key_color, reset_color, key_color, reset_color, number_color, max, reset_color });
+            }
+            try writer.print("\n  {s}}}{s}", .{ bracket_color, reset_color });
+        }
+
         if (self.primary_rock_type) |prt| {
             try writer.print(",\n  {s}\"{s}primary_rock_type{s}\"{s}: {s}\"{s}{s}{s}\"{s}", .{ key_color, reset_color, key_color, reset_color, string_color, reset_color, prt.toString(), string_color, reset_color });
         }
@@ -904,6 +3015,37 @@ pub const SoilDescription = struct {
             try writer.print(",\n  {s}\"{s}color{s}\"{s}: {s}\"{s}{s}{s}\"{s}", .{ key_color, reset_color, key_color, reset_color, string_color, reset_color, color.toString(), string_color, reset_color });
         }
 
+        if (self.secondary_color) |color| {
+            try writer.print(",\n  {s}\"{s}secondary_color{s}\"{s}: {s}\"{s}{s}{s}\"{s}", .{ key_color, reset_color, key_color, reset_color, string_color, reset_color, color.toString(), string_color, reset_color });
+        }
+
+        if (self.colour_detail) |colour| {
+            try writer.print(",\n  {s}\"{s}colour_detail{s}\"{s}: {s}{{{s}\n", .{ key_color, reset_color, key_color, reset_color, bracket_color, reset_color });
+            try writer.print("    {s}\"{s}hue{s}\"{s}: {s}\"{s}{s}{s}\"{s}", .{ key_color, reset_color, key_color, reset_color, string_color, reset_color, colour.hue.toString(), string_color, reset_color });
+            if (colour.shade) |shade| {
+                try writer.print(",\n    {s}\"{s}shade{s}\"{s}: {s}\"{s}{s}{s}\"{s}", .{ key_color, reset_color, key_color, reset_color, string_color, reset_color, shade.toString(), string_color, reset_color });
+            }
+            if (colour.tint) |tint| {
+                try writer.print(",\n    {s}\"{s}tint{s}\"{s}: {s}\"{s}{s}{s}\"{s}", .{ key_color, reset_color, key_color, reset_color, string_color, reset_color, tint.toString(), string_color, reset_color });
+            }
+            if (colour.mottling) |mottles| {
+                try writer.print(",\n    {s}\"{s}mottling{s}\"{s}: {s}{{{s}\n", .{ key_color, reset_color, key_color, reset_color, bracket_color, reset_color });
+                try writer.print("      {s}\"{s}hue{s}\"{s}: {s}\"{s}{s}{s}\"{s}", .{ key_color, reset_color, key_color, reset_color, string_color, reset_color, mottles.hue.toString(), string_color, reset_color });
+                if (mottles.shade) |shade| {
+                    try writer.print(",\n      {s}\"{s}shade{s}\"{s}: {s}\"{s}{s}{s}\"{s}", .{ key_color, reset_color, key_color, reset_color, string_color, reset_color, shade.toString(), string_color, reset_color });
+                }
+                if (mottles.tint) |tint| {
+                    try writer.print(",\n      {s}\"{s}tint{s}\"{s}: {s}\"{s}{s}{s}\"{s}", .{ key_color, reset_color, key_color, reset_color, string_color, reset_color, tint.toString(), string_color, reset_color });
+                }
+                try writer.print("\n    {s}}}{s}", .{ bracket_color, reset_color });
+            }
+            try writer.print("\n  {s}}}{s}", .{ bracket_color, reset_color });
+        }
+
+        if (self.munsell_colour) |munsell| {
+            try writer.print(",\n  {s}\"{s}munsell_colour{s}\"{s}: {s}\"{s}{}{s}\"{s}", .{ key_color, reset_color, key_color, reset_color, string_color, reset_color, munsell, string_color, reset_color });
+        }
+
         if (self.moisture_content) |moisture| {
             try writer.print(",\n  {s}\"{s}moisture_content{s}\"{s}: {s}\"{s}{s}{s}\"{s}", .{ key_color, reset_color, key_color, reset_color, string_color, reset_color, moisture.toString(), string_color, reset_color });
         }
@@ -916,6 +3058,110 @@ pub const SoilDescription = struct {
             try writer.print(",\n  {s}\"{s}particle_size{s}\"{s}: {s}\"{s}{s}{s}\"{s}", .{ key_color, reset_color, key_color, reset_color, string_color, reset_color, particle_size.toString(), string_color, reset_color });
         }
 
+        if (self.particle_shape) |shape| {
+            try writer.print(",\n  {s}\"{s}particle_shape{s}\"{s}: {s}{{{s}", .{ key_color, reset_color, key_color, reset_color, bracket_color, reset_color });
+            var first = true;
+            inline for (.{ "angularity", "angularity_to", "form" }) |key| {
+                if (@field(shape, key)) |value| {
+                    try writer.print("{s}\n    {s}\"{s}" ++ key ++ "{s}\"{s}: {s}\"{s}{s}{s}\"{s}", .{ if (first) "" else ",", key_color, reset_color, key_color, reset_color, string_color, reset_color, value.toString(), string_color, reset_color });
+                    first = false;
+                }
+            }
+            try writer.print("\n  {s}}}{s}", .{ bracket_color, reset_color });
+        }
+
+        if (self.sensitivity) |sensitivity| {
+            try writer.print(",\n  {s}\"{s}sensitivity{s}\"{s}: {s}\"{s}{s}{s}\"{s}", .{ key_color, reset_color, key_color, reset_color, string_color, reset_color, sensitivity.toString(), string_color, reset_color });
+        }
+
+        if (self.peat_properties) |peat| {
+            try writer.print(",\n  {s}\"{s}peat_properties{s}\"{s}: {s}{{{s}", .{ key_color, reset_color, key_color, reset_color, bracket_color, reset_color });
+            var first = true;
+            inline for (.{ "fabric", "humification" }) |key| {
+                if (@field(peat, key)) |value| {
+                    try writer.print("{s}\n    {s}\"{s}" ++ key ++ "{s}\"{s}: {s}\"{s}{s}{s}\"{s}", .{ if (first) "" else ",", key_color, reset_color, key_color, reset_color, string_color, reset_color, value.toString(), string_color, reset_color });
+                    first = false;
+                }
+            }
+            try writer.print("\n  {s}}}{s}", .{ bracket_color, reset_color });
+        }
+
+        if (self.karst_grade) |grade| {
+            try writer.print(",\n  {s}\"{s}karst_grade{s}\"{s}: {s}\"{s}{s}{s}\"{s}", .{ key_color, reset_color, key_color, reset_color, string_color, reset_color, grade.toString(), string_color, reset_color });
+        }
+
+        if (self.composite) |composite| {
+            try writer.print(",\n  {s}\"{s}composite{s}\"{s}: {s}{{{s}\n", .{ key_color, reset_color, key_color, reset_color, bracket_color, reset_color });
+            try writer.print("    {s}\"{s}arrangement{s}\"{s}: {s}\"{s}{s}{s}\"{s},\n", .{ key_color, reset_color, key_color, reset_color, string_color, reset_color, composite.arrangement.toString(), string_color, reset_color });
+            if (composite.thickness) |thickness| {
+                try writer.print("    {s}\"{s}thickness{s}\"{s}: {s}\"{s}{s}{s}\"{s},\n", .{ key_color, reset_color, key_color, reset_color, string_color, reset_color, thickness.toString(), string_color, reset_color });
+            }
+            try writer.print("    {s}\"{s}components{s}\"{s}: {s}[{s}\n", .{ key_color, reset_color, key_color, reset_color, bracket_color, reset_color });
+            for (composite.components, 0..) |component, i| {
+                if (i > 0) try writer.writeAll(",\n");
+                try writer.print("      {s}{{{s}\n", .{ bracket_color, reset_color });
+                if (component.rock_type) |rock_type| {
+                    try writer.print("        {s}\"{s}rock_type{s}\"{s}: {s}\"{s}{s}{s}\"{s},\n", .{ key_color, reset_color, key_color, reset_color, string_color, reset_color, rock_type.toString(), string_color, reset_color });
+                }
+                if (component.soil_type) |soil_type| {
+                    try writer.print("        {s}\"{s}soil_type{s}\"{s}: {s}\"{s}{s}{s}\"{s},\n", .{ key_color, reset_color, key_color, reset_color, string_color, reset_color, @tagName(soil_type), string_color, reset_color });
+                }
+                try writer.print("        {s}\"{s}percent{s}\"{s}: {s}{d}{s}\n", .{ key_color, reset_color, key_color, reset_color, number_color, component.percent, reset_color });
+                try writer.print("      {s}}}{s}", .{ bracket_color, reset_color });
+            }
+            try writer.print("\n    {s}]{s},\n", .{ bracket_color, reset_color });
+            try writer.print("    {s}\"{s}proportions_stated{s}\"{s}: {s}{s}{s}\n", .{ key_color, reset_color, key_color, reset_color, bool_color, if (composite.proportions_stated) "true" else "false", reset_color });
+            try writer.print("  {s}}}{s}", .{ bracket_color, reset_color });
+        }
+
+        if (self.material_origin) |origin| {
+            try writer.print(",\n  {s}\"{s}material_origin{s}\"{s}: {s}\"{s}{s}{s}\"{s}", .{ key_color, reset_color, key_color, reset_color, string_color, reset_color, origin.toString(), string_color, reset_color });
+        }
+
+        if (self.alternative_type) |alternative| {
+            try writer.print(",\n  {s}\"{s}alternative_type{s}\"{s}: {s}\"{s}{s}{s}\"{s}", .{ key_color, reset_color, key_color, reset_color, string_color, reset_color, alternative.toString(), string_color, reset_color });
+        }
+
+        if (self.condition_notes.len > 0) {
+            try writer.print(",\n  {s}\"{s}condition_notes{s}\"{s}: {s}[{s}", .{ key_color, reset_color, key_color, reset_color, bracket_color, reset_color });
+            for (self.condition_notes, 0..) |note, i| {
+                if (i > 0) try writer.writeAll(", ");
+                try writer.print("{s}\"{s}{}{s}\"{s}", .{ string_color, reset_color, note, string_color, reset_color });
+            }
+            try writer.print("{s}]{s}", .{ bracket_color, reset_color });
+        }
+
+        if (self.marine_indicators.count() > 0) {
+            try writer.print(",\n  {s}\"{s}marine_indicators{s}\"{s}: {s}[{s}", .{ key_color, reset_color, key_color, reset_color, bracket_color, reset_color });
+            var indicators = self.marine_indicators.iterator();
+            var first = true;
+            while (indicators.next()) |indicator| {
+                if (!first) try writer.writeAll(", ");
+                first = false;
+                try writer.print("{s}\"{s}{s}{s}\"{s}", .{ string_color, reset_color, indicator.toString(), string_color, reset_color });
+            }
+            try writer.print("{s}]{s}", .{ bracket_color, reset_color });
+        }
+
+        if (self.gas_indicators) |gas| {
+            try writer.print(",\n  {s}\"{s}gas_indicators{s}\"{s}: {s}{{{s}\n", .{ key_color, reset_color, key_color, reset_color, bracket_color, reset_color });
+            const flags = [_]struct { key: []const u8, set: bool }{
+                .{ .key = "gas_blisters", .set = gas.gas_blisters },
+                .{ .key = "expanded_core", .set = gas.expanded_core },
+                .{ .key = "gas_voids", .set = gas.gas_voids },
+                .{ .key = "hydrate_dissociation", .set = gas.hydrate_dissociation },
+            };
+            for (flags, 0..) |flag, i| {
+                if (i > 0) try writer.writeAll(",\n");
+                try writer.print("    {s}\"{s}{s}{s}\"{s}: {s}{}{s}", .{ key_color, reset_color, flag.key, key_color, reset_color, bool_color, flag.set, reset_color });
+            }
+            try writer.print("\n  {s}}}{s}", .{ bracket_color, reset_color });
+        }
+
+        if (self.fossil_frequency) |frequency| {
+            try writer.print(",\n  {s}\"{s}fossil_frequency{s}\"{s}: {s}\"{s}{s}{s}\"{s}", .{ key_color, reset_color, key_color, reset_color, string_color, reset_color, frequency.toString(), string_color, reset_color });
+        }
+
         // Add strength parameters to JSON
         if (self.strength_parameters) |sp| {
             try writer.print(",\n  {s}\"{s}strength_parameter_type{s}\"{s}: {s}\"{s}{s}{s}\"{s}", .{ key_color, reset_color, key_color, reset_color, string_color, reset_color, sp.parameter_type.toString(), string_color, reset_color });
@@ -928,6 +3174,34 @@ pub const SoilDescription = struct {
                 try writer.print(",\n  {s}\"{s}strength_typical_value{s}\"{s}: {s}{d:.2}{s}", .{ key_color, reset_color, key_color, reset_color, number_color, sp.range.getMidpoint(), reset_color });
             }
             try writer.print(",\n  {s}\"{s}strength_confidence{s}\"{s}: {s}{d:.2}{s}", .{ key_color, reset_color, key_color, reset_color, number_color, sp.confidence, reset_color });
+            try writer.print(",\n  {s}\"{s}strength_provenance{s}\"{s}: {s}\"{s}{s}{s}\"{s}", .{ key_color, reset_color, key_color, reset_color, string_color, reset_color, sp.provenance.toString(), string_color, reset_color });
+            if (sp.groundwater_adjustment) |adjustment| {
+                try writer.print(",\n  {s}\"{s}strength_groundwater_adjustment{s}\"{s}: {s}\"{s}{s}{s}\"{s}", .{ key_color, reset_color, key_color, reset_color, string_color, reset_color, adjustment.toString(), string_color, reset_color });
+            }
+            if (sp.alternate) |alt| {
+                try writer.print(",\n  {s}\"{s}intermediate_geomaterial{s}\"{s}: {s}true{s}", .{ key_color, reset_color, key_color, reset_color, bool_color, reset_color });
+                try writer.print(",\n  {s}\"{s}strength_alternate_type{s}\"{s}: {s}\"{s}{s}{s}\"{s}", .{ key_color, reset_color, key_color, reset_color, string_color, reset_color, alt.parameter_type.toString(), string_color, reset_color });
+                try writer.print(",\n  {s}\"{s}strength_alternate_units{s}\"{s}: {s}\"{s}{s}{s}\"{s}", .{ key_color, reset_color, key_color, reset_color, string_color, reset_color, alt.parameter_type.getUnits(), string_color, reset_color });
+                try writer.print(",\n  {s}\"{s}strength_alternate_lower_bound{s}\"{s}: {s}{d:.2}{s}", .{ key_color, reset_color, key_color, reset_color, number_color, alt.range.lower_bound, reset_color });
+                try writer.print(",\n  {s}\"{s}strength_alternate_upper_bound{s}\"{s}: {s}{d:.2}{s}", .{ key_color, reset_color, key_color, reset_color, number_color, alt.range.upper_bound, reset_color });
+                try writer.print(",\n  {s}\"{s}strength_alternate_provenance{s}\"{s}: {s}\"{s}{s}{s}\"{s}", .{ key_color, reset_color, key_color, reset_color, string_color, reset_color, alt.provenance.toString(), string_color, reset_color });
+            }
+        }
+
+        if (self.boundary_advisory) |advisory| {
+            try writer.print(",\n  {s}\"{s}boundary_advisory{s}\"{s}: {s}{{{s}\n", .{ key_color, reset_color, key_color, reset_color, bracket_color, reset_color });
+            try writer.print("    {s}\"{s}described_as{s}\"{s}: {s}\"{s}{s}{s}\"{s},\n", .{ key_color, reset_color, key_color, reset_color, string_color, reset_color, advisory.described_as.toString(), string_color, reset_color });
+            const bounds = [_]struct { key: []const u8, value: f32 }{
+                .{ .key = "cu_lower_bound", .value = advisory.as_soil.lower_bound },
+                .{ .key = "cu_upper_bound", .value = advisory.as_soil.upper_bound },
+                .{ .key = "ucs_lower_bound", .value = advisory.as_rock.lower_bound },
+                .{ .key = "ucs_upper_bound", .value = advisory.as_rock.upper_bound },
+            };
+            for (bounds, 0..) |bound, i| {
+                if (i > 0) try writer.writeAll(",\n");
+                try writer.print("    {s}\"{s}{s}{s}\"{s}: {s}{d:.2}{s}", .{ key_color, reset_color, bound.key, key_color, reset_color, number_color, bound.value, reset_color });
+            }
+            try writer.print("\n  {s}}}{s}", .{ bracket_color, reset_color });
         }
 
         // Add constituent guidance to JSON
@@ -956,6 +3230,103 @@ pub const SoilDescription = struct {
         }
         try writer.print("\n  {s}]{s}", .{ bracket_color, reset_color });
 
+        if (self.inclusions.len > 0) {
+            try writer.print(",\n  {s}\"{s}inclusions{s}\"{s}: {s}[{s}\n", .{ key_color, reset_color, key_color, reset_color, bracket_color, reset_color });
+            for (self.inclusions, 0..) |inclusion, i| {
+                if (i > 0) try writer.writeAll(",\n");
+                try writer.print("    {s}{{{s}\n", .{ bracket_color, reset_color });
+                if (inclusion.frequency) |frequency| {
+                    try writer.print("      {s}\"{s}frequency{s}\"{s}: {s}\"{s}{s}{s}\"{s},\n", .{ key_color, reset_color, key_color, reset_color, string_color, reset_color, frequency.toString(), string_color, reset_color });
+                }
+                if (inclusion.thickness) |thickness| {
+                    try writer.print("      {s}\"{s}thickness{s}\"{s}: {s}\"{s}{s}{s}\"{s},\n", .{ key_color, reset_color, key_color, reset_color, string_color, reset_color, thickness.toString(), string_color, reset_color });
+                }
+                try writer.print("      {s}\"{s}form{s}\"{s}: {s}\"{s}{s}{s}\"{s},\n", .{ key_color, reset_color, key_color, reset_color, string_color, reset_color, inclusion.form.toString(), string_color, reset_color });
+                if (inclusion.consistency) |consistency| {
+                    try writer.print("      {s}\"{s}consistency{s}\"{s}: {s}\"{s}{s}{s}\"{s},\n", .{ key_color, reset_color, key_color, reset_color, string_color, reset_color, consistency.toString(), string_color, reset_color });
+                }
+                if (inclusion.color) |color| {
+                    try writer.print("      {s}\"{s}color{s}\"{s}: {s}\"{s}{s}{s}\"{s},\n", .{ key_color, reset_color, key_color, reset_color, string_color, reset_color, color.toString(), string_color, reset_color });
+                }
+                if (inclusion.particle_size) |size| {
+                    try writer.print("      {s}\"{s}particle_size{s}\"{s}: {s}\"{s}{s}{s}\"{s},\n", .{ key_color, reset_color, key_color, reset_color, string_color, reset_color, size.toString(), string_color, reset_color });
+                }
+                try writer.print("      {s}\"{s}soil_type{s}\"{s}: {s}\"{s}{s}{s}\"{s}\n", .{ key_color, reset_color, key_color, reset_color, string_color, reset_color, @tagName(inclusion.soil_type), string_color, reset_color });
+                try writer.print("    {s}}}{s}", .{ bracket_color, reset_color });
+            }
+            try writer.print("\n  {s}]{s}", .{ bracket_color, reset_color });
+        }
+
+        if (self.tertiary_constituents.len > 0) {
+            try writer.print(",\n  {s}\"{s}tertiary_constituents{s}\"{s}: {s}[{s}\n", .{ key_color, reset_color, key_color, reset_color, bracket_color, reset_color });
+            for (self.tertiary_constituents, 0..) |constituent, i| {
+                if (i > 0) try writer.writeAll(",\n");
+                try writer.print("    {s}{{{s}\n", .{ bracket_color, reset_color });
+                if (constituent.frequency) |frequency| {
+                    try writer.print("      {s}\"{s}frequency{s}\"{s}: {s}\"{s}{s}{s}\"{s},\n", .{ key_color, reset_color, key_color, reset_color, string_color, reset_color, frequency.toString(), string_color, reset_color });
+                }
+                try writer.print("      {s}\"{s}name{s}\"{s}: {s}", .{ key_color, reset_color, key_color, reset_color, string_color });
+                try std.json.stringify(constituent.name, .{}, writer);
+                try writer.print("{s}", .{reset_color});
+                if (constituent.soil_type) |soil_type| {
+                    try writer.print(",\n      {s}\"{s}soil_type{s}\"{s}: {s}\"{s}{s}{s}\"{s}", .{ key_color, reset_color, key_color, reset_color, string_color, reset_color, @tagName(soil_type), string_color, reset_color });
+                }
+                try writer.writeAll("\n");
+                try writer.print("    {s}}}{s}", .{ bracket_color, reset_color });
+            }
+            try writer.print("\n  {s}]{s}", .{ bracket_color, reset_color });
+        }
+
+        if (self.made_ground_constituents.len > 0) {
+            try writer.print(",\n  {s}\"{s}made_ground_constituents{s}\"{s}: {s}[{s}\n", .{ key_color, reset_color, key_color, reset_color, bracket_color, reset_color });
+            for (self.made_ground_constituents, 0..) |constituent, i| {
+                if (i > 0) try writer.writeAll(",\n");
+                try writer.print("    {s}{{{s}\n", .{ bracket_color, reset_color });
+                if (constituent.frequency) |frequency| {
+                    try writer.print("      {s}\"{s}frequency{s}\"{s}: {s}\"{s}{s}{s}\"{s},\n", .{ key_color, reset_color, key_color, reset_color, string_color, reset_color, frequency.toString(), string_color, reset_color });
+                }
+                try writer.print("      {s}\"{s}material{s}\"{s}: {s}\"{s}{s}{s}\"{s}\n", .{ key_color, reset_color, key_color, reset_color, string_color, reset_color, constituent.material.toString(), string_color, reset_color });
+                try writer.print("    {s}}}{s}", .{ bracket_color, reset_color });
+            }
+            try writer.print("\n  {s}]{s}", .{ bracket_color, reset_color });
+        }
+
+        if (self.absences.len > 0) {
+            try writer.print(",\n  {s}\"{s}absences{s}\"{s}: {s}[{s}\n", .{ key_color, reset_color, key_color, reset_color, bracket_color, reset_color });
+            for (self.absences, 0..) |item, i| {
+                if (i > 0) try writer.writeAll(",\n");
+                try writer.print("    {s}{{{s}\n", .{ bracket_color, reset_color });
+                try writer.print("      {s}\"{s}feature{s}\"{s}: {s}\"{s}{s}{s}\"{s},\n", .{ key_color, reset_color, key_color, reset_color, string_color, reset_color, item.feature, string_color, reset_color });
+                try writer.print("      {s}\"{s}phrase{s}\"{s}: {s}\"{s}{s}{s}\"{s}\n", .{ key_color, reset_color, key_color, reset_color, string_color, reset_color, item.phrase, string_color, reset_color });
+                try writer.print("    {s}}}{s}", .{ bracket_color, reset_color });
+            }
+            try writer.print("\n  {s}]{s}", .{ bracket_color, reset_color });
+        }
+
+        if (self.sources.count() > 0) {
+            try writer.print(",\n  {s}\"{s}sources{s}\"{s}: {s}{{{s}\n", .{ key_color, reset_color, key_color, reset_color, bracket_color, reset_color });
+            var first = true;
+            for (std.enums.values(Field)) |field| {
+                const source = self.sources.get(field) orelse continue;
+                if (!first) try writer.writeAll(",\n");
+                first = false;
+                try writer.print("    {s}\"{s}{s}{s}\"{s}: {s}\"{s}{s}{s}\"{s}", .{ key_color, reset_color, @tagName(field), key_color, reset_color, string_color, reset_color, source.toString(), string_color, reset_color });
+            }
+            try writer.print("\n  {s}}}{s}", .{ bracket_color, reset_color });
+        }
+
+        if (self.spans.count() > 0) {
+            try writer.print(",\n  {s}\"{s}spans{s}\"{s}: {s}{{{s}\n", .{ key_color, reset_color, key_color, reset_color, bracket_color, reset_color });
+            var first = true;
+            for (std.enums.values(Field)) |field| {
+                const span = self.spans.get(field) orelse continue;
+                if (!first) try writer.writeAll(",\n");
+                first = false;
+                try writer.print("    {s}\"{s}{s}{s}\"{s}: {s}[{s}{s}{d}{s}, {s}{d}{s}{s}]{s}", .{ key_color, reset_color, @tagName(field), key_color, reset_color, bracket_color, reset_color, number_color, span.start, reset_color, number_color, span.end, reset_color, bracket_color, reset_color });
+            }
+            try writer.print("\n  {s}}}{s}", .{ bracket_color, reset_color });
+        }
+
         try writer.print(",\n  {s}\"{s}warnings{s}\"{s}: {s}[{s}\n", .{ key_color, reset_color, key_color, reset_color, bracket_color, reset_color });
         for (self.warnings, 0..) |warning, i| {
             if (i > 0) try writer.writeAll(",\n");
@@ -972,12 +3343,12 @@ pub const SoilDescription = struct {
         return result.toOwnedSlice();
     }
 
-    pub fn toColorizedJson(self: SoilDescription, allocator: std.mem.Allocator, use_colors: bool) ![]u8 {
+    pub fn toColorizedJson(self: GeologicalDescription, allocator: std.mem.Allocator, use_colors: bool) ![]u8 {
         return self.toColouredTerminal(allocator, use_colors);
     }
 
-    /// Parse a SoilDescription from JSON string
-    pub fn fromJson(json_str: []const u8, allocator: std.mem.Allocator) !SoilDescription {
+    /// Parse a GeologicalDescription from JSON string
+    pub fn fromJson(json_str: []const u8, allocator: std.mem.Allocator) !GeologicalDescription {
         // Parse JSON using std.json
         const parsed = try std.json.parseFromSlice(
             std.json.Value,
@@ -993,7 +3364,7 @@ pub const SoilDescription = struct {
         const obj = root.object;
 
         // Initialize with defaults
-        var desc = SoilDescription{
+        var desc = GeologicalDescription{
             .raw_description = "from_json",
             .material_type = .soil,
         };
@@ -1050,6 +3421,10 @@ pub const SoilDescription = struct {
             if (label != .string) return error.InvalidJson;
             desc.made_ground_label = try allocator.dupe(u8, label.string);
         }
+        if (obj.get("is_topsoil")) |is_topsoil| {
+            if (is_topsoil != .bool) return error.InvalidJson;
+            desc.is_topsoil = is_topsoil.bool;
+        }
 
         // Parse rock properties
         if (obj.get("rock_strength")) |rs| {
@@ -1067,6 +3442,24 @@ pub const SoilDescription = struct {
             desc.rock_structure = RockStructure.fromString(rs.string);
         }
 
+        if (obj.get("discontinuity_spacing")) |spacing_value| {
+            if (spacing_value != .object) return error.InvalidJson;
+            const spacing_obj = spacing_value.object;
+            const min = spacing_obj.get("min_mm") orelse return error.InvalidJson;
+            if (min != .integer or min.integer < 0 or min.integer > std.math.maxInt(u32)) return error.InvalidJson;
+
+            var spacing = DiscontinuitySpacing{ .min_mm = @intCast(min.integer) };
+            if (spacing_obj.get("max_mm")) |max| {
+                if (max != .integer or max.integer < min.integer or max.integer > std.math.maxInt(u32)) return error.InvalidJson;
+                spacing.max_mm = @intCast(max.integer);
+            }
+            if (spacing_obj.get("term")) |term| {
+                if (term != .string) return error.InvalidJson;
+                spacing.term = SpacingTerm.fromString(term.string);
+            }
+            desc.discontinuity_spacing = spacing;
+        }
+
         if (obj.get("primary_rock_type")) |prt| {
             if (prt != .string) return error.InvalidJson;
             desc.primary_rock_type = RockType.fromString(prt.string);
@@ -1078,6 +3471,24 @@ pub const SoilDescription = struct {
             desc.color = Color.fromString(color.string);
         }
 
+        if (obj.get("secondary_color")) |color| {
+            if (color != .string) return error.InvalidJson;
+            desc.secondary_color = Color.fromString(color.string);
+        }
+
+        if (obj.get("colour_detail")) |colour| {
+            if (try toneFromJson(colour)) |tone| {
+                var stated = Colour{ .shade = tone.shade, .tint = tone.tint, .hue = tone.hue };
+                if (colour.object.get("mottling")) |mottling| stated.mottling = try toneFromJson(mottling);
+                desc.colour_detail = stated;
+            }
+        }
+
+        if (obj.get("munsell_colour")) |munsell| {
+            if (munsell != .string) return error.InvalidJson;
+            desc.munsell_colour = MunsellColour.fromString(munsell.string);
+        }
+
         if (obj.get("moisture_content")) |moisture| {
             if (moisture != .string) return error.InvalidJson;
             desc.moisture_content = MoistureContent.fromString(moisture.string);
@@ -1093,6 +3504,139 @@ pub const SoilDescription = struct {
             desc.particle_size = ParticleSize.fromString(particle_size.string);
         }
 
+        if (obj.get("particle_shape")) |particle_shape| {
+            if (particle_shape != .object) return error.InvalidJson;
+            var shape = ParticleShape{};
+            if (particle_shape.object.get("angularity")) |value| {
+                if (value != .string) return error.InvalidJson;
+                shape.angularity = Angularity.fromString(value.string);
+            }
+            if (particle_shape.object.get("angularity_to")) |value| {
+                if (value != .string) return error.InvalidJson;
+                shape.angularity_to = Angularity.fromString(value.string);
+            }
+            if (particle_shape.object.get("form")) |value| {
+                if (value != .string) return error.InvalidJson;
+                shape.form = ParticleForm.fromString(value.string);
+            }
+            desc.particle_shape = shape;
+        }
+
+        if (obj.get("sensitivity")) |sensitivity| {
+            if (sensitivity != .string) return error.InvalidJson;
+            desc.sensitivity = Sensitivity.fromString(sensitivity.string);
+        }
+
+        if (obj.get("peat_properties")) |peat_properties| {
+            if (peat_properties != .object) return error.InvalidJson;
+            var peat = PeatProperties{};
+            if (peat_properties.object.get("fabric")) |value| {
+                if (value != .string) return error.InvalidJson;
+                peat.fabric = PeatFabric.fromString(value.string);
+            }
+            if (peat_properties.object.get("humification")) |value| {
+                if (value != .string) return error.InvalidJson;
+                peat.humification = VonPost.fromString(value.string);
+            }
+            desc.peat_properties = peat;
+        }
+
+        if (obj.get("karst_grade")) |grade| {
+            if (grade != .string) return error.InvalidJson;
+            desc.karst_grade = KarstGrade.fromString(grade.string);
+        }
+
+        if (obj.get("composite")) |composite_value| {
+            if (composite_value != .object) return error.InvalidJson;
+            const composite_obj = composite_value.object;
+            const arrangement = composite_obj.get("arrangement") orelse return error.InvalidJson;
+            if (arrangement != .string) return error.InvalidJson;
+            const component_array = composite_obj.get("components") orelse return error.InvalidJson;
+            if (component_array != .array) return error.InvalidJson;
+
+            var composite = CompositeDescription{
+                .arrangement = CompositeArrangement.fromString(arrangement.string) orelse return error.InvalidJson,
+                .components = &[_]CompositeComponent{},
+            };
+            if (composite_obj.get("thickness")) |value| {
+                if (value != .string) return error.InvalidJson;
+                composite.thickness = Thickness.fromString(value.string);
+            }
+            if (composite_obj.get("proportions_stated")) |value| {
+                if (value != .bool) return error.InvalidJson;
+                composite.proportions_stated = value.bool;
+            }
+
+            const components = try allocator.alloc(CompositeComponent, component_array.array.items.len);
+            errdefer allocator.free(components);
+            for (component_array.array.items, 0..) |item, i| {
+                if (item != .object) return error.InvalidJson;
+                const percent = item.object.get("percent") orelse return error.InvalidJson;
+                if (percent != .integer or percent.integer < 0 or percent.integer > 100) return error.InvalidJson;
+
+                var component = CompositeComponent{ .percent = @intCast(percent.integer) };
+                if (item.object.get("rock_type")) |value| {
+                    if (value != .string) return error.InvalidJson;
+                    component.rock_type = RockType.fromString(value.string);
+                }
+                if (item.object.get("soil_type")) |value| {
+                    if (value != .string) return error.InvalidJson;
+                    component.soil_type = SoilType.fromString(value.string);
+                }
+                if (component.rock_type == null and component.soil_type == null) return error.InvalidJson;
+                components[i] = component;
+            }
+            composite.components = components;
+            desc.composite = composite;
+        }
+
+        if (obj.get("material_origin")) |origin| {
+            if (origin != .string) return error.InvalidJson;
+            desc.material_origin = MaterialOrigin.fromString(origin.string);
+        }
+
+        if (obj.get("alternative_type")) |alternative| {
+            if (alternative != .string) return error.InvalidJson;
+            desc.alternative_type = AlternativeType.fromString(alternative.string);
+        }
+
+        if (obj.get("condition_notes")) |note_array| {
+            if (note_array != .array) return error.InvalidJson;
+            var notes = std.ArrayList(ConditionNote).init(allocator);
+            errdefer notes.deinit();
+            for (note_array.array.items) |item| {
+                if (item != .string) return error.InvalidJson;
+                try notes.append(ConditionNote.fromString(item.string) orelse continue);
+            }
+            desc.condition_notes = try notes.toOwnedSlice();
+        }
+
+        if (obj.get("marine_indicators")) |indicators| {
+            if (indicators != .array) return error.InvalidJson;
+            for (indicators.array.items) |item| {
+                if (item != .string) return error.InvalidJson;
+                const indicator = MarineIndicator.fromString(item.string) orelse continue;
+                desc.marine_indicators.insert(indicator);
+            }
+        }
+
+        if (obj.get("gas_indicators")) |gas| {
+            if (gas != .object) return error.InvalidJson;
+            var indicators = GasIndicators{};
+            inline for (.{ "gas_blisters", "expanded_core", "gas_voids", "hydrate_dissociation" }) |key| {
+                if (gas.object.get(key)) |flag| {
+                    if (flag != .bool) return error.InvalidJson;
+                    @field(indicators, key) = flag.bool;
+                }
+            }
+            desc.gas_indicators = indicators;
+        }
+
+        if (obj.get("fossil_frequency")) |frequency| {
+            if (frequency != .string) return error.InvalidJson;
+            desc.fossil_frequency = Frequency.fromString(frequency.string);
+        }
+
         // Parse secondary constituents
         if (obj.get("secondary_constituents")) |sc_array| {
             if (sc_array != .array) return error.InvalidJson;
@@ -1123,6 +3667,138 @@ pub const SoilDescription = struct {
             }
         }
 
+        if (obj.get("inclusions")) |inclusion_array| {
+            if (inclusion_array != .array) return error.InvalidJson;
+            const items = inclusion_array.array.items;
+
+            if (items.len > 0) {
+                const inclusions = try allocator.alloc(Inclusion, items.len);
+                for (items, 0..) |item, i| {
+                    if (item != .object) return error.InvalidJson;
+                    const soil_type = item.object.get("soil_type") orelse return error.InvalidJson;
+                    if (soil_type != .string) return error.InvalidJson;
+
+                    var inclusion = Inclusion{ .soil_type = SoilType.fromString(soil_type.string) orelse return error.InvalidJson };
+                    if (item.object.get("frequency")) |value| {
+                        if (value != .string) return error.InvalidJson;
+                        inclusion.frequency = Frequency.fromString(value.string);
+                    }
+                    if (item.object.get("thickness")) |value| {
+                        if (value != .string) return error.InvalidJson;
+                        inclusion.thickness = Thickness.fromString(value.string);
+                    }
+                    if (item.object.get("form")) |value| {
+                        if (value != .string) return error.InvalidJson;
+                        inclusion.form = InclusionForm.fromString(value.string) orelse .particles;
+                    }
+                    if (item.object.get("consistency")) |value| {
+                        if (value != .string) return error.InvalidJson;
+                        inclusion.consistency = Consistency.fromString(value.string);
+                    }
+                    if (item.object.get("color")) |value| {
+                        if (value != .string) return error.InvalidJson;
+                        inclusion.color = Color.fromString(value.string);
+                    }
+                    if (item.object.get("particle_size")) |value| {
+                        if (value != .string) return error.InvalidJson;
+                        inclusion.particle_size = ParticleSize.fromString(value.string);
+                    }
+                    inclusions[i] = inclusion;
+                }
+                desc.inclusions = inclusions;
+            }
+        }
+
+        if (obj.get("tertiary_constituents")) |constituent_array| {
+            if (constituent_array != .array) return error.InvalidJson;
+            const items = constituent_array.array.items;
+
+            if (items.len > 0) {
+                const constituents = try allocator.alloc(TertiaryConstituent, items.len);
+                for (items, 0..) |item, i| {
+                    if (item != .object) return error.InvalidJson;
+                    const name = item.object.get("name") orelse return error.InvalidJson;
+                    if (name != .string) return error.InvalidJson;
+
+                    var constituent = TertiaryConstituent{ .name = try allocator.dupe(u8, name.string) };
+                    if (item.object.get("frequency")) |value| {
+                        if (value != .string) return error.InvalidJson;
+                        constituent.frequency = Frequency.fromString(value.string);
+                    }
+                    if (item.object.get("soil_type")) |value| {
+                        if (value != .string) return error.InvalidJson;
+                        constituent.soil_type = SoilType.fromString(value.string) orelse return error.InvalidJson;
+                    }
+                    constituents[i] = constituent;
+                }
+                desc.tertiary_constituents = constituents;
+            }
+        }
+
+        if (obj.get("made_ground_constituents")) |constituent_array| {
+            if (constituent_array != .array) return error.InvalidJson;
+            const items = constituent_array.array.items;
+
+            if (items.len > 0) {
+                const constituents = try allocator.alloc(MadeGroundConstituent, items.len);
+                for (items, 0..) |item, i| {
+                    if (item != .object) return error.InvalidJson;
+                    const material = item.object.get("material") orelse return error.InvalidJson;
+                    if (material != .string) return error.InvalidJson;
+
+                    var constituent = MadeGroundConstituent{ .material = AnthropogenicMaterial.fromString(material.string) orelse return error.InvalidJson };
+                    if (item.object.get("frequency")) |value| {
+                        if (value != .string) return error.InvalidJson;
+                        constituent.frequency = Frequency.fromString(value.string);
+                    }
+                    constituents[i] = constituent;
+                }
+                desc.made_ground_constituents = constituents;
+            }
+        }
+
+        if (obj.get("absences")) |absence_array| {
+            if (absence_array != .array) return error.InvalidJson;
+            const items = absence_array.array.items;
+
+            if (items.len > 0) {
+                const absences = try allocator.alloc(Absence, items.len);
+                for (items, 0..) |item, i| {
+                    if (item != .object) return error.InvalidJson;
+                    const feature = item.object.get("feature") orelse return error.InvalidJson;
+                    const phrase = item.object.get("phrase") orelse return error.InvalidJson;
+                    if (feature != .string or phrase != .string) return error.InvalidJson;
+
+                    absences[i] = Absence{
+                        .feature = try allocator.dupe(u8, feature.string),
+                        .phrase = try allocator.dupe(u8, phrase.string),
+                    };
+                }
+                desc.absences = absences;
+            }
+        }
+
+        if (obj.get("boundary_advisory")) |advisory_value| {
+            if (advisory_value != .object) return error.InvalidJson;
+            const advisory_obj = advisory_value.object;
+            const described_as = advisory_obj.get("described_as") orelse return error.InvalidJson;
+            if (described_as != .string) return error.InvalidJson;
+
+            var bounds: [4]f32 = undefined;
+            for ([_][]const u8{ "cu_lower_bound", "cu_upper_bound", "ucs_lower_bound", "ucs_upper_bound" }, 0..) |key, i| {
+                bounds[i] = switch (advisory_obj.get(key) orelse return error.InvalidJson) {
+                    .float => |f| @floatCast(f),
+                    .integer => |n| @floatFromInt(n),
+                    else => return error.InvalidJson,
+                };
+            }
+            desc.boundary_advisory = BoundaryAdvisory{
+                .described_as = std.meta.stringToEnum(MaterialType, described_as.string) orelse return error.InvalidJson,
+                .as_soil = .{ .lower_bound = bounds[0], .upper_bound = bounds[1] },
+                .as_rock = .{ .lower_bound = bounds[2], .upper_bound = bounds[3] },
+            };
+        }
+
         // Parse confidence if provided
         if (obj.get("confidence")) |conf| {
             desc.confidence = switch (conf) {
@@ -1138,6 +3814,37 @@ pub const SoilDescription = struct {
             desc.is_valid = valid.bool;
         }
 
+        // Keep recorded provenance; anything else came from the caller
+        if (obj.get("sources")) |sources| {
+            if (sources != .object) return error.InvalidJson;
+            var entries = sources.object.iterator();
+            while (entries.next()) |entry| {
+                const field = std.meta.stringToEnum(Field, entry.key_ptr.*) orelse continue;
+                if (entry.value_ptr.* != .string) return error.InvalidJson;
+                const source = std.meta.stringToEnum(Source, entry.value_ptr.string) orelse return error.InvalidJson;
+                desc.sources.put(field, source);
+            }
+        }
+        desc.tagSources(.user_supplied);
+
+        if (obj.get("spans")) |spans| {
+            if (spans != .object) return error.InvalidJson;
+            var entries = spans.object.iterator();
+            while (entries.next()) |entry| {
+                const field = std.meta.stringToEnum(Field, entry.key_ptr.*) orelse continue;
+                const bounds = entry.value_ptr.*;
+                if (bounds != .array or bounds.array.items.len != 2) return error.InvalidJson;
+                const start = bounds.array.items[0];
+                const end = bounds.array.items[1];
+                if (start != .integer or end != .integer or start.integer < 0 or end.integer < start.integer) return error.InvalidJson;
+                desc.spans.put(field, .{ .start = @intCast(start.integer), .end = @intCast(end.integer) });
+            }
+        }
+
         return desc;
     }
 };
+
+/// Deprecated: use `GeologicalDescription`. Kept so existing code that names
+/// the old type keeps compiling.
+pub const SoilDescription = GeologicalDescription;
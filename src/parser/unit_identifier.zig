@@ -8,7 +8,7 @@ pub const BoreholeEntry = struct {
     borehole_id: []const u8,
     depth_top: f64,
     depth_bottom: f64,
-    description: types.SoilDescription,
+    description: types.GeologicalDescription,
 
     pub fn deinit(self: *BoreholeEntry, allocator: std.mem.Allocator) void {
         allocator.free(self.borehole_id);
@@ -175,9 +175,14 @@ pub const UnitIdentifier = struct {
     }
 
     /// Check if two descriptions are similar enough to belong to same unit
-    pub fn areSimilar(self: *UnitIdentifier, desc1: *const types.SoilDescription, desc2: *const types.SoilDescription) !bool {
+    pub fn areSimilar(self: *UnitIdentifier, desc1: *const types.GeologicalDescription, desc2: *const types.GeologicalDescription) !bool {
+        return similarityScore(desc1, desc2) >= self.similarity_threshold;
+    }
+
+    /// Semantic similarity between two descriptions (0.0 to 1.0)
+    pub fn similarityScore(desc1: *const types.GeologicalDescription, desc2: *const types.GeologicalDescription) f64 {
         // Must be same material type
-        if (desc1.material_type != desc2.material_type) return false;
+        if (desc1.material_type != desc2.material_type) return 0;
 
         var similarity_score: f64 = 0;
         var criteria_count: f64 = 0;
@@ -257,10 +262,9 @@ pub const UnitIdentifier = struct {
         }
 
         // Calculate final similarity ratio
-        if (criteria_count == 0) return false;
+        if (criteria_count == 0) return 0;
 
-        const ratio = similarity_score / criteria_count;
-        return ratio >= self.similarity_threshold;
+        return similarity_score / criteria_count;
     }
 
     /// Sort cluster indices by average depth
@@ -0,0 +1,186 @@
+const std = @import("std");
+const types = @import("types.zig");
+const lexer = @import("lexer.zig");
+const hooks = @import("hooks.zig");
+const bs5930 = @import("bs5930.zig");
+
+const Field = types.Field;
+const GeologicalDescription = types.GeologicalDescription;
+const Token = lexer.Token;
+
+/// One unclassified word and how often it was seen
+pub const TermCount = struct {
+    term: []const u8,
+    count: usize,
+};
+
+/// Joining words that are never vocabulary
+const function_words = [_][]const u8{ "a", "an", "and", "as", "at", "by", "for", "from", "in", "is", "are", "of", "on", "or", "the", "to", "with" };
+
+/// Lower-cased words the parser could not classify, with their frequency.
+/// Words within a phrase the parser did read ("pockets" in "with pockets of
+/// grey silt") are not counted.
+pub const UnknownTerms = struct {
+    allocator: std.mem.Allocator,
+    counts: std.StringHashMap(usize),
+    /// Descriptions looked at
+    descriptions: usize = 0,
+
+    pub fn init(allocator: std.mem.Allocator) UnknownTerms {
+        return UnknownTerms{
+            .allocator = allocator,
+            .counts = std.StringHashMap(usize).init(allocator),
+        };
+    }
+
+    pub fn deinit(self: *UnknownTerms) void {
+        var keys = self.counts.keyIterator();
+        while (keys.next()) |key| self.allocator.free(key.*);
+        self.counts.deinit();
+    }
+
+    pub fn count(self: UnknownTerms, term: []const u8) usize {
+        return self.counts.get(term) orelse 0;
+    }
+
+    /// Every term, most frequent first and then alphabetically, as a work
+    /// list for vocabulary registration. Terms belong to `self`; free the
+    /// returned slice with `allocator`.
+    pub fn sorted(self: UnknownTerms, allocator: std.mem.Allocator) ![]TermCount {
+        const terms = try allocator.alloc(TermCount, self.counts.count());
+        var iter = self.counts.iterator();
+        var i: usize = 0;
+        while (iter.next()) |entry| : (i += 1) {
+            terms[i] = TermCount{ .term = entry.key_ptr.*, .count = entry.value_ptr.* };
+        }
+        std.mem.sort(TermCount, terms, {}, moreFrequent);
+        return terms;
+    }
+
+    fn add(self: *UnknownTerms, term: []const u8) !void {
+        const entry = try self.counts.getOrPut(term);
+        if (entry.found_existing) {
+            entry.value_ptr.* += 1;
+            return;
+        }
+        entry.key_ptr.* = self.allocator.dupe(u8, term) catch |err| {
+            self.counts.removeByPtr(entry.key_ptr);
+            return err;
+        };
+        entry.value_ptr.* = 1;
+    }
+};
+
+fn moreFrequent(_: void, a: TermCount, b: TermCount) bool {
+    if (a.count != b.count) return a.count > b.count;
+    return std.mem.lessThan(u8, a.term, b.term);
+}
+
+/// Words reported by the parser's unknown token hook for one description
+const Pending = struct {
+    words: std.ArrayList([]u8),
+    /// A hook the caller had registered, still called for every word
+    previous: ?hooks.UnknownTokenHook,
+
+    fn record(self: *Pending, token: Token) !void {
+        if (self.previous) |hook| try hook.call(token);
+        // Token text is freed with the parse, so keep a copy
+        const word = try std.ascii.allocLowerString(self.words.allocator, token.value);
+        errdefer self.words.allocator.free(word);
+        try self.words.append(word);
+    }
+
+    fn clear(self: *Pending) void {
+        for (self.words.items) |word| self.words.allocator.free(word);
+        self.words.clearRetainingCapacity();
+    }
+};
+
+/// Parse every description with `parser` and count the words it could not
+/// classify. The parser's own unknown token hook, if any, still runs and is
+/// restored afterwards.
+pub fn collect(allocator: std.mem.Allocator, parser: *bs5930.Parser, descriptions: []const []const u8) !UnknownTerms {
+    var terms = UnknownTerms.init(allocator);
+    errdefer terms.deinit();
+
+    var pending = Pending{ .words = std.ArrayList([]u8).init(allocator), .previous = parser.unknown_token_hook };
+    defer {
+        pending.clear();
+        pending.words.deinit();
+    }
+    parser.onUnknownToken(&pending, Pending.record);
+    defer parser.unknown_token_hook = pending.previous;
+
+    for (descriptions) |description| {
+        pending.clear();
+        const result = try parser.parse(description);
+        defer result.deinit(parser.allocator);
+
+        for (pending.words.items) |word| {
+            if (!isTerm(word) or readInPhrase(&result, word)) continue;
+            try terms.add(word);
+        }
+        terms.descriptions += 1;
+    }
+    return terms;
+}
+
+fn isTerm(word: []const u8) bool {
+    for (function_words) |function_word| {
+        if (std.mem.eql(u8, word, function_word)) return false;
+    }
+    for (word) |c| {
+        if (std.ascii.isAlphabetic(c)) return true;
+    }
+    return false;
+}
+
+/// `word` is part of the text behind a parsed field
+fn readInPhrase(result: *const GeologicalDescription, word: []const u8) bool {
+    for (std.enums.values(Field)) |field| {
+        const span = result.spans.get(field) orelse continue;
+        if (span.end > result.raw_description.len) continue;
+        if (containsWord(result.raw_description[span.start..span.end], word)) return true;
+    }
+    return false;
+}
+
+fn containsWord(text: []const u8, word: []const u8) bool {
+    var pos: usize = 0;
+    while (std.ascii.indexOfIgnoreCasePos(text, pos, word)) |start| {
+        const end = start + word.len;
+        const bounded = (start == 0 or !std.ascii.isAlphabetic(text[start - 1])) and
+            (end == text.len or !std.ascii.isAlphabetic(text[end]));
+        if (bounded) return true;
+        pos = start + 1;
+    }
+    return false;
+}
+
+test "collect unknown terms across descriptions" {
+    const allocator = std.testing.allocator;
+    var parser = bs5930.Parser.init(allocator);
+
+    const descriptions = [_][]const u8{
+        "Firm brown xylophonic CLAY",
+        "Soft grey xylophonic SILT",
+        "Dense SAND with pockets of grey silt",
+    };
+    var terms = try collect(allocator, &parser, &descriptions);
+    defer terms.deinit();
+
+    try std.testing.expectEqual(@as(usize, 3), terms.descriptions);
+    try std.testing.expectEqual(@as(usize, 2), terms.count("xylophonic"));
+    try std.testing.expectEqual(@as(usize, 0), terms.count("with"));
+    try std.testing.expect(parser.unknown_token_hook == null);
+
+    const ranked = try terms.sorted(allocator);
+    defer allocator.free(ranked);
+    try std.testing.expectEqualStrings("xylophonic", ranked[0].term);
+    try std.testing.expectEqual(@as(usize, 2), ranked[0].count);
+}
+
+test "match whole words only" {
+    try std.testing.expect(containsWord("with Pockets of grey silt", "pockets"));
+    try std.testing.expect(!containsWord("with pocketsful", "pockets"));
+}
@@ -0,0 +1,252 @@
+const std = @import("std");
+const types = @import("types.zig");
+
+const ParticleSize = types.ParticleSize;
+const SoilType = types.SoilType;
+
+/// A US standard sieve (ASTM E11) and its opening
+pub const Sieve = struct {
+    number: u16,
+    opening_mm: f32,
+};
+
+pub const sieves = [_]Sieve{
+    .{ .number = 4, .opening_mm = 4.75 },
+    .{ .number = 8, .opening_mm = 2.36 },
+    .{ .number = 10, .opening_mm = 2.0 },
+    .{ .number = 16, .opening_mm = 1.18 },
+    .{ .number = 20, .opening_mm = 0.85 },
+    .{ .number = 30, .opening_mm = 0.6 },
+    .{ .number = 40, .opening_mm = 0.425 },
+    .{ .number = 50, .opening_mm = 0.3 },
+    .{ .number = 60, .opening_mm = 0.25 },
+    .{ .number = 100, .opening_mm = 0.15 },
+    .{ .number = 140, .opening_mm = 0.106 },
+    .{ .number = 200, .opening_mm = 0.075 },
+};
+
+pub fn sieveOpening(number: u16) ?f32 {
+    for (sieves) |sieve| {
+        if (sieve.number == number) return sieve.opening_mm;
+    }
+    return null;
+}
+
+/// Grain-size limits in millimetres; null is open-ended
+pub const SizeRange = struct {
+    min_mm: ?f32 = null,
+    max_mm: ?f32 = null,
+};
+
+/// The BS 5930 size term covering `range` for sand or gravel, e.g. material
+/// passing the #40 sieve (0.425 mm) is fine to medium sand. Null for other
+/// soils or a range outside the soil's limits.
+pub fn particleSize(soil: SoilType, range: SizeRange) ?ParticleSize {
+    // Fine/medium/coarse boundaries
+    const bounds: [4]f32 = switch (soil) {
+        .sand => .{ 0.063, 0.2, 0.63, 2.0 },
+        .gravel => .{ 2.0, 6.3, 20.0, 63.0 },
+        else => return null,
+    };
+    const lowest = range.min_mm orelse bounds[0];
+    const highest = range.max_mm orelse bounds[3];
+    if (highest <= bounds[0] or lowest >= bounds[3] or lowest >= highest) return null;
+
+    var first: usize = 0;
+    while (first < 2 and lowest >= bounds[first + 1]) first += 1;
+    var last: usize = 2;
+    while (last > 0 and highest <= bounds[last]) last -= 1;
+
+    return switch (first) {
+        0 => switch (last) {
+            0 => .fine,
+            1 => .fine_to_medium,
+            else => .fine_to_coarse,
+        },
+        1 => if (last == 1) .medium else .medium_to_coarse,
+        else => .coarse,
+    };
+}
+
+const Bound = enum { min, max };
+
+const Descriptor = struct {
+    end: usize,
+    bound: Bound,
+    mm: f32,
+};
+
+/// Rewrite US sieve descriptors such as "fine sand (passing #40)",
+/// "retained on No. 200" and "3-inch minus gravel" into BS 5930 size terms.
+/// The descriptors are removed; if the sand or gravel they qualify has no
+/// size word of its own, the equivalent one is inserted before it. Returns
+/// caller-owned text.
+pub fn normalize(allocator: std.mem.Allocator, text: []const u8) ![]u8 {
+    const lower = try std.ascii.allocLowerString(allocator, text);
+    defer allocator.free(lower);
+
+    var stripped = std.ArrayList(u8).init(allocator);
+    defer stripped.deinit();
+
+    var range = SizeRange{};
+    var found = false;
+    var i: usize = 0;
+    while (i < text.len) {
+        if (i == 0 or !std.ascii.isAlphanumeric(lower[i - 1])) {
+            if (matchAt(lower, i)) |descriptor| {
+                switch (descriptor.bound) {
+                    .min => range.min_mm = descriptor.mm,
+                    .max => range.max_mm = descriptor.mm,
+                }
+                found = true;
+                i = descriptor.end;
+                continue;
+            }
+        }
+        try stripped.append(text[i]);
+        i += 1;
+    }
+    if (!found) return allocator.dupe(u8, text);
+
+    var out = std.ArrayList(u8).init(allocator);
+    errdefer out.deinit();
+
+    var sized = false;
+    var previous: []const u8 = "";
+    var words = std.mem.tokenizeAny(u8, stripped.items, " \t");
+    while (words.next()) |word| {
+        if (!sized) {
+            if (soilNoun(word)) |soil| {
+                sized = true;
+                if (!isSizeWord(previous)) {
+                    if (particleSize(soil, range)) |size| {
+                        if (out.items.len > 0) try out.append(' ');
+                        try out.appendSlice(size.toString());
+                    }
+                }
+            }
+        }
+        if (out.items.len > 0) try out.append(' ');
+        try out.appendSlice(word);
+        previous = word;
+    }
+    return out.toOwnedSlice();
+}
+
+/// A descriptor starting at `start`, optionally in parentheses
+fn matchAt(lower: []const u8, start: usize) ?Descriptor {
+    if (lower[start] == '(') {
+        var descriptor = matchDescriptor(lower, start + 1) orelse return null;
+        if (descriptor.end >= lower.len or lower[descriptor.end] != ')') return null;
+        descriptor.end += 1;
+        return descriptor;
+    }
+    return matchDescriptor(lower, start);
+}
+
+fn matchDescriptor(lower: []const u8, start: usize) ?Descriptor {
+    const rest = lower[start..];
+    if (std.mem.startsWith(u8, rest, "passing ")) {
+        return sieveRef(lower, start + "passing ".len, .max);
+    }
+    if (std.mem.startsWith(u8, rest, "retained on ")) {
+        return sieveRef(lower, start + "retained on ".len, .min);
+    }
+    return inchMinus(lower, start);
+}
+
+/// "#40", "no. 40" or "no 40"
+fn sieveRef(lower: []const u8, start: usize, bound: Bound) ?Descriptor {
+    var pos = start;
+    const rest = lower[start..];
+    if (std.mem.startsWith(u8, rest, "#")) {
+        pos += 1;
+    } else if (std.mem.startsWith(u8, rest, "no. ")) {
+        pos += "no. ".len;
+    } else if (std.mem.startsWith(u8, rest, "no ")) {
+        pos += "no ".len;
+    } else return null;
+
+    const digits_end = scanDigits(lower, pos);
+    if (digits_end == pos) return null;
+    const number = std.fmt.parseInt(u16, lower[pos..digits_end], 10) catch return null;
+    const opening = sieveOpening(number) orelse return null;
+    return Descriptor{ .end = digits_end, .bound = bound, .mm = opening };
+}
+
+/// "3-inch minus", "3 inch minus", "3/4-in. minus" or "1.5\" minus"
+fn inchMinus(lower: []const u8, start: usize) ?Descriptor {
+    var pos = scanDigits(lower, start);
+    if (pos == start) return null;
+    var inches = std.fmt.parseFloat(f32, lower[start..pos]) catch return null;
+
+    if (pos < lower.len and (lower[pos] == '.' or lower[pos] == '/')) {
+        const separator = lower[pos];
+        const tail_end = scanDigits(lower, pos + 1);
+        if (tail_end == pos + 1) return null;
+        if (separator == '.') {
+            inches = std.fmt.parseFloat(f32, lower[start..tail_end]) catch return null;
+        } else {
+            const denominator = std.fmt.parseFloat(f32, lower[pos + 1 .. tail_end]) catch return null;
+            if (denominator == 0) return null;
+            inches /= denominator;
+        }
+        pos = tail_end;
+    }
+
+    const units = [_][]const u8{ "-inch", " inch", "-in.", " in.", "-in", "\"" };
+    const unit_len = for (units) |unit| {
+        if (std.mem.startsWith(u8, lower[pos..], unit)) break unit.len;
+    } else return null;
+    pos += unit_len;
+
+    const rest = lower[pos..];
+    if (std.mem.startsWith(u8, rest, " minus")) {
+        pos += " minus".len;
+    } else if (std.mem.startsWith(u8, rest, "-minus")) {
+        pos += "-minus".len;
+    } else return null;
+    if (pos < lower.len and std.ascii.isAlphanumeric(lower[pos])) return null;
+
+    return Descriptor{ .end = pos, .bound = .max, .mm = inches * 25.4 };
+}
+
+fn scanDigits(text: []const u8, start: usize) usize {
+    var pos = start;
+    while (pos < text.len and std.ascii.isDigit(text[pos])) pos += 1;
+    return pos;
+}
+
+fn soilNoun(word: []const u8) ?SoilType {
+    const trimmed = std.mem.trim(u8, word, ",;:.()");
+    if (std.ascii.eqlIgnoreCase(trimmed, "sand")) return .sand;
+    if (std.ascii.eqlIgnoreCase(trimmed, "gravel")) return .gravel;
+    return null;
+}
+
+fn isSizeWord(word: []const u8) bool {
+    for ([_][]const u8{ "fine", "medium", "coarse" }) |size| {
+        if (std.ascii.eqlIgnoreCase(word, size)) return true;
+    }
+    return false;
+}
+
+test "sieve descriptors map onto BS 5930 size terms" {
+    const allocator = std.testing.allocator;
+
+    const cases = [_]struct { input: []const u8, expected: []const u8 }{
+        .{ .input = "Loose brown fine SAND (passing #40)", .expected = "Loose brown fine SAND" },
+        .{ .input = "Loose brown SAND (passing #40)", .expected = "Loose brown fine to medium SAND" },
+        .{ .input = "Dense 3-inch minus GRAVEL", .expected = "Dense fine to coarse GRAVEL" },
+        .{ .input = "Medium dense SAND passing No. 10 retained on #40", .expected = "Medium dense medium to coarse SAND" },
+        .{ .input = "Firm CLAY", .expected = "Firm CLAY" },
+    };
+    for (cases) |case| {
+        const normalized = try normalize(allocator, case.input);
+        defer allocator.free(normalized);
+        try std.testing.expectEqualStrings(case.expected, normalized);
+    }
+
+    try std.testing.expectEqual(ParticleSize.fine, particleSize(.gravel, .{ .max_mm = sieveOpening(4).? }).?);
+    try std.testing.expect(particleSize(.clay, .{ .max_mm = 1.0 }) == null);
+}
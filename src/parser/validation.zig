@@ -1,12 +1,35 @@
 const std = @import("std");
 const types = @import("types.zig");
 
+const GeologicalDescription = types.GeologicalDescription;
 const SoilDescription = types.SoilDescription;
 const SoilType = types.SoilType;
 const Consistency = types.Consistency;
 const Density = types.Density;
 const MaterialType = types.MaterialType;
 
+/// How strictly upper-case primary types ("Firm CLAY") are enforced
+pub const CapitalizationPolicy = enum {
+    required, // Violations are medium severity warnings
+    preferred, // Violations are low severity notes with no confidence penalty
+    ignored,
+
+    pub fn fromString(str: []const u8) ?CapitalizationPolicy {
+        if (std.ascii.eqlIgnoreCase(str, "required")) return .required;
+        if (std.ascii.eqlIgnoreCase(str, "preferred")) return .preferred;
+        if (std.ascii.eqlIgnoreCase(str, "ignored")) return .ignored;
+        return null;
+    }
+
+    pub fn toString(self: CapitalizationPolicy) []const u8 {
+        return switch (self) {
+            .required => "required",
+            .preferred => "preferred",
+            .ignored => "ignored",
+        };
+    }
+};
+
 pub const ValidationError = enum {
     cohesive_soil_missing_consistency,
     granular_soil_missing_density,
@@ -20,6 +43,17 @@ pub const ValidationError = enum {
     invalid_strength_material_combination,
     // Material type misclassification errors
     soil_material_classified_as_rock,
+    // Capitalisation policy errors
+    primary_type_not_capitalized,
+    description_all_capitals,
+    // Overall parse quality
+    low_confidence,
+    missing_primary_type,
+    // Offshore geohazards
+    gas_disturbed_core,
+    hydrate_dissociation,
+    // Colour chart codes
+    invalid_munsell_colour,
 
     pub fn toString(self: ValidationError) []const u8 {
         return switch (self) {
@@ -33,20 +67,114 @@ pub const ValidationError = enum {
             .invalid_plasticity_granular_soil => "Plasticity descriptors should only be used with cohesive soils (clay/silt)",
             .invalid_strength_material_combination => "Rock strength descriptors cannot be used with soil materials",
             .soil_material_classified_as_rock => "Material contains soil types (clay, silt, sand, gravel) but was classified as rock - check descriptors",
+            .primary_type_not_capitalized => "Primary soil/rock type should be written in capitals (e.g. 'Firm CLAY')",
+            .description_all_capitals => "Description is written entirely in capitals - only the primary soil/rock type should be capitalised",
+            .low_confidence => "Parse confidence is low - check the description for unrecognised or conflicting terms",
+            .missing_primary_type => "No primary soil or rock type found (e.g. CLAY, SAND, LIMESTONE)",
+            .gas_disturbed_core => "Gas expansion has disturbed the core - consistency and density may not reflect in situ conditions",
+            .hydrate_dissociation => "Gas hydrate dissociation noted - a seabed geohazard; sample structure and pore water are not representative",
+            .invalid_munsell_colour => "Munsell colour code is out of range - hue step runs 0 to 10, value 0 to 10 and chroma up to 20, or 0 for neutral N (e.g. '10YR 5/3', 'N 5/')",
+        };
+    }
+
+    /// Stable machine-readable code. The prefix gives the rule's usual level
+    /// (E error, W warning); codes never change once published.
+    pub fn code(self: ValidationError) []const u8 {
+        return switch (self) {
+            .low_confidence => "W001",
+            .cohesive_soil_missing_consistency => "W002",
+            .granular_soil_missing_density => "W003",
+            .cohesive_soil_has_density => "W004",
+            .granular_soil_has_consistency => "W005",
+            .invalid_soil_strength_combination => "W006",
+            .primary_type_not_capitalized => "W020",
+            .description_all_capitals => "W021",
+            .gas_disturbed_core => "W030",
+            .hydrate_dissociation => "W031",
+            .invalid_munsell_colour => "W032",
+            .missing_primary_type => "E010",
+            .invalid_consistency_soil_combination => "E011",
+            .invalid_density_soil_combination => "E012",
+            .invalid_plasticity_granular_soil => "E013",
+            .invalid_strength_material_combination => "E014",
+            .soil_material_classified_as_rock => "E015",
+        };
+    }
+
+    /// Short rule name shown alongside the code, e.g. "W001 LowConfidence"
+    pub fn name(self: ValidationError) []const u8 {
+        return switch (self) {
+            .cohesive_soil_missing_consistency => "MissingConsistency",
+            .granular_soil_missing_density => "MissingDensity",
+            .cohesive_soil_has_density => "CohesiveSoilHasDensity",
+            .granular_soil_has_consistency => "GranularSoilHasConsistency",
+            .invalid_soil_strength_combination => "InvalidStrengthCombination",
+            .invalid_consistency_soil_combination => "ConsistencyOnGranularSoil",
+            .invalid_density_soil_combination => "DensityOnCohesiveSoil",
+            .invalid_plasticity_granular_soil => "PlasticityOnGranularSoil",
+            .invalid_strength_material_combination => "RockStrengthOnSoil",
+            .soil_material_classified_as_rock => "SoilClassifiedAsRock",
+            .primary_type_not_capitalized => "PrimaryTypeNotCapitalized",
+            .description_all_capitals => "DescriptionAllCapitals",
+            .low_confidence => "LowConfidence",
+            .missing_primary_type => "MissingPrimaryType",
+            .gas_disturbed_core => "GasDisturbedCore",
+            .hydrate_dissociation => "HydrateDissociation",
+            .invalid_munsell_colour => "InvalidMunsellColour",
         };
     }
 
+    /// Look up a rule by code ("W001") or name ("LowConfidence"), ignoring case
+    pub fn fromCode(str: []const u8) ?ValidationError {
+        for (std.enums.values(ValidationError)) |rule| {
+            if (std.ascii.eqlIgnoreCase(str, rule.code()) or std.ascii.eqlIgnoreCase(str, rule.name())) return rule;
+        }
+        return null;
+    }
+
     pub fn isInvalidating(self: ValidationError) bool {
         return switch (self) {
-            .invalid_consistency_soil_combination, .invalid_density_soil_combination, .invalid_plasticity_granular_soil, .invalid_strength_material_combination, .soil_material_classified_as_rock => true,
+            .invalid_consistency_soil_combination, .invalid_density_soil_combination, .invalid_plasticity_granular_soil, .invalid_strength_material_combination, .soil_material_classified_as_rock, .missing_primary_type => true,
+            else => false,
+        };
+    }
+
+    /// Presentation-only issues that do not affect the parsed meaning
+    pub fn isStyleOnly(self: ValidationError) bool {
+        return switch (self) {
+            .primary_type_not_capitalized, .description_all_capitals => true,
+            else => false,
+        };
+    }
+
+    /// Conditions at the site rather than faults in the description; they
+    /// are reported but never reduce confidence
+    pub fn isAdvisory(self: ValidationError) bool {
+        return switch (self) {
+            .gas_disturbed_core, .hydrate_dissociation => true,
             else => false,
         };
     }
 };
 
-pub const ValidationWarning = struct {
-    error_type: ValidationError,
-    message: []const u8,
+/// Reporting level of a finding, shared by the CLI, reports and exports
+pub const Level = enum {
+    err,
+    warning,
+    info,
+
+    pub fn toString(self: Level) []const u8 {
+        return switch (self) {
+            .err => "error",
+            .warning => "warning",
+            .info => "info",
+        };
+    }
+};
+
+/// A single rule violation found while validating a description
+pub const Finding = struct {
+    rule: ValidationError,
     severity: Severity,
 
     pub const Severity = enum {
@@ -63,207 +191,322 @@ pub const ValidationWarning = struct {
         }
     };
 
-    pub fn init(allocator: std.mem.Allocator, error_type: ValidationError, severity: Severity) !ValidationWarning {
-        const message = try allocator.dupe(u8, error_type.toString());
-        return ValidationWarning{
-            .error_type = error_type,
-            .message = message,
-            .severity = severity,
-        };
+    pub fn init(rule: ValidationError, severity: Severity) Finding {
+        return Finding{ .rule = rule, .severity = severity };
+    }
+
+    pub fn code(self: Finding) []const u8 {
+        return self.rule.code();
+    }
+
+    pub fn message(self: Finding) []const u8 {
+        return self.rule.toString();
+    }
+
+    /// Invalidating rules are errors; low severity notes are informational
+    pub fn level(self: Finding) Level {
+        if (self.rule.isInvalidating()) return .err;
+        if (self.severity == .low) return .info;
+        return .warning;
+    }
+};
+
+/// Structured outcome of validating one description
+pub const ValidationResult = struct {
+    findings: []Finding,
+    is_valid: bool,
+
+    pub fn deinit(self: ValidationResult, allocator: std.mem.Allocator) void {
+        allocator.free(self.findings);
+    }
+
+    pub fn count(self: ValidationResult, level: Level) usize {
+        var total: usize = 0;
+        for (self.findings) |finding| {
+            if (finding.level() == level) total += 1;
+        }
+        return total;
+    }
+
+    pub fn hasErrors(self: ValidationResult) bool {
+        return self.count(.err) > 0;
     }
 
-    pub fn deinit(self: ValidationWarning, allocator: std.mem.Allocator) void {
-        allocator.free(self.message);
+    pub fn contains(self: ValidationResult, rule: ValidationError) bool {
+        for (self.findings) |finding| {
+            if (finding.rule == rule) return true;
+        }
+        return false;
     }
 };
 
 pub const Validator = struct {
     allocator: std.mem.Allocator,
+    capitalization_policy: CapitalizationPolicy = .ignored,
+    /// Rules to drop from results, by code ("W002") or name ("MissingConsistency")
+    suppressed_rules: []const []const u8 = &.{},
+    /// Confidence below which W001 LowConfidence is reported
+    low_confidence_threshold: f32 = 0.5,
 
     pub fn init(allocator: std.mem.Allocator) Validator {
         return Validator{ .allocator = allocator };
     }
 
-    pub fn validate(self: *Validator, description: *SoilDescription) !void {
-        var warnings = std.ArrayList(ValidationWarning).init(self.allocator);
-        defer {
-            for (warnings.items) |warning| {
-                warning.deinit(self.allocator);
-            }
-            warnings.deinit();
+    pub fn isSuppressed(self: Validator, rule: ValidationError) bool {
+        for (self.suppressed_rules) |entry| {
+            if (ValidationError.fromCode(entry) == rule) return true;
         }
+        return false;
+    }
+
+    /// Run every rule against a description without modifying it. The
+    /// description's confidence is taken as final, so this also suits
+    /// descriptions that have already been validated.
+    pub fn check(self: *Validator, description: *const GeologicalDescription) !ValidationResult {
+        return self.collect(description, false);
+    }
 
-        var has_invalidating_error = false;
+    /// Run the rules; when `before_penalty` is set the low-confidence rule
+    /// looks at the confidence left once this run's penalty is applied
+    fn collect(self: *Validator, description: *const GeologicalDescription, before_penalty: bool) !ValidationResult {
+        var findings = std.ArrayList(Finding).init(self.allocator);
+        errdefer findings.deinit();
 
-        // Check for material type misclassification first
-        const invalid_classification = try self.validateMaterialClassification(&warnings, description);
-        if (invalid_classification) has_invalidating_error = true;
+        try self.validateMaterialClassification(&findings, description);
+        try self.validatePrimaryType(&findings, description);
 
         if (description.material_type == .soil) {
             if (description.primary_soil_type) |soil_type| {
-                const invalid_result = try self.validateSoilStrengthDescriptors(&warnings, soil_type, description.consistency, description.density);
-                if (invalid_result) has_invalidating_error = true;
-
-                const invalid_plasticity = try self.validatePlasticityDescriptors(&warnings, soil_type, description.plasticity_index);
-                if (invalid_plasticity) has_invalidating_error = true;
+                try self.validateSoilStrengthDescriptors(&findings, soil_type, description.consistency, description.density);
+                try self.validatePlasticityDescriptors(&findings, soil_type, description.plasticity_index);
             }
 
-            const invalid_rock_props = try self.validateRockPropertiesOnSoil(&warnings, description);
-            if (invalid_rock_props) has_invalidating_error = true;
+            try self.validateRockPropertiesOnSoil(&findings, description);
         }
 
-        // Mark as invalid if there are invalidating errors
-        if (has_invalidating_error) {
-            description.is_valid = false;
+        if (description.gas_indicators) |gas| {
+            if (gas.disturbsCore()) try findings.append(Finding.init(.gas_disturbed_core, .medium));
+            if (gas.hydrate_dissociation) try findings.append(Finding.init(.hydrate_dissociation, .medium));
         }
 
-        // Convert warnings to string array for SoilDescription
-        if (warnings.items.len > 0) {
-            var warning_strings = std.ArrayList([]const u8).init(self.allocator);
-            defer warning_strings.deinit();
+        if (description.munsell_colour) |munsell| {
+            if (!munsell.isValid()) try findings.append(Finding.init(.invalid_munsell_colour, .medium));
+        }
 
-            for (warnings.items) |warning| {
-                const warning_str = try std.fmt.allocPrint(self.allocator, "[{s}] {s}", .{ warning.severity.toString(), warning.message });
-                try warning_strings.append(warning_str);
-            }
+        if (self.capitalization_policy != .ignored) {
+            try self.validateCapitalization(&findings, description);
+        }
 
-            // Free existing warnings if any
-            for (description.warnings) |warning| {
-                self.allocator.free(warning);
-            }
-            self.allocator.free(description.warnings);
+        // Drop suppressed rules before they affect validity or confidence
+        var kept: usize = 0;
+        for (findings.items) |finding| {
+            if (self.isSuppressed(finding.rule)) continue;
+            findings.items[kept] = finding;
+            kept += 1;
+        }
+        findings.shrinkRetainingCapacity(kept);
 
-            description.warnings = try warning_strings.toOwnedSlice();
+        const final_confidence = if (before_penalty) confidenceAfter(findings.items, description.confidence) else description.confidence;
+        if (final_confidence < self.low_confidence_threshold and !self.isSuppressed(.low_confidence)) {
+            try findings.append(Finding.init(.low_confidence, .medium));
+        }
 
-            // Reduce confidence based on validation issues
-            const confidence_penalty = @as(f32, @floatFromInt(warnings.items.len)) * 0.15;
-            description.confidence = @max(0.1, description.confidence - confidence_penalty);
+        var is_valid = description.is_valid;
+        for (findings.items) |finding| {
+            if (finding.rule.isInvalidating()) is_valid = false;
+        }
+
+        return ValidationResult{
+            .findings = try findings.toOwnedSlice(),
+            .is_valid = is_valid,
+        };
+    }
+
+    /// Validate a description in place: record warnings, apply the confidence
+    /// penalty and clear is_valid when an error is found
+    pub fn validate(self: *Validator, description: *GeologicalDescription) !void {
+        const result = try self.collect(description, true);
+        defer result.deinit(self.allocator);
+
+        description.is_valid = result.is_valid;
+        if (result.findings.len == 0) return;
+
+        // Convert findings to string array for GeologicalDescription
+        var warning_strings = std.ArrayList([]const u8).init(self.allocator);
+        defer warning_strings.deinit();
+        errdefer for (warning_strings.items) |warning| self.allocator.free(warning);
+
+        for (result.findings) |finding| {
+            const warning_str = try std.fmt.allocPrint(self.allocator, "[{s}] {s} {s}", .{ finding.severity.toString(), finding.code(), finding.message() });
+            try warning_strings.append(warning_str);
+        }
+
+        // Free existing warnings if any
+        for (description.warnings) |warning| {
+            self.allocator.free(warning);
+        }
+        self.allocator.free(description.warnings);
+
+        description.warnings = try warning_strings.toOwnedSlice();
+        description.confidence = confidenceAfter(result.findings, description.confidence);
+    }
+
+    /// Reduce confidence based on validation issues. Low severity style notes
+    /// and the low-confidence finding itself are free.
+    fn confidenceAfter(findings: []const Finding, confidence: f32) f32 {
+        var penalised: usize = 0;
+        for (findings) |finding| {
+            if (finding.rule == .low_confidence) continue;
+            if (finding.severity == .low and finding.rule.isStyleOnly()) continue;
+            if (finding.rule.isAdvisory()) continue;
+            penalised += 1;
+        }
+        if (penalised == 0) return confidence;
+        const confidence_penalty = @as(f32, @floatFromInt(penalised)) * 0.15;
+        return @max(0.1, confidence - confidence_penalty);
+    }
+
+    fn validatePrimaryType(
+        self: *Validator,
+        findings: *std.ArrayList(Finding),
+        description: *const GeologicalDescription,
+    ) !void {
+        _ = self;
+        // MADE GROUND and TOPSOIL are complete descriptions without a
+        // natural soil name
+        if (description.is_made_ground or description.is_topsoil) return;
+
+        const missing = switch (description.material_type) {
+            .soil => description.primary_soil_type == null,
+            .rock => description.primary_rock_type == null,
+        };
+        if (missing) {
+            try findings.append(Finding.init(.missing_primary_type, .high));
         }
     }
 
     fn validateSoilStrengthDescriptors(
         self: *Validator,
-        warnings: *std.ArrayList(ValidationWarning),
+        findings: *std.ArrayList(Finding),
         soil_type: SoilType,
         consistency: ?Consistency,
         density: ?Density,
-    ) !bool {
-        var has_invalidating_error = false;
+    ) !void {
+        _ = self;
 
         if (soil_type.isCohesive()) {
             // Cohesive soils (clay/silt) should have consistency, not density
             if (consistency == null) {
-                const warning = try ValidationWarning.init(
-                    self.allocator,
-                    .cohesive_soil_missing_consistency,
-                    .high,
-                );
-                try warnings.append(warning);
+                try findings.append(Finding.init(.cohesive_soil_missing_consistency, .high));
             }
 
             if (density != null) {
-                const warning = try ValidationWarning.init(
-                    self.allocator,
-                    .invalid_density_soil_combination,
-                    .high,
-                );
-                try warnings.append(warning);
-                has_invalidating_error = true;
+                try findings.append(Finding.init(.invalid_density_soil_combination, .high));
             }
         } else if (soil_type.isGranular()) {
             // Granular soils (sand/gravel) should have density, not consistency
             if (density == null) {
-                const warning = try ValidationWarning.init(
-                    self.allocator,
-                    .granular_soil_missing_density,
-                    .high,
-                );
-                try warnings.append(warning);
+                try findings.append(Finding.init(.granular_soil_missing_density, .high));
             }
 
             if (consistency != null) {
-                const warning = try ValidationWarning.init(
-                    self.allocator,
-                    .invalid_consistency_soil_combination,
-                    .high,
-                );
-                try warnings.append(warning);
-                has_invalidating_error = true;
+                try findings.append(Finding.init(.invalid_consistency_soil_combination, .high));
             }
         }
-
-        return has_invalidating_error;
     }
 
     fn validateMaterialClassification(
         self: *Validator,
-        warnings: *std.ArrayList(ValidationWarning),
-        description: *SoilDescription,
-    ) !bool {
+        findings: *std.ArrayList(Finding),
+        description: *const GeologicalDescription,
+    ) !void {
         // Check if a description contains obvious soil types but was classified as rock
         if (description.material_type == .rock) {
             // Check raw description for soil type keywords
-            const raw_lower = std.ascii.allocLowerString(self.allocator, description.raw_description) catch return false;
+            const raw_lower = std.ascii.allocLowerString(self.allocator, description.raw_description) catch return;
             defer self.allocator.free(raw_lower);
 
             const soil_keywords = [_][]const u8{ "clay", "silt", "sand", "gravel", "peat", "organic" };
             for (soil_keywords) |keyword| {
                 if (std.mem.indexOf(u8, raw_lower, keyword)) |_| {
-                    const warning = try ValidationWarning.init(
-                        self.allocator,
-                        .soil_material_classified_as_rock,
-                        .high,
-                    );
-                    try warnings.append(warning);
-                    return true;
+                    try findings.append(Finding.init(.soil_material_classified_as_rock, .high));
+                    return;
                 }
             }
         }
-
-        return false;
     }
 
     fn validatePlasticityDescriptors(
         self: *Validator,
-        warnings: *std.ArrayList(ValidationWarning),
+        findings: *std.ArrayList(Finding),
         soil_type: SoilType,
         plasticity: ?types.PlasticityIndex,
-    ) !bool {
-        if (plasticity == null) return false;
+    ) !void {
+        _ = self;
+        if (plasticity == null) return;
 
         // Plasticity descriptors should only be used with cohesive soils
         if (soil_type.isGranular()) {
-            const warning = try ValidationWarning.init(
-                self.allocator,
-                .invalid_plasticity_granular_soil,
-                .high,
-            );
-            try warnings.append(warning);
-            return true;
+            try findings.append(Finding.init(.invalid_plasticity_granular_soil, .high));
         }
-
-        return false;
     }
 
     fn validateRockPropertiesOnSoil(
         self: *Validator,
-        warnings: *std.ArrayList(ValidationWarning),
-        description: *SoilDescription,
-    ) !bool {
-        var has_invalidating_error = false;
+        findings: *std.ArrayList(Finding),
+        description: *const GeologicalDescription,
+    ) !void {
+        _ = self;
 
         // Check if rock strength descriptors are used with soil
         if (description.rock_strength != null) {
-            const warning = try ValidationWarning.init(
-                self.allocator,
-                .invalid_strength_material_combination,
-                .high,
-            );
-            try warnings.append(warning);
-            has_invalidating_error = true;
+            try findings.append(Finding.init(.invalid_strength_material_combination, .high));
         }
+    }
 
-        return has_invalidating_error;
+    fn validateCapitalization(
+        self: *Validator,
+        findings: *std.ArrayList(Finding),
+        description: *const GeologicalDescription,
+    ) !void {
+        const severity: Finding.Severity = if (self.capitalization_policy == .required) .medium else .low;
+        const primary_name = switch (description.material_type) {
+            .soil => if (description.primary_soil_type) |pst| pst.toString() else return,
+            .rock => if (description.primary_rock_type) |prt| prt.toString() else return,
+        };
+
+        if (isAllCapitals(description.raw_description)) {
+            try findings.append(Finding.init(.description_all_capitals, severity));
+        } else if (!containsCapitalizedWord(description.raw_description, primary_name)) {
+            try findings.append(Finding.init(.primary_type_not_capitalized, severity));
+        }
+    }
+
+    /// True when a multi-word description has no lower-case letters ("FIRM CLAY")
+    fn isAllCapitals(text: []const u8) bool {
+        var words: usize = 0;
+        var iter = std.mem.tokenizeAny(u8, text, " \t,;:.()-");
+        while (iter.next()) |word| {
+            var letters: usize = 0;
+            for (word) |ch| {
+                if (std.ascii.isLower(ch)) return false;
+                if (std.ascii.isAlphabetic(ch)) letters += 1;
+            }
+            if (letters >= 2) words += 1;
+        }
+        return words >= 2;
+    }
+
+    /// True when `name` appears as a whole word written fully in capitals
+    fn containsCapitalizedWord(text: []const u8, name: []const u8) bool {
+        var iter = std.mem.tokenizeAny(u8, text, " \t,;:.()-");
+        while (iter.next()) |word| {
+            if (!std.ascii.eqlIgnoreCase(word, name)) continue;
+            for (word) |ch| {
+                if (std.ascii.isLower(ch)) break;
+            } else return true;
+        }
+        return false;
     }
 
     pub fn isValidCohesiveSoilDescription(soil_type: SoilType, consistency: ?Consistency) bool {
@@ -287,6 +530,26 @@ pub const Validator = struct {
 };
 
 // Tests
+test "gas indicators are reported without a confidence penalty" {
+    const allocator = std.testing.allocator;
+    var validator = Validator.init(allocator);
+
+    const description = GeologicalDescription{
+        .raw_description = "Very soft grey CLAY with gas blisters and hydrate dissociation",
+        .material_type = .soil,
+        .primary_soil_type = .clay,
+        .consistency = .very_soft,
+        .gas_indicators = .{ .gas_blisters = true, .hydrate_dissociation = true },
+    };
+
+    const result = try validator.check(&description);
+    defer result.deinit(allocator);
+    try std.testing.expect(result.contains(.gas_disturbed_core));
+    try std.testing.expect(result.contains(.hydrate_dissociation));
+    try std.testing.expect(result.is_valid);
+    try std.testing.expectEqual(@as(f32, 1.0), Validator.confidenceAfter(result.findings, 1.0));
+}
+
 test "validate cohesive soil with consistency" {
     const allocator = std.testing.allocator;
     var validator = Validator.init(allocator);
@@ -425,6 +688,104 @@ test "validate granular soil with consistency" {
     try std.testing.expect(description.confidence < 1.0);
 }
 
+test "validate capitalisation policy" {
+    const allocator = std.testing.allocator;
+    var validator = Validator.init(allocator);
+    validator.capitalization_policy = .required;
+
+    var description = SoilDescription{
+        .raw_description = try allocator.dupe(u8, "Firm clay"),
+        .material_type = .soil,
+        .primary_soil_type = .clay,
+        .consistency = .firm,
+    };
+    defer {
+        allocator.free(description.raw_description);
+        for (description.warnings) |warning| {
+            allocator.free(warning);
+        }
+        allocator.free(description.warnings);
+    }
+
+    try validator.validate(&description);
+    try std.testing.expect(description.warnings.len == 1);
+    try std.testing.expect(std.mem.indexOf(u8, description.warnings[0], "[medium]") != null);
+    try std.testing.expect(description.confidence < 1.0);
+    try std.testing.expect(description.is_valid);
+}
+
+test "validate preferred capitalisation has no penalty" {
+    const allocator = std.testing.allocator;
+    var validator = Validator.init(allocator);
+    validator.capitalization_policy = .preferred;
+
+    var description = SoilDescription{
+        .raw_description = try allocator.dupe(u8, "FIRM CLAY"),
+        .material_type = .soil,
+        .primary_soil_type = .clay,
+        .consistency = .firm,
+    };
+    defer {
+        allocator.free(description.raw_description);
+        for (description.warnings) |warning| {
+            allocator.free(warning);
+        }
+        allocator.free(description.warnings);
+    }
+
+    try validator.validate(&description);
+    try std.testing.expect(description.warnings.len == 1);
+    try std.testing.expect(std.mem.indexOf(u8, description.warnings[0], "entirely in capitals") != null);
+    try std.testing.expect(description.confidence == 1.0);
+}
+
+test "check reports coded findings" {
+    const allocator = std.testing.allocator;
+    var validator = Validator.init(allocator);
+
+    const description = SoilDescription{
+        .raw_description = "Firm",
+        .material_type = .soil,
+        .consistency = .firm,
+        .confidence = 0.4,
+    };
+
+    const result = try validator.check(&description);
+    defer result.deinit(allocator);
+
+    try std.testing.expect(!result.is_valid);
+    try std.testing.expect(result.hasErrors());
+    try std.testing.expect(result.contains(.missing_primary_type));
+    try std.testing.expect(result.contains(.low_confidence));
+    try std.testing.expectEqualStrings("E010", result.findings[0].code());
+    try std.testing.expectEqual(Level.err, result.findings[0].level());
+    try std.testing.expectEqual(Level.warning, result.findings[1].level());
+}
+
+test "suppressed rules are dropped" {
+    const allocator = std.testing.allocator;
+    var validator = Validator.init(allocator);
+    validator.suppressed_rules = &.{ "W002", "lowconfidence" };
+
+    var description = SoilDescription{
+        .raw_description = try allocator.dupe(u8, "CLAY"),
+        .material_type = .soil,
+        .primary_soil_type = .clay,
+    };
+    defer {
+        allocator.free(description.raw_description);
+        for (description.warnings) |warning| {
+            allocator.free(warning);
+        }
+        allocator.free(description.warnings);
+    }
+
+    try validator.validate(&description);
+    try std.testing.expect(description.warnings.len == 0);
+    try std.testing.expect(description.confidence == 1.0);
+    try std.testing.expectEqual(ValidationError.low_confidence, ValidationError.fromCode("W001").?);
+}
+
 test "validation helper functions" {
     try std.testing.expect(Validator.isValidCohesiveSoilDescription(.clay, .firm));
     try std.testing.expect(!Validator.isValidCohesiveSoilDescription(.clay, null));
@@ -0,0 +1,140 @@
+const std = @import("std");
+const lexer = @import("lexer.zig");
+
+const TokenType = lexer.TokenType;
+
+pub const Category = struct {
+    terms: []const []const u8,
+    token_type: TokenType,
+};
+
+/// Single-word BS 5930 terms recognised by the lexer, shared by exact and
+/// fuzzy matching
+pub const categories = [_]Category{
+    .{ .terms = &[_][]const u8{ "soft", "firm", "stiff", "hard" }, .token_type = .consistency },
+    .{ .terms = &[_][]const u8{ "loose", "dense" }, .token_type = .density },
+    .{ .terms = &[_][]const u8{ "weak", "strong" }, .token_type = .rock_strength },
+    .{ .terms = &[_][]const u8{ "fresh", "weathered" }, .token_type = .weathering_grade },
+    .{ .terms = &[_][]const u8{ "massive", "bedded", "jointed", "fractured", "foliated", "laminated" }, .token_type = .rock_structure },
+    .{ .terms = &[_][]const u8{ "slightly", "moderately", "very" }, .token_type = .proportion },
+    .{ .terms = &[_][]const u8{ "clay", "silt", "sand", "gravel", "cobbles", "boulders", "peat", "organic" }, .token_type = .soil_type },
+    .{ .terms = &[_][]const u8{ "limestone", "sandstone", "mudstone", "shale", "granite", "basalt", "chalk", "dolomite", "quartzite", "slate", "schist", "gneiss", "marble", "conglomerate", "breccia" }, .token_type = .rock_type },
+    .{ .terms = &[_][]const u8{ "sandy", "silty", "clayey", "gravelly" }, .token_type = .adjective },
+    .{ .terms = &[_][]const u8{ "gray", "grey", "brown", "red", "yellow", "orange", "black", "white", "green", "blue", "pink", "purple", "tan", "buff" }, .token_type = .color },
+    .{ .terms = &[_][]const u8{ "dry", "moist", "wet", "saturated" }, .token_type = .moisture_content },
+    .{ .terms = &[_][]const u8{ "fine", "medium", "coarse" }, .token_type = .particle_size },
+};
+
+const alphabet_size = 26;
+const no_type: u8 = 0xff;
+
+/// One trie node with a child slot per lower-case letter. Index 0 is the
+/// root, so a zero child means "no edge". The layout is fixed so a compiled
+/// trie can be written to disk and mapped back in.
+pub const Node = extern struct {
+    next: [alphabet_size]u16,
+    token_type: u8,
+};
+
+/// Flat, read-only trie for exact term lookup in O(word length)
+pub const Trie = struct {
+    nodes: []const Node,
+
+    pub fn lookup(self: Trie, lower: []const u8) ?TokenType {
+        var node: usize = 0;
+        for (lower) |ch| {
+            if (ch < 'a' or ch > 'z') return null;
+            node = self.nodes[node].next[ch - 'a'];
+            if (node == 0) return null;
+        }
+        const token_type = self.nodes[node].token_type;
+        if (token_type == no_type) return null;
+        return @enumFromInt(token_type);
+    }
+
+    /// Raw node table, e.g. to write to a file for later mapping
+    pub fn bytes(self: Trie) []const u8 {
+        return std.mem.sliceAsBytes(self.nodes);
+    }
+
+    /// View a node table produced by `bytes` without copying it. The memory
+    /// (for example an mmap of the file) must outlive the trie.
+    pub fn fromBytes(data: []align(@alignOf(Node)) const u8) !Trie {
+        if (data.len == 0 or data.len % @sizeOf(Node) != 0) return error.InvalidTrie;
+        const nodes = std.mem.bytesAsSlice(Node, data);
+        for (nodes) |node| {
+            for (node.next) |child| {
+                if (child >= nodes.len) return error.InvalidTrie;
+            }
+            if (node.token_type != no_type) {
+                _ = std.meta.intToEnum(TokenType, node.token_type) catch return error.InvalidTrie;
+            }
+        }
+        return Trie{ .nodes = nodes };
+    }
+};
+
+const empty_node = Node{ .next = [_]u16{0} ** alphabet_size, .token_type = no_type };
+
+const builtin_nodes = blk: {
+    @setEvalBranchQuota(100_000);
+
+    var max_nodes: usize = 1;
+    for (categories) |category| {
+        for (category.terms) |term| max_nodes += term.len;
+    }
+
+    var nodes: [max_nodes]Node = undefined;
+    nodes[0] = empty_node;
+    var count: usize = 1;
+
+    for (categories) |category| {
+        for (category.terms) |term| {
+            var node: usize = 0;
+            for (term) |ch| {
+                const slot = ch - 'a';
+                if (nodes[node].next[slot] == 0) {
+                    nodes[count] = empty_node;
+                    nodes[node].next[slot] = @intCast(count);
+                    count += 1;
+                }
+                node = nodes[node].next[slot];
+            }
+            // The first category listing a term wins
+            if (nodes[node].token_type == no_type) {
+                nodes[node].token_type = @intFromEnum(category.token_type);
+            }
+        }
+    }
+
+    const compiled: [count]Node = nodes[0..count].*;
+    break :blk compiled;
+};
+
+/// Trie of the built-in vocabulary, compiled into the binary and shared by
+/// every lexer
+pub const builtin = Trie{ .nodes = &builtin_nodes };
+
+test "builtin trie classifies every term" {
+    for (categories) |category| {
+        for (category.terms) |term| {
+            try std.testing.expectEqual(category.token_type, builtin.lookup(term).?);
+        }
+    }
+
+    try std.testing.expect(builtin.lookup("cla") == null);
+    try std.testing.expect(builtin.lookup("clays") == null);
+    try std.testing.expect(builtin.lookup("grey-brown") == null);
+}
+
+test "trie round trips through bytes" {
+    const allocator = std.testing.allocator;
+
+    const copy = try allocator.alignedAlloc(u8, @alignOf(Node), builtin.bytes().len);
+    defer allocator.free(copy);
+    @memcpy(copy, builtin.bytes());
+
+    const mapped = try Trie.fromBytes(copy);
+    try std.testing.expectEqual(TokenType.rock_type, mapped.lookup("mudstone").?);
+    try std.testing.expectError(error.InvalidTrie, Trie.fromBytes(copy[0 .. @sizeOf(Node) - 1]));
+}
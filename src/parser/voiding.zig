@@ -0,0 +1,66 @@
+const std = @import("std");
+const types = @import("types.zig");
+
+const GeologicalDescription = types.GeologicalDescription;
+const RockType = types.RockType;
+
+/// Likelihood of meeting voids beneath a stratum, for planning probe holes
+/// and foundations in carbonate terrain
+pub const VoidRisk = enum {
+    negligible,
+    low,
+    moderate,
+    high,
+    very_high,
+
+    pub fn toString(self: VoidRisk) []const u8 {
+        return switch (self) {
+            .negligible => "negligible",
+            .low => "low",
+            .moderate => "moderate",
+            .high => "high",
+            .very_high => "very high",
+        };
+    }
+};
+
+/// Void risk from the karst grade, or low for a soluble rock with no grade
+/// given. Insoluble rock and soil are negligible.
+pub fn voidRisk(desc: GeologicalDescription) VoidRisk {
+    if (desc.karst_grade) |grade| {
+        return switch (grade) {
+            .juvenile => .low,
+            .youthful => .moderate,
+            .mature => .high,
+            .complex, .extreme => .very_high,
+        };
+    }
+    if (desc.primary_rock_type) |rock_type| {
+        if (isSoluble(rock_type)) return .low;
+    }
+    return .negligible;
+}
+
+/// Carbonate rocks, which dissolve to form karst
+pub fn isSoluble(rock_type: RockType) bool {
+    return switch (rock_type) {
+        .limestone, .chalk, .dolomite, .marble => true,
+        else => false,
+    };
+}
+
+test "void risk follows karst grade" {
+    const graded = GeologicalDescription{
+        .raw_description = "Strong LIMESTONE, kIII",
+        .material_type = .rock,
+        .primary_rock_type = .limestone,
+        .karst_grade = .mature,
+    };
+    try std.testing.expectEqual(VoidRisk.high, voidRisk(graded));
+
+    const ungraded = GeologicalDescription{ .raw_description = "Strong CHALK", .material_type = .rock, .primary_rock_type = .chalk };
+    try std.testing.expectEqual(VoidRisk.low, voidRisk(ungraded));
+
+    const granite = GeologicalDescription{ .raw_description = "Strong GRANITE", .material_type = .rock, .primary_rock_type = .granite };
+    try std.testing.expectEqual(VoidRisk.negligible, voidRisk(granite));
+}
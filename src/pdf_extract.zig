@@ -0,0 +1,312 @@
+const std = @import("std");
+const bs5930 = @import("parser/bs5930.zig");
+pub const Parser = bs5930.Parser;
+
+/// Text pulled from one page (or the log table region of a page) of a PDF borehole log,
+/// in reading order with one table row per line
+pub const PageText = struct {
+    page: usize,
+    text: []const u8,
+};
+
+/// How a single depth in the depth column should be read
+pub const DepthConvention = enum {
+    base, // Depth marks the base of the stratum (usual UK log layout)
+    top, // Depth marks the top of the stratum
+};
+
+pub const ExtractOptions = struct {
+    depth_convention: DepthConvention = .base,
+    /// Final depth of the hole, used to close the last stratum under the top convention
+    final_depth: ?f64 = null,
+    /// Lines starting with these (case-insensitive) are table furniture, not descriptions
+    header_prefixes: []const []const u8 = &.{ "depth", "description", "strata", "legend", "continued" },
+};
+
+/// A depth/description pair recovered from a log table
+pub const LogEntry = struct {
+    page: usize,
+    depth_top: f64,
+    depth_bottom: f64,
+    description: []const u8,
+};
+
+/// Pluggable OCR engine for scanned logs with no text layer
+pub const OcrEngine = struct {
+    ptr: *anyopaque,
+    vtable: *const VTable,
+
+    pub const VTable = struct {
+        recognize: *const fn (ptr: *anyopaque, allocator: std.mem.Allocator, page_image: []const u8) anyerror![]u8,
+    };
+
+    /// Recognise one page image, returning text owned by the caller
+    pub fn recognize(self: OcrEngine, allocator: std.mem.Allocator, page_image: []const u8) anyerror![]u8 {
+        return self.vtable.recognize(self.ptr, allocator, page_image);
+    }
+};
+
+pub fn freeEntries(allocator: std.mem.Allocator, entries: []LogEntry) void {
+    for (entries) |entry| allocator.free(entry.description);
+    allocator.free(entries);
+}
+
+/// Reconstruct depth/description pairs from page text.
+/// A line starting with a depth ("1.20", "1.20m", "0.00 - 1.20") opens a new entry;
+/// other lines continue the current description, including across page breaks.
+pub fn extractEntries(allocator: std.mem.Allocator, pages: []const PageText, options: ExtractOptions) ![]LogEntry {
+    var entries = std.ArrayList(LogEntry).init(allocator);
+    errdefer {
+        for (entries.items) |entry| allocator.free(entry.description);
+        entries.deinit();
+    }
+
+    var description = std.ArrayList(u8).init(allocator);
+    defer description.deinit();
+
+    var current: ?PendingEntry = null;
+    var previous_depth: f64 = 0;
+
+    for (pages) |page| {
+        var lines = std.mem.splitScalar(u8, page.text, '\n');
+        while (lines.next()) |raw_line| {
+            const line = std.mem.trim(u8, raw_line, " \t\r");
+            if (line.len == 0) continue;
+            if (isHeaderLine(line, options.header_prefixes)) continue;
+
+            if (parseLeadingDepth(line)) |depth| {
+                if (current) |pending| {
+                    try entries.append(try finishEntry(allocator, pending, &description, depth.first));
+                }
+
+                var entry = PendingEntry{ .page = page.page, .depth_top = depth.first, .depth_bottom = null };
+                if (depth.second) |second| {
+                    entry.depth_bottom = second;
+                } else if (options.depth_convention == .base) {
+                    entry.depth_top = previous_depth;
+                    entry.depth_bottom = depth.first;
+                }
+                previous_depth = entry.depth_bottom orelse depth.first;
+                current = entry;
+
+                try appendText(&description, depth.rest);
+                continue;
+            }
+
+            // Text before the first depth is title block material
+            if (current == null) continue;
+            try appendText(&description, line);
+        }
+    }
+
+    if (current) |pending| {
+        const end = pending.depth_bottom orelse options.final_depth orelse pending.depth_top;
+        try entries.append(try finishEntry(allocator, pending, &description, end));
+    }
+
+    return entries.toOwnedSlice();
+}
+
+/// Run OCR over page images and reconstruct depth/description pairs from the result
+pub fn extractWithOcr(
+    allocator: std.mem.Allocator,
+    ocr: OcrEngine,
+    page_images: []const []const u8,
+    options: ExtractOptions,
+) ![]LogEntry {
+    var pages = std.ArrayList(PageText).init(allocator);
+    defer {
+        for (pages.items) |page| allocator.free(page.text);
+        pages.deinit();
+    }
+
+    for (page_images, 0..) |image, i| {
+        const text = try ocr.recognize(allocator, image);
+        errdefer allocator.free(text);
+        try pages.append(PageText{ .page = i + 1, .text = text });
+    }
+
+    return extractEntries(allocator, pages.items, options);
+}
+
+/// Parse recovered entries into strata. Descriptions in the returned strata
+/// are owned by the caller; free them with freeStrata.
+pub fn parseLayers(allocator: std.mem.Allocator, parser: *Parser, entries: []const LogEntry) ![]bs5930.Stratum {
+    var strata = std.ArrayList(bs5930.Stratum).init(allocator);
+    errdefer {
+        for (strata.items) |stratum| stratum.description.deinit(allocator);
+        strata.deinit();
+    }
+
+    for (entries) |entry| {
+        const parsed = try parser.parse(entry.description);
+        errdefer parsed.deinit(allocator);
+        try strata.append(bs5930.Stratum{
+            .depth_top = entry.depth_top,
+            .depth_bottom = entry.depth_bottom,
+            .description = parsed,
+        });
+    }
+
+    return strata.toOwnedSlice();
+}
+
+pub fn freeStrata(allocator: std.mem.Allocator, strata: []bs5930.Stratum) void {
+    for (strata) |stratum| stratum.description.deinit(allocator);
+    allocator.free(strata);
+}
+
+const PendingEntry = struct {
+    page: usize,
+    depth_top: f64,
+    depth_bottom: ?f64,
+};
+
+fn finishEntry(allocator: std.mem.Allocator, pending: PendingEntry, description: *std.ArrayList(u8), next_depth: f64) !LogEntry {
+    defer description.clearRetainingCapacity();
+    return LogEntry{
+        .page = pending.page,
+        .depth_top = pending.depth_top,
+        .depth_bottom = pending.depth_bottom orelse next_depth,
+        .description = try allocator.dupe(u8, description.items),
+    };
+}
+
+/// Join wrapped lines, rejoining words hyphenated across a line break
+fn appendText(description: *std.ArrayList(u8), text: []const u8) !void {
+    const trimmed = std.mem.trim(u8, text, " \t\r");
+    if (trimmed.len == 0) return;
+
+    if (description.items.len > 0 and description.items[description.items.len - 1] != '-') {
+        try description.append(' ');
+    }
+    try description.appendSlice(trimmed);
+}
+
+fn isHeaderLine(line: []const u8, prefixes: []const []const u8) bool {
+    for (prefixes) |prefix| {
+        if (std.ascii.startsWithIgnoreCase(line, prefix)) return true;
+    }
+    return false;
+}
+
+const LeadingDepth = struct {
+    first: f64,
+    second: ?f64,
+    rest: []const u8,
+};
+
+/// Read "1.20", "1.20m" or a range "0.00 - 1.20" / "0.00 to 1.20" from the start of a line
+fn parseLeadingDepth(line: []const u8) ?LeadingDepth {
+    var pos: usize = 0;
+    const first = readNumber(line, &pos) orelse return null;
+    var result = LeadingDepth{ .first = first, .second = null, .rest = line[pos..] };
+
+    var range_pos = pos;
+    skipSpaces(line, &range_pos);
+    if (range_pos < line.len and line[range_pos] == '-') {
+        range_pos += 1;
+    } else if (std.ascii.startsWithIgnoreCase(line[range_pos..], "to ")) {
+        range_pos += 2;
+    } else {
+        return result;
+    }
+    skipSpaces(line, &range_pos);
+
+    if (readNumber(line, &range_pos)) |second| {
+        result.second = second;
+        result.rest = line[range_pos..];
+    }
+    return result;
+}
+
+/// A depth is a decimal number, optionally suffixed with "m", followed by a break
+fn readNumber(line: []const u8, pos: *usize) ?f64 {
+    const start = pos.*;
+    var end = start;
+    var seen_point = false;
+    while (end < line.len) : (end += 1) {
+        const ch = line[end];
+        if (std.ascii.isDigit(ch)) continue;
+        if (ch == '.' and !seen_point) {
+            seen_point = true;
+            continue;
+        }
+        break;
+    }
+    if (end == start or !seen_point) return null;
+
+    const value = std.fmt.parseFloat(f64, line[start..end]) catch return null;
+
+    var next = end;
+    if (next < line.len and (line[next] == 'm' or line[next] == 'M')) next += 1;
+    if (next < line.len and !std.ascii.isWhitespace(line[next]) and line[next] != '-') return null;
+
+    pos.* = next;
+    return value;
+}
+
+fn skipSpaces(line: []const u8, pos: *usize) void {
+    while (pos.* < line.len and std.ascii.isWhitespace(line[pos.*])) pos.* += 1;
+}
+
+test "extract entries using base depths" {
+    const allocator = std.testing.allocator;
+
+    const pages = [_]PageText{
+        .{ .page = 1, .text = "BOREHOLE LOG BH01\nDepth (m)  Description\n0.30 MADE GROUND\n2.50 Firm brown slightly sandy\nCLAY\n" },
+        .{ .page = 2, .text = "Continued from previous sheet\n4.00m Dense grey fine to coarse\nSAND\n" },
+    };
+
+    const entries = try extractEntries(allocator, &pages, .{});
+    defer freeEntries(allocator, entries);
+
+    try std.testing.expectEqual(@as(usize, 3), entries.len);
+    try std.testing.expectEqualStrings("MADE GROUND", entries[0].description);
+    try std.testing.expectApproxEqAbs(@as(f64, 0.0), entries[0].depth_top, 0.0001);
+    try std.testing.expectApproxEqAbs(@as(f64, 0.3), entries[0].depth_bottom, 0.0001);
+    try std.testing.expectEqualStrings("Firm brown slightly sandy CLAY", entries[1].description);
+    try std.testing.expectApproxEqAbs(@as(f64, 0.3), entries[1].depth_top, 0.0001);
+    try std.testing.expectEqual(@as(usize, 2), entries[2].page);
+    try std.testing.expectApproxEqAbs(@as(f64, 4.0), entries[2].depth_bottom, 0.0001);
+}
+
+test "extract entries with depth ranges" {
+    const allocator = std.testing.allocator;
+
+    const pages = [_]PageText{
+        .{ .page = 1, .text = "0.00 - 1.20 Soft CLAY\n1.20-3.00 Stiff CLAY\n" },
+    };
+
+    const entries = try extractEntries(allocator, &pages, .{});
+    defer freeEntries(allocator, entries);
+
+    try std.testing.expectEqual(@as(usize, 2), entries.len);
+    try std.testing.expectEqualStrings("Soft CLAY", entries[0].description);
+    try std.testing.expectApproxEqAbs(@as(f64, 1.2), entries[0].depth_bottom, 0.0001);
+    try std.testing.expectApproxEqAbs(@as(f64, 3.0), entries[1].depth_bottom, 0.0001);
+}
+
+test "extract with OCR engine and parse layers" {
+    const allocator = std.testing.allocator;
+
+    const StubOcr = struct {
+        fn recognize(_: *anyopaque, alloc: std.mem.Allocator, page_image: []const u8) anyerror![]u8 {
+            return alloc.dupe(u8, page_image);
+        }
+    };
+    var dummy: u8 = 0;
+    const ocr = OcrEngine{ .ptr = &dummy, .vtable = &.{ .recognize = StubOcr.recognize } };
+
+    const images = [_][]const u8{"1.50 Firm CLAY\n6.00 Dense SAND\n"};
+    const entries = try extractWithOcr(allocator, ocr, &images, .{});
+    defer freeEntries(allocator, entries);
+
+    var parser = Parser.init(allocator);
+    const strata = try parseLayers(allocator, &parser, entries);
+    defer freeStrata(allocator, strata);
+
+    try std.testing.expectEqual(@as(usize, 2), strata.len);
+    try std.testing.expectEqual(bs5930.SoilType.clay, strata[0].description.primary_soil_type.?);
+    try std.testing.expectApproxEqAbs(@as(f64, 1.5), strata[1].depth_top, 0.0001);
+}
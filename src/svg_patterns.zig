@@ -45,6 +45,10 @@ pub fn defs() []const u8 {
     \\    <polygon points="1,9 4,3 7,9" fill="none" stroke="#555" stroke-width="0.7"/>
     \\    <circle cx="9" cy="4" r="1" fill="#666"/>
     \\  </pattern>
+    \\  <pattern id="pat-topsoil" patternUnits="userSpaceOnUse" width="12" height="12">
+    \\    <rect width="12" height="12" fill="#f2f2f2"/>
+    \\    <path d="M2,10 L3,6 M6,10 L6,5 M10,10 L9,6" fill="none" stroke="#555" stroke-width="0.7"/>
+    \\  </pattern>
     \\  <pattern id="pat-rock" patternUnits="userSpaceOnUse" width="10" height="10">
     \\    <rect width="10" height="10" fill="#f6f6f6"/>
     \\    <path d="M0,10 L5,4 L10,10" fill="none" stroke="#555" stroke-width="0.7"/>
@@ -56,6 +60,7 @@ pub fn defs() []const u8 {
 pub fn patternForStratum(stratum: ags_reader.AgsStratum) []const u8 {
     if (stratum.parsed) |p| {
         if (p.is_made_ground) return "pat-made-ground";
+        if (p.is_topsoil) return "pat-topsoil";
         if (p.material_type == .soil) {
             if (p.secondary_primary_soil_type != null and p.primary_soil_type != null) {
                 const a = p.primary_soil_type.?;
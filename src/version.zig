@@ -2,11 +2,11 @@ const std = @import("std");
 
 pub const VERSION = std.SemanticVersion{
     .major = 0,
-    .minor = 7,
+    .minor = 8,
     .patch = 0,
 };
 
-pub const VERSION_STRING = "0.7.0";
+pub const VERSION_STRING = "0.8.0";
 
 // Export for C bindings
 export fn litholog_version_major() u32 {
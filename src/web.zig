@@ -4,6 +4,9 @@ const types = @import("parser/types.zig");
 const ags_reader = @import("ags_reader.zig");
 const ags_writer = @import("ags_writer.zig");
 const svg_renderer = @import("svg_renderer.zig");
+const circuit_breaker = @import("circuit_breaker.zig");
+
+const CircuitBreaker = circuit_breaker.CircuitBreaker;
 
 const HTML_CONTENT = @embedFile("web_ui.html");
 
@@ -13,6 +16,9 @@ pub const WebServer = struct {
     port: u16,
     state_mutex: std.Thread.Mutex = .{},
     uploaded_ags: ?UploadedAgs = null,
+    /// Refuses parse requests while the parser keeps failing; replace it
+    /// with `CircuitBreaker.init` to change the thresholds
+    breaker: CircuitBreaker = .{},
 
     const UploadedAgs = struct {
         filename: []const u8,
@@ -41,6 +47,12 @@ pub const WebServer = struct {
     }
 
     pub fn start(self: *WebServer) !void {
+        // Refuse to serve from a broken build
+        if (try bs5930.runSelfTest(self.allocator)) |failed| {
+            std.debug.print("Self-test failed: \"{s}\" gave the wrong {s}\n", .{ failed.description, failed.field });
+            return error.SelfTestFailed;
+        }
+
         const address = try std.net.Address.parseIp("127.0.0.1", self.port);
 
         var tcp_server = try address.listen(.{
@@ -112,12 +124,7 @@ pub const WebServer = struct {
             // Parse multiple descriptions
             try self.handleParseBatch(request);
         } else if (method == .GET and std.mem.startsWith(u8, target, "/api/health")) {
-            // Health check
-            try request.respond("{\"status\":\"ok\"}", .{
-                .extra_headers = &.{
-                    .{ .name = "content-type", .value = "application/json" },
-                },
-            });
+            try self.handleHealth(request);
         } else {
             // 404 Not Found
             try request.respond("404 Not Found", .{
@@ -126,6 +133,41 @@ pub const WebServer = struct {
         }
     }
 
+    fn handleHealth(self: *WebServer, request: *std.http.Server.Request) !void {
+        const health = self.breaker.health();
+        const status = switch (health.state) {
+            .closed => "ok",
+            .half_open => "degraded",
+            .open => "unavailable",
+        };
+
+        const json = try std.fmt.allocPrint(self.allocator, "{{\"status\":\"{s}\",\"circuit\":\"{s}\",\"failure_rate\":{d:.2},\"recent_calls\":{d}}}", .{
+            status,
+            health.state.toString(),
+            health.failure_rate,
+            health.calls,
+        });
+        defer self.allocator.free(json);
+
+        try request.respond(json, .{
+            .status = if (health.state == .open) .service_unavailable else .ok,
+            .extra_headers = &.{
+                .{ .name = "content-type", .value = "application/json" },
+            },
+        });
+    }
+
+    /// Turn away parse requests while the breaker is open
+    fn respondUnavailable(request: *std.http.Server.Request) !void {
+        try request.respond("{\"error\":\"parser unavailable, try again later\"}", .{
+            .status = .service_unavailable,
+            .extra_headers = &.{
+                .{ .name = "content-type", .value = "application/json" },
+                .{ .name = "access-control-allow-origin", .value = "*" },
+            },
+        });
+    }
+
     fn handleParse(self: *WebServer, request: *std.http.Server.Request) !void {
         // Read request body
         var body_buffer = std.ArrayList(u8).init(self.allocator);
@@ -144,7 +186,12 @@ pub const WebServer = struct {
         defer parsed.deinit();
 
         // Parse the geological description
-        const result = try self.parser.parse(parsed.value.description);
+        if (!self.breaker.allow()) return respondUnavailable(request);
+        const result = self.parser.parse(parsed.value.description) catch |err| {
+            self.breaker.record(false);
+            return err;
+        };
+        self.breaker.record(true);
 
         // Convert to JSON
         const json = try result.toJson(self.allocator);
@@ -177,6 +224,10 @@ pub const WebServer = struct {
         defer parsed.deinit();
 
         // Parse all descriptions
+        if (!self.breaker.allow()) return respondUnavailable(request);
+        var succeeded = false;
+        defer self.breaker.record(succeeded);
+
         var results = std.ArrayList(u8).init(self.allocator);
         defer results.deinit();
 
@@ -191,6 +242,7 @@ pub const WebServer = struct {
             try results.appendSlice(json);
         }
         try results.appendSlice("]");
+        succeeded = true;
 
         // Send response
         try request.respond(results.items, .{
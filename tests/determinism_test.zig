@@ -0,0 +1,112 @@
+const std = @import("std");
+const testing = std.testing;
+const parser = @import("parser");
+
+const Parser = parser.Parser;
+const GeologicalDescription = parser.GeologicalDescription;
+
+const samples = [_][]const u8{
+    "Firm slightly sandy slightly gravelly CLAY",
+    "Stiff brown fissured silty CLAY with rare rootlets",
+    "Medium dense grey fine to coarse SAND",
+    "Strong slightly weathered jointed LIMESTONE",
+    "MADE GROUND: soft brown sandy CLAY with brick fragments",
+};
+
+/// Position of `key` in the documented order, or null if undocumented
+fn keyRank(key: []const u8) ?usize {
+    for (GeologicalDescription.json_key_order, 0..) |known, rank| {
+        if (std.mem.eql(u8, known, key)) return rank;
+    }
+    return null;
+}
+
+test "determinism: repeated parses give identical JSON and text" {
+    const allocator = testing.allocator;
+
+    for (samples) |sample| {
+        var first_parser = Parser.init(allocator);
+        const first = try first_parser.parse(sample);
+        defer first.deinit(allocator);
+        var second_parser = Parser.init(allocator);
+        const second = try second_parser.parse(sample);
+        defer second.deinit(allocator);
+
+        const first_json = try first.toJson(allocator);
+        defer allocator.free(first_json);
+        const second_json = try second.toJson(allocator);
+        defer allocator.free(second_json);
+        try testing.expectEqualStrings(first_json, second_json);
+
+        const first_text = try parser.generate(first, allocator);
+        defer allocator.free(first_text);
+        const second_text = try parser.generate(second, allocator);
+        defer allocator.free(second_text);
+        try testing.expectEqualStrings(first_text, second_text);
+    }
+}
+
+test "determinism: JSON keys follow the documented order" {
+    const allocator = testing.allocator;
+    var p = Parser.init(allocator);
+
+    for (samples) |sample| {
+        const result = try p.parse(sample);
+        defer result.deinit(allocator);
+        const json = try result.toJson(allocator);
+        defer allocator.free(json);
+
+        const parsed = try std.json.parseFromSlice(std.json.Value, allocator, json, .{});
+        defer parsed.deinit();
+
+        var previous: ?usize = null;
+        for (parsed.value.object.keys()) |key| {
+            const rank = keyRank(key) orelse {
+                std.debug.print("undocumented JSON key: {s}\n", .{key});
+                return error.UndocumentedKey;
+            };
+            if (previous) |prev| try testing.expect(rank > prev);
+            previous = rank;
+        }
+    }
+}
+
+test "determinism: every key in the example corpus output is documented" {
+    const allocator = testing.allocator;
+    var p = Parser.init(allocator);
+
+    var descriptions = parser.corpusIterator();
+    while (descriptions.next()) |description| {
+        const result = try p.parse(description);
+        defer result.deinit(allocator);
+        const json = try result.toJson(allocator);
+        defer allocator.free(json);
+
+        const parsed = try std.json.parseFromSlice(std.json.Value, allocator, json, .{});
+        defer parsed.deinit();
+        for (parsed.value.object.keys()) |key| {
+            if (keyRank(key) == null) {
+                std.debug.print("undocumented JSON key {s} for \"{s}\"\n", .{ key, description });
+                return error.UndocumentedKey;
+            }
+        }
+    }
+}
+
+test "determinism: secondary constituents keep description order" {
+    const allocator = testing.allocator;
+    var p = Parser.init(allocator);
+
+    const result = try p.parse("Firm slightly gravelly slightly sandy CLAY");
+    defer result.deinit(allocator);
+
+    try testing.expectEqual(@as(usize, 2), result.secondary_constituents.len);
+    try testing.expectEqualStrings("gravelly", result.secondary_constituents[0].soil_type);
+    try testing.expectEqualStrings("sandy", result.secondary_constituents[1].soil_type);
+
+    const text = try parser.generate(result, allocator);
+    defer allocator.free(text);
+    const gravelly = std.mem.indexOf(u8, text, "gravelly").?;
+    const sandy = std.mem.indexOf(u8, text, "sandy").?;
+    try testing.expect(gravelly < sandy);
+}
@@ -80,6 +80,43 @@ test "integration: JSON output is valid" {
     try testing.expect(std.mem.indexOf(u8, json, "material_type") != null);
 }
 
+test "integration: JSON output limited to selected fields" {
+    const allocator = testing.allocator;
+    var p = Parser.init(allocator);
+
+    const result = try p.parse("Firm slightly sandy CLAY");
+    defer result.deinit(allocator);
+
+    const json = try result.toJsonWith(allocator, &.{ .material_type, .consistency, .primary_soil_type });
+    defer allocator.free(json);
+
+    try testing.expect(std.mem.indexOf(u8, json, "\"consistency\":\"firm\"") != null);
+    try testing.expect(std.mem.indexOf(u8, json, "strength_lower_bound") == null);
+    try testing.expect(std.mem.indexOf(u8, json, "secondary_constituents") == null);
+    try testing.expect(std.mem.indexOf(u8, json, "\"is_valid\"") != null);
+
+    const parsed = try std.json.parseFromSlice(std.json.Value, allocator, json, .{});
+    defer parsed.deinit();
+}
+
+test "integration: JSON formatting options" {
+    const allocator = testing.allocator;
+    var p = Parser.init(allocator);
+
+    const result = try p.parse("Firm CLAY");
+    defer result.deinit(allocator);
+
+    const json = try result.toJsonWithOptions(allocator, .{ .indent = 2, .precision = 1, .include_nulls = true });
+    defer allocator.free(json);
+
+    try testing.expect(std.mem.startsWith(u8, json, "{\n  \"raw_description\": \"Firm CLAY\",\n"));
+    try testing.expect(std.mem.indexOf(u8, json, "\"strength_lower_bound\": 25.0,") != null);
+    try testing.expect(std.mem.indexOf(u8, json, "\"rock_strength\": null") != null);
+
+    const parsed = try std.json.parseFromSlice(std.json.Value, allocator, json, .{});
+    defer parsed.deinit();
+}
+
 test "integration: round-trip parse and generate" {
     const allocator = testing.allocator;
     var p = Parser.init(allocator);
@@ -322,6 +322,36 @@ test "lexer: tokenize position tracking" {
     try testing.expectEqual(@as(usize, 9), tokens[1].end);
 }
 
+test "lexer: tokenize punctuation as separators" {
+    const allocator = testing.allocator;
+    var lex = Lexer.init(allocator, "Firm, brown (grey) CLAY.");
+    defer lex.deinit();
+
+    const tokens = try lex.tokenize();
+    defer allocator.free(tokens);
+
+    try testing.expectEqual(@as(usize, 4), tokens.len);
+    try testing.expectEqualStrings("Firm", tokens[0].value);
+    try testing.expectEqualStrings("grey", tokens[2].value);
+    try testing.expectEqual(TokenType.soil_type, tokens[3].type);
+    try testing.expectEqualStrings("CLAY", tokens[3].value);
+    try testing.expectEqual(@as(usize, 23), tokens[3].end);
+}
+
+test "lexer: tokenize hyphenated multi-word pattern" {
+    const allocator = testing.allocator;
+    var lex = Lexer.init(allocator, "fine-to-coarse SAND");
+    defer lex.deinit();
+
+    const tokens = try lex.tokenize();
+    defer allocator.free(tokens);
+
+    try testing.expectEqual(@as(usize, 2), tokens.len);
+    try testing.expectEqual(TokenType.particle_size, tokens[0].type);
+    try testing.expectEqualStrings("fine to coarse", tokens[0].value);
+    try testing.expectEqual(@as(usize, 14), tokens[0].end);
+}
+
 test "lexer: tokenize all consistency values" {
     const allocator = testing.allocator;
     const consistencies = [_][]const u8{
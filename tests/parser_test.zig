@@ -12,6 +12,7 @@ const RockType = parser.RockType;
 const RockStrength = parser.RockStrength;
 const WeatheringGrade = parser.WeatheringGrade;
 const RockStructure = parser.RockStructure;
+const PlasticityIndex = parser.PlasticityIndex;
 
 test "parser: parse simple cohesive soil" {
     const allocator = testing.allocator;
@@ -274,6 +275,24 @@ test "parser: parse with plasticity index" {
     try testing.expect(result.plasticity_index != null);
 }
 
+test "parser: parse ISO 14688-2 plasticity terms" {
+    const allocator = testing.allocator;
+    var p = Parser.init(allocator);
+
+    const cases = [_]struct { description: []const u8, expected: PlasticityIndex }{
+        .{ .description = "Firm brown CLAY of low plasticity", .expected = .low_plasticity },
+        .{ .description = "Stiff grey intermediate plasticity CLAY", .expected = .intermediate_plasticity },
+        .{ .description = "Soft high plasticity silty CLAY", .expected = .high_plasticity },
+        .{ .description = "Loose nonplastic SILT", .expected = .non_plastic },
+        .{ .description = "Firm CLAY of medium plasticity", .expected = .intermediate_plasticity },
+    };
+    for (cases) |case| {
+        const result = try p.parse(case.description);
+        defer result.deinit(allocator);
+        try testing.expectEqual(case.expected, result.plasticity_index.?);
+    }
+}
+
 test "parser: parse with particle size" {
     const allocator = testing.allocator;
     var p = Parser.init(allocator);
@@ -404,3 +423,324 @@ test "parser: whitespace handling" {
     try testing.expectEqual(result1.consistency, result2.consistency);
     try testing.expectEqual(result1.primary_soil_type, result2.primary_soil_type);
 }
+
+test "parser: hyphenated and slashed consistency ranges" {
+    const allocator = testing.allocator;
+    var p = Parser.init(allocator);
+
+    const result1 = try p.parse("Firm-stiff brown CLAY");
+    defer result1.deinit(allocator);
+    try testing.expectEqual(parser.Consistency.firm_to_stiff, result1.consistency.?);
+
+    const result2 = try p.parse("Stiff/very stiff CLAY");
+    defer result2.deinit(allocator);
+    try testing.expectEqual(parser.Consistency.stiff_to_very_stiff, result2.consistency.?);
+
+    const result3 = try p.parse("Loose-medium dense SAND");
+    defer result3.deinit(allocator);
+    try testing.expectEqual(parser.Density.loose_to_medium_dense, result3.density.?);
+}
+
+test "parser: slashed colours and hyphenated constituents" {
+    const allocator = testing.allocator;
+    var p = Parser.init(allocator);
+
+    const result = try p.parse("Firm grey/brown silty-sandy CLAY");
+    defer result.deinit(allocator);
+
+    try testing.expectEqual(parser.Color.grey, result.color.?);
+    try testing.expectEqual(parser.Color.brown, result.secondary_color.?);
+    try testing.expectEqual(@as(usize, 2), result.secondary_constituents.len);
+    try testing.expectEqualStrings("silty", result.secondary_constituents[0].soil_type);
+    try testing.expectEqualStrings("sandy", result.secondary_constituents[1].soil_type);
+}
+
+test "parser: absence statements are not detections" {
+    const allocator = testing.allocator;
+    var p = Parser.init(allocator);
+
+    const result = try p.parse("Firm brown CLAY, no visible organic matter, not sandy");
+    defer result.deinit(allocator);
+
+    try testing.expectEqual(parser.SoilType.clay, result.primary_soil_type.?);
+    try testing.expectEqual(@as(usize, 0), result.secondary_constituents.len);
+    try testing.expectEqual(@as(usize, 2), result.absences.len);
+    try testing.expectEqualStrings("organic matter", result.absences[0].feature);
+    try testing.expectEqualStrings("sandy", result.absences[1].feature);
+}
+
+test "parser: non-plastic is recorded as an absence and a value" {
+    const allocator = testing.allocator;
+    var p = Parser.init(allocator);
+
+    const result = try p.parse("Loose non-plastic grey SILT, no recovery of fines");
+    defer result.deinit(allocator);
+
+    try testing.expectEqualStrings("non plastic", result.plasticity_index.?.toString());
+    try testing.expectEqual(@as(usize, 2), result.absences.len);
+    try testing.expectEqualStrings("plasticity", result.absences[0].feature);
+    try testing.expectEqualStrings("no recovery of fines", result.absences[1].phrase);
+    try testing.expect(result.particle_size == null);
+}
+
+test "parser: measured strength replaces the inferred range" {
+    const allocator = testing.allocator;
+    var p = Parser.init(allocator);
+
+    var result = try p.parse("Firm brown CLAY");
+    defer result.deinit(allocator);
+
+    const measurements = [_]parser.Measurement{
+        .{ .parameter_type = .undrained_shear_strength, .value = 42, .test_method = "hand vane" },
+    };
+    parser.mergeMeasured(&result, &measurements);
+
+    const params = result.strength_parameters.?;
+    try testing.expectEqual(parser.StrengthProvenance.measured, params.provenance);
+    try testing.expectEqual(@as(f32, 42), params.range.lower_bound);
+    try testing.expectEqual(@as(f32, 42), params.range.upper_bound);
+}
+
+test "parser: fields record where their values came from" {
+    const allocator = testing.allocator;
+    var p = Parser.init(allocator);
+
+    const result = try p.parse("Firm brown CLAY");
+    defer result.deinit(allocator);
+
+    try testing.expectEqual(parser.Source.parsed, result.sourceOf(.consistency).?);
+    try testing.expectEqual(parser.Source.parsed, result.sourceOf(.color).?);
+    try testing.expectEqual(parser.Source.inferred, result.sourceOf(.strength_parameters).?);
+    try testing.expect(result.sourceOf(.density) == null);
+
+    const bare = try p.parse("brown");
+    defer bare.deinit(allocator);
+    try testing.expectEqual(parser.Source.default, bare.sourceOf(.material_type).?);
+}
+
+test "parser: lifecycle hooks see unknown terms and results" {
+    const allocator = testing.allocator;
+
+    const Recorder = struct {
+        unknown: std.ArrayList([]const u8),
+        parsed: usize = 0,
+
+        // Token values point into parser-owned text, so keep copies
+        fn unknownToken(self: *@This(), token: parser.Token) !void {
+            const term = try self.unknown.allocator.dupe(u8, token.value);
+            errdefer self.unknown.allocator.free(term);
+            try self.unknown.append(term);
+        }
+
+        fn afterParse(self: *@This(), result: *SoilDescription) !void {
+            _ = result;
+            self.parsed += 1;
+        }
+    };
+
+    var recorder = Recorder{ .unknown = std.ArrayList([]const u8).init(allocator) };
+    defer {
+        for (recorder.unknown.items) |term| allocator.free(term);
+        recorder.unknown.deinit();
+    }
+
+    var p = Parser.init(allocator);
+    p.onUnknownToken(&recorder, Recorder.unknownToken);
+    p.onAfterParse(&recorder, Recorder.afterParse);
+
+    const result = try p.parse("Firm glaucony CLAY");
+    defer result.deinit(allocator);
+
+    try testing.expectEqual(@as(usize, 1), recorder.parsed);
+    try testing.expectEqual(@as(usize, 1), recorder.unknown.items.len);
+    try testing.expectEqualStrings("glaucony", recorder.unknown.items[0]);
+}
+
+test "parser: profile store reload applies to later parses" {
+    const allocator = testing.allocator;
+
+    const strict = try parser.parseProfile(allocator, "[validation]\ncapitalization = \"required\"", .toml);
+    var store = try parser.ProfileStore.init(allocator, strict);
+    defer store.deinit();
+
+    const p = Parser.init(allocator);
+
+    const before = try p.parseWithProfile(&store, "Firm clay");
+    defer before.deinit(allocator);
+
+    try store.replace(try parser.parseProfile(allocator, "[validation]\ncapitalization = \"ignored\"", .toml));
+
+    const after = try p.parseWithProfile(&store, "Firm clay");
+    defer after.deinit(allocator);
+
+    try testing.expect(after.warnings.len < before.warnings.len);
+}
+
+test "parser: phonetic matching corrects misheard rock names" {
+    const allocator = testing.allocator;
+    var p = Parser.init(allocator);
+
+    const spelled = try p.parse("Weak grey shail");
+    defer spelled.deinit(allocator);
+    try testing.expect(spelled.primary_rock_type == null);
+
+    p.match_mode = .phonetic;
+    const heard = try p.parse("Weak grey shail");
+    defer heard.deinit(allocator);
+    try testing.expectEqual(parser.RockType.shale, heard.primary_rock_type.?);
+}
+
+test "parser: drilling jargon is ignored" {
+    const allocator = testing.allocator;
+
+    const Counter = struct {
+        unknown: usize = 0,
+
+        fn unknownToken(self: *@This(), token: parser.Token) !void {
+            _ = token;
+            self.unknown += 1;
+        }
+    };
+
+    var counter = Counter{};
+    var p = Parser.init(allocator);
+    p.onUnknownToken(&counter, Counter.unknownToken);
+
+    const filtered = try p.parse("Recovered as firm brown CLAY, bentonite flush");
+    defer filtered.deinit(allocator);
+    try testing.expectEqual(@as(usize, 0), counter.unknown);
+    try testing.expectEqual(parser.Consistency.firm, filtered.consistency.?);
+    try testing.expectEqualStrings("Recovered as firm brown CLAY, bentonite flush", filtered.raw_description);
+
+    p.noise_filter = .{ .use_defaults = false };
+    const unfiltered = try p.parse("Recovered as firm brown CLAY, bentonite flush");
+    defer unfiltered.deinit(allocator);
+    try testing.expect(counter.unknown > 0);
+}
+
+test "parser: spans point at the words behind each field" {
+    const allocator = testing.allocator;
+    var p = Parser.init(allocator);
+
+    const result = try p.parse("MADE GROUND: Firm brown slightly sandy CLAY (London Clay Formation)");
+    defer result.deinit(allocator);
+
+    const raw = result.raw_description;
+    const expected = [_]struct { field: parser.Field, text: []const u8 }{
+        .{ .field = .made_ground_label, .text = "MADE GROUND" },
+        .{ .field = .consistency, .text = "Firm" },
+        .{ .field = .color, .text = "brown" },
+        .{ .field = .secondary_constituents, .text = "slightly sandy" },
+        .{ .field = .primary_soil_type, .text = "CLAY" },
+        .{ .field = .geological_formation, .text = "London Clay Formation" },
+    };
+    for (expected) |item| {
+        const span = result.spanOf(item.field).?;
+        try testing.expectEqualStrings(item.text, raw[span.start..span.end]);
+    }
+    try testing.expect(result.spanOf(.strength_parameters) == null);
+}
+
+test "parser: replacing an attribute keeps the rest of the text" {
+    const allocator = testing.allocator;
+    var p = Parser.init(allocator);
+
+    const raw = "Firm  brown, slightly sandy CLAY (Glacial Till)";
+
+    const stiffer = try p.replaceAttribute(raw, .consistency, "Stiff");
+    defer allocator.free(stiffer);
+    try testing.expectEqualStrings("Stiff  brown, slightly sandy CLAY (Glacial Till)", stiffer);
+
+    const greyer = try p.replaceAttribute(raw, .color, "grey");
+    defer allocator.free(greyer);
+    try testing.expectEqualStrings("Firm  grey, slightly sandy CLAY (Glacial Till)", greyer);
+
+    try testing.expectError(error.AttributeNotFound, p.replaceAttribute(raw, .density, "Dense"));
+}
+
+test "parser: stored enum values are pinned and unknown terms are errors" {
+    // These integers are part of the C API; changing them breaks stored data
+    try testing.expectEqual(@as(u8, 3), @intFromEnum(parser.Density.medium_dense));
+    try testing.expectEqual(@as(u8, 6), @intFromEnum(parser.SoilType.peat));
+    try testing.expectEqual(@as(u8, 14), @intFromEnum(parser.RockType.breccia));
+
+    try testing.expectEqual(parser.Consistency.very_stiff, try parser.Consistency.parse("Very Stiff"));
+    try testing.expectEqual(parser.SoilType.clay, try parser.SoilType.parse("CLAY"));
+    try testing.expectError(error.InvalidConsistency, parser.Consistency.parse("squishy"));
+    try testing.expectError(error.InvalidSoilType, parser.SoilType.parse("loam"));
+}
+
+test "parser: unknown sentinel round-trips stored values" {
+    try testing.expectEqual(parser.unknown_value, parser.toStored(Consistency, null));
+    try testing.expectEqual(@as(u8, 2), parser.toStored(Consistency, .firm));
+    try testing.expectEqual(Consistency.firm, parser.fromStored(Consistency, 2).?);
+    try testing.expect(parser.fromStored(Consistency, parser.unknown_value) == null);
+    // A value from a newer release is unknown here, not a wrong member
+    try testing.expect(parser.fromStored(SoilType, 200) == null);
+}
+
+test "parser: results group soil and rock properties" {
+    const allocator = testing.allocator;
+    var p = Parser.init(allocator);
+
+    const clay = try p.parse("Firm brown CLAY");
+    defer clay.deinit(allocator);
+    try testing.expect(clay.rock() == null);
+    try testing.expectEqual(parser.Consistency.firm, clay.soil().?.consistency.?);
+
+    const limestone: parser.GeologicalDescription = try p.parse("Strong grey LIMESTONE");
+    defer limestone.deinit(allocator);
+    try testing.expect(limestone.soil() == null);
+    try testing.expectEqual(parser.RockType.limestone, limestone.rock().?.primary_rock_type.?);
+}
+
+test "parser: typed results switch on material" {
+    const allocator = testing.allocator;
+    var p = Parser.init(allocator);
+
+    const typed = try p.parseTyped("Medium dense brown gravelly SAND");
+    defer typed.deinit(allocator);
+
+    switch (typed.material) {
+        .soil => |soil| {
+            try testing.expectEqual(parser.Density.medium_dense, soil.properties.density.?);
+            try testing.expectEqual(parser.SoilType.sand, soil.properties.primary_soil_type.?);
+            try testing.expectEqual(parser.Color.brown, soil.common.color.?);
+        },
+        .rock => return error.TestUnexpectedResult,
+    }
+    try testing.expect(typed.asRock() == null);
+    try testing.expectEqualStrings("Medium dense brown gravelly SAND", typed.rawDescription());
+}
+
+test "parser: marine indicators only describe soils" {
+    const allocator = testing.allocator;
+    var p = Parser.init(allocator);
+
+    const silt = try p.parse("Very soft dark grey clayey SILT with shell fragments");
+    defer silt.deinit(allocator);
+    try testing.expect(silt.marine_indicators.contains(.shell_fragments));
+
+    const limestone = try p.parse("Strong grey shelly LIMESTONE with coral fragments");
+    defer limestone.deinit(allocator);
+    try testing.expectEqual(parser.MaterialType.rock, limestone.material_type);
+    try testing.expectEqual(@as(usize, 0), limestone.marine_indicators.count());
+}
+
+test "parser: a with clause ends at the sentence" {
+    const allocator = testing.allocator;
+    var p = Parser.init(allocator);
+
+    const clause = try p.parse("Firm grey CLAY with sand and GRAVEL");
+    defer clause.deinit(allocator);
+    try testing.expect(clause.secondary_primary_soil_type == null);
+
+    const sentences = try p.parse("Firm grey CLAY with gravel. CLAY and SILT below");
+    defer sentences.deinit(allocator);
+    try testing.expectEqual(SoilType.clay, sentences.primary_soil_type.?);
+    try testing.expectEqual(SoilType.silt, sentences.secondary_primary_soil_type.?);
+
+    const semicolon = try p.parse("Firm grey CLAY with gravel; CLAY and SILT below");
+    defer semicolon.deinit(allocator);
+    try testing.expectEqual(SoilType.silt, semicolon.secondary_primary_soil_type.?);
+}
@@ -158,3 +158,20 @@ test "strength_db: typical values are reasonable" {
         try testing.expect(tv <= params.?.range.upper_bound);
     }
 }
+
+test "strength_db: enum metadata matches the lookup tables" {
+    const firm = Consistency.firm.cuRange();
+    try testing.expectEqual(@as(f32, 25), firm.lower_bound);
+    try testing.expectEqual(@as(f32, 50), firm.upper_bound);
+
+    try testing.expectEqual(@as(f32, 30), Density.dense.sptRange().lower_bound);
+    try testing.expectEqual(@as(f32, 100), RockStrength.strong.ucsRange().upper_bound);
+    try testing.expectEqualStrings("III", parser.WeatheringGrade.moderately_weathered.gradeCode());
+    try testing.expect(SoilType.silt.isCohesive());
+    try testing.expect(!SoilType.sand.isCohesive());
+
+    for (std.enums.values(Consistency)) |consistency| {
+        const params = StrengthDatabase.getStrengthParameters(.soil, consistency, null, null, .clay).?;
+        try testing.expectEqual(consistency.cuRange().upper_bound, params.range.upper_bound);
+    }
+}
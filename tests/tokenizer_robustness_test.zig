@@ -0,0 +1,143 @@
+const std = @import("std");
+const testing = std.testing;
+const parser = @import("parser");
+
+const Parser = parser.Parser;
+const SoilDescription = parser.SoilDescription;
+
+fn expectSameParse(clean: SoilDescription, messy: SoilDescription) !void {
+    try testing.expectEqual(clean.material_type, messy.material_type);
+    try testing.expectEqual(clean.consistency, messy.consistency);
+    try testing.expectEqual(clean.density, messy.density);
+    try testing.expectEqual(clean.primary_soil_type, messy.primary_soil_type);
+    try testing.expectEqual(clean.primary_rock_type, messy.primary_rock_type);
+    try testing.expectEqual(clean.rock_strength, messy.rock_strength);
+    try testing.expectEqual(clean.weathering_grade, messy.weathering_grade);
+    try testing.expectEqual(clean.color, messy.color);
+    try testing.expectEqual(clean.particle_size, messy.particle_size);
+    try testing.expectEqual(clean.secondary_constituents.len, messy.secondary_constituents.len);
+    try testing.expectEqual(clean.confidence, messy.confidence);
+    try testing.expectEqual(@as(usize, 0), messy.spelling_corrections.len);
+}
+
+test "tokenizer: messy real-world strings parse like clean ones" {
+    const allocator = testing.allocator;
+    var p = Parser.init(allocator);
+
+    const cases = [_]struct { clean: []const u8, messy: []const u8 }{
+        .{ .clean = "Firm brown CLAY", .messy = "Firm, brown CLAY." },
+        .{ .clean = "Firm brown CLAY", .messy = "Firm;  brown   CLAY" },
+        .{ .clean = "Dense brown fine to coarse SAND", .messy = "Dense brown fine-to-coarse SAND" },
+        .{ .clean = "Firm to stiff slightly sandy CLAY", .messy = "Firm-to-stiff, slightly sandy CLAY." },
+        .{ .clean = "Medium dense grey SAND", .messy = "Medium  dense (grey) SAND" },
+        .{ .clean = "Stiff very sandy CLAY", .messy = "Stiff [very sandy] CLAY;" },
+        .{ .clean = "Strong slightly weathered LIMESTONE", .messy = "Strong, slightly-weathered LIMESTONE." },
+        .{ .clean = "Soft grey SILT", .messy = "\tSoft\tgrey SILT .\n" },
+    };
+
+    for (cases) |case| {
+        const clean = try p.parse(case.clean);
+        defer clean.deinit(allocator);
+        const messy = try p.parse(case.messy);
+        defer messy.deinit(allocator);
+
+        try expectSameParse(clean, messy);
+    }
+}
+
+test "tokenizer: trailing period does not hide formation" {
+    const allocator = testing.allocator;
+    var p = Parser.init(allocator);
+
+    const result = try p.parse("Firm brown CLAY (London Clay Formation).");
+    defer result.deinit(allocator);
+
+    try testing.expectEqualStrings("London Clay Formation", result.geological_formation.?);
+    try testing.expectEqual(parser.SoilType.clay, result.primary_soil_type.?);
+}
+
+// Descriptions for mutation fuzzing. '|' marks a boundary between terms where
+// punctuation may be inserted; spaces inside a term may only become other gaps.
+const fuzz_bases = [_][]const u8{
+    "Firm to stiff|brown|slightly sandy|CLAY",
+    "Medium dense|grey|fine to coarse|SAND",
+    "Very stiff|dark grey|very silty|CLAY",
+    "Loose|brown|slightly gravelly|SAND",
+    "Moderately strong|slightly weathered|grey|SANDSTONE",
+};
+
+fn mutate(random: std.Random, base: []const u8, out: *std.ArrayList(u8), canonical: *std.ArrayList(u8)) !void {
+    const term_gaps = [_][]const u8{ " ", "  ", "\t", "-", " - " };
+    const boundaries = [_][]const u8{ " ", "  ", ", ", " ,", "; ", " ; ", ",\t" };
+
+    if (random.boolean()) try out.appendSlice("  ");
+    for (base) |ch| {
+        switch (ch) {
+            ' ' => {
+                try out.appendSlice(term_gaps[random.uintLessThan(usize, term_gaps.len)]);
+                try canonical.append(' ');
+            },
+            '|' => {
+                try out.appendSlice(boundaries[random.uintLessThan(usize, boundaries.len)]);
+                try canonical.append(' ');
+            },
+            else => {
+                try out.append(ch);
+                try canonical.append(ch);
+            },
+        }
+    }
+
+    const endings = [_][]const u8{ "", ".", ";", " .", ",", "\n" };
+    try out.appendSlice(endings[random.uintLessThan(usize, endings.len)]);
+}
+
+test "tokenizer: fuzzed punctuation and spacing" {
+    const allocator = testing.allocator;
+    var p = Parser.init(allocator);
+
+    var prng = std.Random.DefaultPrng.init(0x5930);
+    const random = prng.random();
+
+    var iteration: usize = 0;
+    while (iteration < 200) : (iteration += 1) {
+        const base = fuzz_bases[random.uintLessThan(usize, fuzz_bases.len)];
+
+        var messy_text = std.ArrayList(u8).init(allocator);
+        defer messy_text.deinit();
+        var clean_text = std.ArrayList(u8).init(allocator);
+        defer clean_text.deinit();
+        try mutate(random, base, &messy_text, &clean_text);
+
+        const clean = try p.parse(clean_text.items);
+        defer clean.deinit(allocator);
+        const messy = try p.parse(messy_text.items);
+        defer messy.deinit(allocator);
+
+        expectSameParse(clean, messy) catch |err| {
+            std.debug.print("fuzz mismatch: clean=\"{s}\" messy=\"{s}\"\n", .{ clean_text.items, messy_text.items });
+            return err;
+        };
+    }
+}
+
+test "tokenizer: random input never fails" {
+    const allocator = testing.allocator;
+    var p = Parser.init(allocator);
+
+    var prng = std.Random.DefaultPrng.init(42);
+    const random = prng.random();
+    const alphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789 ,;.:-()[]/\t";
+
+    var buf: [96]u8 = undefined;
+    var iteration: usize = 0;
+    while (iteration < 500) : (iteration += 1) {
+        const len = random.uintAtMost(usize, buf.len);
+        for (buf[0..len]) |*ch| {
+            ch.* = alphabet[random.uintLessThan(usize, alphabet.len)];
+        }
+
+        const result = try p.parse(buf[0..len]);
+        result.deinit(allocator);
+    }
+}